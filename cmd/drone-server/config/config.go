@@ -49,6 +49,7 @@ type (
 
 		Authn        Authentication
 		Agent        Agent
+		Artifacts    Artifacts
 		AzureBlob    AzureBlob
 		Convert      Convert
 		Cleanup      Cleanup
@@ -58,21 +59,29 @@ type (
 		Datadog      Datadog
 		Docker       Docker
 		HTTP         HTTP
+		Journal      Journal
 		Jsonnet      Jsonnet
 		Starlark     Starlark
+		Lease        Lease
+		Timeout      Timeout
 		Logging      Logging
+		Logs         Logs
+		OIDC         OIDC
 		Prometheus   Prometheus
 		Proxy        Proxy
+		Queue        Queue
 		Redis        Redis
 		Registration Registration
 		Registries   Registries
 		Repository   Repository
+		Retention    Retention
 		Runner       Runner
 		RPC          RPC
 		S3           S3
 		Secrets      Secrets
 		Server       Server
 		Session      Session
+		SMTP         SMTP
 		Status       Status
 		Users        Users
 		Validate     Validate
@@ -105,12 +114,47 @@ type (
 		Pending  time.Duration `envconfig:"DRONE_CLEANUP_DEADLINE_PENDING" default:"24h"`
 	}
 
+	// Retention provides the log retention configuration.
+	Retention struct {
+		Interval    time.Duration            `envconfig:"DRONE_RETENTION_INTERVAL" default:"24h"`
+		MaxAge      time.Duration            `envconfig:"DRONE_RETENTION_MAX_AGE"`
+		MaxCount    int64                    `envconfig:"DRONE_RETENTION_MAX_COUNT"`
+		Events      map[string]time.Duration `envconfig:"DRONE_RETENTION_EVENTS"`
+		KeepDefault int                      `envconfig:"DRONE_RETENTION_KEEP_DEFAULT_BRANCH"`
+	}
+
 	// Cron provides the cron configuration.
 	Cron struct {
 		Disabled bool          `envconfig:"DRONE_CRON_DISABLED"`
 		Interval time.Duration `envconfig:"DRONE_CRON_INTERVAL" default:"30m"`
 	}
 
+	// Journal provides the incoming webhook journal configuration.
+	Journal struct {
+		Interval  time.Duration `envconfig:"DRONE_JOURNAL_INTERVAL"  default:"2s"`
+		Retention time.Duration `envconfig:"DRONE_JOURNAL_RETENTION" default:"720h"`
+	}
+
+	// Lease provides the stage lease configuration.
+	Lease struct {
+		Disabled bool          `envconfig:"DRONE_LEASE_DISABLED"`
+		Interval time.Duration `envconfig:"DRONE_LEASE_INTERVAL" default:"1m"`
+		Timeout  time.Duration `envconfig:"DRONE_LEASE_TIMEOUT"  default:"5m"`
+	}
+
+	// Timeout provides the server-side timeout configuration.
+	// The watchdog enforces the pipeline and repository timeouts,
+	// and the maximum queue wait, at every interval. The reaper,
+	// configured with DRONE_CLEANUP_*, is a coarser backstop that
+	// kills builds still pending or running after the cleanup
+	// deadlines regardless of their timeouts, and its deadlines
+	// should therefore exceed the largest repository timeout.
+	Timeout struct {
+		Disabled bool          `envconfig:"DRONE_TIMEOUT_DISABLED"`
+		Interval time.Duration `envconfig:"DRONE_TIMEOUT_INTERVAL" default:"1m"`
+		Pending  time.Duration `envconfig:"DRONE_TIMEOUT_PENDING"`
+	}
+
 	// Database provides the database configuration.
 	Database struct {
 		Driver         string `envconfig:"DRONE_DATABASE_DRIVER"          default:"sqlite3"`
@@ -170,6 +214,16 @@ type (
 		EnableAnonymousAccess bool `envconfig:"DRONE_PROMETHEUS_ANONYMOUS_ACCESS" default:"false"`
 	}
 
+	// Queue provides the scheduler queue configuration.
+	Queue struct {
+		PriorityEvents     map[string]int `envconfig:"DRONE_QUEUE_PRIORITY_EVENTS"`
+		PriorityBranch     int            `envconfig:"DRONE_QUEUE_PRIORITY_DEFAULT_BRANCH"`
+		PriorityNamespaces map[string]int `envconfig:"DRONE_QUEUE_PRIORITY_NAMESPACES"`
+		PriorityRepos      map[string]int `envconfig:"DRONE_QUEUE_PRIORITY_REPOS"`
+		FairShare          bool           `envconfig:"DRONE_QUEUE_FAIR_SHARE"`
+		FairShareWeights   map[string]int `envconfig:"DRONE_QUEUE_FAIR_SHARE_WEIGHTS"`
+	}
+
 	// Redis provides the redis configuration.
 	Redis struct {
 		ConnectionString string `envconfig:"DRONE_REDIS_CONNECTION"`
@@ -229,6 +283,7 @@ type (
 		Machine    string            `envconfig:"DRONE_RUNNER_NAME"`
 		Capacity   int               `envconfig:"DRONE_RUNNER_CAPACITY" default:"2"`
 		Labels     map[string]string `envconfig:"DRONE_RUNNER_LABELS"`
+		Taints     map[string]string `envconfig:"DRONE_RUNNER_TAINTS"`
 		Volumes    []string          `envconfig:"DRONE_RUNNER_VOLUMES"`
 		Networks   []string          `envconfig:"DRONE_RUNNER_NETWORKS"`
 		Devices    []string          `envconfig:"DRONE_RUNNER_DEVICES"`
@@ -276,6 +331,23 @@ type (
 		SkipVerify bool   `envconfig:"DRONE_ADMISSION_PLUGIN_SKIP_VERIFY"`
 	}
 
+	// OIDC provides the OpenID Connect login configuration.
+	// Identities are mapped to organizations only for the
+	// claim values listed in DRONE_OIDC_ORG_MAP.
+	OIDC struct {
+		Issuer       string            `envconfig:"DRONE_OIDC_ISSUER"`
+		ClientID     string            `envconfig:"DRONE_OIDC_CLIENT_ID"`
+		ClientSecret string            `envconfig:"DRONE_OIDC_CLIENT_SECRET"`
+		Scope        []string          `envconfig:"DRONE_OIDC_SCOPE" default:"openid,profile,email"`
+		LoginClaim   string            `envconfig:"DRONE_OIDC_LOGIN_CLAIM" default:"preferred_username"`
+		Match        string            `envconfig:"DRONE_OIDC_MATCH" default:"email"`
+		AdminClaim   string            `envconfig:"DRONE_OIDC_ADMIN_CLAIM" default:"groups"`
+		AdminValues  []string          `envconfig:"DRONE_OIDC_ADMIN_VALUES"`
+		OrgClaim     string            `envconfig:"DRONE_OIDC_ORG_CLAIM" default:"groups"`
+		OrgMap       map[string]string `envconfig:"DRONE_OIDC_ORG_MAP"`
+		SkipVerify   bool              `envconfig:"DRONE_OIDC_SKIP_VERIFY"`
+	}
+
 	// Session provides the session configuration.
 	Session struct {
 		Timeout time.Duration `envconfig:"DRONE_COOKIE_TIMEOUT" default:"720h"`
@@ -283,6 +355,16 @@ type (
 		Secure  bool          `envconfig:"DRONE_COOKIE_SECURE"`
 	}
 
+	// SMTP provides the smtp server configuration used to
+	// send email notifications.
+	SMTP struct {
+		Host     string `envconfig:"DRONE_SMTP_HOST"`
+		Port     int    `envconfig:"DRONE_SMTP_PORT" default:"587"`
+		Username string `envconfig:"DRONE_SMTP_USERNAME"`
+		Password string `envconfig:"DRONE_SMTP_PASSWORD"`
+		From     string `envconfig:"DRONE_SMTP_FROM"`
+	}
+
 	// Status provides status configurations.
 	Status struct {
 		Disabled bool   `envconfig:"DRONE_STATUS_DISABLED"`
@@ -298,10 +380,14 @@ type (
 
 	// Webhook provides the webhook configuration.
 	Webhook struct {
-		Events     []string `envconfig:"DRONE_WEBHOOK_EVENTS"`
-		Endpoint   []string `envconfig:"DRONE_WEBHOOK_ENDPOINT"`
-		Secret     string   `envconfig:"DRONE_WEBHOOK_SECRET"`
-		SkipVerify bool     `envconfig:"DRONE_WEBHOOK_SKIP_VERIFY"`
+		Events     []string      `envconfig:"DRONE_WEBHOOK_EVENTS"`
+		Endpoint   []string      `envconfig:"DRONE_WEBHOOK_ENDPOINT"`
+		Secret     string        `envconfig:"DRONE_WEBHOOK_SECRET"`
+		SkipVerify bool          `envconfig:"DRONE_WEBHOOK_SKIP_VERIFY"`
+		Interval   time.Duration `envconfig:"DRONE_WEBHOOK_INTERVAL"  default:"10s"`
+		Retries    int           `envconfig:"DRONE_WEBHOOK_RETRIES"   default:"10"`
+		Backoff    time.Duration `envconfig:"DRONE_WEBHOOK_BACKOFF"   default:"30s"`
+		Retention  time.Duration `envconfig:"DRONE_WEBHOOK_RETENTION" default:"168h"`
 	}
 
 	// Yaml provides the yaml webhook configuration.
@@ -421,6 +507,23 @@ type (
 		StorageAccessKey   string `envconfig:"DRONE_AZURE_STORAGE_ACCESS_KEY"`
 	}
 
+	// Artifacts provides the artifact storage configuration.
+	// Artifacts are stored in S3 if configured, else in the
+	// filesystem path if configured, else in the database.
+	// Artifacts stored in the database are buffered in memory
+	// and limited to MaxSize bytes.
+	Artifacts struct {
+		Path    string `envconfig:"DRONE_ARTIFACTS_PATH"`
+		MaxSize int64  `envconfig:"DRONE_ARTIFACTS_DATABASE_MAX_SIZE" default:"104857600"`
+	}
+
+	// Logs provides the log storage configuration.
+	Logs struct {
+		Compression  string        `envconfig:"DRONE_LOG_COMPRESSION"`
+		Search       bool          `envconfig:"DRONE_LOG_SEARCH_ENABLED"`
+		SearchWindow time.Duration `envconfig:"DRONE_LOG_SEARCH_WINDOW" default:"168h"`
+	}
+
 	// HTTP provides http configuration.
 	HTTP struct {
 		AllowedHosts          []string          `envconfig:"DRONE_HTTP_ALLOWED_HOSTS"`
@@ -454,6 +557,9 @@ func Environ() (Config, error) {
 	if err := kubernetesServiceConflict(&cfg); err != nil {
 		return cfg, err
 	}
+	if err := logCompressionInvalid(&cfg); err != nil {
+		return cfg, err
+	}
 	return cfg, err
 }
 
@@ -586,6 +692,15 @@ func kubernetesServiceConflict(c *Config) error {
 	return nil
 }
 
+func logCompressionInvalid(c *Config) error {
+	switch c.Logs.Compression {
+	case "", "gzip", "zstd":
+		return nil
+	default:
+		return errors.New("Invalid log compression. Supported values are gzip and zstd")
+	}
+}
+
 // Bytes stores number bytes (e.g. megabytes)
 type Bytes int64
 
@@ -16,6 +16,8 @@ package main
 
 import (
 	"github.com/drone/drone/cmd/drone-server/config"
+	"github.com/drone/drone/core"
+	"github.com/drone/drone/service/oidc"
 	"github.com/drone/go-login/login"
 	"github.com/drone/go-login/login/bitbucket"
 	"github.com/drone/go-login/login/gitea"
@@ -35,6 +37,7 @@ import (
 var loginSet = wire.NewSet(
 	provideLogin,
 	provideRefresher,
+	provideIdentityService,
 )
 
 // provideLogin is a Wire provider function that returns an
@@ -60,6 +63,31 @@ func provideLogin(config config.Config) login.Middleware {
 	return nil
 }
 
+// provideIdentityService is a Wire provider function that
+// returns an OpenID Connect identity provider based on the
+// environment configuration. A nil value is returned if the
+// issuer is not configured, in which case the OpenID Connect
+// login path is disabled.
+func provideIdentityService(config config.Config) core.IdentityService {
+	if config.OIDC.Issuer == "" {
+		return nil
+	}
+	return oidc.New(oidc.Config{
+		Issuer:       config.OIDC.Issuer,
+		ClientID:     config.OIDC.ClientID,
+		ClientSecret: config.OIDC.ClientSecret,
+		RedirectURL:  config.Server.Addr + "/login/oidc",
+		Scope:        config.OIDC.Scope,
+		LoginClaim:   config.OIDC.LoginClaim,
+		MatchLogin:   config.OIDC.Match == "login",
+		AdminClaim:   config.OIDC.AdminClaim,
+		AdminValues:  config.OIDC.AdminValues,
+		OrgClaim:     config.OIDC.OrgClaim,
+		OrgMap:       config.OIDC.OrgMap,
+		Client:       defaultClient(config.OIDC.SkipVerify),
+	})
+}
+
 // provideBitbucketLogin is a Wire provider function that
 // returns a Bitbucket Cloud authenticator based on the
 // environment configuration.
@@ -20,6 +20,7 @@ import (
 	"github.com/drone/drone/plugin/admission"
 	"github.com/drone/drone/plugin/config"
 	"github.com/drone/drone/plugin/converter"
+	"github.com/drone/drone/plugin/notify"
 	"github.com/drone/drone/plugin/registry"
 	"github.com/drone/drone/plugin/secret"
 	"github.com/drone/drone/plugin/validator"
@@ -38,6 +39,7 @@ var pluginSet = wire.NewSet(
 	provideSecretPlugin,
 	provideValidatePlugin,
 	provideWebhookPlugin,
+	provideWebhookOutbox,
 )
 
 // provideAdmissionPlugin is a Wire provider function that
@@ -158,12 +160,45 @@ func provideValidatePlugin(conf spec.Config) core.ValidateService {
 }
 
 // provideWebhookPlugin is a Wire provider function that returns
-// a webhook plugin based on the environment configuration.
-func provideWebhookPlugin(config spec.Config, system *core.System) core.WebhookSender {
-	return webhook.New(webhook.Config{
-		Events:   config.Webhook.Events,
-		Endpoint: config.Webhook.Endpoint,
-		Secret:   config.Webhook.Secret,
-		System:   system,
-	})
+// a webhook plugin based on the environment configuration. The
+// webhook plugin also sends build notifications to the chat and
+// email channels configured for the repository.
+func provideWebhookPlugin(config spec.Config, system *core.System, deliveries core.DeliveryStore, subscriptions core.SubscriptionStore, channels core.ChannelStore) core.WebhookSender {
+	return webhook.Combine(
+		webhook.New(provideWebhookConfig(config, system, deliveries, subscriptions)),
+		notify.New(notify.Config{
+			Channels: channels,
+			System:   system,
+			SMTP: notify.SMTP{
+				Host:     config.SMTP.Host,
+				Port:     config.SMTP.Port,
+				Username: config.SMTP.Username,
+				Password: config.SMTP.Password,
+				From:     config.SMTP.From,
+			},
+		}),
+	)
+}
+
+// provideWebhookOutbox is a Wire provider function that returns
+// the webhook outbox, which delivers persisted webhooks to the
+// global endpoints.
+func provideWebhookOutbox(config spec.Config, system *core.System, deliveries core.DeliveryStore, subscriptions core.SubscriptionStore) *webhook.Outbox {
+	return webhook.NewOutbox(provideWebhookConfig(config, system, deliveries, subscriptions))
+}
+
+// provideWebhookConfig is a helper function that returns the
+// webhook configuration.
+func provideWebhookConfig(config spec.Config, system *core.System, deliveries core.DeliveryStore, subscriptions core.SubscriptionStore) webhook.Config {
+	return webhook.Config{
+		Events:        config.Webhook.Events,
+		Endpoint:      config.Webhook.Endpoint,
+		Secret:        config.Webhook.Secret,
+		System:        system,
+		Deliveries:    deliveries,
+		Subscriptions: subscriptions,
+		Retries:       config.Webhook.Retries,
+		Backoff:       config.Webhook.Backoff,
+		Retention:     config.Webhook.Retention,
+	}
 }
@@ -15,6 +15,8 @@
 package main
 
 import (
+	"time"
+
 	"github.com/drone/drone-runtime/engine/docker"
 	"github.com/drone/drone/cmd/drone-server/config"
 	"github.com/drone/drone/core"
@@ -28,6 +30,8 @@ import (
 // wire set for loading the server.
 var runnerSet = wire.NewSet(
 	provideRunner,
+	provideReclaimer,
+	provideWatchdog,
 )
 
 // provideRunner is a Wire provider function that returns a
@@ -65,7 +69,9 @@ func provideRunner(
 		Privileged: config.Runner.Privileged,
 		Machine:    config.Runner.Machine,
 		Labels:     config.Runner.Labels,
+		Taints:     config.Runner.Taints,
 		Environ:    config.Runner.Environ,
+		Heartbeat:  provideHeartbeat(config),
 		Limits: runner.Limits{
 			MemSwapLimit: int64(config.Runner.Limits.MemSwapLimit),
 			MemLimit:     int64(config.Runner.Limits.MemLimit),
@@ -76,3 +82,77 @@ func provideRunner(
 		},
 	}
 }
+
+// provideHeartbeat returns the interval at which the local
+// runner renews its stage leases. The lease is renewed at the
+// reclaimer interval, which is well within the lease timeout.
+func provideHeartbeat(config config.Config) time.Duration {
+	if config.Lease.Disabled {
+		return 0
+	}
+	return config.Lease.Interval
+}
+
+// provideReclaimer is a Wire provider function that returns the
+// expired stage lease reclaimer.
+func provideReclaimer(
+	builds core.BuildStore,
+	events core.Pubsub,
+	index core.LogIndex,
+	logs core.LogStore,
+	logz core.LogStream,
+	repos core.RepositoryStore,
+	scheduler core.Scheduler,
+	stages core.StageStore,
+	status core.StatusService,
+	steps core.StepStore,
+	users core.UserStore,
+	webhook core.WebhookSender,
+	config config.Config,
+) *manager.Reclaimer {
+	return manager.NewReclaimer(
+		builds,
+		events,
+		index,
+		logs,
+		logz,
+		repos,
+		scheduler,
+		stages,
+		status,
+		steps,
+		users,
+		webhook,
+		config.Lease.Timeout,
+	)
+}
+
+// provideWatchdog is a Wire provider function that returns the
+// overdue stage watchdog.
+func provideWatchdog(
+	builds core.BuildStore,
+	events core.Pubsub,
+	logz core.LogStream,
+	repos core.RepositoryStore,
+	scheduler core.Scheduler,
+	stages core.StageStore,
+	status core.StatusService,
+	steps core.StepStore,
+	users core.UserStore,
+	webhook core.WebhookSender,
+	config config.Config,
+) *manager.Watchdog {
+	return manager.NewWatchdog(
+		builds,
+		events,
+		logz,
+		repos,
+		scheduler,
+		stages,
+		status,
+		steps,
+		users,
+		webhook,
+		config.Timeout.Pending,
+	)
+}
@@ -15,6 +15,7 @@
 package main
 
 import (
+	"github.com/drone/drone/cmd/drone-server/config"
 	"github.com/drone/drone/core"
 	"github.com/drone/drone/scheduler/queue"
 	"github.com/drone/drone/service/redisdb"
@@ -24,11 +25,26 @@ import (
 
 // wire set for loading the scheduler.
 var schedulerSet = wire.NewSet(
+	provideQueuePolicy,
 	provideScheduler,
 )
 
 // provideScheduler is a Wire provider function that returns a
 // scheduler based on the environment configuration.
-func provideScheduler(store core.StageStore, r redisdb.RedisDB) core.Scheduler {
-	return queue.New(store, r)
+func provideScheduler(store core.StageStore, repos core.RepositoryStore, nodes core.NodeStore, policy *core.QueuePolicy, r redisdb.RedisDB) core.Scheduler {
+	return queue.New(store, repos, nodes, policy, r)
+}
+
+// provideQueuePolicy is a Wire provider function that returns
+// the queue priority and fair-share policy based on the
+// environment configuration.
+func provideQueuePolicy(config config.Config) *core.QueuePolicy {
+	return &core.QueuePolicy{
+		Events:        config.Queue.PriorityEvents,
+		DefaultBranch: config.Queue.PriorityBranch,
+		Namespaces:    config.Queue.PriorityNamespaces,
+		Repos:         config.Queue.PriorityRepos,
+		FairShare:     config.Queue.FairShare,
+		Weights:       config.Queue.FairShareWeights,
+	}
 }
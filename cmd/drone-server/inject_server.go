@@ -45,6 +45,7 @@ type (
 // wire set for loading the server.
 var serverSet = wire.NewSet(
 	manager.New,
+	manager.NewRestarter,
 	api.New,
 	web.New,
 	provideHealthz,
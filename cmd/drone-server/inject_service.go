@@ -24,6 +24,7 @@ import (
 	"github.com/drone/drone/pubsub"
 	"github.com/drone/drone/service/canceler"
 	"github.com/drone/drone/service/canceler/reaper"
+	"github.com/drone/drone/service/chatops"
 	"github.com/drone/drone/service/commit"
 	contents "github.com/drone/drone/service/content"
 	"github.com/drone/drone/service/content/cache"
@@ -33,6 +34,7 @@ import (
 	"github.com/drone/drone/service/netrc"
 	orgs "github.com/drone/drone/service/org"
 	"github.com/drone/drone/service/repo"
+	"github.com/drone/drone/service/retention"
 	"github.com/drone/drone/service/status"
 	"github.com/drone/drone/service/syncer"
 	"github.com/drone/drone/service/token"
@@ -41,6 +43,7 @@ import (
 	"github.com/drone/drone/session"
 	"github.com/drone/drone/trigger"
 	"github.com/drone/drone/trigger/cron"
+	"github.com/drone/drone/trigger/journal"
 	"github.com/drone/drone/version"
 	"github.com/drone/go-scm/scm"
 
@@ -50,6 +53,7 @@ import (
 // wire set for loading the services.
 var serviceSet = wire.NewSet(
 	canceler.New,
+	chatops.New,
 	commit.New,
 	cron.New,
 	livelog.New,
@@ -65,9 +69,11 @@ var serviceSet = wire.NewSet(
 	provideContentService,
 	provideDatadog,
 	provideHookService,
+	provideJournal,
 	provideNetrcService,
 	provideOrgService,
 	provideReaper,
+	provideRetention,
 	provideSession,
 	provideStatusService,
 	provideSyncer,
@@ -119,8 +125,8 @@ func provideRepositoryService(client *scm.Client, renewer core.Renewer, config c
 
 // provideSession is a Wire provider function that returns a
 // user session based on the environment configuration.
-func provideSession(store core.UserStore, config config.Config) (core.Session, error) {
-	return session.New(store, session.NewConfig(
+func provideSession(store core.UserStore, tokens core.AccessTokenStore, config config.Config) (core.Session, error) {
+	return session.New(store, tokens, session.NewConfig(
 		config.Session.Secret,
 		config.Session.Timeout,
 		config.Session.Secure),
@@ -184,6 +190,53 @@ func provideReaper(
 	)
 }
 
+// provideRetention is a Wire provider function that returns the
+// build log retention worker, configured from the environment.
+func provideRetention(
+	repos core.RepositoryStore,
+	builds core.BuildStore,
+	stages core.StageStore,
+	logs core.LogStore,
+	cards core.CardStore,
+	index core.LogIndex,
+	config config.Config,
+) *retention.Retention {
+	return retention.New(
+		repos,
+		builds,
+		stages,
+		logs,
+		cards,
+		index,
+		core.RetentionPolicy{
+			MaxAge:      config.Retention.MaxAge,
+			MaxCount:    config.Retention.MaxCount,
+			Events:      config.Retention.Events,
+			KeepDefault: config.Retention.KeepDefault,
+		},
+	)
+}
+
+// provideJournal is a Wire provider function that returns the
+// incoming webhook journal worker.
+func provideJournal(
+	builds core.BuildStore,
+	commands core.CommandService,
+	entries core.JournalStore,
+	repos core.RepositoryStore,
+	triggerer core.Triggerer,
+	config config.Config,
+) *journal.Worker {
+	return journal.New(
+		builds,
+		commands,
+		entries,
+		repos,
+		triggerer,
+		config.Journal.Retention,
+	)
+}
+
 // provideDatadog is a Wire provider function that returns the
 // datadog sink.
 func provideDatadog(
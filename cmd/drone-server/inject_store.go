@@ -18,12 +18,20 @@ import (
 	"github.com/drone/drone/cmd/drone-server/config"
 	"github.com/drone/drone/core"
 	"github.com/drone/drone/metric"
+	"github.com/drone/drone/store/approval"
+	"github.com/drone/drone/store/artifact"
 	"github.com/drone/drone/store/batch"
 	"github.com/drone/drone/store/batch2"
 	"github.com/drone/drone/store/build"
 	"github.com/drone/drone/store/card"
+	"github.com/drone/drone/store/channel"
 	"github.com/drone/drone/store/cron"
+	"github.com/drone/drone/store/delivery"
+	"github.com/drone/drone/store/environment"
+	"github.com/drone/drone/store/identity"
+	"github.com/drone/drone/store/journal"
 	"github.com/drone/drone/store/logs"
+	"github.com/drone/drone/store/node"
 	"github.com/drone/drone/store/perm"
 	"github.com/drone/drone/store/repos"
 	"github.com/drone/drone/store/secret"
@@ -32,7 +40,10 @@ import (
 	"github.com/drone/drone/store/shared/encrypt"
 	"github.com/drone/drone/store/stage"
 	"github.com/drone/drone/store/step"
+	"github.com/drone/drone/store/subscription"
 	"github.com/drone/drone/store/template"
+	"github.com/drone/drone/store/testresult"
+	"github.com/drone/drone/store/token"
 	"github.com/drone/drone/store/user"
 
 	"github.com/google/wire"
@@ -45,8 +56,11 @@ var storeSet = wire.NewSet(
 	provideEncrypter,
 	provideBuildStore,
 	provideLogStore,
+	provideLogIndex,
+	provideArtifactStore,
 	provideRepoStore,
 	provideStageStore,
+	provideStepStore,
 	provideUserStore,
 	provideBatchStore,
 	// batch.New,
@@ -55,8 +69,17 @@ var storeSet = wire.NewSet(
 	perm.New,
 	secret.New,
 	global.New,
-	step.New,
+	node.New,
+	delivery.New,
+	subscription.New,
+	channel.New,
+	approval.New,
+	environment.New,
 	template.New,
+	testresult.New,
+	journal.New,
+	token.New,
+	identity.New,
 )
 
 // provideDatabase is a Wire provider function that provides a
@@ -102,7 +125,7 @@ func provideBuildStore(db *db.DB) core.BuildStore {
 // provideLogStore is a Wire provider function that provides a
 // log datastore, configured from the environment.
 func provideLogStore(db *db.DB, config config.Config) core.LogStore {
-	s := logs.New(db)
+	var s core.LogStore = logs.New(db)
 	if config.S3.Bucket != "" {
 		p := logs.NewS3Env(
 			config.S3.Bucket,
@@ -110,17 +133,56 @@ func provideLogStore(db *db.DB, config config.Config) core.LogStore {
 			config.S3.Endpoint,
 			config.S3.PathStyle,
 		)
-		return logs.NewCombined(p, s)
-	}
-	if config.AzureBlob.ContainerName != "" {
+		s = logs.NewCombined(p, s)
+	} else if config.AzureBlob.ContainerName != "" {
 		p := logs.NewAzureBlobEnv(
 			config.AzureBlob.ContainerName,
 			config.AzureBlob.StorageAccountName,
 			config.AzureBlob.StorageAccessKey,
 		)
-		return logs.NewCombined(p, s)
+		s = logs.NewCombined(p, s)
+	}
+	return logs.NewCompressed(s, config.Logs.Compression)
+}
+
+// provideArtifactStore is a Wire provider function that provides
+// an artifact datastore, configured from the environment.
+func provideArtifactStore(db *db.DB, config config.Config) core.ArtifactStore {
+	var blobs artifact.Blobs = artifact.NewDatabase(db, config.Artifacts.MaxSize)
+	if config.S3.Bucket != "" {
+		if b := artifact.NewS3Env(
+			config.S3.Bucket,
+			config.S3.Prefix,
+			config.S3.Endpoint,
+			config.S3.PathStyle,
+		); b != nil {
+			blobs = b
+		}
+	} else if config.Artifacts.Path != "" {
+		blobs = artifact.NewFilesystem(config.Artifacts.Path)
+	}
+	return artifact.New(db, blobs)
+}
+
+// provideLogIndex is a Wire provider function that provides a
+// log search index, configured from the environment. Logs stored
+// uncompressed in the database are searched in place. Logs stored
+// in S3 or Azure Blob Storage, or compressed in the database, are
+// copied to a local index when uploaded; logs uploaded before
+// search was enabled are not indexed.
+func provideLogIndex(db *db.DB, config config.Config) core.LogIndex {
+	switch {
+	case !config.Logs.Search:
+		return nil
+	case config.S3.Bucket != "",
+		config.AzureBlob.ContainerName != "":
+		return logs.NewLocalIndex(db, config.Logs.SearchWindow)
+	case config.Logs.Compression != "":
+		logrus.Infoln("main: log search keeps an uncompressed copy of compressed logs")
+		return logs.NewLocalIndex(db, config.Logs.SearchWindow)
+	default:
+		return logs.NewIndex(db, config.Logs.SearchWindow)
 	}
-	return s
 }
 
 // provideStageStore is a Wire provider function that provides a
@@ -133,6 +195,15 @@ func provideStageStore(db *db.DB) core.StageStore {
 	return stages
 }
 
+// provideStepStore is a Wire provider function that provides a
+// step datastore, configured from the environment, with metrics
+// enabled.
+func provideStepStore(db *db.DB) core.StepStore {
+	steps := step.New(db)
+	metric.StepMetrics()
+	return steps
+}
+
 // provideRepoStore is a Wire provider function that provides a
 // user datastore, configured from the environment, with metrics
 // enabled.
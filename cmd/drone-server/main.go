@@ -23,10 +23,14 @@ import (
 	"github.com/drone/drone/cmd/drone-server/config"
 	"github.com/drone/drone/core"
 	"github.com/drone/drone/metric/sink"
+	"github.com/drone/drone/operator/manager"
 	"github.com/drone/drone/operator/runner"
+	"github.com/drone/drone/plugin/webhook"
 	"github.com/drone/drone/service/canceler/reaper"
+	"github.com/drone/drone/service/retention"
 	"github.com/drone/drone/server"
 	"github.com/drone/drone/trigger/cron"
+	"github.com/drone/drone/trigger/journal"
 	"github.com/drone/signal"
 
 	"github.com/joho/godotenv"
@@ -127,6 +131,60 @@ func main() {
 		return app.reaper.Start(ctx, config.Cleanup.Interval)
 	})
 
+	// launches the log retention worker in a goroutine. If
+	// the retention policy never expires logs, the goroutine
+	// exits immediately without error.
+	g.Go(func() (err error) {
+		if app.retention.Policy.IsZero() {
+			return nil
+		}
+		logrus.WithField("interval", config.Retention.Interval.String()).
+			Infoln("starting the log retention worker")
+		return app.retention.Start(ctx, config.Retention.Interval)
+	})
+
+	// launches the stage lease reclaimer in a goroutine. If the
+	// reclaimer is disabled, the goroutine exits immediately
+	// without error.
+	g.Go(func() (err error) {
+		if config.Lease.Disabled {
+			return nil
+		}
+		logrus.WithField("interval", config.Lease.Interval.String()).
+			Infoln("starting the stage lease reclaimer")
+		return app.reclaimer.Start(ctx, config.Lease.Interval)
+	})
+
+	// launches the overdue stage watchdog in a goroutine. If the
+	// watchdog is disabled, the goroutine exits immediately
+	// without error.
+	g.Go(func() (err error) {
+		if config.Timeout.Disabled {
+			return nil
+		}
+		logrus.WithField("interval", config.Timeout.Interval.String()).
+			Infoln("starting the stage timeout watchdog")
+		return app.watchdog.Start(ctx, config.Timeout.Interval)
+	})
+
+	// launches the webhook outbox in a goroutine, which
+	// delivers persisted webhooks and retries failed
+	// deliveries.
+	g.Go(func() (err error) {
+		logrus.WithField("interval", config.Webhook.Interval.String()).
+			Infoln("starting the webhook outbox")
+		return app.outbox.Start(ctx, config.Webhook.Interval)
+	})
+
+	// launches the webhook journal worker in a goroutine,
+	// which processes the webhooks received from the source
+	// code management system.
+	g.Go(func() (err error) {
+		logrus.WithField("interval", config.Journal.Interval.String()).
+			Infoln("starting the webhook journal worker")
+		return app.journal.Start(ctx, config.Journal.Interval)
+	})
+
 	// launches the build runner in a goroutine. If the local
 	// runner is disabled (because nomad or kubernetes is enabled)
 	// then the goroutine exits immediately without error.
@@ -166,28 +224,43 @@ func initLogging(c config.Config) {
 
 // application is the main struct for the Drone server.
 type application struct {
-	cron   *cron.Scheduler
-	reaper *reaper.Reaper
-	sink   *sink.Datadog
-	runner *runner.Runner
-	server *server.Server
-	users  core.UserStore
+	cron      *cron.Scheduler
+	reaper    *reaper.Reaper
+	retention *retention.Retention
+	reclaimer *manager.Reclaimer
+	watchdog  *manager.Watchdog
+	outbox    *webhook.Outbox
+	journal   *journal.Worker
+	sink      *sink.Datadog
+	runner    *runner.Runner
+	server    *server.Server
+	users     core.UserStore
 }
 
 // newApplication creates a new application struct.
 func newApplication(
 	cron *cron.Scheduler,
 	reaper *reaper.Reaper,
+	retention *retention.Retention,
+	reclaimer *manager.Reclaimer,
+	watchdog *manager.Watchdog,
+	outbox *webhook.Outbox,
+	journal *journal.Worker,
 	sink *sink.Datadog,
 	runner *runner.Runner,
 	server *server.Server,
 	users core.UserStore) application {
 	return application{
-		users:  users,
-		cron:   cron,
-		sink:   sink,
-		server: server,
-		runner: runner,
-		reaper: reaper,
+		users:     users,
+		cron:      cron,
+		sink:      sink,
+		server:    server,
+		runner:    runner,
+		reaper:    reaper,
+		retention: retention,
+		reclaimer: reclaimer,
+		watchdog:  watchdog,
+		outbox:    outbox,
+		journal:   journal,
 	}
 }
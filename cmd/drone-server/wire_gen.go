@@ -13,6 +13,7 @@ import (
 	"github.com/drone/drone/operator/manager"
 	"github.com/drone/drone/pubsub"
 	"github.com/drone/drone/service/canceler"
+	"github.com/drone/drone/service/chatops"
 	"github.com/drone/drone/service/commit"
 	"github.com/drone/drone/service/hook/parser"
 	"github.com/drone/drone/service/license"
@@ -20,13 +21,22 @@ import (
 	"github.com/drone/drone/service/token"
 	"github.com/drone/drone/service/transfer"
 	"github.com/drone/drone/service/user"
+	"github.com/drone/drone/store/approval"
 	"github.com/drone/drone/store/card"
+	"github.com/drone/drone/store/channel"
 	"github.com/drone/drone/store/cron"
+	"github.com/drone/drone/store/delivery"
+	"github.com/drone/drone/store/environment"
+	"github.com/drone/drone/store/identity"
+	"github.com/drone/drone/store/journal"
+	"github.com/drone/drone/store/node"
 	"github.com/drone/drone/store/perm"
 	"github.com/drone/drone/store/secret"
 	"github.com/drone/drone/store/secret/global"
-	"github.com/drone/drone/store/step"
+	"github.com/drone/drone/store/subscription"
 	"github.com/drone/drone/store/template"
+	"github.com/drone/drone/store/testresult"
+	token2 "github.com/drone/drone/store/token"
 	"github.com/drone/drone/trigger"
 	cron2 "github.com/drone/drone/trigger/cron"
 )
@@ -62,38 +72,51 @@ func InitializeApplication(config2 config.Config) (application, error) {
 	}
 	corePubsub := pubsub.New(redisDB)
 	stageStore := provideStageStore(db)
-	scheduler := provideScheduler(stageStore, redisDB)
+	nodeStore := node.New(db)
+	queuePolicy := provideQueuePolicy(config2)
+	scheduler := provideScheduler(stageStore, repositoryStore, nodeStore, queuePolicy, redisDB)
 	statusService := provideStatusService(client, renewer, config2)
-	stepStore := step.New(db)
+	stepStore := provideStepStore(db)
 	system := provideSystem(config2)
-	webhookSender := provideWebhookPlugin(config2, system)
+	deliveryStore := delivery.New(db)
+	subscriptionStore := subscription.New(db, encrypter)
+	channelStore := channel.New(db, encrypter)
+	webhookSender := provideWebhookPlugin(config2, system, deliveryStore, subscriptionStore, channelStore)
 	coreCanceler := canceler.New(buildStore, corePubsub, repositoryStore, scheduler, stageStore, statusService, stepStore, userStore, webhookSender)
 	fileService := provideContentService(client, renewer)
 	configService := provideConfigPlugin(client, fileService, config2)
 	templateStore := template.New(db)
 	convertService := provideConvertPlugin(client, fileService, config2, templateStore)
 	validateService := provideValidatePlugin(config2)
-	triggerer := trigger.New(coreCanceler, configService, convertService, commitService, statusService, buildStore, scheduler, repositoryStore, userStore, validateService, webhookSender)
+	triggerer := trigger.New(coreCanceler, configService, convertService, commitService, statusService, buildStore, scheduler, repositoryStore, userStore, validateService, webhookSender, queuePolicy)
 	cronScheduler := cron2.New(commitService, cronStore, repositoryStore, userStore, triggerer)
 	reaper := provideReaper(repositoryStore, buildStore, stageStore, coreCanceler, config2)
 	coreLicense := provideLicense(client, config2)
 	datadog := provideDatadog(userStore, repositoryStore, buildStore, system, coreLicense, config2)
 	cardStore := card.New(db)
 	logStore := provideLogStore(db, config2)
+	artifactStore := provideArtifactStore(db, config2)
+	logIndex := provideLogIndex(db, config2)
 	logStream := livelog.New(redisDB)
 	netrcService := provideNetrcService(client, renewer, config2)
 	secretStore := secret.New(db, encrypter)
 	globalSecretStore := global.New(db, encrypter)
-	buildManager := manager.New(buildStore, cardStore, configService, convertService, corePubsub, logStore, logStream, netrcService, repositoryStore, scheduler, secretStore, globalSecretStore, statusService, stageStore, stepStore, system, userStore, webhookSender)
+	environmentStore := environment.New(db, encrypter)
+	testStore := testresult.New(db)
+	buildManager := manager.New(artifactStore, buildStore, cardStore, configService, convertService, environmentStore, corePubsub, logStore, logStream, logIndex, netrcService, nodeStore, repositoryStore, scheduler, secretStore, globalSecretStore, statusService, stageStore, stepStore, system, testStore, userStore, webhookSender)
 	secretService := provideSecretPlugin(config2)
 	registryService := provideRegistryPlugin(config2)
 	runner := provideRunner(buildManager, secretService, registryService, config2)
+	restarter := manager.NewRestarter(buildStore, cardStore, corePubsub, logIndex, logStore, logStream, repositoryStore, scheduler, stageStore, statusService, stepStore, testStore, userStore, webhookSender)
+	reclaimer := provideReclaimer(buildStore, corePubsub, logIndex, logStore, logStream, repositoryStore, scheduler, stageStore, statusService, stepStore, userStore, webhookSender, config2)
+	watchdog := provideWatchdog(buildStore, corePubsub, logStream, repositoryStore, scheduler, stageStore, statusService, stepStore, userStore, webhookSender, config2)
 	hookService := provideHookService(client, renewer, config2)
 	licenseService := license.NewService(userStore, repositoryStore, buildStore, coreLicense)
 	organizationService := provideOrgService(client, renewer)
 	permStore := perm.New(db)
 	repositoryService := provideRepositoryService(client, renewer, config2)
-	session, err := provideSession(userStore, config2)
+	accessTokenStore := token2.New(db)
+	session, err := provideSession(userStore, accessTokenStore, config2)
 	if err != nil {
 		return application{}, err
 	}
@@ -101,13 +124,17 @@ func InitializeApplication(config2 config.Config) (application, error) {
 	syncer := provideSyncer(repositoryService, repositoryStore, userStore, batcher, config2)
 	transferer := transfer.New(repositoryStore, permStore)
 	userService := user.New(client, renewer)
-	server := api.New(buildStore, commitService, cardStore, cronStore, corePubsub, globalSecretStore, hookService, logStore, coreLicense, licenseService, organizationService, permStore, repositoryStore, repositoryService, scheduler, secretStore, stageStore, stepStore, statusService, session, logStream, syncer, system, templateStore, transferer, triggerer, userStore, userService, webhookSender)
+	approvalStore := approval.New(db)
+	journalStore := journal.New(db)
+	identityStore := identity.New(db)
+	server := api.New(approvalStore, artifactStore, buildStore, commitService, cardStore, channelStore, cronStore, deliveryStore, environmentStore, corePubsub, globalSecretStore, hookService, identityStore, logStore, logIndex, journalStore, coreLicense, licenseService, nodeStore, organizationService, permStore, repositoryStore, repositoryService, restarter, scheduler, secretStore, stageStore, stepStore, statusService, session, logStream, subscriptionStore, syncer, system, templateStore, testStore, accessTokenStore, transferer, triggerer, userStore, userService, webhookSender)
 	admissionService := provideAdmissionPlugin(client, organizationService, userService, config2)
 	hookParser := parser.New(client)
 	coreLinker := linker.New(client)
 	middleware := provideLogin(config2)
 	options := provideServerOptions(config2)
-	webServer := web.New(admissionService, buildStore, client, hookParser, coreLicense, licenseService, coreLinker, middleware, repositoryStore, session, syncer, triggerer, userStore, userService, webhookSender, options, system)
+	identityService := provideIdentityService(config2)
+	webServer := web.New(admissionService, client, hookParser, identityStore, journalStore, coreLicense, licenseService, coreLinker, middleware, identityService, repositoryStore, session, syncer, userStore, userService, webhookSender, options, system)
 	mainRpcHandlerV1 := provideRPC(buildManager, config2)
 	mainRpcHandlerV2 := provideRPC2(buildManager, config2)
 	mainHealthzHandler := provideHealthz()
@@ -115,6 +142,10 @@ func InitializeApplication(config2 config.Config) (application, error) {
 	mainPprofHandler := providePprof(config2)
 	mux := provideRouter(server, webServer, mainRpcHandlerV1, mainRpcHandlerV2, mainHealthzHandler, metricServer, mainPprofHandler)
 	serverServer := provideServer(mux, config2)
-	mainApplication := newApplication(cronScheduler, reaper, datadog, runner, serverServer, userStore)
+	outbox := provideWebhookOutbox(config2, system, deliveryStore, subscriptionStore)
+	retentionRetention := provideRetention(repositoryStore, buildStore, stageStore, logStore, cardStore, logIndex, config2)
+	commandService := chatops.New(client, renewer, approvalStore, buildStore, environmentStore, organizationService, permStore, repositoryStore, scheduler, stageStore, stepStore, statusService, system, triggerer, userStore, webhookSender)
+	worker := provideJournal(buildStore, commandService, journalStore, repositoryStore, triggerer, config2)
+	mainApplication := newApplication(cronScheduler, reaper, retentionRetention, reclaimer, watchdog, outbox, worker, datadog, runner, serverServer, userStore)
 	return mainApplication, nil
 }
@@ -0,0 +1,165 @@
+// Copyright 2019 Drone IO, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package core
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Approval actions.
+const (
+	ApprovalApprove = "approve"
+	ApprovalDecline = "decline"
+)
+
+var errApprovalRuleInvalid = errors.New("Invalid approval rule")
+
+type (
+	// Approval represents the approval or decline of a
+	// blocked build stage.
+	Approval struct {
+		ID      int64  `json:"id"`
+		RepoID  int64  `json:"repo_id"`
+		BuildID int64  `json:"build_id"`
+		StageID int64  `json:"stage_id"`
+		UserID  int64  `json:"user_id"`
+		Login   string `json:"login"`
+		Action  string `json:"action"`
+		Comment string `json:"comment,omitempty"`
+		Created int64  `json:"created"`
+	}
+
+	// ApprovalRule defines the rules for approving a blocked
+	// build stage for a deployment target.
+	ApprovalRule struct {
+		// Target defines the deployment target pattern. An
+		// empty target matches all builds.
+		Target string `json:"target,omitempty"`
+
+		// Required defines the number of distinct approvers
+		// required to unblock the stage. Defaults to 1.
+		Required int `json:"required,omitempty"`
+
+		// Users defines the users allowed to approve.
+		Users []string `json:"users,omitempty"`
+
+		// Orgs defines the organizations whose members are
+		// allowed to approve. Teams are not supported.
+		Orgs []string `json:"orgs,omitempty"`
+
+		// Expiry defines the number of minutes after which
+		// an approval expires and no longer counts towards
+		// the required number of approvers.
+		Expiry int64 `json:"expiry,omitempty"`
+	}
+
+	// ApprovalStore persists approvals to storage.
+	ApprovalStore interface {
+		// List returns the approvals of the build from the
+		// datastore, in chronological order.
+		List(ctx context.Context, build int64) ([]*Approval, error)
+
+		// Create persists a new approval to the datastore.
+		Create(context.Context, *Approval) error
+
+		// Purge purges the approvals of repository builds with
+		// a build number lower than before.
+		Purge(ctx context.Context, repo int64, before int64) error
+	}
+)
+
+// Validate returns an error if the rule is invalid.
+func (r *ApprovalRule) Validate() error {
+	if _, err := filepath.Match(r.Target, ""); err != nil {
+		return errApprovalRuleInvalid
+	}
+	if r.Required < 0 || r.Expiry < 0 {
+		return errApprovalRuleInvalid
+	}
+	// organization membership is verified with the
+	// source control management system, which does not
+	// expose team membership. Reject team names instead
+	// of silently ignoring them.
+	for _, org := range r.Orgs {
+		if org == "" || strings.Contains(org, "/") {
+			return errApprovalRuleInvalid
+		}
+	}
+	return nil
+}
+
+// Match returns true if the rule matches the build
+// deployment target.
+func (r *ApprovalRule) Match(build *Build) bool {
+	if r.Target == "" {
+		return true
+	}
+	ok, _ := filepath.Match(r.Target, build.Deploy)
+	return ok
+}
+
+// Restricted returns true if approval is restricted to a
+// list of users or organizations.
+func (r *ApprovalRule) Restricted() bool {
+	return len(r.Users) != 0 || len(r.Orgs) != 0
+}
+
+// Quorum returns the number of distinct approvers required
+// to unblock the stage.
+func (r *ApprovalRule) Quorum() int {
+	if r.Required < 1 {
+		return 1
+	}
+	return r.Required
+}
+
+// Approvers returns the distinct users that approved the
+// stage and whose approval has not expired.
+func (r *ApprovalRule) Approvers(approvals []*Approval, stage int64, now time.Time) []string {
+	var out []string
+	seen := map[string]bool{}
+	for _, approval := range approvals {
+		if approval.StageID != stage || approval.Action != ApprovalApprove {
+			continue
+		}
+		if r.Expiry > 0 {
+			expires := time.Unix(approval.Created, 0).Add(time.Duration(r.Expiry) * time.Minute)
+			if now.After(expires) {
+				continue
+			}
+		}
+		if seen[approval.Login] {
+			continue
+		}
+		seen[approval.Login] = true
+		out = append(out, approval.Login)
+	}
+	return out
+}
+
+// FindApprovalRule returns the first rule that matches the
+// build, or nil if no rule matches.
+func FindApprovalRule(rules []*ApprovalRule, build *Build) *ApprovalRule {
+	for _, rule := range rules {
+		if rule.Match(build) {
+			return rule
+		}
+	}
+	return nil
+}
@@ -0,0 +1,74 @@
+// Copyright 2019 Drone.IO Inc. All rights reserved.
+// Use of this source code is governed by the Drone Non-Commercial License
+// that can be found in the LICENSE file.
+
+// +build !oss
+
+package core
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestApprovalRuleApprovers(t *testing.T) {
+	now := time.Unix(10000, 0)
+	rule := &ApprovalRule{Required: 2, Expiry: 60}
+	approvals := []*Approval{
+		{StageID: 1, Login: "octocat", Action: ApprovalApprove, Created: 9000},
+		{StageID: 1, Login: "octocat", Action: ApprovalApprove, Created: 9500},
+		{StageID: 1, Login: "spaceghost", Action: ApprovalDecline, Created: 9500},
+		{StageID: 1, Login: "janedoe", Action: ApprovalApprove, Created: 1000},
+		{StageID: 2, Login: "johnsmith", Action: ApprovalApprove, Created: 9500},
+	}
+	got := rule.Approvers(approvals, 1, now)
+	want := []string{"octocat"}
+	if diff := cmp.Diff(got, want); diff != "" {
+		t.Errorf(diff)
+	}
+}
+
+func TestApprovalRuleQuorum(t *testing.T) {
+	if got, want := new(ApprovalRule).Quorum(), 1; got != want {
+		t.Errorf("Want default quorum %d, got %d", want, got)
+	}
+	if got, want := (&ApprovalRule{Required: 3}).Quorum(), 3; got != want {
+		t.Errorf("Want quorum %d, got %d", want, got)
+	}
+}
+
+func TestFindApprovalRule(t *testing.T) {
+	rules := []*ApprovalRule{
+		{Target: "prod*", Required: 2},
+		{Target: "", Required: 1},
+	}
+	if got := FindApprovalRule(rules, &Build{Deploy: "production"}); got != rules[0] {
+		t.Errorf("Want rule matched by deployment target")
+	}
+	if got := FindApprovalRule(rules, &Build{Deploy: "staging"}); got != rules[1] {
+		t.Errorf("Want rule without a target matches all builds")
+	}
+	if got := FindApprovalRule(rules[:1], &Build{}); got != nil {
+		t.Errorf("Want nil rule when no rule matches")
+	}
+}
+
+func TestApprovalRuleValidate(t *testing.T) {
+	if err := (&ApprovalRule{Target: "prod*", Required: 2}).Validate(); err != nil {
+		t.Error(err)
+	}
+	if got, want := (&ApprovalRule{Target: "["}).Validate(), errApprovalRuleInvalid; got != want {
+		t.Errorf("Want error %v, got %v", want, got)
+	}
+	if got, want := (&ApprovalRule{Required: -1}).Validate(), errApprovalRuleInvalid; got != want {
+		t.Errorf("Want error %v, got %v", want, got)
+	}
+	if err := (&ApprovalRule{Orgs: []string{"octocat"}}).Validate(); err != nil {
+		t.Error(err)
+	}
+	if got, want := (&ApprovalRule{Orgs: []string{"octocat/admins"}}).Validate(), errApprovalRuleInvalid; got != want {
+		t.Errorf("Want error %v, got %v", want, got)
+	}
+}
@@ -0,0 +1,98 @@
+// Copyright 2019 Drone IO, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package core
+
+import (
+	"context"
+	"errors"
+	"io"
+	"path"
+	"strings"
+)
+
+var (
+	errArtifactNameInvalid = errors.New("Invalid Artifact Name")
+
+	// ErrArtifactQuota is returned when an artifact upload
+	// exceeds the repository artifact quota.
+	ErrArtifactQuota = errors.New("Artifact quota exceeded")
+
+	// ErrArtifactTooLarge is returned when an artifact upload
+	// exceeds the maximum artifact size of the datastore.
+	ErrArtifactTooLarge = errors.New("Artifact size limit exceeded")
+)
+
+type (
+	// Artifact represents a file, such as a binary, coverage
+	// report or test results, uploaded by a pipeline step and
+	// kept with the build.
+	Artifact struct {
+		ID      int64  `json:"id"`
+		RepoID  int64  `json:"repo_id"`
+		BuildID int64  `json:"build_id"`
+		StageID int64  `json:"stage_id"`
+		StepID  int64  `json:"step_id"`
+		Name    string `json:"name"`
+		Size    int64  `json:"size"`
+		Created int64  `json:"created"`
+	}
+
+	// ArtifactStore persists build artifacts to storage.
+	ArtifactStore interface {
+		// List returns a list of build artifacts from the
+		// datastore, ordered by name.
+		List(ctx context.Context, build int64) ([]*Artifact, error)
+
+		// FindName returns a build artifact from the datastore
+		// by name.
+		FindName(ctx context.Context, build int64, name string) (*Artifact, error)
+
+		// Usage returns the total size, in bytes, of the
+		// repository artifacts.
+		Usage(ctx context.Context, repo int64) (int64, error)
+
+		// Open returns the artifact contents from the datastore.
+		Open(ctx context.Context, artifact *Artifact) (io.ReadCloser, error)
+
+		// Create copies the artifact contents from Reader r to
+		// the datastore, replacing an existing build artifact
+		// with the same name. The artifact size is set to the
+		// number of bytes copied.
+		Create(ctx context.Context, artifact *Artifact, r io.Reader) error
+
+		// Purge purges the artifacts of repository builds with
+		// a build number lower than before.
+		Purge(ctx context.Context, repo int64, before int64) error
+	}
+)
+
+// Validate validates the artifact name. The name must be a
+// clean, relative path that does not escape the build.
+func (a *Artifact) Validate() error {
+	switch {
+	case a.Name == "" || len(a.Name) > 500:
+		return errArtifactNameInvalid
+	case path.Clean(a.Name) != a.Name:
+		return errArtifactNameInvalid
+	case path.IsAbs(a.Name):
+		return errArtifactNameInvalid
+	case a.Name == "." || a.Name == "..":
+		return errArtifactNameInvalid
+	case strings.HasPrefix(a.Name, "../"):
+		return errArtifactNameInvalid
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,32 @@
+// Copyright 2019 Drone.IO Inc. All rights reserved.
+// Use of this source code is governed by the Drone Non-Commercial License
+// that can be found in the LICENSE file.
+
+// +build !oss
+
+package core
+
+import "testing"
+
+func TestArtifactValidate(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+	}{
+		{"app.tar.gz", nil},
+		{"dist/linux/amd64/app", nil},
+		{"", errArtifactNameInvalid},
+		{".", errArtifactNameInvalid},
+		{"..", errArtifactNameInvalid},
+		{"../secret", errArtifactNameInvalid},
+		{"/etc/passwd", errArtifactNameInvalid},
+		{"dist//app", errArtifactNameInvalid},
+		{"dist/../../app", errArtifactNameInvalid},
+	}
+	for _, test := range tests {
+		artifact := &Artifact{Name: test.name}
+		if got, want := artifact.Validate(), test.err; got != want {
+			t.Errorf("Want error %v for name %q, got %v", want, test.name, got)
+		}
+	}
+}
@@ -47,6 +47,7 @@ type Build struct {
 	Deploy       string            `db:"build_deploy"         json:"deploy_to,omitempty"`
 	DeployID     int64             `db:"build_deploy_id"      json:"deploy_id,omitempty"`
 	Debug        bool              `db:"build_debug"          json:"debug,omitempty"`
+	Superseded   int64             `db:"build_superseded"     json:"superseded_by,omitempty"`
 	Started      int64             `db:"build_started"        json:"started"`
 	Finished     int64             `db:"build_finished"       json:"finished"`
 	Created      int64             `db:"build_created"        json:"created"`
@@ -55,6 +56,51 @@ type Build struct {
 	Stages       []*Stage          `db:"-"                    json:"stages,omitempty"`
 }
 
+// BuildParams defines build query parameters. Empty
+// values are ignored.
+type BuildParams struct {
+	// Status filters builds by status. Builds matching
+	// any of the listed statuses are returned.
+	Status []string
+
+	Event  string
+	Author string
+	Sender string
+	Ref    string
+	Target string
+	Deploy string
+	Cron   string
+
+	// Commit filters builds by commit sha prefix.
+	Commit string
+
+	// Since and Until filter builds by the unix time
+	// the build was created, inclusive.
+	Since int64
+	Until int64
+
+	Limit  int
+	Offset int
+}
+
+// ExpireParams defines the parameters used to list the
+// builds with logs eligible for expiration.
+type ExpireParams struct {
+	// After lists builds with a build number greater than
+	// After, and is used to paginate the results.
+	After int64
+
+	// Number includes builds with a build number less than
+	// or equal to Number. Zero values are ignored.
+	Number int64
+
+	// Before includes builds created before the unix time.
+	// Zero values are ignored.
+	Before int64
+
+	Limit int
+}
+
 // BuildStore defines operations for working with builds.
 type BuildStore interface {
 	// Find returns a build from the datastore.
@@ -72,6 +118,10 @@ type BuildStore interface {
 	// ListRef returns a list of builds from the datastore by ref.
 	ListRef(context.Context, int64, string, int, int) ([]*Build, error)
 
+	// Search returns a list of builds from the datastore by
+	// repository id, filtered by the query parameters.
+	Search(context.Context, int64, BuildParams) ([]*Build, error)
+
 	// LatestBranches returns the latest builds from the
 	// datastore by branch.
 	LatestBranches(context.Context, int64) ([]*Build, error)
@@ -113,6 +163,14 @@ type BuildStore interface {
 	// Purge deletes builds from the database where the build number is less than n.
 	Purge(context.Context, int64, int64) error
 
+	// ListExpirable returns a list of completed builds from
+	// the datastore, by repository id, with logs that have
+	// not yet been expired, ordered by build number.
+	ListExpirable(context.Context, int64, ExpireParams) ([]*Build, error)
+
+	// Expire marks the build logs as expired.
+	Expire(context.Context, int64) error
+
 	// Count returns a count of builds.
 	Count(context.Context) (int64, error)
 }
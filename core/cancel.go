@@ -14,7 +14,13 @@
 
 package core
 
-import "context"
+import (
+	"context"
+	"errors"
+	"path/filepath"
+)
+
+var errCancelPolicyInvalid = errors.New("Invalid auto-cancel policy pattern")
 
 // Canceler cancels a build.
 type Canceler interface {
@@ -25,3 +31,87 @@ type Canceler interface {
 	// type of as the provided build.
 	CancelPending(context.Context, *Repository, *Build) error
 }
+
+// CancelPolicy defines a repository policy for automatically
+// cancelling pending and running builds that are superseded
+// by a newer build for the same event, reference and, for
+// deployments and cron jobs, the same target environment or
+// cron job. Empty lists match all values, except for events.
+type CancelPolicy struct {
+	// Events defines the build events that are cancelled.
+	// Push and pull request builds are cancelled if empty.
+	Events []string `json:"events,omitempty"`
+
+	// Branches defines the branch patterns that are cancelled.
+	// The source branch is matched for pull requests.
+	Branches []string `json:"branches,omitempty"`
+
+	// Pipelines defines the pipeline name patterns. A build is
+	// cancelled only if it includes a matching pipeline.
+	Pipelines []string `json:"pipelines,omitempty"`
+
+	// Exclude defines the git reference patterns that are
+	// never cancelled (e.g. refs/heads/main, refs/tags/*).
+	Exclude []string `json:"exclude,omitempty"`
+}
+
+// Validate returns an error if a policy pattern is invalid.
+func (p *CancelPolicy) Validate() error {
+	var patterns []string
+	patterns = append(patterns, p.Branches...)
+	patterns = append(patterns, p.Pipelines...)
+	patterns = append(patterns, p.Exclude...)
+	for _, pattern := range patterns {
+		if _, err := filepath.Match(pattern, ""); err != nil {
+			return errCancelPolicyInvalid
+		}
+	}
+	return nil
+}
+
+// Match returns true if builds superseded by the provided
+// build should be cancelled.
+func (p *CancelPolicy) Match(build *Build) bool {
+	events := p.Events
+	if len(events) == 0 {
+		events = []string{EventPush, EventPullRequest}
+	}
+	if !matchString(events, build.Event) {
+		return false
+	}
+	branch := build.Target
+	if build.Event == EventPullRequest {
+		branch = build.Source
+	}
+	if len(p.Branches) != 0 && !matchPattern(p.Branches, branch) {
+		return false
+	}
+	return !matchPattern(p.Exclude, build.Ref)
+}
+
+// MatchPipeline returns true if the pipeline name matches
+// the policy.
+func (p *CancelPolicy) MatchPipeline(name string) bool {
+	return len(p.Pipelines) == 0 || matchPattern(p.Pipelines, name)
+}
+
+// helper function returns true if the value is in the list.
+func matchString(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
+
+// helper function returns true if the value matches any of
+// the glob patterns.
+func matchPattern(patterns []string, value string) bool {
+	for _, pattern := range patterns {
+		if ok, _ := filepath.Match(pattern, value); ok {
+			return true
+		}
+	}
+	return false
+}
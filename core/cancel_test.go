@@ -0,0 +1,61 @@
+// Copyright 2019 Drone.IO Inc. All rights reserved.
+// Use of this source code is governed by the Drone Non-Commercial License
+// that can be found in the LICENSE file.
+
+// +build !oss
+
+package core
+
+import "testing"
+
+func TestCancelPolicyMatch(t *testing.T) {
+	policy := &CancelPolicy{
+		Events:   []string{EventPush, EventPullRequest, EventTag},
+		Branches: []string{"feature/*", "v*"},
+		Exclude:  []string{"refs/heads/main", "refs/tags/*"},
+	}
+	tests := []struct {
+		build *Build
+		want  bool
+	}{
+		{&Build{Event: EventPush, Target: "feature/foo", Ref: "refs/heads/feature/foo"}, true},
+		{&Build{Event: EventPullRequest, Source: "feature/foo", Target: "main", Ref: "refs/pull/1/head"}, true},
+		{&Build{Event: EventPush, Target: "develop", Ref: "refs/heads/develop"}, false},
+		{&Build{Event: EventCron, Target: "feature/foo", Ref: "refs/heads/feature/foo"}, false},
+		{&Build{Event: EventTag, Target: "v1.0.0", Ref: "refs/tags/v1.0.0"}, false},
+	}
+	for i, test := range tests {
+		if got, want := policy.Match(test.build), test.want; got != want {
+			t.Errorf("Want match %v at index %d, got %v", want, i, got)
+		}
+	}
+}
+
+func TestCancelPolicyMatch_Empty(t *testing.T) {
+	policy := new(CancelPolicy)
+	if !policy.Match(&Build{Event: EventPush, Target: "main", Ref: "refs/heads/main"}) {
+		t.Errorf("Expect empty policy matches push builds")
+	}
+	if !policy.Match(&Build{Event: EventPullRequest, Source: "feature", Target: "main", Ref: "refs/pull/1/head"}) {
+		t.Errorf("Expect empty policy matches pull request builds")
+	}
+	for _, event := range []string{EventCustom, EventCron, EventPromote, EventRollback} {
+		if policy.Match(&Build{Event: event, Target: "main", Ref: "refs/heads/main"}) {
+			t.Errorf("Expect empty policy does not match %s builds", event)
+		}
+	}
+	if !policy.MatchPipeline("default") {
+		t.Errorf("Expect empty policy matches all pipelines")
+	}
+}
+
+func TestCancelPolicyValidate(t *testing.T) {
+	policy := &CancelPolicy{Branches: []string{"feature/*"}}
+	if err := policy.Validate(); err != nil {
+		t.Error(err)
+	}
+	policy = &CancelPolicy{Exclude: []string{"refs/heads/["}}
+	if got, want := policy.Validate(), errCancelPolicyInvalid; got != want {
+		t.Errorf("Want error %v, got %v", want, got)
+	}
+}
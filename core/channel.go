@@ -0,0 +1,134 @@
+// Copyright 2019 Drone IO, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package core
+
+import (
+	"context"
+	"errors"
+	"net/mail"
+	"net/url"
+	"text/template"
+)
+
+// Channel kinds.
+const (
+	ChannelSlack = "slack"
+	ChannelTeams = "teams"
+	ChannelHTTP  = "http"
+	ChannelEmail = "email"
+)
+
+var (
+	errChannelNameInvalid     = errors.New("Invalid Channel Name")
+	errChannelKindInvalid     = errors.New("Invalid Channel Kind")
+	errChannelEndpointInvalid = errors.New("Invalid Channel Endpoint")
+	errChannelTemplateInvalid = errors.New("Invalid Channel Template")
+	errChannelEventInvalid    = errors.New("Invalid Channel Event")
+)
+
+type (
+	// Channel represents a notification channel that posts
+	// build status messages to a chat service, http endpoint
+	// or email recipients. A channel belongs to a single
+	// repository, or to all repositories in a namespace if
+	// the repository identifier is zero.
+	Channel struct {
+		ID        int64    `json:"id"`
+		RepoID    int64    `json:"repo_id,omitempty"`
+		Namespace string   `json:"namespace"`
+		Name      string   `json:"name"`
+		Kind      string   `json:"kind"`
+		Endpoint  string   `json:"endpoint,omitempty"`
+		Template  string   `json:"template,omitempty"`
+		Events    []string `json:"events,omitempty"`
+		Created   int64    `json:"created"`
+		Updated   int64    `json:"updated"`
+	}
+
+	// ChannelStore persists notification channels to storage.
+	ChannelStore interface {
+		// List returns the repository channel list from
+		// the datastore.
+		List(context.Context, int64) ([]*Channel, error)
+
+		// ListNamespace returns the namespace channel list
+		// from the datastore.
+		ListNamespace(context.Context, string) ([]*Channel, error)
+
+		// Find returns a channel from the datastore.
+		Find(context.Context, int64) (*Channel, error)
+
+		// Create persists a new channel to the datastore.
+		Create(context.Context, *Channel) error
+
+		// Update persists an updated channel to the datastore.
+		Update(context.Context, *Channel) error
+
+		// Delete deletes a channel from the datastore.
+		Delete(context.Context, *Channel) error
+	}
+)
+
+// Validate validates the required fields and formats.
+func (c *Channel) Validate() error {
+	switch {
+	case len(c.Name) == 0,
+		len(c.Name) > 500,
+		slugRE.MatchString(c.Name):
+		return errChannelNameInvalid
+	}
+	switch c.Kind {
+	case ChannelSlack, ChannelTeams, ChannelHTTP:
+		uri, err := url.Parse(c.Endpoint)
+		if err != nil || uri.Host == "" ||
+			(uri.Scheme != "http" && uri.Scheme != "https") {
+			return errChannelEndpointInvalid
+		}
+	case ChannelEmail:
+		if _, err := mail.ParseAddressList(c.Endpoint); err != nil {
+			return errChannelEndpointInvalid
+		}
+	default:
+		return errChannelKindInvalid
+	}
+	if _, err := template.New("_").Parse(c.Template); err != nil {
+		return errChannelTemplateInvalid
+	}
+	for _, event := range c.Events {
+		switch event {
+		case StatusPending,
+			StatusBlocked,
+			StatusDeclined,
+			StatusPassing,
+			StatusFailing,
+			StatusKilled,
+			StatusError:
+		default:
+			return errChannelEventInvalid
+		}
+	}
+	return nil
+}
+
+// Copy makes a copy of the channel. The endpoint of chat and
+// http channels typically embeds an access token and is
+// removed from the copy.
+func (c *Channel) Copy() *Channel {
+	out := *c
+	if c.Kind != ChannelEmail {
+		out.Endpoint = ""
+	}
+	return &out
+}
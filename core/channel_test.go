@@ -0,0 +1,94 @@
+// Copyright 2019 Drone.IO Inc. All rights reserved.
+// Use of this source code is governed by the Drone Non-Commercial License
+// that can be found in the LICENSE file.
+
+// +build !oss
+
+package core
+
+import "testing"
+
+func TestChannelValidate(t *testing.T) {
+	tests := []struct {
+		channel *Channel
+		error   error
+	}{
+		{
+			channel: &Channel{Name: "slack", Kind: ChannelSlack, Endpoint: "https://hooks.slack.com/services/T0/B0/X"},
+			error:   nil,
+		},
+		{
+			channel: &Channel{Name: "email", Kind: ChannelEmail, Endpoint: "octocat@github.com, spaceghost@github.com"},
+			error:   nil,
+		},
+		{
+			channel: &Channel{Name: "teams", Kind: ChannelTeams, Endpoint: "https://outlook.office.com/webhook/1", Events: []string{"failure", "error"}},
+			error:   nil,
+		},
+		{
+			channel: &Channel{Name: "http", Kind: ChannelHTTP, Endpoint: "https://company.com/hooks", Template: "{{ .Build.Number }}"},
+			error:   nil,
+		},
+		{
+			channel: &Channel{Name: "", Kind: ChannelSlack, Endpoint: "https://hooks.slack.com"},
+			error:   errChannelNameInvalid,
+		},
+		{
+			channel: &Channel{Name: "a/b", Kind: ChannelSlack, Endpoint: "https://hooks.slack.com"},
+			error:   errChannelNameInvalid,
+		},
+		{
+			channel: &Channel{Name: "irc", Kind: "irc", Endpoint: "https://company.com"},
+			error:   errChannelKindInvalid,
+		},
+		{
+			channel: &Channel{Name: "slack", Kind: ChannelSlack, Endpoint: "ftp://company.com"},
+			error:   errChannelEndpointInvalid,
+		},
+		{
+			channel: &Channel{Name: "email", Kind: ChannelEmail, Endpoint: "octocat"},
+			error:   errChannelEndpointInvalid,
+		},
+		{
+			channel: &Channel{Name: "http", Kind: ChannelHTTP, Endpoint: "https://company.com", Template: "{{ .Build"},
+			error:   errChannelTemplateInvalid,
+		},
+		{
+			channel: &Channel{Name: "http", Kind: ChannelHTTP, Endpoint: "https://company.com", Events: []string{"running"}},
+			error:   errChannelEventInvalid,
+		},
+	}
+	for i, test := range tests {
+		got, want := test.channel.Validate(), test.error
+		if got != want {
+			t.Errorf("Want error %v, got %v at index %d", want, got, i)
+		}
+	}
+}
+
+func TestChannelSafeCopy(t *testing.T) {
+	before := Channel{
+		ID:       1,
+		RepoID:   2,
+		Name:     "slack",
+		Kind:     ChannelSlack,
+		Endpoint: "https://hooks.slack.com/services/T0/B0/X",
+	}
+	after := before.Copy()
+	if got, want := after.ID, before.ID; got != want {
+		t.Errorf("Want channel ID %d, got %d", want, got)
+	}
+	if got, want := after.Name, before.Name; got != want {
+		t.Errorf("Want channel Name %s, got %s", want, got)
+	}
+	if after.Endpoint != "" {
+		t.Errorf("Expect channel endpoint is empty after copy")
+	}
+
+	before.Kind = ChannelEmail
+	before.Endpoint = "octocat@github.com"
+	after = before.Copy()
+	if got, want := after.Endpoint, before.Endpoint; got != want {
+		t.Errorf("Want email channel Endpoint %s, got %s", want, got)
+	}
+}
@@ -0,0 +1,80 @@
+// Copyright 2019 Drone IO, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package core
+
+import (
+	"bufio"
+	"context"
+	"strings"
+)
+
+// CommandPrefix is the prefix that identifies a chatops
+// command in a pull request comment.
+const CommandPrefix = "/drone"
+
+// ChatOps command names.
+const (
+	CommandRetry   = "retry"
+	CommandCancel  = "cancel"
+	CommandApprove = "approve"
+	CommandPromote = "promote"
+)
+
+type (
+	// Command represents a chatops command posted as a pull
+	// request comment.
+	Command struct {
+		Name string
+		Arg  string
+	}
+
+	// CommandService executes chatops commands received from
+	// pull request comments.
+	CommandService interface {
+		// Exec executes the command contained in the comment
+		// hook and replies to the pull request with the result.
+		// Commands from senders without write access to the
+		// repository are ignored.
+		Exec(ctx context.Context, repo *Repository, hook *Hook) error
+	}
+)
+
+// String returns the command as it would be written in a
+// pull request comment.
+func (c *Command) String() string {
+	if c.Arg == "" {
+		return CommandPrefix + " " + c.Name
+	}
+	return CommandPrefix + " " + c.Name + " " + c.Arg
+}
+
+// ParseCommand returns the first chatops command found in
+// the comment body, or nil if the comment does not contain
+// a command.
+func ParseCommand(body string) *Command {
+	scanner := bufio.NewScanner(strings.NewReader(body))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 2 || fields[0] != CommandPrefix {
+			continue
+		}
+		command := &Command{Name: strings.ToLower(fields[1])}
+		if len(fields) > 2 {
+			command.Arg = fields[2]
+		}
+		return command
+	}
+	return nil
+}
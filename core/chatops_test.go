@@ -0,0 +1,63 @@
+// Copyright 2019 Drone.IO Inc. All rights reserved.
+// Use of this source code is governed by the Drone Non-Commercial License
+// that can be found in the LICENSE file.
+
+// +build !oss
+
+package core
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestParseCommand(t *testing.T) {
+	tests := []struct {
+		body string
+		want *Command
+	}{
+		{
+			body: "/drone retry",
+			want: &Command{Name: CommandRetry},
+		},
+		{
+			body: "looks good to me\n\n  /drone promote production  \n",
+			want: &Command{Name: CommandPromote, Arg: "production"},
+		},
+		{
+			body: "/drone Approve 2",
+			want: &Command{Name: CommandApprove, Arg: "2"},
+		},
+		{
+			body: "please /drone retry",
+			want: nil,
+		},
+		{
+			body: "/drone",
+			want: nil,
+		},
+		{
+			body: "/droned retry",
+			want: nil,
+		},
+	}
+	for _, test := range tests {
+		got := ParseCommand(test.body)
+		if diff := cmp.Diff(got, test.want); diff != "" {
+			t.Errorf("Unexpected command for %q", test.body)
+			t.Log(diff)
+		}
+	}
+}
+
+func TestCommandString(t *testing.T) {
+	command := &Command{Name: CommandPromote, Arg: "production"}
+	if got, want := command.String(), "/drone promote production"; got != want {
+		t.Errorf("Want command %q, got %q", want, got)
+	}
+	command = &Command{Name: CommandRetry}
+	if got, want := command.String(), "/drone retry"; got != want {
+		t.Errorf("Want command %q, got %q", want, got)
+	}
+}
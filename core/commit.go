@@ -55,5 +55,13 @@ type (
 
 		// ListChanges returns the files change by sha or reference.
 		ListChanges(ctx context.Context, user *User, repo, sha, ref string) ([]*Change, error)
+
+		// CompareChanges returns the files changed between the
+		// source and target commits.
+		CompareChanges(ctx context.Context, user *User, repo, source, target string) ([]*Change, error)
+
+		// ListPullChanges returns the files changed by the pull
+		// request number.
+		ListPullChanges(ctx context.Context, user *User, repo string, number int) ([]*Change, error)
 	}
 )
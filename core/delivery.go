@@ -0,0 +1,88 @@
+// Copyright 2019 Drone IO, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package core
+
+import "context"
+
+// Webhook delivery states.
+const (
+	DeliveryPending = "pending"
+	DeliverySuccess = "success"
+	DeliveryFailed  = "failed"
+)
+
+type (
+	// Delivery represents the delivery of a webhook event
+	// to a single endpoint.
+	Delivery struct {
+		ID             int64  `json:"id"`
+		Event          string `json:"event"`
+		Action         string `json:"action"`
+		SubscriptionID int64  `json:"subscription_id,omitempty"`
+		Endpoint       string `json:"endpoint"`
+		Payload        string `json:"payload,omitempty"`
+		Status         string `json:"status"`
+		Attempts       int    `json:"attempts"`
+		Next           int64  `json:"next"`
+		Created        int64  `json:"created"`
+		Updated        int64  `json:"updated"`
+		Version        int64  `json:"version"`
+	}
+
+	// DeliveryAttempt represents an attempt to deliver a
+	// webhook event to the endpoint.
+	DeliveryAttempt struct {
+		ID         int64  `json:"id"`
+		DeliveryID int64  `json:"delivery_id"`
+		Code       int    `json:"code"`
+		Latency    int64  `json:"latency"`
+		Response   string `json:"response,omitempty"`
+		Error      string `json:"error,omitempty"`
+		Created    int64  `json:"created"`
+	}
+
+	// DeliveryStore persists webhook deliveries and delivery
+	// attempts to storage.
+	DeliveryStore interface {
+		// List returns a list of deliveries from the datastore,
+		// ordered by most recent.
+		List(ctx context.Context, limit, offset int) ([]*Delivery, error)
+
+		// ListPending returns a list of pending deliveries from
+		// the datastore that are due for delivery.
+		ListPending(ctx context.Context, now int64) ([]*Delivery, error)
+
+		// Find returns a delivery from the datastore.
+		Find(context.Context, int64) (*Delivery, error)
+
+		// Create persists a new delivery to the datastore.
+		Create(context.Context, *Delivery) error
+
+		// Update persists an updated delivery to the datastore.
+		Update(context.Context, *Delivery) error
+
+		// Purge deletes completed deliveries, and their delivery
+		// attempts, created before the given timestamp.
+		Purge(ctx context.Context, before int64) error
+
+		// ListAttempts returns a list of delivery attempts from
+		// the datastore.
+		ListAttempts(context.Context, int64) ([]*DeliveryAttempt, error)
+
+		// CreateAttempt persists a new delivery attempt to the
+		// datastore.
+		CreateAttempt(context.Context, *DeliveryAttempt) error
+	}
+)
@@ -0,0 +1,158 @@
+// Copyright 2019 Drone IO, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package core
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"regexp"
+)
+
+var (
+	errEnvironmentNameInvalid    = errors.New("Invalid Environment Name")
+	errEnvironmentPatternInvalid = errors.New("Invalid Environment Branch Pattern")
+)
+
+var (
+	// ErrDeployBranch is returned when the branch is not
+	// permitted to deploy to the environment.
+	ErrDeployBranch = errors.New("Branch is not permitted to deploy to the environment")
+
+	// ErrDeployUser is returned when the user is not
+	// permitted to deploy to the environment.
+	ErrDeployUser = errors.New("User is not permitted to deploy to the environment")
+)
+
+// regular expression to validate the environment name.
+var environmentRE = regexp.MustCompile("^[a-zA-Z0-9_.-]+$")
+
+type (
+	// Environment represents a deployment environment, such
+	// as staging or production, defined for a repository.
+	Environment struct {
+		ID          int64  `json:"id"`
+		RepoID      int64  `json:"repo_id"`
+		Name        string `json:"name"`
+		Description string `json:"description,omitempty"`
+
+		// Branches defines the branch patterns permitted to
+		// deploy to the environment. An empty list permits
+		// all branches.
+		Branches []string `json:"branches,omitempty"`
+
+		// Users defines the users permitted to deploy to the
+		// environment. An empty list permits all users with
+		// write access to the repository.
+		Users []string `json:"users,omitempty"`
+
+		// Secrets defines the secrets provided to pipelines
+		// deploying to the environment. Secrets are never
+		// included in the json-encoded environment.
+		Secrets []*Secret `json:"-"`
+
+		Created int64 `json:"created"`
+		Updated int64 `json:"updated"`
+	}
+
+	// EnvironmentStore persists environments to storage.
+	EnvironmentStore interface {
+		// List returns a list of environments from the
+		// datastore by repository id.
+		List(ctx context.Context, repo int64) ([]*Environment, error)
+
+		// FindName returns an environment from the datastore
+		// by repository id and name.
+		FindName(ctx context.Context, repo int64, name string) (*Environment, error)
+
+		// Create persists a new environment to the datastore.
+		Create(context.Context, *Environment) error
+
+		// Update persists an updated environment to the datastore.
+		Update(context.Context, *Environment) error
+
+		// Delete deletes an environment from the datastore.
+		Delete(context.Context, *Environment) error
+
+		// Lock acquires the deployment lock of the environment
+		// until the expiry timestamp. An error is returned if
+		// the lock is held by another deployment.
+		Lock(ctx context.Context, environment *Environment, expiry int64) error
+
+		// Unlock releases the deployment lock of the environment.
+		Unlock(context.Context, *Environment) error
+	}
+)
+
+// Validate validates the environment and returns an error
+// if the validation fails.
+func (e *Environment) Validate() error {
+	if !environmentRE.MatchString(e.Name) {
+		return errEnvironmentNameInvalid
+	}
+	for _, pattern := range e.Branches {
+		if _, err := filepath.Match(pattern, ""); err != nil {
+			return errEnvironmentPatternInvalid
+		}
+	}
+	return nil
+}
+
+// Permit returns an error if the user is not permitted to
+// deploy the build to the environment.
+func (e *Environment) Permit(user *User, build *Build) error {
+	if len(e.Users) != 0 && !matchString(e.Users, user.Login) {
+		return ErrDeployUser
+	}
+	if len(e.Branches) != 0 && !matchPattern(e.Branches, build.Target) {
+		return ErrDeployBranch
+	}
+	return nil
+}
+
+// Secret returns the named environment secret, or nil if
+// the secret does not exist.
+func (e *Environment) Secret(name string) *Secret {
+	for _, secret := range e.Secrets {
+		if secret.Name == name {
+			return secret
+		}
+	}
+	return nil
+}
+
+// SetSecret adds the secret to the environment, replacing
+// an existing secret with the same name.
+func (e *Environment) SetSecret(secret *Secret) {
+	for i, s := range e.Secrets {
+		if s.Name == secret.Name {
+			e.Secrets[i] = secret
+			return
+		}
+	}
+	e.Secrets = append(e.Secrets, secret)
+}
+
+// RemoveSecret removes the named secret from the environment
+// and returns true if the secret existed.
+func (e *Environment) RemoveSecret(name string) bool {
+	for i, s := range e.Secrets {
+		if s.Name == name {
+			e.Secrets = append(e.Secrets[:i], e.Secrets[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,71 @@
+// Copyright 2019 Drone.IO Inc. All rights reserved.
+// Use of this source code is governed by the Drone Non-Commercial License
+// that can be found in the LICENSE file.
+
+// +build !oss
+
+package core
+
+import "testing"
+
+func TestEnvironmentValidate(t *testing.T) {
+	tests := []struct {
+		env *Environment
+		err error
+	}{
+		{&Environment{Name: "production"}, nil},
+		{&Environment{Name: "production", Branches: []string{"release/*"}}, nil},
+		{&Environment{Name: ""}, errEnvironmentNameInvalid},
+		{&Environment{Name: "prod cluster"}, errEnvironmentNameInvalid},
+		{&Environment{Name: "production", Branches: []string{"["}}, errEnvironmentPatternInvalid},
+	}
+	for i, test := range tests {
+		if got, want := test.env.Validate(), test.err; got != want {
+			t.Errorf("Want error %v at index %d, got %v", want, i, got)
+		}
+	}
+}
+
+func TestEnvironmentPermit(t *testing.T) {
+	env := &Environment{
+		Branches: []string{"master", "release/*"},
+		Users:    []string{"octocat"},
+	}
+	tests := []struct {
+		user   string
+		branch string
+		err    error
+	}{
+		{"octocat", "master", nil},
+		{"octocat", "release/1.0", nil},
+		{"octocat", "develop", ErrDeployBranch},
+		{"spaceghost", "master", ErrDeployUser},
+	}
+	for i, test := range tests {
+		err := env.Permit(&User{Login: test.user}, &Build{Target: test.branch})
+		if got, want := err, test.err; got != want {
+			t.Errorf("Want error %v at index %d, got %v", want, i, got)
+		}
+	}
+	if err := new(Environment).Permit(&User{}, &Build{}); err != nil {
+		t.Errorf("Want unprotected environment to permit all deployments")
+	}
+}
+
+func TestEnvironmentSecrets(t *testing.T) {
+	env := new(Environment)
+	env.SetSecret(&Secret{Name: "token", Data: "foo"})
+	env.SetSecret(&Secret{Name: "token", Data: "bar"})
+	if got, want := len(env.Secrets), 1; got != want {
+		t.Errorf("Want %d secrets, got %d", want, got)
+	}
+	if got, want := env.Secret("token").Data, "bar"; got != want {
+		t.Errorf("Want secret value %q, got %q", want, got)
+	}
+	if !env.RemoveSecret("token") {
+		t.Errorf("Want secret removed")
+	}
+	if env.Secret("token") != nil {
+		t.Errorf("Want nil secret after removal")
+	}
+}
@@ -23,4 +23,6 @@ const (
 	EventTag         = "tag"
 	EventPromote     = "promote"
 	EventRollback    = "rollback"
+	EventComment     = "comment"
+	EventRelease     = "release"
 )
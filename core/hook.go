@@ -28,6 +28,19 @@ const (
 	ActionSync   = "sync"
 )
 
+// Release hook action constants.
+const (
+	ActionPublished   = "published"
+	ActionPrereleased = "prereleased"
+	ActionEdited      = "edited"
+)
+
+// Release build parameters.
+const (
+	ParamReleaseTitle = "DRONE_RELEASE_TITLE"
+	ParamReleaseBody  = "DRONE_RELEASE_BODY"
+)
+
 // Hook represents the payload of a post-commit hook.
 type Hook struct {
 	Parent       int64             `json:"parent"`
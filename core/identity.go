@@ -0,0 +1,102 @@
+// Copyright 2019 Drone IO, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package core
+
+import (
+	"context"
+	"strings"
+)
+
+type (
+	// Identity represents a user identity verified by an
+	// external OpenID Connect provider and linked to a user
+	// account.
+	Identity struct {
+		ID      int64    `json:"id"`
+		UserID  int64    `json:"user_id"`
+		Subject string   `json:"subject"`
+		Email   string   `json:"email,omitempty"`
+		Orgs    []string `json:"orgs,omitempty"`
+		Created int64    `json:"created"`
+		Updated int64    `json:"updated"`
+	}
+
+	// IdentityClaims represents the verified claims returned
+	// by the OpenID Connect provider, mapped to user account
+	// attributes.
+	IdentityClaims struct {
+		Subject  string
+		Login    string
+		Email    string
+		Verified bool
+		Avatar   string
+
+		// Admin is true if the identity is mapped to a system
+		// administrator. It is nil if admin mapping is not
+		// configured, in which case the account admin flag
+		// is not changed.
+		Admin *bool
+
+		// Orgs is the list of organizations (namespaces) the
+		// identity is mapped to. Members of an organization
+		// are granted read access to its repositories.
+		Orgs []string
+
+		// MatchLogin is true if the identity may be matched
+		// to an existing user account by login, instead of
+		// by verified email address.
+		MatchLogin bool
+	}
+
+	// IdentityStore persists linked identities to storage.
+	IdentityStore interface {
+		// FindSubject returns an identity from the datastore
+		// by subject.
+		FindSubject(context.Context, string) (*Identity, error)
+
+		// FindUser returns an identity from the datastore
+		// by user id.
+		FindUser(context.Context, int64) (*Identity, error)
+
+		// Create persists a new identity to the datastore.
+		Create(context.Context, *Identity) error
+
+		// Update persists an updated identity to the datastore.
+		Update(context.Context, *Identity) error
+	}
+
+	// IdentityService authenticates users with an external
+	// OpenID Connect provider.
+	IdentityService interface {
+		// Redirect returns the provider authorization url
+		// to which the user is redirected to login.
+		Redirect(ctx context.Context, state, nonce string) (string, error)
+
+		// Verify exchanges the authorization code and returns
+		// the verified identity claims.
+		Verify(ctx context.Context, code, nonce string) (*IdentityClaims, error)
+	}
+)
+
+// Member returns true if the identity is mapped to the named
+// organization.
+func (i *Identity) Member(namespace string) bool {
+	for _, org := range i.Orgs {
+		if strings.EqualFold(org, namespace) {
+			return true
+		}
+	}
+	return false
+}
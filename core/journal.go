@@ -0,0 +1,80 @@
+// Copyright 2019 Drone IO, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package core
+
+import "context"
+
+// Hook journal states.
+const (
+	JournalPending = "pending"
+	JournalSuccess = "success"
+	JournalIgnored = "ignored"
+	JournalFailed  = "failed"
+)
+
+type (
+	// JournalEntry represents an incoming webhook received
+	// from the source code management system, along with the
+	// parse result and trigger outcome.
+	JournalEntry struct {
+		ID       int64  `json:"id"`
+		RepoID   int64  `json:"repo_id,omitempty"`
+		Delivery string `json:"delivery"`
+		Event    string `json:"event,omitempty"`
+		Action   string `json:"action,omitempty"`
+		Hook     *Hook  `json:"hook,omitempty"`
+		Status   string `json:"status"`
+		Error    string `json:"error,omitempty"`
+		Build    int64  `json:"build,omitempty"`
+		Attempts int    `json:"attempts"`
+		Next     int64  `json:"next"`
+		Created  int64  `json:"created"`
+		Updated  int64  `json:"updated"`
+		Version  int64  `json:"version"`
+	}
+
+	// JournalStore persists the incoming webhook journal to
+	// storage.
+	JournalStore interface {
+		// List returns a list of journal entries from the
+		// datastore, ordered by most recent.
+		List(ctx context.Context, limit, offset int) ([]*JournalEntry, error)
+
+		// ListRepo returns a list of journal entries for the
+		// repository from the datastore, ordered by most recent.
+		ListRepo(ctx context.Context, repo int64, limit, offset int) ([]*JournalEntry, error)
+
+		// ListPending returns a list of pending journal entries
+		// from the datastore that are due for processing.
+		ListPending(ctx context.Context, now int64) ([]*JournalEntry, error)
+
+		// Find returns a journal entry from the datastore.
+		Find(context.Context, int64) (*JournalEntry, error)
+
+		// FindDelivery returns a journal entry from the datastore
+		// by the source code management delivery id.
+		FindDelivery(context.Context, string) (*JournalEntry, error)
+
+		// Create persists a new journal entry to the datastore.
+		Create(context.Context, *JournalEntry) error
+
+		// Update persists an updated journal entry to the datastore.
+		Update(context.Context, *JournalEntry) error
+
+		// Purge deletes processed journal entries created before
+		// the given timestamp.
+		Purge(ctx context.Context, before int64) error
+	}
+)
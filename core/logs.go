@@ -41,6 +41,51 @@ type LogStore interface {
 	Delete(ctx context.Context, stage int64) error
 }
 
+// LogQuery defines log search parameters.
+type LogQuery struct {
+	// Text is the literal text to search for. The search
+	// is case-sensitive.
+	Text string
+
+	// Repos limits the search to the listed repositories.
+	// The search returns no results if the list is empty.
+	Repos []int64
+
+	// Limit limits the number of log lines returned.
+	Limit int
+}
+
+// LogHit represents a log line matching a search query.
+type LogHit struct {
+	RepoID    int64  `json:"repo_id"`
+	Slug      string `json:"slug"`
+	BuildID   int64  `json:"build_id"`
+	Build     int64  `json:"build_number"`
+	Stage     int    `json:"stage_number"`
+	StageName string `json:"stage_name"`
+	StepID    int64  `json:"step_id"`
+	Step      int    `json:"step_number"`
+	StepName  string `json:"step_name"`
+	Line      int    `json:"line"`
+	Message   string `json:"message"`
+}
+
+// LogIndex provides full-text search across build logs.
+type LogIndex interface {
+	// Index indexes the full logs for the step ID.
+	Index(ctx context.Context, step int64, data []byte) error
+
+	// Search returns the log lines matching the query.
+	Search(ctx context.Context, query *LogQuery) ([]*LogHit, error)
+
+	// Delete removes the logs for the step ID from the index.
+	Delete(ctx context.Context, step int64) error
+
+	// Purge removes the logs of repository builds with a
+	// build number lower than before from the index.
+	Purge(ctx context.Context, repo int64, before int64) error
+}
+
 // LogStream manages a live stream of logs.
 type LogStream interface {
 	// Create creates the log stream for the step ID.
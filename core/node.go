@@ -0,0 +1,68 @@
+// Copyright 2019 Drone IO, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package core
+
+import "context"
+
+// Node states.
+const (
+	NodeStateActive   = "active"
+	NodeStateDraining = "draining"
+	NodeStateDrained  = "drained"
+)
+
+type (
+	// Node represents a runner registered with the server.
+	Node struct {
+		ID       int64             `json:"id"`
+		Name     string            `json:"name"`
+		State    string            `json:"state"`
+		Kind     string            `json:"kind,omitempty"`
+		Type     string            `json:"type,omitempty"`
+		OS       string            `json:"os"`
+		Arch     string            `json:"arch"`
+		Variant  string            `json:"variant,omitempty"`
+		Kernel   string            `json:"kernel,omitempty"`
+		Capacity int               `json:"capacity"`
+		Labels   map[string]string `json:"labels,omitempty"`
+		Taints   map[string]string `json:"taints,omitempty"`
+		Cordoned bool              `json:"cordoned"`
+		Seen     int64             `json:"seen"`
+		Created  int64             `json:"created"`
+		Updated  int64             `json:"updated"`
+		Stages   []*Stage          `json:"stages,omitempty"`
+	}
+
+	// NodeStore persists runner node information to storage.
+	NodeStore interface {
+		// List returns a node list from the datastore.
+		List(context.Context) ([]*Node, error)
+
+		// Find returns a node from the datastore by ID.
+		Find(context.Context, int64) (*Node, error)
+
+		// FindName returns a node from the datastore by name.
+		FindName(context.Context, string) (*Node, error)
+
+		// Create persists a new node to the datastore.
+		Create(context.Context, *Node) error
+
+		// Update persists an updated node to the datastore.
+		Update(context.Context, *Node) error
+
+		// Delete deletes a node from the datastore.
+		Delete(context.Context, *Node) error
+	}
+)
@@ -54,6 +54,7 @@ type (
 		CancelPulls   bool   `json:"auto_cancel_pull_requests"`
 		CancelPush    bool   `json:"auto_cancel_pushes"`
 		CancelRunning bool   `json:"auto_cancel_running"`
+		RequeueLost   bool   `json:"requeue_lost_stages"`
 		Timeout       int64  `json:"timeout"`
 		Throttle      int64  `json:"throttle,omitempty"`
 		Counter       int64  `json:"counter"`
@@ -66,6 +67,21 @@ type (
 		Build         *Build `json:"build,omitempty"`
 		Perms         *Perm  `json:"permissions,omitempty"`
 		Archived      bool   `json:"archived"`
+
+		// CancelPolicy defines the auto-cancel policy. If
+		// defined, the policy takes precedence over the
+		// CancelPulls, CancelPush and CancelRunning flags.
+		CancelPolicy *CancelPolicy `json:"auto_cancel_policy,omitempty"`
+
+		// ApprovalRules defines the rules for approving blocked
+		// stages. The first rule that matches the deployment
+		// target of the build is applied.
+		ApprovalRules []*ApprovalRule `json:"approval_rules,omitempty"`
+
+		// ArtifactQuota defines the maximum total size, in
+		// bytes, of the repository build artifacts. A zero
+		// value does not limit the size.
+		ArtifactQuota int64 `json:"artifact_quota,omitempty"`
 	}
 
 	RepoBuildStage struct {
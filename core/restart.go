@@ -0,0 +1,40 @@
+// Copyright 2019 Drone IO, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package core
+
+import (
+	"context"
+	"errors"
+)
+
+var (
+	// ErrRestartRunning is returned when attempting to
+	// restart stages of a build that is not complete.
+	ErrRestartRunning = errors.New("cannot restart a running build")
+
+	// ErrRestartNoStages is returned when there are no
+	// stages matching the restart request.
+	ErrRestartNoStages = errors.New("no stages to restart")
+)
+
+// Restarter restarts stages of an existing build.
+type Restarter interface {
+	// Restart resets the stages with the provided numbers,
+	// and their downstream dependents, and schedules them for
+	// execution. The results of the remaining stages are kept.
+	// If no stage numbers are provided the failed stages are
+	// restarted.
+	Restart(ctx context.Context, repo *Repository, build *Build, stages []int) error
+}
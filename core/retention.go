@@ -0,0 +1,87 @@
+// Copyright 2019 Drone IO, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package core
+
+import "time"
+
+// RetentionPolicy defines how long build logs and cards are
+// kept before they are expired. The build history is kept
+// when the logs are expired.
+type RetentionPolicy struct {
+	// MaxAge is the maximum age of the build logs. Logs
+	// for older builds are expired.
+	MaxAge time.Duration
+
+	// MaxCount is the number of most recent builds, per
+	// repository, for which logs are kept.
+	MaxCount int64
+
+	// Events overrides the maximum age of the build logs
+	// by build event. A zero value disables age based
+	// expiration for the event.
+	Events map[string]time.Duration
+
+	// KeepDefault is the number of most recent builds on
+	// the repository default branch for which logs are
+	// always kept.
+	KeepDefault int
+}
+
+// IsZero returns true if the policy never expires logs.
+func (p *RetentionPolicy) IsZero() bool {
+	return p.MaxCount <= 0 && p.cutoff() == 0
+}
+
+// Params returns the parameters used to list builds with
+// logs that may be expired under the policy.
+func (p *RetentionPolicy) Params(repo *Repository, now time.Time) ExpireParams {
+	params := ExpireParams{}
+	if p.MaxCount > 0 && repo.Counter > p.MaxCount {
+		params.Number = repo.Counter - p.MaxCount
+	}
+	if age := p.cutoff(); age > 0 {
+		params.Before = now.Add(-age).Unix()
+	}
+	return params
+}
+
+// IsExpired returns true if the build logs are expired
+// under the policy. It does not consider the builds that
+// are kept for the repository default branch.
+func (p *RetentionPolicy) IsExpired(repo *Repository, build *Build, now time.Time) bool {
+	if p.MaxCount > 0 && build.Number <= repo.Counter-p.MaxCount {
+		return true
+	}
+	age := p.MaxAge
+	if v, ok := p.Events[build.Event]; ok {
+		age = v
+	}
+	return age > 0 && build.Created < now.Add(-age).Unix()
+}
+
+// helper function returns the shortest maximum age across
+// all build events, or zero if logs never expire by age.
+func (p *RetentionPolicy) cutoff() time.Duration {
+	age := p.MaxAge
+	for _, v := range p.Events {
+		if v > 0 && (age <= 0 || v < age) {
+			age = v
+		}
+	}
+	if age < 0 {
+		age = 0
+	}
+	return age
+}
@@ -0,0 +1,90 @@
+// Copyright 2019 Drone.IO Inc. All rights reserved.
+// Use of this source code is governed by the Drone Non-Commercial License
+// that can be found in the LICENSE file.
+
+// +build !oss
+
+package core
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRetentionPolicy_IsZero(t *testing.T) {
+	tests := []struct {
+		policy RetentionPolicy
+		want   bool
+	}{
+		{RetentionPolicy{}, true},
+		{RetentionPolicy{KeepDefault: 10}, true},
+		{RetentionPolicy{Events: map[string]time.Duration{EventPush: 0}}, true},
+		{RetentionPolicy{MaxAge: time.Hour}, false},
+		{RetentionPolicy{MaxCount: 100}, false},
+		{RetentionPolicy{Events: map[string]time.Duration{EventPullRequest: time.Hour}}, false},
+	}
+	for i, test := range tests {
+		if got, want := test.policy.IsZero(), test.want; got != want {
+			t.Errorf("Want IsZero %v, got %v at index %d", want, got, i)
+		}
+	}
+}
+
+func TestRetentionPolicy_Params(t *testing.T) {
+	now := time.Unix(1000000, 0)
+	repo := &Repository{Counter: 150}
+	policy := RetentionPolicy{
+		MaxAge:   time.Hour * 24,
+		MaxCount: 100,
+		Events: map[string]time.Duration{
+			EventPullRequest: time.Hour,
+			EventPush:        0,
+		},
+	}
+	params := policy.Params(repo, now)
+	if got, want := params.Number, int64(50); got != want {
+		t.Errorf("Want build number %d, got %d", want, got)
+	}
+	if got, want := params.Before, now.Add(-time.Hour).Unix(); got != want {
+		t.Errorf("Want created before %d, got %d", want, got)
+	}
+
+	params = policy.Params(&Repository{Counter: 50}, now)
+	if got, want := params.Number, int64(0); got != want {
+		t.Errorf("Want build number %d, got %d", want, got)
+	}
+}
+
+func TestRetentionPolicy_IsExpired(t *testing.T) {
+	now := time.Unix(1000000, 0)
+	day := int64(60 * 60 * 24)
+	repo := &Repository{Counter: 150}
+	policy := RetentionPolicy{
+		MaxAge:   time.Hour * 24 * 7,
+		MaxCount: 100,
+		Events: map[string]time.Duration{
+			EventPullRequest: time.Hour * 24,
+			EventTag:         0,
+		},
+	}
+	tests := []struct {
+		build *Build
+		want  bool
+	}{
+		// build number exceeds the maximum count
+		{&Build{Number: 50, Event: EventTag, Created: now.Unix()}, true},
+		// build is recent
+		{&Build{Number: 140, Event: EventPush, Created: now.Unix() - day}, false},
+		// build exceeds the maximum age
+		{&Build{Number: 140, Event: EventPush, Created: now.Unix() - day*8}, true},
+		// build exceeds the maximum age for the event
+		{&Build{Number: 140, Event: EventPullRequest, Created: now.Unix() - day*2}, true},
+		// build age is ignored for the event
+		{&Build{Number: 140, Event: EventTag, Created: now.Unix() - day*30}, false},
+	}
+	for i, test := range tests {
+		if got, want := policy.IsExpired(repo, test.build, now), test.want; got != want {
+			t.Errorf("Want IsExpired %v, got %v at index %d", want, got, i)
+		}
+	}
+}
@@ -25,7 +25,37 @@ type Filter struct {
 	Arch    string
 	Kernel  string
 	Variant string
+	Machine string
 	Labels  map[string]string
+	Taints  map[string]string
+}
+
+// QueuePolicy defines the server-side rules used by the
+// scheduler to order pending stages.
+type QueuePolicy struct {
+	// Events provides the priority by build event
+	// (e.g. promote, push, pull_request).
+	Events map[string]int
+
+	// DefaultBranch provides the additional priority for
+	// builds that target the repository default branch.
+	DefaultBranch int
+
+	// Namespaces provides the additional priority by
+	// repository namespace.
+	Namespaces map[string]int
+
+	// Repos provides the additional priority by
+	// repository slug.
+	Repos map[string]int
+
+	// FairShare enables weighted fair-share ordering of
+	// stages with equal priority across namespaces.
+	FairShare bool
+
+	// Weights provides the fair-share weight by namespace.
+	// Namespaces without a weight default to 1.
+	Weights map[string]int
 }
 
 // Scheduler schedules Build stages for execution.
@@ -56,3 +86,29 @@ type Scheduler interface {
 	// data format is scheduler-specific.
 	Stats(context.Context) (interface{}, error)
 }
+
+// Priority returns the scheduling priority for the build.
+// Stages with a higher priority are dispatched first.
+func (p *QueuePolicy) Priority(repo *Repository, build *Build) int {
+	if p == nil {
+		return 0
+	}
+	priority := p.Events[build.Event]
+	if build.Event != EventPullRequest && build.Target == repo.Branch {
+		priority += p.DefaultBranch
+	}
+	priority += p.Namespaces[repo.Namespace]
+	priority += p.Repos[repo.Slug]
+	return priority
+}
+
+// Weight returns the fair-share weight for the namespace.
+func (p *QueuePolicy) Weight(namespace string) int {
+	if p == nil {
+		return 1
+	}
+	if weight, ok := p.Weights[namespace]; ok && weight > 0 {
+		return weight
+	}
+	return 1
+}
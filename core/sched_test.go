@@ -0,0 +1,65 @@
+// Copyright 2019 Drone.IO Inc. All rights reserved.
+// Use of this source code is governed by the Drone Non-Commercial License
+// that can be found in the LICENSE file.
+
+// +build !oss
+
+package core
+
+import "testing"
+
+func TestQueuePolicy_Priority(t *testing.T) {
+	policy := &QueuePolicy{
+		Events: map[string]int{
+			EventPromote:     100,
+			EventPullRequest: -10,
+		},
+		DefaultBranch: 5,
+		Namespaces:    map[string]int{"octocat": 1},
+		Repos:         map[string]int{"octocat/hello-world": 2},
+	}
+	repo := &Repository{
+		Namespace: "octocat",
+		Slug:      "octocat/hello-world",
+		Branch:    "master",
+	}
+	tests := []struct {
+		build *Build
+		want  int
+	}{
+		{build: &Build{Event: EventPromote, Target: "master"}, want: 108},
+		{build: &Build{Event: EventPush, Target: "master"}, want: 8},
+		{build: &Build{Event: EventPush, Target: "develop"}, want: 3},
+		{build: &Build{Event: EventPullRequest, Target: "master"}, want: -7},
+	}
+	for i, test := range tests {
+		if got, want := policy.Priority(repo, test.build), test.want; got != want {
+			t.Errorf("Want priority %d at index %d, got %d", want, i, got)
+		}
+	}
+}
+
+func TestQueuePolicy_Nil(t *testing.T) {
+	var policy *QueuePolicy
+	if got := policy.Priority(&Repository{}, &Build{}); got != 0 {
+		t.Errorf("Want zero priority for nil policy, got %d", got)
+	}
+	if got := policy.Weight("octocat"); got != 1 {
+		t.Errorf("Want default weight for nil policy, got %d", got)
+	}
+}
+
+func TestQueuePolicy_Weight(t *testing.T) {
+	policy := &QueuePolicy{
+		Weights: map[string]int{"octocat": 3, "spaceghost": 0},
+	}
+	if got, want := policy.Weight("octocat"), 3; got != want {
+		t.Errorf("Want weight %d, got %d", want, got)
+	}
+	if got, want := policy.Weight("spaceghost"), 1; got != want {
+		t.Errorf("Want default weight %d for invalid weight, got %d", want, got)
+	}
+	if got, want := policy.Weight("unknown"), 1; got != want {
+		t.Errorf("Want default weight %d, got %d", want, got)
+	}
+}
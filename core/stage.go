@@ -37,15 +37,19 @@ type (
 		Kernel    string            `json:"kernel,omitempty"`
 		Limit     int               `json:"limit,omitempty"`
 		LimitRepo int               `json:"throttle,omitempty"`
+		Priority  int               `json:"priority,omitempty"`
+		Timeout   int64             `json:"timeout,omitempty"`
 		Started   int64             `json:"started"`
 		Stopped   int64             `json:"stopped"`
 		Created   int64             `json:"created"`
 		Updated   int64             `json:"updated"`
+		Heartbeat int64             `json:"heartbeat,omitempty"`
 		Version   int64             `json:"version"`
 		OnSuccess bool              `json:"on_success"`
 		OnFailure bool              `json:"on_failure"`
 		DependsOn []string          `json:"depends_on,omitempty"`
 		Labels    map[string]string `json:"labels,omitempty"`
+		Tolerates map[string]string `json:"tolerations,omitempty"`
 		Steps     []*Step           `json:"steps,omitempty"`
 	}
 
@@ -77,6 +81,11 @@ type (
 
 		// Update persists an updated stage to the datastore.
 		Update(context.Context, *Stage) error
+
+		// Heartbeat persists the stage heartbeat to the datastore.
+		// The stage version is not incremented, allowing the lease
+		// to be renewed while the runner holds the stage.
+		Heartbeat(context.Context, *Stage) error
 	}
 )
 
@@ -34,6 +34,19 @@ type (
 		Image     string   `json:"image,omitempty"`
 		Detached  bool     `json:"detached,omitempty"`
 		Schema    string   `json:"schema,omitempty"`
+
+		// Metrics provides the optional timing and resource
+		// metrics reported by the runner.
+		Metrics *StepMetrics `json:"metrics,omitempty"`
+	}
+
+	// StepMetrics represents the timing and resource metrics
+	// sampled by the runner while executing a step.
+	StepMetrics struct {
+		MemoryPeak   int64   `json:"memory_peak,omitempty"` // bytes
+		CPUSeconds   float64 `json:"cpu_seconds,omitempty"`
+		PullSeconds  float64 `json:"pull_seconds,omitempty"`
+		CloneSeconds float64 `json:"clone_seconds,omitempty"`
 	}
 
 	// StepStore persists build step information to storage.
@@ -52,6 +65,10 @@ type (
 
 		// Update persists an updated stage to the datastore.
 		Update(context.Context, *Step) error
+
+		// DeleteStage deletes the steps of a stage from the
+		// datastore.
+		DeleteStage(context.Context, int64) error
 	}
 )
 
@@ -0,0 +1,87 @@
+// Copyright 2019 Drone IO, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package core
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"path/filepath"
+)
+
+var (
+	errSubscriptionEndpointInvalid = errors.New("Invalid Webhook Endpoint")
+	errSubscriptionEventInvalid    = errors.New("Invalid Webhook Event Pattern")
+)
+
+type (
+	// Subscription represents a repository webhook that
+	// subscribes an endpoint to repository events.
+	Subscription struct {
+		ID       int64    `json:"id"`
+		RepoID   int64    `json:"repo_id"`
+		Endpoint string   `json:"endpoint"`
+		Secret   string   `json:"secret,omitempty"`
+		Events   []string `json:"events,omitempty"`
+		Created  int64    `json:"created"`
+		Updated  int64    `json:"updated"`
+	}
+
+	// SubscriptionStore persists repository webhook
+	// subscriptions to storage.
+	SubscriptionStore interface {
+		// List returns a subscription list from the datastore.
+		List(context.Context, int64) ([]*Subscription, error)
+
+		// Find returns a subscription from the datastore.
+		Find(context.Context, int64) (*Subscription, error)
+
+		// Create persists a new subscription to the datastore.
+		Create(context.Context, *Subscription) error
+
+		// Update persists an updated subscription to the datastore.
+		Update(context.Context, *Subscription) error
+
+		// Delete deletes a subscription from the datastore.
+		Delete(context.Context, *Subscription) error
+	}
+)
+
+// Validate validates the required fields and formats.
+func (s *Subscription) Validate() error {
+	uri, err := url.Parse(s.Endpoint)
+	if err != nil || uri.Host == "" ||
+		(uri.Scheme != "http" && uri.Scheme != "https") {
+		return errSubscriptionEndpointInvalid
+	}
+	for _, pattern := range s.Events {
+		if _, err := filepath.Match(pattern, ""); err != nil {
+			return errSubscriptionEventInvalid
+		}
+	}
+	return nil
+}
+
+// Copy makes a copy of the subscription without the secret.
+func (s *Subscription) Copy() *Subscription {
+	return &Subscription{
+		ID:       s.ID,
+		RepoID:   s.RepoID,
+		Endpoint: s.Endpoint,
+		Events:   s.Events,
+		Created:  s.Created,
+		Updated:  s.Updated,
+	}
+}
@@ -0,0 +1,70 @@
+// Copyright 2019 Drone.IO Inc. All rights reserved.
+// Use of this source code is governed by the Drone Non-Commercial License
+// that can be found in the LICENSE file.
+
+// +build !oss
+
+package core
+
+import "testing"
+
+func TestSubscriptionValidate(t *testing.T) {
+	tests := []struct {
+		subscription *Subscription
+		error        error
+	}{
+		{
+			subscription: &Subscription{Endpoint: "https://company.com/hooks"},
+			error:        nil,
+		},
+		{
+			subscription: &Subscription{Endpoint: "http://company.com/hooks", Events: []string{"build:*", "repo"}},
+			error:        nil,
+		},
+		{
+			subscription: &Subscription{Endpoint: ""},
+			error:        errSubscriptionEndpointInvalid,
+		},
+		{
+			subscription: &Subscription{Endpoint: "ftp://company.com/hooks"},
+			error:        errSubscriptionEndpointInvalid,
+		},
+		{
+			subscription: &Subscription{Endpoint: "/hooks"},
+			error:        errSubscriptionEndpointInvalid,
+		},
+		{
+			subscription: &Subscription{Endpoint: "https://company.com/hooks", Events: []string{"build:["}},
+			error:        errSubscriptionEventInvalid,
+		},
+	}
+	for i, test := range tests {
+		got, want := test.subscription.Validate(), test.error
+		if got != want {
+			t.Errorf("Want error %v, got %v at index %d", want, got, i)
+		}
+	}
+}
+
+func TestSubscriptionSafeCopy(t *testing.T) {
+	before := Subscription{
+		ID:       1,
+		RepoID:   2,
+		Endpoint: "https://company.com/hooks",
+		Secret:   "correct-horse-battery-staple",
+		Events:   []string{"build:*"},
+	}
+	after := before.Copy()
+	if got, want := after.ID, before.ID; got != want {
+		t.Errorf("Want subscription ID %d, got %d", want, got)
+	}
+	if got, want := after.RepoID, before.RepoID; got != want {
+		t.Errorf("Want subscription RepoID %d, got %d", want, got)
+	}
+	if got, want := after.Endpoint, before.Endpoint; got != want {
+		t.Errorf("Want subscription Endpoint %s, got %s", want, got)
+	}
+	if after.Secret != "" {
+		t.Errorf("Expect subscription secret is empty after copy")
+	}
+}
@@ -0,0 +1,159 @@
+// Copyright 2019 Drone IO, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package core
+
+import (
+	"context"
+	"sort"
+)
+
+// Test result status values.
+const (
+	TestPassed  = "passed"
+	TestFailed  = "failed"
+	TestSkipped = "skipped"
+)
+
+// FlakyThreshold defines the number of times a test must
+// flip between passed and failed before it is considered
+// flaky.
+const FlakyThreshold = 2
+
+type (
+	// TestResult represents the result of a single test case
+	// reported by a pipeline step.
+	TestResult struct {
+		ID       int64  `json:"id"`
+		RepoID   int64  `json:"repo_id"`
+		BuildID  int64  `json:"build_id"`
+		StageID  int64  `json:"stage_id"`
+		StepID   int64  `json:"step_id"`
+		Suite    string `json:"suite"`
+		Class    string `json:"class,omitempty"`
+		Name     string `json:"name"`
+		Status   string `json:"status"`
+		Duration int64  `json:"duration"` // milliseconds
+		Message  string `json:"message,omitempty"`
+		Created  int64  `json:"created"`
+	}
+
+	// TestSummary provides the number of passed, failed and
+	// skipped tests for a build.
+	TestSummary struct {
+		Total   int `json:"total"`
+		Passed  int `json:"passed"`
+		Failed  int `json:"failed"`
+		Skipped int `json:"skipped"`
+	}
+
+	// FlakyTest represents a test whose status flips between
+	// passed and failed across builds.
+	FlakyTest struct {
+		Suite    string `json:"suite"`
+		Class    string `json:"class,omitempty"`
+		Name     string `json:"name"`
+		Runs     int    `json:"runs"`
+		Failures int    `json:"failures"`
+		Flips    int    `json:"flips"`
+		Status   string `json:"status"`
+	}
+
+	// TestStore persists test results to storage.
+	TestStore interface {
+		// List returns the test results of the build from
+		// the datastore.
+		List(ctx context.Context, build int64) ([]*TestResult, error)
+
+		// Summary returns the test summary of the build from
+		// the datastore.
+		Summary(ctx context.Context, build int64) (*TestSummary, error)
+
+		// History returns the test results of the most recent
+		// builds of the repository branch, limited to n builds,
+		// in ascending build order.
+		History(ctx context.Context, repo int64, branch string, n int) ([]*TestResult, error)
+
+		// Create persists new test results to the datastore.
+		Create(context.Context, []*TestResult) error
+
+		// DeleteStage purges the test results of the build
+		// stage from the datastore.
+		DeleteStage(ctx context.Context, build, stage int64) error
+	}
+)
+
+// Key returns a key that uniquely identifies the test case
+// across builds.
+func (t *TestResult) Key() string {
+	return t.Suite + "\x00" + t.Class + "\x00" + t.Name
+}
+
+// Add adds the test result status to the summary.
+func (s *TestSummary) Add(status string, count int) {
+	switch status {
+	case TestPassed:
+		s.Passed += count
+	case TestFailed:
+		s.Failed += count
+	case TestSkipped:
+		s.Skipped += count
+	default:
+		return
+	}
+	s.Total += count
+}
+
+// FindFlakyTests returns the tests whose status flips between
+// passed and failed at least FlakyThreshold times. The results
+// must be provided in ascending build order. Skipped results
+// are ignored.
+func FindFlakyTests(results []*TestResult) []*FlakyTest {
+	var keys []string
+	tests := map[string]*FlakyTest{}
+	for _, result := range results {
+		if result.Status == TestSkipped {
+			continue
+		}
+		key := result.Key()
+		test, ok := tests[key]
+		if !ok {
+			test = &FlakyTest{
+				Suite: result.Suite,
+				Class: result.Class,
+				Name:  result.Name,
+			}
+			tests[key] = test
+			keys = append(keys, key)
+		} else if test.Status != result.Status {
+			test.Flips++
+		}
+		if result.Status == TestFailed {
+			test.Failures++
+		}
+		test.Runs++
+		test.Status = result.Status
+	}
+
+	out := []*FlakyTest{}
+	for _, key := range keys {
+		if test := tests[key]; test.Flips >= FlakyThreshold {
+			out = append(out, test)
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		return out[i].Flips > out[j].Flips
+	})
+	return out
+}
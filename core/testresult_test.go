@@ -0,0 +1,46 @@
+// Copyright 2019 Drone.IO Inc. All rights reserved.
+// Use of this source code is governed by the Drone Non-Commercial License
+// that can be found in the LICENSE file.
+
+// +build !oss
+
+package core
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestFindFlakyTests(t *testing.T) {
+	results := []*TestResult{
+		{BuildID: 1, Suite: "api", Name: "TestFlaky", Status: TestPassed},
+		{BuildID: 1, Suite: "api", Name: "TestBroken", Status: TestPassed},
+		{BuildID: 1, Suite: "api", Name: "TestStable", Status: TestPassed},
+		{BuildID: 2, Suite: "api", Name: "TestFlaky", Status: TestFailed},
+		{BuildID: 2, Suite: "api", Name: "TestBroken", Status: TestFailed},
+		{BuildID: 2, Suite: "api", Name: "TestStable", Status: TestSkipped},
+		{BuildID: 3, Suite: "api", Name: "TestFlaky", Status: TestPassed},
+		{BuildID: 3, Suite: "api", Name: "TestBroken", Status: TestFailed},
+		{BuildID: 3, Suite: "api", Name: "TestStable", Status: TestPassed},
+	}
+	got := FindFlakyTests(results)
+	want := []*FlakyTest{
+		{Suite: "api", Name: "TestFlaky", Runs: 3, Failures: 1, Flips: 2, Status: TestPassed},
+	}
+	if diff := cmp.Diff(got, want); diff != "" {
+		t.Errorf(diff)
+	}
+}
+
+func TestTestSummaryAdd(t *testing.T) {
+	summary := new(TestSummary)
+	summary.Add(TestPassed, 3)
+	summary.Add(TestFailed, 2)
+	summary.Add(TestSkipped, 1)
+	summary.Add("unknown", 5)
+	want := &TestSummary{Total: 6, Passed: 3, Failed: 2, Skipped: 1}
+	if diff := cmp.Diff(summary, want); diff != "" {
+		t.Errorf(diff)
+	}
+}
@@ -0,0 +1,138 @@
+// Copyright 2019 Drone IO, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package core
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strings"
+)
+
+// TokenPrefix is prepended to personal access tokens so that
+// they can be distinguished from legacy user tokens.
+const TokenPrefix = "drone_pat_"
+
+// Personal access token scopes.
+const (
+	ScopeRead         = "read"
+	ScopeBuildsWrite  = "builds:write"
+	ScopeSecretsWrite = "secrets:write"
+	ScopeAdmin        = "admin"
+)
+
+var (
+	errTokenNameInvalid  = errors.New("Invalid Token Name")
+	errTokenScopeInvalid = errors.New("Invalid Token Scope")
+	errTokenScopeMissing = errors.New("Token Scope is required")
+)
+
+type (
+	// AccessToken represents a named personal access token
+	// used to authenticate api requests on behalf of a user.
+	AccessToken struct {
+		ID       int64    `json:"id"`
+		UserID   int64    `json:"user_id"`
+		RepoID   int64    `json:"repo_id,omitempty"`
+		Name     string   `json:"name"`
+		Scopes   []string `json:"scopes"`
+		Hash     string   `json:"-"`
+		Expires  int64    `json:"expires,omitempty"`
+		LastUsed int64    `json:"last_used,omitempty"`
+		Created  int64    `json:"created"`
+
+		// Token is the plaintext token value. It is only
+		// populated when the token is created, and is never
+		// persisted to the datastore.
+		Token string `json:"token,omitempty"`
+	}
+
+	// AccessTokenStore persists personal access tokens to
+	// storage.
+	AccessTokenStore interface {
+		// List returns a list of tokens from the datastore
+		// by user id.
+		List(ctx context.Context, user int64) ([]*AccessToken, error)
+
+		// Find returns a token from the datastore.
+		Find(context.Context, int64) (*AccessToken, error)
+
+		// FindHash returns a token from the datastore by
+		// token hash.
+		FindHash(context.Context, string) (*AccessToken, error)
+
+		// Create persists a new token to the datastore.
+		Create(context.Context, *AccessToken) error
+
+		// Touch updates the token last used timestamp.
+		Touch(ctx context.Context, id, used int64) error
+
+		// Delete deletes a token from the datastore.
+		Delete(context.Context, *AccessToken) error
+	}
+)
+
+// Validate validates the token and returns an error if the
+// validation fails.
+func (t *AccessToken) Validate() error {
+	if name := strings.TrimSpace(t.Name); name == "" || len(name) > 100 {
+		return errTokenNameInvalid
+	}
+	if len(t.Scopes) == 0 {
+		return errTokenScopeMissing
+	}
+	for _, scope := range t.Scopes {
+		switch scope {
+		case ScopeRead,
+			ScopeBuildsWrite,
+			ScopeSecretsWrite,
+			ScopeAdmin:
+		default:
+			return errTokenScopeInvalid
+		}
+	}
+	return nil
+}
+
+// HasScope returns true if the token grants the named scope.
+// The admin scope grants all scopes, and every scope grants
+// read access.
+func (t *AccessToken) HasScope(scope string) bool {
+	for _, s := range t.Scopes {
+		switch {
+		case s == scope:
+			return true
+		case s == ScopeAdmin:
+			return true
+		case scope == ScopeRead:
+			return true
+		}
+	}
+	return false
+}
+
+// Expired returns true if the token is expired at the given
+// unix timestamp. A token without an expiry never expires.
+func (t *AccessToken) Expired(now int64) bool {
+	return t.Expires != 0 && t.Expires <= now
+}
+
+// HashToken returns the hash of the plaintext token value
+// used to lookup the token in the datastore.
+func HashToken(token string) string {
+	h := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(h[:])
+}
@@ -0,0 +1,63 @@
+// Copyright 2019 Drone.IO Inc. All rights reserved.
+// Use of this source code is governed by the Drone Non-Commercial License
+// that can be found in the LICENSE file.
+
+// +build !oss
+
+package core
+
+import "testing"
+
+func TestAccessTokenValidate(t *testing.T) {
+	tests := []struct {
+		token *AccessToken
+		err   error
+	}{
+		{&AccessToken{Name: "ci", Scopes: []string{ScopeRead}}, nil},
+		{&AccessToken{Name: "ci", Scopes: []string{ScopeBuildsWrite, ScopeSecretsWrite}}, nil},
+		{&AccessToken{Name: "", Scopes: []string{ScopeRead}}, errTokenNameInvalid},
+		{&AccessToken{Name: "ci"}, errTokenScopeMissing},
+		{&AccessToken{Name: "ci", Scopes: []string{"repo"}}, errTokenScopeInvalid},
+	}
+	for i, test := range tests {
+		if got, want := test.token.Validate(), test.err; got != want {
+			t.Errorf("Want error %v at index %d, got %v", want, i, got)
+		}
+	}
+}
+
+func TestAccessTokenHasScope(t *testing.T) {
+	tests := []struct {
+		scopes []string
+		scope  string
+		want   bool
+	}{
+		{[]string{ScopeRead}, ScopeRead, true},
+		{[]string{ScopeRead}, ScopeBuildsWrite, false},
+		{[]string{ScopeBuildsWrite}, ScopeRead, true},
+		{[]string{ScopeBuildsWrite}, ScopeBuildsWrite, true},
+		{[]string{ScopeBuildsWrite}, ScopeSecretsWrite, false},
+		{[]string{ScopeBuildsWrite}, ScopeAdmin, false},
+		{[]string{ScopeAdmin}, ScopeSecretsWrite, true},
+		{[]string{ScopeAdmin}, ScopeAdmin, true},
+		{nil, ScopeRead, false},
+	}
+	for i, test := range tests {
+		token := &AccessToken{Scopes: test.scopes}
+		if got, want := token.HasScope(test.scope), test.want; got != want {
+			t.Errorf("Want HasScope %v at index %d, got %v", want, i, got)
+		}
+	}
+}
+
+func TestAccessTokenExpired(t *testing.T) {
+	if (&AccessToken{}).Expired(1522878684) {
+		t.Errorf("Want token without expiry to never expire")
+	}
+	if (&AccessToken{Expires: 1522878685}).Expired(1522878684) {
+		t.Errorf("Want token not expired")
+	}
+	if !(&AccessToken{Expires: 1522878684}).Expired(1522878684) {
+		t.Errorf("Want token expired")
+	}
+}
@@ -27,4 +27,30 @@ const (
 // returned.
 type Triggerer interface {
 	Trigger(context.Context, *Repository, *Hook) (*Build, error)
+
+	// Evaluate evaluates the pipeline configuration for the
+	// hook without creating a build. If the config is nil the
+	// configuration is fetched using the configuration service.
+	Evaluate(context.Context, *Repository, *Hook, *Config) (*Evaluation, error)
 }
+
+type (
+	// Evaluation represents the result of a dry-run
+	// evaluation of the pipeline configuration.
+	Evaluation struct {
+		Config    string                `json:"config,omitempty"`
+		Verified  bool                  `json:"verified"`
+		Skipped   string                `json:"skipped,omitempty"`
+		Pipelines []*PipelineEvaluation `json:"pipelines"`
+		Errors    []string              `json:"errors,omitempty"`
+	}
+
+	// PipelineEvaluation represents the evaluation result
+	// of an individual pipeline.
+	PipelineEvaluation struct {
+		Name      string   `json:"name"`
+		Matched   bool     `json:"matched"`
+		Reason    string   `json:"reason,omitempty"`
+		DependsOn []string `json:"depends_on,omitempty"`
+	}
+)
@@ -45,6 +45,11 @@ type (
 		Refresh   string `json:"-"`
 		Expiry    int64  `json:"-"`
 		Hash      string `json:"-"`
+
+		// AccessToken is the personal access token used to
+		// authenticate the request, if any. It is populated
+		// by the session and is never persisted.
+		AccessToken *AccessToken `json:"-"`
 	}
 
 	// UserParams defines user query parameters.
@@ -65,6 +70,9 @@ type (
 		// FindLogin returns a user from the datastore by username.
 		FindLogin(context.Context, string) (*User, error)
 
+		// FindEmail returns a user from the datastore by email.
+		FindEmail(context.Context, string) (*User, error)
+
 		// FindToken returns a user from the datastore by token.
 		FindToken(context.Context, string) (*User, error)
 
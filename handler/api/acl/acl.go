@@ -52,6 +52,10 @@ func AuthorizeAdmin(next http.Handler) http.Handler {
 			render.Forbidden(w, errors.ErrForbidden)
 			logger.FromRequest(r).
 				Debugln("api: administrative access required")
+		} else if missingAdminScope(user) || isRepoScoped(user) {
+			render.Forbidden(w, errors.ErrForbidden)
+			logger.FromRequest(r).
+				Debugln("api: token admin scope required")
 		} else {
 			next.ServeHTTP(w, r)
 		}
@@ -73,6 +73,10 @@ func CheckAccess(read, write, admin bool) func(http.Handler) http.Handler {
 				render.Unauthorized(w, errors.ErrUnauthorized)
 				log.Debugln("api: authentication required for admin access")
 				return
+			case ok == true && admin == true && missingAdminScope(user):
+				render.Forbidden(w, errors.ErrForbidden)
+				log.Debugln("api: token admin scope required")
+				return
 			case ok == true && user.Admin == true:
 				log.Debugln("api: root access granted")
 				next.ServeHTTP(w, r)
@@ -590,6 +590,41 @@ func TestCheckAdminAccess_SystemAdmin(t *testing.T) {
 	}
 }
 
+// this test verifies that a 403 forbidden error is written to
+// the response if the client is authenticated with a personal
+// access token that does not grant the admin scope.
+func TestCheckAdminAccess_TokenScope(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	user := &core.User{
+		ID:          1,
+		Admin:       true,
+		Active:      true,
+		AccessToken: &core.AccessToken{Scopes: []string{core.ScopeBuildsWrite}},
+	}
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("GET", "/api/repos/octocat/hello-world", nil)
+	r = r.WithContext(
+		request.WithUser(r.Context(), user),
+	)
+
+	router := chi.NewRouter()
+	router.Route("/api/repos/{owner}/{name}", func(router chi.Router) {
+		router.Use(CheckAdminAccess())
+		router.Get("/", func(w http.ResponseWriter, r *http.Request) {
+			t.Errorf("Must not invoke next handler in middleware chain")
+		})
+	})
+
+	router.ServeHTTP(w, r)
+
+	if got, want := w.Code, http.StatusForbidden; got != want {
+		t.Errorf("Want status code %d, got %d", want, got)
+	}
+}
+
 // this test verifies that a 401 unauthorized error is written to
 // the response if the client is not authenticated and write
 // access is required.
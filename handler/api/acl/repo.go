@@ -29,11 +29,14 @@ import (
 )
 
 // InjectRepository returns an http.Handler middleware that injects
-// the repository and repository permissions into the context.
+// the repository and repository permissions into the context. Users
+// without repository permissions are granted read access if their
+// identity is a member of the repository organization.
 func InjectRepository(
 	repoz core.RepositoryService,
 	repos core.RepositoryStore,
 	perms core.PermStore,
+	identities core.IdentityStore,
 ) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -66,6 +69,14 @@ func InjectRepository(
 				return
 			}
 
+			// a personal access token restricted to a single
+			// repository cannot access any other repository.
+			if sessionExists && missingRepoScope(user, repo) {
+				render.NotFound(w, errors.ErrNotFound)
+				log.Debugln("api: token not permitted to access repository")
+				return
+			}
+
 			// the repository is stored in the request context
 			// and can be accessed by subsequent handlers in the
 			// request chain.
@@ -83,6 +94,27 @@ func InjectRepository(
 			// for the user and repository.
 			perm, err := perms.Find(ctx, repo.UID, user.ID)
 			if err != nil {
+				// if the user identity, provided by the identity
+				// provider, is a member of the organization the
+				// user is granted read access to the repository.
+				// This grants read access to every private and
+				// internal repository in the organization, since
+				// the organization membership is mapped from the
+				// identity claims configured by the administrator.
+				// Public repositories are readable without
+				// permissions, and are not granted access.
+				if identities != nil && repo.Visibility != core.VisibilityPublic {
+					identity, err := identities.FindUser(ctx, user.ID)
+					if err == nil && identity.Member(repo.Namespace) {
+						ctx = request.WithPerm(ctx, &core.Perm{
+							UserID:  user.ID,
+							RepoUID: repo.UID,
+							Read:    true,
+						})
+						log.Debugln("api: read access granted by identity organization")
+					}
+				}
+
 				// if the permissions are not found we forward
 				// the request to the next handler in the chain
 				// with no permissions in the context.
@@ -104,8 +136,9 @@ func InjectRepository(
 
 			// because the permissions are synced with the remote
 			// system (e.g. github) they may be stale. If the permissions
-			// are stale they are refreshed below.
-			if perm.Synced == 0 || time.Unix(perm.Synced, 0).Add(time.Hour).Before(time.Now()) {
+			// are stale they are refreshed below. Users that are not
+			// linked to the remote system cannot be refreshed.
+			if user.Token != "" && (perm.Synced == 0 || time.Unix(perm.Synced, 0).Add(time.Hour).Before(time.Now())) {
 				log.Debugln("api: sync repository permissions")
 
 				permv, err := repoz.FindPerm(ctx, user, repo.Slug)
@@ -12,9 +12,9 @@ import (
 	"testing"
 	"time"
 
+	"github.com/drone/drone/core"
 	"github.com/drone/drone/handler/api/request"
 	"github.com/drone/drone/mock"
-	"github.com/drone/drone/core"
 
 	"github.com/go-chi/chi"
 	"github.com/golang/mock/gomock"
@@ -44,7 +44,7 @@ func TestInjectRepository_RepoNotFound_Guest(t *testing.T) {
 		t.Fail()
 	})
 
-	InjectRepository(nil, repos, nil)(next).ServeHTTP(w, r)
+	InjectRepository(nil, repos, nil, nil)(next).ServeHTTP(w, r)
 	if got, want := w.Code, http.StatusUnauthorized; want != got {
 		t.Errorf("Want response code %d, got %d", want, got)
 	}
@@ -76,7 +76,7 @@ func TestInjectRepository_RepoNotFound_User(t *testing.T) {
 		t.Fail()
 	})
 
-	InjectRepository(nil, repos, nil)(next).ServeHTTP(w, r)
+	InjectRepository(nil, repos, nil, nil)(next).ServeHTTP(w, r)
 	if got, want := w.Code, 404; want != got {
 		t.Errorf("Want response code %d, got %d", want, got)
 	}
@@ -109,7 +109,7 @@ func TestInjectRepository_RepoFound_Guest(t *testing.T) {
 		invoked = true
 	})
 
-	InjectRepository(nil, repos, nil)(next).ServeHTTP(w, r)
+	InjectRepository(nil, repos, nil, nil)(next).ServeHTTP(w, r)
 	if !invoked {
 		t.Errorf("Expect middleware invoked")
 	}
@@ -153,7 +153,7 @@ func TestInjectRepository_PermsFound(t *testing.T) {
 		}
 	})
 
-	InjectRepository(nil, repos, perms)(next).ServeHTTP(w, r)
+	InjectRepository(nil, repos, perms, nil)(next).ServeHTTP(w, r)
 	if !invoked {
 		t.Errorf("Expect middleware invoked")
 	}
@@ -198,8 +198,140 @@ func TestInjectRepository_PermsNotFound(t *testing.T) {
 		}
 	})
 
-	InjectRepository(nil, repos, perms)(next).ServeHTTP(w, r)
+	InjectRepository(nil, repos, perms, nil)(next).ServeHTTP(w, r)
+	if !invoked {
+		t.Errorf("Expect middleware invoked")
+	}
+}
+
+// this unit test ensures that a user without repository
+// permissions is granted read access to a private repository
+// if the user identity is a member of the repository
+// organization.
+func TestInjectRepository_IdentityOrg(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	mockUser := &core.User{ID: 1}
+	mockRepo := &core.Repository{UID: "1", Namespace: "octocat", Private: true, Visibility: core.VisibilityPrivate}
+	mockIdentity := &core.Identity{UserID: 1, Orgs: []string{"OctoCat"}}
+
+	repos := mock.NewMockRepositoryStore(controller)
+	repos.EXPECT().FindName(gomock.Any(), "octocat", "hello-world").Return(mockRepo, nil)
+
+	perms := mock.NewMockPermStore(controller)
+	perms.EXPECT().Find(gomock.Any(), mockRepo.UID, mockUser.ID).Return(nil, sql.ErrNoRows)
+
+	identities := mock.NewMockIdentityStore(controller)
+	identities.EXPECT().FindUser(gomock.Any(), mockUser.ID).Return(mockIdentity, nil)
+
+	c := new(chi.Context)
+	c.URLParams.Add("owner", "octocat")
+	c.URLParams.Add("name", "hello-world")
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("GET", "/", nil)
+	r = r.WithContext(
+		context.WithValue(
+			request.WithUser(r.Context(), mockUser),
+			chi.RouteCtxKey, c),
+	)
+
+	invoked := false
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		invoked = true
+		perm, ok := request.PermFrom(r.Context())
+		if !ok {
+			t.Errorf("Expect perm from context")
+		} else if !perm.Read || perm.Write || perm.Admin {
+			t.Errorf("Expect read-only perm from context")
+		}
+	})
+
+	InjectRepository(nil, repos, perms, identities)(next).ServeHTTP(w, r)
 	if !invoked {
 		t.Errorf("Expect middleware invoked")
 	}
 }
+
+// this unit test ensures that a user without repository
+// permissions is not granted permissions to a public
+// repository by the user identity organization.
+func TestInjectRepository_IdentityOrgPublic(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	mockUser := &core.User{ID: 1}
+	mockRepo := &core.Repository{UID: "1", Namespace: "octocat", Visibility: core.VisibilityPublic}
+
+	repos := mock.NewMockRepositoryStore(controller)
+	repos.EXPECT().FindName(gomock.Any(), "octocat", "hello-world").Return(mockRepo, nil)
+
+	perms := mock.NewMockPermStore(controller)
+	perms.EXPECT().Find(gomock.Any(), mockRepo.UID, mockUser.ID).Return(nil, sql.ErrNoRows)
+
+	// the identity store is not expected to be called,
+	// since public repositories are readable without
+	// permissions.
+	identities := mock.NewMockIdentityStore(controller)
+
+	c := new(chi.Context)
+	c.URLParams.Add("owner", "octocat")
+	c.URLParams.Add("name", "hello-world")
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("GET", "/", nil)
+	r = r.WithContext(
+		context.WithValue(
+			request.WithUser(r.Context(), mockUser),
+			chi.RouteCtxKey, c),
+	)
+
+	invoked := false
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		invoked = true
+		if _, ok := request.PermFrom(r.Context()); ok {
+			t.Errorf("Expect no perm from context")
+		}
+	})
+
+	InjectRepository(nil, repos, perms, identities)(next).ServeHTTP(w, r)
+	if !invoked {
+		t.Errorf("Expect middleware invoked")
+	}
+}
+
+// this unit test ensures that a personal access token
+// restricted to a different repository is rejected with
+// a 404 not found error.
+func TestInjectRepository_TokenRepoScope(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	mockUser := &core.User{ID: 1, AccessToken: &core.AccessToken{RepoID: 2}}
+	mockRepo := &core.Repository{ID: 1, UID: "1"}
+
+	repos := mock.NewMockRepositoryStore(controller)
+	repos.EXPECT().FindName(gomock.Any(), "octocat", "hello-world").Return(mockRepo, nil)
+
+	c := new(chi.Context)
+	c.URLParams.Add("owner", "octocat")
+	c.URLParams.Add("name", "hello-world")
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("GET", "/", nil)
+	r = r.WithContext(
+		context.WithValue(
+			request.WithUser(r.Context(), mockUser),
+			chi.RouteCtxKey, c),
+	)
+
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("Must not invoke next handler in middleware chain")
+	})
+
+	InjectRepository(nil, repos, nil, nil)(next).ServeHTTP(w, r)
+	if got, want := w.Code, http.StatusNotFound; got != want {
+		t.Errorf("Want status code %d, got %d", want, got)
+	}
+}
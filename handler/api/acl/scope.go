@@ -0,0 +1,148 @@
+// Copyright 2019 Drone IO, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package acl
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/drone/drone/core"
+	"github.com/drone/drone/handler/api/errors"
+	"github.com/drone/drone/handler/api/render"
+	"github.com/drone/drone/handler/api/request"
+	"github.com/drone/drone/logger"
+
+	"github.com/go-chi/chi"
+)
+
+// CheckScope returns an http.Handler middleware that authorizes
+// requests authenticated with a personal access token only if
+// the token grants the scope required by the request. Tokens
+// restricted to a single repository are further limited to
+// repository endpoints. Requests authenticated with a session
+// cookie or legacy user token are not restricted.
+func CheckScope(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, ok := request.UserFrom(r.Context())
+		if !ok || user.AccessToken == nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+		scope := requiredScope(r)
+		if !user.AccessToken.HasScope(scope) {
+			render.Forbidden(w, errors.ErrForbidden)
+			logger.FromRequest(r).
+				WithField("token.scope", scope).
+				Debugln("api: token scope required")
+			return
+		}
+		// a personal access token restricted to a single
+		// repository cannot access endpoints that are not
+		// scoped to a repository, such as the user repository
+		// list, the global event stream or log search.
+		if isRepoScoped(user) && !isRepoRoute(r) {
+			render.Forbidden(w, errors.ErrForbidden)
+			logger.FromRequest(r).
+				Debugln("api: token restricted to a single repository")
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// helper function returns true if the request is authenticated
+// with a personal access token that does not grant the admin
+// scope.
+func missingAdminScope(user *core.User) bool {
+	return user.AccessToken != nil &&
+		!user.AccessToken.HasScope(core.ScopeAdmin)
+}
+
+// helper function returns true if the request is authenticated
+// with a personal access token that is restricted to a single
+// repository.
+func isRepoScoped(user *core.User) bool {
+	return user.AccessToken != nil &&
+		user.AccessToken.RepoID != 0
+}
+
+// helper function returns true if the request is authenticated
+// with a personal access token that is restricted to a single
+// repository other than the given repository.
+func missingRepoScope(user *core.User, repo *core.Repository) bool {
+	return isRepoScoped(user) &&
+		user.AccessToken.RepoID != repo.ID
+}
+
+// helper function returns the token scope required to process
+// the request. Read requests only require the read scope. Write
+// requests to repository builds and secrets require the builds
+// and secrets scopes respectively. All other write requests
+// require the admin scope.
+func requiredScope(r *http.Request) string {
+	switch r.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return core.ScopeRead
+	}
+	parts := routeParts(r)
+	// global secrets are routed to the path
+	// /secrets/{namespace}
+	if parts[0] == "secrets" {
+		return core.ScopeSecretsWrite
+	}
+	// repository resources are routed to the path
+	// /repos/{owner}/{name}/{resource}, and environment
+	// secrets to the path
+	// /repos/{owner}/{name}/environments/{environment}/secrets
+	if len(parts) > 3 && parts[0] == "repos" {
+		switch {
+		case parts[3] == "secrets":
+			return core.ScopeSecretsWrite
+		case parts[3] == "environments" && len(parts) > 5 && parts[5] == "secrets":
+			return core.ScopeSecretsWrite
+		case parts[3] == "builds":
+			return core.ScopeBuildsWrite
+		}
+	}
+	return core.ScopeAdmin
+}
+
+// helper function returns true if the request is routed to a
+// repository endpoint. Repository endpoints are routed to the
+// paths /repos/{owner}/{name}, /stream/{owner}/{name} and
+// /badges/{owner}/{name}, and verify the repository access of
+// the token when the repository is loaded.
+func isRepoRoute(r *http.Request) bool {
+	parts := routeParts(r)
+	if len(parts) < 3 {
+		return false
+	}
+	switch parts[0] {
+	case "repos", "stream", "badges":
+		return true
+	default:
+		return false
+	}
+}
+
+// helper function returns the request path segments, relative
+// to the router that mounts the middleware.
+func routeParts(r *http.Request) []string {
+	path := r.URL.Path
+	if rctx, ok := r.Context().Value(chi.RouteCtxKey).(*chi.Context); ok && rctx.RoutePath != "" {
+		path = rctx.RoutePath
+	}
+	return strings.Split(strings.Trim(path, "/"), "/")
+}
@@ -0,0 +1,156 @@
+// Copyright 2019 Drone.IO Inc. All rights reserved.
+// Use of this source code is governed by the Drone Non-Commercial License
+// that can be found in the LICENSE file.
+
+package acl
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/drone/drone/core"
+	"github.com/drone/drone/handler/api/request"
+)
+
+func TestCheckScope(t *testing.T) {
+	tests := []struct {
+		method string
+		path   string
+		scopes []string
+		want   int
+	}{
+		{"GET", "/repos/octocat/hello-world", []string{core.ScopeRead}, http.StatusTeapot},
+		{"POST", "/repos/octocat/hello-world/builds", []string{core.ScopeRead}, http.StatusForbidden},
+		{"POST", "/repos/octocat/hello-world/builds", []string{core.ScopeBuildsWrite}, http.StatusTeapot},
+		{"DELETE", "/repos/octocat/hello-world/builds/1", []string{core.ScopeSecretsWrite}, http.StatusForbidden},
+		{"POST", "/repos/octocat/hello-world/secrets", []string{core.ScopeBuildsWrite}, http.StatusForbidden},
+		{"POST", "/repos/octocat/hello-world/secrets", []string{core.ScopeSecretsWrite}, http.StatusTeapot},
+		{"PATCH", "/repos/octocat/hello-world/environments/production/secrets/password", []string{core.ScopeSecretsWrite}, http.StatusTeapot},
+		{"PATCH", "/repos/octocat/hello-world", []string{core.ScopeBuildsWrite, core.ScopeSecretsWrite}, http.StatusForbidden},
+		{"PATCH", "/repos/octocat/hello-world", []string{core.ScopeAdmin}, http.StatusTeapot},
+		{"POST", "/user/repos", []string{core.ScopeBuildsWrite}, http.StatusForbidden},
+		{"POST", "/repos/acme/secrets/builds", []string{core.ScopeBuildsWrite}, http.StatusTeapot},
+		{"PATCH", "/repos/secrets/hello-world", []string{core.ScopeSecretsWrite}, http.StatusForbidden},
+		{"POST", "/repos/octocat/hello-world/environments/secrets", []string{core.ScopeSecretsWrite}, http.StatusForbidden},
+		{"POST", "/secrets/octocat", []string{core.ScopeSecretsWrite}, http.StatusTeapot},
+		{"POST", "/secrets/octocat", []string{core.ScopeBuildsWrite}, http.StatusForbidden},
+	}
+	for i, test := range tests {
+		user := &core.User{
+			ID:          1,
+			Login:       "octocat",
+			AccessToken: &core.AccessToken{Scopes: test.scopes},
+		}
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(test.method, test.path, nil)
+		r = r.WithContext(
+			request.WithUser(r.Context(), user),
+		)
+
+		CheckScope(
+			http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusTeapot)
+			}),
+		).ServeHTTP(w, r)
+
+		if got, want := w.Code, test.want; got != want {
+			t.Errorf("Want status code %d at index %d, got %d", want, i, got)
+		}
+	}
+}
+
+// this test verifies that a token restricted to a single
+// repository cannot access endpoints that are not scoped to
+// a repository.
+func TestCheckScope_RepoScoped(t *testing.T) {
+	tests := []struct {
+		path string
+		want int
+	}{
+		{"/repos/octocat/hello-world", http.StatusTeapot},
+		{"/repos/octocat/hello-world/builds/1", http.StatusTeapot},
+		{"/stream/octocat/hello-world", http.StatusTeapot},
+		{"/repos", http.StatusForbidden},
+		{"/user/repos", http.StatusForbidden},
+		{"/user/builds", http.StatusForbidden},
+		{"/search/logs", http.StatusForbidden},
+		{"/stream", http.StatusForbidden},
+	}
+	for i, test := range tests {
+		user := &core.User{
+			ID:          1,
+			Login:       "octocat",
+			AccessToken: &core.AccessToken{Scopes: []string{core.ScopeRead}, RepoID: 1},
+		}
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest("GET", test.path, nil)
+		r = r.WithContext(
+			request.WithUser(r.Context(), user),
+		)
+
+		CheckScope(
+			http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusTeapot)
+			}),
+		).ServeHTTP(w, r)
+
+		if got, want := w.Code, test.want; got != want {
+			t.Errorf("Want status code %d at index %d, got %d", want, i, got)
+		}
+	}
+}
+
+// this test verifies that requests authenticated with a
+// session cookie or legacy token are not restricted.
+func TestCheckScope_NoToken(t *testing.T) {
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("DELETE", "/repos/octocat/hello-world", nil)
+	r = r.WithContext(
+		request.WithUser(r.Context(), mockUser),
+	)
+
+	CheckScope(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusTeapot)
+		}),
+	).ServeHTTP(w, r)
+
+	if got, want := w.Code, http.StatusTeapot; got != want {
+		t.Errorf("Want status code %d, got %d", want, got)
+	}
+}
+
+// this test verifies that a system administrator cannot
+// access administrative endpoints with a token that does
+// not grant the admin scope.
+func TestAuthorizeAdmin_TokenScope(t *testing.T) {
+	tests := []struct {
+		token *core.AccessToken
+		want  int
+	}{
+		{&core.AccessToken{Scopes: []string{core.ScopeAdmin}}, http.StatusTeapot},
+		{&core.AccessToken{Scopes: []string{core.ScopeRead}}, http.StatusForbidden},
+		{&core.AccessToken{Scopes: []string{core.ScopeAdmin}, RepoID: 1}, http.StatusForbidden},
+	}
+	for i, test := range tests {
+		user := *mockUserAdmin
+		user.AccessToken = test.token
+
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest("GET", "/", nil)
+		r = r.WithContext(
+			request.WithUser(r.Context(), &user),
+		)
+
+		AuthorizeAdmin(
+			http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusTeapot)
+			}),
+		).ServeHTTP(w, r)
+
+		if got, want := w.Code, test.want; got != want {
+			t.Errorf("Want status code %d at index %d, got %d", want, i, got)
+		}
+	}
+}
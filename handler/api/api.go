@@ -25,25 +25,38 @@ import (
 	globalbuilds "github.com/drone/drone/handler/api/builds"
 	"github.com/drone/drone/handler/api/card"
 	"github.com/drone/drone/handler/api/ccmenu"
+	globalchannels "github.com/drone/drone/handler/api/channels"
+	"github.com/drone/drone/handler/api/deliveries"
 	"github.com/drone/drone/handler/api/events"
+	globaljournal "github.com/drone/drone/handler/api/journal"
 	"github.com/drone/drone/handler/api/queue"
 	"github.com/drone/drone/handler/api/repos"
 	"github.com/drone/drone/handler/api/repos/builds"
+	"github.com/drone/drone/handler/api/repos/builds/artifacts"
 	"github.com/drone/drone/handler/api/repos/builds/branches"
 	"github.com/drone/drone/handler/api/repos/builds/deploys"
 	"github.com/drone/drone/handler/api/repos/builds/logs"
 	"github.com/drone/drone/handler/api/repos/builds/pulls"
 	"github.com/drone/drone/handler/api/repos/builds/stages"
+	"github.com/drone/drone/handler/api/repos/channels"
 	"github.com/drone/drone/handler/api/repos/collabs"
 	"github.com/drone/drone/handler/api/repos/crons"
 	"github.com/drone/drone/handler/api/repos/encrypt"
+	"github.com/drone/drone/handler/api/repos/environments"
+	"github.com/drone/drone/handler/api/repos/evaluate"
+	"github.com/drone/drone/handler/api/repos/journal"
 	"github.com/drone/drone/handler/api/repos/secrets"
 	"github.com/drone/drone/handler/api/repos/sign"
+	"github.com/drone/drone/handler/api/repos/tests"
+	"github.com/drone/drone/handler/api/repos/webhooks"
+	"github.com/drone/drone/handler/api/runners"
+	"github.com/drone/drone/handler/api/search"
 	globalsecrets "github.com/drone/drone/handler/api/secrets"
 	"github.com/drone/drone/handler/api/system"
 	"github.com/drone/drone/handler/api/template"
 	"github.com/drone/drone/handler/api/user"
 	"github.com/drone/drone/handler/api/user/remote"
+	"github.com/drone/drone/handler/api/user/tokens"
 	"github.com/drone/drone/handler/api/users"
 	"github.com/drone/drone/logger"
 
@@ -62,20 +75,30 @@ var corsOpts = cors.Options{
 }
 
 func New(
+	approvals core.ApprovalStore,
+	artifacts core.ArtifactStore,
 	builds core.BuildStore,
 	commits core.CommitService,
 	card core.CardStore,
+	channels core.ChannelStore,
 	cron core.CronStore,
+	deliveries core.DeliveryStore,
+	environments core.EnvironmentStore,
 	events core.Pubsub,
 	globals core.GlobalSecretStore,
 	hooks core.HookService,
+	identities core.IdentityStore,
 	logs core.LogStore,
+	index core.LogIndex,
+	journal core.JournalStore,
 	license *core.License,
 	licenses core.LicenseService,
+	nodes core.NodeStore,
 	orgs core.OrganizationService,
 	perms core.PermStore,
 	repos core.RepositoryStore,
 	repoz core.RepositoryService,
+	restarter core.Restarter,
 	scheduler core.Scheduler,
 	secrets core.SecretStore,
 	stages core.StageStore,
@@ -83,9 +106,12 @@ func New(
 	status core.StatusService,
 	session core.Session,
 	stream core.LogStream,
+	subscriptions core.SubscriptionStore,
 	syncer core.Syncer,
 	system *core.System,
 	template core.TemplateStore,
+	tests core.TestStore,
+	tokens core.AccessTokenStore,
 	transferer core.Transferer,
 	triggerer core.Triggerer,
 	users core.UserStore,
@@ -93,70 +119,96 @@ func New(
 	webhook core.WebhookSender,
 ) Server {
 	return Server{
-		Builds:     builds,
-		Card:       card,
-		Cron:       cron,
-		Commits:    commits,
-		Events:     events,
-		Globals:    globals,
-		Hooks:      hooks,
-		Logs:       logs,
-		License:    license,
-		Licenses:   licenses,
-		Orgs:       orgs,
-		Perms:      perms,
-		Repos:      repos,
-		Repoz:      repoz,
-		Scheduler:  scheduler,
-		Secrets:    secrets,
-		Stages:     stages,
-		Steps:      steps,
-		Status:     status,
-		Session:    session,
-		Stream:     stream,
-		Syncer:     syncer,
-		System:     system,
-		Template:   template,
-		Transferer: transferer,
-		Triggerer:  triggerer,
-		Users:      users,
-		Userz:      userz,
-		Webhook:    webhook,
+		Approvals:     approvals,
+		Artifacts:     artifacts,
+		Builds:        builds,
+		Card:          card,
+		Channels:      channels,
+		Cron:          cron,
+		Commits:       commits,
+		Deliveries:    deliveries,
+		Environments:  environments,
+		Events:        events,
+		Globals:       globals,
+		Hooks:         hooks,
+		Identities:    identities,
+		Logs:          logs,
+		LogIndex:      index,
+		Journal:       journal,
+		License:       license,
+		Licenses:      licenses,
+		Nodes:         nodes,
+		Orgs:          orgs,
+		Perms:         perms,
+		Repos:         repos,
+		Repoz:         repoz,
+		Restarter:     restarter,
+		Scheduler:     scheduler,
+		Secrets:       secrets,
+		Stages:        stages,
+		Steps:         steps,
+		Status:        status,
+		Session:       session,
+		Stream:        stream,
+		Subscriptions: subscriptions,
+		Syncer:        syncer,
+		System:        system,
+		Template:      template,
+		Tests:         tests,
+		Tokens:        tokens,
+		Transferer:    transferer,
+		Triggerer:     triggerer,
+		Users:         users,
+		Userz:         userz,
+		Webhook:       webhook,
 	}
 }
 
 // Server is a http.Handler which exposes drone functionality over HTTP.
 type Server struct {
-	Builds     core.BuildStore
-	Card       core.CardStore
-	Cron       core.CronStore
-	Commits    core.CommitService
-	Events     core.Pubsub
-	Globals    core.GlobalSecretStore
-	Hooks      core.HookService
-	Logs       core.LogStore
-	License    *core.License
-	Licenses   core.LicenseService
-	Orgs       core.OrganizationService
-	Perms      core.PermStore
-	Repos      core.RepositoryStore
-	Repoz      core.RepositoryService
-	Scheduler  core.Scheduler
-	Secrets    core.SecretStore
-	Stages     core.StageStore
-	Steps      core.StepStore
-	Status     core.StatusService
-	Session    core.Session
-	Stream     core.LogStream
-	Syncer     core.Syncer
-	System     *core.System
-	Template   core.TemplateStore
-	Transferer core.Transferer
-	Triggerer  core.Triggerer
-	Users      core.UserStore
-	Userz      core.UserService
-	Webhook    core.WebhookSender
-	Private    bool
+	Approvals     core.ApprovalStore
+	Artifacts     core.ArtifactStore
+	Builds        core.BuildStore
+	Card          core.CardStore
+	Channels      core.ChannelStore
+	Cron          core.CronStore
+	Commits       core.CommitService
+	Deliveries    core.DeliveryStore
+	Environments  core.EnvironmentStore
+	Events        core.Pubsub
+	Globals       core.GlobalSecretStore
+	Hooks         core.HookService
+	Identities    core.IdentityStore
+	Logs          core.LogStore
+	LogIndex      core.LogIndex
+	Journal       core.JournalStore
+	License       *core.License
+	Licenses      core.LicenseService
+	Nodes         core.NodeStore
+	Orgs          core.OrganizationService
+	Perms         core.PermStore
+	Repos         core.RepositoryStore
+	Repoz         core.RepositoryService
+	Restarter     core.Restarter
+	Scheduler     core.Scheduler
+	Secrets       core.SecretStore
+	Stages        core.StageStore
+	Steps         core.StepStore
+	Status        core.StatusService
+	Session       core.Session
+	Stream        core.LogStream
+	Subscriptions core.SubscriptionStore
+	Syncer        core.Syncer
+	System        *core.System
+	Template      core.TemplateStore
+	Tests         core.TestStore
+	Tokens        core.AccessTokenStore
+	Transferer    core.Transferer
+	Triggerer     core.Triggerer
+	Users         core.UserStore
+	Userz         core.UserService
+	Webhook       core.WebhookSender
+	Private       bool
 }
 
 // Handler returns an http.Handler
@@ -166,6 +218,7 @@ func (s Server) Handler() http.Handler {
 	r.Use(middleware.NoCache)
 	r.Use(logger.Middleware)
 	r.Use(auth.HandleAuthentication(s.Session))
+	r.Use(acl.CheckScope)
 
 	cors := cors.New(corsOpts)
 	r.Use(cors.Handler)
@@ -182,7 +235,7 @@ func (s Server) Handler() http.Handler {
 		).Get("/", repos.HandleAll(s.Repos))
 
 		r.Route("/{owner}/{name}", func(r chi.Router) {
-			r.Use(acl.InjectRepository(s.Repoz, s.Repos, s.Perms))
+			r.Use(acl.InjectRepository(s.Repoz, s.Repos, s.Perms, s.Identities))
 			r.Use(acl.CheckReadAccess())
 
 			r.Get("/", repos.HandleFind())
@@ -215,43 +268,52 @@ func (s Server) Handler() http.Handler {
 				r.Get("/deployments", deploys.HandleList(s.Repos, s.Builds))
 				r.With(acl.CheckWriteAccess()).Delete("/deployments/*", deploys.HandleDelete(s.Repos, s.Builds))
 
-				r.Get("/latest", builds.HandleLast(s.Repos, s.Builds, s.Stages))
-				r.Get("/{number}", builds.HandleFind(s.Repos, s.Builds, s.Stages))
+				r.Get("/latest", builds.HandleLast(s.Repos, s.Builds, s.Stages, s.Approvals))
+				r.Get("/{number}", builds.HandleFind(s.Repos, s.Builds, s.Stages, s.Approvals, s.Tests))
 				r.Get("/{number}/logs/{stage}/{step}", logs.HandleFind(s.Repos, s.Builds, s.Stages, s.Steps, s.Logs))
+				r.Get("/{number}/artifacts", artifacts.HandleList(s.Repos, s.Builds, s.Artifacts))
+				r.Get("/{number}/artifacts/*", artifacts.HandleFind(s.Repos, s.Builds, s.Artifacts))
+				r.Get("/{number}/tests", tests.HandleList(s.Repos, s.Builds, s.Tests))
 
 				r.With(
 					acl.CheckWriteAccess(),
 				).Post("/{number}", builds.HandleRetry(s.Repos, s.Builds, s.Triggerer))
 
+				r.With(
+					acl.CheckWriteAccess(),
+				).Post("/{number}/restart", builds.HandleRestart(s.Repos, s.Builds, s.Restarter))
+
 				r.With(
 					acl.CheckWriteAccess(),
 				).Delete("/{number}", builds.HandleCancel(s.Users, s.Repos, s.Builds, s.Stages, s.Steps, s.Status, s.Scheduler, s.Webhook))
 
 				r.With(
 					acl.CheckWriteAccess(),
-				).Post("/{number}/promote", builds.HandlePromote(s.Repos, s.Builds, s.Triggerer))
+				).Post("/{number}/promote", builds.HandlePromote(s.Repos, s.Builds, s.Environments, s.Triggerer))
 
 				r.With(
 					acl.CheckWriteAccess(),
-				).Post("/{number}/rollback", builds.HandleRollback(s.Repos, s.Builds, s.Triggerer))
+				).Post("/{number}/rollback", builds.HandleRollback(s.Repos, s.Builds, s.Environments, s.Triggerer))
 
 				r.With(
 					acl.CheckAdminAccess(),
-				).Post("/{number}/decline/{stage}", stages.HandleDecline(s.Repos, s.Builds, s.Stages))
+				).Post("/{number}/decline/{stage}", stages.HandleDecline(s.Repos, s.Builds, s.Stages, s.Approvals, s.Orgs))
 
 				r.With(
 					acl.CheckAdminAccess(),
-				).Post("/{number}/approve/{stage}", stages.HandleApprove(s.Repos, s.Builds, s.Stages, s.Scheduler))
+				).Post("/{number}/approve/{stage}", stages.HandleApprove(s.Repos, s.Builds, s.Stages, s.Approvals, s.Orgs, s.Scheduler))
 
 				r.With(
 					acl.CheckAdminAccess(),
-				).Delete("/{number}/logs/{stage}/{step}", logs.HandleDelete(s.Repos, s.Builds, s.Stages, s.Steps, s.Logs))
+				).Delete("/{number}/logs/{stage}/{step}", logs.HandleDelete(s.Repos, s.Builds, s.Stages, s.Steps, s.Logs, s.LogIndex))
 
 				r.With(
 					acl.CheckAdminAccess(),
-				).Delete("/", builds.HandlePurge(s.Repos, s.Builds))
+				).Delete("/", builds.HandlePurge(s.Repos, s.Builds, s.Artifacts, s.Approvals, s.LogIndex))
 			})
 
+			r.Get("/tests/flaky", tests.HandleFlaky(s.Repos, s.Tests))
+
 			r.Route("/secrets", func(r chi.Router) {
 				r.Use(acl.CheckWriteAccess())
 				r.Get("/", secrets.HandleList(s.Repos, s.Secrets))
@@ -261,11 +323,58 @@ func (s Server) Handler() http.Handler {
 				r.Delete("/{secret}", secrets.HandleDelete(s.Repos, s.Secrets))
 			})
 
+			r.Route("/environments", func(r chi.Router) {
+				r.Get("/", environments.HandleList(s.Repos, s.Environments, s.Builds))
+				r.With(acl.CheckAdminAccess()).Post("/", environments.HandleCreate(s.Repos, s.Environments))
+				r.Get("/{environment}", environments.HandleFind(s.Repos, s.Environments, s.Builds))
+				r.With(acl.CheckAdminAccess()).Patch("/{environment}", environments.HandleUpdate(s.Repos, s.Environments))
+				r.With(acl.CheckAdminAccess()).Delete("/{environment}", environments.HandleDelete(s.Repos, s.Environments))
+				r.Get("/{environment}/deployments", environments.HandleDeployments(s.Repos, s.Environments, s.Builds))
+
+				r.Route("/{environment}/secrets", func(r chi.Router) {
+					r.Use(acl.CheckAdminAccess())
+					r.Get("/", environments.HandleSecretList(s.Repos, s.Environments))
+					r.Post("/", environments.HandleSecretCreate(s.Repos, s.Environments))
+					r.Patch("/{secret}", environments.HandleSecretUpdate(s.Repos, s.Environments))
+					r.Delete("/{secret}", environments.HandleSecretDelete(s.Repos, s.Environments))
+				})
+			})
+
+			r.Route("/channels", func(r chi.Router) {
+				r.Use(acl.CheckAdminAccess())
+				r.Get("/", channels.HandleList(s.Repos, s.Channels))
+				r.Post("/", channels.HandleCreate(s.Repos, s.Channels))
+				r.Get("/{channel}", channels.HandleFind(s.Repos, s.Channels))
+				r.Patch("/{channel}", channels.HandleUpdate(s.Repos, s.Channels))
+				r.Delete("/{channel}", channels.HandleDelete(s.Repos, s.Channels))
+			})
+
+			r.Route("/webhooks", func(r chi.Router) {
+				r.Use(acl.CheckAdminAccess())
+				r.Get("/", webhooks.HandleList(s.Repos, s.Subscriptions))
+				r.Post("/", webhooks.HandleCreate(s.Repos, s.Subscriptions))
+				r.Get("/{webhook}", webhooks.HandleFind(s.Repos, s.Subscriptions))
+				r.Patch("/{webhook}", webhooks.HandleUpdate(s.Repos, s.Subscriptions))
+				r.Delete("/{webhook}", webhooks.HandleDelete(s.Repos, s.Subscriptions))
+			})
+
+			r.Route("/journal", func(r chi.Router) {
+				r.Use(acl.CheckAdminAccess())
+				r.Get("/", journal.HandleList(s.Repos, s.Journal))
+				r.Get("/{entry}", journal.HandleFind(s.Repos, s.Journal))
+				r.Post("/{entry}/replay", journal.HandleReplay(s.Repos, s.Journal))
+			})
+
 			r.Route("/sign", func(r chi.Router) {
 				r.Use(acl.CheckWriteAccess())
 				r.Post("/", sign.HandleSign(s.Repos))
 			})
 
+			r.Route("/evaluate", func(r chi.Router) {
+				r.Use(acl.CheckWriteAccess())
+				r.Post("/", evaluate.HandleEvaluate(s.Repos, s.Triggerer))
+			})
+
 			r.Route("/encrypt", func(r chi.Router) {
 				r.Use(acl.CheckWriteAccess())
 				r.Post("/", encrypt.Handler(s.Repos))
@@ -305,7 +414,7 @@ func (s Server) Handler() http.Handler {
 	r.Route("/badges/{owner}/{name}", func(r chi.Router) {
 		r.Get("/status.svg", badge.Handler(s.Repos, s.Builds))
 		r.With(
-			acl.InjectRepository(s.Repoz, s.Repos, s.Perms),
+			acl.InjectRepository(s.Repoz, s.Repos, s.Perms, s.Identities),
 			acl.CheckReadAccess(),
 		).Get("/cc.xml", ccmenu.Handler(s.Repos, s.Builds, s.System.Link))
 	})
@@ -313,15 +422,49 @@ func (s Server) Handler() http.Handler {
 	r.Route("/queue", func(r chi.Router) {
 		r.Use(acl.AuthorizeAdmin)
 		r.Get("/", queue.HandleItems(s.Stages))
+		r.Get("/stats", queue.HandleStats(s.Scheduler))
 		r.Post("/", queue.HandleResume(s.Scheduler))
 		r.Delete("/", queue.HandlePause(s.Scheduler))
 	})
 
+	r.Route("/deliveries", func(r chi.Router) {
+		r.Use(acl.AuthorizeAdmin)
+		r.Get("/", deliveries.HandleList(s.Deliveries))
+		r.Get("/{delivery}", deliveries.HandleFind(s.Deliveries))
+		r.Get("/{delivery}/attempts", deliveries.HandleAttempts(s.Deliveries))
+		r.Post("/{delivery}/redeliver", deliveries.HandleRedeliver(s.Deliveries))
+	})
+
+	r.Route("/journal", func(r chi.Router) {
+		r.Use(acl.AuthorizeAdmin)
+		r.Get("/", globaljournal.HandleList(s.Journal))
+		r.Get("/{entry}", globaljournal.HandleFind(s.Journal))
+		r.Post("/{entry}/replay", globaljournal.HandleReplay(s.Journal))
+	})
+
+	r.Route("/runners", func(r chi.Router) {
+		r.Use(acl.AuthorizeAdmin)
+		r.Get("/", runners.HandleList(s.Nodes, s.Stages))
+		r.Get("/{runner}", runners.HandleFind(s.Nodes, s.Stages))
+		r.Delete("/{runner}", runners.HandleDelete(s.Nodes))
+		r.Post("/{runner}/drain", runners.HandleDrain(s.Nodes))
+		r.Post("/{runner}/cordon", runners.HandleCordon(s.Nodes))
+		r.Delete("/{runner}/cordon", runners.HandleUncordon(s.Nodes))
+	})
+
+	r.Route("/search", func(r chi.Router) {
+		r.Use(acl.AuthorizeUser)
+		r.Get("/logs", search.HandleLogs(s.Repos, s.LogIndex))
+	})
+
 	r.Route("/user", func(r chi.Router) {
 		r.Use(acl.AuthorizeUser)
 		r.Get("/", user.HandleFind())
 		r.Patch("/", user.HandleUpdate(s.Users))
 		r.Post("/token", user.HandleToken(s.Users))
+		r.Get("/tokens", tokens.HandleList(s.Tokens))
+		r.Post("/tokens", tokens.HandleCreate(s.Repos, s.Perms, s.Tokens))
+		r.Delete("/tokens/{token}", tokens.HandleDelete(s.Tokens))
 		r.Get("/repos", user.HandleRepos(s.Repos))
 		r.Post("/repos", user.HandleSync(s.Syncer, s.Repos))
 
@@ -348,7 +491,7 @@ func (s Server) Handler() http.Handler {
 		r.Get("/", events.HandleGlobal(s.Repos, s.Events))
 
 		r.Route("/{owner}/{name}", func(r chi.Router) {
-			r.Use(acl.InjectRepository(s.Repoz, s.Repos, s.Perms))
+			r.Use(acl.InjectRepository(s.Repoz, s.Repos, s.Perms, s.Identities))
 			r.Use(acl.CheckReadAccess())
 
 			r.Get("/", events.HandleEvents(s.Repos, s.Events))
@@ -372,6 +515,14 @@ func (s Server) Handler() http.Handler {
 		r.With(acl.CheckMembership(s.Orgs, true)).Delete("/{namespace}/{name}", globalsecrets.HandleDelete(s.Globals))
 	})
 
+	r.Route("/channels", func(r chi.Router) {
+		r.With(acl.CheckMembership(s.Orgs, false)).Get("/{namespace}", globalchannels.HandleList(s.Channels))
+		r.With(acl.CheckMembership(s.Orgs, true)).Post("/{namespace}", globalchannels.HandleCreate(s.Channels))
+		r.With(acl.CheckMembership(s.Orgs, false)).Get("/{namespace}/{channel}", globalchannels.HandleFind(s.Channels))
+		r.With(acl.CheckMembership(s.Orgs, true)).Patch("/{namespace}/{channel}", globalchannels.HandleUpdate(s.Channels))
+		r.With(acl.CheckMembership(s.Orgs, true)).Delete("/{namespace}/{channel}", globalchannels.HandleDelete(s.Channels))
+	})
+
 	r.Route("/templates", func(r chi.Router) {
 		r.With(acl.CheckMembership(s.Orgs, false)).Get("/", template.HandleListAll(s.Template))
 		r.With(acl.CheckMembership(s.Orgs, true)).Post("/{namespace}", template.HandleCreate(s.Template))
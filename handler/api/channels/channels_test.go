@@ -0,0 +1,117 @@
+// Copyright 2019 Drone.IO Inc. All rights reserved.
+// Use of this source code is governed by the Drone Non-Commercial License
+// that can be found in the LICENSE file.
+
+// +build !oss
+
+package channels
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/drone/drone/core"
+	"github.com/drone/drone/mock"
+
+	"github.com/go-chi/chi"
+	"github.com/golang/mock/gomock"
+)
+
+var dummyChannel = &core.Channel{
+	ID:        2,
+	Namespace: "octocat",
+	Name:      "email",
+	Kind:      core.ChannelEmail,
+	Endpoint:  "octocat@github.com",
+}
+
+func TestHandleCreate(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	channels := mock.NewMockChannelStore(controller)
+	channels.EXPECT().Create(gomock.Any(), gomock.Any()).Do(func(_ context.Context, in *core.Channel) {
+		if got, want := in.Namespace, "octocat"; got != want {
+			t.Errorf("Want channel namespace %q, got %q", want, got)
+		}
+		if in.RepoID != 0 {
+			t.Errorf("Want namespace channel without repository id")
+		}
+	}).Return(nil)
+
+	c := new(chi.Context)
+	c.URLParams.Add("namespace", "octocat")
+
+	in := new(bytes.Buffer)
+	json.NewEncoder(in).Encode(dummyChannel)
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("POST", "/", in)
+	r = r.WithContext(
+		context.WithValue(context.Background(), chi.RouteCtxKey, c),
+	)
+
+	HandleCreate(channels).ServeHTTP(w, r)
+	if got, want := w.Code, http.StatusOK; want != got {
+		t.Errorf("Want response code %d, got %d", want, got)
+	}
+}
+
+func TestHandleFind(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	channels := mock.NewMockChannelStore(controller)
+	channels.EXPECT().Find(gomock.Any(), dummyChannel.ID).Return(dummyChannel, nil)
+
+	c := new(chi.Context)
+	c.URLParams.Add("namespace", "octocat")
+	c.URLParams.Add("channel", "2")
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("GET", "/", nil)
+	r = r.WithContext(
+		context.WithValue(context.Background(), chi.RouteCtxKey, c),
+	)
+
+	HandleFind(channels).ServeHTTP(w, r)
+	if got, want := w.Code, http.StatusOK; want != got {
+		t.Errorf("Want response code %d, got %d", want, got)
+	}
+
+	got := &core.Channel{}
+	json.NewDecoder(w.Body).Decode(got)
+	if got, want := got.Endpoint, dummyChannel.Endpoint; got != want {
+		t.Errorf("Want email channel endpoint %q, got %q", want, got)
+	}
+}
+
+// this test verifies that a channel that belongs to another
+// namespace, or to a repository, cannot be accessed through
+// the namespace endpoint.
+func TestHandleFind_NamespaceMismatch(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	channels := mock.NewMockChannelStore(controller)
+	channels.EXPECT().Find(gomock.Any(), dummyChannel.ID).Return(dummyChannel, nil)
+
+	c := new(chi.Context)
+	c.URLParams.Add("namespace", "spaceghost")
+	c.URLParams.Add("channel", "2")
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("GET", "/", nil)
+	r = r.WithContext(
+		context.WithValue(context.Background(), chi.RouteCtxKey, c),
+	)
+
+	HandleFind(channels).ServeHTTP(w, r)
+	if got, want := w.Code, http.StatusNotFound; want != got {
+		t.Errorf("Want response code %d, got %d", want, got)
+	}
+}
@@ -0,0 +1,63 @@
+// Copyright 2019 Drone.IO Inc. All rights reserved.
+// Use of this source code is governed by the Drone Non-Commercial License
+// that can be found in the LICENSE file.
+
+// +build !oss
+
+package channels
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/drone/drone/core"
+	"github.com/drone/drone/handler/api/render"
+
+	"github.com/go-chi/chi"
+)
+
+type channelInput struct {
+	Name     string   `json:"name"`
+	Kind     string   `json:"kind"`
+	Endpoint string   `json:"endpoint"`
+	Template string   `json:"template"`
+	Events   []string `json:"events"`
+}
+
+// HandleCreate returns an http.HandlerFunc that processes http
+// requests to create a new namespace notification channel.
+func HandleCreate(channels core.ChannelStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		in := new(channelInput)
+		err := json.NewDecoder(r.Body).Decode(in)
+		if err != nil {
+			render.BadRequest(w, err)
+			return
+		}
+
+		c := &core.Channel{
+			Namespace: chi.URLParam(r, "namespace"),
+			Name:      in.Name,
+			Kind:      in.Kind,
+			Endpoint:  in.Endpoint,
+			Template:  in.Template,
+			Events:    in.Events,
+			Created:   time.Now().Unix(),
+			Updated:   time.Now().Unix(),
+		}
+
+		err = c.Validate()
+		if err != nil {
+			render.BadRequest(w, err)
+			return
+		}
+
+		err = channels.Create(r.Context(), c)
+		if err != nil {
+			render.InternalError(w, err)
+			return
+		}
+		render.JSON(w, c.Copy(), 200)
+	}
+}
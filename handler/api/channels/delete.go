@@ -0,0 +1,40 @@
+// Copyright 2019 Drone.IO Inc. All rights reserved.
+// Use of this source code is governed by the Drone Non-Commercial License
+// that can be found in the LICENSE file.
+
+// +build !oss
+
+package channels
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/drone/drone/core"
+	"github.com/drone/drone/handler/api/render"
+
+	"github.com/go-chi/chi"
+)
+
+// HandleDelete returns an http.HandlerFunc that processes http
+// requests to delete a namespace notification channel.
+func HandleDelete(channels core.ChannelStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := strconv.ParseInt(chi.URLParam(r, "channel"), 10, 64)
+		if err != nil {
+			render.BadRequest(w, err)
+			return
+		}
+		c, err := find(r.Context(), channels, chi.URLParam(r, "namespace"), id)
+		if err != nil {
+			render.NotFound(w, err)
+			return
+		}
+		err = channels.Delete(r.Context(), c)
+		if err != nil {
+			render.InternalError(w, err)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
@@ -0,0 +1,51 @@
+// Copyright 2019 Drone.IO Inc. All rights reserved.
+// Use of this source code is governed by the Drone Non-Commercial License
+// that can be found in the LICENSE file.
+
+// +build !oss
+
+package channels
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+
+	"github.com/drone/drone/core"
+	"github.com/drone/drone/handler/api/errors"
+	"github.com/drone/drone/handler/api/render"
+
+	"github.com/go-chi/chi"
+)
+
+// HandleFind returns an http.HandlerFunc that writes json-encoded
+// notification channel details to the response body.
+func HandleFind(channels core.ChannelStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := strconv.ParseInt(chi.URLParam(r, "channel"), 10, 64)
+		if err != nil {
+			render.BadRequest(w, err)
+			return
+		}
+		channel, err := find(r.Context(), channels, chi.URLParam(r, "namespace"), id)
+		if err != nil {
+			render.NotFound(w, err)
+			return
+		}
+		render.JSON(w, channel.Copy(), 200)
+	}
+}
+
+// helper function returns the namespace channel. An error is
+// returned if the channel belongs to a repository or to a
+// different namespace.
+func find(ctx context.Context, channels core.ChannelStore, namespace string, id int64) (*core.Channel, error) {
+	channel, err := channels.Find(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if channel.RepoID != 0 || channel.Namespace != namespace {
+		return nil, errors.ErrNotFound
+	}
+	return channel, nil
+}
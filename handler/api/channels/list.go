@@ -0,0 +1,36 @@
+// Copyright 2019 Drone.IO Inc. All rights reserved.
+// Use of this source code is governed by the Drone Non-Commercial License
+// that can be found in the LICENSE file.
+
+// +build !oss
+
+package channels
+
+import (
+	"net/http"
+
+	"github.com/drone/drone/core"
+	"github.com/drone/drone/handler/api/render"
+
+	"github.com/go-chi/chi"
+)
+
+// HandleList returns an http.HandlerFunc that writes a json-encoded
+// list of namespace notification channels to the response body.
+func HandleList(channels core.ChannelStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		namespace := chi.URLParam(r, "namespace")
+		list, err := channels.ListNamespace(r.Context(), namespace)
+		if err != nil {
+			render.InternalError(w, err)
+			return
+		}
+		// the channel list is copied and the channel endpoint
+		// is removed from the response.
+		out := []*core.Channel{}
+		for _, channel := range list {
+			out = append(out, channel.Copy())
+		}
+		render.JSON(w, out, 200)
+	}
+}
@@ -0,0 +1,78 @@
+// Copyright 2019 Drone.IO Inc. All rights reserved.
+// Use of this source code is governed by the Drone Non-Commercial License
+// that can be found in the LICENSE file.
+
+// +build !oss
+
+package channels
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/drone/drone/core"
+	"github.com/drone/drone/handler/api/render"
+
+	"github.com/go-chi/chi"
+)
+
+type channelUpdate struct {
+	Kind     *string  `json:"kind"`
+	Endpoint *string  `json:"endpoint"`
+	Template *string  `json:"template"`
+	Events   []string `json:"events"`
+}
+
+// HandleUpdate returns an http.HandlerFunc that processes http
+// requests to update a namespace notification channel.
+func HandleUpdate(channels core.ChannelStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := strconv.ParseInt(chi.URLParam(r, "channel"), 10, 64)
+		if err != nil {
+			render.BadRequest(w, err)
+			return
+		}
+
+		in := new(channelUpdate)
+		err = json.NewDecoder(r.Body).Decode(in)
+		if err != nil {
+			render.BadRequest(w, err)
+			return
+		}
+
+		c, err := find(r.Context(), channels, chi.URLParam(r, "namespace"), id)
+		if err != nil {
+			render.NotFound(w, err)
+			return
+		}
+
+		if in.Kind != nil {
+			c.Kind = *in.Kind
+		}
+		if in.Endpoint != nil {
+			c.Endpoint = *in.Endpoint
+		}
+		if in.Template != nil {
+			c.Template = *in.Template
+		}
+		if in.Events != nil {
+			c.Events = in.Events
+		}
+		c.Updated = time.Now().Unix()
+
+		err = c.Validate()
+		if err != nil {
+			render.BadRequest(w, err)
+			return
+		}
+
+		err = channels.Update(r.Context(), c)
+		if err != nil {
+			render.InternalError(w, err)
+			return
+		}
+		render.JSON(w, c.Copy(), 200)
+	}
+}
@@ -0,0 +1,72 @@
+// Copyright 2019 Drone.IO Inc. All rights reserved.
+// Use of this source code is governed by the Drone Non-Commercial License
+// that can be found in the LICENSE file.
+
+// +build !oss
+
+package deliveries
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/drone/drone/core"
+	"github.com/drone/drone/handler/api/render"
+	"github.com/drone/drone/logger"
+
+	"github.com/go-chi/chi"
+)
+
+// HandleFind returns an http.HandlerFunc that writes json-encoded
+// webhook delivery details to the response body.
+func HandleFind(deliveries core.DeliveryStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := strconv.ParseInt(chi.URLParam(r, "delivery"), 10, 64)
+		if err != nil {
+			render.BadRequest(w, err)
+			return
+		}
+		delivery, err := deliveries.Find(r.Context(), id)
+		if err != nil {
+			render.NotFound(w, err)
+			logger.FromRequest(r).
+				WithError(err).
+				WithField("delivery", id).
+				Debugln("api: cannot find webhook delivery")
+			return
+		}
+		render.JSON(w, delivery, 200)
+	}
+}
+
+// HandleAttempts returns an http.HandlerFunc that writes a
+// json-encoded list of webhook delivery attempts to the
+// response body.
+func HandleAttempts(deliveries core.DeliveryStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := strconv.ParseInt(chi.URLParam(r, "delivery"), 10, 64)
+		if err != nil {
+			render.BadRequest(w, err)
+			return
+		}
+		delivery, err := deliveries.Find(r.Context(), id)
+		if err != nil {
+			render.NotFound(w, err)
+			logger.FromRequest(r).
+				WithError(err).
+				WithField("delivery", id).
+				Debugln("api: cannot find webhook delivery")
+			return
+		}
+		list, err := deliveries.ListAttempts(r.Context(), delivery.ID)
+		if err != nil {
+			render.InternalError(w, err)
+			logger.FromRequest(r).
+				WithError(err).
+				WithField("delivery", id).
+				Errorln("api: cannot list webhook delivery attempts")
+			return
+		}
+		render.JSON(w, list, 200)
+	}
+}
@@ -0,0 +1,51 @@
+// Copyright 2019 Drone.IO Inc. All rights reserved.
+// Use of this source code is governed by the Drone Non-Commercial License
+// that can be found in the LICENSE file.
+
+// +build !oss
+
+package deliveries
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/drone/drone/core"
+	"github.com/drone/drone/handler/api/render"
+	"github.com/drone/drone/logger"
+)
+
+// HandleList returns an http.HandlerFunc that writes a json-encoded
+// list of webhook deliveries to the response body.
+func HandleList(deliveries core.DeliveryStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var (
+			page    = r.FormValue("page")
+			perPage = r.FormValue("per_page")
+		)
+		offset, _ := strconv.Atoi(page)
+		limit, _ := strconv.Atoi(perPage)
+		if limit < 1 || limit > 100 {
+			limit = 25
+		}
+		switch offset {
+		case 0, 1:
+			offset = 0
+		default:
+			offset = (offset - 1) * limit
+		}
+		list, err := deliveries.List(r.Context(), limit, offset)
+		if err != nil {
+			render.InternalError(w, err)
+			logger.FromRequest(r).WithError(err).
+				Errorln("api: cannot list webhook deliveries")
+			return
+		}
+		// the payload is omitted from the list to limit
+		// the size of the response.
+		for _, delivery := range list {
+			delivery.Payload = ""
+		}
+		render.JSON(w, list, 200)
+	}
+}
@@ -0,0 +1,62 @@
+// Copyright 2019 Drone.IO Inc. All rights reserved.
+// Use of this source code is governed by the Drone Non-Commercial License
+// that can be found in the LICENSE file.
+
+// +build !oss
+
+package deliveries
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/drone/drone/core"
+	"github.com/drone/drone/handler/api/render"
+	"github.com/drone/drone/logger"
+
+	"github.com/go-chi/chi"
+)
+
+// HandleRedeliver returns an http.HandlerFunc that processes
+// an http.Request to redeliver the webhook. The delivery is
+// returned to the outbox and attempted again, regardless of
+// whether it previously succeeded or failed, with the delivery
+// attempts reset.
+func HandleRedeliver(deliveries core.DeliveryStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := strconv.ParseInt(chi.URLParam(r, "delivery"), 10, 64)
+		if err != nil {
+			render.BadRequest(w, err)
+			return
+		}
+		delivery, err := deliveries.Find(r.Context(), id)
+		if err != nil {
+			render.NotFound(w, err)
+			logger.FromRequest(r).
+				WithError(err).
+				WithField("delivery", id).
+				Debugln("api: cannot find webhook delivery")
+			return
+		}
+		// the attempts are reset and the delivery is scheduled
+		// immediately, so that a redelivery is retried with the
+		// full retry budget instead of failing after a single
+		// attempt, or waiting for a previous backoff to elapse.
+		now := time.Now().Unix()
+		delivery.Status = core.DeliveryPending
+		delivery.Attempts = 0
+		delivery.Next = now
+		delivery.Updated = now
+		err = deliveries.Update(r.Context(), delivery)
+		if err != nil {
+			render.InternalError(w, err)
+			logger.FromRequest(r).
+				WithError(err).
+				WithField("delivery", id).
+				Errorln("api: cannot redeliver webhook")
+			return
+		}
+		render.JSON(w, delivery, 200)
+	}
+}
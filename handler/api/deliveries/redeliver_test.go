@@ -0,0 +1,103 @@
+// Copyright 2019 Drone.IO Inc. All rights reserved.
+// Use of this source code is governed by the Drone Non-Commercial License
+// that can be found in the LICENSE file.
+
+// +build !oss
+
+package deliveries
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/drone/drone/core"
+	"github.com/drone/drone/mock"
+
+	"github.com/go-chi/chi"
+	"github.com/golang/mock/gomock"
+)
+
+func TestHandleRedeliver(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	// the delivery exhausted its attempts, and has a next
+	// attempt scheduled by a previous backoff.
+	delivery := &core.Delivery{
+		ID:       1,
+		Status:   core.DeliveryFailed,
+		Attempts: 10,
+		Next:     time.Now().Add(time.Hour).Unix(),
+	}
+	deliveries := mock.NewMockDeliveryStore(controller)
+	deliveries.EXPECT().Find(gomock.Any(), delivery.ID).Return(delivery, nil)
+	deliveries.EXPECT().Update(gomock.Any(), delivery).Return(nil)
+
+	c := new(chi.Context)
+	c.URLParams.Add("delivery", "1")
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("POST", "/", nil)
+	r = r.WithContext(
+		context.WithValue(context.Background(), chi.RouteCtxKey, c),
+	)
+
+	HandleRedeliver(deliveries).ServeHTTP(w, r)
+	if got, want := w.Code, http.StatusOK; want != got {
+		t.Errorf("Want response code %d, got %d", want, got)
+	}
+	if got, want := delivery.Status, core.DeliveryPending; got != want {
+		t.Errorf("Want status %s, got %s", want, got)
+	}
+	if got, want := delivery.Attempts, 0; got != want {
+		t.Errorf("Want attempts reset to %d, got %d", want, got)
+	}
+	if delivery.Next == 0 || delivery.Next > time.Now().Unix() {
+		t.Errorf("Want delivery scheduled immediately, got %d", delivery.Next)
+	}
+}
+
+func TestHandleRedeliver_NotFound(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	deliveries := mock.NewMockDeliveryStore(controller)
+	deliveries.EXPECT().Find(gomock.Any(), int64(1)).Return(nil, sql.ErrNoRows)
+
+	c := new(chi.Context)
+	c.URLParams.Add("delivery", "1")
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("POST", "/", nil)
+	r = r.WithContext(
+		context.WithValue(context.Background(), chi.RouteCtxKey, c),
+	)
+
+	HandleRedeliver(deliveries).ServeHTTP(w, r)
+	if got, want := w.Code, http.StatusNotFound; want != got {
+		t.Errorf("Want response code %d, got %d", want, got)
+	}
+}
+
+func TestHandleRedeliver_BadRequest(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	c := new(chi.Context)
+	c.URLParams.Add("delivery", "one")
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("POST", "/", nil)
+	r = r.WithContext(
+		context.WithValue(context.Background(), chi.RouteCtxKey, c),
+	)
+
+	HandleRedeliver(nil).ServeHTTP(w, r)
+	if got, want := w.Code, http.StatusBadRequest; want != got {
+		t.Errorf("Want response code %d, got %d", want, got)
+	}
+}
@@ -0,0 +1,94 @@
+// Copyright 2019 Drone.IO Inc. All rights reserved.
+// Use of this source code is governed by the Drone Non-Commercial License
+// that can be found in the LICENSE file.
+
+package journal
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/drone/drone/core"
+	"github.com/drone/drone/mock"
+
+	"github.com/go-chi/chi"
+	"github.com/golang/mock/gomock"
+)
+
+func TestHandleReplay(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	entry := &core.JournalEntry{ID: 1, Hook: &core.Hook{}, Status: core.JournalFailed, Error: "yaml not found"}
+	journal := mock.NewMockJournalStore(controller)
+	journal.EXPECT().Find(gomock.Any(), entry.ID).Return(entry, nil)
+	journal.EXPECT().Update(gomock.Any(), entry).Return(nil)
+
+	c := new(chi.Context)
+	c.URLParams.Add("entry", "1")
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("POST", "/", nil)
+	r = r.WithContext(
+		context.WithValue(context.Background(), chi.RouteCtxKey, c),
+	)
+
+	HandleReplay(journal).ServeHTTP(w, r)
+	if got, want := w.Code, http.StatusOK; want != got {
+		t.Errorf("Want response code %d, got %d", want, got)
+	}
+	if got, want := entry.Status, core.JournalPending; got != want {
+		t.Errorf("Want status %s, got %s", want, got)
+	}
+	if entry.Error != "" {
+		t.Errorf("Want error message reset")
+	}
+}
+
+func TestHandleReplay_NoHook(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	entry := &core.JournalEntry{ID: 1, Status: core.JournalFailed}
+	journal := mock.NewMockJournalStore(controller)
+	journal.EXPECT().Find(gomock.Any(), entry.ID).Return(entry, nil)
+
+	c := new(chi.Context)
+	c.URLParams.Add("entry", "1")
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("POST", "/", nil)
+	r = r.WithContext(
+		context.WithValue(context.Background(), chi.RouteCtxKey, c),
+	)
+
+	HandleReplay(journal).ServeHTTP(w, r)
+	if got, want := w.Code, http.StatusBadRequest; want != got {
+		t.Errorf("Want response code %d, got %d", want, got)
+	}
+}
+
+func TestHandleReplay_NotFound(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	journal := mock.NewMockJournalStore(controller)
+	journal.EXPECT().Find(gomock.Any(), int64(1)).Return(nil, sql.ErrNoRows)
+
+	c := new(chi.Context)
+	c.URLParams.Add("entry", "1")
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("POST", "/", nil)
+	r = r.WithContext(
+		context.WithValue(context.Background(), chi.RouteCtxKey, c),
+	)
+
+	HandleReplay(journal).ServeHTTP(w, r)
+	if got, want := w.Code, http.StatusNotFound; want != got {
+		t.Errorf("Want response code %d, got %d", want, got)
+	}
+}
@@ -0,0 +1,73 @@
+// Copyright 2019 Drone IO, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package journal
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/drone/drone/core"
+	"github.com/drone/drone/handler/api/errors"
+	"github.com/drone/drone/handler/api/render"
+	"github.com/drone/drone/logger"
+
+	"github.com/go-chi/chi"
+)
+
+// errCannotReplay is returned when the webhook could not be
+// parsed and therefore cannot be replayed.
+var errCannotReplay = errors.New("Cannot replay a webhook that could not be parsed")
+
+// HandleReplay returns an http.HandlerFunc that processes an
+// http.Request to replay the webhook. The journal entry is
+// returned to the pending state and processed again by the
+// journal worker, regardless of its previous outcome.
+func HandleReplay(journal core.JournalStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := strconv.ParseInt(chi.URLParam(r, "entry"), 10, 64)
+		if err != nil {
+			render.BadRequest(w, err)
+			return
+		}
+		entry, err := journal.Find(r.Context(), id)
+		if err != nil {
+			render.NotFound(w, err)
+			logger.FromRequest(r).
+				WithError(err).
+				WithField("journal.id", id).
+				Debugln("api: cannot find journal entry")
+			return
+		}
+		if entry.Hook == nil {
+			render.BadRequest(w, errCannotReplay)
+			return
+		}
+		entry.Status = core.JournalPending
+		entry.Error = ""
+		entry.Next = time.Now().Unix()
+		entry.Updated = time.Now().Unix()
+		err = journal.Update(r.Context(), entry)
+		if err != nil {
+			render.InternalError(w, err)
+			logger.FromRequest(r).
+				WithError(err).
+				WithField("journal.id", id).
+				Errorln("api: cannot replay webhook")
+			return
+		}
+		render.JSON(w, entry, 200)
+	}
+}
@@ -38,3 +38,7 @@ func HandlePause(core.Scheduler) http.HandlerFunc {
 func HandleResume(core.Scheduler) http.HandlerFunc {
 	return notImplemented
 }
+
+func HandleStats(core.Scheduler) http.HandlerFunc {
+	return notImplemented
+}
@@ -0,0 +1,33 @@
+// Copyright 2019 Drone.IO Inc. All rights reserved.
+// Use of this source code is governed by the Drone Non-Commercial License
+// that can be found in the LICENSE file.
+
+// +build !oss
+
+package queue
+
+import (
+	"net/http"
+
+	"github.com/drone/drone/core"
+	"github.com/drone/drone/handler/api/render"
+	"github.com/drone/drone/logger"
+)
+
+// HandleStats returns an http.HandlerFunc that writes a
+// json-encoded snapshot of the scheduler queue, including the
+// order in which pending items are dispatched, to the response
+// body.
+func HandleStats(scheduler core.Scheduler) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		stats, err := scheduler.Stats(ctx)
+		if err != nil {
+			render.InternalError(w, err)
+			logger.FromRequest(r).WithError(err).
+				Warnln("api: cannot get queue stats")
+			return
+		}
+		render.JSON(w, stats, 200)
+	}
+}
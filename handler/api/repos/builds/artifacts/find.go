@@ -0,0 +1,84 @@
+// Copyright 2019 Drone IO, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package artifacts
+
+import (
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"path"
+	"strconv"
+
+	"github.com/drone/drone/core"
+	"github.com/drone/drone/handler/api/render"
+
+	"github.com/go-chi/chi"
+)
+
+// HandleFind returns an http.HandlerFunc that writes the
+// build artifact contents to the response body.
+func HandleFind(
+	repos core.RepositoryStore,
+	builds core.BuildStore,
+	artifacts core.ArtifactStore,
+) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var (
+			namespace = chi.URLParam(r, "owner")
+			name      = chi.URLParam(r, "name")
+			filename  = chi.URLParam(r, "*")
+		)
+		number, err := strconv.ParseInt(chi.URLParam(r, "number"), 10, 64)
+		if err != nil {
+			render.BadRequest(w, err)
+			return
+		}
+		repo, err := repos.FindName(r.Context(), namespace, name)
+		if err != nil {
+			render.NotFound(w, err)
+			return
+		}
+		build, err := builds.FindNumber(r.Context(), repo.ID, number)
+		if err != nil {
+			render.NotFound(w, err)
+			return
+		}
+		artifact, err := artifacts.FindName(r.Context(), build.ID, filename)
+		if err != nil {
+			render.NotFound(w, err)
+			return
+		}
+		rc, err := artifacts.Open(r.Context(), artifact)
+		if err != nil {
+			render.NotFound(w, err)
+			return
+		}
+		defer rc.Close()
+
+		contentType := mime.TypeByExtension(path.Ext(artifact.Name))
+		if contentType == "" {
+			contentType = "application/octet-stream"
+		}
+		w.Header().Set("Content-Type", contentType)
+		w.Header().Set("Content-Length", fmt.Sprint(artifact.Size))
+		w.Header().Set("Content-Disposition",
+			mime.FormatMediaType("attachment", map[string]string{
+				"filename": path.Base(artifact.Name),
+			}),
+		)
+		io.Copy(w, rc)
+	}
+}
@@ -0,0 +1,112 @@
+// Copyright 2019 Drone.IO Inc. All rights reserved.
+// Use of this source code is governed by the Drone Non-Commercial License
+// that can be found in the LICENSE file.
+
+package artifacts
+
+import (
+	"context"
+	"database/sql"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/drone/drone/core"
+	"github.com/drone/drone/mock"
+
+	"github.com/go-chi/chi"
+	"github.com/golang/mock/gomock"
+)
+
+var (
+	mockRepo = &core.Repository{
+		ID:        1,
+		Namespace: "octocat",
+		Name:      "hello-world",
+	}
+
+	mockBuild = &core.Build{
+		ID:     2,
+		RepoID: 1,
+		Number: 3,
+	}
+
+	mockArtifact = &core.Artifact{
+		ID:      4,
+		RepoID:  1,
+		BuildID: 2,
+		Name:    "dist/coverage.html",
+		Size:    11,
+	}
+)
+
+func TestHandleFind(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	repos := mock.NewMockRepositoryStore(controller)
+	repos.EXPECT().FindName(gomock.Any(), mockRepo.Namespace, mockRepo.Name).Return(mockRepo, nil)
+
+	builds := mock.NewMockBuildStore(controller)
+	builds.EXPECT().FindNumber(gomock.Any(), mockRepo.ID, mockBuild.Number).Return(mockBuild, nil)
+
+	artifacts := mock.NewMockArtifactStore(controller)
+	artifacts.EXPECT().FindName(gomock.Any(), mockBuild.ID, mockArtifact.Name).Return(mockArtifact, nil)
+	artifacts.EXPECT().Open(gomock.Any(), mockArtifact).Return(ioutil.NopCloser(strings.NewReader("hello world")), nil)
+
+	c := new(chi.Context)
+	c.URLParams.Add("owner", "octocat")
+	c.URLParams.Add("name", "hello-world")
+	c.URLParams.Add("number", "3")
+	c.URLParams.Add("*", "dist/coverage.html")
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("GET", "/", nil)
+	r = r.WithContext(
+		context.WithValue(context.Background(), chi.RouteCtxKey, c),
+	)
+
+	HandleFind(repos, builds, artifacts).ServeHTTP(w, r)
+	if got, want := w.Code, http.StatusOK; want != got {
+		t.Errorf("Want response code %d, got %d", want, got)
+	}
+	if got, want := w.Body.String(), "hello world"; got != want {
+		t.Errorf("Want response body %q, got %q", want, got)
+	}
+	if got, want := w.Header().Get("Content-Disposition"), `attachment; filename=coverage.html`; got != want {
+		t.Errorf("Want Content-Disposition %q, got %q", want, got)
+	}
+}
+
+func TestHandleFind_NotFound(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	repos := mock.NewMockRepositoryStore(controller)
+	repos.EXPECT().FindName(gomock.Any(), mockRepo.Namespace, mockRepo.Name).Return(mockRepo, nil)
+
+	builds := mock.NewMockBuildStore(controller)
+	builds.EXPECT().FindNumber(gomock.Any(), mockRepo.ID, mockBuild.Number).Return(mockBuild, nil)
+
+	artifacts := mock.NewMockArtifactStore(controller)
+	artifacts.EXPECT().FindName(gomock.Any(), mockBuild.ID, "missing.txt").Return(nil, sql.ErrNoRows)
+
+	c := new(chi.Context)
+	c.URLParams.Add("owner", "octocat")
+	c.URLParams.Add("name", "hello-world")
+	c.URLParams.Add("number", "3")
+	c.URLParams.Add("*", "missing.txt")
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("GET", "/", nil)
+	r = r.WithContext(
+		context.WithValue(context.Background(), chi.RouteCtxKey, c),
+	)
+
+	HandleFind(repos, builds, artifacts).ServeHTTP(w, r)
+	if got, want := w.Code, http.StatusNotFound; want != got {
+		t.Errorf("Want response code %d, got %d", want, got)
+	}
+}
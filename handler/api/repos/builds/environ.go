@@ -0,0 +1,109 @@
+// Copyright 2019 Drone.IO Inc. All rights reserved.
+// Use of this source code is governed by the Drone Non-Commercial License
+// that can be found in the LICENSE file.
+
+// +build !oss
+
+package builds
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/drone/drone/core"
+	"github.com/drone/drone/handler/api/errors"
+	"github.com/drone/drone/handler/api/render"
+	"github.com/drone/drone/store/shared/db"
+)
+
+var (
+	errEnvironmentInvalid = errors.New("Invalid target environment")
+	errDeployInProgress   = errors.New("Deployment to the target environment is already in progress")
+	errRollbackInvalid    = errors.New("Build is not a successful deployment to the target environment")
+)
+
+// deployLockTimeout is the maximum time the deployment lock
+// of an environment is held while the deployment is created.
+const deployLockTimeout = 5 * time.Minute
+
+// helper function validates the deployment of the build to
+// the target environment, and returns the environment. If the
+// repository does not define environments, all targets are
+// permitted and a nil environment is returned.
+//
+// The deployment lock of the returned environment is held, so
+// that concurrent deployments cannot pass the check before the
+// deployment is created, and must be released by the caller.
+func checkDeploy(
+	ctx context.Context,
+	builds core.BuildStore,
+	environments core.EnvironmentStore,
+	repo *core.Repository,
+	user *core.User,
+	build *core.Build,
+	target string,
+) (*core.Environment, error) {
+	list, err := environments.List(ctx, repo.ID)
+	if err != nil {
+		return nil, err
+	}
+	if len(list) == 0 {
+		return nil, nil
+	}
+	var env *core.Environment
+	for _, item := range list {
+		if item.Name == target {
+			env = item
+			break
+		}
+	}
+	if env == nil {
+		return nil, errEnvironmentInvalid
+	}
+	if err := env.Permit(user, build); err != nil {
+		return nil, err
+	}
+	expiry := time.Now().Add(deployLockTimeout).Unix()
+	if err := environments.Lock(ctx, env, expiry); err == db.ErrOptimisticLock {
+		return nil, errDeployInProgress
+	} else if err != nil {
+		return nil, err
+	}
+	// only one deployment to the environment is permitted
+	// at a time.
+	active, err := builds.Search(ctx, repo.ID, core.BuildParams{
+		Deploy: env.Name,
+		Status: []string{
+			core.StatusBlocked,
+			core.StatusWaiting,
+			core.StatusPending,
+			core.StatusRunning,
+		},
+		Limit: 1,
+	})
+	if err != nil {
+		environments.Unlock(ctx, env)
+		return nil, err
+	}
+	if len(active) != 0 {
+		environments.Unlock(ctx, env)
+		return nil, errDeployInProgress
+	}
+	return env, nil
+}
+
+// helper function writes the deployment validation error to
+// the response with the appropriate status code.
+func renderDeployError(w http.ResponseWriter, err error) {
+	switch err {
+	case errEnvironmentInvalid:
+		render.BadRequest(w, err)
+	case core.ErrDeployBranch, core.ErrDeployUser:
+		render.Forbidden(w, err)
+	case errDeployInProgress:
+		render.ErrorCode(w, err, http.StatusConflict)
+	default:
+		render.InternalError(w, err)
+	}
+}
@@ -0,0 +1,212 @@
+// Copyright 2019 Drone.IO Inc. All rights reserved.
+// Use of this source code is governed by the Drone Non-Commercial License
+// that can be found in the LICENSE file.
+
+// +build !oss
+
+package builds
+
+import (
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/drone/drone/core"
+	"github.com/drone/drone/handler/api/errors"
+	"github.com/drone/drone/handler/api/request"
+	"github.com/drone/drone/mock"
+	"github.com/drone/drone/store/shared/db"
+
+	"github.com/go-chi/chi"
+	"github.com/golang/mock/gomock"
+	"github.com/google/go-cmp/cmp"
+)
+
+var mockEnvironments = []*core.Environment{
+	{RepoID: 1, Name: "staging"},
+	{RepoID: 1, Name: "production", Branches: []string{"master"}, Users: []string{"octocat"}},
+}
+
+func TestCheckDeploy(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	environments := mock.NewMockEnvironmentStore(controller)
+	environments.EXPECT().List(gomock.Any(), mockRepo.ID).Return(mockEnvironments, nil)
+	environments.EXPECT().Lock(gomock.Any(), mockEnvironments[1], gomock.Any()).Return(nil)
+
+	builds := mock.NewMockBuildStore(controller)
+	builds.EXPECT().Search(gomock.Any(), mockRepo.ID, gomock.Any()).Return(nil, nil)
+
+	user := &core.User{Login: "octocat"}
+	build := &core.Build{Target: "master"}
+	env, err := checkDeploy(context.Background(), builds, environments, mockRepo, user, build, "production")
+	if err != nil {
+		t.Error(err)
+	}
+	if env != mockEnvironments[1] {
+		t.Errorf("Want the production environment")
+	}
+}
+
+func TestCheckDeploy_NoEnvironments(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	environments := mock.NewMockEnvironmentStore(controller)
+	environments.EXPECT().List(gomock.Any(), mockRepo.ID).Return(nil, nil)
+
+	env, err := checkDeploy(context.Background(), nil, environments, mockRepo, mockUser, mockBuild, "anywhere")
+	if err != nil {
+		t.Error(err)
+	}
+	if env != nil {
+		t.Errorf("Want nil environment when the repository defines none")
+	}
+}
+
+func TestCheckDeploy_Invalid(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	tests := []struct {
+		user   *core.User
+		build  *core.Build
+		target string
+		err    error
+	}{
+		{&core.User{Login: "octocat"}, &core.Build{Target: "master"}, "qa", errEnvironmentInvalid},
+		{&core.User{Login: "spaceghost"}, &core.Build{Target: "master"}, "production", core.ErrDeployUser},
+		{&core.User{Login: "octocat"}, &core.Build{Target: "develop"}, "production", core.ErrDeployBranch},
+	}
+	for i, test := range tests {
+		environments := mock.NewMockEnvironmentStore(controller)
+		environments.EXPECT().List(gomock.Any(), mockRepo.ID).Return(mockEnvironments, nil)
+
+		_, err := checkDeploy(context.Background(), nil, environments, mockRepo, test.user, test.build, test.target)
+		if got, want := err, test.err; got != want {
+			t.Errorf("Want error %v at index %d, got %v", want, i, got)
+		}
+	}
+}
+
+func TestCheckDeploy_InProgress(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	environments := mock.NewMockEnvironmentStore(controller)
+	environments.EXPECT().List(gomock.Any(), mockRepo.ID).Return(mockEnvironments, nil)
+	environments.EXPECT().Lock(gomock.Any(), mockEnvironments[0], gomock.Any()).Return(nil)
+	environments.EXPECT().Unlock(gomock.Any(), mockEnvironments[0]).Return(nil)
+
+	params := core.BuildParams{
+		Deploy: "staging",
+		Status: []string{
+			core.StatusBlocked,
+			core.StatusWaiting,
+			core.StatusPending,
+			core.StatusRunning,
+		},
+		Limit: 1,
+	}
+	builds := mock.NewMockBuildStore(controller)
+	builds.EXPECT().Search(gomock.Any(), mockRepo.ID, params).Return([]*core.Build{{Number: 2}}, nil)
+
+	_, err := checkDeploy(context.Background(), builds, environments, mockRepo, mockUser, mockBuild, "staging")
+	if got, want := err, errDeployInProgress; got != want {
+		t.Errorf("Want error %v, got %v", want, got)
+	}
+}
+
+// this test verifies that a deployment is rejected if the
+// deployment lock of the environment is held by a concurrent
+// deployment that is not yet created.
+func TestCheckDeploy_Locked(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	environments := mock.NewMockEnvironmentStore(controller)
+	environments.EXPECT().List(gomock.Any(), mockRepo.ID).Return(mockEnvironments, nil)
+	environments.EXPECT().Lock(gomock.Any(), mockEnvironments[0], gomock.Any()).Return(db.ErrOptimisticLock)
+
+	_, err := checkDeploy(context.Background(), nil, environments, mockRepo, mockUser, mockBuild, "staging")
+	if got, want := err, errDeployInProgress; got != want {
+		t.Errorf("Want error %v, got %v", want, got)
+	}
+}
+
+func TestRollback_NotDeployment(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	repos := mock.NewMockRepositoryStore(controller)
+	repos.EXPECT().FindName(gomock.Any(), gomock.Any(), mockRepo.Name).Return(mockRepo, nil)
+
+	// the build is a successful deployment to staging, and
+	// therefore cannot be used to roll back production.
+	prev := &core.Build{Number: 1, Target: "master", Deploy: "staging", Status: core.StatusPassing}
+	builds := mock.NewMockBuildStore(controller)
+	builds.EXPECT().FindNumber(gomock.Any(), mockRepo.ID, prev.Number).Return(prev, nil)
+	builds.EXPECT().Search(gomock.Any(), mockRepo.ID, gomock.Any()).Return(nil, nil)
+
+	environments := mock.NewMockEnvironmentStore(controller)
+	environments.EXPECT().List(gomock.Any(), mockRepo.ID).Return(mockEnvironments, nil)
+	environments.EXPECT().Lock(gomock.Any(), mockEnvironments[1], gomock.Any()).Return(nil)
+	environments.EXPECT().Unlock(gomock.Any(), mockEnvironments[1]).Return(nil)
+
+	c := new(chi.Context)
+	c.URLParams.Add("owner", "octocat")
+	c.URLParams.Add("name", "hello-world")
+	c.URLParams.Add("number", "1")
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("POST", "/?target=production", nil)
+	r = r.WithContext(
+		context.WithValue(request.WithUser(r.Context(), &core.User{Login: "octocat"}), chi.RouteCtxKey, c),
+	)
+
+	HandleRollback(repos, builds, environments, nil)(w, r)
+	if got, want := w.Code, 400; want != got {
+		t.Errorf("Want response code %d, got %d", want, got)
+	}
+
+	got, want := new(errors.Error), errRollbackInvalid
+	json.NewDecoder(w.Body).Decode(got)
+	if diff := cmp.Diff(got, want); len(diff) != 0 {
+		t.Errorf(diff)
+	}
+}
+
+func TestPromote_InProgress(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	repos := mock.NewMockRepositoryStore(controller)
+	repos.EXPECT().FindName(gomock.Any(), gomock.Any(), mockRepo.Name).Return(mockRepo, nil)
+
+	builds := mock.NewMockBuildStore(controller)
+	builds.EXPECT().FindNumber(gomock.Any(), mockRepo.ID, mockBuild.Number).Return(mockBuild, nil)
+	builds.EXPECT().Search(gomock.Any(), mockRepo.ID, gomock.Any()).Return([]*core.Build{{Number: 2}}, nil)
+
+	environments := mock.NewMockEnvironmentStore(controller)
+	environments.EXPECT().List(gomock.Any(), mockRepo.ID).Return(mockEnvironments, nil)
+	environments.EXPECT().Lock(gomock.Any(), mockEnvironments[0], gomock.Any()).Return(nil)
+	environments.EXPECT().Unlock(gomock.Any(), mockEnvironments[0]).Return(nil)
+
+	c := new(chi.Context)
+	c.URLParams.Add("owner", "octocat")
+	c.URLParams.Add("name", "hello-world")
+	c.URLParams.Add("number", "1")
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("POST", "/?target=staging", nil)
+	r = r.WithContext(
+		context.WithValue(request.WithUser(r.Context(), mockUser), chi.RouteCtxKey, c),
+	)
+
+	HandlePromote(repos, builds, environments, nil)(w, r)
+	if got, want := w.Code, 409; want != got {
+		t.Errorf("Want response code %d, got %d", want, got)
+	}
+}
@@ -30,6 +30,8 @@ func HandleFind(
 	repos core.RepositoryStore,
 	builds core.BuildStore,
 	stages core.StageStore,
+	approvals core.ApprovalStore,
+	tests core.TestStore,
 ) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		var (
@@ -56,11 +58,28 @@ func HandleFind(
 			render.InternalError(w, err)
 			return
 		}
-		render.JSON(w, &buildWithStages{build, stages}, 200)
+		list, err := approvals.List(r.Context(), build.ID)
+		if err != nil {
+			render.InternalError(w, err)
+			return
+		}
+		summary, err := tests.Summary(r.Context(), build.ID)
+		if err != nil {
+			render.InternalError(w, err)
+			return
+		}
+		// the test summary is omitted for builds that did
+		// not report test results.
+		if summary.Total == 0 {
+			summary = nil
+		}
+		render.JSON(w, &buildWithStages{build, stages, list, summary}, 200)
 	}
 }
 
 type buildWithStages struct {
 	*core.Build
-	Stages []*core.Stage `json:"stages,omitempty"`
+	Stages    []*core.Stage     `json:"stages,omitempty"`
+	Approvals []*core.Approval  `json:"approvals,omitempty"`
+	Tests     *core.TestSummary `json:"tests,omitempty"`
 }
@@ -10,6 +10,7 @@ import (
 	"net/http/httptest"
 	"testing"
 
+	"github.com/drone/drone/core"
 	"github.com/drone/drone/handler/api/errors"
 	"github.com/drone/drone/mock"
 
@@ -31,6 +32,16 @@ func TestFind(t *testing.T) {
 	stages := mock.NewMockStageStore(controller)
 	stages.EXPECT().ListSteps(gomock.Any(), mockBuild.ID).Return(mockStages, nil)
 
+	mockApprovals := []*core.Approval{
+		{ID: 1, BuildID: mockBuild.ID, StageID: 2, Login: "octocat", Action: core.ApprovalApprove, Comment: "lgtm"},
+	}
+	approvals := mock.NewMockApprovalStore(controller)
+	approvals.EXPECT().List(gomock.Any(), mockBuild.ID).Return(mockApprovals, nil)
+
+	mockSummary := &core.TestSummary{Total: 3, Passed: 2, Failed: 1}
+	tests := mock.NewMockTestStore(controller)
+	tests.EXPECT().Summary(gomock.Any(), mockBuild.ID).Return(mockSummary, nil)
+
 	c := new(chi.Context)
 	c.URLParams.Add("owner", "octocat")
 	c.URLParams.Add("name", "hello-world")
@@ -42,13 +53,13 @@ func TestFind(t *testing.T) {
 		context.WithValue(context.Background(), chi.RouteCtxKey, c),
 	)
 
-	HandleFind(repos, builds, stages)(w, r)
+	HandleFind(repos, builds, stages, approvals, tests)(w, r)
 
 	if got, want := w.Code, 200; want != got {
 		t.Errorf("Want response code %d, got %d", want, got)
 	}
 
-	got, want := &buildWithStages{}, &buildWithStages{mockBuild, mockStages}
+	got, want := &buildWithStages{}, &buildWithStages{mockBuild, mockStages, mockApprovals, mockSummary}
 	json.NewDecoder(w.Body).Decode(got)
 	if diff := cmp.Diff(got, want); len(diff) != 0 {
 		t.Errorf(diff)
@@ -67,7 +78,7 @@ func TestFind_BadRequest(t *testing.T) {
 		context.WithValue(context.Background(), chi.RouteCtxKey, c),
 	)
 
-	HandleFind(nil, nil, nil)(w, r)
+	HandleFind(nil, nil, nil, nil, nil)(w, r)
 
 	if got, want := w.Code, 400; want != got {
 		t.Errorf("Want response code %d, got %d", want, got)
@@ -98,7 +109,7 @@ func TestFind_RepoNotFound(t *testing.T) {
 		context.WithValue(context.Background(), chi.RouteCtxKey, c),
 	)
 
-	HandleFind(repos, nil, nil)(w, r)
+	HandleFind(repos, nil, nil, nil, nil)(w, r)
 
 	if got, want := w.Code, 404; want != got {
 		t.Errorf("Want response code %d, got %d", want, got)
@@ -131,7 +142,7 @@ func TestFind_BuildNotFound(t *testing.T) {
 		context.WithValue(context.Background(), chi.RouteCtxKey, c),
 	)
 
-	HandleFind(repos, builds, nil)(w, r)
+	HandleFind(repos, builds, nil, nil, nil)(w, r)
 
 	if got, want := w.Code, 404; want != got {
 		t.Errorf("Want response code %d, got %d", want, got)
@@ -168,7 +179,7 @@ func TestFind_StagesNotFound(t *testing.T) {
 		context.WithValue(context.Background(), chi.RouteCtxKey, c),
 	)
 
-	HandleFind(repos, builds, stages)(w, r)
+	HandleFind(repos, builds, stages, nil, nil)(w, r)
 	if got, want := w.Code, 500; want != got {
 		t.Errorf("Want response code %d, got %d", want, got)
 	}
@@ -30,6 +30,7 @@ func HandleLast(
 	repos core.RepositoryStore,
 	builds core.BuildStore,
 	stages core.StageStore,
+	approvals core.ApprovalStore,
 ) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		var (
@@ -59,6 +60,11 @@ func HandleLast(
 			render.InternalError(w, err)
 			return
 		}
-		render.JSON(w, &buildWithStages{build, stages}, 200)
+		list, err := approvals.List(r.Context(), build.ID)
+		if err != nil {
+			render.InternalError(w, err)
+			return
+		}
+		render.JSON(w, &buildWithStages{build, stages, list, nil}, 200)
 	}
 }
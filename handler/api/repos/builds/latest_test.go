@@ -10,8 +10,9 @@ import (
 	"net/http/httptest"
 	"testing"
 
-	"github.com/drone/drone/mock"
+	"github.com/drone/drone/core"
 	"github.com/drone/drone/handler/api/errors"
+	"github.com/drone/drone/mock"
 
 	"github.com/go-chi/chi"
 	"github.com/golang/mock/gomock"
@@ -31,6 +32,12 @@ func TestLast(t *testing.T) {
 	stages := mock.NewMockStageStore(controller)
 	stages.EXPECT().ListSteps(gomock.Any(), mockBuild.ID).Return(mockStages, nil)
 
+	mockApprovals := []*core.Approval{
+		{ID: 1, BuildID: mockBuild.ID, Login: "octocat", Action: core.ApprovalApprove},
+	}
+	approvals := mock.NewMockApprovalStore(controller)
+	approvals.EXPECT().List(gomock.Any(), mockBuild.ID).Return(mockApprovals, nil)
+
 	c := new(chi.Context)
 	c.URLParams.Add("owner", "octocat")
 	c.URLParams.Add("name", "hello-world")
@@ -41,13 +48,13 @@ func TestLast(t *testing.T) {
 		context.WithValue(context.Background(), chi.RouteCtxKey, c),
 	)
 
-	HandleLast(repos, builds, stages)(w, r)
+	HandleLast(repos, builds, stages, approvals)(w, r)
 
 	if got, want := w.Code, 200; want != got {
 		t.Errorf("Want response code %d, got %d", want, got)
 	}
 
-	got, want := &buildWithStages{}, &buildWithStages{mockBuild, mockStages}
+	got, want := &buildWithStages{}, &buildWithStages{mockBuild, mockStages, mockApprovals, nil}
 	json.NewDecoder(w.Body).Decode(got)
 	if diff := cmp.Diff(got, want); len(diff) != 0 {
 		t.Errorf(diff)
@@ -72,7 +79,7 @@ func TestLast_RepoNotFound(t *testing.T) {
 		context.WithValue(context.Background(), chi.RouteCtxKey, c),
 	)
 
-	HandleLast(repos, nil, nil)(w, r)
+	HandleLast(repos, nil, nil, nil)(w, r)
 
 	if got, want := w.Code, 404; want != got {
 		t.Errorf("Want response code %d, got %d", want, got)
@@ -105,7 +112,7 @@ func TestLast_BuildNotFound(t *testing.T) {
 		context.WithValue(context.Background(), chi.RouteCtxKey, c),
 	)
 
-	HandleLast(repos, builds, nil)(w, r)
+	HandleLast(repos, builds, nil, nil)(w, r)
 
 	if got, want := w.Code, 404; want != got {
 		t.Errorf("Want response code %d, got %d", want, got)
@@ -141,7 +148,7 @@ func TestLast_StagesNotFound(t *testing.T) {
 		context.WithValue(context.Background(), chi.RouteCtxKey, c),
 	)
 
-	HandleLast(repos, builds, stages)(w, r)
+	HandleLast(repos, builds, stages, nil)(w, r)
 	if got, want := w.Code, 500; want != got {
 		t.Errorf("Want response code %d, got %d", want, got)
 	}
@@ -15,9 +15,13 @@
 package builds
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
+	"regexp"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/drone/drone/core"
 	"github.com/drone/drone/handler/api/render"
@@ -26,8 +30,19 @@ import (
 	"github.com/go-chi/chi"
 )
 
+var (
+	errInvalidCommit = errors.New("Invalid Commit SHA")
+	errInvalidTime   = errors.New("Invalid Time Range")
+)
+
+// regular expression to validate the commit sha prefix.
+var commitRE = regexp.MustCompile("^[0-9a-fA-F]{1,64}$")
+
 // HandleList returns an http.HandlerFunc that writes a json-encoded
-// list of build history to the response body.
+// list of build history to the response body. The build history
+// can be filtered by status, event, author, sender, branch, tag,
+// target branch, deployment target, cron job, commit sha prefix
+// and time range.
 func HandleList(
 	repos core.RepositoryStore,
 	builds core.BuildStore,
@@ -52,6 +67,14 @@ func HandleList(
 		default:
 			offset = (offset - 1) * limit
 		}
+		params, err := parseParams(r)
+		if err != nil {
+			render.BadRequest(w, err)
+			return
+		}
+		params.Limit = limit
+		params.Offset = offset
+
 		repo, err := repos.FindName(r.Context(), namespace, name)
 		if err != nil {
 			render.NotFound(w, err)
@@ -64,7 +87,14 @@ func HandleList(
 		}
 
 		var results []*core.Build
-		if branch != "" {
+		if isFiltered(params) {
+			if branch != "" {
+				params.Ref = fmt.Sprintf("refs/heads/%s", branch)
+			} else if tag != "" {
+				params.Ref = fmt.Sprintf("refs/tags/%s", tag)
+			}
+			results, err = builds.Search(r.Context(), repo.ID, params)
+		} else if branch != "" {
 			ref := fmt.Sprintf("refs/heads/%s", branch)
 			results, err = builds.ListRef(r.Context(), repo.ID, ref, limit, offset)
 		} else if tag != "" {
@@ -86,3 +116,64 @@ func HandleList(
 		}
 	}
 }
+
+// helper function returns the build query parameters from
+// the http request. The ref is not included.
+func parseParams(r *http.Request) (core.BuildParams, error) {
+	params := core.BuildParams{
+		Event:  r.FormValue("event"),
+		Author: r.FormValue("author"),
+		Sender: r.FormValue("sender"),
+		Target: r.FormValue("target"),
+		Deploy: r.FormValue("deploy"),
+		Cron:   r.FormValue("cron"),
+	}
+	if v := r.FormValue("status"); v != "" {
+		params.Status = strings.Split(v, ",")
+	}
+	if v := r.FormValue("commit"); v != "" {
+		if !commitRE.MatchString(v) {
+			return params, errInvalidCommit
+		}
+		params.Commit = strings.ToLower(v)
+	}
+	var err error
+	if params.Since, err = parseTime(r.FormValue("since")); err != nil {
+		return params, err
+	}
+	if params.Until, err = parseTime(r.FormValue("until")); err != nil {
+		return params, err
+	}
+	return params, nil
+}
+
+// helper function returns true if the query parameters
+// include a filter other than the build ref.
+func isFiltered(params core.BuildParams) bool {
+	return len(params.Status) != 0 ||
+		params.Event != "" ||
+		params.Author != "" ||
+		params.Sender != "" ||
+		params.Target != "" ||
+		params.Deploy != "" ||
+		params.Cron != "" ||
+		params.Commit != "" ||
+		params.Since != 0 ||
+		params.Until != 0
+}
+
+// helper function parses the time in unix or rfc3339
+// format and returns the unix time.
+func parseTime(v string) (int64, error) {
+	if v == "" {
+		return 0, nil
+	}
+	if i, err := strconv.ParseInt(v, 10, 64); err == nil {
+		return i, nil
+	}
+	t, err := time.Parse(time.RFC3339, v)
+	if err != nil {
+		return 0, errInvalidTime
+	}
+	return t.Unix(), nil
+}
@@ -171,6 +171,75 @@ func TestListTag(t *testing.T) {
 	}
 }
 
+func TestListSearch(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	repos := mock.NewMockRepositoryStore(controller)
+	repos.EXPECT().FindName(gomock.Any(), gomock.Any(), mockRepo.Name).Return(mockRepo, nil)
+
+	params := core.BuildParams{
+		Status: []string{"failure", "error"},
+		Author: "octocat",
+		Ref:    "refs/heads/develop",
+		Commit: "7fd1a60",
+		Since:  1299283200,
+		Until:  1299369600,
+		Limit:  25,
+	}
+
+	builds := mock.NewMockBuildStore(controller)
+	builds.EXPECT().Search(gomock.Any(), mockRepo.ID, params).Return(mockBuilds, nil)
+
+	c := new(chi.Context)
+	c.URLParams.Add("owner", "octocat")
+	c.URLParams.Add("name", "hello-world")
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("GET", "/?status=failure,error&author=octocat&branch=develop&commit=7FD1A60&since=1299283200&until=2011-03-06T00:00:00Z", nil)
+	r = r.WithContext(
+		context.WithValue(context.Background(), chi.RouteCtxKey, c),
+	)
+
+	HandleList(repos, builds)(w, r)
+	if got, want := w.Code, 200; want != got {
+		t.Errorf("Want response code %d, got %d", want, got)
+	}
+
+	got, want := []*core.Build{}, mockBuilds
+	json.NewDecoder(w.Body).Decode(&got)
+	if diff := cmp.Diff(got, want); len(diff) != 0 {
+		t.Errorf(diff)
+	}
+}
+
+func TestListSearch_BadRequest(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	tests := []string{
+		"/?commit=not-a-sha",
+		"/?since=yesterday",
+		"/?until=2011-03-06",
+	}
+	for _, test := range tests {
+		c := new(chi.Context)
+		c.URLParams.Add("owner", "octocat")
+		c.URLParams.Add("name", "hello-world")
+
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest("GET", test, nil)
+		r = r.WithContext(
+			context.WithValue(context.Background(), chi.RouteCtxKey, c),
+		)
+
+		HandleList(nil, nil)(w, r)
+		if got, want := w.Code, http.StatusBadRequest; want != got {
+			t.Errorf("Want response code %d, got %d for %s", want, got, test)
+		}
+	}
+}
+
 func TestList_RepositoryNotFound(t *testing.T) {
 	controller := gomock.NewController(t)
 	defer controller.Finish()
@@ -25,13 +25,15 @@ import (
 )
 
 // HandleDelete returns an http.HandlerFunc that processes http
-// requests to delete the logs.
+// requests to delete the logs. The logs are also removed from
+// the log index, if log search is enabled.
 func HandleDelete(
 	repos core.RepositoryStore,
 	builds core.BuildStore,
 	stages core.StageStore,
 	steps core.StepStore,
 	logs core.LogStore,
+	index core.LogIndex,
 ) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		var (
@@ -78,6 +80,13 @@ func HandleDelete(
 			render.InternalError(w, err)
 			return
 		}
+		if index != nil {
+			err = index.Delete(r.Context(), step.ID)
+			if err != nil {
+				render.InternalError(w, err)
+				return
+			}
+		}
 		w.WriteHeader(204)
 	}
 }
@@ -22,6 +22,7 @@ import (
 func HandlePromote(
 	repos core.RepositoryStore,
 	builds core.BuildStore,
+	environments core.EnvironmentStore,
 	triggerer core.Triggerer,
 ) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
@@ -50,6 +51,14 @@ func HandlePromote(
 			render.BadRequestf(w, "Missing target environment")
 			return
 		}
+		env, err := checkDeploy(r.Context(), builds, environments, repo, user, prev, environ)
+		if err != nil {
+			renderDeployError(w, err)
+			return
+		}
+		if env != nil {
+			defer environments.Unlock(r.Context(), env)
+		}
 
 		hook := &core.Hook{
 			Parent:       prev.Number,
@@ -81,6 +81,9 @@ func TestPromote(t *testing.T) {
 	builds := mock.NewMockBuildStore(controller)
 	builds.EXPECT().FindNumber(gomock.Any(), mockRepo.ID, mockBuild.Number).Return(mockBuild, nil)
 
+	environments := mock.NewMockEnvironmentStore(controller)
+	environments.EXPECT().List(gomock.Any(), mockRepo.ID).Return(nil, nil)
+
 	triggerer := mock.NewMockTriggerer(controller)
 	triggerer.EXPECT().Trigger(gomock.Any(), mockRepo, gomock.Any()).Return(mockBuild, nil).Do(checkBuild)
 
@@ -95,7 +98,7 @@ func TestPromote(t *testing.T) {
 		context.WithValue(request.WithUser(r.Context(), mockUser), chi.RouteCtxKey, c),
 	)
 
-	HandlePromote(repos, builds, triggerer)(w, r)
+	HandlePromote(repos, builds, environments, triggerer)(w, r)
 	if got, want := w.Code, 200; want != got {
 		t.Errorf("Want response code %d, got %d", want, got)
 	}
@@ -122,7 +125,7 @@ func TestPromote_InvalidBuildNumber(t *testing.T) {
 		context.WithValue(request.WithUser(r.Context(), mockUser), chi.RouteCtxKey, c),
 	)
 
-	HandlePromote(nil, nil, nil)(w, r)
+	HandlePromote(nil, nil, nil, nil)(w, r)
 	if got, want := w.Code, 400; want != got {
 		t.Errorf("Want response code %d, got %d", want, got)
 	}
@@ -154,7 +157,7 @@ func TestPromote_RepoNotFound(t *testing.T) {
 		context.WithValue(request.WithUser(r.Context(), mockUser), chi.RouteCtxKey, c),
 	)
 
-	HandlePromote(repos, nil, nil)(w, r)
+	HandlePromote(repos, nil, nil, nil)(w, r)
 	if got, want := w.Code, 404; want != got {
 		t.Errorf("Want response code %d, got %d", want, got)
 	}
@@ -187,7 +190,7 @@ func TestPromote_BuildNotFound(t *testing.T) {
 		context.WithValue(request.WithUser(r.Context(), mockUser), chi.RouteCtxKey, c),
 	)
 
-	HandlePromote(repos, builds, nil)(w, r)
+	HandlePromote(repos, builds, nil, nil)(w, r)
 	if got, want := w.Code, 404; want != got {
 		t.Errorf("Want response code %d, got %d", want, got)
 	}
@@ -220,7 +223,7 @@ func TestPromote_MissingTargetError(t *testing.T) {
 		context.WithValue(request.WithUser(r.Context(), mockUser), chi.RouteCtxKey, c),
 	)
 
-	HandlePromote(repos, builds, nil)(w, r)
+	HandlePromote(repos, builds, nil, nil)(w, r)
 	if got, want := w.Code, 400; want != got {
 		t.Errorf("Want response code %d, got %d", want, got)
 	}
@@ -242,6 +245,9 @@ func TestPromote_TriggerError(t *testing.T) {
 	builds := mock.NewMockBuildStore(controller)
 	builds.EXPECT().FindNumber(gomock.Any(), mockRepo.ID, mockBuild.Number).Return(mockBuild, nil)
 
+	environments := mock.NewMockEnvironmentStore(controller)
+	environments.EXPECT().List(gomock.Any(), mockRepo.ID).Return(nil, nil)
+
 	triggerer := mock.NewMockTriggerer(controller)
 	triggerer.EXPECT().Trigger(gomock.Any(), mockRepo, gomock.Any()).Return(nil, errors.ErrNotFound)
 
@@ -256,7 +262,7 @@ func TestPromote_TriggerError(t *testing.T) {
 		context.WithValue(request.WithUser(r.Context(), mockUser), chi.RouteCtxKey, c),
 	)
 
-	HandlePromote(repos, builds, triggerer)(w, r)
+	HandlePromote(repos, builds, environments, triggerer)(w, r)
 	if got, want := w.Code, 500; want != got {
 		t.Errorf("Want response code %d, got %d", want, got)
 	}
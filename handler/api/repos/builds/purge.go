@@ -17,8 +17,16 @@ import (
 )
 
 // HandlePurge returns an http.HandlerFunc that purges the
-// build history. If successful a 204 status code is returned.
-func HandlePurge(repos core.RepositoryStore, builds core.BuildStore) http.HandlerFunc {
+// build history, the build artifacts, the build approvals and
+// the indexed build logs. If successful a 204 status code is
+// returned.
+func HandlePurge(
+	repos core.RepositoryStore,
+	builds core.BuildStore,
+	artifacts core.ArtifactStore,
+	approvals core.ApprovalStore,
+	index core.LogIndex,
+) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		var (
 			namespace = chi.URLParam(r, "owner")
@@ -35,6 +43,27 @@ func HandlePurge(repos core.RepositoryStore, builds core.BuildStore) http.Handle
 			render.NotFound(w, err)
 			return
 		}
+		// artifacts, approvals and indexed logs are purged
+		// first because they are selected by the number of the
+		// purged builds. The log index is nil if log search is
+		// disabled.
+		err = artifacts.Purge(r.Context(), repo.ID, number)
+		if err != nil {
+			render.InternalError(w, err)
+			return
+		}
+		err = approvals.Purge(r.Context(), repo.ID, number)
+		if err != nil {
+			render.InternalError(w, err)
+			return
+		}
+		if index != nil {
+			err = index.Purge(r.Context(), repo.ID, number)
+			if err != nil {
+				render.InternalError(w, err)
+				return
+			}
+		}
 		err = builds.Purge(r.Context(), repo.ID, number)
 		if err != nil {
 			render.InternalError(w, err)
@@ -23,6 +23,6 @@ import (
 )
 
 // HandlePurge returns a non-op http.HandlerFunc.
-func HandlePurge(core.RepositoryStore, core.BuildStore) http.HandlerFunc {
+func HandlePurge(core.RepositoryStore, core.BuildStore, core.ArtifactStore) http.HandlerFunc {
 	return notImplemented
 }
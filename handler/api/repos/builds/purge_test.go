@@ -28,6 +28,15 @@ func TestPurge(t *testing.T) {
 	repos := mock.NewMockRepositoryStore(controller)
 	repos.EXPECT().FindName(gomock.Any(), gomock.Any(), mockRepo.Name).Return(mockRepo, nil)
 
+	artifacts := mock.NewMockArtifactStore(controller)
+	artifacts.EXPECT().Purge(gomock.Any(), mockRepo.ID, int64(50)).Return(nil)
+
+	approvals := mock.NewMockApprovalStore(controller)
+	approvals.EXPECT().Purge(gomock.Any(), mockRepo.ID, int64(50)).Return(nil)
+
+	index := mock.NewMockLogIndex(controller)
+	index.EXPECT().Purge(gomock.Any(), mockRepo.ID, int64(50)).Return(nil)
+
 	builds := mock.NewMockBuildStore(controller)
 	builds.EXPECT().Purge(gomock.Any(), mockRepo.ID, int64(50)).Return(nil)
 
@@ -41,7 +50,7 @@ func TestPurge(t *testing.T) {
 		context.WithValue(request.WithUser(r.Context(), mockUser), chi.RouteCtxKey, c),
 	)
 
-	HandlePurge(repos, builds)(w, r)
+	HandlePurge(repos, builds, artifacts, approvals, index)(w, r)
 	if got, want := w.Code, http.StatusNoContent; want != got {
 		t.Errorf("Want response code %d, got %d", want, got)
 	}
@@ -66,7 +75,7 @@ func TestPurge_NotFound(t *testing.T) {
 		context.WithValue(request.WithUser(r.Context(), mockUser), chi.RouteCtxKey, c),
 	)
 
-	HandlePurge(repos, nil)(w, r)
+	HandlePurge(repos, nil, nil, nil, nil)(w, r)
 	if got, want := w.Code, 404; want != got {
 		t.Errorf("Want response code %d, got %d", want, got)
 	}
@@ -95,7 +104,7 @@ func TestPurge_BadRequest(t *testing.T) {
 		context.WithValue(request.WithUser(r.Context(), mockUser), chi.RouteCtxKey, c),
 	)
 
-	HandlePurge(nil, nil)(w, r)
+	HandlePurge(nil, nil, nil, nil, nil)(w, r)
 	if got, want := w.Code, 400; want != got {
 		t.Errorf("Want response code %d, got %d", want, got)
 	}
@@ -118,6 +127,12 @@ func TestPurge_InternalError(t *testing.T) {
 	repos := mock.NewMockRepositoryStore(controller)
 	repos.EXPECT().FindName(gomock.Any(), gomock.Any(), mockRepo.Name).Return(mockRepo, nil)
 
+	artifacts := mock.NewMockArtifactStore(controller)
+	artifacts.EXPECT().Purge(gomock.Any(), mockRepo.ID, int64(50)).Return(nil)
+
+	approvals := mock.NewMockApprovalStore(controller)
+	approvals.EXPECT().Purge(gomock.Any(), mockRepo.ID, int64(50)).Return(nil)
+
 	builds := mock.NewMockBuildStore(controller)
 	builds.EXPECT().Purge(gomock.Any(), mockRepo.ID, int64(50)).Return(errors.ErrNotFound)
 
@@ -131,7 +146,7 @@ func TestPurge_InternalError(t *testing.T) {
 		context.WithValue(request.WithUser(r.Context(), mockUser), chi.RouteCtxKey, c),
 	)
 
-	HandlePurge(repos, builds)(w, r)
+	HandlePurge(repos, builds, artifacts, approvals, nil)(w, r)
 	if got, want := w.Code, http.StatusInternalServerError; want != got {
 		t.Errorf("Want response code %d, got %d", want, got)
 	}
@@ -0,0 +1,85 @@
+// Copyright 2019 Drone IO, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package builds
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/drone/drone/core"
+	"github.com/drone/drone/handler/api/render"
+
+	"github.com/go-chi/chi"
+)
+
+// HandleRestart returns an http.HandlerFunc that processes http
+// requests to restart the failed stages of a build, or the
+// stages provided in the stage query parameter, along with
+// their downstream dependents.
+func HandleRestart(
+	repos core.RepositoryStore,
+	builds core.BuildStore,
+	restarter core.Restarter,
+) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var (
+			namespace = chi.URLParam(r, "owner")
+			name      = chi.URLParam(r, "name")
+		)
+		number, err := strconv.ParseInt(chi.URLParam(r, "number"), 10, 64)
+		if err != nil {
+			render.BadRequest(w, err)
+			return
+		}
+		var stages []int
+		for _, value := range r.URL.Query()["stage"] {
+			stage, err := strconv.Atoi(value)
+			if err != nil {
+				render.BadRequest(w, err)
+				return
+			}
+			stages = append(stages, stage)
+		}
+		repo, err := repos.FindName(r.Context(), namespace, name)
+		if err != nil {
+			render.NotFound(w, err)
+			return
+		}
+		build, err := builds.FindNumber(r.Context(), repo.ID, number)
+		if err != nil {
+			render.NotFound(w, err)
+			return
+		}
+
+		switch build.Status {
+		case core.StatusBlocked:
+			render.BadRequestf(w, "cannot start a blocked build")
+			return
+		case core.StatusDeclined:
+			render.BadRequestf(w, "cannot start a declined build")
+			return
+		}
+
+		err = restarter.Restart(r.Context(), repo, build, stages)
+		switch err {
+		case nil:
+			render.JSON(w, build, 200)
+		case core.ErrRestartRunning, core.ErrRestartNoStages:
+			render.BadRequest(w, err)
+		default:
+			render.InternalError(w, err)
+		}
+	}
+}
@@ -22,6 +22,7 @@ import (
 func HandleRollback(
 	repos core.RepositoryStore,
 	builds core.BuildStore,
+	environments core.EnvironmentStore,
 	triggerer core.Triggerer,
 ) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
@@ -50,6 +51,20 @@ func HandleRollback(
 			render.BadRequestf(w, "Missing target environment")
 			return
 		}
+		env, err := checkDeploy(r.Context(), builds, environments, repo, user, prev, environ)
+		if err != nil {
+			renderDeployError(w, err)
+			return
+		}
+		if env != nil {
+			defer environments.Unlock(r.Context(), env)
+		}
+		// if the repository defines environments, the build must
+		// be a previous successful deployment to the environment.
+		if env != nil && (prev.Deploy != env.Name || prev.Status != core.StatusPassing) {
+			render.BadRequest(w, errRollbackInvalid)
+			return
+		}
 
 		hook := &core.Hook{
 			Parent:       prev.Number,
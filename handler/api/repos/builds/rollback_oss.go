@@ -31,6 +31,7 @@ var rollbackNotImplemented = func(w http.ResponseWriter, r *http.Request) {
 func HandleRollback(
 	core.RepositoryStore,
 	core.BuildStore,
+	core.EnvironmentStore,
 	core.Triggerer,
 ) http.HandlerFunc {
 	return rollbackNotImplemented
@@ -0,0 +1,88 @@
+// Copyright 2019 Drone IO, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package stages
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/drone/drone/core"
+	"github.com/drone/drone/handler/api/errors"
+)
+
+var (
+	errSelfApproval = errors.New("Cannot approve your own build")
+	errNotApprover  = errors.New("Not authorized to review the Pipeline")
+)
+
+type approvalInput struct {
+	Comment string `json:"comment"`
+}
+
+// helper function decodes the optional approval comment from
+// the request body.
+func decodeComment(r *http.Request) (string, error) {
+	in := new(approvalInput)
+	if r.Body == nil {
+		return "", nil
+	}
+	err := json.NewDecoder(r.Body).Decode(in)
+	if err == io.EOF {
+		return "", nil
+	}
+	return in.Comment, err
+}
+
+// helper function returns true if the user is allowed to
+// review stages governed by the approval rule. If the rule
+// does not restrict approvers, all repository admins are
+// allowed to review.
+func isApprover(ctx context.Context, orgs core.OrganizationService, rule *core.ApprovalRule, user *core.User) (bool, error) {
+	if rule.Restricted() == false {
+		return true, nil
+	}
+	for _, login := range rule.Users {
+		if login == user.Login {
+			return true, nil
+		}
+	}
+	for _, org := range rule.Orgs {
+		member, _, err := orgs.Membership(ctx, user, org)
+		if err != nil {
+			return false, err
+		}
+		if member {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// helper function returns a new approval record.
+func newApproval(build *core.Build, stage *core.Stage, user *core.User, action, comment string) *core.Approval {
+	return &core.Approval{
+		RepoID:  build.RepoID,
+		BuildID: build.ID,
+		StageID: stage.ID,
+		UserID:  user.ID,
+		Login:   user.Login,
+		Action:  action,
+		Comment: comment,
+		Created: time.Now().Unix(),
+	}
+}
@@ -0,0 +1,295 @@
+// Copyright 2019 Drone.IO Inc. All rights reserved.
+// Use of this source code is governed by the Drone Non-Commercial License
+// that can be found in the LICENSE file.
+
+package stages
+
+import (
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/drone/drone/core"
+	"github.com/drone/drone/handler/api/errors"
+	"github.com/drone/drone/handler/api/request"
+	"github.com/drone/drone/mock"
+
+	"github.com/go-chi/chi"
+	"github.com/golang/mock/gomock"
+	"github.com/google/go-cmp/cmp"
+)
+
+var mockUser = &core.User{
+	ID:    1,
+	Login: "octocat",
+}
+
+// this test verifies that the stage remains blocked, and a
+// 202 accepted status is returned, if the approval rule
+// requires additional approvers.
+func TestApprove_Pending(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	mockRepo := &core.Repository{
+		Namespace: "octocat",
+		Name:      "hello-world",
+		ApprovalRules: []*core.ApprovalRule{
+			{Target: "production", Required: 2},
+		},
+	}
+	mockBuild := &core.Build{ID: 111, Number: 1, Deploy: "production", Sender: "spaceghost"}
+	mockStage := &core.Stage{ID: 222, Number: 2, Status: core.StatusBlocked}
+
+	repos := mock.NewMockRepositoryStore(controller)
+	repos.EXPECT().FindName(gomock.Any(), mockRepo.Namespace, mockRepo.Name).Return(mockRepo, nil)
+
+	builds := mock.NewMockBuildStore(controller)
+	builds.EXPECT().FindNumber(gomock.Any(), mockRepo.ID, mockBuild.Number).Return(mockBuild, nil)
+
+	stages := mock.NewMockStageStore(controller)
+	stages.EXPECT().FindNumber(gomock.Any(), mockBuild.ID, mockStage.Number).Return(mockStage, nil)
+
+	var created *core.Approval
+	approvals := mock.NewMockApprovalStore(controller)
+	approvals.EXPECT().Create(gomock.Any(), gomock.Any()).Return(nil).Do(func(_ context.Context, approval *core.Approval) {
+		created = approval
+	})
+	approvals.EXPECT().List(gomock.Any(), mockBuild.ID).DoAndReturn(func(context.Context, int64) ([]*core.Approval, error) {
+		return []*core.Approval{created}, nil
+	})
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("POST", "/", strings.NewReader(`{"comment":"lgtm"}`))
+	r = r.WithContext(
+		context.WithValue(request.WithUser(context.Background(), mockUser), chi.RouteCtxKey, newStageContext()),
+	)
+
+	HandleApprove(repos, builds, stages, approvals, nil, nil)(w, r)
+	if got, want := w.Code, 202; want != got {
+		t.Errorf("Want response code %d, got %d", want, got)
+	}
+	if got, want := mockStage.Status, core.StatusBlocked; got != want {
+		t.Errorf("Want stage status %s, got %s", want, got)
+	}
+
+	want := &core.Approval{
+		BuildID: mockBuild.ID,
+		StageID: mockStage.ID,
+		UserID:  mockUser.ID,
+		Login:   mockUser.Login,
+		Action:  core.ApprovalApprove,
+		Comment: "lgtm",
+		Created: created.Created,
+	}
+	if diff := cmp.Diff(created, want); diff != "" {
+		t.Errorf(diff)
+	}
+}
+
+// this test verifies that the stage is unblocked once the
+// required number of distinct approvers is reached.
+func TestApprove_Quorum(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	mockRepo := &core.Repository{
+		Namespace: "octocat",
+		Name:      "hello-world",
+		ApprovalRules: []*core.ApprovalRule{
+			{Target: "production", Required: 2, Users: []string{"octocat"}},
+		},
+	}
+	mockBuild := &core.Build{ID: 111, Number: 1, Deploy: "production", Sender: "spaceghost"}
+	mockStage := &core.Stage{ID: 222, Number: 2, Status: core.StatusBlocked}
+	mockApprovals := []*core.Approval{
+		{StageID: 222, Login: "janedoe", Action: core.ApprovalApprove, Created: time.Now().Unix()},
+		{StageID: 222, Login: "octocat", Action: core.ApprovalApprove, Created: time.Now().Unix()},
+	}
+
+	repos := mock.NewMockRepositoryStore(controller)
+	repos.EXPECT().FindName(gomock.Any(), mockRepo.Namespace, mockRepo.Name).Return(mockRepo, nil)
+
+	builds := mock.NewMockBuildStore(controller)
+	builds.EXPECT().FindNumber(gomock.Any(), mockRepo.ID, mockBuild.Number).Return(mockBuild, nil)
+
+	stages := mock.NewMockStageStore(controller)
+	stages.EXPECT().FindNumber(gomock.Any(), mockBuild.ID, mockStage.Number).Return(mockStage, nil)
+	stages.EXPECT().Update(gomock.Any(), mockStage).Return(nil)
+
+	approvals := mock.NewMockApprovalStore(controller)
+	approvals.EXPECT().Create(gomock.Any(), gomock.Any()).Return(nil)
+	approvals.EXPECT().List(gomock.Any(), mockBuild.ID).Return(mockApprovals, nil)
+
+	sched := mock.NewMockScheduler(controller)
+	sched.EXPECT().Schedule(gomock.Any(), mockStage).Return(nil)
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("POST", "/", nil)
+	r = r.WithContext(
+		context.WithValue(request.WithUser(context.Background(), mockUser), chi.RouteCtxKey, newStageContext()),
+	)
+
+	HandleApprove(repos, builds, stages, approvals, nil, sched)(w, r)
+	if got, want := w.Code, 204; want != got {
+		t.Errorf("Want response code %d, got %d", want, got)
+	}
+	if got, want := mockStage.Status, core.StatusPending; got != want {
+		t.Errorf("Want stage status %s, got %s", want, got)
+	}
+}
+
+// this test verifies that a 403 forbidden status is returned
+// if the build sender attempts to approve their own build.
+func TestApprove_SelfApproval(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	mockRepo := &core.Repository{
+		Namespace:     "octocat",
+		Name:          "hello-world",
+		ApprovalRules: []*core.ApprovalRule{{}},
+	}
+	mockBuild := &core.Build{ID: 111, Number: 1, Sender: "octocat"}
+	mockStage := &core.Stage{ID: 222, Number: 2, Status: core.StatusBlocked}
+
+	repos := mock.NewMockRepositoryStore(controller)
+	repos.EXPECT().FindName(gomock.Any(), mockRepo.Namespace, mockRepo.Name).Return(mockRepo, nil)
+
+	builds := mock.NewMockBuildStore(controller)
+	builds.EXPECT().FindNumber(gomock.Any(), mockRepo.ID, mockBuild.Number).Return(mockBuild, nil)
+
+	stages := mock.NewMockStageStore(controller)
+	stages.EXPECT().FindNumber(gomock.Any(), mockBuild.ID, mockStage.Number).Return(mockStage, nil)
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("POST", "/", nil)
+	r = r.WithContext(
+		context.WithValue(request.WithUser(context.Background(), mockUser), chi.RouteCtxKey, newStageContext()),
+	)
+
+	HandleApprove(repos, builds, stages, nil, nil, nil)(w, r)
+	if got, want := w.Code, 403; want != got {
+		t.Errorf("Want response code %d, got %d", want, got)
+	}
+
+	got, want := new(errors.Error), errSelfApproval
+	json.NewDecoder(w.Body).Decode(got)
+	if diff := cmp.Diff(got, want); len(diff) != 0 {
+		t.Errorf(diff)
+	}
+}
+
+// this test verifies that a 403 forbidden status is returned
+// if the user is not a member of an approving organization.
+func TestApprove_NotApprover(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	mockRepo := &core.Repository{
+		Namespace: "octocat",
+		Name:      "hello-world",
+		ApprovalRules: []*core.ApprovalRule{
+			{Target: "prod*", Orgs: []string{"ops"}},
+		},
+	}
+	mockBuild := &core.Build{ID: 111, Number: 1, Deploy: "production", Sender: "spaceghost"}
+	mockStage := &core.Stage{ID: 222, Number: 2, Status: core.StatusBlocked}
+
+	repos := mock.NewMockRepositoryStore(controller)
+	repos.EXPECT().FindName(gomock.Any(), mockRepo.Namespace, mockRepo.Name).Return(mockRepo, nil)
+
+	builds := mock.NewMockBuildStore(controller)
+	builds.EXPECT().FindNumber(gomock.Any(), mockRepo.ID, mockBuild.Number).Return(mockBuild, nil)
+
+	stages := mock.NewMockStageStore(controller)
+	stages.EXPECT().FindNumber(gomock.Any(), mockBuild.ID, mockStage.Number).Return(mockStage, nil)
+
+	orgs := mock.NewMockOrganizationService(controller)
+	orgs.EXPECT().Membership(gomock.Any(), mockUser, "ops").Return(false, false, nil)
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("POST", "/", nil)
+	r = r.WithContext(
+		context.WithValue(request.WithUser(context.Background(), mockUser), chi.RouteCtxKey, newStageContext()),
+	)
+
+	HandleApprove(repos, builds, stages, nil, orgs, nil)(w, r)
+	if got, want := w.Code, 403; want != got {
+		t.Errorf("Want response code %d, got %d", want, got)
+	}
+
+	got, want := new(errors.Error), errNotApprover
+	json.NewDecoder(w.Body).Decode(got)
+	if diff := cmp.Diff(got, want); len(diff) != 0 {
+		t.Errorf(diff)
+	}
+}
+
+// this test verifies that declining a stage records the
+// decline, with the comment, in the audit trail.
+func TestDecline(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	mockRepo := &core.Repository{
+		Namespace: "octocat",
+		Name:      "hello-world",
+		ApprovalRules: []*core.ApprovalRule{
+			{Orgs: []string{"ops"}},
+		},
+	}
+	mockBuild := &core.Build{ID: 111, Number: 1, Status: core.StatusBlocked}
+	mockStage := &core.Stage{ID: 222, Number: 2, Status: core.StatusBlocked}
+
+	repos := mock.NewMockRepositoryStore(controller)
+	repos.EXPECT().FindName(gomock.Any(), mockRepo.Namespace, mockRepo.Name).Return(mockRepo, nil)
+
+	builds := mock.NewMockBuildStore(controller)
+	builds.EXPECT().FindNumber(gomock.Any(), mockRepo.ID, mockBuild.Number).Return(mockBuild, nil)
+	builds.EXPECT().Update(gomock.Any(), mockBuild).Return(nil)
+
+	stages := mock.NewMockStageStore(controller)
+	stages.EXPECT().FindNumber(gomock.Any(), mockBuild.ID, mockStage.Number).Return(mockStage, nil)
+	stages.EXPECT().Update(gomock.Any(), mockStage).Return(nil)
+
+	orgs := mock.NewMockOrganizationService(controller)
+	orgs.EXPECT().Membership(gomock.Any(), mockUser, "ops").Return(true, false, nil)
+
+	checkApproval := func(_ context.Context, approval *core.Approval) {
+		if got, want := approval.Action, core.ApprovalDecline; got != want {
+			t.Errorf("Want approval action %s, got %s", want, got)
+		}
+		if got, want := approval.Comment, "not today"; got != want {
+			t.Errorf("Want approval comment %q, got %q", want, got)
+		}
+	}
+	approvals := mock.NewMockApprovalStore(controller)
+	approvals.EXPECT().Create(gomock.Any(), gomock.Any()).Return(nil).Do(checkApproval)
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("POST", "/", strings.NewReader(`{"comment":"not today"}`))
+	r = r.WithContext(
+		context.WithValue(request.WithUser(context.Background(), mockUser), chi.RouteCtxKey, newStageContext()),
+	)
+
+	HandleDecline(repos, builds, stages, approvals, orgs)(w, r)
+	if got, want := w.Code, 204; want != got {
+		t.Errorf("Want response code %d, got %d", want, got)
+	}
+	if got, want := mockStage.Status, core.StatusDeclined; got != want {
+		t.Errorf("Want stage status %s, got %s", want, got)
+	}
+}
+
+func newStageContext() *chi.Context {
+	c := new(chi.Context)
+	c.URLParams.Add("owner", "octocat")
+	c.URLParams.Add("name", "hello-world")
+	c.URLParams.Add("number", "1")
+	c.URLParams.Add("stage", "2")
+	return c
+}
@@ -18,9 +18,11 @@ import (
 	"context"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/drone/drone/core"
 	"github.com/drone/drone/handler/api/render"
+	"github.com/drone/drone/handler/api/request"
 
 	"github.com/go-chi/chi"
 )
@@ -29,10 +31,15 @@ var noContext = context.Background()
 
 // HandleApprove returns an http.HandlerFunc that processes http
 // requests to approve a blocked build that is pending review.
+// If an approval rule matches the build deployment target, the
+// stage is unblocked once the required number of distinct
+// approvers is reached.
 func HandleApprove(
 	repos core.RepositoryStore,
 	builds core.BuildStore,
 	stages core.StageStore,
+	approvals core.ApprovalStore,
+	orgs core.OrganizationService,
 	sched core.Scheduler,
 ) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
@@ -69,6 +76,46 @@ func HandleApprove(
 			render.BadRequestf(w, "Cannot approve a Pipeline with Status %q", stage.Status)
 			return
 		}
+		comment, err := decodeComment(r)
+		if err != nil {
+			render.BadRequest(w, err)
+			return
+		}
+		user, _ := request.UserFrom(r.Context())
+		rule := core.FindApprovalRule(repo.ApprovalRules, build)
+		if rule != nil {
+			if user.Login == build.Sender {
+				render.Forbidden(w, errSelfApproval)
+				return
+			}
+			ok, err := isApprover(r.Context(), orgs, rule, user)
+			if err != nil {
+				render.InternalErrorf(w, "There was a problem verifying the approver")
+				return
+			}
+			if !ok {
+				render.Forbidden(w, errNotApprover)
+				return
+			}
+		}
+		err = approvals.Create(r.Context(), newApproval(build, stage, user, core.ApprovalApprove, comment))
+		if err != nil {
+			render.InternalErrorf(w, "There was a problem saving the approval")
+			return
+		}
+		if rule != nil {
+			list, err := approvals.List(r.Context(), build.ID)
+			if err != nil {
+				render.InternalErrorf(w, "There was a problem counting the approvals")
+				return
+			}
+			// the stage remains blocked until the required
+			// number of distinct approvers is reached.
+			if len(rule.Approvers(list, stage.ID, time.Now())) < rule.Quorum() {
+				w.WriteHeader(http.StatusAccepted)
+				return
+			}
+		}
 		stage.Status = core.StatusPending
 		err = stages.Update(r.Context(), stage)
 		if err != nil {
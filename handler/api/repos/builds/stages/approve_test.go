@@ -13,6 +13,7 @@ import (
 	"testing"
 
 	"github.com/drone/drone/handler/api/errors"
+	"github.com/drone/drone/handler/api/request"
 	"github.com/drone/drone/mock"
 	"github.com/drone/drone/core"
 
@@ -62,6 +63,9 @@ func TestApprove(t *testing.T) {
 	sched := mock.NewMockScheduler(controller)
 	sched.EXPECT().Schedule(gomock.Any(), mockStage).Return(nil)
 
+	approvals := mock.NewMockApprovalStore(controller)
+	approvals.EXPECT().Create(gomock.Any(), gomock.Any()).Return(nil)
+
 	c := new(chi.Context)
 	c.URLParams.Add("owner", "octocat")
 	c.URLParams.Add("name", "hello-world")
@@ -71,10 +75,10 @@ func TestApprove(t *testing.T) {
 	w := httptest.NewRecorder()
 	r := httptest.NewRequest("GET", "/", nil)
 	r = r.WithContext(
-		context.WithValue(context.Background(), chi.RouteCtxKey, c),
+		context.WithValue(request.WithUser(context.Background(), mockUser), chi.RouteCtxKey, c),
 	)
 
-	HandleApprove(repos, builds, stages, sched)(w, r)
+	HandleApprove(repos, builds, stages, approvals, nil, sched)(w, r)
 	if got, want := w.Code, 204; want != got {
 		t.Errorf("Want response code %d, got %d", want, got)
 	}
@@ -125,7 +129,7 @@ func TestApprove_InvalidStatus(t *testing.T) {
 		context.WithValue(context.Background(), chi.RouteCtxKey, c),
 	)
 
-	HandleApprove(repos, builds, stages, nil)(w, r)
+	HandleApprove(repos, builds, stages, nil, nil, nil)(w, r)
 	if got, want := w.Code, 400; want != got {
 		t.Errorf("Want response code %d, got %d", want, got)
 	}
@@ -153,7 +157,7 @@ func TestApprove_InvalidBuildNumber(t *testing.T) {
 		context.WithValue(context.Background(), chi.RouteCtxKey, c),
 	)
 
-	HandleApprove(nil, nil, nil, nil)(w, r)
+	HandleApprove(nil, nil, nil, nil, nil, nil)(w, r)
 	if got, want := w.Code, 400; want != got {
 		t.Errorf("Want response code %d, got %d", want, got)
 	}
@@ -181,7 +185,7 @@ func TestApprove_InvalidStageNumber(t *testing.T) {
 		context.WithValue(context.Background(), chi.RouteCtxKey, c),
 	)
 
-	HandleApprove(nil, nil, nil, nil)(w, r)
+	HandleApprove(nil, nil, nil, nil, nil, nil)(w, r)
 	if got, want := w.Code, 400; want != got {
 		t.Errorf("Want response code %d, got %d", want, got)
 	}
@@ -231,7 +235,7 @@ func TestApprove_StageNotFound(t *testing.T) {
 		context.WithValue(context.Background(), chi.RouteCtxKey, c),
 	)
 
-	HandleApprove(repos, builds, stages, nil)(w, r)
+	HandleApprove(repos, builds, stages, nil, nil, nil)(w, r)
 	if got, want := w.Code, 404; want != got {
 		t.Errorf("Want response code %d, got %d", want, got)
 	}
@@ -273,7 +277,7 @@ func TestApprove_BuildNotFound(t *testing.T) {
 		context.WithValue(context.Background(), chi.RouteCtxKey, c),
 	)
 
-	HandleApprove(repos, builds, nil, nil)(w, r)
+	HandleApprove(repos, builds, nil, nil, nil, nil)(w, r)
 	if got, want := w.Code, 404; want != got {
 		t.Errorf("Want response code %d, got %d", want, got)
 	}
@@ -312,7 +316,7 @@ func TestApprove_RepoNotFound(t *testing.T) {
 		context.WithValue(context.Background(), chi.RouteCtxKey, c),
 	)
 
-	HandleApprove(repos, nil, nil, nil)(w, r)
+	HandleApprove(repos, nil, nil, nil, nil, nil)(w, r)
 	if got, want := w.Code, 404; want != got {
 		t.Errorf("Want response code %d, got %d", want, got)
 	}
@@ -358,6 +362,9 @@ func TestApprove_CannotSaveStage(t *testing.T) {
 	stages.EXPECT().FindNumber(gomock.Any(), mockBuild.ID, mockStage.Number).Return(mockStage, nil)
 	stages.EXPECT().Update(gomock.Any(), mockStage).Return(sql.ErrConnDone)
 
+	approvals := mock.NewMockApprovalStore(controller)
+	approvals.EXPECT().Create(gomock.Any(), gomock.Any()).Return(nil)
+
 	c := new(chi.Context)
 	c.URLParams.Add("owner", "octocat")
 	c.URLParams.Add("name", "hello-world")
@@ -367,10 +374,10 @@ func TestApprove_CannotSaveStage(t *testing.T) {
 	w := httptest.NewRecorder()
 	r := httptest.NewRequest("GET", "/", nil)
 	r = r.WithContext(
-		context.WithValue(context.Background(), chi.RouteCtxKey, c),
+		context.WithValue(request.WithUser(context.Background(), mockUser), chi.RouteCtxKey, c),
 	)
 
-	HandleApprove(repos, builds, stages, nil)(w, r)
+	HandleApprove(repos, builds, stages, approvals, nil, nil)(w, r)
 	if got, want := w.Code, 500; want != got {
 		t.Errorf("Want response code %d, got %d", want, got)
 	}
@@ -419,6 +426,9 @@ func TestApprove_CannotEnqueue(t *testing.T) {
 	sched := mock.NewMockScheduler(controller)
 	sched.EXPECT().Schedule(gomock.Any(), gomock.Any()).Return(io.EOF)
 
+	approvals := mock.NewMockApprovalStore(controller)
+	approvals.EXPECT().Create(gomock.Any(), gomock.Any()).Return(nil)
+
 	c := new(chi.Context)
 	c.URLParams.Add("owner", "octocat")
 	c.URLParams.Add("name", "hello-world")
@@ -428,10 +438,10 @@ func TestApprove_CannotEnqueue(t *testing.T) {
 	w := httptest.NewRecorder()
 	r := httptest.NewRequest("GET", "/", nil)
 	r = r.WithContext(
-		context.WithValue(context.Background(), chi.RouteCtxKey, c),
+		context.WithValue(request.WithUser(context.Background(), mockUser), chi.RouteCtxKey, c),
 	)
 
-	HandleApprove(repos, builds, stages, sched)(w, r)
+	HandleApprove(repos, builds, stages, approvals, nil, sched)(w, r)
 	if got, want := w.Code, 500; want != got {
 		t.Errorf("Want response code %d, got %d", want, got)
 	}
@@ -21,6 +21,7 @@ import (
 
 	"github.com/drone/drone/core"
 	"github.com/drone/drone/handler/api/render"
+	"github.com/drone/drone/handler/api/request"
 
 	"github.com/go-chi/chi"
 )
@@ -31,6 +32,8 @@ func HandleDecline(
 	repos core.RepositoryStore,
 	builds core.BuildStore,
 	stages core.StageStore,
+	approvals core.ApprovalStore,
+	orgs core.OrganizationService,
 ) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		var (
@@ -67,6 +70,28 @@ func HandleDecline(
 			render.BadRequest(w, err)
 			return
 		}
+		comment, err := decodeComment(r)
+		if err != nil {
+			render.BadRequest(w, err)
+			return
+		}
+		user, _ := request.UserFrom(r.Context())
+		if rule := core.FindApprovalRule(repo.ApprovalRules, build); rule != nil {
+			ok, err := isApprover(r.Context(), orgs, rule, user)
+			if err != nil {
+				render.InternalErrorf(w, "There was a problem verifying the approver")
+				return
+			}
+			if !ok {
+				render.Forbidden(w, errNotApprover)
+				return
+			}
+		}
+		err = approvals.Create(r.Context(), newApproval(build, stage, user, core.ApprovalDecline, comment))
+		if err != nil {
+			render.InternalErrorf(w, "There was a problem saving the approval")
+			return
+		}
 		stage.Status = core.StatusDeclined
 		err = stages.Update(r.Context(), stage)
 		if err != nil {
@@ -0,0 +1,209 @@
+// Copyright 2019 Drone.IO Inc. All rights reserved.
+// Use of this source code is governed by the Drone Non-Commercial License
+// that can be found in the LICENSE file.
+
+// +build !oss
+
+package channels
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/drone/drone/core"
+	"github.com/drone/drone/mock"
+
+	"github.com/go-chi/chi"
+	"github.com/golang/mock/gomock"
+	"github.com/google/go-cmp/cmp"
+)
+
+var (
+	dummyChannelRepo = &core.Repository{
+		ID:        1,
+		Namespace: "octocat",
+		Name:      "hello-world",
+	}
+
+	dummyChannel = &core.Channel{
+		ID:        2,
+		RepoID:    1,
+		Namespace: "octocat",
+		Name:      "slack",
+		Kind:      core.ChannelSlack,
+		Endpoint:  "https://hooks.slack.com/services/T0/B0/X",
+		Events:    []string{"failure"},
+	}
+
+	dummyChannelScrubbed = &core.Channel{
+		ID:        2,
+		RepoID:    1,
+		Namespace: "octocat",
+		Name:      "slack",
+		Kind:      core.ChannelSlack,
+		Events:    []string{"failure"},
+	}
+)
+
+func TestHandleList(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	repos := mock.NewMockRepositoryStore(controller)
+	repos.EXPECT().FindName(gomock.Any(), dummyChannelRepo.Namespace, dummyChannelRepo.Name).Return(dummyChannelRepo, nil)
+
+	channels := mock.NewMockChannelStore(controller)
+	channels.EXPECT().List(gomock.Any(), dummyChannelRepo.ID).Return([]*core.Channel{dummyChannel}, nil)
+
+	c := new(chi.Context)
+	c.URLParams.Add("owner", "octocat")
+	c.URLParams.Add("name", "hello-world")
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("GET", "/", nil)
+	r = r.WithContext(
+		context.WithValue(context.Background(), chi.RouteCtxKey, c),
+	)
+
+	HandleList(repos, channels).ServeHTTP(w, r)
+	if got, want := w.Code, http.StatusOK; want != got {
+		t.Errorf("Want response code %d, got %d", want, got)
+	}
+
+	got, want := []*core.Channel{}, []*core.Channel{dummyChannelScrubbed}
+	json.NewDecoder(w.Body).Decode(&got)
+	if diff := cmp.Diff(got, want); len(diff) != 0 {
+		t.Errorf(diff)
+	}
+}
+
+// this test verifies that a channel that belongs to another
+// repository cannot be accessed.
+func TestHandleFind_RepoMismatch(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	other := &core.Channel{ID: 2, RepoID: 99}
+
+	repos := mock.NewMockRepositoryStore(controller)
+	repos.EXPECT().FindName(gomock.Any(), dummyChannelRepo.Namespace, dummyChannelRepo.Name).Return(dummyChannelRepo, nil)
+
+	channels := mock.NewMockChannelStore(controller)
+	channels.EXPECT().Find(gomock.Any(), other.ID).Return(other, nil)
+
+	c := new(chi.Context)
+	c.URLParams.Add("owner", "octocat")
+	c.URLParams.Add("name", "hello-world")
+	c.URLParams.Add("channel", "2")
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("GET", "/", nil)
+	r = r.WithContext(
+		context.WithValue(context.Background(), chi.RouteCtxKey, c),
+	)
+
+	HandleFind(repos, channels).ServeHTTP(w, r)
+	if got, want := w.Code, http.StatusNotFound; want != got {
+		t.Errorf("Want response code %d, got %d", want, got)
+	}
+}
+
+func TestHandleCreate(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	repos := mock.NewMockRepositoryStore(controller)
+	repos.EXPECT().FindName(gomock.Any(), dummyChannelRepo.Namespace, dummyChannelRepo.Name).Return(dummyChannelRepo, nil)
+
+	channels := mock.NewMockChannelStore(controller)
+	channels.EXPECT().Create(gomock.Any(), gomock.Any()).Do(func(_ context.Context, in *core.Channel) {
+		if got, want := in.RepoID, dummyChannelRepo.ID; got != want {
+			t.Errorf("Want channel repository id %d, got %d", want, got)
+		}
+		if got, want := in.Endpoint, dummyChannel.Endpoint; got != want {
+			t.Errorf("Want channel endpoint %q, got %q", want, got)
+		}
+	}).Return(nil)
+
+	c := new(chi.Context)
+	c.URLParams.Add("owner", "octocat")
+	c.URLParams.Add("name", "hello-world")
+
+	in := new(bytes.Buffer)
+	json.NewEncoder(in).Encode(dummyChannel)
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("POST", "/", in)
+	r = r.WithContext(
+		context.WithValue(context.Background(), chi.RouteCtxKey, c),
+	)
+
+	HandleCreate(repos, channels).ServeHTTP(w, r)
+	if got, want := w.Code, http.StatusOK; want != got {
+		t.Errorf("Want response code %d, got %d", want, got)
+	}
+
+	got := &core.Channel{}
+	json.NewDecoder(w.Body).Decode(got)
+	if got.Endpoint != "" {
+		t.Errorf("Want channel endpoint removed from response")
+	}
+}
+
+func TestHandleCreate_ValidationError(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	repos := mock.NewMockRepositoryStore(controller)
+	repos.EXPECT().FindName(gomock.Any(), dummyChannelRepo.Namespace, dummyChannelRepo.Name).Return(dummyChannelRepo, nil)
+
+	c := new(chi.Context)
+	c.URLParams.Add("owner", "octocat")
+	c.URLParams.Add("name", "hello-world")
+
+	in := new(bytes.Buffer)
+	json.NewEncoder(in).Encode(&core.Channel{Name: "irc", Kind: "irc"})
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("POST", "/", in)
+	r = r.WithContext(
+		context.WithValue(context.Background(), chi.RouteCtxKey, c),
+	)
+
+	HandleCreate(repos, nil).ServeHTTP(w, r)
+	if got, want := w.Code, http.StatusBadRequest; want != got {
+		t.Errorf("Want response code %d, got %d", want, got)
+	}
+}
+
+func TestHandleDelete(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	repos := mock.NewMockRepositoryStore(controller)
+	repos.EXPECT().FindName(gomock.Any(), dummyChannelRepo.Namespace, dummyChannelRepo.Name).Return(dummyChannelRepo, nil)
+
+	channels := mock.NewMockChannelStore(controller)
+	channels.EXPECT().Find(gomock.Any(), dummyChannel.ID).Return(dummyChannel, nil)
+	channels.EXPECT().Delete(gomock.Any(), dummyChannel).Return(nil)
+
+	c := new(chi.Context)
+	c.URLParams.Add("owner", "octocat")
+	c.URLParams.Add("name", "hello-world")
+	c.URLParams.Add("channel", "2")
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("DELETE", "/", nil)
+	r = r.WithContext(
+		context.WithValue(context.Background(), chi.RouteCtxKey, c),
+	)
+
+	HandleDelete(repos, channels).ServeHTTP(w, r)
+	if got, want := w.Code, http.StatusNoContent; want != got {
+		t.Errorf("Want response code %d, got %d", want, got)
+	}
+}
@@ -0,0 +1,58 @@
+// Copyright 2019 Drone.IO Inc. All rights reserved.
+// Use of this source code is governed by the Drone Non-Commercial License
+// that can be found in the LICENSE file.
+
+// +build !oss
+
+package channels
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/drone/drone/core"
+	"github.com/drone/drone/handler/api/errors"
+	"github.com/drone/drone/handler/api/render"
+
+	"github.com/go-chi/chi"
+)
+
+// HandleDelete returns an http.HandlerFunc that processes http
+// requests to delete a repository notification channel.
+func HandleDelete(
+	repos core.RepositoryStore,
+	channels core.ChannelStore,
+) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var (
+			namespace = chi.URLParam(r, "owner")
+			name      = chi.URLParam(r, "name")
+		)
+		id, err := strconv.ParseInt(chi.URLParam(r, "channel"), 10, 64)
+		if err != nil {
+			render.BadRequest(w, err)
+			return
+		}
+		repo, err := repos.FindName(r.Context(), namespace, name)
+		if err != nil {
+			render.NotFound(w, err)
+			return
+		}
+		c, err := channels.Find(r.Context(), id)
+		if err != nil {
+			render.NotFound(w, err)
+			return
+		}
+		if c.RepoID != repo.ID {
+			render.NotFound(w, errors.ErrNotFound)
+			return
+		}
+
+		err = channels.Delete(r.Context(), c)
+		if err != nil {
+			render.InternalError(w, err)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
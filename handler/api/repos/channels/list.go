@@ -0,0 +1,47 @@
+// Copyright 2019 Drone.IO Inc. All rights reserved.
+// Use of this source code is governed by the Drone Non-Commercial License
+// that can be found in the LICENSE file.
+
+// +build !oss
+
+package channels
+
+import (
+	"net/http"
+
+	"github.com/drone/drone/core"
+	"github.com/drone/drone/handler/api/render"
+
+	"github.com/go-chi/chi"
+)
+
+// HandleList returns an http.HandlerFunc that writes a json-encoded
+// list of repository notification channels to the response body.
+func HandleList(
+	repos core.RepositoryStore,
+	channels core.ChannelStore,
+) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var (
+			namespace = chi.URLParam(r, "owner")
+			name      = chi.URLParam(r, "name")
+		)
+		repo, err := repos.FindName(r.Context(), namespace, name)
+		if err != nil {
+			render.NotFound(w, err)
+			return
+		}
+		list, err := channels.List(r.Context(), repo.ID)
+		if err != nil {
+			render.InternalError(w, err)
+			return
+		}
+		// the channel list is copied and the channel endpoint
+		// is removed from the response.
+		out := []*core.Channel{}
+		for _, channel := range list {
+			out = append(out, channel.Copy())
+		}
+		render.JSON(w, out, 200)
+	}
+}
@@ -0,0 +1,96 @@
+// Copyright 2019 Drone.IO Inc. All rights reserved.
+// Use of this source code is governed by the Drone Non-Commercial License
+// that can be found in the LICENSE file.
+
+// +build !oss
+
+package channels
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/drone/drone/core"
+	"github.com/drone/drone/handler/api/errors"
+	"github.com/drone/drone/handler/api/render"
+
+	"github.com/go-chi/chi"
+)
+
+type channelUpdate struct {
+	Kind     *string  `json:"kind"`
+	Endpoint *string  `json:"endpoint"`
+	Template *string  `json:"template"`
+	Events   []string `json:"events"`
+}
+
+// HandleUpdate returns an http.HandlerFunc that processes http
+// requests to update a repository notification channel.
+func HandleUpdate(
+	repos core.RepositoryStore,
+	channels core.ChannelStore,
+) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var (
+			namespace = chi.URLParam(r, "owner")
+			name      = chi.URLParam(r, "name")
+		)
+		id, err := strconv.ParseInt(chi.URLParam(r, "channel"), 10, 64)
+		if err != nil {
+			render.BadRequest(w, err)
+			return
+		}
+
+		in := new(channelUpdate)
+		err = json.NewDecoder(r.Body).Decode(in)
+		if err != nil {
+			render.BadRequest(w, err)
+			return
+		}
+
+		repo, err := repos.FindName(r.Context(), namespace, name)
+		if err != nil {
+			render.NotFound(w, err)
+			return
+		}
+
+		c, err := channels.Find(r.Context(), id)
+		if err != nil {
+			render.NotFound(w, err)
+			return
+		}
+		if c.RepoID != repo.ID {
+			render.NotFound(w, errors.ErrNotFound)
+			return
+		}
+
+		if in.Kind != nil {
+			c.Kind = *in.Kind
+		}
+		if in.Endpoint != nil {
+			c.Endpoint = *in.Endpoint
+		}
+		if in.Template != nil {
+			c.Template = *in.Template
+		}
+		if in.Events != nil {
+			c.Events = in.Events
+		}
+		c.Updated = time.Now().Unix()
+
+		err = c.Validate()
+		if err != nil {
+			render.BadRequest(w, err)
+			return
+		}
+
+		err = channels.Update(r.Context(), c)
+		if err != nil {
+			render.InternalError(w, err)
+			return
+		}
+		render.JSON(w, c.Copy(), 200)
+	}
+}
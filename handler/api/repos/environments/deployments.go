@@ -0,0 +1,67 @@
+// Copyright 2019 Drone.IO Inc. All rights reserved.
+// Use of this source code is governed by the Drone Non-Commercial License
+// that can be found in the LICENSE file.
+
+// +build !oss
+
+package environments
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/drone/drone/core"
+	"github.com/drone/drone/handler/api/render"
+
+	"github.com/go-chi/chi"
+)
+
+// HandleDeployments returns an http.HandlerFunc that writes a
+// json-encoded timeline of deployments to the environment, most
+// recent first, to the response body.
+func HandleDeployments(
+	repos core.RepositoryStore,
+	environments core.EnvironmentStore,
+	builds core.BuildStore,
+) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var (
+			namespace = chi.URLParam(r, "owner")
+			name      = chi.URLParam(r, "name")
+			environ   = chi.URLParam(r, "environment")
+			page      = r.FormValue("page")
+			perPage   = r.FormValue("per_page")
+		)
+		offset, _ := strconv.Atoi(page)
+		limit, _ := strconv.Atoi(perPage)
+		if limit < 1 || limit > 100 {
+			limit = 25
+		}
+		switch offset {
+		case 0, 1:
+			offset = 0
+		default:
+			offset = (offset - 1) * limit
+		}
+		repo, err := repos.FindName(r.Context(), namespace, name)
+		if err != nil {
+			render.NotFound(w, err)
+			return
+		}
+		env, err := environments.FindName(r.Context(), repo.ID, environ)
+		if err != nil {
+			render.NotFound(w, err)
+			return
+		}
+		results, err := builds.Search(r.Context(), repo.ID, core.BuildParams{
+			Deploy: env.Name,
+			Limit:  limit,
+			Offset: offset,
+		})
+		if err != nil {
+			render.InternalError(w, err)
+			return
+		}
+		render.JSON(w, results, 200)
+	}
+}
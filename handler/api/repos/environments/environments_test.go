@@ -0,0 +1,149 @@
+// Copyright 2019 Drone.IO Inc. All rights reserved.
+// Use of this source code is governed by the Drone Non-Commercial License
+// that can be found in the LICENSE file.
+
+// +build !oss
+
+package environments
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/drone/drone/core"
+	"github.com/drone/drone/mock"
+
+	"github.com/go-chi/chi"
+	"github.com/golang/mock/gomock"
+	"github.com/google/go-cmp/cmp"
+)
+
+var (
+	dummyRepo = &core.Repository{
+		ID:        1,
+		Namespace: "octocat",
+		Name:      "hello-world",
+	}
+
+	dummyBuild = &core.Build{
+		ID:     2,
+		RepoID: 1,
+		Number: 3,
+		Deploy: "production",
+		Status: core.StatusPassing,
+	}
+)
+
+func TestHandleList(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	env := &core.Environment{RepoID: 1, Name: "production"}
+
+	repos := mock.NewMockRepositoryStore(controller)
+	repos.EXPECT().FindName(gomock.Any(), dummyRepo.Namespace, dummyRepo.Name).Return(dummyRepo, nil)
+
+	environments := mock.NewMockEnvironmentStore(controller)
+	environments.EXPECT().List(gomock.Any(), dummyRepo.ID).Return([]*core.Environment{env}, nil)
+
+	params := core.BuildParams{
+		Deploy: "production",
+		Status: []string{core.StatusPassing},
+		Limit:  1,
+	}
+	builds := mock.NewMockBuildStore(controller)
+	builds.EXPECT().Search(gomock.Any(), dummyRepo.ID, params).Return([]*core.Build{dummyBuild}, nil)
+
+	c := new(chi.Context)
+	c.URLParams.Add("owner", "octocat")
+	c.URLParams.Add("name", "hello-world")
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("GET", "/", nil)
+	r = r.WithContext(
+		context.WithValue(context.Background(), chi.RouteCtxKey, c),
+	)
+
+	HandleList(repos, environments, builds).ServeHTTP(w, r)
+	if got, want := w.Code, http.StatusOK; want != got {
+		t.Errorf("Want response code %d, got %d", want, got)
+	}
+
+	got, want := []*environment{}, []*environment{{env, dummyBuild}}
+	json.NewDecoder(w.Body).Decode(&got)
+	if diff := cmp.Diff(got, want); len(diff) != 0 {
+		t.Errorf(diff)
+	}
+}
+
+func TestHandleCreate_Invalid(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	repos := mock.NewMockRepositoryStore(controller)
+	repos.EXPECT().FindName(gomock.Any(), dummyRepo.Namespace, dummyRepo.Name).Return(dummyRepo, nil)
+
+	c := new(chi.Context)
+	c.URLParams.Add("owner", "octocat")
+	c.URLParams.Add("name", "hello-world")
+
+	in := new(bytes.Buffer)
+	json.NewEncoder(in).Encode(&environmentInput{Name: "production cluster"})
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("POST", "/", in)
+	r = r.WithContext(
+		context.WithValue(context.Background(), chi.RouteCtxKey, c),
+	)
+
+	HandleCreate(repos, nil).ServeHTTP(w, r)
+	if got, want := w.Code, http.StatusBadRequest; want != got {
+		t.Errorf("Want response code %d, got %d", want, got)
+	}
+}
+
+func TestHandleSecretCreate(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	env := &core.Environment{RepoID: 1, Name: "production"}
+
+	repos := mock.NewMockRepositoryStore(controller)
+	repos.EXPECT().FindName(gomock.Any(), dummyRepo.Namespace, dummyRepo.Name).Return(dummyRepo, nil)
+
+	environments := mock.NewMockEnvironmentStore(controller)
+	environments.EXPECT().FindName(gomock.Any(), dummyRepo.ID, env.Name).Return(env, nil)
+	environments.EXPECT().Update(gomock.Any(), env).Return(nil)
+
+	c := new(chi.Context)
+	c.URLParams.Add("owner", "octocat")
+	c.URLParams.Add("name", "hello-world")
+	c.URLParams.Add("environment", "production")
+
+	in := new(bytes.Buffer)
+	json.NewEncoder(in).Encode(&secretInput{Name: "kubeconfig", Data: "correct-horse-battery-staple"})
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("POST", "/", in)
+	r = r.WithContext(
+		context.WithValue(context.Background(), chi.RouteCtxKey, c),
+	)
+
+	HandleSecretCreate(repos, environments).ServeHTTP(w, r)
+	if got, want := w.Code, http.StatusOK; want != got {
+		t.Errorf("Want response code %d, got %d", want, got)
+	}
+	if got := env.Secret("kubeconfig"); got == nil || got.Data != "correct-horse-battery-staple" {
+		t.Errorf("Want secret added to the environment")
+	}
+
+	got := new(core.Secret)
+	json.NewDecoder(w.Body).Decode(got)
+	if got.Data != "" {
+		t.Errorf("Want secret value removed from the response")
+	}
+}
@@ -0,0 +1,49 @@
+// Copyright 2019 Drone.IO Inc. All rights reserved.
+// Use of this source code is governed by the Drone Non-Commercial License
+// that can be found in the LICENSE file.
+
+// +build !oss
+
+package environments
+
+import (
+	"net/http"
+
+	"github.com/drone/drone/core"
+	"github.com/drone/drone/handler/api/render"
+
+	"github.com/go-chi/chi"
+)
+
+// HandleFind returns an http.HandlerFunc that writes a json-encoded
+// environment, with its currently deployed version, to the response
+// body.
+func HandleFind(
+	repos core.RepositoryStore,
+	environments core.EnvironmentStore,
+	builds core.BuildStore,
+) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var (
+			namespace = chi.URLParam(r, "owner")
+			name      = chi.URLParam(r, "name")
+			environ   = chi.URLParam(r, "environment")
+		)
+		repo, err := repos.FindName(r.Context(), namespace, name)
+		if err != nil {
+			render.NotFound(w, err)
+			return
+		}
+		env, err := environments.FindName(r.Context(), repo.ID, environ)
+		if err != nil {
+			render.NotFound(w, err)
+			return
+		}
+		current, err := findCurrent(r.Context(), builds, env)
+		if err != nil {
+			render.InternalError(w, err)
+			return
+		}
+		render.JSON(w, &environment{env, current}, 200)
+	}
+}
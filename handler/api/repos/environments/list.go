@@ -0,0 +1,75 @@
+// Copyright 2019 Drone.IO Inc. All rights reserved.
+// Use of this source code is governed by the Drone Non-Commercial License
+// that can be found in the LICENSE file.
+
+// +build !oss
+
+package environments
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/drone/drone/core"
+	"github.com/drone/drone/handler/api/render"
+
+	"github.com/go-chi/chi"
+)
+
+// environment is a json-encoded environment with its current
+// deployment.
+type environment struct {
+	*core.Environment
+	Current *core.Build `json:"current,omitempty"`
+}
+
+// HandleList returns an http.HandlerFunc that writes a json-encoded
+// list of environments, with the currently deployed version of each
+// environment, to the response body.
+func HandleList(
+	repos core.RepositoryStore,
+	environments core.EnvironmentStore,
+	builds core.BuildStore,
+) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var (
+			namespace = chi.URLParam(r, "owner")
+			name      = chi.URLParam(r, "name")
+		)
+		repo, err := repos.FindName(r.Context(), namespace, name)
+		if err != nil {
+			render.NotFound(w, err)
+			return
+		}
+		list, err := environments.List(r.Context(), repo.ID)
+		if err != nil {
+			render.InternalError(w, err)
+			return
+		}
+		out := []*environment{}
+		for _, env := range list {
+			current, err := findCurrent(r.Context(), builds, env)
+			if err != nil {
+				render.InternalError(w, err)
+				return
+			}
+			out = append(out, &environment{env, current})
+		}
+		render.JSON(w, out, 200)
+	}
+}
+
+// helper function returns the most recent successful
+// deployment to the environment, or nil if the environment
+// was never deployed.
+func findCurrent(ctx context.Context, builds core.BuildStore, env *core.Environment) (*core.Build, error) {
+	list, err := builds.Search(ctx, env.RepoID, core.BuildParams{
+		Deploy: env.Name,
+		Status: []string{core.StatusPassing},
+		Limit:  1,
+	})
+	if err != nil || len(list) == 0 {
+		return nil, err
+	}
+	return list[0], nil
+}
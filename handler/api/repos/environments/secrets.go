@@ -0,0 +1,197 @@
+// Copyright 2019 Drone.IO Inc. All rights reserved.
+// Use of this source code is governed by the Drone Non-Commercial License
+// that can be found in the LICENSE file.
+
+// +build !oss
+
+package environments
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/drone/drone/core"
+	"github.com/drone/drone/handler/api/errors"
+	"github.com/drone/drone/handler/api/render"
+
+	"github.com/go-chi/chi"
+)
+
+var errSecretExists = errors.New("Secret already exists")
+
+type secretInput struct {
+	Name string `json:"name"`
+	Data string `json:"data"`
+}
+
+// HandleSecretList returns an http.HandlerFunc that writes a
+// json-encoded list of environment secrets to the response body.
+// The secret values are removed from the response.
+func HandleSecretList(
+	repos core.RepositoryStore,
+	environments core.EnvironmentStore,
+) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var (
+			namespace = chi.URLParam(r, "owner")
+			name      = chi.URLParam(r, "name")
+			environ   = chi.URLParam(r, "environment")
+		)
+		repo, err := repos.FindName(r.Context(), namespace, name)
+		if err != nil {
+			render.NotFound(w, err)
+			return
+		}
+		env, err := environments.FindName(r.Context(), repo.ID, environ)
+		if err != nil {
+			render.NotFound(w, err)
+			return
+		}
+		secrets := []*core.Secret{}
+		for _, secret := range env.Secrets {
+			secrets = append(secrets, secret.Copy())
+		}
+		render.JSON(w, secrets, 200)
+	}
+}
+
+// HandleSecretCreate returns an http.HandlerFunc that processes
+// http requests to add a secret to the environment.
+func HandleSecretCreate(
+	repos core.RepositoryStore,
+	environments core.EnvironmentStore,
+) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var (
+			namespace = chi.URLParam(r, "owner")
+			name      = chi.URLParam(r, "name")
+			environ   = chi.URLParam(r, "environment")
+		)
+		repo, err := repos.FindName(r.Context(), namespace, name)
+		if err != nil {
+			render.NotFound(w, err)
+			return
+		}
+		env, err := environments.FindName(r.Context(), repo.ID, environ)
+		if err != nil {
+			render.NotFound(w, err)
+			return
+		}
+		in := new(secretInput)
+		err = json.NewDecoder(r.Body).Decode(in)
+		if err != nil {
+			render.BadRequest(w, err)
+			return
+		}
+
+		s := &core.Secret{
+			RepoID: repo.ID,
+			Name:   in.Name,
+			Data:   in.Data,
+		}
+		err = s.Validate()
+		if err != nil {
+			render.BadRequest(w, err)
+			return
+		}
+		if env.Secret(s.Name) != nil {
+			render.BadRequest(w, errSecretExists)
+			return
+		}
+
+		env.SetSecret(s)
+		env.Updated = time.Now().Unix()
+		err = environments.Update(r.Context(), env)
+		if err != nil {
+			render.InternalError(w, err)
+			return
+		}
+		render.JSON(w, s.Copy(), 200)
+	}
+}
+
+// HandleSecretUpdate returns an http.HandlerFunc that processes
+// http requests to update an environment secret.
+func HandleSecretUpdate(
+	repos core.RepositoryStore,
+	environments core.EnvironmentStore,
+) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var (
+			namespace = chi.URLParam(r, "owner")
+			name      = chi.URLParam(r, "name")
+			environ   = chi.URLParam(r, "environment")
+			secret    = chi.URLParam(r, "secret")
+		)
+		repo, err := repos.FindName(r.Context(), namespace, name)
+		if err != nil {
+			render.NotFound(w, err)
+			return
+		}
+		env, err := environments.FindName(r.Context(), repo.ID, environ)
+		if err != nil {
+			render.NotFound(w, err)
+			return
+		}
+		s := env.Secret(secret)
+		if s == nil {
+			render.NotFound(w, errors.ErrNotFound)
+			return
+		}
+		in := new(secretInput)
+		err = json.NewDecoder(r.Body).Decode(in)
+		if err != nil {
+			render.BadRequest(w, err)
+			return
+		}
+		if in.Data != "" {
+			s.Data = in.Data
+		}
+
+		env.Updated = time.Now().Unix()
+		err = environments.Update(r.Context(), env)
+		if err != nil {
+			render.InternalError(w, err)
+			return
+		}
+		render.JSON(w, s.Copy(), 200)
+	}
+}
+
+// HandleSecretDelete returns an http.HandlerFunc that processes
+// http requests to remove a secret from the environment.
+func HandleSecretDelete(
+	repos core.RepositoryStore,
+	environments core.EnvironmentStore,
+) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var (
+			namespace = chi.URLParam(r, "owner")
+			name      = chi.URLParam(r, "name")
+			environ   = chi.URLParam(r, "environment")
+			secret    = chi.URLParam(r, "secret")
+		)
+		repo, err := repos.FindName(r.Context(), namespace, name)
+		if err != nil {
+			render.NotFound(w, err)
+			return
+		}
+		env, err := environments.FindName(r.Context(), repo.ID, environ)
+		if err != nil {
+			render.NotFound(w, err)
+			return
+		}
+		if !env.RemoveSecret(secret) {
+			render.NotFound(w, errors.ErrNotFound)
+			return
+		}
+		env.Updated = time.Now().Unix()
+		err = environments.Update(r.Context(), env)
+		if err != nil {
+			render.InternalError(w, err)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
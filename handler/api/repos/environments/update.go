@@ -0,0 +1,79 @@
+// Copyright 2019 Drone.IO Inc. All rights reserved.
+// Use of this source code is governed by the Drone Non-Commercial License
+// that can be found in the LICENSE file.
+
+// +build !oss
+
+package environments
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/drone/drone/core"
+	"github.com/drone/drone/handler/api/render"
+
+	"github.com/go-chi/chi"
+)
+
+type environmentUpdate struct {
+	Description *string   `json:"description"`
+	Branches    *[]string `json:"branches"`
+	Users       *[]string `json:"users"`
+}
+
+// HandleUpdate returns an http.HandlerFunc that processes http
+// requests to update the environment protection rules.
+func HandleUpdate(
+	repos core.RepositoryStore,
+	environments core.EnvironmentStore,
+) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var (
+			namespace = chi.URLParam(r, "owner")
+			name      = chi.URLParam(r, "name")
+			environ   = chi.URLParam(r, "environment")
+		)
+		repo, err := repos.FindName(r.Context(), namespace, name)
+		if err != nil {
+			render.NotFound(w, err)
+			return
+		}
+		env, err := environments.FindName(r.Context(), repo.ID, environ)
+		if err != nil {
+			render.NotFound(w, err)
+			return
+		}
+
+		in := new(environmentUpdate)
+		err = json.NewDecoder(r.Body).Decode(in)
+		if err != nil {
+			render.BadRequest(w, err)
+			return
+		}
+		if in.Description != nil {
+			env.Description = *in.Description
+		}
+		if in.Branches != nil {
+			env.Branches = *in.Branches
+		}
+		if in.Users != nil {
+			env.Users = *in.Users
+		}
+		env.Updated = time.Now().Unix()
+
+		err = env.Validate()
+		if err != nil {
+			render.BadRequest(w, err)
+			return
+		}
+
+		err = environments.Update(r.Context(), env)
+		if err != nil {
+			render.InternalError(w, err)
+			return
+		}
+		render.JSON(w, env, 200)
+	}
+}
@@ -0,0 +1,104 @@
+// Copyright 2019 Drone IO, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package evaluate
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/drone/drone/core"
+	"github.com/drone/drone/handler/api/render"
+	"github.com/drone/drone/handler/api/request"
+	"github.com/drone/go-scm/scm"
+
+	"github.com/go-chi/chi"
+)
+
+type payload struct {
+	Hook   *core.Hook `json:"hook"`
+	Branch string     `json:"branch"`
+	Config string     `json:"config"`
+}
+
+// HandleEvaluate returns an http.HandlerFunc that processes
+// http requests to evaluate the pipeline configuration for
+// a synthetic hook, without creating a build.
+func HandleEvaluate(
+	repos core.RepositoryStore,
+	triggerer core.Triggerer,
+) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var (
+			ctx       = r.Context()
+			namespace = chi.URLParam(r, "owner")
+			name      = chi.URLParam(r, "name")
+			user, _   = request.UserFrom(ctx)
+		)
+
+		repo, err := repos.FindName(ctx, namespace, name)
+		if err != nil {
+			render.NotFound(w, err)
+			return
+		}
+
+		in := new(payload)
+		err = json.NewDecoder(r.Body).Decode(in)
+		if err != nil {
+			render.BadRequest(w, err)
+			return
+		}
+
+		hook := in.Hook
+		if hook == nil {
+			hook = new(core.Hook)
+		}
+		if hook.Event == "" {
+			hook.Event = core.EventPush
+		}
+		// if the user does not provide a branch, assume the
+		// default repository branch.
+		if hook.Target == "" {
+			hook.Target = in.Branch
+		}
+		if hook.Target == "" {
+			hook.Target = repo.Branch
+		}
+		if hook.Source == "" {
+			hook.Source = hook.Target
+		}
+		if hook.Ref == "" {
+			hook.Ref = scm.ExpandRef(hook.Target, "refs/heads")
+		}
+		if hook.Sender == "" && user != nil {
+			hook.Sender = user.Login
+		}
+		// the hook is evaluated as if it was received from the
+		// source control management system, so that signature
+		// verification is applied to protected repositories.
+		hook.Trigger = core.TriggerHook
+
+		var config *core.Config
+		if in.Config != "" {
+			config = &core.Config{Data: in.Config}
+		}
+
+		result, err := triggerer.Evaluate(ctx, repo, hook, config)
+		if err != nil {
+			render.InternalError(w, err)
+			return
+		}
+		render.JSON(w, result, 200)
+	}
+}
@@ -0,0 +1,147 @@
+// Copyright 2019 Drone.IO Inc. All rights reserved.
+// Use of this source code is governed by the Drone Non-Commercial License
+// that can be found in the LICENSE file.
+
+package evaluate
+
+import (
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/drone/drone/core"
+	"github.com/drone/drone/handler/api/errors"
+	"github.com/drone/drone/handler/api/request"
+	"github.com/drone/drone/mock"
+
+	"github.com/go-chi/chi"
+	"github.com/golang/mock/gomock"
+	"github.com/google/go-cmp/cmp"
+)
+
+var (
+	mockUser = &core.User{
+		ID:    1,
+		Login: "octocat",
+	}
+
+	mockRepo = &core.Repository{
+		ID:        1,
+		UserID:    1,
+		Namespace: "octocat",
+		Name:      "hello-world",
+		Slug:      "octocat/hello-world",
+		Branch:    "master",
+	}
+
+	mockEvaluation = &core.Evaluation{
+		Config:   "kind: pipeline\nname: default\n",
+		Verified: true,
+		Pipelines: []*core.PipelineEvaluation{
+			{Name: "default", Matched: true},
+			{Name: "deploy", Reason: "does not match event"},
+		},
+	}
+)
+
+func TestEvaluate(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	checkHook := func(_ context.Context, _ *core.Repository, hook *core.Hook, config *core.Config) {
+		if got, want := hook.Event, core.EventTag; got != want {
+			t.Errorf("Want hook Event %s, got %s", want, got)
+		}
+		if got, want := hook.Target, "develop"; got != want {
+			t.Errorf("Want hook Target %s, got %s", want, got)
+		}
+		if got, want := hook.Ref, "refs/heads/develop"; got != want {
+			t.Errorf("Want hook Ref %s, got %s", want, got)
+		}
+		if got, want := hook.Trigger, core.TriggerHook; got != want {
+			t.Errorf("Want hook Trigger %s, got %s", want, got)
+		}
+		if got, want := hook.Sender, mockUser.Login; got != want {
+			t.Errorf("Want hook Sender %s, got %s", want, got)
+		}
+		if config == nil || config.Data != "kind: pipeline" {
+			t.Errorf("Want inline configuration passed to evaluation")
+		}
+	}
+
+	repos := mock.NewMockRepositoryStore(controller)
+	repos.EXPECT().FindName(gomock.Any(), mockRepo.Namespace, mockRepo.Name).Return(mockRepo, nil)
+
+	triggerer := mock.NewMockTriggerer(controller)
+	triggerer.EXPECT().Evaluate(gomock.Any(), mockRepo, gomock.Any(), gomock.Any()).Return(mockEvaluation, nil).Do(checkHook)
+
+	c := new(chi.Context)
+	c.URLParams.Add("owner", "octocat")
+	c.URLParams.Add("name", "hello-world")
+
+	body := strings.NewReader(`{"hook":{"event":"tag"},"branch":"develop","config":"kind: pipeline"}`)
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("POST", "/", body)
+	r = r.WithContext(
+		context.WithValue(request.WithUser(r.Context(), mockUser), chi.RouteCtxKey, c),
+	)
+
+	HandleEvaluate(repos, triggerer)(w, r)
+	if got, want := w.Code, 200; want != got {
+		t.Errorf("Want response code %d, got %d", want, got)
+	}
+
+	got, want := new(core.Evaluation), mockEvaluation
+	json.NewDecoder(w.Body).Decode(got)
+	if diff := cmp.Diff(got, want); len(diff) != 0 {
+		t.Errorf(diff)
+	}
+}
+
+func TestEvaluate_RepoNotFound(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	repos := mock.NewMockRepositoryStore(controller)
+	repos.EXPECT().FindName(gomock.Any(), mockRepo.Namespace, mockRepo.Name).Return(nil, errors.ErrNotFound)
+
+	c := new(chi.Context)
+	c.URLParams.Add("owner", "octocat")
+	c.URLParams.Add("name", "hello-world")
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("POST", "/", strings.NewReader("{}"))
+	r = r.WithContext(
+		context.WithValue(r.Context(), chi.RouteCtxKey, c),
+	)
+
+	HandleEvaluate(repos, nil)(w, r)
+	if got, want := w.Code, 404; want != got {
+		t.Errorf("Want response code %d, got %d", want, got)
+	}
+}
+
+func TestEvaluate_BadRequest(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	repos := mock.NewMockRepositoryStore(controller)
+	repos.EXPECT().FindName(gomock.Any(), mockRepo.Namespace, mockRepo.Name).Return(mockRepo, nil)
+
+	c := new(chi.Context)
+	c.URLParams.Add("owner", "octocat")
+	c.URLParams.Add("name", "hello-world")
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("POST", "/", strings.NewReader("{"))
+	r = r.WithContext(
+		context.WithValue(r.Context(), chi.RouteCtxKey, c),
+	)
+
+	HandleEvaluate(repos, nil)(w, r)
+	if got, want := w.Code, 400; want != got {
+		t.Errorf("Want response code %d, got %d", want, got)
+	}
+}
@@ -0,0 +1,86 @@
+// Copyright 2019 Drone IO, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package journal
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/drone/drone/core"
+	"github.com/drone/drone/handler/api/errors"
+	"github.com/drone/drone/handler/api/render"
+	"github.com/drone/drone/logger"
+
+	"github.com/go-chi/chi"
+)
+
+// HandleFind returns an http.HandlerFunc that writes json-encoded
+// journal entry details to the response body.
+func HandleFind(
+	repos core.RepositoryStore,
+	journal core.JournalStore,
+) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		entry, ok := findEntry(w, r, repos, journal)
+		if !ok {
+			return
+		}
+		render.JSON(w, entry, 200)
+	}
+}
+
+// helper function finds the journal entry and verifies it
+// belongs to the repository. It writes an error to the response
+// and returns false if the entry cannot be found.
+func findEntry(
+	w http.ResponseWriter,
+	r *http.Request,
+	repos core.RepositoryStore,
+	journal core.JournalStore,
+) (*core.JournalEntry, bool) {
+	var (
+		namespace = chi.URLParam(r, "owner")
+		name      = chi.URLParam(r, "name")
+	)
+	id, err := strconv.ParseInt(chi.URLParam(r, "entry"), 10, 64)
+	if err != nil {
+		render.BadRequest(w, err)
+		return nil, false
+	}
+	repo, err := repos.FindName(r.Context(), namespace, name)
+	if err != nil {
+		render.NotFound(w, err)
+		logger.FromRequest(r).
+			WithError(err).
+			WithField("namespace", namespace).
+			WithField("name", name).
+			Debugln("api: cannot find repository")
+		return nil, false
+	}
+	entry, err := journal.Find(r.Context(), id)
+	if err != nil {
+		render.NotFound(w, err)
+		logger.FromRequest(r).
+			WithError(err).
+			WithField("journal.id", id).
+			Debugln("api: cannot find journal entry")
+		return nil, false
+	}
+	if entry.RepoID != repo.ID {
+		render.NotFound(w, errors.ErrNotFound)
+		return nil, false
+	}
+	return entry, true
+}
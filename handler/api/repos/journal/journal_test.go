@@ -0,0 +1,84 @@
+// Copyright 2019 Drone.IO Inc. All rights reserved.
+// Use of this source code is governed by the Drone Non-Commercial License
+// that can be found in the LICENSE file.
+
+package journal
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/drone/drone/core"
+	"github.com/drone/drone/mock"
+
+	"github.com/go-chi/chi"
+	"github.com/golang/mock/gomock"
+)
+
+func TestHandleReplay(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	repo := &core.Repository{ID: 1, Namespace: "octocat", Name: "hello-world"}
+	entry := &core.JournalEntry{ID: 2, RepoID: 1, Hook: &core.Hook{}, Status: core.JournalSuccess}
+
+	repos := mock.NewMockRepositoryStore(controller)
+	repos.EXPECT().FindName(gomock.Any(), repo.Namespace, repo.Name).Return(repo, nil)
+
+	journal := mock.NewMockJournalStore(controller)
+	journal.EXPECT().Find(gomock.Any(), entry.ID).Return(entry, nil)
+	journal.EXPECT().Update(gomock.Any(), entry).Return(nil)
+
+	c := new(chi.Context)
+	c.URLParams.Add("owner", "octocat")
+	c.URLParams.Add("name", "hello-world")
+	c.URLParams.Add("entry", "2")
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("POST", "/", nil)
+	r = r.WithContext(
+		context.WithValue(context.Background(), chi.RouteCtxKey, c),
+	)
+
+	HandleReplay(repos, journal).ServeHTTP(w, r)
+	if got, want := w.Code, http.StatusOK; want != got {
+		t.Errorf("Want response code %d, got %d", want, got)
+	}
+	if got, want := entry.Status, core.JournalPending; got != want {
+		t.Errorf("Want status %s, got %s", want, got)
+	}
+}
+
+// this test verifies that a journal entry belonging to a
+// different repository cannot be replayed.
+func TestHandleReplay_WrongRepo(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	repo := &core.Repository{ID: 1, Namespace: "octocat", Name: "hello-world"}
+	entry := &core.JournalEntry{ID: 2, RepoID: 3, Hook: &core.Hook{}}
+
+	repos := mock.NewMockRepositoryStore(controller)
+	repos.EXPECT().FindName(gomock.Any(), repo.Namespace, repo.Name).Return(repo, nil)
+
+	journal := mock.NewMockJournalStore(controller)
+	journal.EXPECT().Find(gomock.Any(), entry.ID).Return(entry, nil)
+
+	c := new(chi.Context)
+	c.URLParams.Add("owner", "octocat")
+	c.URLParams.Add("name", "hello-world")
+	c.URLParams.Add("entry", "2")
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("POST", "/", nil)
+	r = r.WithContext(
+		context.WithValue(context.Background(), chi.RouteCtxKey, c),
+	)
+
+	HandleReplay(repos, journal).ServeHTTP(w, r)
+	if got, want := w.Code, http.StatusNotFound; want != got {
+		t.Errorf("Want response code %d, got %d", want, got)
+	}
+}
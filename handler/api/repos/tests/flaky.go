@@ -0,0 +1,60 @@
+// Copyright 2019 Drone IO, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tests
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/drone/drone/core"
+	"github.com/drone/drone/handler/api/render"
+
+	"github.com/go-chi/chi"
+)
+
+// HandleFlaky returns an http.HandlerFunc that writes a json-encoded
+// list of flaky tests to the response body. A test is flaky if its
+// status flips between passed and failed across the most recent
+// builds of the branch, which defaults to the repository branch.
+func HandleFlaky(
+	repos core.RepositoryStore,
+	tests core.TestStore,
+) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var (
+			namespace = chi.URLParam(r, "owner")
+			name      = chi.URLParam(r, "name")
+			branch    = r.FormValue("branch")
+		)
+		limit, _ := strconv.Atoi(r.FormValue("builds"))
+		if limit < 1 || limit > 100 {
+			limit = 25
+		}
+		repo, err := repos.FindName(r.Context(), namespace, name)
+		if err != nil {
+			render.NotFound(w, err)
+			return
+		}
+		if branch == "" {
+			branch = repo.Branch
+		}
+		results, err := tests.History(r.Context(), repo.ID, branch, limit)
+		if err != nil {
+			render.InternalError(w, err)
+			return
+		}
+		render.JSON(w, core.FindFlakyTests(results), 200)
+	}
+}
@@ -0,0 +1,115 @@
+// Copyright 2019 Drone.IO Inc. All rights reserved.
+// Use of this source code is governed by the Drone Non-Commercial License
+// that can be found in the LICENSE file.
+
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/drone/drone/core"
+	"github.com/drone/drone/mock"
+
+	"github.com/go-chi/chi"
+	"github.com/golang/mock/gomock"
+	"github.com/google/go-cmp/cmp"
+)
+
+var (
+	mockRepo = &core.Repository{
+		ID:        1,
+		Namespace: "octocat",
+		Name:      "hello-world",
+		Branch:    "master",
+	}
+
+	mockBuild = &core.Build{
+		ID:     2,
+		RepoID: 1,
+		Number: 3,
+	}
+
+	mockResults = []*core.TestResult{
+		{ID: 1, BuildID: 2, Suite: "api", Name: "TestFind", Status: core.TestPassed},
+		{ID: 2, BuildID: 2, Suite: "api", Name: "TestCreate", Status: core.TestFailed},
+	}
+)
+
+func TestHandleList(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	repos := mock.NewMockRepositoryStore(controller)
+	repos.EXPECT().FindName(gomock.Any(), mockRepo.Namespace, mockRepo.Name).Return(mockRepo, nil)
+
+	builds := mock.NewMockBuildStore(controller)
+	builds.EXPECT().FindNumber(gomock.Any(), mockRepo.ID, mockBuild.Number).Return(mockBuild, nil)
+
+	tests := mock.NewMockTestStore(controller)
+	tests.EXPECT().List(gomock.Any(), mockBuild.ID).Return(mockResults, nil)
+
+	c := new(chi.Context)
+	c.URLParams.Add("owner", "octocat")
+	c.URLParams.Add("name", "hello-world")
+	c.URLParams.Add("number", "3")
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("GET", "/?status=failed", nil)
+	r = r.WithContext(
+		context.WithValue(context.Background(), chi.RouteCtxKey, c),
+	)
+
+	HandleList(repos, builds, tests)(w, r)
+	if got, want := w.Code, 200; want != got {
+		t.Errorf("Want response code %d, got %d", want, got)
+	}
+
+	got, want := []*core.TestResult{}, mockResults[1:]
+	json.NewDecoder(w.Body).Decode(&got)
+	if diff := cmp.Diff(got, want); diff != "" {
+		t.Errorf(diff)
+	}
+}
+
+func TestHandleFlaky(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	history := []*core.TestResult{
+		{BuildID: 1, Suite: "api", Name: "TestFind", Status: core.TestFailed},
+		{BuildID: 2, Suite: "api", Name: "TestFind", Status: core.TestPassed},
+		{BuildID: 3, Suite: "api", Name: "TestFind", Status: core.TestFailed},
+	}
+
+	repos := mock.NewMockRepositoryStore(controller)
+	repos.EXPECT().FindName(gomock.Any(), mockRepo.Namespace, mockRepo.Name).Return(mockRepo, nil)
+
+	tests := mock.NewMockTestStore(controller)
+	tests.EXPECT().History(gomock.Any(), mockRepo.ID, mockRepo.Branch, 25).Return(history, nil)
+
+	c := new(chi.Context)
+	c.URLParams.Add("owner", "octocat")
+	c.URLParams.Add("name", "hello-world")
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("GET", "/", nil)
+	r = r.WithContext(
+		context.WithValue(context.Background(), chi.RouteCtxKey, c),
+	)
+
+	HandleFlaky(repos, tests)(w, r)
+	if got, want := w.Code, 200; want != got {
+		t.Errorf("Want response code %d, got %d", want, got)
+	}
+
+	got, want := []*core.FlakyTest{}, []*core.FlakyTest{
+		{Suite: "api", Name: "TestFind", Runs: 3, Failures: 2, Flips: 2, Status: core.TestFailed},
+	}
+	json.NewDecoder(w.Body).Decode(&got)
+	if diff := cmp.Diff(got, want); diff != "" {
+		t.Errorf(diff)
+	}
+}
@@ -37,9 +37,19 @@ type (
 		CancelPulls   *bool   `json:"auto_cancel_pull_requests"`
 		CancelPush    *bool   `json:"auto_cancel_pushes"`
 		CancelRunning *bool   `json:"auto_cancel_running"`
+		RequeueLost   *bool   `json:"requeue_lost_stages"`
 		Timeout       *int64  `json:"timeout"`
 		Throttle      *int64  `json:"throttle"`
 		Counter       *int64  `json:"counter"`
+		ArtifactQuota *int64  `json:"artifact_quota"`
+
+		// CancelPolicy is decoded separately so that a null
+		// value can be used to remove the policy.
+		CancelPolicy json.RawMessage `json:"auto_cancel_policy"`
+
+		// ApprovalRules replaces the approval rules. An empty
+		// list removes the rules.
+		ApprovalRules *[]*core.ApprovalRule `json:"approval_rules"`
 	}
 )
 
@@ -99,6 +109,43 @@ func HandleUpdate(repos core.RepositoryStore) http.HandlerFunc {
 		if in.CancelRunning != nil {
 			repo.CancelRunning = *in.CancelRunning
 		}
+		if in.RequeueLost != nil {
+			repo.RequeueLost = *in.RequeueLost
+		}
+		if len(in.CancelPolicy) != 0 {
+			var policy *core.CancelPolicy
+			err := json.Unmarshal(in.CancelPolicy, &policy)
+			if err == nil && policy != nil {
+				err = policy.Validate()
+			}
+			if err != nil {
+				render.BadRequest(w, err)
+				logger.FromRequest(r).
+					WithError(err).
+					WithField("repository", slug).
+					Debugln("api: invalid auto-cancel policy")
+				return
+			}
+			repo.CancelPolicy = policy
+		}
+		if in.ApprovalRules != nil {
+			var rules []*core.ApprovalRule
+			for _, rule := range *in.ApprovalRules {
+				if rule == nil {
+					continue
+				}
+				if err := rule.Validate(); err != nil {
+					render.BadRequest(w, err)
+					logger.FromRequest(r).
+						WithError(err).
+						WithField("repository", slug).
+						Debugln("api: invalid approval rule")
+					return
+				}
+				rules = append(rules, rule)
+			}
+			repo.ApprovalRules = rules
+		}
 
 		//
 		// system administrator only
@@ -116,6 +163,9 @@ func HandleUpdate(repos core.RepositoryStore) http.HandlerFunc {
 			if in.Counter != nil {
 				repo.Counter = *in.Counter
 			}
+			if in.ArtifactQuota != nil {
+				repo.ArtifactQuota = *in.ArtifactQuota
+			}
 		}
 
 		// // right now the only repository field that a user
@@ -293,3 +293,177 @@ func TestUpdateAutoCancelRunning(t *testing.T) {
 		t.Errorf(diff)
 	}
 }
+
+func TestUpdateAutoCancelPolicy(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	repo := &core.Repository{
+		ID:        1,
+		UserID:    1,
+		Namespace: "octocat",
+		Name:      "hello-world",
+		Slug:      "octocat/hello-world",
+	}
+
+	checkUpdate := func(_ context.Context, updated *core.Repository) error {
+		want := &core.CancelPolicy{
+			Branches: []string{"feature/*"},
+			Exclude:  []string{"refs/heads/main", "refs/tags/*"},
+		}
+		if diff := cmp.Diff(updated.CancelPolicy, want); diff != "" {
+			t.Errorf(diff)
+		}
+		return nil
+	}
+
+	repos := mock.NewMockRepositoryStore(controller)
+	repos.EXPECT().FindName(gomock.Any(), "octocat", "hello-world").Return(repo, nil)
+	repos.EXPECT().Update(gomock.Any(), repo).Return(nil).Do(checkUpdate)
+
+	c := new(chi.Context)
+	c.URLParams.Add("owner", "octocat")
+	c.URLParams.Add("name", "hello-world")
+
+	in := strings.NewReader(`{"auto_cancel_policy":{"branches":["feature/*"],"exclude":["refs/heads/main","refs/tags/*"]}}`)
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("POST", "/", in)
+	r = r.WithContext(
+		context.WithValue(r.Context(), chi.RouteCtxKey, c),
+	)
+
+	HandleUpdate(repos)(w, r)
+	if got, want := w.Code, 200; want != got {
+		t.Errorf("Want response code %d, got %d", want, got)
+	}
+}
+
+func TestUpdateAutoCancelPolicy_Remove(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	repo := &core.Repository{
+		ID:           1,
+		Namespace:    "octocat",
+		Name:         "hello-world",
+		CancelPolicy: &core.CancelPolicy{},
+	}
+
+	repos := mock.NewMockRepositoryStore(controller)
+	repos.EXPECT().FindName(gomock.Any(), "octocat", "hello-world").Return(repo, nil)
+	repos.EXPECT().Update(gomock.Any(), repo).Return(nil)
+
+	c := new(chi.Context)
+	c.URLParams.Add("owner", "octocat")
+	c.URLParams.Add("name", "hello-world")
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("POST", "/", strings.NewReader(`{"auto_cancel_policy":null}`))
+	r = r.WithContext(
+		context.WithValue(r.Context(), chi.RouteCtxKey, c),
+	)
+
+	HandleUpdate(repos)(w, r)
+	if got, want := w.Code, 200; want != got {
+		t.Errorf("Want response code %d, got %d", want, got)
+	}
+	if repo.CancelPolicy != nil {
+		t.Errorf("Want auto-cancel policy removed")
+	}
+}
+
+func TestUpdateAutoCancelPolicy_Invalid(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	repo := &core.Repository{
+		ID:        1,
+		Namespace: "octocat",
+		Name:      "hello-world",
+	}
+
+	repos := mock.NewMockRepositoryStore(controller)
+	repos.EXPECT().FindName(gomock.Any(), "octocat", "hello-world").Return(repo, nil)
+
+	c := new(chi.Context)
+	c.URLParams.Add("owner", "octocat")
+	c.URLParams.Add("name", "hello-world")
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("POST", "/", strings.NewReader(`{"auto_cancel_policy":{"branches":["["]}}`))
+	r = r.WithContext(
+		context.WithValue(r.Context(), chi.RouteCtxKey, c),
+	)
+
+	HandleUpdate(repos)(w, r)
+	if got, want := w.Code, 400; want != got {
+		t.Errorf("Want response code %d, got %d", want, got)
+	}
+}
+
+func TestUpdateApprovalRules(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	repo := &core.Repository{
+		ID:        1,
+		Namespace: "octocat",
+		Name:      "hello-world",
+	}
+
+	repos := mock.NewMockRepositoryStore(controller)
+	repos.EXPECT().FindName(gomock.Any(), "octocat", "hello-world").Return(repo, nil)
+	repos.EXPECT().Update(gomock.Any(), repo).Return(nil)
+
+	c := new(chi.Context)
+	c.URLParams.Add("owner", "octocat")
+	c.URLParams.Add("name", "hello-world")
+
+	in := strings.NewReader(`{"approval_rules":[{"target":"production","required":2,"orgs":["ops"],"expiry":60}]}`)
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("POST", "/", in)
+	r = r.WithContext(
+		context.WithValue(r.Context(), chi.RouteCtxKey, c),
+	)
+
+	HandleUpdate(repos)(w, r)
+	if got, want := w.Code, 200; want != got {
+		t.Errorf("Want response code %d, got %d", want, got)
+	}
+
+	want := []*core.ApprovalRule{
+		{Target: "production", Required: 2, Orgs: []string{"ops"}, Expiry: 60},
+	}
+	if diff := cmp.Diff(repo.ApprovalRules, want); diff != "" {
+		t.Errorf(diff)
+	}
+}
+
+func TestUpdateApprovalRules_Invalid(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	repo := &core.Repository{
+		ID:        1,
+		Namespace: "octocat",
+		Name:      "hello-world",
+	}
+
+	repos := mock.NewMockRepositoryStore(controller)
+	repos.EXPECT().FindName(gomock.Any(), "octocat", "hello-world").Return(repo, nil)
+
+	c := new(chi.Context)
+	c.URLParams.Add("owner", "octocat")
+	c.URLParams.Add("name", "hello-world")
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("POST", "/", strings.NewReader(`{"approval_rules":[{"required":-1}]}`))
+	r = r.WithContext(
+		context.WithValue(r.Context(), chi.RouteCtxKey, c),
+	)
+
+	HandleUpdate(repos)(w, r)
+	if got, want := w.Code, 400; want != got {
+		t.Errorf("Want response code %d, got %d", want, got)
+	}
+}
@@ -0,0 +1,77 @@
+// Copyright 2019 Drone.IO Inc. All rights reserved.
+// Use of this source code is governed by the Drone Non-Commercial License
+// that can be found in the LICENSE file.
+
+// +build !oss
+
+package webhooks
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/drone/drone/core"
+	"github.com/drone/drone/handler/api/render"
+
+	"github.com/dchest/uniuri"
+	"github.com/go-chi/chi"
+)
+
+type webhookInput struct {
+	Endpoint string   `json:"endpoint"`
+	Secret   string   `json:"secret"`
+	Events   []string `json:"events"`
+}
+
+// HandleCreate returns an http.HandlerFunc that processes http
+// requests to create a new repository webhook. If the signing
+// secret is not provided a random secret is generated. The
+// secret is only included in this response.
+func HandleCreate(
+	repos core.RepositoryStore,
+	subscriptions core.SubscriptionStore,
+) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var (
+			namespace = chi.URLParam(r, "owner")
+			name      = chi.URLParam(r, "name")
+		)
+		repo, err := repos.FindName(r.Context(), namespace, name)
+		if err != nil {
+			render.NotFound(w, err)
+			return
+		}
+		in := new(webhookInput)
+		err = json.NewDecoder(r.Body).Decode(in)
+		if err != nil {
+			render.BadRequest(w, err)
+			return
+		}
+
+		s := &core.Subscription{
+			RepoID:   repo.ID,
+			Endpoint: in.Endpoint,
+			Secret:   in.Secret,
+			Events:   in.Events,
+			Created:  time.Now().Unix(),
+			Updated:  time.Now().Unix(),
+		}
+		if s.Secret == "" {
+			s.Secret = uniuri.NewLen(32)
+		}
+
+		err = s.Validate()
+		if err != nil {
+			render.BadRequest(w, err)
+			return
+		}
+
+		err = subscriptions.Create(r.Context(), s)
+		if err != nil {
+			render.InternalError(w, err)
+			return
+		}
+		render.JSON(w, s, 200)
+	}
+}
@@ -0,0 +1,47 @@
+// Copyright 2019 Drone.IO Inc. All rights reserved.
+// Use of this source code is governed by the Drone Non-Commercial License
+// that can be found in the LICENSE file.
+
+// +build !oss
+
+package webhooks
+
+import (
+	"net/http"
+
+	"github.com/drone/drone/core"
+	"github.com/drone/drone/handler/api/render"
+
+	"github.com/go-chi/chi"
+)
+
+// HandleList returns an http.HandlerFunc that writes a json-encoded
+// list of repository webhooks to the response body.
+func HandleList(
+	repos core.RepositoryStore,
+	subscriptions core.SubscriptionStore,
+) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var (
+			namespace = chi.URLParam(r, "owner")
+			name      = chi.URLParam(r, "name")
+		)
+		repo, err := repos.FindName(r.Context(), namespace, name)
+		if err != nil {
+			render.NotFound(w, err)
+			return
+		}
+		list, err := subscriptions.List(r.Context(), repo.ID)
+		if err != nil {
+			render.InternalError(w, err)
+			return
+		}
+		// the webhook list is copied and the signing secret
+		// is removed from the response.
+		webhooks := []*core.Subscription{}
+		for _, subscription := range list {
+			webhooks = append(webhooks, subscription.Copy())
+		}
+		render.JSON(w, webhooks, 200)
+	}
+}
@@ -0,0 +1,92 @@
+// Copyright 2019 Drone.IO Inc. All rights reserved.
+// Use of this source code is governed by the Drone Non-Commercial License
+// that can be found in the LICENSE file.
+
+// +build !oss
+
+package webhooks
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/drone/drone/core"
+	"github.com/drone/drone/handler/api/errors"
+	"github.com/drone/drone/handler/api/render"
+
+	"github.com/go-chi/chi"
+)
+
+type webhookUpdate struct {
+	Endpoint *string  `json:"endpoint"`
+	Secret   *string  `json:"secret"`
+	Events   []string `json:"events"`
+}
+
+// HandleUpdate returns an http.HandlerFunc that processes http
+// requests to update a repository webhook.
+func HandleUpdate(
+	repos core.RepositoryStore,
+	subscriptions core.SubscriptionStore,
+) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var (
+			namespace = chi.URLParam(r, "owner")
+			name      = chi.URLParam(r, "name")
+		)
+		id, err := strconv.ParseInt(chi.URLParam(r, "webhook"), 10, 64)
+		if err != nil {
+			render.BadRequest(w, err)
+			return
+		}
+
+		in := new(webhookUpdate)
+		err = json.NewDecoder(r.Body).Decode(in)
+		if err != nil {
+			render.BadRequest(w, err)
+			return
+		}
+
+		repo, err := repos.FindName(r.Context(), namespace, name)
+		if err != nil {
+			render.NotFound(w, err)
+			return
+		}
+
+		s, err := subscriptions.Find(r.Context(), id)
+		if err != nil {
+			render.NotFound(w, err)
+			return
+		}
+		if s.RepoID != repo.ID {
+			render.NotFound(w, errors.ErrNotFound)
+			return
+		}
+
+		if in.Endpoint != nil {
+			s.Endpoint = *in.Endpoint
+		}
+		if in.Secret != nil && *in.Secret != "" {
+			s.Secret = *in.Secret
+		}
+		if in.Events != nil {
+			s.Events = in.Events
+		}
+		s.Updated = time.Now().Unix()
+
+		err = s.Validate()
+		if err != nil {
+			render.BadRequest(w, err)
+			return
+		}
+
+		err = subscriptions.Update(r.Context(), s)
+		if err != nil {
+			render.InternalError(w, err)
+			return
+		}
+		render.JSON(w, s.Copy(), 200)
+	}
+}
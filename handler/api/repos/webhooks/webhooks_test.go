@@ -0,0 +1,288 @@
+// Copyright 2019 Drone.IO Inc. All rights reserved.
+// Use of this source code is governed by the Drone Non-Commercial License
+// that can be found in the LICENSE file.
+
+// +build !oss
+
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/drone/drone/core"
+	"github.com/drone/drone/handler/api/errors"
+	"github.com/drone/drone/mock"
+
+	"github.com/go-chi/chi"
+	"github.com/golang/mock/gomock"
+	"github.com/google/go-cmp/cmp"
+)
+
+var (
+	dummyWebhookRepo = &core.Repository{
+		ID:        1,
+		Namespace: "octocat",
+		Name:      "hello-world",
+	}
+
+	dummyWebhook = &core.Subscription{
+		ID:       2,
+		RepoID:   1,
+		Endpoint: "https://octocat.com/hooks",
+		Secret:   "correct-horse-battery-staple",
+		Events:   []string{"build:*"},
+	}
+
+	dummyWebhookScrubbed = &core.Subscription{
+		ID:       2,
+		RepoID:   1,
+		Endpoint: "https://octocat.com/hooks",
+		Events:   []string{"build:*"},
+	}
+)
+
+func TestHandleList(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	repos := mock.NewMockRepositoryStore(controller)
+	repos.EXPECT().FindName(gomock.Any(), dummyWebhookRepo.Namespace, dummyWebhookRepo.Name).Return(dummyWebhookRepo, nil)
+
+	subscriptions := mock.NewMockSubscriptionStore(controller)
+	subscriptions.EXPECT().List(gomock.Any(), dummyWebhookRepo.ID).Return([]*core.Subscription{dummyWebhook}, nil)
+
+	c := new(chi.Context)
+	c.URLParams.Add("owner", "octocat")
+	c.URLParams.Add("name", "hello-world")
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("GET", "/", nil)
+	r = r.WithContext(
+		context.WithValue(context.Background(), chi.RouteCtxKey, c),
+	)
+
+	HandleList(repos, subscriptions).ServeHTTP(w, r)
+	if got, want := w.Code, http.StatusOK; want != got {
+		t.Errorf("Want response code %d, got %d", want, got)
+	}
+
+	got, want := []*core.Subscription{}, []*core.Subscription{dummyWebhookScrubbed}
+	json.NewDecoder(w.Body).Decode(&got)
+	if diff := cmp.Diff(got, want); len(diff) != 0 {
+		t.Errorf(diff)
+	}
+}
+
+func TestHandleFind(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	repos := mock.NewMockRepositoryStore(controller)
+	repos.EXPECT().FindName(gomock.Any(), dummyWebhookRepo.Namespace, dummyWebhookRepo.Name).Return(dummyWebhookRepo, nil)
+
+	subscriptions := mock.NewMockSubscriptionStore(controller)
+	subscriptions.EXPECT().Find(gomock.Any(), dummyWebhook.ID).Return(dummyWebhook, nil)
+
+	c := new(chi.Context)
+	c.URLParams.Add("owner", "octocat")
+	c.URLParams.Add("name", "hello-world")
+	c.URLParams.Add("webhook", "2")
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("GET", "/", nil)
+	r = r.WithContext(
+		context.WithValue(context.Background(), chi.RouteCtxKey, c),
+	)
+
+	HandleFind(repos, subscriptions).ServeHTTP(w, r)
+	if got, want := w.Code, http.StatusOK; want != got {
+		t.Errorf("Want response code %d, got %d", want, got)
+	}
+
+	got, want := &core.Subscription{}, dummyWebhookScrubbed
+	json.NewDecoder(w.Body).Decode(got)
+	if diff := cmp.Diff(got, want); len(diff) != 0 {
+		t.Errorf(diff)
+	}
+}
+
+// this test verifies that a webhook that belongs to another
+// repository cannot be accessed.
+func TestHandleFind_RepoMismatch(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	other := &core.Subscription{ID: 2, RepoID: 99}
+
+	repos := mock.NewMockRepositoryStore(controller)
+	repos.EXPECT().FindName(gomock.Any(), dummyWebhookRepo.Namespace, dummyWebhookRepo.Name).Return(dummyWebhookRepo, nil)
+
+	subscriptions := mock.NewMockSubscriptionStore(controller)
+	subscriptions.EXPECT().Find(gomock.Any(), other.ID).Return(other, nil)
+
+	c := new(chi.Context)
+	c.URLParams.Add("owner", "octocat")
+	c.URLParams.Add("name", "hello-world")
+	c.URLParams.Add("webhook", "2")
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("GET", "/", nil)
+	r = r.WithContext(
+		context.WithValue(context.Background(), chi.RouteCtxKey, c),
+	)
+
+	HandleFind(repos, subscriptions).ServeHTTP(w, r)
+	if got, want := w.Code, http.StatusNotFound; want != got {
+		t.Errorf("Want response code %d, got %d", want, got)
+	}
+
+	got, want := new(errors.Error), errors.ErrNotFound
+	json.NewDecoder(w.Body).Decode(got)
+	if diff := cmp.Diff(got, want); len(diff) != 0 {
+		t.Errorf(diff)
+	}
+}
+
+func TestHandleCreate(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	repos := mock.NewMockRepositoryStore(controller)
+	repos.EXPECT().FindName(gomock.Any(), dummyWebhookRepo.Namespace, dummyWebhookRepo.Name).Return(dummyWebhookRepo, nil)
+
+	subscriptions := mock.NewMockSubscriptionStore(controller)
+	subscriptions.EXPECT().Create(gomock.Any(), gomock.Any()).Return(nil)
+
+	c := new(chi.Context)
+	c.URLParams.Add("owner", "octocat")
+	c.URLParams.Add("name", "hello-world")
+
+	in := new(bytes.Buffer)
+	json.NewEncoder(in).Encode(&webhookInput{
+		Endpoint: "https://octocat.com/hooks",
+		Events:   []string{"build:*"},
+	})
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("POST", "/", in)
+	r = r.WithContext(
+		context.WithValue(context.Background(), chi.RouteCtxKey, c),
+	)
+
+	HandleCreate(repos, subscriptions).ServeHTTP(w, r)
+	if got, want := w.Code, http.StatusOK; want != got {
+		t.Errorf("Want response code %d, got %d", want, got)
+	}
+
+	got := &core.Subscription{}
+	json.NewDecoder(w.Body).Decode(got)
+	if got.Secret == "" {
+		t.Errorf("Want generated signing secret")
+	}
+	if got, want := got.RepoID, dummyWebhookRepo.ID; got != want {
+		t.Errorf("Want repository id %d, got %d", want, got)
+	}
+}
+
+func TestHandleCreate_ValidationError(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	repos := mock.NewMockRepositoryStore(controller)
+	repos.EXPECT().FindName(gomock.Any(), dummyWebhookRepo.Namespace, dummyWebhookRepo.Name).Return(dummyWebhookRepo, nil)
+
+	c := new(chi.Context)
+	c.URLParams.Add("owner", "octocat")
+	c.URLParams.Add("name", "hello-world")
+
+	in := new(bytes.Buffer)
+	json.NewEncoder(in).Encode(&webhookInput{Endpoint: "ftp://octocat.com"})
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("POST", "/", in)
+	r = r.WithContext(
+		context.WithValue(context.Background(), chi.RouteCtxKey, c),
+	)
+
+	HandleCreate(repos, nil).ServeHTTP(w, r)
+	if got, want := w.Code, http.StatusBadRequest; want != got {
+		t.Errorf("Want response code %d, got %d", want, got)
+	}
+}
+
+func TestHandleUpdate(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	webhook := *dummyWebhook
+
+	repos := mock.NewMockRepositoryStore(controller)
+	repos.EXPECT().FindName(gomock.Any(), dummyWebhookRepo.Namespace, dummyWebhookRepo.Name).Return(dummyWebhookRepo, nil)
+
+	subscriptions := mock.NewMockSubscriptionStore(controller)
+	subscriptions.EXPECT().Find(gomock.Any(), dummyWebhook.ID).Return(&webhook, nil)
+	subscriptions.EXPECT().Update(gomock.Any(), &webhook).Return(nil)
+
+	c := new(chi.Context)
+	c.URLParams.Add("owner", "octocat")
+	c.URLParams.Add("name", "hello-world")
+	c.URLParams.Add("webhook", "2")
+
+	in := new(bytes.Buffer)
+	json.NewEncoder(in).Encode(map[string]interface{}{
+		"events": []string{"build:*", "repo:*"},
+	})
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("PATCH", "/", in)
+	r = r.WithContext(
+		context.WithValue(context.Background(), chi.RouteCtxKey, c),
+	)
+
+	HandleUpdate(repos, subscriptions).ServeHTTP(w, r)
+	if got, want := w.Code, http.StatusOK; want != got {
+		t.Errorf("Want response code %d, got %d", want, got)
+	}
+
+	got := &core.Subscription{}
+	json.NewDecoder(w.Body).Decode(got)
+	if got.Secret != "" {
+		t.Errorf("Want signing secret removed from response")
+	}
+	if got, want := len(got.Events), 2; got != want {
+		t.Errorf("Want event count %d, got %d", want, got)
+	}
+}
+
+func TestHandleDelete(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	repos := mock.NewMockRepositoryStore(controller)
+	repos.EXPECT().FindName(gomock.Any(), dummyWebhookRepo.Namespace, dummyWebhookRepo.Name).Return(dummyWebhookRepo, nil)
+
+	subscriptions := mock.NewMockSubscriptionStore(controller)
+	subscriptions.EXPECT().Find(gomock.Any(), dummyWebhook.ID).Return(dummyWebhook, nil)
+	subscriptions.EXPECT().Delete(gomock.Any(), dummyWebhook).Return(nil)
+
+	c := new(chi.Context)
+	c.URLParams.Add("owner", "octocat")
+	c.URLParams.Add("name", "hello-world")
+	c.URLParams.Add("webhook", "2")
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("DELETE", "/", nil)
+	r = r.WithContext(
+		context.WithValue(context.Background(), chi.RouteCtxKey, c),
+	)
+
+	HandleDelete(repos, subscriptions).ServeHTTP(w, r)
+	if got, want := w.Code, http.StatusNoContent; want != got {
+		t.Errorf("Want response code %d, got %d", want, got)
+	}
+}
@@ -0,0 +1,83 @@
+// Copyright 2019 Drone.IO Inc. All rights reserved.
+// Use of this source code is governed by the Drone Non-Commercial License
+// that can be found in the LICENSE file.
+
+// +build !oss
+
+package runners
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/drone/drone/core"
+	"github.com/drone/drone/handler/api/render"
+	"github.com/drone/drone/logger"
+
+	"github.com/go-chi/chi"
+)
+
+// HandleCordon returns an http.HandlerFunc that processes an
+// http.Request to cordon the runner. A cordoned runner is not
+// assigned new stages, but stages already running continue.
+func HandleCordon(nodes core.NodeStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		update(w, r, nodes, func(node *core.Node) {
+			node.Cordoned = true
+		})
+	}
+}
+
+// HandleUncordon returns an http.HandlerFunc that processes an
+// http.Request to uncordon the runner, returning a cordoned or
+// drained runner to service.
+func HandleUncordon(nodes core.NodeStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		update(w, r, nodes, func(node *core.Node) {
+			node.Cordoned = false
+			node.State = core.NodeStateActive
+		})
+	}
+}
+
+// HandleDrain returns an http.HandlerFunc that processes an
+// http.Request to drain the runner. The runner is cordoned and
+// transitions to drained once its running stages complete.
+func HandleDrain(nodes core.NodeStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		update(w, r, nodes, func(node *core.Node) {
+			node.Cordoned = true
+			if node.State != core.NodeStateDrained {
+				node.State = core.NodeStateDraining
+			}
+		})
+	}
+}
+
+// update is a helper function that finds the named runner,
+// applies the function and persists the runner.
+func update(w http.ResponseWriter, r *http.Request, nodes core.NodeStore, fn func(*core.Node)) {
+	ctx := r.Context()
+	name := chi.URLParam(r, "runner")
+	node, err := nodes.FindName(ctx, name)
+	if err != nil {
+		render.NotFound(w, err)
+		logger.FromRequest(r).
+			WithError(err).
+			WithField("runner", name).
+			Debugln("api: cannot find runner")
+		return
+	}
+	fn(node)
+	node.Updated = time.Now().Unix()
+	err = nodes.Update(ctx, node)
+	if err != nil {
+		render.InternalError(w, err)
+		logger.FromRequest(r).
+			WithError(err).
+			WithField("runner", name).
+			Warnln("api: cannot update runner")
+		return
+	}
+	render.JSON(w, node, 200)
+}
@@ -0,0 +1,133 @@
+// Copyright 2019 Drone.IO Inc. All rights reserved.
+// Use of this source code is governed by the Drone Non-Commercial License
+// that can be found in the LICENSE file.
+
+// +build !oss
+
+package runners
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/drone/drone/core"
+	"github.com/drone/drone/mock"
+
+	"github.com/go-chi/chi"
+	"github.com/golang/mock/gomock"
+)
+
+func TestHandleCordon(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	node := &core.Node{Name: "runner-1", State: core.NodeStateActive}
+	nodes := mock.NewMockNodeStore(controller)
+	nodes.EXPECT().FindName(gomock.Any(), node.Name).Return(node, nil)
+	nodes.EXPECT().Update(gomock.Any(), node).Return(nil)
+
+	c := new(chi.Context)
+	c.URLParams.Add("runner", "runner-1")
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("POST", "/", nil)
+	r = r.WithContext(
+		context.WithValue(context.Background(), chi.RouteCtxKey, c),
+	)
+
+	HandleCordon(nodes).ServeHTTP(w, r)
+	if got, want := w.Code, http.StatusOK; want != got {
+		t.Errorf("Want response code %d, got %d", want, got)
+	}
+	if !node.Cordoned {
+		t.Errorf("Want runner cordoned")
+	}
+	if got, want := node.State, core.NodeStateActive; got != want {
+		t.Errorf("Want state %s, got %s", want, got)
+	}
+}
+
+func TestHandleDrain(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	node := &core.Node{Name: "runner-1", State: core.NodeStateActive}
+	nodes := mock.NewMockNodeStore(controller)
+	nodes.EXPECT().FindName(gomock.Any(), node.Name).Return(node, nil)
+	nodes.EXPECT().Update(gomock.Any(), node).Return(nil)
+
+	c := new(chi.Context)
+	c.URLParams.Add("runner", "runner-1")
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("POST", "/", nil)
+	r = r.WithContext(
+		context.WithValue(context.Background(), chi.RouteCtxKey, c),
+	)
+
+	HandleDrain(nodes).ServeHTTP(w, r)
+	if got, want := w.Code, http.StatusOK; want != got {
+		t.Errorf("Want response code %d, got %d", want, got)
+	}
+	if !node.Cordoned {
+		t.Errorf("Want runner cordoned")
+	}
+	if got, want := node.State, core.NodeStateDraining; got != want {
+		t.Errorf("Want state %s, got %s", want, got)
+	}
+}
+
+func TestHandleUncordon(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	node := &core.Node{Name: "runner-1", State: core.NodeStateDrained, Cordoned: true}
+	nodes := mock.NewMockNodeStore(controller)
+	nodes.EXPECT().FindName(gomock.Any(), node.Name).Return(node, nil)
+	nodes.EXPECT().Update(gomock.Any(), node).Return(nil)
+
+	c := new(chi.Context)
+	c.URLParams.Add("runner", "runner-1")
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("DELETE", "/", nil)
+	r = r.WithContext(
+		context.WithValue(context.Background(), chi.RouteCtxKey, c),
+	)
+
+	HandleUncordon(nodes).ServeHTTP(w, r)
+	if got, want := w.Code, http.StatusOK; want != got {
+		t.Errorf("Want response code %d, got %d", want, got)
+	}
+	if node.Cordoned {
+		t.Errorf("Want runner uncordoned")
+	}
+	if got, want := node.State, core.NodeStateActive; got != want {
+		t.Errorf("Want state %s, got %s", want, got)
+	}
+}
+
+func TestHandleCordon_NotFound(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	nodes := mock.NewMockNodeStore(controller)
+	nodes.EXPECT().FindName(gomock.Any(), "runner-1").Return(nil, sql.ErrNoRows)
+
+	c := new(chi.Context)
+	c.URLParams.Add("runner", "runner-1")
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("POST", "/", nil)
+	r = r.WithContext(
+		context.WithValue(context.Background(), chi.RouteCtxKey, c),
+	)
+
+	HandleCordon(nodes).ServeHTTP(w, r)
+	if got, want := w.Code, http.StatusNotFound; want != got {
+		t.Errorf("Want response code %d, got %d", want, got)
+	}
+}
@@ -0,0 +1,46 @@
+// Copyright 2019 Drone.IO Inc. All rights reserved.
+// Use of this source code is governed by the Drone Non-Commercial License
+// that can be found in the LICENSE file.
+
+// +build !oss
+
+package runners
+
+import (
+	"net/http"
+
+	"github.com/drone/drone/core"
+	"github.com/drone/drone/handler/api/render"
+	"github.com/drone/drone/logger"
+
+	"github.com/go-chi/chi"
+)
+
+// HandleDelete returns an http.HandlerFunc that processes an
+// http.Request to remove the runner from the registry. A runner
+// that is still online registers itself again on its next request.
+func HandleDelete(nodes core.NodeStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		name := chi.URLParam(r, "runner")
+		node, err := nodes.FindName(ctx, name)
+		if err != nil {
+			render.NotFound(w, err)
+			logger.FromRequest(r).
+				WithError(err).
+				WithField("runner", name).
+				Debugln("api: cannot find runner")
+			return
+		}
+		err = nodes.Delete(ctx, node)
+		if err != nil {
+			render.InternalError(w, err)
+			logger.FromRequest(r).
+				WithError(err).
+				WithField("runner", name).
+				Warnln("api: cannot delete runner")
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
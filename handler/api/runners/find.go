@@ -0,0 +1,45 @@
+// Copyright 2019 Drone.IO Inc. All rights reserved.
+// Use of this source code is governed by the Drone Non-Commercial License
+// that can be found in the LICENSE file.
+
+// +build !oss
+
+package runners
+
+import (
+	"net/http"
+
+	"github.com/drone/drone/core"
+	"github.com/drone/drone/handler/api/render"
+	"github.com/drone/drone/logger"
+
+	"github.com/go-chi/chi"
+)
+
+// HandleFind returns an http.HandlerFunc that writes json-encoded
+// runner details, including the stages the runner is currently
+// executing, to the response body.
+func HandleFind(nodes core.NodeStore, stages core.StageStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		name := chi.URLParam(r, "runner")
+		node, err := nodes.FindName(ctx, name)
+		if err != nil {
+			render.NotFound(w, err)
+			logger.FromRequest(r).
+				WithError(err).
+				WithField("runner", name).
+				Debugln("api: cannot find runner")
+			return
+		}
+		running, err := stages.ListIncomplete(ctx)
+		if err != nil {
+			render.InternalError(w, err)
+			logger.FromRequest(r).WithError(err).
+				Errorln("api: cannot list incomplete stages")
+			return
+		}
+		node.Stages = assigned(node, running)
+		render.JSON(w, node, 200)
+	}
+}
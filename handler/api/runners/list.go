@@ -0,0 +1,53 @@
+// Copyright 2019 Drone.IO Inc. All rights reserved.
+// Use of this source code is governed by the Drone Non-Commercial License
+// that can be found in the LICENSE file.
+
+// +build !oss
+
+package runners
+
+import (
+	"net/http"
+
+	"github.com/drone/drone/core"
+	"github.com/drone/drone/handler/api/render"
+	"github.com/drone/drone/logger"
+)
+
+// HandleList returns an http.HandlerFunc that writes a json-encoded
+// list of registered runners, including the stages each runner is
+// currently executing, to the response body.
+func HandleList(nodes core.NodeStore, stages core.StageStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		list, err := nodes.List(ctx)
+		if err != nil {
+			render.InternalError(w, err)
+			logger.FromRequest(r).WithError(err).
+				Errorln("api: cannot list runners")
+			return
+		}
+		running, err := stages.ListIncomplete(ctx)
+		if err != nil {
+			render.InternalError(w, err)
+			logger.FromRequest(r).WithError(err).
+				Errorln("api: cannot list incomplete stages")
+			return
+		}
+		for _, node := range list {
+			node.Stages = assigned(node, running)
+		}
+		render.JSON(w, list, 200)
+	}
+}
+
+// assigned returns the incomplete stages assigned to the node.
+func assigned(node *core.Node, stages []*core.Stage) []*core.Stage {
+	var out []*core.Stage
+	for _, stage := range stages {
+		if stage.Machine == node.Name && stage.Status != core.StatusPending {
+			out = append(out, stage)
+		}
+	}
+	return out
+}
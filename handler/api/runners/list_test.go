@@ -0,0 +1,59 @@
+// Copyright 2019 Drone.IO Inc. All rights reserved.
+// Use of this source code is governed by the Drone Non-Commercial License
+// that can be found in the LICENSE file.
+
+// +build !oss
+
+package runners
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/drone/drone/core"
+	"github.com/drone/drone/mock"
+
+	"github.com/golang/mock/gomock"
+)
+
+func TestHandleList(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	list := []*core.Node{
+		{Name: "runner-1"},
+		{Name: "runner-2"},
+	}
+	running := []*core.Stage{
+		{ID: 1, Machine: "runner-1", Status: core.StatusRunning},
+		{ID: 2, Machine: "", Status: core.StatusPending},
+	}
+
+	nodes := mock.NewMockNodeStore(controller)
+	nodes.EXPECT().List(gomock.Any()).Return(list, nil)
+
+	stages := mock.NewMockStageStore(controller)
+	stages.EXPECT().ListIncomplete(gomock.Any()).Return(running, nil)
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("GET", "/", nil)
+
+	HandleList(nodes, stages).ServeHTTP(w, r)
+	if got, want := w.Code, http.StatusOK; want != got {
+		t.Errorf("Want response code %d, got %d", want, got)
+	}
+
+	got := []*core.Node{}
+	json.NewDecoder(w.Body).Decode(&got)
+	if len(got) != 2 {
+		t.Fatalf("Want 2 runners, got %d", len(got))
+	}
+	if len(got[0].Stages) != 1 || got[0].Stages[0].ID != 1 {
+		t.Errorf("Want runner-1 assigned stage 1")
+	}
+	if len(got[1].Stages) != 0 {
+		t.Errorf("Want runner-2 assigned no stages")
+	}
+}
@@ -0,0 +1,100 @@
+// Copyright 2019 Drone IO, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package search
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/drone/drone/core"
+	"github.com/drone/drone/handler/api/errors"
+	"github.com/drone/drone/handler/api/render"
+	"github.com/drone/drone/handler/api/request"
+	"github.com/drone/drone/logger"
+)
+
+const (
+	minQueryLength = 3
+	defaultLimit   = 100
+	maxLimit       = 1000
+)
+
+var (
+	errSearchDisabled = errors.New("Log search is not enabled")
+	errQueryInvalid   = errors.New("Search query must be at least 3 characters")
+)
+
+// HandleLogs returns an http.HandlerFunc that writes a json-encoded
+// list of log lines matching the search query to the response body.
+// The search is limited to the repositories the user can access.
+func HandleLogs(
+	repos core.RepositoryStore,
+	index core.LogIndex,
+) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if index == nil {
+			render.NotImplemented(w, errSearchDisabled)
+			return
+		}
+
+		text := r.FormValue("q")
+		if len(text) < minQueryLength {
+			render.BadRequest(w, errQueryInvalid)
+			return
+		}
+
+		limit, _ := strconv.Atoi(r.FormValue("limit"))
+		if limit <= 0 {
+			limit = defaultLimit
+		}
+		if limit > maxLimit {
+			limit = maxLimit
+		}
+
+		viewer, _ := request.UserFrom(r.Context())
+		list, err := repos.List(r.Context(), viewer.ID)
+		if err != nil {
+			render.InternalError(w, err)
+			logger.FromRequest(r).WithError(err).
+				Debugln("api: cannot list repositories")
+			return
+		}
+
+		// the search can be optionally limited to a
+		// single repository, using the repository slug.
+		slug := r.FormValue("repo")
+		query := &core.LogQuery{
+			Text:  text,
+			Limit: limit,
+		}
+		for _, repo := range list {
+			if slug == "" || slug == repo.Slug {
+				query.Repos = append(query.Repos, repo.ID)
+			}
+		}
+
+		hits, err := index.Search(r.Context(), query)
+		if err != nil {
+			render.InternalError(w, err)
+			logger.FromRequest(r).WithError(err).
+				Debugln("api: cannot search logs")
+			return
+		}
+		if hits == nil {
+			hits = []*core.LogHit{}
+		}
+		render.JSON(w, hits, 200)
+	}
+}
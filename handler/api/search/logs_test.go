@@ -0,0 +1,171 @@
+// Copyright 2019 Drone.IO Inc. All rights reserved.
+// Use of this source code is governed by the Drone Non-Commercial License
+// that can be found in the LICENSE file.
+
+package search
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/drone/drone/core"
+	"github.com/drone/drone/handler/api/errors"
+	"github.com/drone/drone/handler/api/request"
+	"github.com/drone/drone/mock"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/go-cmp/cmp"
+	"github.com/sirupsen/logrus"
+)
+
+func init() {
+	logrus.SetOutput(ioutil.Discard)
+}
+
+var (
+	mockUser = &core.User{
+		ID:    1,
+		Login: "octocat",
+	}
+
+	mockRepos = []*core.Repository{
+		{ID: 1, Slug: "octocat/hello-world"},
+		{ID: 2, Slug: "octocat/spoon-knife"},
+	}
+
+	mockHits = []*core.LogHit{
+		{
+			RepoID:    1,
+			Slug:      "octocat/hello-world",
+			BuildID:   1,
+			Build:     1,
+			Stage:     1,
+			StageName: "default",
+			StepID:    1,
+			Step:      2,
+			StepName:  "test",
+			Line:      1,
+			Message:   "--- FAIL: TestLogs\n",
+		},
+	}
+)
+
+func TestHandleLogs(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	repos := mock.NewMockRepositoryStore(controller)
+	repos.EXPECT().List(gomock.Any(), mockUser.ID).Return(mockRepos, nil)
+
+	want := &core.LogQuery{Text: "FAIL", Repos: []int64{1, 2}, Limit: 100}
+	index := mock.NewMockLogIndex(controller)
+	index.EXPECT().Search(gomock.Any(), want).Return(mockHits, nil)
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("GET", "/?q=FAIL", nil)
+	r = r.WithContext(
+		request.WithUser(r.Context(), mockUser),
+	)
+
+	HandleLogs(repos, index)(w, r)
+	if got, want := w.Code, http.StatusOK; want != got {
+		t.Errorf("Want response code %d, got %d", want, got)
+	}
+
+	got := []*core.LogHit{}
+	json.NewDecoder(w.Body).Decode(&got)
+	if diff := cmp.Diff(got, mockHits); len(diff) > 0 {
+		t.Errorf(diff)
+	}
+}
+
+func TestHandleLogs_Repo(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	repos := mock.NewMockRepositoryStore(controller)
+	repos.EXPECT().List(gomock.Any(), mockUser.ID).Return(mockRepos, nil)
+
+	want := &core.LogQuery{Text: "FAIL", Repos: []int64{2}, Limit: 10}
+	index := mock.NewMockLogIndex(controller)
+	index.EXPECT().Search(gomock.Any(), want).Return(nil, nil)
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("GET", "/?q=FAIL&repo=octocat/spoon-knife&limit=10", nil)
+	r = r.WithContext(
+		request.WithUser(r.Context(), mockUser),
+	)
+
+	HandleLogs(repos, index)(w, r)
+	if got, want := w.Code, http.StatusOK; want != got {
+		t.Errorf("Want response code %d, got %d", want, got)
+	}
+
+	got := []*core.LogHit{}
+	json.NewDecoder(w.Body).Decode(&got)
+	if len(got) != 0 {
+		t.Errorf("Want empty list of hits, got %d", len(got))
+	}
+}
+
+func TestHandleLogs_BadRequest(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	index := mock.NewMockLogIndex(controller)
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("GET", "/?q=go", nil)
+	r = r.WithContext(
+		request.WithUser(r.Context(), mockUser),
+	)
+
+	HandleLogs(nil, index)(w, r)
+	if got, want := w.Code, http.StatusBadRequest; want != got {
+		t.Errorf("Want response code %d, got %d", want, got)
+	}
+
+	got, want := new(errors.Error), errQueryInvalid
+	json.NewDecoder(w.Body).Decode(got)
+	if diff := cmp.Diff(got, want); len(diff) > 0 {
+		t.Errorf(diff)
+	}
+}
+
+func TestHandleLogs_Disabled(t *testing.T) {
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("GET", "/?q=FAIL", nil)
+	r = r.WithContext(
+		request.WithUser(r.Context(), mockUser),
+	)
+
+	HandleLogs(nil, nil)(w, r)
+	if got, want := w.Code, http.StatusNotImplemented; want != got {
+		t.Errorf("Want response code %d, got %d", want, got)
+	}
+}
+
+func TestHandleLogs_SearchErr(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	repos := mock.NewMockRepositoryStore(controller)
+	repos.EXPECT().List(gomock.Any(), mockUser.ID).Return(mockRepos, nil)
+
+	index := mock.NewMockLogIndex(controller)
+	index.EXPECT().Search(gomock.Any(), gomock.Any()).Return(nil, errors.ErrNotFound)
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("GET", "/?q=FAIL", nil)
+	r = r.WithContext(
+		request.WithUser(r.Context(), mockUser),
+	)
+
+	HandleLogs(repos, index)(w, r)
+	if got, want := w.Code, http.StatusInternalServerError; want != got {
+		t.Errorf("Want response code %d, got %d", want, got)
+	}
+}
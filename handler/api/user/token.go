@@ -19,6 +19,7 @@ import (
 
 	"github.com/dchest/uniuri"
 	"github.com/drone/drone/core"
+	"github.com/drone/drone/handler/api/errors"
 	"github.com/drone/drone/handler/api/render"
 	"github.com/drone/drone/handler/api/request"
 )
@@ -34,6 +35,13 @@ func HandleToken(users core.UserStore) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		ctx := r.Context()
 		viewer, _ := request.UserFrom(ctx)
+		// the user token grants unrestricted access to the
+		// account and cannot be retrieved with a personal
+		// access token.
+		if viewer.AccessToken != nil {
+			render.Forbidden(w, errors.ErrForbidden)
+			return
+		}
 		if r.FormValue("rotate") == "true" {
 			viewer.Hash = uniuri.NewLen(32)
 			if err := users.Update(ctx, viewer); err != nil {
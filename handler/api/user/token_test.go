@@ -122,3 +122,25 @@ func TestToken_UpdateError(t *testing.T) {
 		t.Errorf(diff)
 	}
 }
+
+// the purpose of this unit test is to verify that the user
+// token cannot be retrieved with a personal access token.
+func TestToken_AccessToken(t *testing.T) {
+	mockUser := &core.User{
+		ID:          1,
+		Login:       "octocat",
+		Hash:        "MjAxOC0wOC0xMVQxNTo1ODowN1o",
+		AccessToken: &core.AccessToken{Scopes: []string{core.ScopeAdmin}},
+	}
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("POST", "/", nil)
+	r = r.WithContext(
+		request.WithUser(r.Context(), mockUser),
+	)
+
+	HandleToken(nil)(w, r)
+	if got, want := w.Code, 403; want != got {
+		t.Errorf("Want response code %d, got %d", want, got)
+	}
+}
@@ -0,0 +1,118 @@
+// Copyright 2019 Drone IO, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tokens
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/drone/drone/core"
+	"github.com/drone/drone/handler/api/errors"
+	"github.com/drone/drone/handler/api/render"
+	"github.com/drone/drone/handler/api/request"
+	"github.com/drone/drone/logger"
+
+	"github.com/dchest/uniuri"
+	"github.com/drone/go-scm/scm"
+)
+
+type tokenInput struct {
+	Name    string   `json:"name"`
+	Scopes  []string `json:"scopes"`
+	Repo    string   `json:"repo"`
+	Expires int64    `json:"expires"`
+}
+
+// HandleCreate returns an http.HandlerFunc that processes http
+// requests to create a new personal access token. The plaintext
+// token is only included in this response.
+func HandleCreate(
+	repos core.RepositoryStore,
+	perms core.PermStore,
+	tokens core.AccessTokenStore,
+) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		viewer, _ := request.UserFrom(ctx)
+
+		// a personal access token cannot be used to create
+		// additional tokens, otherwise a restricted token
+		// could be exchanged for a less restricted token.
+		if viewer.AccessToken != nil {
+			render.Forbidden(w, errors.ErrForbidden)
+			logger.FromRequest(r).
+				Debugln("api: cannot create token with a personal access token")
+			return
+		}
+
+		in := new(tokenInput)
+		err := json.NewDecoder(r.Body).Decode(in)
+		if err != nil {
+			render.BadRequest(w, err)
+			return
+		}
+
+		now := time.Now().Unix()
+		if in.Expires != 0 && in.Expires <= now {
+			render.BadRequestf(w, "Token expiry must be in the future")
+			return
+		}
+
+		raw := core.TokenPrefix + uniuri.NewLen(40)
+		token := &core.AccessToken{
+			UserID:  viewer.ID,
+			Name:    strings.TrimSpace(in.Name),
+			Scopes:  in.Scopes,
+			Hash:    core.HashToken(raw),
+			Expires: in.Expires,
+			Created: now,
+		}
+
+		if in.Repo != "" {
+			namespace, name := scm.Split(in.Repo)
+			repo, err := repos.FindName(ctx, namespace, name)
+			if err != nil {
+				render.BadRequestf(w, "Cannot find repository %s", in.Repo)
+				return
+			}
+			if !viewer.Admin {
+				if _, err := perms.Find(ctx, repo.UID, viewer.ID); err != nil {
+					render.BadRequestf(w, "Cannot find repository %s", in.Repo)
+					return
+				}
+			}
+			token.RepoID = repo.ID
+		}
+
+		err = token.Validate()
+		if err != nil {
+			render.BadRequest(w, err)
+			return
+		}
+
+		err = tokens.Create(ctx, token)
+		if err != nil {
+			render.InternalError(w, err)
+			logger.FromRequest(r).WithError(err).
+				Warnln("api: cannot create token")
+			return
+		}
+
+		token.Token = raw
+		render.JSON(w, token, 200)
+	}
+}
@@ -0,0 +1,165 @@
+// Copyright 2019 Drone.IO Inc. All rights reserved.
+// Use of this source code is governed by the Drone Non-Commercial License
+// that can be found in the LICENSE file.
+
+package tokens
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/drone/drone/core"
+	"github.com/drone/drone/handler/api/request"
+	"github.com/drone/drone/mock"
+
+	"github.com/golang/mock/gomock"
+)
+
+var mockUser = &core.User{
+	ID:    1,
+	Login: "octocat",
+}
+
+func TestHandleCreate(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	mockRepo := &core.Repository{ID: 2, UID: "42", Namespace: "octocat", Name: "hello-world"}
+
+	repos := mock.NewMockRepositoryStore(controller)
+	repos.EXPECT().FindName(gomock.Any(), "octocat", "hello-world").Return(mockRepo, nil)
+
+	perms := mock.NewMockPermStore(controller)
+	perms.EXPECT().Find(gomock.Any(), mockRepo.UID, mockUser.ID).Return(&core.Perm{Read: true}, nil)
+
+	var created *core.AccessToken
+	tokens := mock.NewMockAccessTokenStore(controller)
+	tokens.EXPECT().Create(gomock.Any(), gomock.Any()).Do(func(_ interface{}, token *core.AccessToken) {
+		created = token
+	}).Return(nil)
+
+	in := new(bytes.Buffer)
+	json.NewEncoder(in).Encode(&tokenInput{
+		Name:   "deploy",
+		Scopes: []string{core.ScopeBuildsWrite},
+		Repo:   "octocat/hello-world",
+	})
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("POST", "/", in)
+	r = r.WithContext(
+		request.WithUser(r.Context(), mockUser),
+	)
+
+	HandleCreate(repos, perms, tokens).ServeHTTP(w, r)
+	if got, want := w.Code, 200; want != got {
+		t.Errorf("Want response code %d, got %d", want, got)
+	}
+
+	got := new(core.AccessToken)
+	json.NewDecoder(w.Body).Decode(got)
+	if !strings.HasPrefix(got.Token, core.TokenPrefix) {
+		t.Errorf("Want plaintext token returned, got %q", got.Token)
+	}
+	if got, want := created.Hash, core.HashToken(got.Token); got != want {
+		t.Errorf("Want token hash %q, got %q", want, got)
+	}
+	if got, want := created.RepoID, mockRepo.ID; got != want {
+		t.Errorf("Want token restricted to repository %d, got %d", want, got)
+	}
+	if got, want := created.UserID, mockUser.ID; got != want {
+		t.Errorf("Want token owned by user %d, got %d", want, got)
+	}
+}
+
+func TestHandleCreate_RepoNotFound(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	mockRepo := &core.Repository{ID: 2, UID: "42", Namespace: "octocat", Name: "hello-world"}
+
+	repos := mock.NewMockRepositoryStore(controller)
+	repos.EXPECT().FindName(gomock.Any(), "octocat", "hello-world").Return(mockRepo, nil)
+
+	perms := mock.NewMockPermStore(controller)
+	perms.EXPECT().Find(gomock.Any(), mockRepo.UID, mockUser.ID).Return(nil, sql.ErrNoRows)
+
+	in := new(bytes.Buffer)
+	json.NewEncoder(in).Encode(&tokenInput{
+		Name:   "deploy",
+		Scopes: []string{core.ScopeRead},
+		Repo:   "octocat/hello-world",
+	})
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("POST", "/", in)
+	r = r.WithContext(
+		request.WithUser(r.Context(), mockUser),
+	)
+
+	HandleCreate(repos, perms, nil).ServeHTTP(w, r)
+	if got, want := w.Code, 400; want != got {
+		t.Errorf("Want response code %d, got %d", want, got)
+	}
+}
+
+func TestHandleCreate_InvalidScope(t *testing.T) {
+	in := new(bytes.Buffer)
+	json.NewEncoder(in).Encode(&tokenInput{
+		Name:   "deploy",
+		Scopes: []string{"repo"},
+	})
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("POST", "/", in)
+	r = r.WithContext(
+		request.WithUser(r.Context(), mockUser),
+	)
+
+	HandleCreate(nil, nil, nil).ServeHTTP(w, r)
+	if got, want := w.Code, 400; want != got {
+		t.Errorf("Want response code %d, got %d", want, got)
+	}
+}
+
+func TestHandleCreate_Expired(t *testing.T) {
+	in := new(bytes.Buffer)
+	json.NewEncoder(in).Encode(&tokenInput{
+		Name:    "deploy",
+		Scopes:  []string{core.ScopeRead},
+		Expires: 1522878684,
+	})
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("POST", "/", in)
+	r = r.WithContext(
+		request.WithUser(r.Context(), mockUser),
+	)
+
+	HandleCreate(nil, nil, nil).ServeHTTP(w, r)
+	if got, want := w.Code, 400; want != got {
+		t.Errorf("Want response code %d, got %d", want, got)
+	}
+}
+
+// this test verifies that a personal access token cannot be
+// used to create additional tokens.
+func TestHandleCreate_AccessToken(t *testing.T) {
+	user := *mockUser
+	user.AccessToken = &core.AccessToken{Scopes: []string{core.ScopeAdmin}}
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("POST", "/", nil)
+	r = r.WithContext(
+		request.WithUser(r.Context(), &user),
+	)
+
+	HandleCreate(nil, nil, nil).ServeHTTP(w, r)
+	if got, want := w.Code, 403; want != got {
+		t.Errorf("Want response code %d, got %d", want, got)
+	}
+}
@@ -0,0 +1,69 @@
+// Copyright 2019 Drone.IO Inc. All rights reserved.
+// Use of this source code is governed by the Drone Non-Commercial License
+// that can be found in the LICENSE file.
+
+package tokens
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/drone/drone/core"
+	"github.com/drone/drone/handler/api/request"
+	"github.com/drone/drone/mock"
+
+	"github.com/go-chi/chi"
+	"github.com/golang/mock/gomock"
+)
+
+func TestHandleDelete(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	mockToken := &core.AccessToken{ID: 3, UserID: mockUser.ID}
+
+	tokens := mock.NewMockAccessTokenStore(controller)
+	tokens.EXPECT().Find(gomock.Any(), mockToken.ID).Return(mockToken, nil)
+	tokens.EXPECT().Delete(gomock.Any(), mockToken).Return(nil)
+
+	c := new(chi.Context)
+	c.URLParams.Add("token", "3")
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("DELETE", "/", nil)
+	r = r.WithContext(
+		context.WithValue(request.WithUser(r.Context(), mockUser), chi.RouteCtxKey, c),
+	)
+
+	HandleDelete(tokens).ServeHTTP(w, r)
+	if got, want := w.Code, 204; want != got {
+		t.Errorf("Want response code %d, got %d", want, got)
+	}
+}
+
+// this test verifies that a user cannot revoke a token
+// owned by another user.
+func TestHandleDelete_NotOwner(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	mockToken := &core.AccessToken{ID: 3, UserID: 2}
+
+	tokens := mock.NewMockAccessTokenStore(controller)
+	tokens.EXPECT().Find(gomock.Any(), mockToken.ID).Return(mockToken, nil)
+
+	c := new(chi.Context)
+	c.URLParams.Add("token", "3")
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("DELETE", "/", nil)
+	r = r.WithContext(
+		context.WithValue(request.WithUser(r.Context(), mockUser), chi.RouteCtxKey, c),
+	)
+
+	HandleDelete(tokens).ServeHTTP(w, r)
+	if got, want := w.Code, 404; want != got {
+		t.Errorf("Want response code %d, got %d", want, got)
+	}
+}
@@ -22,10 +22,10 @@ import (
 	"strconv"
 	"time"
 
+	"github.com/dchest/uniuri"
 	"github.com/sirupsen/logrus"
 
 	"github.com/drone/drone/core"
-	"github.com/drone/drone/logger"
 	"github.com/drone/go-scm/scm"
 )
 
@@ -39,12 +39,23 @@ func init() {
 	)
 }
 
+// deliveryHeaders lists the headers used by source code management
+// systems to provide a unique webhook delivery identifier.
+var deliveryHeaders = []string{
+	"X-GitHub-Delivery",
+	"X-Gitea-Delivery",
+	"X-Gogs-Delivery",
+	"X-Gitlab-Event-UUID",
+	"X-Request-UUID",
+	"X-Request-Id",
+}
+
 // HandleHook returns an http.HandlerFunc that handles webhooks
-// triggered by source code management.
+// triggered by source code management. The webhook is recorded
+// in the journal and processed asynchronously.
 func HandleHook(
 	repos core.RepositoryStore,
-	builds core.BuildStore,
-	triggerer core.Triggerer,
+	journal core.JournalStore,
 	parser core.HookParser,
 ) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
@@ -56,6 +67,8 @@ func HandleHook(
 			os.Stderr.Write(out)
 		}
 
+		delivery := deliveryID(r)
+
 		hook, remote, err := parser.Parse(r, func(slug string) string {
 			namespace, name := scm.Split(slug)
 			repo, err := repos.FindName(r.Context(), namespace, name)
@@ -70,8 +83,49 @@ func HandleHook(
 			return repo.Signer
 		})
 
+		// requests that fail signature verification are not
+		// recorded, since they cannot be attributed to the
+		// source code management system.
+		if err == scm.ErrSignatureInvalid {
+			logrus.WithField("delivery", delivery).
+				Debugln("cannot verify webhook signature")
+			writeBadRequest(w, err)
+			return
+		}
+
+		// the delivery is deduplicated against webhooks that
+		// were previously parsed. A delivery that previously
+		// failed to parse is recorded again with the new result.
+		var prev *core.JournalEntry
+		if v, err := journal.FindDelivery(r.Context(), delivery); err == nil {
+			prev = v
+		}
+		if prev != nil && prev.Hook != nil {
+			logrus.WithField("delivery", delivery).
+				Debugln("ignore webhook, duplicate delivery")
+			w.WriteHeader(200)
+			return
+		}
+
+		now := time.Now().Unix()
+		entry := &core.JournalEntry{
+			Delivery: delivery,
+			Status:   core.JournalPending,
+			Next:     now,
+			Created:  now,
+			Updated:  now,
+		}
+		if prev != nil {
+			entry.ID = prev.ID
+			entry.Created = prev.Created
+			entry.Version = prev.Version
+		}
+
 		if err != nil {
 			logrus.Debugf("cannot parse webhook: %s", err)
+			entry.Status = core.JournalFailed
+			entry.Error = err.Error()
+			record(r.Context(), journal, entry)
 			writeBadRequest(w, err)
 			return
 		}
@@ -89,6 +143,7 @@ func HandleHook(
 			"name":      remote.Name,
 			"event":     hook.Event,
 			"commit":    hook.After,
+			"delivery":  delivery,
 		})
 
 		log.Debugln("webhook parsed")
@@ -101,35 +156,49 @@ func HandleHook(
 			return
 		}
 
+		entry.RepoID = repo.ID
+		entry.Event = hook.Event
+		entry.Action = hook.Action
+		entry.Hook = hook
+
 		if !repo.Active {
 			log.Debugln("ignore webhook, repository inactive")
-			w.WriteHeader(200)
-			return
+			entry.Status = core.JournalIgnored
 		}
 
-		ctx, cancel := context.WithTimeout(context.Background(), time.Minute*5)
-		ctx = logger.WithContext(ctx, log)
-		defer cancel()
-
-		if hook.Event == core.EventPush && hook.Action == core.ActionDelete {
-			log.WithField("branch", hook.Target).Debugln("branch deleted")
-			builds.DeleteBranch(ctx, repo.ID, hook.Target)
-			w.WriteHeader(http.StatusNoContent)
-			return
-		}
-		if hook.Event == core.EventPullRequest && hook.Action == core.ActionClose {
-			log.WithField("ref", hook.Ref).Debugln("pull request closed")
-			builds.DeletePull(ctx, repo.ID, scm.ExtractPullRequest(hook.Ref))
-			w.WriteHeader(http.StatusNoContent)
+		err = record(r.Context(), journal, entry)
+		if err != nil {
+			log = log.WithError(err)
+			log.Errorln("cannot record webhook")
+			writeError(w, err)
 			return
 		}
 
-		builds, err := triggerer.Trigger(ctx, repo, hook)
-		if err != nil {
-			writeError(w, err)
+		if entry.Status == core.JournalIgnored {
+			w.WriteHeader(200)
 			return
 		}
+		writeJSON(w, entry, http.StatusAccepted)
+	}
+}
 
-		writeJSON(w, builds, 200)
+// helper function records the journal entry, updating the
+// existing entry if the delivery was previously recorded.
+func record(ctx context.Context, journal core.JournalStore, entry *core.JournalEntry) error {
+	if entry.ID != 0 {
+		return journal.Update(ctx, entry)
+	}
+	return journal.Create(ctx, entry)
+}
+
+// helper function returns the webhook delivery identifier
+// provided by the source code management system. A unique
+// identifier is generated if no delivery header is present.
+func deliveryID(r *http.Request) string {
+	for _, key := range deliveryHeaders {
+		if id := r.Header.Get(key); id != "" {
+			return id
+		}
 	}
+	return uniuri.NewLen(32)
 }
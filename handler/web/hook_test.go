@@ -0,0 +1,180 @@
+// Copyright 2019 Drone.IO Inc. All rights reserved.
+// Use of this source code is governed by the Drone Non-Commercial License
+// that can be found in the LICENSE file.
+
+package web
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/drone/drone/core"
+	"github.com/drone/drone/mock"
+	"github.com/drone/go-scm/scm"
+
+	"github.com/golang/mock/gomock"
+)
+
+func TestHandleHook(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	repo := &core.Repository{ID: 1, Namespace: "octocat", Name: "hello-world", Active: true}
+	hook := &core.Hook{Event: core.EventPush, Action: core.ActionCreate}
+	remote := &core.Repository{Namespace: "octocat", Name: "hello-world"}
+
+	parser := mock.NewMockHookParser(controller)
+	parser.EXPECT().Parse(gomock.Any(), gomock.Any()).Return(hook, remote, nil)
+
+	repos := mock.NewMockRepositoryStore(controller)
+	repos.EXPECT().FindName(gomock.Any(), "octocat", "hello-world").Return(repo, nil)
+
+	journal := mock.NewMockJournalStore(controller)
+	journal.EXPECT().FindDelivery(gomock.Any(), "f3b1b0b0").Return(nil, sql.ErrNoRows)
+	journal.EXPECT().Create(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ interface{}, entry *core.JournalEntry) error {
+			if got, want := entry.Delivery, "f3b1b0b0"; got != want {
+				t.Errorf("Want delivery %s, got %s", want, got)
+			}
+			if got, want := entry.Status, core.JournalPending; got != want {
+				t.Errorf("Want status %s, got %s", want, got)
+			}
+			if entry.RepoID != repo.ID || entry.Hook != hook {
+				t.Errorf("Want journal entry linked to the repository and hook")
+			}
+			return nil
+		},
+	)
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("POST", "/hook", nil)
+	r.Header.Set("X-GitHub-Delivery", "f3b1b0b0")
+
+	HandleHook(repos, journal, parser).ServeHTTP(w, r)
+	if got, want := w.Code, http.StatusAccepted; want != got {
+		t.Errorf("Want response code %d, got %d", want, got)
+	}
+}
+
+// this test verifies that a webhook delivered more than once
+// is only recorded in the journal once.
+func TestHandleHook_Duplicate(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	hook := &core.Hook{Event: core.EventPush}
+	remote := &core.Repository{Namespace: "octocat", Name: "hello-world"}
+
+	parser := mock.NewMockHookParser(controller)
+	parser.EXPECT().Parse(gomock.Any(), gomock.Any()).Return(hook, remote, nil)
+
+	journal := mock.NewMockJournalStore(controller)
+	journal.EXPECT().FindDelivery(gomock.Any(), "f3b1b0b0").Return(&core.JournalEntry{Hook: hook}, nil)
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("POST", "/hook", nil)
+	r.Header.Set("X-Gitea-Delivery", "f3b1b0b0")
+
+	HandleHook(nil, journal, parser).ServeHTTP(w, r)
+	if got, want := w.Code, http.StatusOK; want != got {
+		t.Errorf("Want response code %d, got %d", want, got)
+	}
+}
+
+// this test verifies that a webhook delivery that previously
+// failed to parse is recorded again when it is redelivered.
+func TestHandleHook_DuplicateFailed(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	repo := &core.Repository{ID: 1, Namespace: "octocat", Name: "hello-world", Active: true}
+	hook := &core.Hook{Event: core.EventPush}
+	remote := &core.Repository{Namespace: "octocat", Name: "hello-world"}
+	prev := &core.JournalEntry{ID: 5, Delivery: "f3b1b0b0", Status: core.JournalFailed, Version: 1}
+
+	parser := mock.NewMockHookParser(controller)
+	parser.EXPECT().Parse(gomock.Any(), gomock.Any()).Return(hook, remote, nil)
+
+	repos := mock.NewMockRepositoryStore(controller)
+	repos.EXPECT().FindName(gomock.Any(), "octocat", "hello-world").Return(repo, nil)
+
+	journal := mock.NewMockJournalStore(controller)
+	journal.EXPECT().FindDelivery(gomock.Any(), "f3b1b0b0").Return(prev, nil)
+	journal.EXPECT().Update(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ interface{}, entry *core.JournalEntry) error {
+			if got, want := entry.ID, prev.ID; got != want {
+				t.Errorf("Want journal entry %d updated, got %d", want, got)
+			}
+			if got, want := entry.Status, core.JournalPending; got != want {
+				t.Errorf("Want status %s, got %s", want, got)
+			}
+			if entry.Hook != hook {
+				t.Errorf("Want journal entry linked to the hook")
+			}
+			return nil
+		},
+	)
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("POST", "/hook", nil)
+	r.Header.Set("X-GitHub-Delivery", "f3b1b0b0")
+
+	HandleHook(repos, journal, parser).ServeHTTP(w, r)
+	if got, want := w.Code, http.StatusAccepted; want != got {
+		t.Errorf("Want response code %d, got %d", want, got)
+	}
+}
+
+func TestHandleHook_ParseError(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	parser := mock.NewMockHookParser(controller)
+	parser.EXPECT().Parse(gomock.Any(), gomock.Any()).Return(nil, nil, errors.New("unexpected end of JSON input"))
+
+	journal := mock.NewMockJournalStore(controller)
+	journal.EXPECT().FindDelivery(gomock.Any(), gomock.Any()).Return(nil, sql.ErrNoRows)
+	journal.EXPECT().Create(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ interface{}, entry *core.JournalEntry) error {
+			if got, want := entry.Status, core.JournalFailed; got != want {
+				t.Errorf("Want status %s, got %s", want, got)
+			}
+			if entry.Delivery == "" {
+				t.Errorf("Want generated delivery identifier")
+			}
+			return nil
+		},
+	)
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("POST", "/hook", nil)
+
+	HandleHook(nil, journal, parser).ServeHTTP(w, r)
+	if got, want := w.Code, http.StatusBadRequest; want != got {
+		t.Errorf("Want response code %d, got %d", want, got)
+	}
+}
+
+// this test verifies that a webhook with an invalid signature
+// is rejected and not recorded in the journal.
+func TestHandleHook_SignatureInvalid(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	parser := mock.NewMockHookParser(controller)
+	parser.EXPECT().Parse(gomock.Any(), gomock.Any()).Return(nil, nil, scm.ErrSignatureInvalid)
+
+	journal := mock.NewMockJournalStore(controller)
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("POST", "/hook", nil)
+	r.Header.Set("X-GitHub-Delivery", "f3b1b0b0")
+
+	HandleHook(nil, journal, parser).ServeHTTP(w, r)
+	if got, want := w.Code, http.StatusBadRequest; want != got {
+		t.Errorf("Want response code %d, got %d", want, got)
+	}
+}
@@ -72,6 +72,25 @@ func HandleLogin(
 
 		redirect := "/"
 		user, err := users.FindLogin(ctx, account.Login)
+
+		// if the session user is not linked to the source control
+		// management system, for example an account created with
+		// OpenID Connect, the source control account is linked to
+		// the session user. The login is updated to match the
+		// source control account for subsequent logins.
+		if current, _ := session.Get(r); current != nil && current.Token == "" {
+			switch {
+			case err == sql.ErrNoRows:
+				logger.Debugf("linking user %s", current.Login)
+				current.Login = account.Login
+				user, err = current, nil
+			case err == nil && user.ID != current.ID:
+				writeLoginErrorStr(w, r, "Source control account is linked to another user")
+				logger.Debugf("cannot link user %s", current.Login)
+				return
+			}
+		}
+
 		if err == sql.ErrNoRows {
 			redirect = "/register"
 
@@ -0,0 +1,269 @@
+// Copyright 2019 Drone IO, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package web
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/drone/drone/core"
+
+	"github.com/dchest/uniuri"
+	"github.com/sirupsen/logrus"
+)
+
+// name of the cookie used to store the login state and
+// nonce between the redirect and the callback.
+const oidcCookie = "_oidc_"
+
+// HandleLoginOIDC creates an http.HandlerFunc that handles user
+// authentication with an OpenID Connect provider and session
+// initialization. Identities are matched to existing accounts
+// by verified email, or by login if configured, and a new
+// account is created if no match is found.
+func HandleLoginOIDC(
+	users core.UserStore,
+	identities core.IdentityStore,
+	idp core.IdentityService,
+	session core.Session,
+	admission core.AdmissionService,
+	sender core.WebhookSender,
+) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		if errstr := r.FormValue("error"); errstr != "" {
+			writeLoginErrorStr(w, r, errstr)
+			logrus.Debugf("cannot authenticate user: %s", errstr)
+			return
+		}
+
+		// if the authorization code is missing the user is
+		// redirected to the provider to authenticate.
+		code := r.FormValue("code")
+		if code == "" {
+			state, nonce := uniuri.NewLen(32), uniuri.NewLen(32)
+			redirect, err := idp.Redirect(ctx, state, nonce)
+			if err != nil {
+				writeLoginError(w, r, err)
+				logrus.Errorf("cannot discover identity provider: %s", err)
+				return
+			}
+			writeCookie(w, &http.Cookie{
+				Name:     oidcCookie,
+				Value:    state + "." + nonce,
+				Path:     "/login/oidc",
+				MaxAge:   600,
+				HttpOnly: true,
+			})
+			http.Redirect(w, r, redirect, http.StatusSeeOther)
+			return
+		}
+
+		cookie, err := r.Cookie(oidcCookie)
+		if err != nil {
+			writeLoginErrorStr(w, r, "Login state expired")
+			return
+		}
+		parts := strings.SplitN(cookie.Value, ".", 2)
+		if len(parts) != 2 || parts[0] != r.FormValue("state") {
+			writeLoginErrorStr(w, r, "Login state mismatch")
+			return
+		}
+		writeCookie(w, &http.Cookie{
+			Name:   oidcCookie,
+			Value:  "deleted",
+			Path:   "/login/oidc",
+			MaxAge: -1,
+		})
+
+		claims, err := idp.Verify(ctx, code, parts[1])
+		if err != nil {
+			writeLoginError(w, r, err)
+			logrus.Debugf("cannot verify identity: %s", err)
+			return
+		}
+
+		logger := logrus.WithField("subject", claims.Subject)
+		logger.Debugf("attempting authentication")
+
+		identity, err := identities.FindSubject(ctx, claims.Subject)
+		if err == sql.ErrNoRows {
+			identity = nil
+		} else if err != nil {
+			writeLoginError(w, r, err)
+			logger.Errorf("cannot find identity: %s", err)
+			return
+		}
+
+		var user *core.User
+		if identity != nil {
+			user, err = users.Find(ctx, identity.UserID)
+		} else {
+			user, err = matchUser(ctx, users, claims)
+		}
+
+		if err == sql.ErrNoRows {
+			// the login may be in use by an account that is not
+			// matched to the identity, for example an account
+			// created by the source control management system.
+			// The login must be unique, and the account is not
+			// linked without a match.
+			if _, ferr := users.FindLogin(ctx, claims.Login); ferr == nil {
+				writeLoginErrorStr(w, r, "Login is already in use by another account")
+				logger.Warnf("cannot create user: login %s is already in use", claims.Login)
+				return
+			}
+
+			user = &core.User{
+				Login:     claims.Login,
+				Avatar:    claims.Avatar,
+				Admin:     false,
+				Machine:   false,
+				Active:    true,
+				LastLogin: time.Now().Unix(),
+				Created:   time.Now().Unix(),
+				Updated:   time.Now().Unix(),
+				Hash:      uniuri.NewLen(32),
+			}
+			if claims.Verified {
+				user.Email = claims.Email
+			}
+
+			err = admission.Admit(ctx, user)
+			if err != nil {
+				writeLoginError(w, r, err)
+				logger.Errorf("cannot admit user: %s", err)
+				return
+			}
+
+			err = users.Create(ctx, user)
+			if err != nil {
+				writeLoginError(w, r, err)
+				logger.Errorf("cannot create user: %s", err)
+				return
+			}
+
+			err = sender.Send(ctx, &core.WebhookData{
+				Event:  core.WebhookEventUser,
+				Action: core.WebhookActionCreated,
+				User:   user,
+			})
+			if err != nil {
+				logger.Errorf("cannot send webhook: %s", err)
+			} else {
+				logger.Debugf("successfully created user")
+			}
+		} else if err != nil {
+			writeLoginError(w, r, err)
+			logger.Errorf("cannot find user: %s", err)
+			return
+		} else {
+			err = admission.Admit(ctx, user)
+			if err != nil {
+				writeLoginError(w, r, err)
+				logger.Errorf("cannot admit user: %s", err)
+				return
+			}
+		}
+
+		if user.Machine {
+			writeLoginErrorStr(w, r, "Machine account login is forbidden")
+			return
+		}
+
+		if user.Active == false {
+			writeLoginErrorStr(w, r, "Account is not active")
+			return
+		}
+
+		// an account can only be linked to a single identity.
+		// if the matched account is already linked we cannot
+		// link it to this identity.
+		if identity == nil {
+			linked, err := identities.FindUser(ctx, user.ID)
+			if err == nil && linked.Subject != claims.Subject {
+				writeLoginErrorStr(w, r, "Account is linked to another identity")
+				return
+			}
+			identity = &core.Identity{
+				UserID:  user.ID,
+				Subject: claims.Subject,
+				Created: time.Now().Unix(),
+			}
+		}
+		identity.Email = claims.Email
+		identity.Orgs = claims.Orgs
+		identity.Updated = time.Now().Unix()
+		if identity.ID == 0 {
+			err = identities.Create(ctx, identity)
+		} else {
+			err = identities.Update(ctx, identity)
+		}
+		if err != nil {
+			writeLoginError(w, r, err)
+			logger.Errorf("cannot save identity: %s", err)
+			return
+		}
+
+		// the administrator flag is only managed by the
+		// identity provider if an admin mapping is configured.
+		if claims.Admin != nil {
+			user.Admin = *claims.Admin
+		}
+		if claims.Avatar != "" {
+			user.Avatar = claims.Avatar
+		}
+		if user.Email == "" && claims.Verified {
+			user.Email = claims.Email
+		}
+		user.LastLogin = time.Now().Unix()
+
+		err = users.Update(ctx, user)
+		if err != nil {
+			// if the account update fails we should still
+			// proceed to create the user session. This is
+			// considered a non-fatal error.
+			logger.Errorf("cannot update user: %s", err)
+		}
+
+		redirect := "/"
+		if len(user.Email) == 0 {
+			redirect = "/register"
+		}
+
+		logger.WithField("login", user.Login).
+			Debugf("authentication successful")
+
+		session.Create(w, user)
+		http.Redirect(w, r, redirect, http.StatusSeeOther)
+	}
+}
+
+// helper function returns the existing user account that
+// matches the identity claims. Accounts are matched by login
+// if configured, otherwise by verified email address.
+func matchUser(ctx context.Context, users core.UserStore, claims *core.IdentityClaims) (*core.User, error) {
+	switch {
+	case claims.MatchLogin:
+		return users.FindLogin(ctx, claims.Login)
+	case claims.Email != "" && claims.Verified:
+		return users.FindEmail(ctx, claims.Email)
+	default:
+		return nil, sql.ErrNoRows
+	}
+}
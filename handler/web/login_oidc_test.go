@@ -0,0 +1,246 @@
+// Copyright 2019 Drone.IO Inc. All rights reserved.
+// Use of this source code is governed by the Drone Non-Commercial License
+// that can be found in the LICENSE file.
+
+package web
+
+import (
+	"database/sql"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/drone/drone/core"
+	"github.com/drone/drone/mock"
+
+	"github.com/golang/mock/gomock"
+)
+
+func TestLoginOIDC_Redirect(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	idp := mock.NewMockIdentityService(controller)
+	idp.EXPECT().Redirect(gomock.Any(), gomock.Any(), gomock.Any()).Return("https://idp.company.com/authorize", nil)
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("GET", "/login/oidc", nil)
+
+	HandleLoginOIDC(nil, nil, idp, nil, nil, nil).ServeHTTP(w, r)
+	if got, want := w.Code, http.StatusSeeOther; want != got {
+		t.Errorf("Want response code %d, got %d", want, got)
+	}
+	if got, want := w.Header().Get("Location"), "https://idp.company.com/authorize"; want != got {
+		t.Errorf("Want redirect %s, got %s", want, got)
+	}
+	if got := w.Header().Get("Set-Cookie"); !strings.HasPrefix(got, "_oidc_=") {
+		t.Errorf("Want login state cookie, got %q", got)
+	}
+}
+
+// this test verifies that a new user account and identity
+// are created when the identity does not match an existing
+// user account.
+func TestLoginOIDC_Create(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	claims := &core.IdentityClaims{
+		Subject:  "248289761001",
+		Login:    "octocat",
+		Email:    "octocat@github.com",
+		Verified: true,
+		Orgs:     []string{"octo-org"},
+	}
+
+	idp := mock.NewMockIdentityService(controller)
+	idp.EXPECT().Verify(gomock.Any(), "8cbc4d4a", "n-0S6_WzA2Mj").Return(claims, nil)
+
+	users := mock.NewMockUserStore(controller)
+	users.EXPECT().FindEmail(gomock.Any(), claims.Email).Return(nil, sql.ErrNoRows)
+	users.EXPECT().FindLogin(gomock.Any(), claims.Login).Return(nil, sql.ErrNoRows)
+	users.EXPECT().Create(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ interface{}, user *core.User) error {
+			if got, want := user.Login, "octocat"; got != want {
+				t.Errorf("Want login %s, got %s", want, got)
+			}
+			if got, want := user.Email, "octocat@github.com"; got != want {
+				t.Errorf("Want email %s, got %s", want, got)
+			}
+			user.ID = 1
+			return nil
+		},
+	)
+	users.EXPECT().Update(gomock.Any(), gomock.Any()).Return(nil)
+
+	identities := mock.NewMockIdentityStore(controller)
+	identities.EXPECT().FindSubject(gomock.Any(), claims.Subject).Return(nil, sql.ErrNoRows)
+	identities.EXPECT().FindUser(gomock.Any(), int64(1)).Return(nil, sql.ErrNoRows)
+	identities.EXPECT().Create(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ interface{}, identity *core.Identity) error {
+			if got, want := identity.UserID, int64(1); got != want {
+				t.Errorf("Want identity linked to user %d, got %d", want, got)
+			}
+			if got, want := identity.Orgs, claims.Orgs; len(got) != 1 || got[0] != want[0] {
+				t.Errorf("Want identity orgs %v, got %v", want, got)
+			}
+			return nil
+		},
+	)
+
+	admission := mock.NewMockAdmissionService(controller)
+	admission.EXPECT().Admit(gomock.Any(), gomock.Any()).Return(nil)
+
+	sender := mock.NewMockWebhookSender(controller)
+	sender.EXPECT().Send(gomock.Any(), gomock.Any()).Return(nil)
+
+	session := mock.NewMockSession(controller)
+	session.EXPECT().Create(gomock.Any(), gomock.Any()).Return(nil)
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("GET", "/login/oidc?code=8cbc4d4a&state=xyz", nil)
+	r.AddCookie(&http.Cookie{Name: oidcCookie, Value: "xyz.n-0S6_WzA2Mj"})
+
+	HandleLoginOIDC(users, identities, idp, session, admission, sender).ServeHTTP(w, r)
+	if got, want := w.Code, http.StatusSeeOther; want != got {
+		t.Errorf("Want response code %d, got %d", want, got)
+	}
+	if got, want := w.Header().Get("Location"), "/"; want != got {
+		t.Errorf("Want redirect %s, got %s", want, got)
+	}
+}
+
+// this test verifies that a linked identity resolves the
+// user account, and that the admin flag and organizations
+// are updated from the identity claims.
+func TestLoginOIDC_Linked(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	admin := true
+	claims := &core.IdentityClaims{
+		Subject: "248289761001",
+		Login:   "octocat",
+		Admin:   &admin,
+		Orgs:    []string{"octo-org"},
+	}
+	user := &core.User{ID: 1, Login: "octocat", Email: "octocat@github.com", Active: true}
+	identity := &core.Identity{ID: 2, UserID: 1, Subject: claims.Subject}
+
+	idp := mock.NewMockIdentityService(controller)
+	idp.EXPECT().Verify(gomock.Any(), "8cbc4d4a", "n-0S6_WzA2Mj").Return(claims, nil)
+
+	users := mock.NewMockUserStore(controller)
+	users.EXPECT().Find(gomock.Any(), user.ID).Return(user, nil)
+	users.EXPECT().Update(gomock.Any(), user).Return(nil)
+
+	identities := mock.NewMockIdentityStore(controller)
+	identities.EXPECT().FindSubject(gomock.Any(), claims.Subject).Return(identity, nil)
+	identities.EXPECT().Update(gomock.Any(), identity).Return(nil)
+
+	admission := mock.NewMockAdmissionService(controller)
+	admission.EXPECT().Admit(gomock.Any(), user).Return(nil)
+
+	session := mock.NewMockSession(controller)
+	session.EXPECT().Create(gomock.Any(), user).Return(nil)
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("GET", "/login/oidc?code=8cbc4d4a&state=xyz", nil)
+	r.AddCookie(&http.Cookie{Name: oidcCookie, Value: "xyz.n-0S6_WzA2Mj"})
+
+	HandleLoginOIDC(users, identities, idp, session, admission, nil).ServeHTTP(w, r)
+	if got, want := w.Code, http.StatusSeeOther; want != got {
+		t.Errorf("Want response code %d, got %d", want, got)
+	}
+	if !user.Admin {
+		t.Errorf("Want admin flag mapped from claims")
+	}
+	if got := identity.Orgs; len(got) != 1 || got[0] != "octo-org" {
+		t.Errorf("Want identity orgs updated, got %v", got)
+	}
+}
+
+// this test verifies that an identity cannot be linked to
+// a user account that is already linked to another identity.
+func TestLoginOIDC_LinkedToAnother(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	claims := &core.IdentityClaims{
+		Subject:  "248289761001",
+		Login:    "octocat",
+		Email:    "octocat@github.com",
+		Verified: true,
+	}
+	user := &core.User{ID: 1, Login: "octocat", Active: true}
+
+	idp := mock.NewMockIdentityService(controller)
+	idp.EXPECT().Verify(gomock.Any(), gomock.Any(), gomock.Any()).Return(claims, nil)
+
+	users := mock.NewMockUserStore(controller)
+	users.EXPECT().FindEmail(gomock.Any(), claims.Email).Return(user, nil)
+
+	identities := mock.NewMockIdentityStore(controller)
+	identities.EXPECT().FindSubject(gomock.Any(), claims.Subject).Return(nil, sql.ErrNoRows)
+	identities.EXPECT().FindUser(gomock.Any(), user.ID).Return(&core.Identity{Subject: "1"}, nil)
+
+	admission := mock.NewMockAdmissionService(controller)
+	admission.EXPECT().Admit(gomock.Any(), user).Return(nil)
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("GET", "/login/oidc?code=8cbc4d4a&state=xyz", nil)
+	r.AddCookie(&http.Cookie{Name: oidcCookie, Value: "xyz.n-0S6_WzA2Mj"})
+
+	HandleLoginOIDC(users, identities, idp, nil, admission, nil).ServeHTTP(w, r)
+	if got, want := w.Header().Get("Location"), "/login/error?message=Account is linked to another identity"; want != got {
+		t.Errorf("Want redirect %s, got %s", want, got)
+	}
+}
+
+// this test verifies that a new user account is not created
+// if the login is in use by an account that does not match the
+// identity.
+func TestLoginOIDC_LoginInUse(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	claims := &core.IdentityClaims{
+		Subject:  "248289761001",
+		Login:    "octocat",
+		Email:    "octocat@company.com",
+		Verified: true,
+	}
+
+	idp := mock.NewMockIdentityService(controller)
+	idp.EXPECT().Verify(gomock.Any(), "8cbc4d4a", "n-0S6_WzA2Mj").Return(claims, nil)
+
+	users := mock.NewMockUserStore(controller)
+	users.EXPECT().FindEmail(gomock.Any(), claims.Email).Return(nil, sql.ErrNoRows)
+	users.EXPECT().FindLogin(gomock.Any(), claims.Login).Return(&core.User{ID: 1, Login: "octocat"}, nil)
+
+	identities := mock.NewMockIdentityStore(controller)
+	identities.EXPECT().FindSubject(gomock.Any(), claims.Subject).Return(nil, sql.ErrNoRows)
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("GET", "/login/oidc?code=8cbc4d4a&state=xyz", nil)
+	r.AddCookie(&http.Cookie{Name: oidcCookie, Value: "xyz.n-0S6_WzA2Mj"})
+
+	HandleLoginOIDC(users, identities, idp, nil, nil, nil).ServeHTTP(w, r)
+	if got, want := w.Header().Get("Location"), "/login/error?message=Login is already in use by another account"; want != got {
+		t.Errorf("Want redirect %s, got %s", want, got)
+	}
+}
+
+// this test verifies that the login fails if the state
+// parameter does not match the state cookie.
+func TestLoginOIDC_StateMismatch(t *testing.T) {
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("GET", "/login/oidc?code=8cbc4d4a&state=abc", nil)
+	r.AddCookie(&http.Cookie{Name: oidcCookie, Value: "xyz.n-0S6_WzA2Mj"})
+
+	HandleLoginOIDC(nil, nil, nil, nil, nil, nil).ServeHTTP(w, r)
+	if got, want := w.Header().Get("Location"), "/login/error?message=Login state mismatch"; want != got {
+		t.Errorf("Want redirect %s, got %s", want, got)
+	}
+}
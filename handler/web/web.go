@@ -32,17 +32,18 @@ import (
 
 func New(
 	admitter core.AdmissionService,
-	builds core.BuildStore,
 	client *scm.Client,
 	hooks core.HookParser,
+	identities core.IdentityStore,
+	journal core.JournalStore,
 	license *core.License,
 	licenses core.LicenseService,
 	linker core.Linker,
 	login login.Middleware,
+	oidc core.IdentityService,
 	repos core.RepositoryStore,
 	session core.Session,
 	syncer core.Syncer,
-	triggerer core.Triggerer,
 	users core.UserStore,
 	userz core.UserService,
 	webhook core.WebhookSender,
@@ -50,45 +51,47 @@ func New(
 	system *core.System,
 ) Server {
 	return Server{
-		Admitter:  admitter,
-		Builds:    builds,
-		Client:    client,
-		Hooks:     hooks,
-		License:   license,
-		Licenses:  licenses,
-		Linker:    linker,
-		Login:     login,
-		Repos:     repos,
-		Session:   session,
-		Syncer:    syncer,
-		Triggerer: triggerer,
-		Users:     users,
-		Userz:     userz,
-		Webhook:   webhook,
-		Options:   options,
-		Host:      system.Host,
+		Admitter:   admitter,
+		Client:     client,
+		Hooks:      hooks,
+		Identities: identities,
+		Journal:    journal,
+		License:    license,
+		Licenses:   licenses,
+		Linker:     linker,
+		Login:      login,
+		OIDC:       oidc,
+		Repos:      repos,
+		Session:    session,
+		Syncer:     syncer,
+		Users:      users,
+		Userz:      userz,
+		Webhook:    webhook,
+		Options:    options,
+		Host:       system.Host,
 	}
 }
 
 // Server is a http.Handler which exposes drone functionality over HTTP.
 type Server struct {
-	Admitter  core.AdmissionService
-	Builds    core.BuildStore
-	Client    *scm.Client
-	Hooks     core.HookParser
-	License   *core.License
-	Licenses  core.LicenseService
-	Linker    core.Linker
-	Login     login.Middleware
-	Repos     core.RepositoryStore
-	Session   core.Session
-	Syncer    core.Syncer
-	Triggerer core.Triggerer
-	Users     core.UserStore
-	Userz     core.UserService
-	Webhook   core.WebhookSender
-	Options   secure.Options
-	Host      string
+	Admitter   core.AdmissionService
+	Client     *scm.Client
+	Hooks      core.HookParser
+	Identities core.IdentityStore
+	Journal    core.JournalStore
+	License    *core.License
+	Licenses   core.LicenseService
+	Linker     core.Linker
+	Login      login.Middleware
+	OIDC       core.IdentityService
+	Repos      core.RepositoryStore
+	Session    core.Session
+	Syncer     core.Syncer
+	Users      core.UserStore
+	Userz      core.UserService
+	Webhook    core.WebhookSender
+	Options    secure.Options
+	Host       string
 }
 
 // Handler returns an http.Handler
@@ -103,7 +106,7 @@ func (s Server) Handler() http.Handler {
 	r.Use(sec.Handler)
 
 	r.Route("/hook", func(r chi.Router) {
-		r.Post("/", HandleHook(s.Repos, s.Builds, s.Triggerer, s.Hooks))
+		r.Post("/", HandleHook(s.Repos, s.Journal, s.Hooks))
 	})
 
 	r.Get("/link/{namespace}/{name}/tree/*", link.HandleTree(s.Linker))
@@ -126,6 +129,18 @@ func (s Server) Handler() http.Handler {
 			),
 		),
 	)
+	if s.OIDC != nil {
+		r.Get("/login/oidc",
+			HandleLoginOIDC(
+				s.Users,
+				s.Identities,
+				s.OIDC,
+				s.Session,
+				s.Admitter,
+				s.Webhook,
+			),
+		)
+	}
 	r.Get("/logout", HandleLogout())
 	r.Post("/logout", HandleLogout())
 
@@ -0,0 +1,96 @@
+// Copyright 2019 Drone.IO Inc. All rights reserved.
+// Use of this source code is governed by the Drone Non-Commercial License
+// that can be found in the LICENSE file.
+
+// +build !oss
+
+package metric
+
+import (
+	"github.com/drone/drone/core"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	stepDuration = prometheus.NewHistogram(prometheus.HistogramOpts{
+		Name:    "drone_step_duration_seconds",
+		Help:    "Step execution time in seconds.",
+		Buckets: prometheus.ExponentialBuckets(1, 2, 14),
+	})
+
+	stepCPU = prometheus.NewHistogram(prometheus.HistogramOpts{
+		Name:    "drone_step_cpu_seconds",
+		Help:    "Step cpu time in seconds reported by the runner.",
+		Buckets: prometheus.ExponentialBuckets(1, 2, 14),
+	})
+
+	stepMemory = prometheus.NewHistogram(prometheus.HistogramOpts{
+		Name:    "drone_step_memory_peak_bytes",
+		Help:    "Step peak memory usage in bytes reported by the runner.",
+		Buckets: prometheus.ExponentialBuckets(1<<24, 2, 10),
+	})
+
+	stepPull = prometheus.NewHistogram(prometheus.HistogramOpts{
+		Name:    "drone_step_pull_seconds",
+		Help:    "Step image pull time in seconds reported by the runner.",
+		Buckets: prometheus.ExponentialBuckets(0.5, 2, 12),
+	})
+
+	stepClone = prometheus.NewHistogram(prometheus.HistogramOpts{
+		Name:    "drone_step_clone_seconds",
+		Help:    "Step clone time in seconds reported by the runner.",
+		Buckets: prometheus.ExponentialBuckets(0.5, 2, 12),
+	})
+
+	// stepMetrics is true if the step histograms are
+	// registered.
+	stepMetrics bool
+)
+
+// StepMetrics provides histograms for step execution time and
+// the timing and resource metrics reported by runners. The
+// histograms are not labeled by step, since step names are
+// defined by users and their number is unbounded.
+func StepMetrics() {
+	prometheus.MustRegister(
+		stepDuration,
+		stepCPU,
+		stepMemory,
+		stepPull,
+		stepClone,
+	)
+	stepMetrics = true
+}
+
+// StepMetricsEnabled returns true if the step histograms are
+// registered, and completed steps should be observed.
+func StepMetricsEnabled() bool {
+	return stepMetrics
+}
+
+// ObserveStep records the execution time and the metrics
+// reported by the runner for the completed step.
+func ObserveStep(step *core.Step) {
+	if step.Started > 0 && step.Stopped >= step.Started {
+		stepDuration.Observe(
+			float64(step.Stopped - step.Started),
+		)
+	}
+	metrics := step.Metrics
+	if metrics == nil {
+		return
+	}
+	if metrics.CPUSeconds > 0 {
+		stepCPU.Observe(metrics.CPUSeconds)
+	}
+	if metrics.MemoryPeak > 0 {
+		stepMemory.Observe(float64(metrics.MemoryPeak))
+	}
+	if metrics.PullSeconds > 0 {
+		stepPull.Observe(metrics.PullSeconds)
+	}
+	if metrics.CloneSeconds > 0 {
+		stepClone.Observe(metrics.CloneSeconds)
+	}
+}
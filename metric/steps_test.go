@@ -0,0 +1,73 @@
+// Copyright 2019 Drone.IO Inc. All rights reserved.
+// Use of this source code is governed by the Drone Non-Commercial License
+// that can be found in the LICENSE file.
+
+// +build !oss
+
+package metric
+
+import (
+	"testing"
+
+	"github.com/drone/drone/core"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func TestStepMetrics(t *testing.T) {
+	// restore the default prometheus registerer
+	// when the unit test is complete.
+	snapshot := prometheus.DefaultRegisterer
+	defer func() {
+		prometheus.DefaultRegisterer = snapshot
+		stepMetrics = false
+	}()
+
+	// creates a blank registry
+	registry := prometheus.NewRegistry()
+	prometheus.DefaultRegisterer = registry
+
+	StepMetrics()
+	if !StepMetricsEnabled() {
+		t.Errorf("Want step metrics enabled once registered")
+	}
+	ObserveStep(&core.Step{
+		Name:    "test",
+		Started: 1522878684,
+		Stopped: 1522878690,
+		Metrics: &core.StepMetrics{
+			MemoryPeak:  1048576,
+			PullSeconds: 2.5,
+		},
+	})
+
+	metrics, err := registry.Gather()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	got := map[string]uint64{}
+	for _, metric := range metrics {
+		for _, m := range metric.Metric {
+			if len(m.Label) != 0 {
+				t.Errorf("Expect no labels for metric %s", metric.GetName())
+			}
+			got[metric.GetName()] += m.Histogram.GetSampleCount()
+		}
+	}
+	want := map[string]uint64{
+		"drone_step_duration_seconds":  1,
+		"drone_step_memory_peak_bytes": 1,
+		"drone_step_pull_seconds":      1,
+	}
+	for name, count := range want {
+		if got[name] != count {
+			t.Errorf("Expect %d samples for metric %s, got %d", count, name, got[name])
+		}
+	}
+	for _, name := range []string{"drone_step_cpu_seconds", "drone_step_clone_seconds"} {
+		if got[name] != 0 {
+			t.Errorf("Expect no samples for metric %s", name)
+		}
+	}
+}
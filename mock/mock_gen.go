@@ -465,6 +465,21 @@ func (m *MockCommitService) EXPECT() *MockCommitServiceMockRecorder {
 	return m.recorder
 }
 
+// CompareChanges mocks base method.
+func (m *MockCommitService) CompareChanges(arg0 context.Context, arg1 *core.User, arg2, arg3, arg4 string) ([]*core.Change, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CompareChanges", arg0, arg1, arg2, arg3, arg4)
+	ret0, _ := ret[0].([]*core.Change)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CompareChanges indicates an expected call of CompareChanges.
+func (mr *MockCommitServiceMockRecorder) CompareChanges(arg0, arg1, arg2, arg3, arg4 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CompareChanges", reflect.TypeOf((*MockCommitService)(nil).CompareChanges), arg0, arg1, arg2, arg3, arg4)
+}
+
 // Find mocks base method.
 func (m *MockCommitService) Find(arg0 context.Context, arg1 *core.User, arg2, arg3 string) (*core.Commit, error) {
 	m.ctrl.T.Helper()
@@ -510,6 +525,21 @@ func (mr *MockCommitServiceMockRecorder) ListChanges(arg0, arg1, arg2, arg3, arg
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListChanges", reflect.TypeOf((*MockCommitService)(nil).ListChanges), arg0, arg1, arg2, arg3, arg4)
 }
 
+// ListPullChanges mocks base method.
+func (m *MockCommitService) ListPullChanges(arg0 context.Context, arg1 *core.User, arg2 string, arg3 int) ([]*core.Change, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListPullChanges", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].([]*core.Change)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListPullChanges indicates an expected call of ListPullChanges.
+func (mr *MockCommitServiceMockRecorder) ListPullChanges(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListPullChanges", reflect.TypeOf((*MockCommitService)(nil).ListPullChanges), arg0, arg1, arg2, arg3)
+}
+
 // MockStatusService is a mock of StatusService interface.
 type MockStatusService struct {
 	ctrl     *gomock.Controller
@@ -781,6 +811,20 @@ func (mr *MockBuildStoreMockRecorder) DeletePull(arg0, arg1, arg2 interface{}) *
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeletePull", reflect.TypeOf((*MockBuildStore)(nil).DeletePull), arg0, arg1, arg2)
 }
 
+// Expire mocks base method.
+func (m *MockBuildStore) Expire(arg0 context.Context, arg1 int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Expire", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Expire indicates an expected call of Expire.
+func (mr *MockBuildStoreMockRecorder) Expire(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Expire", reflect.TypeOf((*MockBuildStore)(nil).Expire), arg0, arg1)
+}
+
 // Find mocks base method.
 func (m *MockBuildStore) Find(arg0 context.Context, arg1 int64) (*core.Build, error) {
 	m.ctrl.T.Helper()
@@ -886,6 +930,21 @@ func (mr *MockBuildStoreMockRecorder) List(arg0, arg1, arg2, arg3 interface{}) *
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockBuildStore)(nil).List), arg0, arg1, arg2, arg3)
 }
 
+// ListExpirable mocks base method.
+func (m *MockBuildStore) ListExpirable(arg0 context.Context, arg1 int64, arg2 core.ExpireParams) ([]*core.Build, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListExpirable", arg0, arg1, arg2)
+	ret0, _ := ret[0].([]*core.Build)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListExpirable indicates an expected call of ListExpirable.
+func (mr *MockBuildStoreMockRecorder) ListExpirable(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListExpirable", reflect.TypeOf((*MockBuildStore)(nil).ListExpirable), arg0, arg1, arg2)
+}
+
 // ListRef mocks base method.
 func (m *MockBuildStore) ListRef(arg0 context.Context, arg1 int64, arg2 string, arg3, arg4 int) ([]*core.Build, error) {
 	m.ctrl.T.Helper()
@@ -945,6 +1004,21 @@ func (mr *MockBuildStoreMockRecorder) Running(arg0 interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Running", reflect.TypeOf((*MockBuildStore)(nil).Running), arg0)
 }
 
+// Search mocks base method.
+func (m *MockBuildStore) Search(arg0 context.Context, arg1 int64, arg2 core.BuildParams) ([]*core.Build, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Search", arg0, arg1, arg2)
+	ret0, _ := ret[0].([]*core.Build)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Search indicates an expected call of Search.
+func (mr *MockBuildStoreMockRecorder) Search(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Search", reflect.TypeOf((*MockBuildStore)(nil).Search), arg0, arg1, arg2)
+}
+
 // Update mocks base method.
 func (m *MockBuildStore) Update(arg0 context.Context, arg1 *core.Build) error {
 	m.ctrl.T.Helper()
@@ -1547,6 +1621,20 @@ func (mr *MockStageStoreMockRecorder) FindNumber(arg0, arg1, arg2 interface{}) *
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindNumber", reflect.TypeOf((*MockStageStore)(nil).FindNumber), arg0, arg1, arg2)
 }
 
+// Heartbeat mocks base method.
+func (m *MockStageStore) Heartbeat(arg0 context.Context, arg1 *core.Stage) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Heartbeat", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Heartbeat indicates an expected call of Heartbeat.
+func (mr *MockStageStoreMockRecorder) Heartbeat(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Heartbeat", reflect.TypeOf((*MockStageStore)(nil).Heartbeat), arg0, arg1)
+}
+
 // List mocks base method.
 func (m *MockStageStore) List(arg0 context.Context, arg1 int64) ([]*core.Stage, error) {
 	m.ctrl.T.Helper()
@@ -1658,6 +1746,20 @@ func (mr *MockStepStoreMockRecorder) Create(arg0, arg1 interface{}) *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockStepStore)(nil).Create), arg0, arg1)
 }
 
+// DeleteStage mocks base method.
+func (m *MockStepStore) DeleteStage(arg0 context.Context, arg1 int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteStage", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteStage indicates an expected call of DeleteStage.
+func (mr *MockStepStoreMockRecorder) DeleteStage(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteStage", reflect.TypeOf((*MockStepStore)(nil).DeleteStage), arg0, arg1)
+}
+
 // Find mocks base method.
 func (m *MockStepStore) Find(arg0 context.Context, arg1 int64) (*core.Step, error) {
 	m.ctrl.T.Helper()
@@ -2042,6 +2144,21 @@ func (mr *MockUserStoreMockRecorder) Find(arg0, arg1 interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Find", reflect.TypeOf((*MockUserStore)(nil).Find), arg0, arg1)
 }
 
+// FindEmail mocks base method.
+func (m *MockUserStore) FindEmail(arg0 context.Context, arg1 string) (*core.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindEmail", arg0, arg1)
+	ret0, _ := ret[0].(*core.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindEmail indicates an expected call of FindEmail.
+func (mr *MockUserStoreMockRecorder) FindEmail(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindEmail", reflect.TypeOf((*MockUserStore)(nil).FindEmail), arg0, arg1)
+}
+
 // FindLogin mocks base method.
 func (m *MockUserStore) FindLogin(arg0 context.Context, arg1 string) (*core.User, error) {
 	m.ctrl.T.Helper()
@@ -2534,6 +2651,21 @@ func (m *MockTriggerer) EXPECT() *MockTriggererMockRecorder {
 	return m.recorder
 }
 
+// Evaluate mocks base method.
+func (m *MockTriggerer) Evaluate(arg0 context.Context, arg1 *core.Repository, arg2 *core.Hook, arg3 *core.Config) (*core.Evaluation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Evaluate", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(*core.Evaluation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Evaluate indicates an expected call of Evaluate.
+func (mr *MockTriggererMockRecorder) Evaluate(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Evaluate", reflect.TypeOf((*MockTriggerer)(nil).Evaluate), arg0, arg1, arg2, arg3)
+}
+
 // Trigger mocks base method.
 func (m *MockTriggerer) Trigger(arg0 context.Context, arg1 *core.Repository, arg2 *core.Hook) (*core.Build, error) {
 	m.ctrl.T.Helper()
@@ -2974,3 +3106,1428 @@ func (mr *MockCardStoreMockRecorder) Update(arg0, arg1, arg2 interface{}) *gomoc
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockCardStore)(nil).Update), arg0, arg1, arg2)
 }
+
+// MockNodeStore is a mock of NodeStore interface.
+type MockNodeStore struct {
+	ctrl     *gomock.Controller
+	recorder *MockNodeStoreMockRecorder
+}
+
+// MockNodeStoreMockRecorder is the mock recorder for MockNodeStore.
+type MockNodeStoreMockRecorder struct {
+	mock *MockNodeStore
+}
+
+// NewMockNodeStore creates a new mock instance.
+func NewMockNodeStore(ctrl *gomock.Controller) *MockNodeStore {
+	mock := &MockNodeStore{ctrl: ctrl}
+	mock.recorder = &MockNodeStoreMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockNodeStore) EXPECT() *MockNodeStoreMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method.
+func (m *MockNodeStore) Create(arg0 context.Context, arg1 *core.Node) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockNodeStoreMockRecorder) Create(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockNodeStore)(nil).Create), arg0, arg1)
+}
+
+// Delete mocks base method.
+func (m *MockNodeStore) Delete(arg0 context.Context, arg1 *core.Node) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockNodeStoreMockRecorder) Delete(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockNodeStore)(nil).Delete), arg0, arg1)
+}
+
+// Find mocks base method.
+func (m *MockNodeStore) Find(arg0 context.Context, arg1 int64) (*core.Node, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Find", arg0, arg1)
+	ret0, _ := ret[0].(*core.Node)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Find indicates an expected call of Find.
+func (mr *MockNodeStoreMockRecorder) Find(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Find", reflect.TypeOf((*MockNodeStore)(nil).Find), arg0, arg1)
+}
+
+// FindName mocks base method.
+func (m *MockNodeStore) FindName(arg0 context.Context, arg1 string) (*core.Node, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindName", arg0, arg1)
+	ret0, _ := ret[0].(*core.Node)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindName indicates an expected call of FindName.
+func (mr *MockNodeStoreMockRecorder) FindName(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindName", reflect.TypeOf((*MockNodeStore)(nil).FindName), arg0, arg1)
+}
+
+// List mocks base method.
+func (m *MockNodeStore) List(arg0 context.Context) ([]*core.Node, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List", arg0)
+	ret0, _ := ret[0].([]*core.Node)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// List indicates an expected call of List.
+func (mr *MockNodeStoreMockRecorder) List(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockNodeStore)(nil).List), arg0)
+}
+
+// Update mocks base method.
+func (m *MockNodeStore) Update(arg0 context.Context, arg1 *core.Node) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Update", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Update indicates an expected call of Update.
+func (mr *MockNodeStoreMockRecorder) Update(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockNodeStore)(nil).Update), arg0, arg1)
+}
+
+// MockDeliveryStore is a mock of DeliveryStore interface.
+type MockDeliveryStore struct {
+	ctrl     *gomock.Controller
+	recorder *MockDeliveryStoreMockRecorder
+}
+
+// MockDeliveryStoreMockRecorder is the mock recorder for MockDeliveryStore.
+type MockDeliveryStoreMockRecorder struct {
+	mock *MockDeliveryStore
+}
+
+// NewMockDeliveryStore creates a new mock instance.
+func NewMockDeliveryStore(ctrl *gomock.Controller) *MockDeliveryStore {
+	mock := &MockDeliveryStore{ctrl: ctrl}
+	mock.recorder = &MockDeliveryStoreMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockDeliveryStore) EXPECT() *MockDeliveryStoreMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method.
+func (m *MockDeliveryStore) Create(arg0 context.Context, arg1 *core.Delivery) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockDeliveryStoreMockRecorder) Create(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockDeliveryStore)(nil).Create), arg0, arg1)
+}
+
+// CreateAttempt mocks base method.
+func (m *MockDeliveryStore) CreateAttempt(arg0 context.Context, arg1 *core.DeliveryAttempt) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateAttempt", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateAttempt indicates an expected call of CreateAttempt.
+func (mr *MockDeliveryStoreMockRecorder) CreateAttempt(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateAttempt", reflect.TypeOf((*MockDeliveryStore)(nil).CreateAttempt), arg0, arg1)
+}
+
+// Find mocks base method.
+func (m *MockDeliveryStore) Find(arg0 context.Context, arg1 int64) (*core.Delivery, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Find", arg0, arg1)
+	ret0, _ := ret[0].(*core.Delivery)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Find indicates an expected call of Find.
+func (mr *MockDeliveryStoreMockRecorder) Find(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Find", reflect.TypeOf((*MockDeliveryStore)(nil).Find), arg0, arg1)
+}
+
+// List mocks base method.
+func (m *MockDeliveryStore) List(arg0 context.Context, arg1, arg2 int) ([]*core.Delivery, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List", arg0, arg1, arg2)
+	ret0, _ := ret[0].([]*core.Delivery)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// List indicates an expected call of List.
+func (mr *MockDeliveryStoreMockRecorder) List(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockDeliveryStore)(nil).List), arg0, arg1, arg2)
+}
+
+// ListAttempts mocks base method.
+func (m *MockDeliveryStore) ListAttempts(arg0 context.Context, arg1 int64) ([]*core.DeliveryAttempt, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListAttempts", arg0, arg1)
+	ret0, _ := ret[0].([]*core.DeliveryAttempt)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListAttempts indicates an expected call of ListAttempts.
+func (mr *MockDeliveryStoreMockRecorder) ListAttempts(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAttempts", reflect.TypeOf((*MockDeliveryStore)(nil).ListAttempts), arg0, arg1)
+}
+
+// ListPending mocks base method.
+func (m *MockDeliveryStore) ListPending(arg0 context.Context, arg1 int64) ([]*core.Delivery, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListPending", arg0, arg1)
+	ret0, _ := ret[0].([]*core.Delivery)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListPending indicates an expected call of ListPending.
+func (mr *MockDeliveryStoreMockRecorder) ListPending(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListPending", reflect.TypeOf((*MockDeliveryStore)(nil).ListPending), arg0, arg1)
+}
+
+// Purge mocks base method.
+func (m *MockDeliveryStore) Purge(arg0 context.Context, arg1 int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Purge", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Purge indicates an expected call of Purge.
+func (mr *MockDeliveryStoreMockRecorder) Purge(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Purge", reflect.TypeOf((*MockDeliveryStore)(nil).Purge), arg0, arg1)
+}
+
+// Update mocks base method.
+func (m *MockDeliveryStore) Update(arg0 context.Context, arg1 *core.Delivery) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Update", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Update indicates an expected call of Update.
+func (mr *MockDeliveryStoreMockRecorder) Update(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockDeliveryStore)(nil).Update), arg0, arg1)
+}
+
+// MockSubscriptionStore is a mock of SubscriptionStore interface.
+type MockSubscriptionStore struct {
+	ctrl     *gomock.Controller
+	recorder *MockSubscriptionStoreMockRecorder
+}
+
+// MockSubscriptionStoreMockRecorder is the mock recorder for MockSubscriptionStore.
+type MockSubscriptionStoreMockRecorder struct {
+	mock *MockSubscriptionStore
+}
+
+// NewMockSubscriptionStore creates a new mock instance.
+func NewMockSubscriptionStore(ctrl *gomock.Controller) *MockSubscriptionStore {
+	mock := &MockSubscriptionStore{ctrl: ctrl}
+	mock.recorder = &MockSubscriptionStoreMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockSubscriptionStore) EXPECT() *MockSubscriptionStoreMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method.
+func (m *MockSubscriptionStore) Create(arg0 context.Context, arg1 *core.Subscription) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockSubscriptionStoreMockRecorder) Create(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockSubscriptionStore)(nil).Create), arg0, arg1)
+}
+
+// Delete mocks base method.
+func (m *MockSubscriptionStore) Delete(arg0 context.Context, arg1 *core.Subscription) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockSubscriptionStoreMockRecorder) Delete(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockSubscriptionStore)(nil).Delete), arg0, arg1)
+}
+
+// Find mocks base method.
+func (m *MockSubscriptionStore) Find(arg0 context.Context, arg1 int64) (*core.Subscription, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Find", arg0, arg1)
+	ret0, _ := ret[0].(*core.Subscription)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Find indicates an expected call of Find.
+func (mr *MockSubscriptionStoreMockRecorder) Find(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Find", reflect.TypeOf((*MockSubscriptionStore)(nil).Find), arg0, arg1)
+}
+
+// List mocks base method.
+func (m *MockSubscriptionStore) List(arg0 context.Context, arg1 int64) ([]*core.Subscription, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List", arg0, arg1)
+	ret0, _ := ret[0].([]*core.Subscription)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// List indicates an expected call of List.
+func (mr *MockSubscriptionStoreMockRecorder) List(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockSubscriptionStore)(nil).List), arg0, arg1)
+}
+
+// Update mocks base method.
+func (m *MockSubscriptionStore) Update(arg0 context.Context, arg1 *core.Subscription) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Update", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Update indicates an expected call of Update.
+func (mr *MockSubscriptionStoreMockRecorder) Update(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockSubscriptionStore)(nil).Update), arg0, arg1)
+}
+
+// MockChannelStore is a mock of ChannelStore interface.
+type MockChannelStore struct {
+	ctrl     *gomock.Controller
+	recorder *MockChannelStoreMockRecorder
+}
+
+// MockChannelStoreMockRecorder is the mock recorder for MockChannelStore.
+type MockChannelStoreMockRecorder struct {
+	mock *MockChannelStore
+}
+
+// NewMockChannelStore creates a new mock instance.
+func NewMockChannelStore(ctrl *gomock.Controller) *MockChannelStore {
+	mock := &MockChannelStore{ctrl: ctrl}
+	mock.recorder = &MockChannelStoreMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockChannelStore) EXPECT() *MockChannelStoreMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method.
+func (m *MockChannelStore) Create(arg0 context.Context, arg1 *core.Channel) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockChannelStoreMockRecorder) Create(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockChannelStore)(nil).Create), arg0, arg1)
+}
+
+// Delete mocks base method.
+func (m *MockChannelStore) Delete(arg0 context.Context, arg1 *core.Channel) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockChannelStoreMockRecorder) Delete(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockChannelStore)(nil).Delete), arg0, arg1)
+}
+
+// Find mocks base method.
+func (m *MockChannelStore) Find(arg0 context.Context, arg1 int64) (*core.Channel, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Find", arg0, arg1)
+	ret0, _ := ret[0].(*core.Channel)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Find indicates an expected call of Find.
+func (mr *MockChannelStoreMockRecorder) Find(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Find", reflect.TypeOf((*MockChannelStore)(nil).Find), arg0, arg1)
+}
+
+// List mocks base method.
+func (m *MockChannelStore) List(arg0 context.Context, arg1 int64) ([]*core.Channel, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List", arg0, arg1)
+	ret0, _ := ret[0].([]*core.Channel)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// List indicates an expected call of List.
+func (mr *MockChannelStoreMockRecorder) List(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockChannelStore)(nil).List), arg0, arg1)
+}
+
+// ListNamespace mocks base method.
+func (m *MockChannelStore) ListNamespace(arg0 context.Context, arg1 string) ([]*core.Channel, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListNamespace", arg0, arg1)
+	ret0, _ := ret[0].([]*core.Channel)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListNamespace indicates an expected call of ListNamespace.
+func (mr *MockChannelStoreMockRecorder) ListNamespace(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListNamespace", reflect.TypeOf((*MockChannelStore)(nil).ListNamespace), arg0, arg1)
+}
+
+// Update mocks base method.
+func (m *MockChannelStore) Update(arg0 context.Context, arg1 *core.Channel) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Update", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Update indicates an expected call of Update.
+func (mr *MockChannelStoreMockRecorder) Update(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockChannelStore)(nil).Update), arg0, arg1)
+}
+
+// MockLogIndex is a mock of LogIndex interface.
+type MockLogIndex struct {
+	ctrl     *gomock.Controller
+	recorder *MockLogIndexMockRecorder
+}
+
+// MockLogIndexMockRecorder is the mock recorder for MockLogIndex.
+type MockLogIndexMockRecorder struct {
+	mock *MockLogIndex
+}
+
+// NewMockLogIndex creates a new mock instance.
+func NewMockLogIndex(ctrl *gomock.Controller) *MockLogIndex {
+	mock := &MockLogIndex{ctrl: ctrl}
+	mock.recorder = &MockLogIndexMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockLogIndex) EXPECT() *MockLogIndexMockRecorder {
+	return m.recorder
+}
+
+// Delete mocks base method.
+func (m *MockLogIndex) Delete(arg0 context.Context, arg1 int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockLogIndexMockRecorder) Delete(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockLogIndex)(nil).Delete), arg0, arg1)
+}
+
+// Index mocks base method.
+func (m *MockLogIndex) Index(arg0 context.Context, arg1 int64, arg2 []byte) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Index", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Index indicates an expected call of Index.
+func (mr *MockLogIndexMockRecorder) Index(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Index", reflect.TypeOf((*MockLogIndex)(nil).Index), arg0, arg1, arg2)
+}
+
+// Purge mocks base method.
+func (m *MockLogIndex) Purge(arg0 context.Context, arg1, arg2 int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Purge", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Purge indicates an expected call of Purge.
+func (mr *MockLogIndexMockRecorder) Purge(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Purge", reflect.TypeOf((*MockLogIndex)(nil).Purge), arg0, arg1, arg2)
+}
+
+// Search mocks base method.
+func (m *MockLogIndex) Search(arg0 context.Context, arg1 *core.LogQuery) ([]*core.LogHit, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Search", arg0, arg1)
+	ret0, _ := ret[0].([]*core.LogHit)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Search indicates an expected call of Search.
+func (mr *MockLogIndexMockRecorder) Search(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Search", reflect.TypeOf((*MockLogIndex)(nil).Search), arg0, arg1)
+}
+
+// MockRestarter is a mock of Restarter interface.
+type MockRestarter struct {
+	ctrl     *gomock.Controller
+	recorder *MockRestarterMockRecorder
+}
+
+// MockRestarterMockRecorder is the mock recorder for MockRestarter.
+type MockRestarterMockRecorder struct {
+	mock *MockRestarter
+}
+
+// NewMockRestarter creates a new mock instance.
+func NewMockRestarter(ctrl *gomock.Controller) *MockRestarter {
+	mock := &MockRestarter{ctrl: ctrl}
+	mock.recorder = &MockRestarterMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockRestarter) EXPECT() *MockRestarterMockRecorder {
+	return m.recorder
+}
+
+// Restart mocks base method.
+func (m *MockRestarter) Restart(arg0 context.Context, arg1 *core.Repository, arg2 *core.Build, arg3 []int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Restart", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Restart indicates an expected call of Restart.
+func (mr *MockRestarterMockRecorder) Restart(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Restart", reflect.TypeOf((*MockRestarter)(nil).Restart), arg0, arg1, arg2, arg3)
+}
+
+// MockApprovalStore is a mock of ApprovalStore interface.
+type MockApprovalStore struct {
+	ctrl     *gomock.Controller
+	recorder *MockApprovalStoreMockRecorder
+}
+
+// MockApprovalStoreMockRecorder is the mock recorder for MockApprovalStore.
+type MockApprovalStoreMockRecorder struct {
+	mock *MockApprovalStore
+}
+
+// NewMockApprovalStore creates a new mock instance.
+func NewMockApprovalStore(ctrl *gomock.Controller) *MockApprovalStore {
+	mock := &MockApprovalStore{ctrl: ctrl}
+	mock.recorder = &MockApprovalStoreMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockApprovalStore) EXPECT() *MockApprovalStoreMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method.
+func (m *MockApprovalStore) Create(arg0 context.Context, arg1 *core.Approval) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockApprovalStoreMockRecorder) Create(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockApprovalStore)(nil).Create), arg0, arg1)
+}
+
+// List mocks base method.
+func (m *MockApprovalStore) List(arg0 context.Context, arg1 int64) ([]*core.Approval, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List", arg0, arg1)
+	ret0, _ := ret[0].([]*core.Approval)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// List indicates an expected call of List.
+func (mr *MockApprovalStoreMockRecorder) List(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockApprovalStore)(nil).List), arg0, arg1)
+}
+
+// Purge mocks base method.
+func (m *MockApprovalStore) Purge(arg0 context.Context, arg1, arg2 int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Purge", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Purge indicates an expected call of Purge.
+func (mr *MockApprovalStoreMockRecorder) Purge(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Purge", reflect.TypeOf((*MockApprovalStore)(nil).Purge), arg0, arg1, arg2)
+}
+
+// MockEnvironmentStore is a mock of EnvironmentStore interface.
+type MockEnvironmentStore struct {
+	ctrl     *gomock.Controller
+	recorder *MockEnvironmentStoreMockRecorder
+}
+
+// MockEnvironmentStoreMockRecorder is the mock recorder for MockEnvironmentStore.
+type MockEnvironmentStoreMockRecorder struct {
+	mock *MockEnvironmentStore
+}
+
+// NewMockEnvironmentStore creates a new mock instance.
+func NewMockEnvironmentStore(ctrl *gomock.Controller) *MockEnvironmentStore {
+	mock := &MockEnvironmentStore{ctrl: ctrl}
+	mock.recorder = &MockEnvironmentStoreMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockEnvironmentStore) EXPECT() *MockEnvironmentStoreMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method.
+func (m *MockEnvironmentStore) Create(arg0 context.Context, arg1 *core.Environment) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockEnvironmentStoreMockRecorder) Create(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockEnvironmentStore)(nil).Create), arg0, arg1)
+}
+
+// Delete mocks base method.
+func (m *MockEnvironmentStore) Delete(arg0 context.Context, arg1 *core.Environment) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockEnvironmentStoreMockRecorder) Delete(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockEnvironmentStore)(nil).Delete), arg0, arg1)
+}
+
+// FindName mocks base method.
+func (m *MockEnvironmentStore) FindName(arg0 context.Context, arg1 int64, arg2 string) (*core.Environment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindName", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*core.Environment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindName indicates an expected call of FindName.
+func (mr *MockEnvironmentStoreMockRecorder) FindName(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindName", reflect.TypeOf((*MockEnvironmentStore)(nil).FindName), arg0, arg1, arg2)
+}
+
+// List mocks base method.
+func (m *MockEnvironmentStore) List(arg0 context.Context, arg1 int64) ([]*core.Environment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List", arg0, arg1)
+	ret0, _ := ret[0].([]*core.Environment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// List indicates an expected call of List.
+func (mr *MockEnvironmentStoreMockRecorder) List(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockEnvironmentStore)(nil).List), arg0, arg1)
+}
+
+// Lock mocks base method.
+func (m *MockEnvironmentStore) Lock(arg0 context.Context, arg1 *core.Environment, arg2 int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Lock", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Lock indicates an expected call of Lock.
+func (mr *MockEnvironmentStoreMockRecorder) Lock(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Lock", reflect.TypeOf((*MockEnvironmentStore)(nil).Lock), arg0, arg1, arg2)
+}
+
+// Unlock mocks base method.
+func (m *MockEnvironmentStore) Unlock(arg0 context.Context, arg1 *core.Environment) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Unlock", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Unlock indicates an expected call of Unlock.
+func (mr *MockEnvironmentStoreMockRecorder) Unlock(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Unlock", reflect.TypeOf((*MockEnvironmentStore)(nil).Unlock), arg0, arg1)
+}
+
+// Update mocks base method.
+func (m *MockEnvironmentStore) Update(arg0 context.Context, arg1 *core.Environment) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Update", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Update indicates an expected call of Update.
+func (mr *MockEnvironmentStoreMockRecorder) Update(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockEnvironmentStore)(nil).Update), arg0, arg1)
+}
+
+// MockArtifactStore is a mock of ArtifactStore interface.
+type MockArtifactStore struct {
+	ctrl     *gomock.Controller
+	recorder *MockArtifactStoreMockRecorder
+}
+
+// MockArtifactStoreMockRecorder is the mock recorder for MockArtifactStore.
+type MockArtifactStoreMockRecorder struct {
+	mock *MockArtifactStore
+}
+
+// NewMockArtifactStore creates a new mock instance.
+func NewMockArtifactStore(ctrl *gomock.Controller) *MockArtifactStore {
+	mock := &MockArtifactStore{ctrl: ctrl}
+	mock.recorder = &MockArtifactStoreMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockArtifactStore) EXPECT() *MockArtifactStoreMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method.
+func (m *MockArtifactStore) Create(arg0 context.Context, arg1 *core.Artifact, arg2 io.Reader) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockArtifactStoreMockRecorder) Create(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockArtifactStore)(nil).Create), arg0, arg1, arg2)
+}
+
+// FindName mocks base method.
+func (m *MockArtifactStore) FindName(arg0 context.Context, arg1 int64, arg2 string) (*core.Artifact, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindName", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*core.Artifact)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindName indicates an expected call of FindName.
+func (mr *MockArtifactStoreMockRecorder) FindName(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindName", reflect.TypeOf((*MockArtifactStore)(nil).FindName), arg0, arg1, arg2)
+}
+
+// List mocks base method.
+func (m *MockArtifactStore) List(arg0 context.Context, arg1 int64) ([]*core.Artifact, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List", arg0, arg1)
+	ret0, _ := ret[0].([]*core.Artifact)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// List indicates an expected call of List.
+func (mr *MockArtifactStoreMockRecorder) List(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockArtifactStore)(nil).List), arg0, arg1)
+}
+
+// Open mocks base method.
+func (m *MockArtifactStore) Open(arg0 context.Context, arg1 *core.Artifact) (io.ReadCloser, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Open", arg0, arg1)
+	ret0, _ := ret[0].(io.ReadCloser)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Open indicates an expected call of Open.
+func (mr *MockArtifactStoreMockRecorder) Open(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Open", reflect.TypeOf((*MockArtifactStore)(nil).Open), arg0, arg1)
+}
+
+// Purge mocks base method.
+func (m *MockArtifactStore) Purge(arg0 context.Context, arg1, arg2 int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Purge", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Purge indicates an expected call of Purge.
+func (mr *MockArtifactStoreMockRecorder) Purge(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Purge", reflect.TypeOf((*MockArtifactStore)(nil).Purge), arg0, arg1, arg2)
+}
+
+// Usage mocks base method.
+func (m *MockArtifactStore) Usage(arg0 context.Context, arg1 int64) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Usage", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Usage indicates an expected call of Usage.
+func (mr *MockArtifactStoreMockRecorder) Usage(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Usage", reflect.TypeOf((*MockArtifactStore)(nil).Usage), arg0, arg1)
+}
+
+// MockTestStore is a mock of TestStore interface.
+type MockTestStore struct {
+	ctrl     *gomock.Controller
+	recorder *MockTestStoreMockRecorder
+}
+
+// MockTestStoreMockRecorder is the mock recorder for MockTestStore.
+type MockTestStoreMockRecorder struct {
+	mock *MockTestStore
+}
+
+// NewMockTestStore creates a new mock instance.
+func NewMockTestStore(ctrl *gomock.Controller) *MockTestStore {
+	mock := &MockTestStore{ctrl: ctrl}
+	mock.recorder = &MockTestStoreMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockTestStore) EXPECT() *MockTestStoreMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method.
+func (m *MockTestStore) Create(arg0 context.Context, arg1 []*core.TestResult) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockTestStoreMockRecorder) Create(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockTestStore)(nil).Create), arg0, arg1)
+}
+
+// DeleteStage mocks base method.
+func (m *MockTestStore) DeleteStage(arg0 context.Context, arg1, arg2 int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteStage", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteStage indicates an expected call of DeleteStage.
+func (mr *MockTestStoreMockRecorder) DeleteStage(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteStage", reflect.TypeOf((*MockTestStore)(nil).DeleteStage), arg0, arg1, arg2)
+}
+
+// History mocks base method.
+func (m *MockTestStore) History(arg0 context.Context, arg1 int64, arg2 string, arg3 int) ([]*core.TestResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "History", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].([]*core.TestResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// History indicates an expected call of History.
+func (mr *MockTestStoreMockRecorder) History(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "History", reflect.TypeOf((*MockTestStore)(nil).History), arg0, arg1, arg2, arg3)
+}
+
+// List mocks base method.
+func (m *MockTestStore) List(arg0 context.Context, arg1 int64) ([]*core.TestResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List", arg0, arg1)
+	ret0, _ := ret[0].([]*core.TestResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// List indicates an expected call of List.
+func (mr *MockTestStoreMockRecorder) List(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockTestStore)(nil).List), arg0, arg1)
+}
+
+// Summary mocks base method.
+func (m *MockTestStore) Summary(arg0 context.Context, arg1 int64) (*core.TestSummary, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Summary", arg0, arg1)
+	ret0, _ := ret[0].(*core.TestSummary)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Summary indicates an expected call of Summary.
+func (mr *MockTestStoreMockRecorder) Summary(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Summary", reflect.TypeOf((*MockTestStore)(nil).Summary), arg0, arg1)
+}
+
+// MockJournalStore is a mock of JournalStore interface.
+type MockJournalStore struct {
+	ctrl     *gomock.Controller
+	recorder *MockJournalStoreMockRecorder
+}
+
+// MockJournalStoreMockRecorder is the mock recorder for MockJournalStore.
+type MockJournalStoreMockRecorder struct {
+	mock *MockJournalStore
+}
+
+// NewMockJournalStore creates a new mock instance.
+func NewMockJournalStore(ctrl *gomock.Controller) *MockJournalStore {
+	mock := &MockJournalStore{ctrl: ctrl}
+	mock.recorder = &MockJournalStoreMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockJournalStore) EXPECT() *MockJournalStoreMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method.
+func (m *MockJournalStore) Create(arg0 context.Context, arg1 *core.JournalEntry) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockJournalStoreMockRecorder) Create(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockJournalStore)(nil).Create), arg0, arg1)
+}
+
+// Find mocks base method.
+func (m *MockJournalStore) Find(arg0 context.Context, arg1 int64) (*core.JournalEntry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Find", arg0, arg1)
+	ret0, _ := ret[0].(*core.JournalEntry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Find indicates an expected call of Find.
+func (mr *MockJournalStoreMockRecorder) Find(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Find", reflect.TypeOf((*MockJournalStore)(nil).Find), arg0, arg1)
+}
+
+// FindDelivery mocks base method.
+func (m *MockJournalStore) FindDelivery(arg0 context.Context, arg1 string) (*core.JournalEntry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindDelivery", arg0, arg1)
+	ret0, _ := ret[0].(*core.JournalEntry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindDelivery indicates an expected call of FindDelivery.
+func (mr *MockJournalStoreMockRecorder) FindDelivery(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindDelivery", reflect.TypeOf((*MockJournalStore)(nil).FindDelivery), arg0, arg1)
+}
+
+// List mocks base method.
+func (m *MockJournalStore) List(arg0 context.Context, arg1, arg2 int) ([]*core.JournalEntry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List", arg0, arg1, arg2)
+	ret0, _ := ret[0].([]*core.JournalEntry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// List indicates an expected call of List.
+func (mr *MockJournalStoreMockRecorder) List(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockJournalStore)(nil).List), arg0, arg1, arg2)
+}
+
+// ListPending mocks base method.
+func (m *MockJournalStore) ListPending(arg0 context.Context, arg1 int64) ([]*core.JournalEntry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListPending", arg0, arg1)
+	ret0, _ := ret[0].([]*core.JournalEntry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListPending indicates an expected call of ListPending.
+func (mr *MockJournalStoreMockRecorder) ListPending(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListPending", reflect.TypeOf((*MockJournalStore)(nil).ListPending), arg0, arg1)
+}
+
+// ListRepo mocks base method.
+func (m *MockJournalStore) ListRepo(arg0 context.Context, arg1 int64, arg2, arg3 int) ([]*core.JournalEntry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListRepo", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].([]*core.JournalEntry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListRepo indicates an expected call of ListRepo.
+func (mr *MockJournalStoreMockRecorder) ListRepo(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListRepo", reflect.TypeOf((*MockJournalStore)(nil).ListRepo), arg0, arg1, arg2, arg3)
+}
+
+// Purge mocks base method.
+func (m *MockJournalStore) Purge(arg0 context.Context, arg1 int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Purge", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Purge indicates an expected call of Purge.
+func (mr *MockJournalStoreMockRecorder) Purge(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Purge", reflect.TypeOf((*MockJournalStore)(nil).Purge), arg0, arg1)
+}
+
+// Update mocks base method.
+func (m *MockJournalStore) Update(arg0 context.Context, arg1 *core.JournalEntry) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Update", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Update indicates an expected call of Update.
+func (mr *MockJournalStoreMockRecorder) Update(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockJournalStore)(nil).Update), arg0, arg1)
+}
+
+// MockCommandService is a mock of CommandService interface.
+type MockCommandService struct {
+	ctrl     *gomock.Controller
+	recorder *MockCommandServiceMockRecorder
+}
+
+// MockCommandServiceMockRecorder is the mock recorder for MockCommandService.
+type MockCommandServiceMockRecorder struct {
+	mock *MockCommandService
+}
+
+// NewMockCommandService creates a new mock instance.
+func NewMockCommandService(ctrl *gomock.Controller) *MockCommandService {
+	mock := &MockCommandService{ctrl: ctrl}
+	mock.recorder = &MockCommandServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockCommandService) EXPECT() *MockCommandServiceMockRecorder {
+	return m.recorder
+}
+
+// Exec mocks base method.
+func (m *MockCommandService) Exec(arg0 context.Context, arg1 *core.Repository, arg2 *core.Hook) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Exec", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Exec indicates an expected call of Exec.
+func (mr *MockCommandServiceMockRecorder) Exec(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Exec", reflect.TypeOf((*MockCommandService)(nil).Exec), arg0, arg1, arg2)
+}
+
+// MockAccessTokenStore is a mock of AccessTokenStore interface.
+type MockAccessTokenStore struct {
+	ctrl     *gomock.Controller
+	recorder *MockAccessTokenStoreMockRecorder
+}
+
+// MockAccessTokenStoreMockRecorder is the mock recorder for MockAccessTokenStore.
+type MockAccessTokenStoreMockRecorder struct {
+	mock *MockAccessTokenStore
+}
+
+// NewMockAccessTokenStore creates a new mock instance.
+func NewMockAccessTokenStore(ctrl *gomock.Controller) *MockAccessTokenStore {
+	mock := &MockAccessTokenStore{ctrl: ctrl}
+	mock.recorder = &MockAccessTokenStoreMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAccessTokenStore) EXPECT() *MockAccessTokenStoreMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method.
+func (m *MockAccessTokenStore) Create(arg0 context.Context, arg1 *core.AccessToken) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockAccessTokenStoreMockRecorder) Create(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockAccessTokenStore)(nil).Create), arg0, arg1)
+}
+
+// Delete mocks base method.
+func (m *MockAccessTokenStore) Delete(arg0 context.Context, arg1 *core.AccessToken) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockAccessTokenStoreMockRecorder) Delete(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockAccessTokenStore)(nil).Delete), arg0, arg1)
+}
+
+// Find mocks base method.
+func (m *MockAccessTokenStore) Find(arg0 context.Context, arg1 int64) (*core.AccessToken, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Find", arg0, arg1)
+	ret0, _ := ret[0].(*core.AccessToken)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Find indicates an expected call of Find.
+func (mr *MockAccessTokenStoreMockRecorder) Find(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Find", reflect.TypeOf((*MockAccessTokenStore)(nil).Find), arg0, arg1)
+}
+
+// FindHash mocks base method.
+func (m *MockAccessTokenStore) FindHash(arg0 context.Context, arg1 string) (*core.AccessToken, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindHash", arg0, arg1)
+	ret0, _ := ret[0].(*core.AccessToken)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindHash indicates an expected call of FindHash.
+func (mr *MockAccessTokenStoreMockRecorder) FindHash(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindHash", reflect.TypeOf((*MockAccessTokenStore)(nil).FindHash), arg0, arg1)
+}
+
+// List mocks base method.
+func (m *MockAccessTokenStore) List(arg0 context.Context, arg1 int64) ([]*core.AccessToken, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List", arg0, arg1)
+	ret0, _ := ret[0].([]*core.AccessToken)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// List indicates an expected call of List.
+func (mr *MockAccessTokenStoreMockRecorder) List(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockAccessTokenStore)(nil).List), arg0, arg1)
+}
+
+// Touch mocks base method.
+func (m *MockAccessTokenStore) Touch(arg0 context.Context, arg1, arg2 int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Touch", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Touch indicates an expected call of Touch.
+func (mr *MockAccessTokenStoreMockRecorder) Touch(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Touch", reflect.TypeOf((*MockAccessTokenStore)(nil).Touch), arg0, arg1, arg2)
+}
+
+// MockIdentityStore is a mock of IdentityStore interface.
+type MockIdentityStore struct {
+	ctrl     *gomock.Controller
+	recorder *MockIdentityStoreMockRecorder
+}
+
+// MockIdentityStoreMockRecorder is the mock recorder for MockIdentityStore.
+type MockIdentityStoreMockRecorder struct {
+	mock *MockIdentityStore
+}
+
+// NewMockIdentityStore creates a new mock instance.
+func NewMockIdentityStore(ctrl *gomock.Controller) *MockIdentityStore {
+	mock := &MockIdentityStore{ctrl: ctrl}
+	mock.recorder = &MockIdentityStoreMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockIdentityStore) EXPECT() *MockIdentityStoreMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method.
+func (m *MockIdentityStore) Create(arg0 context.Context, arg1 *core.Identity) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockIdentityStoreMockRecorder) Create(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockIdentityStore)(nil).Create), arg0, arg1)
+}
+
+// FindSubject mocks base method.
+func (m *MockIdentityStore) FindSubject(arg0 context.Context, arg1 string) (*core.Identity, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindSubject", arg0, arg1)
+	ret0, _ := ret[0].(*core.Identity)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindSubject indicates an expected call of FindSubject.
+func (mr *MockIdentityStoreMockRecorder) FindSubject(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindSubject", reflect.TypeOf((*MockIdentityStore)(nil).FindSubject), arg0, arg1)
+}
+
+// FindUser mocks base method.
+func (m *MockIdentityStore) FindUser(arg0 context.Context, arg1 int64) (*core.Identity, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindUser", arg0, arg1)
+	ret0, _ := ret[0].(*core.Identity)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindUser indicates an expected call of FindUser.
+func (mr *MockIdentityStoreMockRecorder) FindUser(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindUser", reflect.TypeOf((*MockIdentityStore)(nil).FindUser), arg0, arg1)
+}
+
+// Update mocks base method.
+func (m *MockIdentityStore) Update(arg0 context.Context, arg1 *core.Identity) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Update", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Update indicates an expected call of Update.
+func (mr *MockIdentityStoreMockRecorder) Update(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockIdentityStore)(nil).Update), arg0, arg1)
+}
+
+// MockIdentityService is a mock of IdentityService interface.
+type MockIdentityService struct {
+	ctrl     *gomock.Controller
+	recorder *MockIdentityServiceMockRecorder
+}
+
+// MockIdentityServiceMockRecorder is the mock recorder for MockIdentityService.
+type MockIdentityServiceMockRecorder struct {
+	mock *MockIdentityService
+}
+
+// NewMockIdentityService creates a new mock instance.
+func NewMockIdentityService(ctrl *gomock.Controller) *MockIdentityService {
+	mock := &MockIdentityService{ctrl: ctrl}
+	mock.recorder = &MockIdentityServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockIdentityService) EXPECT() *MockIdentityServiceMockRecorder {
+	return m.recorder
+}
+
+// Redirect mocks base method.
+func (m *MockIdentityService) Redirect(arg0 context.Context, arg1, arg2 string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Redirect", arg0, arg1, arg2)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Redirect indicates an expected call of Redirect.
+func (mr *MockIdentityServiceMockRecorder) Redirect(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Redirect", reflect.TypeOf((*MockIdentityService)(nil).Redirect), arg0, arg1, arg2)
+}
+
+// Verify mocks base method.
+func (m *MockIdentityService) Verify(arg0 context.Context, arg1, arg2 string) (*core.IdentityClaims, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Verify", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*core.IdentityClaims)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Verify indicates an expected call of Verify.
+func (mr *MockIdentityServiceMockRecorder) Verify(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Verify", reflect.TypeOf((*MockIdentityService)(nil).Verify), arg0, arg1, arg2)
+}
+
+// MockAdmissionService is a mock of AdmissionService interface.
+type MockAdmissionService struct {
+	ctrl     *gomock.Controller
+	recorder *MockAdmissionServiceMockRecorder
+}
+
+// MockAdmissionServiceMockRecorder is the mock recorder for MockAdmissionService.
+type MockAdmissionServiceMockRecorder struct {
+	mock *MockAdmissionService
+}
+
+// NewMockAdmissionService creates a new mock instance.
+func NewMockAdmissionService(ctrl *gomock.Controller) *MockAdmissionService {
+	mock := &MockAdmissionService{ctrl: ctrl}
+	mock.recorder = &MockAdmissionServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAdmissionService) EXPECT() *MockAdmissionServiceMockRecorder {
+	return m.recorder
+}
+
+// Admit mocks base method.
+func (m *MockAdmissionService) Admit(arg0 context.Context, arg1 *core.User) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Admit", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Admit indicates an expected call of Admit.
+func (mr *MockAdmissionServiceMockRecorder) Admit(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Admit", reflect.TypeOf((*MockAdmissionService)(nil).Admit), arg0, arg1)
+}
@@ -0,0 +1,143 @@
+// Copyright 2019 Drone IO, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package junit parses JUnit XML test reports.
+package junit
+
+import (
+	"encoding/xml"
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/drone/drone/core"
+)
+
+// maximum length of the failure message stored for a test.
+const maxMessage = 4096
+
+var errInvalidReport = errors.New("junit: invalid test report")
+
+type (
+	// suite represents a testsuites or testsuite element.
+	// The same structure is used for both elements because
+	// test suites can be nested.
+	suite struct {
+		XMLName xml.Name
+		Name    string   `xml:"name,attr"`
+		Suites  []*suite `xml:"testsuite"`
+		Cases   []*tcase `xml:"testcase"`
+	}
+
+	// tcase represents a testcase element.
+	tcase struct {
+		Name      string  `xml:"name,attr"`
+		Classname string  `xml:"classname,attr"`
+		Time      string  `xml:"time,attr"`
+		Failure   *result `xml:"failure"`
+		Error     *result `xml:"error"`
+		Skipped   *result `xml:"skipped"`
+	}
+
+	// result represents a failure, error or skipped element.
+	result struct {
+		Message string `xml:"message,attr"`
+		Text    string `xml:",chardata"`
+	}
+)
+
+// Parse parses the JUnit XML test report and returns the
+// test results. The caller is responsible for setting the
+// repository, build, stage and step identifiers.
+func Parse(r io.Reader) ([]*core.TestResult, error) {
+	root := new(suite)
+	err := xml.NewDecoder(r).Decode(root)
+	if err != nil {
+		return nil, err
+	}
+	switch root.XMLName.Local {
+	case "testsuites", "testsuite":
+	default:
+		return nil, errInvalidReport
+	}
+	var out []*core.TestResult
+	walk(root, root.Name, &out)
+	return out, nil
+}
+
+// helper function walks the test suite and appends the
+// test cases, including the test cases of nested suites.
+func walk(s *suite, name string, out *[]*core.TestResult) {
+	for _, c := range s.Cases {
+		*out = append(*out, convert(c, name))
+	}
+	for _, child := range s.Suites {
+		childName := child.Name
+		if childName == "" {
+			childName = name
+		}
+		walk(child, childName, out)
+	}
+}
+
+// helper function converts the test case to a test result.
+func convert(c *tcase, suite string) *core.TestResult {
+	out := &core.TestResult{
+		Suite:    suite,
+		Class:    c.Classname,
+		Name:     c.Name,
+		Status:   core.TestPassed,
+		Duration: duration(c.Time),
+	}
+	switch {
+	case c.Failure != nil:
+		out.Status = core.TestFailed
+		out.Message = message(c.Failure)
+	case c.Error != nil:
+		out.Status = core.TestFailed
+		out.Message = message(c.Error)
+	case c.Skipped != nil:
+		out.Status = core.TestSkipped
+		out.Message = message(c.Skipped)
+	}
+	return out
+}
+
+// helper function returns the duration, in milliseconds,
+// from the time attribute, in seconds.
+func duration(s string) int64 {
+	f, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+	if err != nil {
+		return 0
+	}
+	return int64(f * 1000)
+}
+
+// helper function returns the failure message, falling back
+// to the element text, truncated to the maximum length.
+func message(r *result) string {
+	s := strings.TrimSpace(r.Message)
+	if s == "" {
+		s = strings.TrimSpace(r.Text)
+	}
+	if len(s) > maxMessage {
+		s = s[:maxMessage]
+		for !utf8.ValidString(s) {
+			s = s[:len(s)-1]
+		}
+	}
+	return s
+}
@@ -0,0 +1,71 @@
+// Copyright 2019 Drone.IO Inc. All rights reserved.
+// Use of this source code is governed by the Drone Non-Commercial License
+// that can be found in the LICENSE file.
+
+package junit
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/drone/drone/core"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+const report = `<?xml version="1.0" encoding="UTF-8"?>
+<testsuites>
+  <testsuite name="api" tests="3">
+    <testcase name="TestFind" classname="handler" time="0.015"/>
+    <testcase name="TestCreate" classname="handler" time="1.5">
+      <failure message="want 200, got 500">handler_test.go:42</failure>
+    </testcase>
+    <testcase name="TestDelete" classname="handler">
+      <skipped/>
+    </testcase>
+    <testsuite name="">
+      <testcase name="TestNested" time="oops">
+        <error>panic: runtime error</error>
+      </testcase>
+    </testsuite>
+  </testsuite>
+</testsuites>`
+
+func TestParse(t *testing.T) {
+	got, err := Parse(strings.NewReader(report))
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	want := []*core.TestResult{
+		{Suite: "api", Class: "handler", Name: "TestFind", Status: core.TestPassed, Duration: 15},
+		{Suite: "api", Class: "handler", Name: "TestCreate", Status: core.TestFailed, Duration: 1500, Message: "want 200, got 500"},
+		{Suite: "api", Class: "handler", Name: "TestDelete", Status: core.TestSkipped},
+		{Suite: "api", Name: "TestNested", Status: core.TestFailed, Message: "panic: runtime error"},
+	}
+	if diff := cmp.Diff(got, want); diff != "" {
+		t.Errorf(diff)
+	}
+}
+
+func TestParse_Suite(t *testing.T) {
+	got, err := Parse(strings.NewReader(`<testsuite name="api"><testcase name="TestFind"/></testsuite>`))
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if len(got) != 1 || got[0].Suite != "api" {
+		t.Errorf("Want test case parsed from the root test suite")
+	}
+}
+
+func TestParse_Invalid(t *testing.T) {
+	_, err := Parse(strings.NewReader(`<coverage/>`))
+	if err != errInvalidReport {
+		t.Errorf("Want invalid report error, got %v", err)
+	}
+	_, err = Parse(strings.NewReader(`not xml`))
+	if err == nil {
+		t.Errorf("Want error parsing malformed report")
+	}
+}
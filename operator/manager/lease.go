@@ -0,0 +1,261 @@
+// Copyright 2019 Drone IO, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package manager
+
+import (
+	"context"
+	"fmt"
+	"runtime/debug"
+	"time"
+
+	"github.com/drone/drone/core"
+	"github.com/drone/drone/store/shared/db"
+
+	"github.com/hashicorp/go-multierror"
+	"github.com/sirupsen/logrus"
+)
+
+// Reclaimer finds build stages held by runners that stopped
+// renewing their lease, and either re-queues the stage for
+// another runner or fails the stage, depending on the
+// repository configuration.
+type Reclaimer struct {
+	Builds    core.BuildStore
+	Events    core.Pubsub
+	Index     core.LogIndex
+	Logs      core.LogStore
+	Logz      core.LogStream
+	Repos     core.RepositoryStore
+	Scheduler core.Scheduler
+	Stages    core.StageStore
+	Status    core.StatusService
+	Steps     core.StepStore
+	Users     core.UserStore
+	Webhook   core.WebhookSender
+	Timeout   time.Duration // Timeout is the lease duration
+}
+
+// NewReclaimer returns a new Reclaimer.
+func NewReclaimer(
+	builds core.BuildStore,
+	events core.Pubsub,
+	index core.LogIndex,
+	logs core.LogStore,
+	logz core.LogStream,
+	repos core.RepositoryStore,
+	scheduler core.Scheduler,
+	stages core.StageStore,
+	status core.StatusService,
+	steps core.StepStore,
+	users core.UserStore,
+	webhook core.WebhookSender,
+	timeout time.Duration,
+) *Reclaimer {
+	if timeout == 0 {
+		timeout = time.Minute * 5
+	}
+	return &Reclaimer{
+		Builds:    builds,
+		Events:    events,
+		Index:     index,
+		Logs:      logs,
+		Logz:      logz,
+		Repos:     repos,
+		Scheduler: scheduler,
+		Stages:    stages,
+		Status:    status,
+		Steps:     steps,
+		Users:     users,
+		Webhook:   webhook,
+		Timeout:   timeout,
+	}
+}
+
+// Start starts the reclaimer.
+func (r *Reclaimer) Start(ctx context.Context, dur time.Duration) error {
+	ticker := time.NewTicker(dur)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			r.reclaim(ctx)
+		}
+	}
+}
+
+func (r *Reclaimer) reclaim(ctx context.Context) error {
+	defer func() {
+		// taking the paranoid approach to recover from
+		// a panic that should absolutely never happen.
+		if r := recover(); r != nil {
+			logrus.Errorf("manager: unexpected panic: %s", r)
+			debug.PrintStack()
+		}
+	}()
+
+	logrus.Traceln("manager: finding expired stage leases")
+
+	stages, err := r.Stages.ListIncomplete(ctx)
+	if err != nil {
+		logrus.WithError(err).
+			Errorln("manager: cannot list incomplete stages")
+		return err
+	}
+
+	var result error
+	now := time.Now()
+	for _, stage := range stages {
+		if !isLeaseExpired(stage, r.Timeout, now) {
+			continue
+		}
+		logger := logrus.
+			WithField("stage.id", stage.ID).
+			WithField("stage.build_id", stage.BuildID).
+			WithField("stage.machine", stage.Machine).
+			WithField("stage.heartbeat", stage.Heartbeat)
+
+		repo, err := r.Repos.Find(ctx, stage.RepoID)
+		if err != nil {
+			logger.WithError(err).
+				Errorln("manager: cannot find the repository")
+			result = multierror.Append(result, err)
+			continue
+		}
+		if repo.RequeueLost {
+			logger.Debugln("manager: stage lease expired, re-queue stage")
+			err = r.requeue(ctx, stage)
+		} else {
+			logger.Debugln("manager: stage lease expired, fail stage")
+			err = r.fail(ctx, stage)
+		}
+		if err == db.ErrOptimisticLock {
+			logger.Debugln("manager: stage updated by another goroutine")
+			continue
+		}
+		if err != nil {
+			logger.WithError(err).
+				Errorln("manager: cannot reclaim the stage")
+			result = multierror.Append(result, err)
+		}
+	}
+	return result
+}
+
+// requeue resets the stage to pending and returns it to the
+// queue. The steps created by the lost runner are removed so
+// that the next runner can execute the stage from scratch.
+func (r *Reclaimer) requeue(ctx context.Context, stage *core.Stage) error {
+	steps, err := r.Steps.List(ctx, stage.ID)
+	if err != nil {
+		return err
+	}
+
+	stage.Status = core.StatusPending
+	stage.Machine = ""
+	stage.Error = ""
+	stage.ExitCode = 0
+	stage.Started = 0
+	stage.Stopped = 0
+	stage.Updated = time.Now().Unix()
+	err = r.Stages.Update(ctx, stage)
+	if err != nil {
+		return err
+	}
+
+	stage.Heartbeat = 0
+	err = r.Stages.Heartbeat(ctx, stage)
+	if err != nil {
+		return err
+	}
+
+	for _, step := range steps {
+		r.Logz.Delete(ctx, step.ID)
+		r.Logs.Delete(ctx, step.ID)
+		if r.Index != nil {
+			r.Index.Delete(ctx, step.ID)
+		}
+	}
+	err = r.Steps.DeleteStage(ctx, stage.ID)
+	if err != nil {
+		return err
+	}
+	return r.Scheduler.Schedule(ctx, stage)
+}
+
+// fail marks the stage and its unfinished steps as errored
+// and completes the stage as if the runner had finished it.
+func (r *Reclaimer) fail(ctx context.Context, stage *core.Stage) error {
+	steps, err := r.Steps.List(ctx, stage.ID)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now().Unix()
+	for _, step := range steps {
+		switch step.Status {
+		case core.StatusPending:
+			step.Status = core.StatusSkipped
+		case core.StatusRunning:
+			step.Status = core.StatusError
+			step.Error = errRunnerLost
+			step.Stopped = now
+		}
+	}
+
+	stage.Steps = steps
+	stage.Status = core.StatusError
+	stage.Error = fmt.Sprintf("%s: no heartbeat received from %s in %s",
+		errRunnerLost, stage.Machine, r.Timeout)
+	stage.Stopped = now
+	if stage.Started == 0 {
+		stage.Started = now
+	}
+
+	t := &teardown{
+		Builds:    r.Builds,
+		Events:    r.Events,
+		Logs:      r.Logz,
+		Repos:     r.Repos,
+		Scheduler: r.Scheduler,
+		Steps:     r.Steps,
+		Stages:    r.Stages,
+		Status:    r.Status,
+		Users:     r.Users,
+		Webhook:   r.Webhook,
+	}
+	return t.do(ctx, stage)
+}
+
+// errRunnerLost is the error message recorded on stages and
+// steps that are failed because the runner lease expired.
+const errRunnerLost = "runner lost"
+
+// isLeaseExpired returns true if the stage is assigned to a
+// runner that has renewed its lease at least once, but has not
+// renewed it within the lease timeout. Runners that never send
+// a heartbeat do not hold a lease and are never expired.
+func isLeaseExpired(stage *core.Stage, timeout time.Duration, now time.Time) bool {
+	if stage.IsDone() || stage.Machine == "" || stage.Heartbeat == 0 {
+		return false
+	}
+	last := stage.Heartbeat
+	if stage.Updated > last {
+		last = stage.Updated
+	}
+	return now.Sub(time.Unix(last, 0)) > timeout
+}
@@ -0,0 +1,98 @@
+// Copyright 2019 Drone.IO Inc. All rights reserved.
+// Use of this source code is governed by the Drone Non-Commercial License
+// that can be found in the LICENSE file.
+
+package manager
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/drone/drone/core"
+	"github.com/drone/drone/mock"
+
+	"github.com/golang/mock/gomock"
+)
+
+func TestIsLeaseExpired(t *testing.T) {
+	now := time.Unix(1000, 0)
+	timeout := time.Minute
+	tests := []struct {
+		stage *core.Stage
+		want  bool
+	}{
+		// runner never sent a heartbeat
+		{&core.Stage{Status: core.StatusRunning, Machine: "a", Updated: 1}, false},
+		// stage not assigned to a runner
+		{&core.Stage{Status: core.StatusPending, Heartbeat: 1}, false},
+		// stage is complete
+		{&core.Stage{Status: core.StatusPassing, Machine: "a", Heartbeat: 1}, false},
+		// heartbeat within the lease
+		{&core.Stage{Status: core.StatusRunning, Machine: "a", Heartbeat: 990}, false},
+		// heartbeat expired, but stage recently updated
+		{&core.Stage{Status: core.StatusRunning, Machine: "a", Heartbeat: 100, Updated: 990}, false},
+		// heartbeat expired
+		{&core.Stage{Status: core.StatusRunning, Machine: "a", Heartbeat: 100, Updated: 100}, true},
+	}
+	for i, test := range tests {
+		if got, want := isLeaseExpired(test.stage, timeout, now), test.want; got != want {
+			t.Errorf("Unexpected results at index %d", i)
+		}
+	}
+}
+
+func TestReclaim_Requeue(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	stage := &core.Stage{
+		ID:        1,
+		RepoID:    2,
+		Status:    core.StatusRunning,
+		Machine:   "runner-1",
+		Heartbeat: 1,
+		Updated:   1,
+	}
+	steps := []*core.Step{{ID: 3}, {ID: 4}}
+	repo := &core.Repository{ID: 2, RequeueLost: true}
+
+	ctx := context.Background()
+	stages := mock.NewMockStageStore(controller)
+	stages.EXPECT().ListIncomplete(ctx).Return([]*core.Stage{stage}, nil)
+	stages.EXPECT().Update(ctx, stage).Return(nil)
+	stages.EXPECT().Heartbeat(ctx, stage).Return(nil)
+
+	repos := mock.NewMockRepositoryStore(controller)
+	repos.EXPECT().Find(ctx, stage.RepoID).Return(repo, nil)
+
+	stepz := mock.NewMockStepStore(controller)
+	stepz.EXPECT().List(ctx, stage.ID).Return(steps, nil)
+	stepz.EXPECT().DeleteStage(ctx, stage.ID).Return(nil)
+
+	logs := mock.NewMockLogStore(controller)
+	logs.EXPECT().Delete(ctx, gomock.Any()).Return(nil).Times(2)
+
+	logz := mock.NewMockLogStream(controller)
+	logz.EXPECT().Delete(ctx, gomock.Any()).Return(nil).Times(2)
+
+	index := mock.NewMockLogIndex(controller)
+	index.EXPECT().Delete(ctx, gomock.Any()).Return(nil).Times(2)
+
+	scheduler := mock.NewMockScheduler(controller)
+	scheduler.EXPECT().Schedule(ctx, stage).Return(nil)
+
+	r := NewReclaimer(nil, nil, index, logs, logz, repos, scheduler, stages, nil, stepz, nil, nil, time.Minute)
+	if err := r.reclaim(ctx); err != nil {
+		t.Error(err)
+	}
+	if got, want := stage.Status, core.StatusPending; got != want {
+		t.Errorf("Want status %s, got %s", want, got)
+	}
+	if stage.Machine != "" {
+		t.Errorf("Want machine cleared, got %s", stage.Machine)
+	}
+	if stage.Heartbeat != 0 {
+		t.Errorf("Want heartbeat cleared, got %d", stage.Heartbeat)
+	}
+}
@@ -17,12 +17,14 @@ package manager
 import (
 	"bytes"
 	"context"
+	"database/sql"
 	"io"
 	"io/ioutil"
 	"time"
 
 	"github.com/drone/drone-yaml/yaml/converter"
 	"github.com/drone/drone/core"
+	"github.com/drone/drone/metric"
 	"github.com/drone/drone/store/shared/db"
 
 	"github.com/hashicorp/go-multierror"
@@ -54,6 +56,15 @@ type (
 		// Accept accepts the build stage for execution.
 		Accept(ctx context.Context, stage int64, machine string) (*core.Stage, error)
 
+		// Heartbeat renews the lease on the build stage.
+		Heartbeat(ctx context.Context, stage int64, machine string) error
+
+		// Join registers the runner node with the server.
+		Join(ctx context.Context, node *core.Node) error
+
+		// Leave removes the runner node from the server.
+		Leave(ctx context.Context, machine string) error
+
 		// Netrc returns a valid netrc for execution.
 		Netrc(ctx context.Context, repo int64) (*core.Netrc, error)
 
@@ -86,6 +97,12 @@ type (
 
 		// UploadCard creates a new card
 		UploadCard(ctx context.Context, step int64, input *core.CardInput) error
+
+		// UploadArtifact uploads a build artifact
+		UploadArtifact(ctx context.Context, step int64, name string, r io.Reader) error
+
+		// UploadTests uploads the test results
+		UploadTests(ctx context.Context, step int64, results []*core.TestResult) error
 	}
 
 	// Request provides filters when requesting a pending
@@ -98,20 +115,26 @@ type (
 		Arch    string            `json:"arch"`
 		Variant string            `json:"variant"`
 		Kernel  string            `json:"kernel"`
+		Machine string            `json:"machine,omitempty"`
 		Labels  map[string]string `json:"labels,omitempty"`
+		Taints  map[string]string `json:"taints,omitempty"`
 	}
 )
 
 // New returns a new Manager.
 func New(
+	artifacts core.ArtifactStore,
 	builds core.BuildStore,
 	cards core.CardStore,
 	config core.ConfigService,
 	converter core.ConvertService,
+	environments core.EnvironmentStore,
 	events core.Pubsub,
 	logs core.LogStore,
 	logz core.LogStream,
+	index core.LogIndex,
 	netrcs core.NetrcService,
+	nodes core.NodeStore,
 	repos core.RepositoryStore,
 	scheduler core.Scheduler,
 	secrets core.SecretStore,
@@ -120,52 +143,63 @@ func New(
 	stages core.StageStore,
 	steps core.StepStore,
 	system *core.System,
+	tests core.TestStore,
 	users core.UserStore,
 	webhook core.WebhookSender,
 ) BuildManager {
 	return &Manager{
-		Builds:    builds,
-		Cards:     cards,
-		Config:    config,
-		Converter: converter,
-		Events:    events,
-		Globals:   globals,
-		Logs:      logs,
-		Logz:      logz,
-		Netrcs:    netrcs,
-		Repos:     repos,
-		Scheduler: scheduler,
-		Secrets:   secrets,
-		Status:    status,
-		Stages:    stages,
-		Steps:     steps,
-		System:    system,
-		Users:     users,
-		Webhook:   webhook,
+		Artifacts:    artifacts,
+		Builds:       builds,
+		Cards:        cards,
+		Config:       config,
+		Converter:    converter,
+		Environments: environments,
+		Events:       events,
+		Globals:      globals,
+		Index:        index,
+		Logs:         logs,
+		Logz:         logz,
+		Netrcs:       netrcs,
+		Nodes:        nodes,
+		Repos:        repos,
+		Scheduler:    scheduler,
+		Secrets:      secrets,
+		Status:       status,
+		Stages:       stages,
+		Steps:        steps,
+		System:       system,
+		Tests:        tests,
+		Users:        users,
+		Webhook:      webhook,
 	}
 }
 
 // Manager provides a simplified interface to the build runner so that it
 // can more easily interact with the server.
 type Manager struct {
-	Builds    core.BuildStore
-	Cards     core.CardStore
-	Config    core.ConfigService
-	Converter core.ConvertService
-	Events    core.Pubsub
-	Globals   core.GlobalSecretStore
-	Logs      core.LogStore
-	Logz      core.LogStream
-	Netrcs    core.NetrcService
-	Repos     core.RepositoryStore
-	Scheduler core.Scheduler
-	Secrets   core.SecretStore
-	Status    core.StatusService
-	Stages    core.StageStore
-	Steps     core.StepStore
-	System    *core.System
-	Users     core.UserStore
-	Webhook   core.WebhookSender
+	Artifacts    core.ArtifactStore
+	Builds       core.BuildStore
+	Cards        core.CardStore
+	Config       core.ConfigService
+	Converter    core.ConvertService
+	Environments core.EnvironmentStore
+	Events       core.Pubsub
+	Globals      core.GlobalSecretStore
+	Index        core.LogIndex
+	Logs         core.LogStore
+	Logz         core.LogStream
+	Netrcs       core.NetrcService
+	Nodes        core.NodeStore
+	Repos        core.RepositoryStore
+	Scheduler    core.Scheduler
+	Secrets      core.SecretStore
+	Status       core.StatusService
+	Stages       core.StageStore
+	Steps        core.StepStore
+	System       *core.System
+	Tests        core.TestStore
+	Users        core.UserStore
+	Webhook      core.WebhookSender
 }
 
 // Request requests the next available build stage for execution.
@@ -178,10 +212,26 @@ func (m *Manager) Request(ctx context.Context, args *Request) (*core.Stage, erro
 			"arch":    args.Arch,
 			"kernel":  args.Kernel,
 			"variant": args.Variant,
+			"machine": args.Machine,
 		},
 	)
 	logger.Debugln("manager: request queue item")
 
+	if args.Machine != "" {
+		node, err := m.register(ctx, args)
+		if err != nil {
+			logger.WithError(err).
+				Warnln("manager: cannot register runner node")
+		} else if node.Cordoned {
+			// a cordoned node does not receive new stages. The
+			// request blocks until the runner long-poll times out,
+			// as if the queue were empty.
+			logger.Debugln("manager: runner node is cordoned")
+			<-ctx.Done()
+			return nil, ctx.Err()
+		}
+	}
+
 	stage, err := m.Scheduler.Request(ctx, core.Filter{
 		Kind:    args.Kind,
 		Type:    args.Type,
@@ -189,7 +239,9 @@ func (m *Manager) Request(ctx context.Context, args *Request) (*core.Stage, erro
 		Arch:    args.Arch,
 		Kernel:  args.Kernel,
 		Variant: args.Variant,
+		Machine: args.Machine,
 		Labels:  args.Labels,
+		Taints:  args.Taints,
 	})
 	if err != nil && ctx.Err() != nil {
 		logger.Debugln("manager: context canceled")
@@ -226,6 +278,10 @@ func (m *Manager) Accept(ctx context.Context, id int64, machine string) (*core.S
 		logger.Debugln("manager: stage already assigned. abort.")
 		return nil, db.ErrOptimisticLock
 	}
+	if node, err := m.Nodes.FindName(noContext, machine); err == nil && node.Cordoned {
+		logger.Debugln("manager: runner node is cordoned. abort.")
+		return nil, db.ErrOptimisticLock
+	}
 
 	stage.Machine = machine
 	stage.Status = core.StatusPending
@@ -244,6 +300,38 @@ func (m *Manager) Accept(ctx context.Context, id int64, machine string) (*core.S
 	return stage, err
 }
 
+// Heartbeat renews the lease held by the machine on the build
+// stage. A stage that is no longer assigned to the machine returns
+// an optimistic lock error, signaling the runner to abort.
+func (m *Manager) Heartbeat(ctx context.Context, id int64, machine string) error {
+	logger := logrus.WithFields(
+		logrus.Fields{
+			"stage-id": id,
+			"machine":  machine,
+		},
+	)
+	logger.Traceln("manager: heartbeat stage")
+
+	stage, err := m.Stages.Find(noContext, id)
+	if err != nil {
+		logger = logger.WithError(err)
+		logger.Warnln("manager: cannot find stage")
+		return err
+	}
+	if stage.IsDone() || stage.Machine != machine {
+		logger.Debugln("manager: stage lease lost. abort.")
+		return db.ErrOptimisticLock
+	}
+
+	stage.Heartbeat = time.Now().Unix()
+	err = m.Stages.Heartbeat(noContext, stage)
+	if err != nil {
+		logger = logger.WithError(err)
+		logger.Warnln("manager: cannot renew stage lease")
+	}
+	return err
+}
+
 // Details fetches build details.
 func (m *Manager) Details(ctx context.Context, id int64) (*Context, error) {
 	logger := logrus.WithField("step-id", id)
@@ -329,6 +417,19 @@ func (m *Manager) Details(ctx context.Context, id int64) (*Context, error) {
 		logger.Warnln("manager: cannot list global secrets")
 		return nil, err
 	}
+	// environment secrets are listed first so that they take
+	// precedence over repository and global secrets with the
+	// same name.
+	if build.Deploy != "" {
+		env, err := m.Environments.FindName(noContext, repo.ID, build.Deploy)
+		if err == nil {
+			secrets = append(secrets, env.Secrets...)
+		} else if err != sql.ErrNoRows {
+			logger = logger.WithError(err)
+			logger.Warnln("manager: cannot find environment")
+			return nil, err
+		}
+	}
 	// TODO(bradrydzewski) can we delegate filtering
 	// secrets to the agent? If not, we should add
 	// unit tests.
@@ -409,6 +510,8 @@ func (m *Manager) After(ctx context.Context, step *core.Step) error {
 		errs = multierror.Append(errs, err)
 		logger = logger.WithError(err)
 		logger.Warnln("manager: cannot update step")
+	} else if step.IsDone() {
+		metric.ObserveStep(step)
 	}
 
 	if err := m.Logz.Delete(noContext, step.ID); err != nil {
@@ -522,13 +625,19 @@ func (m *Manager) Write(ctx context.Context, step int64, line *core.Line) error
 
 // Upload uploads the full logs.
 func (m *Manager) Upload(ctx context.Context, step int64, r io.Reader) error {
+	var buf bytes.Buffer
+	if m.Index != nil {
+		r = io.TeeReader(r, &buf)
+	}
 	err := m.Logs.Create(ctx, step, r)
 	if err != nil {
 		logger := logrus.WithError(err)
 		logger = logger.WithField("step-id", step)
 		logger.Warnln("manager: cannot upload complete logs")
+		return err
 	}
-	return err
+	m.index(ctx, step, buf.Bytes())
+	return nil
 }
 
 // UploadBytes uploads the full logs.
@@ -539,8 +648,25 @@ func (m *Manager) UploadBytes(ctx context.Context, step int64, data []byte) erro
 		logger := logrus.WithError(err)
 		logger = logger.WithField("step-id", step)
 		logger.Warnln("manager: cannot upload complete logs")
+		return err
+	}
+	m.index(ctx, step, data)
+	return nil
+}
+
+// index adds the uploaded logs to the search index, if log
+// search is enabled. Indexing errors are logged and ignored
+// so that they do not fail the upload.
+func (m *Manager) index(ctx context.Context, step int64, data []byte) {
+	if m.Index == nil {
+		return
+	}
+	err := m.Index.Index(ctx, step, data)
+	if err != nil {
+		logger := logrus.WithError(err)
+		logger = logger.WithField("step-id", step)
+		logger.Warnln("manager: cannot index logs")
 	}
-	return err
 }
 
 // UploadCard creates card for step.
@@ -555,3 +681,109 @@ func (m *Manager) UploadCard(ctx context.Context, stepId int64, input *core.Card
 	}
 	return nil
 }
+
+// UploadArtifact uploads a build artifact for the step. The
+// upload fails if it exceeds the repository artifact quota.
+func (m *Manager) UploadArtifact(ctx context.Context, stepID int64, name string, r io.Reader) error {
+	logger := logrus.WithField("step-id", stepID).WithField("artifact", name)
+
+	step, err := m.Steps.Find(noContext, stepID)
+	if err != nil {
+		logger.WithError(err).Warnln("manager: cannot find step")
+		return err
+	}
+	stage, err := m.Stages.Find(noContext, step.StageID)
+	if err != nil {
+		logger.WithError(err).Warnln("manager: cannot find stage")
+		return err
+	}
+	build, err := m.Builds.Find(noContext, stage.BuildID)
+	if err != nil {
+		logger.WithError(err).Warnln("manager: cannot find build")
+		return err
+	}
+	repo, err := m.Repos.Find(noContext, build.RepoID)
+	if err != nil {
+		logger.WithError(err).Warnln("manager: cannot find repository")
+		return err
+	}
+
+	artifact := &core.Artifact{
+		RepoID:  repo.ID,
+		BuildID: build.ID,
+		StageID: stage.ID,
+		StepID:  step.ID,
+		Name:    name,
+		Created: time.Now().Unix(),
+	}
+	err = artifact.Validate()
+	if err != nil {
+		return err
+	}
+
+	if repo.ArtifactQuota > 0 {
+		usage, err := m.Artifacts.Usage(ctx, repo.ID)
+		if err != nil {
+			logger.WithError(err).Warnln("manager: cannot calculate artifact usage")
+			return err
+		}
+		// an artifact replaced by the upload is kept until the
+		// upload succeeds, so its size counts towards the quota.
+		if usage >= repo.ArtifactQuota {
+			return core.ErrArtifactQuota
+		}
+		r = &quotaReader{r: r, n: repo.ArtifactQuota - usage}
+	}
+
+	err = m.Artifacts.Create(ctx, artifact, r)
+	if err != nil {
+		logger.WithError(err).Warnln("manager: cannot upload artifact")
+	}
+	return err
+}
+
+// UploadTests persists the test results reported by the step.
+func (m *Manager) UploadTests(ctx context.Context, stepID int64, results []*core.TestResult) error {
+	logger := logrus.WithField("step-id", stepID)
+
+	step, err := m.Steps.Find(noContext, stepID)
+	if err != nil {
+		logger.WithError(err).Warnln("manager: cannot find step")
+		return err
+	}
+	stage, err := m.Stages.Find(noContext, step.StageID)
+	if err != nil {
+		logger.WithError(err).Warnln("manager: cannot find stage")
+		return err
+	}
+
+	now := time.Now().Unix()
+	for _, result := range results {
+		result.RepoID = stage.RepoID
+		result.BuildID = stage.BuildID
+		result.StageID = stage.ID
+		result.StepID = step.ID
+		result.Created = now
+	}
+	err = m.Tests.Create(ctx, results)
+	if err != nil {
+		logger.WithError(err).Warnln("manager: cannot upload test results")
+	}
+	return err
+}
+
+// quotaReader returns an error once more than n bytes are
+// read from the underlying reader.
+type quotaReader struct {
+	r io.Reader
+	n int64
+}
+
+func (q *quotaReader) Read(p []byte) (int, error) {
+	n, err := q.r.Read(p)
+	q.n -= int64(n)
+	if q.n < 0 {
+		return n, core.ErrArtifactQuota
+	}
+	return n, err
+}
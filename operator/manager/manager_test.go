@@ -5,11 +5,184 @@
 package manager
 
 import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
 	"io/ioutil"
+	"testing"
 
+	"github.com/drone/drone/core"
+	"github.com/drone/drone/mock"
+
+	"github.com/golang/mock/gomock"
 	"github.com/sirupsen/logrus"
 )
 
 func init() {
 	logrus.SetOutput(ioutil.Discard)
 }
+
+func TestUpload_Index(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	data := []byte(`[{"pos":0,"out":"hello world"}]`)
+
+	logs := mock.NewMockLogStore(controller)
+	logs.EXPECT().Create(gomock.Any(), int64(1), gomock.Any()).DoAndReturn(
+		func(_ context.Context, _ int64, r io.Reader) error {
+			_, err := ioutil.ReadAll(r)
+			return err
+		},
+	)
+
+	index := mock.NewMockLogIndex(controller)
+	index.EXPECT().Index(gomock.Any(), int64(1), data).Return(nil)
+
+	m := &Manager{Logs: logs, Index: index}
+	err := m.Upload(context.Background(), 1, bytes.NewBuffer(data))
+	if err != nil {
+		t.Error(err)
+	}
+}
+
+func TestUploadBytes_Index(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	data := []byte(`[{"pos":0,"out":"hello world"}]`)
+
+	logs := mock.NewMockLogStore(controller)
+	logs.EXPECT().Create(gomock.Any(), int64(1), gomock.Any()).Return(nil)
+
+	index := mock.NewMockLogIndex(controller)
+	index.EXPECT().Index(gomock.Any(), int64(1), data).Return(nil)
+
+	m := &Manager{Logs: logs, Index: index}
+	err := m.UploadBytes(context.Background(), 1, data)
+	if err != nil {
+		t.Error(err)
+	}
+}
+
+func TestUpload_IndexSkippedOnError(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	logs := mock.NewMockLogStore(controller)
+	logs.EXPECT().Create(gomock.Any(), int64(1), gomock.Any()).Return(errors.New("not found"))
+
+	index := mock.NewMockLogIndex(controller)
+
+	m := &Manager{Logs: logs, Index: index}
+	err := m.UploadBytes(context.Background(), 1, nil)
+	if err == nil {
+		t.Errorf("Expect upload error returned")
+	}
+}
+
+func TestUploadArtifact_Quota(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	step := &core.Step{ID: 1, StageID: 2}
+	stage := &core.Stage{ID: 2, BuildID: 3}
+	build := &core.Build{ID: 3, RepoID: 4}
+	repo := &core.Repository{ID: 4, ArtifactQuota: 10}
+
+	steps := mock.NewMockStepStore(controller)
+	steps.EXPECT().Find(gomock.Any(), step.ID).Return(step, nil)
+	stages := mock.NewMockStageStore(controller)
+	stages.EXPECT().Find(gomock.Any(), stage.ID).Return(stage, nil)
+	builds := mock.NewMockBuildStore(controller)
+	builds.EXPECT().Find(gomock.Any(), build.ID).Return(build, nil)
+	repos := mock.NewMockRepositoryStore(controller)
+	repos.EXPECT().Find(gomock.Any(), repo.ID).Return(repo, nil)
+
+	artifacts := mock.NewMockArtifactStore(controller)
+	artifacts.EXPECT().Usage(gomock.Any(), repo.ID).Return(int64(6), nil)
+	artifacts.EXPECT().Create(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, _ *core.Artifact, r io.Reader) error {
+			_, err := ioutil.ReadAll(r)
+			return err
+		},
+	)
+
+	m := &Manager{
+		Artifacts: artifacts,
+		Builds:    builds,
+		Repos:     repos,
+		Stages:    stages,
+		Steps:     steps,
+	}
+	r := bytes.NewBufferString("hello world")
+	err := m.UploadArtifact(noContext, step.ID, "coverage.out", r)
+	if got, want := err, core.ErrArtifactQuota; got != want {
+		t.Errorf("Want error %v, got %v", want, got)
+	}
+}
+
+func TestUploadArtifact_InvalidName(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	step := &core.Step{ID: 1, StageID: 2}
+	stage := &core.Stage{ID: 2, BuildID: 3}
+	build := &core.Build{ID: 3, RepoID: 4}
+	repo := &core.Repository{ID: 4}
+
+	steps := mock.NewMockStepStore(controller)
+	steps.EXPECT().Find(gomock.Any(), step.ID).Return(step, nil)
+	stages := mock.NewMockStageStore(controller)
+	stages.EXPECT().Find(gomock.Any(), stage.ID).Return(stage, nil)
+	builds := mock.NewMockBuildStore(controller)
+	builds.EXPECT().Find(gomock.Any(), build.ID).Return(build, nil)
+	repos := mock.NewMockRepositoryStore(controller)
+	repos.EXPECT().Find(gomock.Any(), repo.ID).Return(repo, nil)
+
+	m := &Manager{
+		Builds: builds,
+		Repos:  repos,
+		Stages: stages,
+		Steps:  steps,
+	}
+	err := m.UploadArtifact(noContext, step.ID, "../secret", new(bytes.Buffer))
+	if err == nil {
+		t.Errorf("Want error for invalid artifact name")
+	}
+}
+
+func TestUploadTests(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	step := &core.Step{ID: 1, StageID: 2}
+	stage := &core.Stage{ID: 2, BuildID: 3, RepoID: 4}
+
+	steps := mock.NewMockStepStore(controller)
+	steps.EXPECT().Find(gomock.Any(), step.ID).Return(step, nil)
+	stages := mock.NewMockStageStore(controller)
+	stages.EXPECT().Find(gomock.Any(), stage.ID).Return(stage, nil)
+
+	results := []*core.TestResult{
+		{Suite: "api", Name: "TestFind", Status: core.TestPassed},
+	}
+	tests := mock.NewMockTestStore(controller)
+	tests.EXPECT().Create(gomock.Any(), results).Return(nil)
+
+	m := &Manager{
+		Stages: stages,
+		Steps:  steps,
+		Tests:  tests,
+	}
+	err := m.UploadTests(noContext, step.ID, results)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	result := results[0]
+	if result.RepoID != 4 || result.BuildID != 3 || result.StageID != 2 || result.StepID != 1 {
+		t.Errorf("Want test result linked to the repository, build, stage and step")
+	}
+}
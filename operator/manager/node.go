@@ -0,0 +1,127 @@
+// Copyright 2019 Drone IO, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package manager
+
+import (
+	"context"
+	"time"
+
+	"github.com/drone/drone/core"
+
+	"github.com/sirupsen/logrus"
+)
+
+// register creates or refreshes the runner node that issued
+// the request, recording the platform details and the time
+// the runner last checked in.
+func (m *Manager) register(ctx context.Context, args *Request) (*core.Node, error) {
+	return m.upsert(ctx, &core.Node{
+		Name:    args.Machine,
+		Kind:    args.Kind,
+		Type:    args.Type,
+		OS:      args.OS,
+		Arch:    args.Arch,
+		Variant: args.Variant,
+		Kernel:  args.Kernel,
+		Labels:  args.Labels,
+		Taints:  args.Taints,
+	})
+}
+
+// upsert creates the runner node if it does not exist, or
+// updates the existing node with the platform details reported
+// by the runner. The cordon and drain state of an existing node
+// is preserved. A draining node is marked as drained once it
+// no longer has stages assigned.
+func (m *Manager) upsert(ctx context.Context, in *core.Node) (*core.Node, error) {
+	now := time.Now().Unix()
+	node, err := m.Nodes.FindName(ctx, in.Name)
+	if err != nil {
+		in.State = core.NodeStateActive
+		in.Cordoned = false
+		in.Seen = now
+		in.Created = now
+		in.Updated = now
+		return in, m.Nodes.Create(ctx, in)
+	}
+
+	node.Kind = in.Kind
+	node.Type = in.Type
+	node.OS = in.OS
+	node.Arch = in.Arch
+	node.Variant = in.Variant
+	node.Kernel = in.Kernel
+	node.Labels = in.Labels
+	node.Taints = in.Taints
+	if in.Capacity != 0 {
+		node.Capacity = in.Capacity
+	}
+	node.Seen = now
+	node.Updated = now
+
+	if node.State == core.NodeStateDraining {
+		stages, err := m.Stages.ListIncomplete(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if isNodeIdle(node, stages) {
+			node.State = core.NodeStateDrained
+		}
+	}
+	return node, m.Nodes.Update(ctx, node)
+}
+
+// helper function returns true if none of the incomplete
+// stages are assigned to the runner node.
+func isNodeIdle(node *core.Node, stages []*core.Stage) bool {
+	for _, stage := range stages {
+		if stage.Machine == node.Name {
+			return false
+		}
+	}
+	return true
+}
+
+// Join registers the runner node with the server.
+func (m *Manager) Join(ctx context.Context, node *core.Node) error {
+	logger := logrus.WithField("machine", node.Name)
+	logger.Debugln("manager: runner node join")
+
+	_, err := m.upsert(ctx, node)
+	if err != nil {
+		logger.WithError(err).
+			Warnln("manager: cannot register runner node")
+	}
+	return err
+}
+
+// Leave removes the runner node from the server.
+func (m *Manager) Leave(ctx context.Context, machine string) error {
+	logger := logrus.WithField("machine", machine)
+	logger.Debugln("manager: runner node leave")
+
+	node, err := m.Nodes.FindName(ctx, machine)
+	if err != nil {
+		logger.WithError(err).
+			Debugln("manager: cannot find runner node")
+		return err
+	}
+	err = m.Nodes.Delete(ctx, node)
+	if err != nil {
+		logger.WithError(err).
+			Warnln("manager: cannot remove runner node")
+	}
+	return err
+}
@@ -0,0 +1,295 @@
+// Copyright 2019 Drone IO, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package manager
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/drone/drone/core"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Restarter restarts the failed or selected stages of a
+// completed build, and their downstream dependents, without
+// re-executing the stages that already passed.
+type Restarter struct {
+	Builds    core.BuildStore
+	Cards     core.CardStore
+	Events    core.Pubsub
+	Index     core.LogIndex
+	Logs      core.LogStore
+	Logz      core.LogStream
+	Repos     core.RepositoryStore
+	Scheduler core.Scheduler
+	Stages    core.StageStore
+	Status    core.StatusService
+	Steps     core.StepStore
+	Tests     core.TestStore
+	Users     core.UserStore
+	Webhook   core.WebhookSender
+}
+
+// NewRestarter returns a new Restarter.
+func NewRestarter(
+	builds core.BuildStore,
+	cards core.CardStore,
+	events core.Pubsub,
+	index core.LogIndex,
+	logs core.LogStore,
+	logz core.LogStream,
+	repos core.RepositoryStore,
+	scheduler core.Scheduler,
+	stages core.StageStore,
+	status core.StatusService,
+	steps core.StepStore,
+	tests core.TestStore,
+	users core.UserStore,
+	webhook core.WebhookSender,
+) core.Restarter {
+	return &Restarter{
+		Builds:    builds,
+		Cards:     cards,
+		Events:    events,
+		Index:     index,
+		Logs:      logs,
+		Logz:      logz,
+		Repos:     repos,
+		Scheduler: scheduler,
+		Stages:    stages,
+		Status:    status,
+		Steps:     steps,
+		Tests:     tests,
+		Users:     users,
+		Webhook:   webhook,
+	}
+}
+
+// Restart restarts the stages of a completed build.
+func (r *Restarter) Restart(ctx context.Context, repo *core.Repository, build *core.Build, numbers []int) error {
+	logger := logrus.WithFields(
+		logrus.Fields{
+			"repo":   repo.Slug,
+			"build":  build.Number,
+			"stages": numbers,
+		},
+	)
+
+	if build.IsDone() == false {
+		return core.ErrRestartRunning
+	}
+
+	stages, err := r.Stages.ListSteps(ctx, build.ID)
+	if err != nil {
+		logger.WithError(err).
+			Warnln("manager: cannot list stages")
+		return err
+	}
+
+	selected := selectRestart(stages, numbers)
+	if len(selected) == 0 {
+		return core.ErrRestartNoStages
+	}
+
+	// the build start time is reset, and is set again once
+	// the first restarted stage starts, so that the repository
+	// timeout is measured from the restart.
+	now := time.Now().Unix()
+	build.Status = core.StatusPending
+	build.Started = 0
+	build.Finished = 0
+	build.Updated = now
+	err = r.Builds.Update(ctx, build)
+	if err != nil {
+		logger.WithError(err).
+			Warnln("manager: cannot update the build")
+		return err
+	}
+
+	for _, stage := range selected {
+		err := r.reset(ctx, stage)
+		if err != nil {
+			logger.WithError(err).
+				WithField("stage.id", stage.ID).
+				Warnln("manager: cannot reset the stage")
+			return err
+		}
+	}
+
+	// stages without dependencies are scheduled immediately.
+	// the remaining stages are scheduled using the same rules
+	// that are applied when a stage completes, which schedules
+	// the stages with completed dependencies.
+	for _, stage := range selected {
+		if stage.Status != core.StatusPending {
+			continue
+		}
+		err = r.Scheduler.Schedule(ctx, stage)
+		if err != nil {
+			logger.WithError(err).
+				WithField("stage.id", stage.ID).
+				Warnln("manager: cannot schedule stage")
+			return err
+		}
+	}
+	t := &teardown{
+		Builds:    r.Builds,
+		Events:    r.Events,
+		Logs:      r.Logz,
+		Repos:     r.Repos,
+		Scheduler: r.Scheduler,
+		Steps:     r.Steps,
+		Stages:    r.Stages,
+		Status:    r.Status,
+		Users:     r.Users,
+		Webhook:   r.Webhook,
+	}
+	err = t.scheduleDownstream(ctx, nil, stages)
+	if err != nil {
+		logger.WithError(err).
+			Warnln("manager: cannot schedule downstream stages")
+		return err
+	}
+
+	logger.Debugln("manager: build stages restarted")
+
+	build.Stages = stages
+	repo.Build = build
+	data, _ := json.Marshal(repo)
+	err = r.Events.Publish(ctx, &core.Message{
+		Repository: repo.Slug,
+		Visibility: repo.Visibility,
+		Data:       data,
+	})
+	if err != nil {
+		logger.WithError(err).
+			Warnln("manager: cannot publish build event")
+	}
+
+	user, err := r.Users.Find(ctx, repo.UserID)
+	if err != nil {
+		logger.WithError(err).
+			Warnln("manager: cannot find repository owner")
+		return nil
+	}
+	err = r.Status.Send(ctx, user, &core.StatusInput{
+		Repo:  repo,
+		Build: build,
+	})
+	if err != nil {
+		logger.WithError(err).
+			Warnln("manager: cannot publish status")
+	}
+	return nil
+}
+
+// reset resets the stage and removes the steps, logs, cards
+// and test results of the previous execution. Stages with
+// dependencies are reset to waiting, pending completion of
+// their dependencies.
+func (r *Restarter) reset(ctx context.Context, stage *core.Stage) error {
+	stage.Status = core.StatusPending
+	if len(stage.DependsOn) != 0 {
+		stage.Status = core.StatusWaiting
+	}
+	stage.Machine = ""
+	stage.Error = ""
+	stage.ErrIgnore = false
+	stage.ExitCode = 0
+	stage.Started = 0
+	stage.Stopped = 0
+	stage.Updated = time.Now().Unix()
+	err := r.Stages.Update(ctx, stage)
+	if err != nil {
+		return err
+	}
+
+	stage.Heartbeat = 0
+	err = r.Stages.Heartbeat(ctx, stage)
+	if err != nil {
+		return err
+	}
+
+	// the logs, cards and log index may not exist, for example
+	// if the step was skipped, and errors are therefore ignored.
+	// These are removed explicitly because foreign keys are not
+	// enforced by every database driver.
+	for _, step := range stage.Steps {
+		r.Logz.Delete(ctx, step.ID)
+		r.Logs.Delete(ctx, step.ID)
+		r.Cards.Delete(ctx, step.ID)
+		if r.Index != nil {
+			r.Index.Delete(ctx, step.ID)
+		}
+	}
+	err = r.Tests.DeleteStage(ctx, stage.BuildID, stage.ID)
+	if err != nil {
+		return err
+	}
+	stage.Steps = nil
+	return r.Steps.DeleteStage(ctx, stage.ID)
+}
+
+// helper function returns the stages that should be restarted,
+// which includes the stages with the provided numbers, or the
+// failed stages if no numbers are provided, and their
+// downstream dependents.
+func selectRestart(stages []*core.Stage, numbers []int) []*core.Stage {
+	selected := map[string]bool{}
+	for _, stage := range stages {
+		if len(numbers) == 0 && stage.IsFailed() {
+			selected[stage.Name] = true
+		}
+		for _, number := range numbers {
+			if stage.Number == number {
+				selected[stage.Name] = true
+			}
+		}
+	}
+	if len(selected) == 0 {
+		return nil
+	}
+
+	// walk the graph until no additional downstream stages
+	// are found.
+	for {
+		found := false
+		for _, stage := range stages {
+			if selected[stage.Name] {
+				continue
+			}
+			for _, dep := range stage.DependsOn {
+				if selected[dep] {
+					selected[stage.Name] = true
+					found = true
+					break
+				}
+			}
+		}
+		if !found {
+			break
+		}
+	}
+
+	var result []*core.Stage
+	for _, stage := range stages {
+		if selected[stage.Name] {
+			result = append(result, stage)
+		}
+	}
+	return result
+}
@@ -0,0 +1,144 @@
+// Copyright 2019 Drone.IO Inc. All rights reserved.
+// Use of this source code is governed by the Drone Non-Commercial License
+// that can be found in the LICENSE file.
+
+package manager
+
+import (
+	"context"
+	"testing"
+
+	"github.com/drone/drone/core"
+	"github.com/drone/drone/mock"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestSelectRestart(t *testing.T) {
+	stages := []*core.Stage{
+		{Number: 1, Name: "build", Status: core.StatusPassing},
+		{Number: 2, Name: "unit", Status: core.StatusPassing, DependsOn: []string{"build"}},
+		{Number: 3, Name: "integration", Status: core.StatusFailing, DependsOn: []string{"build"}},
+		{Number: 4, Name: "publish", Status: core.StatusSkipped, DependsOn: []string{"unit", "integration"}},
+		{Number: 5, Name: "deploy", Status: core.StatusSkipped, DependsOn: []string{"publish"}},
+		{Number: 6, Name: "lint", Status: core.StatusPassing},
+	}
+	tests := []struct {
+		numbers []int
+		want    []string
+	}{
+		// restart failed stages and downstream dependents
+		{nil, []string{"integration", "publish", "deploy"}},
+		// restart from the selected stage
+		{[]int{2}, []string{"unit", "publish", "deploy"}},
+		// restart from the first stage
+		{[]int{1}, []string{"build", "unit", "integration", "publish", "deploy"}},
+		// restart a stage without dependents
+		{[]int{6}, []string{"lint"}},
+		// restart an unknown stage
+		{[]int{7}, nil},
+	}
+	for i, test := range tests {
+		var got []string
+		for _, stage := range selectRestart(stages, test.numbers) {
+			got = append(got, stage.Name)
+		}
+		if diff := cmp.Diff(got, test.want); diff != "" {
+			t.Errorf("Unexpected results at index %d", i)
+			t.Log(diff)
+		}
+	}
+}
+
+func TestRestart(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	repo := &core.Repository{ID: 1, UserID: 2, Slug: "octocat/hello-world"}
+	build := &core.Build{ID: 3, RepoID: 1, Number: 4, Status: core.StatusFailing, Started: 1, Finished: 1}
+	user := &core.User{ID: 2}
+
+	passed := &core.Stage{ID: 5, BuildID: 3, Number: 1, Name: "build", Status: core.StatusPassing}
+	failed := &core.Stage{ID: 6, BuildID: 3, Number: 2, Name: "test", Status: core.StatusFailing, Machine: "runner-1",
+		Steps: []*core.Step{{ID: 8}}}
+	skipped := &core.Stage{ID: 7, BuildID: 3, Number: 3, Name: "deploy", Status: core.StatusSkipped, DependsOn: []string{"test"}}
+	stages := []*core.Stage{passed, failed, skipped}
+
+	ctx := context.Background()
+	builds := mock.NewMockBuildStore(controller)
+	builds.EXPECT().Update(ctx, build).Return(nil)
+
+	stagez := mock.NewMockStageStore(controller)
+	stagez.EXPECT().ListSteps(ctx, build.ID).Return(stages, nil)
+	stagez.EXPECT().Update(ctx, failed).Return(nil)
+	stagez.EXPECT().Update(ctx, skipped).Return(nil)
+	stagez.EXPECT().Heartbeat(ctx, failed).Return(nil)
+	stagez.EXPECT().Heartbeat(ctx, skipped).Return(nil)
+
+	steps := mock.NewMockStepStore(controller)
+	steps.EXPECT().DeleteStage(ctx, failed.ID).Return(nil)
+	steps.EXPECT().DeleteStage(ctx, skipped.ID).Return(nil)
+
+	logs := mock.NewMockLogStore(controller)
+	logs.EXPECT().Delete(ctx, int64(8)).Return(nil)
+
+	logz := mock.NewMockLogStream(controller)
+	logz.EXPECT().Delete(ctx, int64(8)).Return(nil)
+
+	cards := mock.NewMockCardStore(controller)
+	cards.EXPECT().Delete(ctx, int64(8)).Return(nil)
+
+	index := mock.NewMockLogIndex(controller)
+	index.EXPECT().Delete(ctx, int64(8)).Return(nil)
+
+	tests := mock.NewMockTestStore(controller)
+	tests.EXPECT().DeleteStage(ctx, build.ID, failed.ID).Return(nil)
+	tests.EXPECT().DeleteStage(ctx, build.ID, skipped.ID).Return(nil)
+
+	scheduler := mock.NewMockScheduler(controller)
+	scheduler.EXPECT().Schedule(ctx, failed).Return(nil)
+
+	events := mock.NewMockPubsub(controller)
+	events.EXPECT().Publish(ctx, gomock.Any()).Return(nil)
+
+	users := mock.NewMockUserStore(controller)
+	users.EXPECT().Find(ctx, repo.UserID).Return(user, nil)
+
+	status := mock.NewMockStatusService(controller)
+	status.EXPECT().Send(ctx, user, gomock.Any()).Return(nil)
+
+	r := NewRestarter(builds, cards, events, index, logs, logz, nil, scheduler, stagez, status, steps, tests, users, nil)
+	if err := r.Restart(ctx, repo, build, nil); err != nil {
+		t.Error(err)
+	}
+	if got, want := build.Status, core.StatusPending; got != want {
+		t.Errorf("Want build status %s, got %s", want, got)
+	}
+	// the build start time is reset so that the repository
+	// timeout is not measured from the original execution.
+	if build.Started != 0 {
+		t.Errorf("Want build start time reset, got %d", build.Started)
+	}
+	if got, want := passed.Status, core.StatusPassing; got != want {
+		t.Errorf("Want passed stage status %s, got %s", want, got)
+	}
+	if got, want := failed.Status, core.StatusPending; got != want {
+		t.Errorf("Want failed stage status %s, got %s", want, got)
+	}
+	if got, want := skipped.Status, core.StatusWaiting; got != want {
+		t.Errorf("Want downstream stage status %s, got %s", want, got)
+	}
+	if failed.Machine != "" {
+		t.Errorf("Want machine cleared, got %s", failed.Machine)
+	}
+}
+
+func TestRestart_Running(t *testing.T) {
+	build := &core.Build{Status: core.StatusRunning}
+	r := NewRestarter(nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil)
+	err := r.Restart(context.Background(), &core.Repository{}, build, nil)
+	if err != core.ErrRestartRunning {
+		t.Errorf("Want error %s, got %v", core.ErrRestartRunning, err)
+	}
+}
@@ -92,6 +92,24 @@ func (s *Client) Accept(ctx context.Context, stage int64, machine string) (*core
 	return nil, s.send(noContext, "/rpc/v1/accept", in, nil)
 }
 
+// Heartbeat renews the lease on the build stage.
+func (s *Client) Heartbeat(ctx context.Context, stage int64, machine string) error {
+	in := &heartbeatRequest{Stage: stage, Machine: machine}
+	return s.send(noContext, "/rpc/v1/heartbeat", in, nil)
+}
+
+// Join registers the runner node with the server.
+func (s *Client) Join(ctx context.Context, node *core.Node) error {
+	in := &joinRequest{Node: node}
+	return s.send(noContext, "/rpc/v1/join", in, nil)
+}
+
+// Leave removes the runner node from the server.
+func (s *Client) Leave(ctx context.Context, machine string) error {
+	in := &leaveRequest{Machine: machine}
+	return s.send(noContext, "/rpc/v1/leave", in, nil)
+}
+
 // Netrc returns a valid netrc for execution.
 func (s *Client) Netrc(ctx context.Context, repo int64) (*core.Netrc, error) {
 	in := &netrcRequest{repo}
@@ -213,6 +231,14 @@ func (s *Client) UploadCard(ctx context.Context, step int64, input *core.CardInp
 	return errors.New("rpc upload card not supported")
 }
 
+func (s *Client) UploadArtifact(ctx context.Context, step int64, name string, r io.Reader) error {
+	return errors.New("rpc upload artifact not supported")
+}
+
+func (s *Client) UploadTests(ctx context.Context, step int64, results []*core.TestResult) error {
+	return errors.New("rpc upload tests not supported")
+}
+
 func (s *Client) send(ctx context.Context, path string, in, out interface{}) error {
 	// Source a buffer from a pool. The agent may generate a
 	// large number of small requests for log entries. This will
@@ -55,6 +55,12 @@ func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		s.handleRequest(w, r)
 	case "/rpc/v1/accept":
 		s.handleAccept(w, r)
+	case "/rpc/v1/heartbeat":
+		s.handleHeartbeat(w, r)
+	case "/rpc/v1/join":
+		s.handleJoin(w, r)
+	case "/rpc/v1/leave":
+		s.handleLeave(w, r)
 	case "/rpc/v1/netrc":
 		s.handleNetrc(w, r)
 	case "/rpc/v1/details":
@@ -111,6 +117,54 @@ func (s *Server) handleAccept(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(out)
 }
 
+func (s *Server) handleHeartbeat(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	in := &heartbeatRequest{}
+	err := json.NewDecoder(r.Body).Decode(in)
+	if err != nil {
+		writeBadRequest(w, err)
+		return
+	}
+	err = s.manager.Heartbeat(ctx, in.Stage, in.Machine)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) handleJoin(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	in := &joinRequest{}
+	err := json.NewDecoder(r.Body).Decode(in)
+	if err != nil {
+		writeBadRequest(w, err)
+		return
+	}
+	err = s.manager.Join(ctx, in.Node)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) handleLeave(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	in := &leaveRequest{}
+	err := json.NewDecoder(r.Body).Decode(in)
+	if err != nil {
+		writeBadRequest(w, err)
+		return
+	}
+	err = s.manager.Leave(ctx, in.Machine)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
 func (s *Server) handleNetrc(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 	in := &netrcRequest{}
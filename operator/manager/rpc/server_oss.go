@@ -47,6 +47,21 @@ func (Server) Accept(ctx context.Context, stage int64, machine string) error {
 	return errors.New("not implemented")
 }
 
+// Heartbeat renews the lease on the build stage.
+func (Server) Heartbeat(ctx context.Context, stage int64, machine string) error {
+	return errors.New("not implemented")
+}
+
+// Join registers the runner node with the server.
+func (Server) Join(ctx context.Context, node *core.Node) error {
+	return errors.New("not implemented")
+}
+
+// Leave removes the runner node from the server.
+func (Server) Leave(ctx context.Context, machine string) error {
+	return errors.New("not implemented")
+}
+
 // Netrc returns a valid netrc for execution.
 func (Server) Netrc(ctx context.Context, repo int64) (*core.Netrc, error) {
 	return nil, errors.New("not implemented")
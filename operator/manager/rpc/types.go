@@ -22,6 +22,19 @@ type acceptRequest struct {
 	Machine string
 }
 
+type heartbeatRequest struct {
+	Stage   int64
+	Machine string
+}
+
+type joinRequest struct {
+	Node *core.Node
+}
+
+type leaveRequest struct {
+	Machine string
+}
+
 type netrcRequest struct {
 	Repo int64
 }
@@ -8,6 +8,7 @@
 
 /rpc/v2/stage                       POST  (request)
 /rpc/v2/stage/{stage}?machine=      POST  (accept, details)
+/rpc/v2/stage/{stage}/heartbeat     POST  (heartbeat)
 /rpc/v2/stage/{stage}               PUT   (beforeAll, afterAll)
 /rpc/v2/stage/{stage}/steps/{step}  PUT   (before, after)
 /rpc/v2/build/{build}/watch         POST  (watch)
@@ -31,6 +32,7 @@ import (
 	"github.com/drone/drone/core"
 	"github.com/drone/drone/handler/api/render"
 	"github.com/drone/drone/operator/manager"
+	"github.com/drone/drone/operator/manager/junit"
 	"github.com/drone/drone/store/shared/db"
 )
 
@@ -40,22 +42,41 @@ var defaultTimeout = time.Second * 30
 var noContext = context.Background()
 
 // HandleJoin returns an http.HandlerFunc that makes an
-// http.Request to join the cluster.
+// http.Request to join the cluster. The optional request
+// body provides the runner platform details and capacity.
 //
-// POST /rpc/v2/nodes/:machine
-func HandleJoin() http.HandlerFunc {
+// POST /rpc/v2/nodes/{machine}
+func HandleJoin(m manager.BuildManager) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		writeOK(w) // this is a no-op
+		node := new(core.Node)
+		err := json.NewDecoder(r.Body).Decode(node)
+		if err != nil && err != io.EOF {
+			writeError(w, err)
+			return
+		}
+		node.Name = chi.URLParam(r, "machine")
+
+		err = m.Join(noContext, node)
+		if err != nil {
+			writeError(w, err)
+		} else {
+			writeOK(w)
+		}
 	}
 }
 
 // HandleLeave returns an http.HandlerFunc that makes an
 // http.Request to leave the cluster.
 //
-// DELETE /rpc/v2/nodes/:machine
-func HandleLeave() http.HandlerFunc {
+// DELETE /rpc/v2/nodes/{machine}
+func HandleLeave(m manager.BuildManager) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		writeOK(w) // this is a no-op
+		err := m.Leave(noContext, chi.URLParam(r, "machine"))
+		if err != nil {
+			writeError(w, err)
+		} else {
+			writeOK(w)
+		}
 	}
 }
 
@@ -112,6 +133,24 @@ func HandleAccept(m manager.BuildManager) http.HandlerFunc {
 	}
 }
 
+// HandleHeartbeat returns an http.HandlerFunc that processes an
+// http.Request to renew the lease on the stage.
+//
+// POST /rpc/v2/stage/{stage}/heartbeat?machine=
+func HandleHeartbeat(m manager.BuildManager) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		stage, _ := strconv.ParseInt(
+			chi.URLParam(r, "stage"), 10, 64)
+
+		err := m.Heartbeat(noContext, stage, r.FormValue("machine"))
+		if err != nil {
+			writeError(w, err)
+		} else {
+			writeOK(w)
+		}
+	}
+}
+
 // HandleInfo returns an http.HandlerFunc that processes an
 // http.Request to get the build details.
 //
@@ -296,6 +335,51 @@ func HandleCardUpload(m manager.BuildManager) http.HandlerFunc {
 	}
 }
 
+// HandleArtifactUpload returns an http.HandlerFunc that accepts
+// an http.Request to upload and persist a build artifact for a
+// pipeline step. The artifact name is the remainder of the path.
+//
+// POST /rpc/v2/step/{step}/artifacts/*
+func HandleArtifactUpload(m manager.BuildManager) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		step, _ := strconv.ParseInt(
+			chi.URLParam(r, "step"), 10, 64)
+		name := chi.URLParam(r, "*")
+
+		err := m.UploadArtifact(noContext, step, name, r.Body)
+		if err != nil {
+			writeError(w, err)
+		} else {
+			writeOK(w)
+		}
+	}
+}
+
+// HandleTestUpload returns an http.HandlerFunc that accepts an
+// http.Request to upload and persist a JUnit XML test report
+// for a pipeline step.
+//
+// POST /rpc/v2/step/{step}/tests
+func HandleTestUpload(m manager.BuildManager) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		step, _ := strconv.ParseInt(
+			chi.URLParam(r, "step"), 10, 64)
+
+		results, err := junit.Parse(r.Body)
+		if err != nil {
+			render.BadRequest(w, err)
+			return
+		}
+
+		err = m.UploadTests(noContext, step, results)
+		if err != nil {
+			writeError(w, err)
+		} else {
+			writeOK(w)
+		}
+	}
+}
+
 // write a 200 Status OK to the response body.
 func writeJSON(w http.ResponseWriter, v interface{}) {
 	json.NewEncoder(w).Encode(v)
@@ -314,6 +398,10 @@ func writeError(w http.ResponseWriter, err error) {
 		w.WriteHeader(204) // should retry
 	} else if err == db.ErrOptimisticLock {
 		w.WriteHeader(409) // should abort
+	} else if err == core.ErrArtifactQuota {
+		w.WriteHeader(413) // should abort
+	} else if err == core.ErrArtifactTooLarge {
+		w.WriteHeader(413) // should abort
 	} else {
 		w.WriteHeader(500) // should fail
 	}
@@ -26,11 +26,12 @@ func NewServer(manager manager.BuildManager, secret string) Server {
 	r.Use(middleware.Recoverer)
 	r.Use(middleware.NoCache)
 	r.Use(authorization(secret))
-	r.Post("/nodes/:machine", HandleJoin())
-	r.Delete("/nodes/:machine", HandleLeave())
+	r.Post("/nodes/{machine}", HandleJoin(manager))
+	r.Delete("/nodes/{machine}", HandleLeave(manager))
 	r.Post("/ping", HandlePing())
 	r.Post("/stage", HandleRequest(manager))
 	r.Post("/stage/{stage}", HandleAccept(manager))
+	r.Post("/stage/{stage}/heartbeat", HandleHeartbeat(manager))
 	r.Get("/stage/{stage}", HandleInfo(manager))
 	r.Put("/stage/{stage}", HandleUpdateStage(manager))
 	r.Put("/step/{step}", HandleUpdateStep(manager))
@@ -38,6 +39,8 @@ func NewServer(manager manager.BuildManager, secret string) Server {
 	r.Post("/step/{step}/logs/batch", HandleLogBatch(manager))
 	r.Post("/step/{step}/logs/upload", HandleLogUpload(manager))
 	r.Post("/step/{step}/card", HandleCardUpload(manager))
+	r.Post("/step/{step}/artifacts/*", HandleArtifactUpload(manager))
+	r.Post("/step/{step}/tests", HandleTestUpload(manager))
 	return Server(r)
 }
 
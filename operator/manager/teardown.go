@@ -20,6 +20,7 @@ import (
 	"time"
 
 	"github.com/drone/drone/core"
+	"github.com/drone/drone/metric"
 	"github.com/drone/drone/store/shared/db"
 	"github.com/drone/go-scm/scm"
 
@@ -64,6 +65,16 @@ func (t *teardown) do(ctx context.Context, stage *core.Stage) error {
 		return err
 	}
 
+	// steps completed by the teardown, for example steps that
+	// are skipped or killed when the stage is cancelled, are
+	// never reported as complete by the runner and are observed
+	// here. Steps that were already complete were observed when
+	// the runner reported them.
+	done, err := t.listDone(stage)
+	if err != nil {
+		logger.WithError(err).Debugln("manager: cannot list the steps")
+	}
+
 	for _, step := range stage.Steps {
 		if len(step.Error) > 500 {
 			step.Error = step.Error[:500]
@@ -77,6 +88,9 @@ func (t *teardown) do(ctx context.Context, stage *core.Stage) error {
 				Warnln("manager: cannot persist the step")
 			return err
 		}
+		if done != nil && !done[step.ID] && step.IsDone() {
+			metric.ObserveStep(step)
+		}
 	}
 
 	if len(stage.Error) > 500 {
@@ -272,6 +286,25 @@ func (t *teardown) cancelDownstream(
 	return errs
 }
 
+// helper function returns the set of stage steps that are
+// complete in the datastore, before the teardown updates them.
+// The steps are only listed if the step metrics are enabled,
+// since the result is only used to observe the steps.
+func (t *teardown) listDone(stage *core.Stage) (map[int64]bool, error) {
+	if !metric.StepMetricsEnabled() {
+		return nil, nil
+	}
+	steps, err := t.Steps.List(noContext, stage.ID)
+	if err != nil {
+		return nil, err
+	}
+	done := map[int64]bool{}
+	for _, step := range steps {
+		done[step.ID] = step.IsDone()
+	}
+	return done, nil
+}
+
 // scheduleDownstream is a helper function that tests for
 // downstream stages and schedules stages if all dependencies
 // and execution requirements are met.
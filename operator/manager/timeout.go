@@ -0,0 +1,282 @@
+// Copyright 2019 Drone IO, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package manager
+
+import (
+	"context"
+	"fmt"
+	"runtime/debug"
+	"time"
+
+	"github.com/drone/drone/core"
+	"github.com/drone/drone/store/shared/db"
+
+	"github.com/hashicorp/go-multierror"
+	"github.com/sirupsen/logrus"
+)
+
+// Watchdog enforces timeouts on the server. Pending stages
+// that wait in the queue longer than the maximum queue wait
+// are errored, and running stages that exceed the pipeline
+// or repository timeout are errored. A stage that exceeds its
+// pipeline timeout is cancelled without affecting its sibling
+// stages, and a build that exceeds the repository timeout is
+// cancelled.
+type Watchdog struct {
+	Builds    core.BuildStore
+	Events    core.Pubsub
+	Logz      core.LogStream
+	Repos     core.RepositoryStore
+	Scheduler core.Scheduler
+	Stages    core.StageStore
+	Status    core.StatusService
+	Steps     core.StepStore
+	Users     core.UserStore
+	Webhook   core.WebhookSender
+	Pending   time.Duration // Pending is the maximum queue wait
+}
+
+// NewWatchdog returns a new Watchdog. If the pending duration
+// is zero, pending stages wait in the queue indefinitely.
+func NewWatchdog(
+	builds core.BuildStore,
+	events core.Pubsub,
+	logz core.LogStream,
+	repos core.RepositoryStore,
+	scheduler core.Scheduler,
+	stages core.StageStore,
+	status core.StatusService,
+	steps core.StepStore,
+	users core.UserStore,
+	webhook core.WebhookSender,
+	pending time.Duration,
+) *Watchdog {
+	return &Watchdog{
+		Builds:    builds,
+		Events:    events,
+		Logz:      logz,
+		Repos:     repos,
+		Scheduler: scheduler,
+		Stages:    stages,
+		Status:    status,
+		Steps:     steps,
+		Users:     users,
+		Webhook:   webhook,
+		Pending:   pending,
+	}
+}
+
+// Start starts the watchdog.
+func (w *Watchdog) Start(ctx context.Context, dur time.Duration) error {
+	ticker := time.NewTicker(dur)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			w.watch(ctx)
+		}
+	}
+}
+
+func (w *Watchdog) watch(ctx context.Context) error {
+	defer func() {
+		// taking the paranoid approach to recover from
+		// a panic that should absolutely never happen.
+		if r := recover(); r != nil {
+			logrus.Errorf("manager: unexpected panic: %s", r)
+			debug.PrintStack()
+		}
+	}()
+
+	logrus.Traceln("manager: finding overdue stages")
+
+	stages, err := w.Stages.ListIncomplete(ctx)
+	if err != nil {
+		logrus.WithError(err).
+			Errorln("manager: cannot list incomplete stages")
+		return err
+	}
+
+	var result error
+	now := time.Now()
+	repos := map[int64]*core.Repository{}
+	builds := map[int64]*core.Build{}
+	for _, stage := range stages {
+		logger := logrus.
+			WithField("stage.id", stage.ID).
+			WithField("stage.build_id", stage.BuildID).
+			WithField("stage.status", stage.Status)
+
+		var reason string
+		var cancel bool
+		switch stage.Status {
+		case core.StatusPending:
+			if isQueueExpired(stage, w.Pending, now) {
+				reason = errNoRunner
+			}
+		case core.StatusRunning:
+			repo, ok := repos[stage.RepoID]
+			if !ok {
+				repo, err = w.Repos.Find(ctx, stage.RepoID)
+				if err != nil {
+					logger.WithError(err).
+						Errorln("manager: cannot find the repository")
+					result = multierror.Append(result, err)
+					continue
+				}
+				repos[stage.RepoID] = repo
+			}
+			build, ok := builds[stage.BuildID]
+			if !ok {
+				build, err = w.Builds.Find(ctx, stage.BuildID)
+				if err != nil {
+					logger.WithError(err).
+						Errorln("manager: cannot find the build")
+					result = multierror.Append(result, err)
+					continue
+				}
+				builds[stage.BuildID] = build
+			}
+			reason, cancel = checkTimeout(stage, build, repo, now)
+		}
+		if reason == "" {
+			continue
+		}
+
+		logger.WithField("reason", reason).
+			Debugln("manager: stage is overdue, error stage")
+
+		err = w.expire(ctx, stage, reason)
+		if err == db.ErrOptimisticLock {
+			logger.Debugln("manager: stage updated by another goroutine")
+			continue
+		}
+		if err != nil {
+			logger.WithError(err).
+				Errorln("manager: cannot error the overdue stage")
+			result = multierror.Append(result, err)
+			continue
+		}
+
+		// pending stages are not assigned to a runner and
+		// therefore do not need to be cancelled. A stage that
+		// exceeds the pipeline timeout is cancelled through its
+		// lease: the stage is complete, so the runner fails to
+		// renew the lease on its next heartbeat and aborts
+		// execution of the stage, leaving the siblings running.
+		if stage.Machine == "" || !cancel {
+			continue
+		}
+
+		// the scheduler cancels stages at the build level,
+		// which instructs the runner executing this stage,
+		// and any runner executing a sibling stage, to stop
+		// execution.
+		err = w.Scheduler.Cancel(ctx, stage.BuildID)
+		if err != nil {
+			logger.WithError(err).
+				Errorln("manager: cannot cancel the overdue stage")
+			result = multierror.Append(result, err)
+		}
+	}
+	return result
+}
+
+// expire marks the stage and its unfinished steps as errored,
+// records the reason, and completes the stage as if the runner
+// had finished it.
+func (w *Watchdog) expire(ctx context.Context, stage *core.Stage, reason string) error {
+	steps, err := w.Steps.List(ctx, stage.ID)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now().Unix()
+	for _, step := range steps {
+		switch step.Status {
+		case core.StatusPending:
+			step.Status = core.StatusSkipped
+		case core.StatusRunning:
+			step.Status = core.StatusError
+			step.Error = reason
+			step.Stopped = now
+		}
+	}
+
+	stage.Steps = steps
+	stage.Status = core.StatusError
+	stage.Error = reason
+	stage.Stopped = now
+	if stage.Started == 0 {
+		stage.Started = now
+	}
+
+	t := &teardown{
+		Builds:    w.Builds,
+		Events:    w.Events,
+		Logs:      w.Logz,
+		Repos:     w.Repos,
+		Scheduler: w.Scheduler,
+		Steps:     w.Steps,
+		Stages:    w.Stages,
+		Status:    w.Status,
+		Users:     w.Users,
+		Webhook:   w.Webhook,
+	}
+	return t.do(ctx, stage)
+}
+
+// errNoRunner is the error message recorded on pending stages
+// that exceed the maximum queue wait.
+const errNoRunner = "no runner available"
+
+// isQueueExpired returns true if the stage is pending and has
+// not been assigned to a runner within the maximum queue wait.
+// The stage is considered queued from the time it was last
+// updated, which is the time it entered the pending state.
+func isQueueExpired(stage *core.Stage, wait time.Duration, now time.Time) bool {
+	if wait == 0 || stage.Status != core.StatusPending || stage.Machine != "" {
+		return false
+	}
+	return now.Sub(time.Unix(stage.Updated, 0)) > wait
+}
+
+// checkTimeout returns the reason the running stage exceeded
+// its timeout, or an empty string if the stage is within its
+// timeout. The stage is limited by its pipeline timeout, and
+// the build is limited by the repository timeout. It returns
+// true if the build exceeded the repository timeout and should
+// be cancelled.
+func checkTimeout(stage *core.Stage, build *core.Build, repo *core.Repository, now time.Time) (string, bool) {
+	if stage.Status != core.StatusRunning || stage.Started == 0 {
+		return "", false
+	}
+	if repo.Timeout > 0 && build.Started > 0 {
+		timeout := time.Duration(repo.Timeout) * time.Minute
+		if now.Sub(time.Unix(build.Started, 0)) > timeout {
+			return fmt.Sprintf("timeout exceeded: build did not complete within %s", timeout), true
+		}
+	}
+	if stage.Timeout > 0 {
+		timeout := time.Duration(stage.Timeout) * time.Minute
+		if now.Sub(time.Unix(stage.Started, 0)) > timeout {
+			return fmt.Sprintf("timeout exceeded: stage did not complete within %s", timeout), false
+		}
+	}
+	return "", false
+}
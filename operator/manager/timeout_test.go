@@ -0,0 +1,234 @@
+// Copyright 2019 Drone.IO Inc. All rights reserved.
+// Use of this source code is governed by the Drone Non-Commercial License
+// that can be found in the LICENSE file.
+
+package manager
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/drone/drone/core"
+	"github.com/drone/drone/mock"
+	"github.com/drone/drone/store/shared/db"
+
+	"github.com/golang/mock/gomock"
+)
+
+func TestIsQueueExpired(t *testing.T) {
+	now := time.Unix(1000, 0)
+	wait := time.Minute
+	tests := []struct {
+		stage *core.Stage
+		wait  time.Duration
+		want  bool
+	}{
+		// maximum queue wait is disabled
+		{&core.Stage{Status: core.StatusPending, Updated: 1}, 0, false},
+		// stage is running
+		{&core.Stage{Status: core.StatusRunning, Updated: 1}, wait, false},
+		// stage is assigned to a runner
+		{&core.Stage{Status: core.StatusPending, Machine: "a", Updated: 1}, wait, false},
+		// stage is pending within the queue wait
+		{&core.Stage{Status: core.StatusPending, Updated: 990}, wait, false},
+		// stage is pending beyond the queue wait
+		{&core.Stage{Status: core.StatusPending, Updated: 100}, wait, true},
+	}
+	for i, test := range tests {
+		if got, want := isQueueExpired(test.stage, test.wait, now), test.want; got != want {
+			t.Errorf("Unexpected results at index %d", i)
+		}
+	}
+}
+
+func TestCheckTimeout(t *testing.T) {
+	now := time.Unix(10000, 0)
+	tests := []struct {
+		stage  *core.Stage
+		build  *core.Build
+		repo   *core.Repository
+		want   string
+		cancel bool
+	}{
+		// stage is not running
+		{
+			stage: &core.Stage{Status: core.StatusPending, Timeout: 1},
+			build: &core.Build{Started: 1},
+			repo:  &core.Repository{Timeout: 1},
+		},
+		// stage and build within the timeout
+		{
+			stage: &core.Stage{Status: core.StatusRunning, Started: 9990, Timeout: 1},
+			build: &core.Build{Started: 9990},
+			repo:  &core.Repository{Timeout: 60},
+		},
+		// stage exceeds the pipeline timeout
+		{
+			stage: &core.Stage{Status: core.StatusRunning, Started: 9000, Timeout: 10},
+			build: &core.Build{Started: 9000},
+			repo:  &core.Repository{Timeout: 60},
+			want:  "timeout exceeded: stage did not complete within 10m0s",
+		},
+		// build exceeds the repository timeout
+		{
+			stage:  &core.Stage{Status: core.StatusRunning, Started: 9990},
+			build:  &core.Build{Started: 100},
+			repo:   &core.Repository{Timeout: 60},
+			want:   "timeout exceeded: build did not complete within 1h0m0s",
+			cancel: true,
+		},
+		// restarted build has not started, and the original
+		// start time is not used to measure the timeout
+		{
+			stage: &core.Stage{Status: core.StatusRunning, Started: 9990},
+			build: &core.Build{Started: 0},
+			repo:  &core.Repository{Timeout: 60},
+		},
+		// repository timeout is not configured
+		{
+			stage: &core.Stage{Status: core.StatusRunning, Started: 1},
+			build: &core.Build{Started: 1},
+			repo:  &core.Repository{},
+		},
+	}
+	for i, test := range tests {
+		reason, cancel := checkTimeout(test.stage, test.build, test.repo, now)
+		if got, want := reason, test.want; got != want {
+			t.Errorf("Want reason %q at index %d, got %q", want, i, got)
+		}
+		if got, want := cancel, test.cancel; got != want {
+			t.Errorf("Want cancel %v at index %d, got %v", want, i, got)
+		}
+	}
+}
+
+func TestWatchdog_Timeout(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	started := time.Now().Add(-time.Hour).Unix()
+	stage := &core.Stage{
+		ID:      1,
+		RepoID:  2,
+		BuildID: 3,
+		Status:  core.StatusRunning,
+		Machine: "runner-1",
+		Started: started,
+		Timeout: 30,
+	}
+	sibling := &core.Stage{
+		ID:      4,
+		RepoID:  2,
+		BuildID: 3,
+		Status:  core.StatusRunning,
+		Machine: "runner-2",
+		Started: started,
+	}
+	step := &core.Step{ID: 5, StageID: 1, Status: core.StatusRunning}
+	repo := &core.Repository{ID: 2, Timeout: 120}
+	build := &core.Build{ID: 3, RepoID: 2, Status: core.StatusRunning, Started: started}
+
+	ctx := context.Background()
+	stages := mock.NewMockStageStore(controller)
+	stages.EXPECT().ListIncomplete(ctx).Return([]*core.Stage{stage, sibling}, nil)
+	stages.EXPECT().Update(gomock.Any(), stage).Return(nil)
+	stages.EXPECT().ListSteps(gomock.Any(), build.ID).Return([]*core.Stage{stage, sibling}, nil)
+
+	repos := mock.NewMockRepositoryStore(controller)
+	repos.EXPECT().Find(gomock.Any(), repo.ID).Return(repo, nil).Times(2)
+
+	builds := mock.NewMockBuildStore(controller)
+	builds.EXPECT().Find(gomock.Any(), build.ID).Return(build, nil).Times(2)
+
+	stepz := mock.NewMockStepStore(controller)
+	stepz.EXPECT().List(ctx, stage.ID).Return([]*core.Step{step}, nil)
+	stepz.EXPECT().Update(gomock.Any(), step).Return(nil)
+
+	logz := mock.NewMockLogStream(controller)
+	logz.EXPECT().Delete(gomock.Any(), step.ID).Return(nil)
+
+	// the stage exceeds the pipeline timeout, and the build
+	// is therefore not cancelled.
+	scheduler := mock.NewMockScheduler(controller)
+
+	w := NewWatchdog(builds, nil, logz, repos, scheduler, stages, nil, stepz, nil, nil, 0)
+	if err := w.watch(ctx); err != nil {
+		t.Error(err)
+	}
+	if got, want := stage.Status, core.StatusError; got != want {
+		t.Errorf("Want status %s, got %s", want, got)
+	}
+	if got, want := stage.Error, "timeout exceeded: stage did not complete within 30m0s"; got != want {
+		t.Errorf("Want error %q, got %q", want, got)
+	}
+	if got, want := step.Status, core.StatusError; got != want {
+		t.Errorf("Want step status %s, got %s", want, got)
+	}
+	if got, want := sibling.Status, core.StatusRunning; got != want {
+		t.Errorf("Want sibling status %s, got %s", want, got)
+	}
+
+	// the runner executing the overdue stage fails to renew
+	// its lease, which cancels execution of the stage.
+	stages.EXPECT().Find(gomock.Any(), stage.ID).Return(stage, nil)
+	m := &Manager{Stages: stages}
+	if got, want := m.Heartbeat(ctx, stage.ID, stage.Machine), db.ErrOptimisticLock; got != want {
+		t.Errorf("Want lease lost error, got %v", got)
+	}
+}
+
+func TestWatchdog_Pending(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	stage := &core.Stage{
+		ID:      1,
+		RepoID:  2,
+		BuildID: 3,
+		Status:  core.StatusPending,
+		Updated: time.Now().Add(-time.Hour).Unix(),
+	}
+	sibling := &core.Stage{
+		ID:      4,
+		RepoID:  2,
+		BuildID: 3,
+		Status:  core.StatusPending,
+		Updated: time.Now().Unix(),
+	}
+	repo := &core.Repository{ID: 2}
+	build := &core.Build{ID: 3, RepoID: 2, Status: core.StatusPending}
+
+	ctx := context.Background()
+	stages := mock.NewMockStageStore(controller)
+	stages.EXPECT().ListIncomplete(ctx).Return([]*core.Stage{stage, sibling}, nil)
+	stages.EXPECT().Update(gomock.Any(), stage).Return(nil)
+	stages.EXPECT().ListSteps(gomock.Any(), build.ID).Return([]*core.Stage{stage, sibling}, nil)
+
+	repos := mock.NewMockRepositoryStore(controller)
+	repos.EXPECT().Find(gomock.Any(), repo.ID).Return(repo, nil)
+
+	builds := mock.NewMockBuildStore(controller)
+	builds.EXPECT().Find(gomock.Any(), build.ID).Return(build, nil)
+
+	stepz := mock.NewMockStepStore(controller)
+	stepz.EXPECT().List(ctx, stage.ID).Return(nil, nil)
+
+	// the scheduler is not expected to cancel the build
+	// because the pending stage is not assigned to a runner.
+	scheduler := mock.NewMockScheduler(controller)
+
+	w := NewWatchdog(builds, nil, nil, repos, scheduler, stages, nil, stepz, nil, nil, time.Minute)
+	if err := w.watch(ctx); err != nil {
+		t.Error(err)
+	}
+	if got, want := stage.Status, core.StatusError; got != want {
+		t.Errorf("Want status %s, got %s", want, got)
+	}
+	if got, want := stage.Error, errNoRunner; got != want {
+		t.Errorf("Want error %q, got %q", want, got)
+	}
+	if got, want := sibling.Status, core.StatusPending; got != want {
+		t.Errorf("Want sibling status %s, got %s", want, got)
+	}
+}
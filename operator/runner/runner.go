@@ -70,6 +70,8 @@ type Runner struct {
 	Environ    map[string]string
 	Machine    string
 	Labels     map[string]string
+	Taints     map[string]string
+	Heartbeat  time.Duration // Heartbeat is the lease renewal interval
 
 	Kind     string
 	Type     string
@@ -568,7 +570,9 @@ func (r *Runner) poll(ctx context.Context) error {
 		Arch:    r.Arch,
 		Kernel:  r.Kernel,
 		Variant: r.Variant,
+		Machine: r.Machine,
 		Labels:  r.Labels,
+		Taints:  r.Taints,
 	})
 	if err != nil {
 		logger = logger.WithError(err)
@@ -596,6 +600,8 @@ func (r *Runner) poll(ctx context.Context) error {
 		return err
 	}
 
+	go r.heartbeat(ctx, p.ID, cancel)
+
 	go func() {
 		logger.Debugln("runner: watch for cancel signal")
 		done, _ := r.Manager.Watch(ctx, p.BuildID)
@@ -609,3 +615,43 @@ func (r *Runner) poll(ctx context.Context) error {
 
 	return r.Run(ctx, p.ID)
 }
+
+// heartbeat renews the lease on the build stage until the
+// context is cancelled. If the lease is lost, because the
+// server reclaimed or completed the stage, the execution of
+// the stage is cancelled.
+func (r *Runner) heartbeat(ctx context.Context, id int64, cancel context.CancelFunc) {
+	if r.Heartbeat == 0 {
+		return
+	}
+	logger := logrus.WithFields(
+		logrus.Fields{
+			"machine":  r.Machine,
+			"stage-id": id,
+		},
+	)
+
+	ticker := time.NewTicker(r.Heartbeat)
+	defer ticker.Stop()
+
+	for {
+		// the lease is renewed immediately, since the server
+		// only expires stages that received a heartbeat.
+		err := r.Manager.Heartbeat(ctx, id, r.Machine)
+		if err == db.ErrOptimisticLock {
+			logger.Infoln("runner: stage lease lost, cancel execution")
+			cancel()
+			return
+		}
+		if err != nil && ctx.Err() == nil {
+			logger.WithError(err).
+				Warnln("runner: cannot renew stage lease")
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -5,11 +5,178 @@
 package runner
 
 import (
+	"context"
+	"database/sql"
 	"io/ioutil"
+	"sync"
+	"testing"
+	"time"
 
+	"github.com/drone/drone/core"
+	"github.com/drone/drone/mock"
+	"github.com/drone/drone/operator/manager"
+
+	"github.com/golang/mock/gomock"
 	"github.com/sirupsen/logrus"
 )
 
 func init() {
 	logrus.SetOutput(ioutil.Discard)
 }
+
+// this test verifies the runner renews the stage lease with
+// the server while the stage executes, and cancels execution
+// once the server reclaims the stage.
+func TestHeartbeat_LeaseLost(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	var mu sync.Mutex
+	stage := &core.Stage{
+		ID:      1,
+		Status:  core.StatusRunning,
+		Machine: "runner-1",
+	}
+
+	stages := mock.NewMockStageStore(controller)
+	stages.EXPECT().Find(gomock.Any(), stage.ID).DoAndReturn(
+		func(ctx context.Context, id int64) (*core.Stage, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			clone := *stage
+			return &clone, nil
+		},
+	).MinTimes(2)
+	stages.EXPECT().Heartbeat(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(ctx context.Context, in *core.Stage) error {
+			mu.Lock()
+			defer mu.Unlock()
+			stage.Heartbeat = in.Heartbeat
+			// the server reclaims the stage after the first
+			// renewal, as if the lease expired and the stage
+			// was returned to the queue.
+			stage.Status = core.StatusPending
+			stage.Machine = ""
+			return nil
+		},
+	)
+
+	r := &Runner{
+		Manager: manager.New(
+			nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil,
+			nil, nil, nil, nil, nil, stages, nil, nil, nil, nil, nil,
+		),
+		Machine:   "runner-1",
+		Heartbeat: time.Millisecond,
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	done := make(chan struct{})
+	go func() {
+		r.heartbeat(ctx, stage.ID, cancel)
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatalf("Want heartbeat to stop when the lease is lost")
+	}
+	if ctx.Err() == nil {
+		t.Errorf("Want stage execution cancelled when the lease is lost")
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if stage.Heartbeat == 0 {
+		t.Errorf("Want stage lease renewed")
+	}
+}
+
+// this test verifies the runner does not renew the stage
+// lease when leases are disabled.
+func TestHeartbeat_Disabled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	r := &Runner{Machine: "runner-1"}
+	r.heartbeat(ctx, 1, cancel)
+	if ctx.Err() != nil {
+		t.Errorf("Want stage execution not cancelled")
+	}
+}
+
+// this test verifies the runner identifies its machine when
+// polling the queue, which registers the runner node with the
+// server.
+func TestPoll_RegisterNode(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	nodes := mock.NewMockNodeStore(controller)
+	nodes.EXPECT().FindName(gomock.Any(), "runner-1").Return(nil, sql.ErrNoRows)
+	nodes.EXPECT().Create(gomock.Any(), gomock.Any()).Do(func(ctx context.Context, node *core.Node) {
+		if got, want := node.Name, "runner-1"; got != want {
+			t.Errorf("Want node name %q, got %q", want, got)
+		}
+		if got, want := node.State, core.NodeStateActive; got != want {
+			t.Errorf("Want node state %q, got %q", want, got)
+		}
+	}).Return(nil)
+
+	scheduler := mock.NewMockScheduler(controller)
+	scheduler.EXPECT().Request(gomock.Any(), gomock.Any()).Do(func(ctx context.Context, filter core.Filter) {
+		if got, want := filter.Machine, "runner-1"; got != want {
+			t.Errorf("Want queue filtered by machine %q, got %q", want, got)
+		}
+	}).Return(nil, nil)
+
+	r := &Runner{
+		Manager: manager.New(
+			nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nodes,
+			nil, scheduler, nil, nil, nil, nil, nil, nil, nil, nil, nil,
+		),
+		Machine: "runner-1",
+		OS:      "linux",
+		Arch:    "amd64",
+	}
+	if err := r.poll(context.Background()); err != nil {
+		t.Error(err)
+	}
+}
+
+// this test verifies a runner on a cordoned node does not
+// receive stages from the queue.
+func TestPoll_Cordoned(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	node := &core.Node{
+		Name:     "runner-1",
+		State:    core.NodeStateActive,
+		Cordoned: true,
+	}
+	nodes := mock.NewMockNodeStore(controller)
+	nodes.EXPECT().FindName(gomock.Any(), "runner-1").Return(node, nil)
+	nodes.EXPECT().Update(gomock.Any(), node).Return(nil)
+
+	// the scheduler is not expected to be called, since
+	// cordoned nodes do not receive new stages.
+	scheduler := mock.NewMockScheduler(controller)
+
+	r := &Runner{
+		Manager: manager.New(
+			nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nodes,
+			nil, scheduler, nil, nil, nil, nil, nil, nil, nil, nil, nil,
+		),
+		Machine: "runner-1",
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	if err := r.poll(ctx); err != context.DeadlineExceeded {
+		t.Errorf("Want poll to block until the context deadline, got %v", err)
+	}
+}
@@ -0,0 +1,55 @@
+// Copyright 2019 Drone.IO Inc. All rights reserved.
+// Use of this source code is governed by the Drone Non-Commercial License
+// that can be found in the LICENSE file.
+
+// +build !oss
+
+package notify
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"mime"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var errSMTPNotConfigured = errors.New("notify: smtp server is not configured")
+
+// sendEmail sends the plain text message to the comma-separated
+// list of recipients using the configured smtp server.
+func (n *notifier) sendEmail(recipients, subject, message string) error {
+	if n.smtp.Host == "" || n.smtp.From == "" {
+		return errSMTPNotConfigured
+	}
+	addrs, err := mail.ParseAddressList(recipients)
+	if err != nil {
+		return err
+	}
+	var to []string
+	for _, addr := range addrs {
+		to = append(to, addr.Address)
+	}
+
+	var auth smtp.Auth
+	if n.smtp.Username != "" {
+		auth = smtp.PlainAuth("", n.smtp.Username, n.smtp.Password, n.smtp.Host)
+	}
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "From: %s\r\n", n.smtp.From)
+	fmt.Fprintf(&buf, "To: %s\r\n", strings.Join(to, ", "))
+	fmt.Fprintf(&buf, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", subject))
+	fmt.Fprintf(&buf, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	fmt.Fprintf(&buf, "MIME-Version: 1.0\r\n")
+	fmt.Fprintf(&buf, "Content-Type: text/plain; charset=utf-8\r\n")
+	fmt.Fprintf(&buf, "\r\n%s\r\n", message)
+
+	addr := net.JoinHostPort(n.smtp.Host, strconv.Itoa(n.smtp.Port))
+	return smtp.SendMail(addr, auth, n.smtp.From, to, buf.Bytes())
+}
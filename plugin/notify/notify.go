@@ -0,0 +1,261 @@
+// Copyright 2019 Drone.IO Inc. All rights reserved.
+// Use of this source code is governed by the Drone Non-Commercial License
+// that can be found in the LICENSE file.
+
+// +build !oss
+
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"text/template"
+	"time"
+
+	"github.com/drone/drone/core"
+
+	"github.com/sirupsen/logrus"
+)
+
+// defaultTemplate is the message template used when the
+// channel does not define a custom template.
+const defaultTemplate = `{{ .Repo.Slug }} build #{{ .Build.Number }} {{ .Build.Status }}` +
+	`{{ if .Failed }} ({{ range $i, $name := .Failed }}{{ if $i }}, {{ end }}{{ $name }}{{ end }}){{ end }}` +
+	`: {{ .Link }}`
+
+// data provides the template data used to render the
+// notification message.
+type data struct {
+	Build  *core.Build      `json:"build"`
+	Repo   *core.Repository `json:"repo"`
+	System *core.System     `json:"system,omitempty"`
+	Failed []string         `json:"failed,omitempty"`
+	Link   string           `json:"link"`
+}
+
+// New returns a new notification sender. The sender posts
+// build status messages to the notification channels of the
+// repository and the repository namespace.
+func New(config Config) core.WebhookSender {
+	return &notifier{
+		channels: config.Channels,
+		system:   config.System,
+		smtp:     config.SMTP,
+	}
+}
+
+type notifier struct {
+	Client   *http.Client
+	channels core.ChannelStore
+	system   *core.System
+	smtp     SMTP
+}
+
+// Send sends the build notification to the matching channels.
+// Notifications are sent in the background so that a slow or
+// unavailable channel does not delay the build.
+func (n *notifier) Send(ctx context.Context, in *core.WebhookData) error {
+	channels, err := n.match(ctx, in)
+	if err != nil {
+		return err
+	}
+	if len(channels) == 0 {
+		return nil
+	}
+	go n.notify(context.Background(), channels, in)
+	return nil
+}
+
+// match returns the repository and namespace channels that
+// subscribe to the build status.
+func (n *notifier) match(ctx context.Context, in *core.WebhookData) ([]*core.Channel, error) {
+	if in.Event != core.WebhookEventBuild || in.Repo == nil || in.Build == nil {
+		return nil, nil
+	}
+	repo, err := n.channels.List(ctx, in.Repo.ID)
+	if err != nil {
+		return nil, err
+	}
+	namespace, err := n.channels.ListNamespace(ctx, in.Repo.Namespace)
+	if err != nil {
+		return nil, err
+	}
+	var channels []*core.Channel
+	for _, channel := range append(repo, namespace...) {
+		if matchStatus(channel.Events, in.Build.Status) {
+			channels = append(channels, channel)
+		}
+	}
+	return channels, nil
+}
+
+// notify sends the notification to each channel and logs
+// any errors.
+func (n *notifier) notify(ctx context.Context, channels []*core.Channel, in *core.WebhookData) {
+	for _, channel := range channels {
+		err := n.send(ctx, channel, in)
+		if err != nil {
+			logrus.WithError(err).
+				WithField("repo", in.Repo.Slug).
+				WithField("build", in.Build.Number).
+				WithField("channel", channel.Name).
+				Warnln("notify: cannot send notification")
+		}
+	}
+}
+
+// send renders the channel message template and sends the
+// notification to the channel.
+func (n *notifier) send(ctx context.Context, channel *core.Channel, in *core.WebhookData) error {
+	ctx, cancel := context.WithTimeout(ctx, time.Minute)
+	defer cancel()
+
+	v := n.data(in)
+	if channel.Kind == core.ChannelHTTP && channel.Template == "" {
+		body, _ := json.Marshal(v)
+		return n.post(ctx, channel.Endpoint, body)
+	}
+	message, err := render(channel.Template, v)
+	if err != nil {
+		return err
+	}
+	switch channel.Kind {
+	case core.ChannelSlack:
+		return n.postSlack(ctx, channel.Endpoint, message)
+	case core.ChannelTeams:
+		return n.postTeams(ctx, channel.Endpoint, message, v)
+	case core.ChannelEmail:
+		return n.sendEmail(channel.Endpoint, subject(v), message)
+	default:
+		return n.post(ctx, channel.Endpoint, []byte(message))
+	}
+}
+
+// data returns the template data for the webhook. The
+// repository webhook secret and signing key are removed
+// since the message template is provided by the user.
+func (n *notifier) data(in *core.WebhookData) *data {
+	repo := *in.Repo
+	repo.Secret = ""
+	repo.Signer = ""
+	v := &data{
+		Build:  in.Build,
+		Repo:   &repo,
+		System: n.system,
+	}
+	for _, stage := range in.Build.Stages {
+		switch stage.Status {
+		case core.StatusFailing, core.StatusError:
+			v.Failed = append(v.Failed, stage.Name)
+		}
+	}
+	if n.system != nil {
+		v.Link = fmt.Sprintf("%s/%s/%d", n.system.Link, in.Repo.Slug, in.Build.Number)
+	}
+	return v
+}
+
+func (n *notifier) postSlack(ctx context.Context, endpoint, message string) error {
+	body, _ := json.Marshal(map[string]string{
+		"text": message,
+	})
+	return n.post(ctx, endpoint, body)
+}
+
+func (n *notifier) postTeams(ctx context.Context, endpoint, message string, v *data) error {
+	body, _ := json.Marshal(map[string]string{
+		"@type":      "MessageCard",
+		"@context":   "https://schema.org/extensions",
+		"summary":    subject(v),
+		"title":      subject(v),
+		"themeColor": color(v.Build.Status),
+		"text":       message,
+	})
+	return n.post(ctx, endpoint, body)
+}
+
+func (n *notifier) post(ctx context.Context, endpoint string, body []byte) error {
+	req, err := http.NewRequest("POST", endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req = req.WithContext(ctx)
+	if json.Valid(body) {
+		req.Header.Set("Content-Type", "application/json")
+	} else {
+		req.Header.Set("Content-Type", "text/plain; charset=utf-8")
+	}
+	res, err := n.client().Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return fmt.Errorf("notify: unexpected status code %d", res.StatusCode)
+	}
+	return nil
+}
+
+func (n *notifier) client() *http.Client {
+	if n.Client == nil {
+		return http.DefaultClient
+	}
+	return n.Client
+}
+
+// helper function returns true if the build status matches
+// the channel events. Channels without events are notified
+// when the build is finished.
+func matchStatus(events []string, status string) bool {
+	if len(events) == 0 {
+		switch status {
+		case core.StatusPassing,
+			core.StatusFailing,
+			core.StatusKilled,
+			core.StatusError:
+			return true
+		}
+		return false
+	}
+	for _, event := range events {
+		if event == status {
+			return true
+		}
+	}
+	return false
+}
+
+// helper function renders the message template.
+func render(text string, v *data) (string, error) {
+	if text == "" {
+		text = defaultTemplate
+	}
+	t, err := template.New("_").Parse(text)
+	if err != nil {
+		return "", err
+	}
+	var buf bytes.Buffer
+	err = t.Execute(&buf, v)
+	return buf.String(), err
+}
+
+// helper function returns the message subject.
+func subject(v *data) string {
+	return fmt.Sprintf("[%s] Build #%d %s", v.Repo.Slug, v.Build.Number, v.Build.Status)
+}
+
+// helper function returns the message card color for the
+// build status.
+func color(status string) string {
+	switch status {
+	case core.StatusPassing:
+		return "2EB886"
+	case core.StatusFailing, core.StatusError, core.StatusKilled:
+		return "A30200"
+	default:
+		return "808080"
+	}
+}
@@ -0,0 +1,329 @@
+// Copyright 2019 Drone.IO Inc. All rights reserved.
+// Use of this source code is governed by the Drone Non-Commercial License
+// that can be found in the LICENSE file.
+
+// +build !oss
+
+package notify
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/drone/drone/core"
+	"github.com/drone/drone/mock"
+
+	"github.com/golang/mock/gomock"
+)
+
+var noContext = context.Background()
+
+var (
+	dummyRepo = &core.Repository{
+		ID:        1,
+		Namespace: "octocat",
+		Slug:      "octocat/hello-world",
+	}
+
+	dummyBuild = &core.Build{
+		Number: 42,
+		Status: core.StatusFailing,
+		Stages: []*core.Stage{
+			{Name: "backend", Status: core.StatusPassing},
+			{Name: "frontend", Status: core.StatusFailing},
+		},
+	}
+
+	dummyWebhook = &core.WebhookData{
+		Event:  core.WebhookEventBuild,
+		Action: core.WebhookActionUpdated,
+		Repo:   dummyRepo,
+		Build:  dummyBuild,
+	}
+
+	dummySystem = &core.System{
+		Link: "https://drone.company.com",
+	}
+)
+
+func TestMatch(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	channels := mock.NewMockChannelStore(controller)
+	channels.EXPECT().List(noContext, dummyRepo.ID).Return([]*core.Channel{
+		{Name: "failures", Events: []string{"failure", "error"}},
+		{Name: "successes", Events: []string{"success"}},
+	}, nil)
+	channels.EXPECT().ListNamespace(noContext, dummyRepo.Namespace).Return([]*core.Channel{
+		{Name: "finished"},
+	}, nil)
+
+	n := New(Config{Channels: channels}).(*notifier)
+	got, err := n.match(noContext, dummyWebhook)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if len(got) != 2 {
+		t.Errorf("Want 2 matching channels, got %d", len(got))
+		return
+	}
+	if got[0].Name != "failures" || got[1].Name != "finished" {
+		t.Errorf("Want failures and finished channels, got %s and %s", got[0].Name, got[1].Name)
+	}
+}
+
+func TestMatch_IgnoreEvent(t *testing.T) {
+	n := New(Config{}).(*notifier)
+	got, err := n.match(noContext, &core.WebhookData{
+		Event:  core.WebhookEventRepo,
+		Action: core.WebhookActionEnabled,
+		Repo:   dummyRepo,
+	})
+	if err != nil {
+		t.Error(err)
+	}
+	if len(got) != 0 {
+		t.Errorf("Want no matching channels for repository events")
+	}
+}
+
+func TestMatchStatus(t *testing.T) {
+	tests := []struct {
+		events []string
+		status string
+		want   bool
+	}{
+		{nil, core.StatusPassing, true},
+		{nil, core.StatusFailing, true},
+		{nil, core.StatusKilled, true},
+		{nil, core.StatusPending, false},
+		{nil, core.StatusRunning, false},
+		{[]string{"failure"}, core.StatusFailing, true},
+		{[]string{"failure"}, core.StatusPassing, false},
+		{[]string{"pending"}, core.StatusPending, true},
+	}
+	for i, test := range tests {
+		if got := matchStatus(test.events, test.status); got != test.want {
+			t.Errorf("Want matched %v at index %d", test.want, i)
+		}
+	}
+}
+
+// this test verifies that the repository secret and signing
+// key are not exposed to the message template.
+func TestRender_Sanitized(t *testing.T) {
+	repo := *dummyRepo
+	repo.Secret = "correct-horse-battery-staple"
+	repo.Signer = "0f3c7a2e"
+
+	n := New(Config{}).(*notifier)
+	got, err := render("{{ .Repo.Secret }}{{ .Repo.Signer }}", n.data(&core.WebhookData{
+		Event: core.WebhookEventBuild,
+		Repo:  &repo,
+		Build: dummyBuild,
+	}))
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if got != "" {
+		t.Errorf("Want secret and signer render empty, got %q", got)
+	}
+	if repo.Secret == "" || repo.Signer == "" {
+		t.Errorf("Want stored repository unchanged")
+	}
+}
+
+func TestSend_Slack(t *testing.T) {
+	var got map[string]string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&got)
+	}))
+	defer server.Close()
+
+	channel := &core.Channel{Kind: core.ChannelSlack, Endpoint: server.URL}
+	n := New(Config{System: dummySystem}).(*notifier)
+	err := n.send(noContext, channel, dummyWebhook)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	want := "octocat/hello-world build #42 failure (frontend): https://drone.company.com/octocat/hello-world/42"
+	if got["text"] != want {
+		t.Errorf("Want slack message %q, got %q", want, got["text"])
+	}
+}
+
+func TestSend_Teams(t *testing.T) {
+	var got map[string]string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&got)
+	}))
+	defer server.Close()
+
+	channel := &core.Channel{
+		Kind:     core.ChannelTeams,
+		Endpoint: server.URL,
+		Template: "{{ .Repo.Slug }} #{{ .Build.Number }}",
+	}
+	n := New(Config{}).(*notifier)
+	err := n.send(noContext, channel, dummyWebhook)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if got, want := got["@type"], "MessageCard"; got != want {
+		t.Errorf("Want card type %q, got %q", want, got)
+	}
+	if got, want := got["text"], "octocat/hello-world #42"; got != want {
+		t.Errorf("Want card text %q, got %q", want, got)
+	}
+	if got, want := got["themeColor"], "A30200"; got != want {
+		t.Errorf("Want card color %q, got %q", want, got)
+	}
+}
+
+func TestSend_HTTP(t *testing.T) {
+	var got string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		got = string(body)
+	}))
+	defer server.Close()
+
+	channel := &core.Channel{
+		Kind:     core.ChannelHTTP,
+		Endpoint: server.URL,
+		Template: `{{ .Build.Status }}{{ range .Failed }} {{ . }}{{ end }}`,
+	}
+	n := New(Config{}).(*notifier)
+	err := n.send(noContext, channel, dummyWebhook)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if want := "failure frontend"; got != want {
+		t.Errorf("Want body %q, got %q", want, got)
+	}
+}
+
+func TestSend_HTTPError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(500)
+	}))
+	defer server.Close()
+
+	channel := &core.Channel{Kind: core.ChannelHTTP, Endpoint: server.URL}
+	n := New(Config{}).(*notifier)
+	err := n.send(noContext, channel, dummyWebhook)
+	if err == nil {
+		t.Errorf("Want error when the endpoint returns a non-2xx status")
+	}
+}
+
+func TestSend_Email(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	defer listener.Close()
+
+	received := make(chan string, 1)
+	go serveSMTP(listener, received)
+
+	addr := listener.Addr().(*net.TCPAddr)
+	channel := &core.Channel{
+		Kind:     core.ChannelEmail,
+		Endpoint: "Octocat <octocat@github.com>",
+	}
+	n := New(Config{
+		SMTP: SMTP{
+			Host: "127.0.0.1",
+			Port: addr.Port,
+			From: "drone@company.com",
+		},
+	}).(*notifier)
+	err = n.send(noContext, channel, dummyWebhook)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	message := <-received
+	if !strings.Contains(message, "To: octocat@github.com") {
+		t.Errorf("Want message sent to octocat@github.com, got %s", message)
+	}
+	if !strings.Contains(message, "Subject: [octocat/hello-world] Build #42 failure") {
+		t.Errorf("Want message subject, got %s", message)
+	}
+}
+
+func TestSend_EmailNotConfigured(t *testing.T) {
+	channel := &core.Channel{
+		Kind:     core.ChannelEmail,
+		Endpoint: "octocat@github.com",
+	}
+	n := New(Config{}).(*notifier)
+	err := n.send(noContext, channel, dummyWebhook)
+	if err != errSMTPNotConfigured {
+		t.Errorf("Want smtp not configured error, got %v", err)
+	}
+}
+
+// serveSMTP is a minimal smtp server that accepts a single
+// message and writes the message data to the channel.
+func serveSMTP(listener net.Listener, received chan<- string) {
+	conn, err := listener.Accept()
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+
+	reader := bufio.NewReader(conn)
+	reply := func(code int, text string) {
+		conn.Write([]byte(strconv.Itoa(code) + " " + text + "\r\n"))
+	}
+	reply(220, "localhost ESMTP")
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return
+		}
+		switch cmd := strings.ToUpper(strings.TrimSpace(line)); {
+		case strings.HasPrefix(cmd, "EHLO"), strings.HasPrefix(cmd, "HELO"):
+			reply(250, "localhost")
+		case strings.HasPrefix(cmd, "DATA"):
+			reply(354, "end data with <CR><LF>.<CR><LF>")
+			var data strings.Builder
+			for {
+				line, err := reader.ReadString('\n')
+				if err != nil {
+					return
+				}
+				if line == ".\r\n" {
+					break
+				}
+				data.WriteString(line)
+			}
+			received <- data.String()
+			reply(250, "OK")
+		case strings.HasPrefix(cmd, "QUIT"):
+			reply(221, "bye")
+			return
+		default:
+			reply(250, "OK")
+		}
+	}
+}
@@ -0,0 +1,43 @@
+// Copyright 2019 Drone IO, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webhook
+
+import (
+	"context"
+
+	"github.com/drone/drone/core"
+
+	"github.com/hashicorp/go-multierror"
+)
+
+// Combine combines the webhook senders, allowing the system
+// to send webhooks to multiple destinations.
+func Combine(senders ...core.WebhookSender) core.WebhookSender {
+	return &combined{senders}
+}
+
+type combined struct {
+	senders []core.WebhookSender
+}
+
+func (c *combined) Send(ctx context.Context, in *core.WebhookData) error {
+	var result error
+	for _, sender := range c.senders {
+		if err := sender.Send(ctx, in); err != nil {
+			result = multierror.Append(result, err)
+		}
+	}
+	return result
+}
@@ -0,0 +1,62 @@
+// Copyright 2019 Drone.IO Inc. All rights reserved.
+// Use of this source code is governed by the Drone Non-Commercial License
+// that can be found in the LICENSE file.
+
+// +build !oss
+
+package webhook
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/drone/drone/core"
+	"github.com/drone/drone/mock"
+
+	"github.com/golang/mock/gomock"
+)
+
+func TestCombine(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	webhook := &core.WebhookData{
+		Event:  core.WebhookEventUser,
+		Action: core.WebhookActionCreated,
+	}
+
+	a := mock.NewMockWebhookSender(controller)
+	a.EXPECT().Send(noContext, webhook).Return(nil)
+
+	b := mock.NewMockWebhookSender(controller)
+	b.EXPECT().Send(noContext, webhook).Return(nil)
+
+	err := Combine(a, b).Send(noContext, webhook)
+	if err != nil {
+		t.Error(err)
+	}
+}
+
+// this test verifies that an error returned by one sender
+// does not prevent the webhook from being sent by the other
+// senders, and that the error is returned to the caller.
+func TestCombine_Error(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	webhook := &core.WebhookData{
+		Event:  core.WebhookEventUser,
+		Action: core.WebhookActionCreated,
+	}
+
+	a := mock.NewMockWebhookSender(controller)
+	a.EXPECT().Send(noContext, webhook).Return(errors.New("pc load letter"))
+
+	b := mock.NewMockWebhookSender(controller)
+	b.EXPECT().Send(noContext, webhook).Return(nil)
+
+	err := Combine(a, b).Send(noContext, webhook)
+	if err == nil {
+		t.Errorf("Expect error returned from combined senders")
+	}
+}
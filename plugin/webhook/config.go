@@ -14,12 +14,21 @@
 
 package webhook
 
-import "github.com/drone/drone/core"
+import (
+	"time"
+
+	"github.com/drone/drone/core"
+)
 
 // Config provides the webhook configuration.
 type Config struct {
-	Events   []string
-	Endpoint []string
-	Secret   string
-	System   *core.System
+	Events        []string
+	Endpoint      []string
+	Secret        string
+	System        *core.System
+	Deliveries    core.DeliveryStore
+	Subscriptions core.SubscriptionStore
+	Retries       int
+	Backoff       time.Duration
+	Retention     time.Duration
 }
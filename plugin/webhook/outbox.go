@@ -0,0 +1,213 @@
+// Copyright 2019 Drone.IO Inc. All rights reserved.
+// Use of this source code is governed by the Drone Non-Commercial License
+// that can be found in the LICENSE file.
+
+// +build !oss
+
+package webhook
+
+import (
+	"context"
+	"runtime/debug"
+	"sync"
+	"time"
+
+	"github.com/drone/drone/core"
+	"github.com/drone/drone/store/shared/db"
+
+	"github.com/sirupsen/logrus"
+)
+
+// maxBackoff is the maximum delay between delivery attempts.
+const maxBackoff = time.Hour
+
+// claimTimeout is the duration a delivery is reserved by the
+// server attempting the delivery. It must exceed the http
+// request timeout to prevent duplicate deliveries.
+const claimTimeout = time.Minute * 2
+
+// purgeInterval is the interval at which expired deliveries
+// are removed from the datastore.
+const purgeInterval = time.Hour
+
+// Outbox delivers the persisted webhooks to their endpoints,
+// retrying failed deliveries with exponential backoff.
+type Outbox struct {
+	sender        *sender
+	deliveries    core.DeliveryStore
+	subscriptions core.SubscriptionStore
+	retries       int
+	backoff       time.Duration
+	retention     time.Duration
+	purged        time.Time
+}
+
+// NewOutbox returns a new webhook Outbox.
+func NewOutbox(config Config) *Outbox {
+	retries := config.Retries
+	if retries == 0 {
+		retries = 10
+	}
+	backoff := config.Backoff
+	if backoff == 0 {
+		backoff = time.Second * 30
+	}
+	return &Outbox{
+		sender:        newSender(config),
+		deliveries:    config.Deliveries,
+		subscriptions: config.Subscriptions,
+		retries:       retries,
+		backoff:       backoff,
+		retention:     config.Retention,
+	}
+}
+
+// Start starts the outbox.
+func (o *Outbox) Start(ctx context.Context, dur time.Duration) error {
+	ticker := time.NewTicker(dur)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			o.run(ctx)
+		}
+	}
+}
+
+func (o *Outbox) run(ctx context.Context) error {
+	defer func() {
+		// taking the paranoid approach to recover from
+		// a panic that should absolutely never happen.
+		if r := recover(); r != nil {
+			logrus.Errorf("webhook: unexpected panic: %s", r)
+			debug.PrintStack()
+		}
+	}()
+
+	now := time.Now()
+	if o.retention != 0 && now.Sub(o.purged) > purgeInterval {
+		o.purged = now
+		err := o.deliveries.Purge(ctx, now.Add(-o.retention).Unix())
+		if err != nil {
+			logrus.WithError(err).
+				Errorln("webhook: cannot purge deliveries")
+		}
+	}
+
+	deliveries, err := o.deliveries.ListPending(ctx, now.Unix())
+	if err != nil {
+		logrus.WithError(err).
+			Errorln("webhook: cannot list pending deliveries")
+		return err
+	}
+
+	// deliveries are grouped by endpoint and delivered to each
+	// endpoint concurrently, so that a slow or unavailable
+	// endpoint does not delay delivery to other endpoints.
+	// Deliveries to the same endpoint are sent in order.
+	endpoints := map[string][]*core.Delivery{}
+	for _, delivery := range deliveries {
+		endpoints[delivery.Endpoint] = append(endpoints[delivery.Endpoint], delivery)
+	}
+
+	var wg sync.WaitGroup
+	for _, list := range endpoints {
+		wg.Add(1)
+		go func(list []*core.Delivery) {
+			defer wg.Done()
+			for _, delivery := range list {
+				o.deliver(ctx, delivery)
+			}
+		}(list)
+	}
+	wg.Wait()
+	return nil
+}
+
+// deliver attempts to deliver the webhook and records the
+// attempt. A failed delivery is re-scheduled with exponential
+// backoff until the maximum number of attempts is reached.
+func (o *Outbox) deliver(ctx context.Context, delivery *core.Delivery) error {
+	logger := logrus.
+		WithField("delivery.id", delivery.ID).
+		WithField("delivery.event", delivery.Event).
+		WithField("delivery.endpoint", delivery.Endpoint)
+
+	// the delivery is claimed before it is sent to prevent
+	// other servers from delivering the same webhook.
+	delivery.Next = time.Now().Add(claimTimeout).Unix()
+	err := o.deliveries.Update(ctx, delivery)
+	if err == db.ErrOptimisticLock {
+		logger.Debugln("webhook: delivery claimed by another server")
+		return nil
+	}
+	if err != nil {
+		logger.WithError(err).Errorln("webhook: cannot claim delivery")
+		return err
+	}
+
+	// repository webhooks are signed with the secret of
+	// the subscription. If the subscription was deleted the
+	// delivery is abandoned.
+	endpoint, secret := delivery.Endpoint, o.sender.Secret
+	if delivery.SubscriptionID != 0 {
+		subscription, err := o.subscriptions.Find(ctx, delivery.SubscriptionID)
+		if err != nil {
+			logger.WithError(err).
+				Warnln("webhook: cannot find subscription, delivery abandoned")
+			delivery.Status = core.DeliveryFailed
+			delivery.Updated = time.Now().Unix()
+			return o.deliveries.Update(ctx, delivery)
+		}
+		endpoint, secret = subscription.Endpoint, subscription.Secret
+	}
+
+	attempt := o.sender.send(
+		endpoint,
+		secret,
+		delivery.Event,
+		[]byte(delivery.Payload),
+	)
+	attempt.DeliveryID = delivery.ID
+	err = o.deliveries.CreateAttempt(ctx, attempt)
+	if err != nil {
+		logger.WithError(err).Warnln("webhook: cannot record delivery attempt")
+	}
+
+	delivery.Attempts++
+	delivery.Updated = time.Now().Unix()
+	switch {
+	case attempt.Error == "":
+		logger.Debugln("webhook: delivery succeeded")
+		delivery.Status = core.DeliverySuccess
+	case delivery.Attempts >= o.retries:
+		logger.WithField("error", attempt.Error).
+			Warnln("webhook: delivery failed, no retries remaining")
+		delivery.Status = core.DeliveryFailed
+	default:
+		logger.WithField("error", attempt.Error).
+			Debugln("webhook: delivery failed, will retry")
+		delivery.Next = time.Now().Add(backoff(o.backoff, delivery.Attempts)).Unix()
+	}
+	err = o.deliveries.Update(ctx, delivery)
+	if err != nil {
+		logger.WithError(err).Errorln("webhook: cannot update delivery")
+	}
+	return err
+}
+
+// backoff returns the delay before the next delivery attempt,
+// doubling the base delay for each failed attempt.
+func backoff(base time.Duration, attempts int) time.Duration {
+	delay := base
+	for i := 1; i < attempts; i++ {
+		delay = delay * 2
+		if delay >= maxBackoff {
+			return maxBackoff
+		}
+	}
+	return delay
+}
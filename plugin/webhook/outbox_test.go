@@ -0,0 +1,209 @@
+// Copyright 2019 Drone.IO Inc. All rights reserved.
+// Use of this source code is governed by the Drone Non-Commercial License
+// that can be found in the LICENSE file.
+
+// +build !oss
+
+package webhook
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/drone/drone/core"
+	"github.com/drone/drone/mock"
+	"github.com/drone/drone/store/shared/db"
+
+	"github.com/golang/mock/gomock"
+	"github.com/h2non/gock"
+)
+
+func TestOutbox_Send(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	webhook := &core.WebhookData{
+		Event:  core.WebhookEventUser,
+		Action: core.WebhookActionCreated,
+		User:   &core.User{Login: "octocat"},
+	}
+
+	deliveries := mock.NewMockDeliveryStore(controller)
+	deliveries.EXPECT().Create(noContext, gomock.Any()).Return(nil).Times(2)
+
+	config := Config{
+		Endpoint:   []string{"https://company.com/hooks", "https://example.com/hooks"},
+		Secret:     "GMEuUHQfmrMRsseWxi9YlIeBtn9lm6im",
+		Deliveries: deliveries,
+	}
+	err := New(config).Send(noContext, webhook)
+	if err != nil {
+		t.Error(err)
+	}
+}
+
+func TestOutbox_Deliver(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+	defer gock.Off()
+
+	gock.New("https://company.com").
+		Post("/hooks").
+		MatchHeader("X-Drone-Event", "user").
+		Reply(200).
+		BodyString("ok")
+
+	delivery := &core.Delivery{
+		ID:       1,
+		Event:    core.WebhookEventUser,
+		Endpoint: "https://company.com/hooks",
+		Payload:  `{"event":"user"}`,
+		Status:   core.DeliveryPending,
+	}
+
+	deliveries := mock.NewMockDeliveryStore(controller)
+	deliveries.EXPECT().Update(noContext, delivery).Return(nil).Times(2)
+	deliveries.EXPECT().CreateAttempt(noContext, gomock.Any()).Do(func(_ interface{}, attempt *core.DeliveryAttempt) {
+		if got, want := attempt.Code, 200; got != want {
+			t.Errorf("Want status code %d, got %d", want, got)
+		}
+		if got, want := attempt.Response, "ok"; got != want {
+			t.Errorf("Want response %q, got %q", want, got)
+		}
+	}).Return(nil)
+
+	outbox := NewOutbox(Config{Deliveries: deliveries})
+	outbox.deliver(noContext, delivery)
+
+	if got, want := delivery.Status, core.DeliverySuccess; got != want {
+		t.Errorf("Want status %s, got %s", want, got)
+	}
+	if got, want := delivery.Attempts, 1; got != want {
+		t.Errorf("Want attempts %d, got %d", want, got)
+	}
+	if gock.IsPending() {
+		t.Errorf("Unfinished requests")
+	}
+}
+
+func TestOutbox_Retry(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+	defer gock.Off()
+
+	gock.New("https://company.com").
+		Post("/hooks").
+		Reply(500)
+
+	delivery := &core.Delivery{
+		ID:       1,
+		Endpoint: "https://company.com/hooks",
+		Status:   core.DeliveryPending,
+	}
+
+	deliveries := mock.NewMockDeliveryStore(controller)
+	deliveries.EXPECT().Update(noContext, delivery).Return(nil).Times(2)
+	deliveries.EXPECT().CreateAttempt(noContext, gomock.Any()).Return(nil)
+
+	before := time.Now()
+	outbox := NewOutbox(Config{Deliveries: deliveries, Backoff: time.Minute})
+	outbox.deliver(noContext, delivery)
+
+	if got, want := delivery.Status, core.DeliveryPending; got != want {
+		t.Errorf("Want status %s, got %s", want, got)
+	}
+	if delivery.Next < before.Add(time.Minute).Unix() {
+		t.Errorf("Want delivery re-scheduled with backoff")
+	}
+}
+
+func TestOutbox_Exhausted(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+	defer gock.Off()
+
+	gock.New("https://company.com").
+		Post("/hooks").
+		Reply(500)
+
+	delivery := &core.Delivery{
+		ID:       1,
+		Endpoint: "https://company.com/hooks",
+		Status:   core.DeliveryPending,
+		Attempts: 2,
+	}
+
+	deliveries := mock.NewMockDeliveryStore(controller)
+	deliveries.EXPECT().Update(noContext, delivery).Return(nil).Times(2)
+	deliveries.EXPECT().CreateAttempt(noContext, gomock.Any()).Return(nil)
+
+	outbox := NewOutbox(Config{Deliveries: deliveries, Retries: 3})
+	outbox.deliver(noContext, delivery)
+
+	if got, want := delivery.Status, core.DeliveryFailed; got != want {
+		t.Errorf("Want status %s, got %s", want, got)
+	}
+}
+
+func TestOutbox_Claimed(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	delivery := &core.Delivery{
+		ID:       1,
+		Endpoint: "https://company.com/hooks",
+		Status:   core.DeliveryPending,
+	}
+
+	deliveries := mock.NewMockDeliveryStore(controller)
+	deliveries.EXPECT().Update(noContext, delivery).Return(db.ErrOptimisticLock)
+
+	outbox := NewOutbox(Config{Deliveries: deliveries})
+	if err := outbox.deliver(noContext, delivery); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestOutbox_SubscriptionDeleted(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	delivery := &core.Delivery{
+		ID:             1,
+		SubscriptionID: 2,
+		Endpoint:       "https://octocat.com/hooks",
+		Status:         core.DeliveryPending,
+	}
+
+	deliveries := mock.NewMockDeliveryStore(controller)
+	deliveries.EXPECT().Update(noContext, delivery).Return(nil).Times(2)
+
+	subscriptions := mock.NewMockSubscriptionStore(controller)
+	subscriptions.EXPECT().Find(noContext, delivery.SubscriptionID).Return(nil, sql.ErrNoRows)
+
+	outbox := NewOutbox(Config{Deliveries: deliveries, Subscriptions: subscriptions})
+	outbox.deliver(noContext, delivery)
+
+	if got, want := delivery.Status, core.DeliveryFailed; got != want {
+		t.Errorf("Want status %s, got %s", want, got)
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	tests := []struct {
+		attempts int
+		want     time.Duration
+	}{
+		{1, time.Second * 30},
+		{2, time.Minute},
+		{3, time.Minute * 2},
+		{8, time.Hour},
+		{20, time.Hour},
+	}
+	for _, test := range tests {
+		if got, want := backoff(time.Second*30, test.attempts), test.want; got != want {
+			t.Errorf("Want backoff %s for attempt %d, got %s", want, test.attempts, got)
+		}
+	}
+}
@@ -12,15 +12,25 @@ import (
 	"crypto/sha256"
 	"encoding/base64"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
 	"net/http"
 	"path/filepath"
+	"strings"
 	"time"
 
 	"github.com/drone/drone/core"
 
 	"github.com/99designs/httpsignatures-go"
+	"github.com/hashicorp/go-multierror"
 )
 
+// maxResponse is the maximum number of response body bytes
+// recorded for each delivery attempt.
+const maxResponse = 1000
+
 // required http headers
 var headers = []string{
 	"date",
@@ -34,11 +44,17 @@ var signer = httpsignatures.NewSigner(
 
 // New returns a new Webhook sender.
 func New(config Config) core.WebhookSender {
+	return newSender(config)
+}
+
+func newSender(config Config) *sender {
 	return &sender{
-		Events:    config.Events,
-		Endpoints: config.Endpoint,
-		Secret:    config.Secret,
-		System:    config.System,
+		Deliveries:    config.Deliveries,
+		Subscriptions: config.Subscriptions,
+		Events:        config.Events,
+		Endpoints:     config.Endpoint,
+		Secret:        config.Secret,
+		System:        config.System,
 	}
 }
 
@@ -48,20 +64,32 @@ type payload struct {
 }
 
 type sender struct {
-	Client    *http.Client
-	Events    []string
-	Endpoints []string
-	Secret    string
-	System    *core.System
+	Client        *http.Client
+	Deliveries    core.DeliveryStore
+	Subscriptions core.SubscriptionStore
+	Events        []string
+	Endpoints     []string
+	Secret        string
+	System        *core.System
+}
+
+// target is a webhook endpoint and its signing secret.
+type target struct {
+	subscription int64
+	endpoint     string
+	secret       string
 }
 
-// Send sends the JSON encoded webhook to the global
-// HTTP endpoints.
+// Send sends the JSON encoded webhook to the global HTTP
+// endpoints and to the endpoints subscribed to the repository.
+// If a delivery store is configured the webhook is persisted
+// for each endpoint and delivered asynchronously by the outbox.
 func (s *sender) Send(ctx context.Context, in *core.WebhookData) error {
-	if len(s.Endpoints) == 0 {
-		return nil
+	targets, err := s.targets(ctx, in)
+	if err != nil {
+		return err
 	}
-	if s.match(in.Event, in.Action) == false {
+	if len(targets) == 0 {
 		return nil
 	}
 	wrapper := payload{
@@ -69,8 +97,31 @@ func (s *sender) Send(ctx context.Context, in *core.WebhookData) error {
 		System:      s.System,
 	}
 	data, _ := json.Marshal(wrapper)
-	for _, endpoint := range s.Endpoints {
-		err := s.send(endpoint, s.Secret, in.Event, data)
+
+	if s.Deliveries == nil {
+		var result error
+		for _, target := range targets {
+			attempt := s.send(target.endpoint, target.secret, in.Event, data)
+			if attempt.Error != "" {
+				result = multierror.Append(result, errors.New(attempt.Error))
+			}
+		}
+		return result
+	}
+
+	now := time.Now().Unix()
+	for _, target := range targets {
+		err := s.Deliveries.Create(ctx, &core.Delivery{
+			SubscriptionID: target.subscription,
+			Event:          in.Event,
+			Action:         in.Action,
+			Endpoint:       target.endpoint,
+			Payload:        string(data),
+			Status:         core.DeliveryPending,
+			Next:           now,
+			Created:        now,
+			Updated:        now,
+		})
 		if err != nil {
 			return err
 		}
@@ -78,7 +129,52 @@ func (s *sender) Send(ctx context.Context, in *core.WebhookData) error {
 	return nil
 }
 
-func (s *sender) send(endpoint, secret, event string, data []byte) error {
+// targets returns the global endpoints and the repository
+// subscriptions that match the webhook event and action.
+func (s *sender) targets(ctx context.Context, in *core.WebhookData) ([]*target, error) {
+	var targets []*target
+	if match(s.Events, in.Event, in.Action) {
+		for _, endpoint := range s.Endpoints {
+			targets = append(targets, &target{
+				endpoint: endpoint,
+				secret:   s.Secret,
+			})
+		}
+	}
+	if s.Subscriptions == nil || in.Repo == nil || in.Repo.ID == 0 {
+		return targets, nil
+	}
+	subscriptions, err := s.Subscriptions.List(ctx, in.Repo.ID)
+	if err != nil {
+		return nil, err
+	}
+	for _, subscription := range subscriptions {
+		if match(subscription.Events, in.Event, in.Action) {
+			targets = append(targets, &target{
+				subscription: subscription.ID,
+				endpoint:     subscription.Endpoint,
+				secret:       subscription.Secret,
+			})
+		}
+	}
+	return targets, nil
+}
+
+// send posts the webhook to the endpoint and returns the
+// delivery attempt, including the response status code,
+// latency and an excerpt of the response body.
+func (s *sender) send(endpoint, secret, event string, data []byte) *core.DeliveryAttempt {
+	attempt := &core.DeliveryAttempt{
+		Created: time.Now().Unix(),
+	}
+	err := s.post(attempt, endpoint, secret, event, data)
+	if err != nil {
+		attempt.Error = err.Error()
+	}
+	return attempt
+}
+
+func (s *sender) post(attempt *core.DeliveryAttempt, endpoint, secret, event string, data []byte) error {
 	ctx := context.Background()
 	ctx, cancel := context.WithTimeout(ctx, time.Minute)
 	defer cancel()
@@ -94,19 +190,31 @@ func (s *sender) send(endpoint, secret, event string, data []byte) error {
 	req.Header.Add("Content-Type", "application/json")
 	req.Header.Add("Digest", "SHA-256="+digest(data))
 	req.Header.Add("Date", time.Now().UTC().Format(http.TimeFormat))
-	err = signer.SignRequest("hmac-key", s.Secret, req)
+	err = signer.SignRequest("hmac-key", secret, req)
 	if err != nil {
 		return err
 	}
+	start := time.Now()
 	res, err := s.client().Do(req)
-	if res != nil {
-		res.Body.Close()
+	attempt.Latency = time.Since(start).Milliseconds()
+	if err != nil {
+		return err
 	}
-	return err
+	defer res.Body.Close()
+
+	body, _ := ioutil.ReadAll(io.LimitReader(res.Body, maxResponse))
+	attempt.Code = res.StatusCode
+	attempt.Response = strings.ToValidUTF8(string(body), "")
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return fmt.Errorf("webhook: unexpected status code %d", res.StatusCode)
+	}
+	return nil
 }
 
-func (s *sender) match(event, action string) bool {
-	if len(s.Events) == 0 {
+// match returns true if the event and action match one of
+// the glob patterns, or if no patterns are provided.
+func match(patterns []string, event, action string) bool {
+	if len(patterns) == 0 {
 		return true
 	}
 	var name string
@@ -116,7 +224,7 @@ func (s *sender) match(event, action string) bool {
 	case action != "":
 		name = event + ":" + action
 	}
-	for _, pattern := range s.Events {
+	for _, pattern := range patterns {
 		if ok, _ := filepath.Match(pattern, name); ok {
 			return true
 		}
@@ -18,6 +18,7 @@ package webhook
 
 import (
 	"context"
+	"time"
 
 	"github.com/drone/drone/core"
 )
@@ -32,3 +33,16 @@ type noop struct{}
 func (noop) Send(context.Context, *core.WebhookData) error {
 	return nil
 }
+
+// Outbox is a no-op webhook outbox.
+type Outbox struct{}
+
+// NewOutbox returns a no-op webhook Outbox.
+func NewOutbox(Config) *Outbox {
+	return new(Outbox)
+}
+
+// Start starts the outbox.
+func (*Outbox) Start(context.Context, time.Duration) error {
+	return nil
+}
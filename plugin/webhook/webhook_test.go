@@ -12,8 +12,10 @@ import (
 	"testing"
 
 	"github.com/drone/drone/core"
+	"github.com/drone/drone/mock"
 
 	"github.com/99designs/httpsignatures-go"
+	"github.com/golang/mock/gomock"
 	"github.com/h2non/gock"
 )
 
@@ -154,10 +156,67 @@ func TestWebhook_Match(t *testing.T) {
 		},
 	}
 	for i, test := range tests {
-		s := new(sender)
-		s.Events = test.events
-		if s.match(test.event, test.action) != test.matched {
+		if match(test.events, test.event, test.action) != test.matched {
 			t.Errorf("Expect matched %v at index %d", test.matched, i)
 		}
 	}
 }
+
+func TestWebhook_Subscriptions(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+	defer gock.Off()
+
+	webhook := &core.WebhookData{
+		Event:  core.WebhookEventBuild,
+		Action: core.WebhookActionCreated,
+		Repo:   &core.Repository{ID: 1, Slug: "octocat/hello-world"},
+	}
+
+	matchSignature := func(r *http.Request, _ *gock.Request) (bool, error) {
+		signature, err := httpsignatures.FromRequest(r)
+		if err != nil {
+			return false, err
+		}
+		return signature.IsValid("correct-horse-battery-staple", r), nil
+	}
+
+	gock.New("https://octocat.com").
+		Post("/hooks").
+		AddMatcher(matchSignature).
+		MatchHeader("X-Drone-Event", "build").
+		Reply(200)
+
+	subscriptions := []*core.Subscription{
+		{
+			ID:       1,
+			Endpoint: "https://octocat.com/hooks",
+			Secret:   "correct-horse-battery-staple",
+			Events:   []string{"build:*"},
+		},
+		{
+			ID:       2,
+			Endpoint: "https://octocat.com/repos",
+			Secret:   "correct-horse-battery-staple",
+			Events:   []string{"repo:*"},
+		},
+	}
+
+	mockSubscriptions := mock.NewMockSubscriptionStore(controller)
+	mockSubscriptions.EXPECT().List(noContext, webhook.Repo.ID).Return(subscriptions, nil)
+
+	config := Config{
+		Events:        []string{"user:*"},
+		Endpoint:      []string{"https://company.com/hooks"},
+		Secret:        "GMEuUHQfmrMRsseWxi9YlIeBtn9lm6im",
+		Subscriptions: mockSubscriptions,
+	}
+	err := New(config).Send(noContext, webhook)
+	if err != nil {
+		t.Error(err)
+	}
+
+	if gock.IsPending() {
+		t.Errorf("Unfinished requests")
+	}
+}
@@ -34,16 +34,24 @@ type queue struct {
 	interval time.Duration
 	throttle int
 	store    core.StageStore
+	repos    core.RepositoryStore
+	nodes    core.NodeStore
+	policy   *core.QueuePolicy
+	spaces   map[int64]string
 	workers  map[*worker]struct{}
 	ctx      context.Context
 }
 
 // newQueue returns a new Queue backed by the build datastore.
-func newQueue(store core.StageStore) *queue {
+func newQueue(store core.StageStore, repos core.RepositoryStore, nodes core.NodeStore, policy *core.QueuePolicy) *queue {
 	q := &queue{
 		store:    store,
+		repos:    repos,
+		nodes:    nodes,
+		policy:   policy,
 		globMx:   redisdb.LockErrNoOp{},
 		ready:    make(chan struct{}, 1),
+		spaces:   map[int64]string{},
 		workers:  map[*worker]struct{}{},
 		interval: time.Minute,
 		ctx:      context.Background(),
@@ -94,7 +102,9 @@ func (q *queue) Request(ctx context.Context, params core.Filter) (*core.Stage, e
 		arch:    params.Arch,
 		kernel:  params.Kernel,
 		variant: params.Variant,
+		machine: params.Machine,
 		labels:  params.Labels,
+		taints:  params.Taints,
 		channel: make(chan *core.Stage),
 	}
 	q.Lock()
@@ -138,8 +148,44 @@ func (q *queue) signal(ctx context.Context) error {
 		return err
 	}
 
+	pending := pendingItems(items)
+	cordoned := q.cordoned(ctx)
+	shares := newShares(q.policy, q.namespaces(ctx, items))
+	for _, item := range items {
+		if item.Status == core.StatusRunning || item.Machine != "" {
+			shares.add(item)
+		}
+	}
+
 	q.Lock()
 	defer q.Unlock()
+	for len(pending) > 0 && len(q.workers) > 0 {
+		// the pending stages are re-sorted after every
+		// dispatch so that the namespace usage, which
+		// changes with every dispatched stage, is
+		// reflected in the fair-share ordering.
+		shares.sort(pending)
+
+		index := -1
+		for i, item := range pending {
+			if q.dispatch(item, cordoned) {
+				shares.add(item)
+				index = i
+				break
+			}
+		}
+		if index == -1 {
+			break
+		}
+		pending = append(pending[:index], pending[index+1:]...)
+	}
+	return nil
+}
+
+// pendingItems returns the stages that are waiting to be
+// dispatched and are not blocked by concurrency limits.
+func pendingItems(items []*core.Stage) []*core.Stage {
+	var pending []*core.Stage
 	for _, item := range items {
 		if item.Status == core.StatusRunning {
 			continue
@@ -161,62 +207,138 @@ func (q *queue) signal(ctx context.Context) error {
 		if shouldThrottle(item, items, item.LimitRepo) == true {
 			continue
 		}
+		pending = append(pending, item)
+	}
+	return pending
+}
 
-	loop:
-		for w := range q.workers {
-			// the worker must match the resource kind and type
-			if !matchResource(w.kind, w.typ, item.Kind, item.Type) {
+// dispatch sends the stage to the first matching worker. It
+// returns false if no worker is able to execute the stage. The
+// caller must hold the queue lock.
+func (q *queue) dispatch(item *core.Stage, cordoned map[string]struct{}) bool {
+	for w := range q.workers {
+		// the worker must not be running on a cordoned node.
+		if _, ok := cordoned[w.machine]; ok {
+			continue
+		}
+
+		// the worker must match the resource kind and type
+		if !matchResource(w.kind, w.typ, item.Kind, item.Type) {
+			continue
+		}
+
+		if w.os != "" || w.arch != "" || w.variant != "" || w.kernel != "" {
+			// the worker is platform-specific. check to ensure
+			// the queue item matches the worker platform.
+			if w.os != item.OS {
 				continue
 			}
-
-			if w.os != "" || w.arch != "" || w.variant != "" || w.kernel != "" {
-				// the worker is platform-specific. check to ensure
-				// the queue item matches the worker platform.
-				if w.os != item.OS {
-					continue
-				}
-				if w.arch != item.Arch {
-					continue
-				}
-				// if the pipeline defines a variant it must match
-				// the worker variant (e.g. arm6, arm7, etc).
-				if item.Variant != "" && item.Variant != w.variant {
-					continue
-				}
-				// if the pipeline defines a kernel version it must match
-				// the worker kernel version (e.g. 1709, 1803).
-				if item.Kernel != "" && item.Kernel != w.kernel {
-					continue
-				}
+			if w.arch != item.Arch {
+				continue
 			}
-
-			if len(item.Labels) > 0 || len(w.labels) > 0 {
-				if !checkLabels(item.Labels, w.labels) {
-					continue
-				}
+			// if the pipeline defines a variant it must match
+			// the worker variant (e.g. arm6, arm7, etc).
+			if item.Variant != "" && item.Variant != w.variant {
+				continue
 			}
-
-			// // the queue has 60 seconds to ack the item, otherwise
-			// // it is eligible for processing by another worker.
-			// // item.Expires = time.Now().Add(time.Minute).Unix()
-			// err := q.store.Update(ctx, item)
-
-			// if err != nil {
-			// 	log.Ctx(ctx).Warn().
-			// 		Err(err).
-			// 		Int64("build_id", item.BuildID).
-			// 		Int64("stage_id", item.ID).
-			// 		Msg("cannot update queue item")
-			// 	continue
-			// }
-			select {
-			case w.channel <- item:
-				delete(q.workers, w)
-				break loop
+			// if the pipeline defines a kernel version it must match
+			// the worker kernel version (e.g. 1709, 1803).
+			if item.Kernel != "" && item.Kernel != w.kernel {
+				continue
 			}
 		}
+
+		// the worker labels must satisfy the stage label
+		// selector, and the stage must tolerate the worker
+		// taints.
+		if !checkLabels(item.Labels, w.labels) {
+			continue
+		}
+		if !checkTaints(item.Tolerates, w.taints) {
+			continue
+		}
+
+		// // the queue has 60 seconds to ack the item, otherwise
+		// // it is eligible for processing by another worker.
+		// // item.Expires = time.Now().Add(time.Minute).Unix()
+		// err := q.store.Update(ctx, item)
+
+		// if err != nil {
+		// 	log.Ctx(ctx).Warn().
+		// 		Err(err).
+		// 		Int64("build_id", item.BuildID).
+		// 		Int64("stage_id", item.ID).
+		// 		Msg("cannot update queue item")
+		// 	continue
+		// }
+		select {
+		case w.channel <- item:
+			delete(q.workers, w)
+			return true
+		}
 	}
-	return nil
+	return false
+}
+
+// namespaces returns the repository namespace for each of the
+// provided stages, indexed by repository id. The namespaces are
+// only resolved when fair-share ordering is enabled.
+func (q *queue) namespaces(ctx context.Context, items []*core.Stage) map[int64]string {
+	if q.policy == nil || q.policy.FairShare == false {
+		return nil
+	}
+
+	missing := map[int64]struct{}{}
+	q.Lock()
+	for _, item := range items {
+		if _, ok := q.spaces[item.RepoID]; !ok {
+			missing[item.RepoID] = struct{}{}
+		}
+	}
+	q.Unlock()
+
+	// the repository namespace is cached because it
+	// is not expected to change while the repository
+	// has stages in the queue.
+	for id := range missing {
+		repo, err := q.repos.Find(ctx, id)
+		if err != nil {
+			// the stage is grouped with stages from
+			// unknown namespaces until the repository
+			// can be resolved.
+			continue
+		}
+		q.Lock()
+		q.spaces[id] = repo.Namespace
+		q.Unlock()
+	}
+
+	spaces := map[int64]string{}
+	q.Lock()
+	for _, item := range items {
+		spaces[item.RepoID] = q.spaces[item.RepoID]
+	}
+	q.Unlock()
+	return spaces
+}
+
+// cordoned returns the names of the runner nodes that are
+// cordoned and should not receive new stages.
+func (q *queue) cordoned(ctx context.Context) map[string]struct{} {
+	if q.nodes == nil {
+		return nil
+	}
+	nodes, err := q.nodes.List(ctx)
+	if err != nil {
+		return nil
+	}
+	cordoned := map[string]struct{}{}
+	for _, node := range nodes {
+		if node.Cordoned {
+			cordoned[node.Name] = struct{}{}
+		}
+	}
+	return cordoned
 }
 
 func (q *queue) start() error {
@@ -239,7 +361,9 @@ type worker struct {
 	arch    string
 	kernel  string
 	variant string
+	machine string
 	labels  map[string]string
+	taints  map[string]string
 	channel chan *core.Stage
 }
 
@@ -247,18 +371,6 @@ type counter struct {
 	counts map[string]int
 }
 
-func checkLabels(a, b map[string]string) bool {
-	if len(a) != len(b) {
-		return false
-	}
-	for k, v := range a {
-		if w, ok := b[k]; !ok || v != w {
-			return false
-		}
-	}
-	return true
-}
-
 func withinLimits(stage *core.Stage, siblings []*core.Stage) bool {
 	if stage.Limit == 0 {
 		return true
@@ -33,7 +33,7 @@ func TestQueue(t *testing.T) {
 	store.EXPECT().ListIncomplete(ctx).Return(items[1:], nil).Times(1)
 	store.EXPECT().ListIncomplete(ctx).Return(items[2:], nil).Times(1)
 
-	q := newQueue(store)
+	q := newQueue(store, nil, nil, nil)
 	for _, item := range items {
 		next, err := q.Request(ctx, core.Filter{OS: "linux", Arch: "amd64"})
 		if err != nil {
@@ -46,6 +46,46 @@ func TestQueue(t *testing.T) {
 	}
 }
 
+func TestQueueCordoned(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	items := []*core.Stage{
+		{ID: 1, OS: "linux", Arch: "amd64"},
+	}
+	nodes := []*core.Node{
+		{Name: "runner-1", Cordoned: true},
+		{Name: "runner-2"},
+	}
+
+	ctx := context.Background()
+	store := mock.NewMockStageStore(controller)
+	store.EXPECT().ListIncomplete(ctx).Return(items, nil).AnyTimes()
+	nodez := mock.NewMockNodeStore(controller)
+	nodez.EXPECT().List(ctx).Return(nodes, nil).AnyTimes()
+
+	q := newQueue(store, nil, nodez, nil)
+
+	cordoned := &worker{machine: "runner-1", channel: make(chan *core.Stage, 1)}
+	q.Lock()
+	q.workers[cordoned] = struct{}{}
+	q.Unlock()
+
+	next, err := q.Request(ctx, core.Filter{OS: "linux", Arch: "amd64", Machine: "runner-2"})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if got, want := next.ID, items[0].ID; got != want {
+		t.Errorf("Want stage %d dispatched to runner-2, got %d", want, got)
+	}
+	select {
+	case <-cordoned.channel:
+		t.Errorf("Want no stage dispatched to cordoned runner-1")
+	default:
+	}
+}
+
 func TestQueueCancel(t *testing.T) {
 	controller := gomock.NewController(t)
 	defer controller.Finish()
@@ -54,7 +94,7 @@ func TestQueueCancel(t *testing.T) {
 	store := mock.NewMockStageStore(controller)
 	store.EXPECT().ListIncomplete(ctx).Return(nil, nil)
 
-	q := newQueue(store)
+	q := newQueue(store, nil, nil, nil)
 	q.ctx = ctx
 
 	var wg sync.WaitGroup
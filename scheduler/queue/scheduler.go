@@ -14,16 +14,13 @@
 
 package queue
 
-import (
-	"context"
-	"errors"
-)
+import "context"
 
 type scheduler struct {
 	*queue
 	*canceller
 }
 
-func (d scheduler) Stats(context.Context) (interface{}, error) {
-	return nil, errors.New("not implemented")
+func (d scheduler) Stats(ctx context.Context) (interface{}, error) {
+	return d.queue.stats(ctx)
 }
@@ -24,16 +24,16 @@ import (
 )
 
 // New creates a new scheduler.
-func New(store core.StageStore, r redisdb.RedisDB) core.Scheduler {
+func New(store core.StageStore, repos core.RepositoryStore, nodes core.NodeStore, policy *core.QueuePolicy, r redisdb.RedisDB) core.Scheduler {
 	if r == nil {
 		return scheduler{
-			queue:     newQueue(store),
+			queue:     newQueue(store, repos, nodes, policy),
 			canceller: newCanceller(),
 		}
 	}
 
 	sched := schedulerRedis{
-		queue:          newQueue(store),
+		queue:          newQueue(store, repos, nodes, policy),
 		cancellerRedis: newCancellerRedis(r),
 	}
 
@@ -16,16 +16,13 @@
 
 package queue
 
-import (
-	"context"
-	"errors"
-)
+import "context"
 
 type schedulerRedis struct {
 	*queue
 	*cancellerRedis
 }
 
-func (d schedulerRedis) Stats(context.Context) (interface{}, error) {
-	return nil, errors.New("not implemented")
+func (d schedulerRedis) Stats(ctx context.Context) (interface{}, error) {
+	return d.queue.stats(ctx)
 }
@@ -0,0 +1,122 @@
+// Copyright 2019 Drone IO, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package queue
+
+import "strings"
+
+// selector operators. A stage label value is parsed as a
+// selector expression when it starts with an operator,
+// otherwise the runner label must equal the value.
+//
+//	region: eu              runner label equals eu
+//	gpu:    in (a100, h100) runner label is a100 or h100
+//	disk:   notin (hdd)     runner label is missing or not hdd
+//	ssd:    exists          runner label is present
+//	spot:   !exists         runner label is missing
+const (
+	opIn        = "in"
+	opNotIn     = "notin"
+	opExists    = "exists"
+	opNotExists = "!exists"
+)
+
+// requirement is a single parsed selector expression.
+type requirement struct {
+	op     string
+	values []string
+}
+
+// parseRequirement parses the selector expression.
+func parseRequirement(expr string) requirement {
+	s := strings.TrimSpace(expr)
+	switch s {
+	case opExists, opNotExists:
+		return requirement{op: s}
+	}
+	for _, op := range []string{opNotIn, opIn} {
+		if !strings.HasPrefix(s, op) {
+			continue
+		}
+		rest := strings.TrimSpace(strings.TrimPrefix(s, op))
+		if !strings.HasPrefix(rest, "(") || !strings.HasSuffix(rest, ")") {
+			continue
+		}
+		rest = strings.TrimSuffix(strings.TrimPrefix(rest, "("), ")")
+		var values []string
+		for _, v := range strings.Split(rest, ",") {
+			if v = strings.TrimSpace(v); v != "" {
+				values = append(values, v)
+			}
+		}
+		return requirement{op: op, values: values}
+	}
+	return requirement{values: []string{expr}}
+}
+
+// matches returns true if the label value, and whether or
+// not the label is present, satisfies the requirement.
+func (r requirement) matches(value string, ok bool) bool {
+	switch r.op {
+	case opExists:
+		return ok
+	case opNotExists:
+		return !ok
+	case opIn:
+		return ok && contains(r.values, value)
+	case opNotIn:
+		return !ok || !contains(r.values, value)
+	default:
+		return ok && value == r.values[0]
+	}
+}
+
+// checkLabels returns true if the runner labels satisfy every
+// expression in the stage label selector. Runner labels that
+// are not referenced by the selector are ignored.
+func checkLabels(selector, labels map[string]string) bool {
+	for k, expr := range selector {
+		v, ok := labels[k]
+		if !parseRequirement(expr).matches(v, ok) {
+			return false
+		}
+	}
+	return true
+}
+
+// checkTaints returns true if the stage tolerates every runner
+// taint. A taint is tolerated when the stage defines a
+// toleration for the taint key and the taint value satisfies
+// the toleration expression, for example `gpu: exists`.
+func checkTaints(tolerates, taints map[string]string) bool {
+	for k, v := range taints {
+		expr, ok := tolerates[k]
+		if !ok {
+			return false
+		}
+		if !parseRequirement(expr).matches(v, true) {
+			return false
+		}
+	}
+	return true
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
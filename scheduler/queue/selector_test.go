@@ -0,0 +1,78 @@
+// Copyright 2019 Drone.IO Inc. All rights reserved.
+// Use of this source code is governed by the Drone Non-Commercial License
+// that can be found in the LICENSE file.
+
+// +build !oss
+
+package queue
+
+import "testing"
+
+func TestCheckLabels(t *testing.T) {
+	labels := map[string]string{
+		"gpu":    "none",
+		"region": "eu",
+		"disk":   "ssd",
+	}
+	tests := []struct {
+		selector map[string]string
+		want     bool
+	}{
+		// no selector matches any runner
+		{nil, true},
+		// subset match
+		{map[string]string{"region": "eu"}, true},
+		{map[string]string{"region": "eu", "disk": "ssd"}, true},
+		{map[string]string{"region": "us"}, false},
+		{map[string]string{"zone": "a"}, false},
+		// in
+		{map[string]string{"region": "in (us, eu)"}, true},
+		{map[string]string{"region": "in(us,eu)"}, true},
+		{map[string]string{"region": "in (us, ap)"}, false},
+		{map[string]string{"zone": "in (a, b)"}, false},
+		// notin
+		{map[string]string{"disk": "notin (hdd)"}, true},
+		{map[string]string{"disk": "notin (hdd, ssd)"}, false},
+		{map[string]string{"zone": "notin (a)"}, true},
+		// exists
+		{map[string]string{"gpu": "exists"}, true},
+		{map[string]string{"zone": "exists"}, false},
+		// not exists
+		{map[string]string{"zone": "!exists"}, true},
+		{map[string]string{"gpu": "!exists"}, false},
+		// malformed expressions are compared as values
+		{map[string]string{"region": "in eu"}, false},
+	}
+	for i, test := range tests {
+		if got, want := checkLabels(test.selector, labels), test.want; got != want {
+			t.Errorf("Unexpected results at index %d", i)
+		}
+	}
+}
+
+func TestCheckTaints(t *testing.T) {
+	tests := []struct {
+		tolerates map[string]string
+		taints    map[string]string
+		want      bool
+	}{
+		// untainted runner admits any stage
+		{nil, nil, true},
+		{map[string]string{"gpu": "exists"}, nil, true},
+		// tainted runner rejects stages without tolerations
+		{nil, map[string]string{"gpu": "true"}, false},
+		{map[string]string{"arm": "exists"}, map[string]string{"gpu": "true"}, false},
+		// tainted runner admits stages with tolerations
+		{map[string]string{"gpu": "true"}, map[string]string{"gpu": "true"}, true},
+		{map[string]string{"gpu": "exists"}, map[string]string{"gpu": "true"}, true},
+		{map[string]string{"gpu": "in (a100, h100)"}, map[string]string{"gpu": "h100"}, true},
+		{map[string]string{"gpu": "false"}, map[string]string{"gpu": "true"}, false},
+		// every taint must be tolerated
+		{map[string]string{"gpu": "exists"}, map[string]string{"gpu": "true", "spot": "true"}, false},
+	}
+	for i, test := range tests {
+		if got, want := checkTaints(test.tolerates, test.taints), test.want; got != want {
+			t.Errorf("Unexpected results at index %d", i)
+		}
+	}
+}
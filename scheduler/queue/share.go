@@ -0,0 +1,80 @@
+// Copyright 2019 Drone IO, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package queue
+
+import (
+	"sort"
+
+	"github.com/drone/drone/core"
+)
+
+// shares tracks the number of running stages per namespace
+// and per repository, which is used to order pending stages
+// with equal priority.
+type shares struct {
+	policy *core.QueuePolicy
+	spaces map[int64]string
+	counts map[string]int
+	repos  map[int64]int
+}
+
+func newShares(policy *core.QueuePolicy, spaces map[int64]string) *shares {
+	return &shares{
+		policy: policy,
+		spaces: spaces,
+		counts: map[string]int{},
+		repos:  map[int64]int{},
+	}
+}
+
+// add records the stage as running.
+func (s *shares) add(stage *core.Stage) {
+	s.counts[s.spaces[stage.RepoID]]++
+	s.repos[stage.RepoID]++
+}
+
+// usage returns the number of running stages for the stage
+// namespace, divided by the namespace weight.
+func (s *shares) usage(stage *core.Stage) float64 {
+	namespace := s.spaces[stage.RepoID]
+	return float64(s.counts[namespace]) /
+		float64(s.policy.Weight(namespace))
+}
+
+// sort sorts the stages by priority and, if enabled, by the
+// weighted namespace usage. The sort is stable and preserves
+// the datastore order (stage id) for stages that are equal.
+func (s *shares) sort(stages []*core.Stage) {
+	sort.SliceStable(stages, func(i, j int) bool {
+		return s.less(stages[i], stages[j])
+	})
+}
+
+func (s *shares) less(a, b *core.Stage) bool {
+	if a.Priority != b.Priority {
+		return a.Priority > b.Priority
+	}
+	if s.policy == nil || s.policy.FairShare == false {
+		return false
+	}
+	if x, y := s.usage(a), s.usage(b); x != y {
+		return x < y
+	}
+	// if the namespace usage is equal, stages from the
+	// repository with fewer running stages are preferred
+	// so that a single busy repository cannot starve the
+	// other repositories in the same namespace.
+	return s.repos[a.RepoID] < s.repos[b.RepoID]
+}
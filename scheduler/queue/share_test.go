@@ -0,0 +1,106 @@
+// Copyright 2019 Drone.IO Inc. All rights reserved.
+// Use of this source code is governed by the Drone Non-Commercial License
+// that can be found in the LICENSE file.
+
+// +build !oss
+
+package queue
+
+import (
+	"context"
+	"testing"
+
+	"github.com/drone/drone/core"
+	"github.com/drone/drone/mock"
+
+	"github.com/golang/mock/gomock"
+)
+
+func TestQueuePriority(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	items := []*core.Stage{
+		{ID: 1, OS: "linux", Arch: "amd64", Priority: 0},
+		{ID: 2, OS: "linux", Arch: "amd64", Priority: 10},
+		{ID: 3, OS: "linux", Arch: "amd64", Priority: 5},
+	}
+
+	ctx := context.Background()
+	store := mock.NewMockStageStore(controller)
+	store.EXPECT().ListIncomplete(ctx).Return(items, nil).Times(1)
+	store.EXPECT().ListIncomplete(ctx).Return([]*core.Stage{items[0], items[2]}, nil).Times(1)
+	store.EXPECT().ListIncomplete(ctx).Return(items[:1], nil).Times(1)
+
+	q := newQueue(store, nil, nil, nil)
+	for _, want := range []int64{2, 3, 1} {
+		next, err := q.Request(ctx, core.Filter{OS: "linux", Arch: "amd64"})
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if got := next.ID; got != want {
+			t.Errorf("Want stage %d, got %d", want, got)
+		}
+	}
+}
+
+func TestSharesSort(t *testing.T) {
+	policy := &core.QueuePolicy{
+		FairShare: true,
+		Weights:   map[string]int{"octocat": 2},
+	}
+	spaces := map[int64]string{
+		1: "octocat",
+		2: "octocat",
+		3: "spaceghost",
+	}
+	shares := newShares(policy, spaces)
+
+	// two stages from the octocat namespace are running,
+	// however, the namespace has a weight of two.
+	shares.add(&core.Stage{ID: 1, RepoID: 1})
+	shares.add(&core.Stage{ID: 2, RepoID: 1})
+	// two stages from the spaceghost namespace are running
+	// with the default weight of one.
+	shares.add(&core.Stage{ID: 3, RepoID: 3})
+	shares.add(&core.Stage{ID: 8, RepoID: 3})
+
+	stages := []*core.Stage{
+		{ID: 4, RepoID: 1},
+		{ID: 5, RepoID: 3},
+		{ID: 6, RepoID: 2},
+		{ID: 7, RepoID: 3, Priority: 1},
+	}
+	shares.sort(stages)
+
+	// stage 7 has the highest priority. stage 6 and stage 4
+	// are from the octocat namespace, which has the lowest
+	// weighted usage, but stage 6 is from a repository without
+	// running stages.
+	want := []int64{7, 6, 4, 5}
+	for i, stage := range stages {
+		if got := stage.ID; got != want[i] {
+			t.Errorf("Want stage %d at index %d, got %d", want[i], i, got)
+		}
+	}
+}
+
+func TestSharesSort_Disabled(t *testing.T) {
+	shares := newShares(nil, nil)
+	shares.add(&core.Stage{ID: 1, RepoID: 1})
+
+	stages := []*core.Stage{
+		{ID: 2, RepoID: 1},
+		{ID: 3, RepoID: 2},
+		{ID: 4, RepoID: 1, Priority: 1},
+	}
+	shares.sort(stages)
+
+	want := []int64{4, 2, 3}
+	for i, stage := range stages {
+		if got := stage.ID; got != want[i] {
+			t.Errorf("Want stage %d at index %d, got %d", want[i], i, got)
+		}
+	}
+}
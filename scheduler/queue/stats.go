@@ -0,0 +1,115 @@
+// Copyright 2019 Drone IO, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package queue
+
+import (
+	"context"
+
+	"github.com/drone/drone/core"
+)
+
+type (
+	// stats provides a snapshot of the queue, including the
+	// order in which pending stages will be dispatched.
+	stats struct {
+		Paused     bool                   `json:"paused"`
+		Workers    int                    `json:"workers"`
+		FairShare  bool                   `json:"fair_share"`
+		Pending    []*statsItem           `json:"pending"`
+		Namespaces map[string]*statsUsage `json:"namespaces,omitempty"`
+	}
+
+	// statsItem provides the position of a pending stage.
+	statsItem struct {
+		Position  int    `json:"position"`
+		ID        int64  `json:"id"`
+		RepoID    int64  `json:"repo_id"`
+		BuildID   int64  `json:"build_id"`
+		Name      string `json:"name"`
+		Namespace string `json:"namespace,omitempty"`
+		Priority  int    `json:"priority"`
+	}
+
+	// statsUsage provides the fair-share usage of a namespace.
+	statsUsage struct {
+		Running int `json:"running"`
+		Pending int `json:"pending"`
+		Weight  int `json:"weight"`
+	}
+)
+
+func (q *queue) stats(ctx context.Context) (*stats, error) {
+	items, err := q.store.ListIncomplete(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	q.Lock()
+	out := &stats{
+		Paused:     q.paused,
+		Workers:    len(q.workers),
+		FairShare:  q.policy != nil && q.policy.FairShare,
+		Pending:    []*statsItem{},
+		Namespaces: map[string]*statsUsage{},
+	}
+	q.Unlock()
+
+	spaces := q.namespaces(ctx, items)
+	shares := newShares(q.policy, spaces)
+	for _, item := range items {
+		if item.Status == core.StatusRunning || item.Machine != "" {
+			shares.add(item)
+			out.usage(spaces[item.RepoID], q.policy).Running++
+		}
+	}
+
+	// the pending stages are ordered the same way the
+	// queue orders them when dispatching, assuming every
+	// stage is dispatched to an available runner.
+	pending := pendingItems(items)
+	for len(pending) > 0 {
+		shares.sort(pending)
+		next := pending[0]
+		pending = pending[1:]
+		shares.add(next)
+
+		out.usage(spaces[next.RepoID], q.policy).Pending++
+		out.Pending = append(out.Pending, &statsItem{
+			Position:  len(out.Pending) + 1,
+			ID:        next.ID,
+			RepoID:    next.RepoID,
+			BuildID:   next.BuildID,
+			Name:      next.Name,
+			Namespace: spaces[next.RepoID],
+			Priority:  next.Priority,
+		})
+	}
+
+	if out.FairShare == false {
+		out.Namespaces = nil
+	}
+	return out, nil
+}
+
+// helper function returns the usage for the namespace,
+// creating the entry if it does not exist.
+func (s *stats) usage(namespace string, policy *core.QueuePolicy) *statsUsage {
+	usage, ok := s.Namespaces[namespace]
+	if !ok {
+		usage = &statsUsage{Weight: policy.Weight(namespace)}
+		s.Namespaces[namespace] = usage
+	}
+	return usage
+}
@@ -87,10 +87,22 @@ func (s *service) CancelPending(ctx context.Context, repo *core.Repository, buil
 	// 	return nil
 	// }
 
-	switch build.Event {
+	var policy *core.CancelPolicy
+	if repo != nil {
+		policy = repo.CancelPolicy
+	}
+
+	switch {
+	// if the repository defines an auto-cancel policy, the
+	// policy determines which builds are cancelled.
+	case policy != nil:
+		if !policy.Match(build) {
+			return nil
+		}
 	// on the push and pull request builds can be automatically
 	// cancelled by the system.
-	case core.EventPush, core.EventPullRequest:
+	case build.Event == core.EventPush,
+		build.Event == core.EventPullRequest:
 	default:
 		return nil
 	}
@@ -110,6 +122,22 @@ func (s *service) CancelPending(ctx context.Context, repo *core.Repository, buil
 			continue
 		}
 
+		// ignore builds that do not include a pipeline
+		// that matches the auto-cancel policy.
+		if policy != nil && len(policy.Pipelines) != 0 {
+			stages, err := s.stages.List(ctx, item.Build.ID)
+			if err != nil {
+				result = multierror.Append(result, err)
+				continue
+			}
+			if !matchPipeline(policy, stages) {
+				continue
+			}
+		}
+
+		// record the newer build that superseded the
+		// cancelled build.
+		item.Build.Superseded = build.Number
 		err := s.cancel(ctx, repo, item.Build, core.StatusSkipped)
 		if err != nil {
 			result = multierror.Append(result, err)
@@ -105,6 +105,136 @@ func TestCancel(t *testing.T) {
 	}
 }
 
+// this test verifies that builds matching the auto-cancel
+// policy are cancelled for all events, and record the build
+// that superseded them.
+func TestCancelPending_Policy(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	repo := &core.Repository{
+		ID:     1,
+		UserID: 1,
+		Slug:   "octocat/hello-world",
+		CancelPolicy: &core.CancelPolicy{
+			Events:    []string{core.EventTag},
+			Pipelines: []string{"test"},
+		},
+	}
+	build := &core.Build{ID: 3, RepoID: 1, Number: 3, Event: core.EventTag, Ref: "refs/tags/v1.0.0"}
+	older := &core.Build{ID: 2, RepoID: 1, Number: 2, Event: core.EventTag, Ref: "refs/tags/v1.0.0", Status: core.StatusRunning}
+	ignored := &core.Build{ID: 1, RepoID: 1, Number: 1, Event: core.EventTag, Ref: "refs/tags/v1.0.0", Status: core.StatusPending}
+
+	incomplete := []*core.Repository{
+		{ID: 1, CancelPolicy: repo.CancelPolicy, Build: older},
+		{ID: 1, CancelPolicy: repo.CancelPolicy, Build: ignored},
+	}
+
+	repos := mock.NewMockRepositoryStore(controller)
+	repos.EXPECT().ListIncomplete(gomock.Any()).Return(incomplete, nil)
+
+	stages := mock.NewMockStageStore(controller)
+	stages.EXPECT().List(gomock.Any(), older.ID).Return([]*core.Stage{{Name: "test"}}, nil)
+	stages.EXPECT().List(gomock.Any(), ignored.ID).Return([]*core.Stage{{Name: "deploy"}}, nil)
+	stages.EXPECT().ListSteps(gomock.Any(), older.ID).Return(nil, nil)
+
+	builds := mock.NewMockBuildStore(controller)
+	builds.EXPECT().Update(gomock.Any(), older).Return(nil)
+
+	users := mock.NewMockUserStore(controller)
+	users.EXPECT().Find(gomock.Any(), repo.UserID).Return(mockUser, nil)
+
+	status := mock.NewMockStatusService(controller)
+	status.EXPECT().Send(gomock.Any(), mockUser, gomock.Any()).Return(nil)
+
+	events := mock.NewMockPubsub(controller)
+	events.EXPECT().Publish(gomock.Any(), gomock.Any()).Return(nil)
+
+	webhook := mock.NewMockWebhookSender(controller)
+	webhook.EXPECT().Send(gomock.Any(), gomock.Any()).Return(nil)
+
+	scheduler := mock.NewMockScheduler(controller)
+	scheduler.EXPECT().Cancel(gomock.Any(), older.ID).Return(nil)
+
+	s := New(builds, events, repos, scheduler, stages, status, nil, users, webhook)
+	err := s.CancelPending(noContext, repo, build)
+	if err != nil {
+		t.Error(err)
+	}
+	if got, want := older.Status, core.StatusSkipped; got != want {
+		t.Errorf("Want build status %s, got %s", want, got)
+	}
+	if got, want := older.Superseded, build.Number; got != want {
+		t.Errorf("Want build superseded by %d, got %d", want, got)
+	}
+	if got, want := ignored.Status, core.StatusPending; got != want {
+		t.Errorf("Want build status %s, got %s", want, got)
+	}
+}
+
+// this test verifies that a promotion does not cancel a
+// running promotion of the same reference to a different
+// target environment.
+func TestCancelPending_PolicyPromote(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	repo := &core.Repository{
+		ID: 1,
+		CancelPolicy: &core.CancelPolicy{
+			Events: []string{core.EventPromote},
+		},
+	}
+	build := &core.Build{ID: 2, RepoID: 1, Number: 2, Event: core.EventPromote, Ref: "refs/heads/master", Deploy: "staging"}
+	older := &core.Build{ID: 1, RepoID: 1, Number: 1, Event: core.EventPromote, Ref: "refs/heads/master", Deploy: "production", Status: core.StatusRunning}
+
+	incomplete := []*core.Repository{
+		{ID: 1, CancelPolicy: repo.CancelPolicy, Build: older},
+	}
+
+	repos := mock.NewMockRepositoryStore(controller)
+	repos.EXPECT().ListIncomplete(gomock.Any()).Return(incomplete, nil)
+
+	s := New(nil, nil, repos, nil, nil, nil, nil, nil, nil)
+	err := s.CancelPending(noContext, repo, build)
+	if err != nil {
+		t.Error(err)
+	}
+	if got, want := older.Status, core.StatusRunning; got != want {
+		t.Errorf("Want build status %s, got %s", want, got)
+	}
+}
+
+// this test verifies that a policy without events does not
+// cancel promotions and rollbacks.
+func TestCancelPending_PolicyDefaultEvents(t *testing.T) {
+	repo := &core.Repository{
+		CancelPolicy: &core.CancelPolicy{},
+	}
+	for _, event := range []string{core.EventPromote, core.EventRollback} {
+		s := new(service)
+		err := s.CancelPending(noContext, repo, &core.Build{Event: event, Ref: "refs/heads/master"})
+		if err != nil {
+			t.Errorf("Expect cancel skipped for event type %s", event)
+		}
+	}
+}
+
+// this test verifies that builds are not cancelled for
+// references excluded by the auto-cancel policy.
+func TestCancelPending_PolicyExclude(t *testing.T) {
+	repo := &core.Repository{
+		CancelPolicy: &core.CancelPolicy{
+			Exclude: []string{"refs/heads/main"},
+		},
+	}
+	s := new(service)
+	err := s.CancelPending(noContext, repo, &core.Build{Event: core.EventPush, Ref: "refs/heads/main"})
+	if err != nil {
+		t.Errorf("Expect cancel skipped for excluded reference")
+	}
+}
+
 var (
 	mockRepo = &core.Repository{
 		ID:        1,
@@ -28,7 +28,10 @@ func match(build *core.Build, with *core.Repository) bool {
 		return false
 	}
 
-	if with.CancelRunning == true {
+	// running builds are cancelled if enabled for the
+	// repository, or if the repository defines an
+	// auto-cancel policy.
+	if with.CancelRunning == true || with.CancelPolicy != nil {
 		if with.Build.Status != core.StatusRunning && with.Build.Status != core.StatusPending {
 			return false
 		}
@@ -48,5 +51,24 @@ func match(build *core.Build, with *core.Repository) bool {
 	if with.Build.Ref != build.Ref {
 		return false
 	}
+	// filter out deployments to a different target
+	// environment, and builds of a different cron job.
+	if with.Build.Deploy != build.Deploy {
+		return false
+	}
+	if with.Build.Cron != build.Cron {
+		return false
+	}
 	return true
 }
+
+// helper function returns true if any of the stages match the
+// pipeline names in the auto-cancel policy.
+func matchPipeline(policy *core.CancelPolicy, stages []*core.Stage) bool {
+	for _, stage := range stages {
+		if policy.MatchPipeline(stage.Name) {
+			return true
+		}
+	}
+	return false
+}
@@ -61,6 +61,30 @@ func TestMatchPendingBuild(t *testing.T) {
 			}},
 			want: false,
 		},
+		// does not match deployment target
+		{
+			build: &core.Build{RepoID: 1, Number: 2, Event: core.EventPromote, Ref: "refs/heads/master", Deploy: "staging"},
+			repo: &core.Repository{ID: 1, Build: &core.Build{
+				Number: 1,
+				Status: core.StatusPending,
+				Event:  core.EventPromote,
+				Ref:    "refs/heads/master",
+				Deploy: "production",
+			}},
+			want: false,
+		},
+		// does not match cron job
+		{
+			build: &core.Build{RepoID: 1, Number: 2, Event: core.EventCron, Ref: "refs/heads/master", Cron: "nightly"},
+			repo: &core.Repository{ID: 1, Build: &core.Build{
+				Number: 1,
+				Status: core.StatusPending,
+				Event:  core.EventCron,
+				Ref:    "refs/heads/master",
+				Cron:   "hourly",
+			}},
+			want: false,
+		},
 
 		//
 		// successful matches
@@ -169,6 +193,18 @@ func TestMatchRunningBuilds(t *testing.T) {
 			}, CancelRunning: true},
 			want: true,
 		},
+		// running builds match if an auto-cancel policy
+		// is defined for the repository.
+		{
+			build: &core.Build{RepoID: 1, Number: 2, Event: core.EventTag, Ref: "refs/tags/v1.0.0"},
+			repo: &core.Repository{ID: 1, Build: &core.Build{
+				Number: 1,
+				Status: core.StatusRunning,
+				Event:  core.EventTag,
+				Ref:    "refs/tags/v1.0.0",
+			}, CancelPolicy: &core.CancelPolicy{}},
+			want: true,
+		},
 	}
 
 	for i, test := range tests {
@@ -177,3 +213,20 @@ func TestMatchRunningBuilds(t *testing.T) {
 		}
 	}
 }
+
+func TestMatchPipeline(t *testing.T) {
+	stages := []*core.Stage{{Name: "build"}, {Name: "test-integration"}}
+	tests := []struct {
+		policy *core.CancelPolicy
+		want   bool
+	}{
+		{&core.CancelPolicy{}, true},
+		{&core.CancelPolicy{Pipelines: []string{"test-*"}}, true},
+		{&core.CancelPolicy{Pipelines: []string{"deploy"}}, false},
+	}
+	for i, test := range tests {
+		if got, want := matchPipeline(test.policy, stages), test.want; got != want {
+			t.Errorf("Want match %v at index %d, got %v", want, i, got)
+		}
+	}
+}
@@ -0,0 +1,259 @@
+// Copyright 2019 Drone IO, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package chatops
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/drone/drone/core"
+	buildsapi "github.com/drone/drone/handler/api/repos/builds"
+	stagesapi "github.com/drone/drone/handler/api/repos/builds/stages"
+	"github.com/drone/drone/handler/api/request"
+	"github.com/drone/drone/logger"
+	"github.com/drone/go-scm/scm"
+
+	"github.com/go-chi/chi"
+)
+
+// New returns a new CommandService that maps chatops commands
+// to the existing retry, cancel, approve and promote handlers.
+func New(
+	client *scm.Client,
+	renew core.Renewer,
+	approvals core.ApprovalStore,
+	builds core.BuildStore,
+	environments core.EnvironmentStore,
+	orgs core.OrganizationService,
+	perms core.PermStore,
+	repos core.RepositoryStore,
+	scheduler core.Scheduler,
+	stages core.StageStore,
+	steps core.StepStore,
+	status core.StatusService,
+	system *core.System,
+	triggerer core.Triggerer,
+	users core.UserStore,
+	webhooks core.WebhookSender,
+) core.CommandService {
+	return &service{
+		client: client,
+		renew:  renew,
+		builds: builds,
+		perms:  perms,
+		stages: stages,
+		users:  users,
+		link:   system.Link,
+		handlers: map[string]http.HandlerFunc{
+			core.CommandRetry:   buildsapi.HandleRetry(repos, builds, triggerer),
+			core.CommandCancel:  buildsapi.HandleCancel(users, repos, builds, stages, steps, status, scheduler, webhooks),
+			core.CommandApprove: stagesapi.HandleApprove(repos, builds, stages, approvals, orgs, scheduler),
+			core.CommandPromote: buildsapi.HandlePromote(repos, builds, environments, triggerer),
+		},
+	}
+}
+
+type service struct {
+	client   *scm.Client
+	renew    core.Renewer
+	builds   core.BuildStore
+	perms    core.PermStore
+	stages   core.StageStore
+	users    core.UserStore
+	link     string
+	handlers map[string]http.HandlerFunc
+}
+
+func (s *service) Exec(ctx context.Context, repo *core.Repository, hook *core.Hook) error {
+	command := core.ParseCommand(hook.Message)
+	if command == nil {
+		return nil
+	}
+	log := logger.FromContext(ctx).
+		WithField("command", command.Name).
+		WithField("sender", hook.Sender)
+
+	// commands posted by senders that are not registered users
+	// with write access to the repository are ignored without a
+	// reply, so that anyone able to comment on a pull request
+	// cannot use the repository owner's account to post comments.
+	user, perm, err := s.authorize(ctx, repo, hook.Sender)
+	if err != nil {
+		log.WithError(err).Debugln("chatops: ignore command from unauthorized sender")
+		return nil
+	}
+
+	reply, err := s.exec(ctx, repo, hook, command, user, perm)
+	if err != nil {
+		log.WithError(err).Warnln("chatops: cannot execute command")
+		reply = fmt.Sprintf("Cannot execute `%s`: %s", command, err)
+	} else {
+		log.Debugln("chatops: command executed")
+	}
+	return s.reply(ctx, repo, scm.ExtractPullRequest(hook.Ref), reply)
+}
+
+// authorize returns the sender and the sender's repository
+// permissions. An error is returned if the sender is not a
+// registered user with write access to the repository.
+func (s *service) authorize(ctx context.Context, repo *core.Repository, sender string) (*core.User, *core.Perm, error) {
+	user, err := s.users.FindLogin(ctx, sender)
+	if err != nil {
+		return nil, nil, errUnknownUser
+	}
+	if user.Admin {
+		return user, &core.Perm{Read: true, Write: true, Admin: true}, nil
+	}
+	perm, err := s.perms.Find(ctx, repo.UID, user.ID)
+	if err != nil || !perm.Write {
+		return nil, nil, errWriteAccess
+	}
+	return user, perm, nil
+}
+
+// exec executes the command and returns the reply message.
+func (s *service) exec(ctx context.Context, repo *core.Repository, hook *core.Hook, command *core.Command, user *core.User, perm *core.Perm) (string, error) {
+	handler, ok := s.handlers[command.Name]
+	if !ok {
+		return "", errUnknownCommand
+	}
+	// the sender must have the same repository access that
+	// is required by the api endpoint. Approvals require
+	// admin access, all other commands require write access.
+	if command.Name == core.CommandApprove && !perm.Admin {
+		return "", errAdminAccess
+	}
+	build, err := s.builds.FindRef(ctx, repo.ID, hook.Ref)
+	if err != nil {
+		return "", errBuildNotFound
+	}
+
+	params := map[string]string{
+		"owner":  repo.Namespace,
+		"name":   repo.Name,
+		"number": fmt.Sprint(build.Number),
+	}
+	method := "POST"
+	query := url.Values{}
+	switch command.Name {
+	case core.CommandCancel:
+		method = "DELETE"
+	case core.CommandApprove:
+		stage, err := s.findStage(ctx, build, command.Arg)
+		if err != nil {
+			return "", err
+		}
+		params["stage"] = fmt.Sprint(stage.Number)
+	case core.CommandPromote:
+		if command.Arg == "" {
+			return "", errMissingTarget
+		}
+		query.Set("target", command.Arg)
+	}
+
+	r, err := http.NewRequest(method, "/?"+query.Encode(), nil)
+	if err != nil {
+		return "", err
+	}
+	c := new(chi.Context)
+	for key, value := range params {
+		c.URLParams.Add(key, value)
+	}
+	ctx = context.WithValue(ctx, chi.RouteCtxKey, c)
+	ctx = request.WithUser(ctx, user)
+	ctx = request.WithRepo(ctx, repo)
+
+	w := newRecorder()
+	handler(w, r.WithContext(ctx))
+	if err := w.err(); err != nil {
+		return "", err
+	}
+	return s.message(repo, build, command, w)
+}
+
+// findStage returns the build stage by number or by name.
+func (s *service) findStage(ctx context.Context, build *core.Build, name string) (*core.Stage, error) {
+	if name == "" {
+		return nil, errMissingStage
+	}
+	stages, err := s.stages.List(ctx, build.ID)
+	if err != nil {
+		return nil, err
+	}
+	for _, stage := range stages {
+		if stage.Name == name || fmt.Sprint(stage.Number) == name {
+			return stage, nil
+		}
+	}
+	return nil, errStageNotFound
+}
+
+// message returns the reply message for the successfully
+// executed command.
+func (s *service) message(repo *core.Repository, prev *core.Build, command *core.Command, w *recorder) (string, error) {
+	switch command.Name {
+	case core.CommandCancel:
+		return fmt.Sprintf("Build %s cancelled.", s.buildLink(repo, prev)), nil
+	case core.CommandApprove:
+		if w.code == http.StatusAccepted {
+			return fmt.Sprintf("Approval of stage `%s` recorded for build %s. Additional approvals are required.",
+				command.Arg, s.buildLink(repo, prev)), nil
+		}
+		return fmt.Sprintf("Stage `%s` of build %s approved.", command.Arg, s.buildLink(repo, prev)), nil
+	}
+	build := new(core.Build)
+	if err := w.decode(build); err != nil {
+		return "", err
+	}
+	if command.Name == core.CommandPromote {
+		return fmt.Sprintf("Build %s promoted to `%s` as build %s.",
+			s.buildLink(repo, prev), command.Arg, s.buildLink(repo, build)), nil
+	}
+	return fmt.Sprintf("Build %s restarted as build %s.",
+		s.buildLink(repo, prev), s.buildLink(repo, build)), nil
+}
+
+// buildLink returns a markdown link to the build.
+func (s *service) buildLink(repo *core.Repository, build *core.Build) string {
+	return fmt.Sprintf("[#%d](%s/%s/%d)", build.Number, s.link, repo.Slug, build.Number)
+}
+
+// reply posts the message to the pull request as a comment,
+// using the credentials of the repository owner.
+func (s *service) reply(ctx context.Context, repo *core.Repository, number int, body string) error {
+	user, err := s.users.Find(ctx, repo.UserID)
+	if err != nil {
+		return err
+	}
+	err = s.renew.Renew(ctx, user, false)
+	if err != nil {
+		return err
+	}
+	ctx = context.WithValue(ctx, scm.TokenKey{}, &scm.Token{
+		Token:   user.Token,
+		Refresh: user.Refresh,
+	})
+	in := &scm.CommentInput{Body: body}
+	_, _, err = s.client.Issues.CreateComment(ctx, repo.Slug, number, in)
+	if err == scm.ErrNotSupported {
+		_, _, err = s.client.PullRequests.CreateComment(ctx, repo.Slug, number, in)
+	}
+	if err == scm.ErrNotSupported {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,210 @@
+// Copyright 2019 Drone.IO Inc. All rights reserved.
+// Use of this source code is governed by the Drone Non-Commercial License
+// that can be found in the LICENSE file.
+
+package chatops
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/drone/drone/core"
+	"github.com/drone/drone/mock"
+	"github.com/drone/go-scm/scm"
+
+	"github.com/golang/mock/gomock"
+)
+
+var noContext = context.Background()
+
+// issueService is a stub issue service that records the
+// pull request comment.
+type issueService struct {
+	scm.IssueService
+	repo   string
+	number int
+	body   string
+}
+
+func (s *issueService) CreateComment(_ context.Context, repo string, number int, in *scm.CommentInput) (*scm.Comment, *scm.Response, error) {
+	s.repo, s.number, s.body = repo, number, in.Body
+	return &scm.Comment{Body: in.Body}, nil, nil
+}
+
+var (
+	dummyRepo = &core.Repository{
+		ID:        1,
+		UID:       "42",
+		UserID:    2,
+		Namespace: "octocat",
+		Name:      "hello-world",
+		Slug:      "octocat/hello-world",
+	}
+	dummyOwner  = &core.User{ID: 2, Login: "octocat", Token: "3da541559"}
+	dummySender = &core.User{ID: 3, Login: "spaceghost"}
+	dummyBuild  = &core.Build{ID: 4, RepoID: 1, Number: 5, Status: core.StatusFailing, Ref: "refs/pull/7/head"}
+)
+
+func TestExec_Retry(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	hook := &core.Hook{
+		Event:   core.EventComment,
+		Ref:     "refs/pull/7/head",
+		Message: "/drone retry",
+		Sender:  dummySender.Login,
+	}
+
+	users := mock.NewMockUserStore(controller)
+	users.EXPECT().FindLogin(gomock.Any(), dummySender.Login).Return(dummySender, nil)
+	users.EXPECT().Find(gomock.Any(), dummyRepo.UserID).Return(dummyOwner, nil)
+
+	perms := mock.NewMockPermStore(controller)
+	perms.EXPECT().Find(gomock.Any(), dummyRepo.UID, dummySender.ID).Return(&core.Perm{Write: true}, nil)
+
+	repos := mock.NewMockRepositoryStore(controller)
+	repos.EXPECT().FindName(gomock.Any(), dummyRepo.Namespace, dummyRepo.Name).Return(dummyRepo, nil)
+
+	builds := mock.NewMockBuildStore(controller)
+	builds.EXPECT().FindRef(gomock.Any(), dummyRepo.ID, hook.Ref).Return(dummyBuild, nil)
+	builds.EXPECT().FindNumber(gomock.Any(), dummyRepo.ID, dummyBuild.Number).Return(dummyBuild, nil)
+
+	triggerer := mock.NewMockTriggerer(controller)
+	triggerer.EXPECT().Trigger(gomock.Any(), dummyRepo, gomock.Any()).DoAndReturn(
+		func(_ context.Context, _ *core.Repository, hook *core.Hook) (*core.Build, error) {
+			if got, want := hook.Trigger, dummySender.Login; got != want {
+				t.Errorf("Want build triggered by %s, got %s", want, got)
+			}
+			return &core.Build{Number: 6}, nil
+		},
+	)
+
+	renewer := mock.NewMockRenewer(controller)
+	renewer.EXPECT().Renew(gomock.Any(), dummyOwner, false).Return(nil)
+
+	issues := new(issueService)
+	client := new(scm.Client)
+	client.Issues = issues
+
+	system := &core.System{Link: "https://drone.company.com"}
+	service := New(client, renewer, nil, builds, nil, nil, perms, repos, nil, nil, nil, nil, system, triggerer, users, nil)
+	err := service.Exec(noContext, dummyRepo, hook)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if got, want := issues.number, 7; got != want {
+		t.Errorf("Want reply to pull request %d, got %d", want, got)
+	}
+	want := "Build [#5](https://drone.company.com/octocat/hello-world/5) restarted as build [#6](https://drone.company.com/octocat/hello-world/6)."
+	if got := issues.body; got != want {
+		t.Errorf("Want reply %q, got %q", want, got)
+	}
+}
+
+// this test verifies that a command posted by a user without
+// write access to the repository is ignored without a reply.
+func TestExec_WriteAccess(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	hook := &core.Hook{
+		Event:   core.EventComment,
+		Ref:     "refs/pull/7/head",
+		Message: "/drone cancel",
+		Sender:  dummySender.Login,
+	}
+
+	users := mock.NewMockUserStore(controller)
+	users.EXPECT().FindLogin(gomock.Any(), dummySender.Login).Return(dummySender, nil)
+
+	perms := mock.NewMockPermStore(controller)
+	perms.EXPECT().Find(gomock.Any(), dummyRepo.UID, dummySender.ID).Return(&core.Perm{Read: true}, nil)
+
+	issues := new(issueService)
+	client := new(scm.Client)
+	client.Issues = issues
+
+	system := &core.System{Link: "https://drone.company.com"}
+	service := New(client, nil, nil, nil, nil, nil, perms, nil, nil, nil, nil, nil, system, nil, users, nil)
+	err := service.Exec(noContext, dummyRepo, hook)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if got := issues.body; got != "" {
+		t.Errorf("Want no reply, got %q", got)
+	}
+}
+
+// this test verifies that a command posted by a sender that
+// is not a registered user is ignored without a reply.
+func TestExec_UnknownUser(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	hook := &core.Hook{
+		Event:   core.EventComment,
+		Ref:     "refs/pull/7/head",
+		Message: "/drone deploy",
+		Sender:  "octocat-fan",
+	}
+
+	users := mock.NewMockUserStore(controller)
+	users.EXPECT().FindLogin(gomock.Any(), hook.Sender).Return(nil, sql.ErrNoRows)
+
+	issues := new(issueService)
+	client := new(scm.Client)
+	client.Issues = issues
+
+	system := &core.System{Link: "https://drone.company.com"}
+	service := New(client, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, system, nil, users, nil)
+	err := service.Exec(noContext, dummyRepo, hook)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if got := issues.body; got != "" {
+		t.Errorf("Want no reply, got %q", got)
+	}
+}
+
+func TestExec_UnknownCommand(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	hook := &core.Hook{
+		Event:   core.EventComment,
+		Ref:     "refs/pull/7/head",
+		Message: "/drone deploy",
+		Sender:  dummySender.Login,
+	}
+
+	users := mock.NewMockUserStore(controller)
+	users.EXPECT().FindLogin(gomock.Any(), dummySender.Login).Return(dummySender, nil)
+	users.EXPECT().Find(gomock.Any(), dummyRepo.UserID).Return(dummyOwner, nil)
+
+	perms := mock.NewMockPermStore(controller)
+	perms.EXPECT().Find(gomock.Any(), dummyRepo.UID, dummySender.ID).Return(&core.Perm{Write: true}, nil)
+
+	renewer := mock.NewMockRenewer(controller)
+	renewer.EXPECT().Renew(gomock.Any(), dummyOwner, false).Return(nil)
+
+	issues := new(issueService)
+	client := new(scm.Client)
+	client.Issues = issues
+
+	system := &core.System{Link: "https://drone.company.com"}
+	service := New(client, renewer, nil, nil, nil, nil, perms, nil, nil, nil, nil, nil, system, nil, users, nil)
+	err := service.Exec(noContext, dummyRepo, hook)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	want := "Cannot execute `/drone deploy`: " + errUnknownCommand.Error()
+	if got := issues.body; got != want {
+		t.Errorf("Want reply %q, got %q", want, got)
+	}
+}
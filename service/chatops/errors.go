@@ -0,0 +1,28 @@
+// Copyright 2019 Drone IO, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package chatops
+
+import "errors"
+
+var (
+	errUnknownCommand = errors.New("Unknown command. Supported commands are retry, cancel, approve <stage> and promote <environment>")
+	errUnknownUser    = errors.New("Sender is not a registered user")
+	errWriteAccess    = errors.New("Sender does not have write access to the repository")
+	errAdminAccess    = errors.New("Sender does not have admin access to the repository")
+	errBuildNotFound  = errors.New("Cannot find a build for the pull request")
+	errStageNotFound  = errors.New("Cannot find the stage")
+	errMissingStage   = errors.New("Missing stage name or number")
+	errMissingTarget  = errors.New("Missing target environment")
+)
@@ -0,0 +1,62 @@
+// Copyright 2019 Drone IO, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package chatops
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+)
+
+// recorder is an http.ResponseWriter that records the
+// response of the command handler.
+type recorder struct {
+	code   int
+	header http.Header
+	body   bytes.Buffer
+}
+
+func newRecorder() *recorder {
+	return &recorder{
+		code:   http.StatusOK,
+		header: http.Header{},
+	}
+}
+
+func (r *recorder) Header() http.Header         { return r.header }
+func (r *recorder) Write(b []byte) (int, error) { return r.body.Write(b) }
+func (r *recorder) WriteHeader(code int)        { r.code = code }
+
+// err returns the error message written to the response
+// body if the handler did not succeed.
+func (r *recorder) err() error {
+	if r.code < 300 {
+		return nil
+	}
+	out := struct {
+		Message string `json:"message"`
+	}{}
+	json.Unmarshal(r.body.Bytes(), &out)
+	if out.Message == "" {
+		out.Message = http.StatusText(r.code)
+	}
+	return errors.New(out.Message)
+}
+
+// decode decodes the json-encoded response body.
+func (r *recorder) decode(v interface{}) error {
+	return json.NewDecoder(&r.body).Decode(v)
+}
@@ -128,14 +128,73 @@ func (s *service) ListChanges(ctx context.Context, user *core.User, repo, sha, r
 	if err != nil {
 		return nil, err
 	}
+	return convertChanges(out), nil
+}
+
+func (s *service) CompareChanges(ctx context.Context, user *core.User, repo, source, target string) ([]*core.Change, error) {
+	err := s.renew.Renew(ctx, user, false)
+	if err != nil {
+		return nil, err
+	}
+	ctx = context.WithValue(ctx, scm.TokenKey{}, &scm.Token{
+		Token:   user.Token,
+		Refresh: user.Refresh,
+	})
 	var changes []*core.Change
-	for _, change := range out {
-		changes = append(changes, &core.Change{
+	opts := scm.ListOptions{Size: 100}
+	for i := 0; i < maxPages; i++ {
+		out, res, err := s.client.Git.CompareChanges(ctx, repo, source, target, opts)
+		if err != nil {
+			return nil, err
+		}
+		changes = append(changes, convertChanges(out)...)
+		if res == nil || res.Page.Next == 0 {
+			break
+		}
+		opts.Page = res.Page.Next
+	}
+	return changes, nil
+}
+
+func (s *service) ListPullChanges(ctx context.Context, user *core.User, repo string, number int) ([]*core.Change, error) {
+	err := s.renew.Renew(ctx, user, false)
+	if err != nil {
+		return nil, err
+	}
+	ctx = context.WithValue(ctx, scm.TokenKey{}, &scm.Token{
+		Token:   user.Token,
+		Refresh: user.Refresh,
+	})
+	var changes []*core.Change
+	opts := scm.ListOptions{Size: 100}
+	for i := 0; i < maxPages; i++ {
+		out, res, err := s.client.PullRequests.ListChanges(ctx, repo, number, opts)
+		if err != nil {
+			return nil, err
+		}
+		changes = append(changes, convertChanges(out)...)
+		if res == nil || res.Page.Next == 0 {
+			break
+		}
+		opts.Page = res.Page.Next
+	}
+	return changes, nil
+}
+
+// maximum number of pages of changed files fetched from
+// the source code management service.
+const maxPages = 10
+
+// helper function converts the scm changes to core changes.
+func convertChanges(from []*scm.Change) []*core.Change {
+	var to []*core.Change
+	for _, change := range from {
+		to = append(to, &core.Change{
 			Path:    change.Path,
 			Added:   change.Added,
 			Renamed: change.Renamed,
 			Deleted: change.Deleted,
 		})
 	}
-	return changes, nil
+	return to
 }
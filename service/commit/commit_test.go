@@ -343,3 +343,107 @@ func TestListChanges_ErrRenew(t *testing.T) {
 		t.Errorf("Want not authorized error, got %v", err)
 	}
 }
+
+func TestCompareChanges(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	mockUser := &core.User{}
+
+	mockRenewer := mock.NewMockRenewer(controller)
+	mockRenewer.EXPECT().Renew(gomock.Any(), mockUser, false).Return(nil)
+
+	// the changed files are returned in two pages.
+	mockGit := mockscm.NewMockGitService(controller)
+	mockGit.EXPECT().CompareChanges(gomock.Any(), "octocat/hello-world", "553c2077f0edc3d5dc5d17262f6aa498e69d6f8e", "a6586b3db244fb6b1198f2b25c213ded5b44f9fa", scm.ListOptions{Size: 100}).
+		Return([]*scm.Change{{Path: "file1"}}, &scm.Response{Page: scm.Page{Next: 2}}, nil)
+	mockGit.EXPECT().CompareChanges(gomock.Any(), "octocat/hello-world", "553c2077f0edc3d5dc5d17262f6aa498e69d6f8e", "a6586b3db244fb6b1198f2b25c213ded5b44f9fa", scm.ListOptions{Size: 100, Page: 2}).
+		Return([]*scm.Change{{Path: "file2", Added: true}}, &scm.Response{}, nil)
+
+	client := new(scm.Client)
+	client.Git = mockGit
+
+	want := []*core.Change{
+		{Path: "file1"},
+		{Path: "file2", Added: true},
+	}
+
+	service := New(client, mockRenewer)
+	got, err := service.CompareChanges(noContext, mockUser, "octocat/hello-world", "553c2077f0edc3d5dc5d17262f6aa498e69d6f8e", "a6586b3db244fb6b1198f2b25c213ded5b44f9fa")
+	if err != nil {
+		t.Error(err)
+	}
+
+	if diff := cmp.Diff(got, want); diff != "" {
+		t.Errorf(diff)
+	}
+}
+
+func TestCompareChanges_Err(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	mockUser := &core.User{}
+
+	mockRenewer := mock.NewMockRenewer(controller)
+	mockRenewer.EXPECT().Renew(gomock.Any(), mockUser, false).Return(nil)
+
+	mockGit := mockscm.NewMockGitService(controller)
+	mockGit.EXPECT().CompareChanges(gomock.Any(), "octocat/hello-world", "553c2077f0edc3d5dc5d17262f6aa498e69d6f8e", "a6586b3db244fb6b1198f2b25c213ded5b44f9fa", gomock.Any()).Return(nil, nil, scm.ErrNotFound)
+
+	client := new(scm.Client)
+	client.Git = mockGit
+
+	service := New(client, mockRenewer)
+	_, err := service.CompareChanges(noContext, mockUser, "octocat/hello-world", "553c2077f0edc3d5dc5d17262f6aa498e69d6f8e", "a6586b3db244fb6b1198f2b25c213ded5b44f9fa")
+	if err != scm.ErrNotFound {
+		t.Errorf("Want not found error, got %v", err)
+	}
+}
+
+func TestListPullChanges(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	mockUser := &core.User{}
+
+	mockRenewer := mock.NewMockRenewer(controller)
+	mockRenewer.EXPECT().Renew(gomock.Any(), mockUser, false).Return(nil)
+
+	mockPulls := mockscm.NewMockPullRequestService(controller)
+	mockPulls.EXPECT().ListChanges(gomock.Any(), "octocat/hello-world", 42, scm.ListOptions{Size: 100}).
+		Return([]*scm.Change{{Path: "file1", Deleted: true}}, &scm.Response{}, nil)
+
+	client := new(scm.Client)
+	client.PullRequests = mockPulls
+
+	want := []*core.Change{
+		{Path: "file1", Deleted: true},
+	}
+
+	service := New(client, mockRenewer)
+	got, err := service.ListPullChanges(noContext, mockUser, "octocat/hello-world", 42)
+	if err != nil {
+		t.Error(err)
+	}
+
+	if diff := cmp.Diff(got, want); diff != "" {
+		t.Errorf(diff)
+	}
+}
+
+func TestListPullChanges_ErrRenew(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	mockUser := &core.User{}
+
+	mockRenewer := mock.NewMockRenewer(controller)
+	mockRenewer.EXPECT().Renew(gomock.Any(), mockUser, false).Return(scm.ErrNotAuthorized)
+
+	service := New(nil, mockRenewer)
+	_, err := service.ListPullChanges(noContext, mockUser, "octocat/hello-world", 42)
+	if err != scm.ErrNotAuthorized {
+		t.Errorf("Want not authorized error, got %v", err)
+	}
+}
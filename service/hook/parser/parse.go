@@ -84,6 +84,10 @@ func (p *parser) Parse(req *http.Request, secretFunc func(string) string) (*core
 		os.Stderr.Write(out)
 	}
 
+	if p.isRelease(req) {
+		return p.parseRelease(req, secretFunc)
+	}
+
 	// callback function provides the webhook parser with
 	// a per-repository secret key used to verify the webhook
 	// payload signature for authenticity.
@@ -388,6 +392,85 @@ func (p *parser) Parse(req *http.Request, secretFunc func(string) string) (*core
 			SSHURL:    v.Repo.CloneSSH,
 		}
 		return hook, repo, nil
+	case *scm.IssueCommentHook:
+		// github sends issue comment hooks for pull request
+		// comments. The issue includes a pull request link if
+		// the comment was posted to a pull request.
+		if v.Action != scm.ActionCreate || v.Issue.PullRequest.Link == "" {
+			return nil, nil, nil
+		}
+		// comments are ignored unless they contain a chatops
+		// command, to avoid journaling every comment.
+		if core.ParseCommand(v.Comment.Body) == nil {
+			return nil, nil, nil
+		}
+		hook = &core.Hook{
+			Trigger:      core.TriggerHook,
+			Event:        core.EventComment,
+			Action:       core.ActionCreate,
+			Link:         v.Issue.Link,
+			Timestamp:    v.Comment.Created.Unix(),
+			Title:        v.Issue.Title,
+			Message:      v.Comment.Body,
+			Ref:          fmt.Sprintf("refs/pull/%d/head", v.Issue.Number),
+			Author:       v.Comment.Author.Login,
+			AuthorName:   v.Comment.Author.Name,
+			AuthorEmail:  v.Comment.Author.Email,
+			AuthorAvatar: v.Comment.Author.Avatar,
+			Sender:       v.Sender.Login,
+		}
+		repo = &core.Repository{
+			UID:       v.Repo.ID,
+			Namespace: v.Repo.Namespace,
+			Name:      v.Repo.Name,
+			Slug:      scm.Join(v.Repo.Namespace, v.Repo.Name),
+			Link:      v.Repo.Link,
+			Branch:    v.Repo.Branch,
+			Private:   v.Repo.Private,
+			HTTPURL:   v.Repo.Clone,
+			SSHURL:    v.Repo.CloneSSH,
+		}
+		return hook, repo, nil
+	case *scm.PullRequestCommentHook:
+		if v.Action != scm.ActionCreate {
+			return nil, nil, nil
+		}
+		if core.ParseCommand(v.Comment.Body) == nil {
+			return nil, nil, nil
+		}
+		hook = &core.Hook{
+			Trigger:      core.TriggerHook,
+			Event:        core.EventComment,
+			Action:       core.ActionCreate,
+			Link:         v.PullRequest.Link,
+			Timestamp:    v.Comment.Created.Unix(),
+			Title:        v.PullRequest.Title,
+			Message:      v.Comment.Body,
+			After:        v.PullRequest.Sha,
+			Ref:          v.PullRequest.Ref,
+			Source:       v.PullRequest.Source,
+			Target:       v.PullRequest.Target,
+			Author:       v.Comment.Author.Login,
+			AuthorName:   v.Comment.Author.Name,
+			AuthorEmail:  v.Comment.Author.Email,
+			AuthorAvatar: v.Comment.Author.Avatar,
+			Sender:       v.Sender.Login,
+		}
+		if hook.Ref == "" {
+			hook.Ref = fmt.Sprintf("refs/pull/%d/head", v.PullRequest.Number)
+		}
+		repo = &core.Repository{
+			UID:       v.Repo.ID,
+			Namespace: v.Repo.Namespace,
+			Name:      v.Repo.Name,
+			Slug:      scm.Join(v.Repo.Namespace, v.Repo.Name),
+			Link:      v.Repo.Link,
+			Branch:    v.Repo.Branch,
+			Private:   v.Repo.Private,
+			HTTPURL:   v.Repo.Clone,
+			SSHURL:    v.Repo.CloneSSH,
+		}
+		return hook, repo, nil
 	default:
 		return nil, nil, nil
 	}
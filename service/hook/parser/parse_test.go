@@ -3,3 +3,94 @@
 // that can be found in the LICENSE file.
 
 package parser
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/drone/drone/core"
+	"github.com/drone/go-scm/scm"
+)
+
+// webhookService is a stub webhook service that returns
+// the configured webhook.
+type webhookService struct {
+	hook scm.Webhook
+}
+
+func (s *webhookService) Parse(req *http.Request, fn scm.SecretFunc) (scm.Webhook, error) {
+	_, err := fn(s.hook)
+	return s.hook, err
+}
+
+func TestParseIssueComment(t *testing.T) {
+	client := new(scm.Client)
+	client.Webhooks = &webhookService{
+		hook: &scm.IssueCommentHook{
+			Action: scm.ActionCreate,
+			Repo:   scm.Repository{Namespace: "octocat", Name: "hello-world"},
+			Issue: scm.Issue{
+				Number:      42,
+				PullRequest: scm.PullRequest{Link: "https://github.com/octocat/hello-world/pull/42"},
+			},
+			Comment: scm.Comment{Body: "/drone promote production"},
+			Sender:  scm.User{Login: "octocat"},
+		},
+	}
+
+	r := httptest.NewRequest("POST", "/", nil)
+	hook, repo, err := New(client).Parse(r, func(string) string { return "secret" })
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if got, want := hook.Event, core.EventComment; got != want {
+		t.Errorf("Want event %s, got %s", want, got)
+	}
+	if got, want := hook.Ref, "refs/pull/42/head"; got != want {
+		t.Errorf("Want ref %s, got %s", want, got)
+	}
+	if got, want := hook.Sender, "octocat"; got != want {
+		t.Errorf("Want sender %s, got %s", want, got)
+	}
+	if got, want := repo.Slug, "octocat/hello-world"; got != want {
+		t.Errorf("Want repository %s, got %s", want, got)
+	}
+}
+
+// this test verifies that issue comments without a chatops
+// command, and comments posted to issues that are not pull
+// requests, are ignored.
+func TestParseIssueComment_Ignored(t *testing.T) {
+	hooks := []*scm.IssueCommentHook{
+		{
+			Action:  scm.ActionCreate,
+			Issue:   scm.Issue{Number: 42, PullRequest: scm.PullRequest{Link: "https://github.com/octocat/hello-world/pull/42"}},
+			Comment: scm.Comment{Body: "looks good to me"},
+		},
+		{
+			Action:  scm.ActionCreate,
+			Issue:   scm.Issue{Number: 42},
+			Comment: scm.Comment{Body: "/drone retry"},
+		},
+		{
+			Action:  scm.ActionEdit,
+			Issue:   scm.Issue{Number: 42, PullRequest: scm.PullRequest{Link: "https://github.com/octocat/hello-world/pull/42"}},
+			Comment: scm.Comment{Body: "/drone retry"},
+		},
+	}
+	for _, v := range hooks {
+		client := new(scm.Client)
+		client.Webhooks = &webhookService{hook: v}
+
+		r := httptest.NewRequest("POST", "/", nil)
+		hook, _, err := New(client).Parse(r, func(string) string { return "secret" })
+		if err != nil {
+			t.Error(err)
+		}
+		if hook != nil {
+			t.Errorf("Want comment %q ignored", v.Comment.Body)
+		}
+	}
+}
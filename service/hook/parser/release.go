@@ -0,0 +1,207 @@
+// Copyright 2019 Drone IO, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package parser
+
+import (
+	"crypto/hmac"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"hash"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/drone/drone/core"
+	"github.com/drone/go-scm/scm"
+)
+
+type (
+	// releaseHook represents a github or gitea release hook.
+	releaseHook struct {
+		Action  string `json:"action"`
+		Release struct {
+			TagName     string      `json:"tag_name"`
+			Name        string      `json:"name"`
+			Body        string      `json:"body"`
+			Draft       bool        `json:"draft"`
+			Prerelease  bool        `json:"prerelease"`
+			HTMLURL     string      `json:"html_url"`
+			PublishedAt time.Time   `json:"published_at"`
+			Author      releaseUser `json:"author"`
+		} `json:"release"`
+		Repository struct {
+			ID            json.Number `json:"id"`
+			Name          string      `json:"name"`
+			Owner         releaseUser `json:"owner"`
+			Private       bool        `json:"private"`
+			HTMLURL       string      `json:"html_url"`
+			CloneURL      string      `json:"clone_url"`
+			SSHURL        string      `json:"ssh_url"`
+			DefaultBranch string      `json:"default_branch"`
+		} `json:"repository"`
+		Sender releaseUser `json:"sender"`
+	}
+
+	// releaseUser represents a github or gitea user.
+	releaseUser struct {
+		Login     string `json:"login"`
+		Username  string `json:"username"`
+		Name      string `json:"full_name"`
+		Email     string `json:"email"`
+		AvatarURL string `json:"avatar_url"`
+	}
+)
+
+// login returns the user login. Gitea provides the login
+// as the username in older versions.
+func (u *releaseUser) login() string {
+	if u.Login != "" {
+		return u.Login
+	}
+	return u.Username
+}
+
+// isRelease returns true if the webhook is a release hook.
+// Release hooks are not supported by the scm client and are
+// parsed separately.
+func (p *parser) isRelease(req *http.Request) bool {
+	switch p.client.Driver {
+	case scm.DriverGithub:
+		return req.Header.Get("X-GitHub-Event") == "release"
+	case scm.DriverGitea:
+		return req.Header.Get("X-Gitea-Event") == "release"
+	default:
+		return false
+	}
+}
+
+// parseRelease parses and verifies the release hook.
+func (p *parser) parseRelease(req *http.Request, secretFunc func(string) string) (*core.Hook, *core.Repository, error) {
+	data, err := ioutil.ReadAll(
+		io.LimitReader(req.Body, 10000000),
+	)
+	if err != nil {
+		return nil, nil, err
+	}
+	src := new(releaseHook)
+	err = json.Unmarshal(data, src)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	namespace := src.Repository.Owner.login()
+	secret := secretFunc(scm.Join(namespace, src.Repository.Name))
+	if secret == "" {
+		return nil, nil, errors.New("Cannot find repository")
+	}
+	if !p.validateRelease(req.Header, data, secret) {
+		return nil, nil, scm.ErrSignatureInvalid
+	}
+
+	action := p.releaseAction(src)
+	if action == "" || src.Release.Draft {
+		return nil, nil, nil
+	}
+
+	hook := &core.Hook{
+		Trigger:      core.TriggerHook,
+		Event:        core.EventRelease,
+		Action:       action,
+		Link:         src.Release.HTMLURL,
+		Title:        src.Release.Name,
+		Ref:          "refs/tags/" + src.Release.TagName,
+		Source:       src.Release.TagName,
+		Target:       src.Release.TagName,
+		Author:       src.Release.Author.login(),
+		AuthorName:   src.Release.Author.Name,
+		AuthorEmail:  src.Release.Author.Email,
+		AuthorAvatar: src.Release.Author.AvatarURL,
+		Sender:       src.Sender.login(),
+		Params: map[string]string{
+			core.ParamReleaseTitle: src.Release.Name,
+			core.ParamReleaseBody:  src.Release.Body,
+		},
+	}
+	if !src.Release.PublishedAt.IsZero() {
+		hook.Timestamp = src.Release.PublishedAt.Unix()
+	}
+	if hook.AuthorAvatar == "" {
+		hook.AuthorAvatar = src.Sender.AvatarURL
+	}
+	repo := &core.Repository{
+		UID:       src.Repository.ID.String(),
+		Namespace: namespace,
+		Name:      src.Repository.Name,
+		Slug:      scm.Join(namespace, src.Repository.Name),
+		Link:      src.Repository.HTMLURL,
+		Branch:    src.Repository.DefaultBranch,
+		Private:   src.Repository.Private,
+		HTTPURL:   src.Repository.CloneURL,
+		SSHURL:    src.Repository.SSHURL,
+	}
+	return hook, repo, nil
+}
+
+// releaseAction returns the normalized release hook action,
+// or an empty string if the action should be ignored.
+func (p *parser) releaseAction(src *releaseHook) string {
+	switch src.Action {
+	case "published":
+		if !src.Release.Prerelease {
+			return core.ActionPublished
+		}
+		// github sends a separate prereleased hook when a
+		// pre-release is published. Gitea does not.
+		if p.client.Driver == scm.DriverGithub {
+			return ""
+		}
+		return core.ActionPrereleased
+	case "prereleased":
+		return core.ActionPrereleased
+	case "edited", "updated":
+		return core.ActionEdited
+	default:
+		return ""
+	}
+}
+
+// validateRelease returns true if the release hook payload
+// signature is valid.
+func (p *parser) validateRelease(header http.Header, data []byte, secret string) bool {
+	var sig string
+	var fn func() hash.Hash
+	switch p.client.Driver {
+	case scm.DriverGitea:
+		sig, fn = header.Get("X-Gitea-Signature"), sha256.New
+	default:
+		if sig = header.Get("X-Hub-Signature-256"); sig != "" {
+			sig, fn = strings.TrimPrefix(sig, "sha256="), sha256.New
+		} else {
+			sig, fn = strings.TrimPrefix(header.Get("X-Hub-Signature"), "sha1="), sha1.New
+		}
+	}
+	want, err := hex.DecodeString(sig)
+	if err != nil || len(want) == 0 {
+		return false
+	}
+	mac := hmac.New(fn, []byte(secret))
+	mac.Write(data)
+	return hmac.Equal(mac.Sum(nil), want)
+}
@@ -0,0 +1,131 @@
+// Copyright 2019 Drone.IO Inc. All rights reserved.
+// Use of this source code is governed by the Drone Non-Commercial License
+// that can be found in the LICENSE file.
+
+package parser
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/drone/drone/core"
+	"github.com/drone/go-scm/scm"
+	"github.com/google/go-cmp/cmp"
+)
+
+const releasePayload = `{
+  "action": "%action%",
+  "release": {
+    "html_url": "https://github.com/octocat/hello-world/releases/tag/v1.0.0",
+    "tag_name": "v1.0.0",
+    "name": "Version 1.0",
+    "body": "Initial release",
+    "draft": false,
+    "prerelease": %prerelease%,
+    "published_at": "2021-04-01T12:00:00Z",
+    "author": { "login": "octocat", "avatar_url": "https://github.com/octocat.png" }
+  },
+  "repository": {
+    "id": 1296269,
+    "name": "hello-world",
+    "owner": { "login": "octocat" },
+    "private": false,
+    "html_url": "https://github.com/octocat/hello-world",
+    "clone_url": "https://github.com/octocat/hello-world.git",
+    "ssh_url": "git@github.com:octocat/hello-world.git",
+    "default_branch": "master"
+  },
+  "sender": { "login": "octocat" }
+}`
+
+func TestParseRelease(t *testing.T) {
+	body := strings.NewReplacer("%action%", "published", "%prerelease%", "false").Replace(releasePayload)
+	r := httptest.NewRequest("POST", "/", strings.NewReader(body))
+	r.Header.Set("X-GitHub-Event", "release")
+	r.Header.Set("X-Hub-Signature-256", "sha256="+sign(body, "secret"))
+
+	client := &scm.Client{Driver: scm.DriverGithub}
+	hook, repo, err := New(client).Parse(r, func(string) string { return "secret" })
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	want := &core.Hook{
+		Trigger:      core.TriggerHook,
+		Event:        core.EventRelease,
+		Action:       core.ActionPublished,
+		Link:         "https://github.com/octocat/hello-world/releases/tag/v1.0.0",
+		Timestamp:    1617278400,
+		Title:        "Version 1.0",
+		Ref:          "refs/tags/v1.0.0",
+		Source:       "v1.0.0",
+		Target:       "v1.0.0",
+		Author:       "octocat",
+		AuthorAvatar: "https://github.com/octocat.png",
+		Sender:       "octocat",
+		Params: map[string]string{
+			core.ParamReleaseTitle: "Version 1.0",
+			core.ParamReleaseBody:  "Initial release",
+		},
+	}
+	if diff := cmp.Diff(hook, want); diff != "" {
+		t.Errorf(diff)
+	}
+	if got, want := repo.Slug, "octocat/hello-world"; got != want {
+		t.Errorf("Want repository %s, got %s", want, got)
+	}
+	if got, want := repo.UID, "1296269"; got != want {
+		t.Errorf("Want repository uid %s, got %s", want, got)
+	}
+}
+
+func TestParseRelease_Actions(t *testing.T) {
+	tests := []struct {
+		driver     scm.Driver
+		action     string
+		prerelease bool
+		want       string
+	}{
+		{scm.DriverGithub, "published", false, core.ActionPublished},
+		{scm.DriverGithub, "published", true, ""},
+		{scm.DriverGithub, "prereleased", true, core.ActionPrereleased},
+		{scm.DriverGithub, "edited", false, core.ActionEdited},
+		{scm.DriverGithub, "created", false, ""},
+		{scm.DriverGithub, "deleted", false, ""},
+		{scm.DriverGitea, "published", false, core.ActionPublished},
+		{scm.DriverGitea, "published", true, core.ActionPrereleased},
+		{scm.DriverGitea, "updated", false, core.ActionEdited},
+	}
+	for _, test := range tests {
+		p := &parser{client: &scm.Client{Driver: test.driver}}
+		src := new(releaseHook)
+		src.Action = test.action
+		src.Release.Prerelease = test.prerelease
+		if got := p.releaseAction(src); got != test.want {
+			t.Errorf("Want %s %s action %q, got %q", test.driver, test.action, test.want, got)
+		}
+	}
+}
+
+func TestParseRelease_InvalidSignature(t *testing.T) {
+	body := strings.NewReplacer("%action%", "published", "%prerelease%", "false").Replace(releasePayload)
+	r := httptest.NewRequest("POST", "/", strings.NewReader(body))
+	r.Header.Set("X-Gitea-Event", "release")
+	r.Header.Set("X-Gitea-Signature", sign(body, "invalid"))
+
+	client := &scm.Client{Driver: scm.DriverGitea}
+	_, _, err := New(client).Parse(r, func(string) string { return "secret" })
+	if err != scm.ErrSignatureInvalid {
+		t.Errorf("Want invalid signature error, got %v", err)
+	}
+}
+
+func sign(body, secret string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(body))
+	return hex.EncodeToString(mac.Sum(nil))
+}
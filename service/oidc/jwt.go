@@ -0,0 +1,231 @@
+// Copyright 2019 Drone IO, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package oidc
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+)
+
+var (
+	errTokenFormat    = errors.New("oidc: malformed id_token")
+	errTokenSignature = errors.New("oidc: invalid id_token signature")
+	errTokenExpired   = errors.New("oidc: id_token is expired")
+	errTokenIssuer    = errors.New("oidc: id_token issuer mismatch")
+	errTokenAudience  = errors.New("oidc: id_token audience mismatch")
+	errKeyNotFound    = errors.New("oidc: id_token signing key not found")
+)
+
+// leeway is the permitted clock skew when validating the
+// id_token expiry.
+const leeway = time.Minute
+
+// now returns the current time. It is a variable so it can
+// be replaced by unit tests.
+var now = time.Now
+
+// json web token header.
+type header struct {
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+}
+
+// json web key set returned by the provider.
+type jwks struct {
+	Keys []*jwk `json:"keys"`
+}
+
+// json web key.
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+// helper function verifies the id_token signature and the
+// standard claims, and returns the token claims.
+func (s *service) verify(ctx context.Context, conf *discovery, token string) (map[string]interface{}, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, errTokenFormat
+	}
+	rawHeader, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return nil, errTokenFormat
+	}
+	rawClaims, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, errTokenFormat
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, errTokenFormat
+	}
+	head := new(header)
+	if err := json.Unmarshal(rawHeader, head); err != nil {
+		return nil, errTokenFormat
+	}
+	key, err := s.key(ctx, conf, head.Kid)
+	if err != nil {
+		return nil, err
+	}
+	digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	if err := verifySignature(head.Alg, key, digest[:], signature); err != nil {
+		return nil, err
+	}
+
+	claims := map[string]interface{}{}
+	if err := json.Unmarshal(rawClaims, &claims); err != nil {
+		return nil, errTokenFormat
+	}
+	if iss, _ := claims["iss"].(string); iss != conf.Issuer {
+		return nil, errTokenIssuer
+	}
+	if !contains(claimValues(claims["aud"]), s.config.ClientID) {
+		return nil, errTokenAudience
+	}
+	exp, _ := claims["exp"].(float64)
+	if time.Unix(int64(exp), 0).Add(leeway).Before(now()) {
+		return nil, errTokenExpired
+	}
+	return claims, nil
+}
+
+// helper function verifies the signature of the digest.
+// Only the RS256 and ES256 algorithms are supported.
+func verifySignature(alg string, key interface{}, digest, signature []byte) error {
+	switch alg {
+	case "RS256":
+		pub, ok := key.(*rsa.PublicKey)
+		if !ok {
+			return errTokenSignature
+		}
+		if rsa.VerifyPKCS1v15(pub, crypto.SHA256, digest, signature) != nil {
+			return errTokenSignature
+		}
+		return nil
+	case "ES256":
+		pub, ok := key.(*ecdsa.PublicKey)
+		if !ok || len(signature) != 64 {
+			return errTokenSignature
+		}
+		r := new(big.Int).SetBytes(signature[:32])
+		s := new(big.Int).SetBytes(signature[32:])
+		if !ecdsa.Verify(pub, digest, r, s) {
+			return errTokenSignature
+		}
+		return nil
+	default:
+		return fmt.Errorf("oidc: unsupported id_token algorithm %q", alg)
+	}
+}
+
+// helper function returns the signing key with the key id.
+// The key set is fetched from the provider if the key is
+// not cached, to support key rotation.
+func (s *service) key(ctx context.Context, conf *discovery, kid string) (interface{}, error) {
+	s.Lock()
+	key, ok := s.keys[kid]
+	s.Unlock()
+	if ok {
+		return key, nil
+	}
+
+	set := new(jwks)
+	if err := s.get(ctx, conf.JwksURI, set); err != nil {
+		return nil, err
+	}
+	keys := map[string]interface{}{}
+	for _, k := range set.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+		if pub, err := parseKey(k); err == nil {
+			keys[k.Kid] = pub
+		}
+	}
+
+	s.Lock()
+	s.keys = keys
+	s.Unlock()
+
+	// if the token does not include a key id the key set
+	// must contain a single key.
+	if kid == "" && len(keys) == 1 {
+		for _, key := range keys {
+			return key, nil
+		}
+	}
+	if key, ok := keys[kid]; ok {
+		return key, nil
+	}
+	return nil, errKeyNotFound
+}
+
+// helper function parses the json web key.
+func parseKey(key *jwk) (interface{}, error) {
+	switch key.Kty {
+	case "RSA":
+		n, err := decodeInt(key.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decodeInt(key.E)
+		if err != nil {
+			return nil, err
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		if key.Crv != "P-256" {
+			return nil, fmt.Errorf("oidc: unsupported curve %q", key.Crv)
+		}
+		x, err := decodeInt(key.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decodeInt(key.Y)
+		if err != nil {
+			return nil, err
+		}
+		return &ecdsa.PublicKey{Curve: elliptic.P256(), X: x, Y: y}, nil
+	default:
+		return nil, fmt.Errorf("oidc: unsupported key type %q", key.Kty)
+	}
+}
+
+// helper function decodes the base64url encoded integer.
+func decodeInt(s string) (*big.Int, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).SetBytes(raw), nil
+}
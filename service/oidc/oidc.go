@@ -0,0 +1,302 @@
+// Copyright 2019 Drone IO, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package oidc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/drone/drone/core"
+)
+
+var (
+	errMissingToken   = errors.New("oidc: id_token missing from token response")
+	errMissingSubject = errors.New("oidc: subject claim missing from id_token")
+	errMissingLogin   = errors.New("oidc: login claim missing from id_token")
+	errNonce          = errors.New("oidc: id_token nonce mismatch")
+)
+
+// Config configures the OpenID Connect provider.
+type Config struct {
+	// Issuer is the provider issuer url. The provider
+	// configuration is discovered from the issuer.
+	Issuer string
+
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+	Scope        []string
+
+	// LoginClaim is the claim used as the username for
+	// new user accounts.
+	LoginClaim string
+
+	// MatchLogin matches identities to existing user
+	// accounts by login instead of verified email.
+	MatchLogin bool
+
+	// AdminClaim and AdminValues map identities to system
+	// administrators. An identity is an administrator if
+	// the claim contains any of the values.
+	AdminClaim  string
+	AdminValues []string
+
+	// OrgClaim and OrgMap map identities to organizations.
+	// Only claim values listed in the map are mapped, and
+	// identities are not mapped to organizations if the map
+	// is empty.
+	OrgClaim string
+	OrgMap   map[string]string
+
+	Client *http.Client
+}
+
+// provider configuration returned by the discovery endpoint.
+type discovery struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JwksURI               string `json:"jwks_uri"`
+}
+
+// token endpoint response.
+type tokenResponse struct {
+	IDToken          string `json:"id_token"`
+	AccessToken      string `json:"access_token"`
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description"`
+}
+
+// New returns a new IdentityService that authenticates users
+// with an OpenID Connect provider.
+func New(config Config) core.IdentityService {
+	client := config.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return &service{
+		config: config,
+		client: client,
+		keys:   map[string]interface{}{},
+	}
+}
+
+type service struct {
+	config Config
+	client *http.Client
+
+	sync.Mutex
+	discovery *discovery
+	keys      map[string]interface{}
+}
+
+func (s *service) Redirect(ctx context.Context, state, nonce string) (string, error) {
+	conf, err := s.discover(ctx)
+	if err != nil {
+		return "", err
+	}
+	params := url.Values{}
+	params.Set("response_type", "code")
+	params.Set("client_id", s.config.ClientID)
+	params.Set("redirect_uri", s.config.RedirectURL)
+	params.Set("scope", strings.Join(s.config.Scope, " "))
+	params.Set("state", state)
+	params.Set("nonce", nonce)
+
+	endpoint := conf.AuthorizationEndpoint
+	if strings.Contains(endpoint, "?") {
+		return endpoint + "&" + params.Encode(), nil
+	}
+	return endpoint + "?" + params.Encode(), nil
+}
+
+func (s *service) Verify(ctx context.Context, code, nonce string) (*core.IdentityClaims, error) {
+	conf, err := s.discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+	token, err := s.exchange(ctx, conf, code)
+	if err != nil {
+		return nil, err
+	}
+	claims, err := s.verify(ctx, conf, token)
+	if err != nil {
+		return nil, err
+	}
+	if got, _ := claims["nonce"].(string); got != nonce {
+		return nil, errNonce
+	}
+	return s.convert(claims)
+}
+
+// helper function exchanges the authorization code for the
+// id_token.
+func (s *service) exchange(ctx context.Context, conf *discovery, code string) (string, error) {
+	params := url.Values{}
+	params.Set("grant_type", "authorization_code")
+	params.Set("code", code)
+	params.Set("redirect_uri", s.config.RedirectURL)
+
+	req, err := http.NewRequest("POST", conf.TokenEndpoint, strings.NewReader(params.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.SetBasicAuth(
+		url.QueryEscape(s.config.ClientID),
+		url.QueryEscape(s.config.ClientSecret),
+	)
+	res, err := s.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer res.Body.Close()
+
+	out := new(tokenResponse)
+	json.NewDecoder(res.Body).Decode(out)
+	switch {
+	case out.Error != "" && out.ErrorDescription != "":
+		return "", fmt.Errorf("oidc: %s: %s", out.Error, out.ErrorDescription)
+	case out.Error != "":
+		return "", fmt.Errorf("oidc: %s", out.Error)
+	case res.StatusCode > 299:
+		return "", fmt.Errorf("oidc: token exchange failed: %s", res.Status)
+	case out.IDToken == "":
+		return "", errMissingToken
+	}
+	return out.IDToken, nil
+}
+
+// helper function returns the provider configuration from
+// the discovery endpoint. The configuration is cached.
+func (s *service) discover(ctx context.Context) (*discovery, error) {
+	s.Lock()
+	defer s.Unlock()
+	if s.discovery != nil {
+		return s.discovery, nil
+	}
+	endpoint := strings.TrimSuffix(s.config.Issuer, "/") + "/.well-known/openid-configuration"
+	out := new(discovery)
+	if err := s.get(ctx, endpoint, out); err != nil {
+		return nil, err
+	}
+	if strings.TrimSuffix(out.Issuer, "/") != strings.TrimSuffix(s.config.Issuer, "/") {
+		return nil, fmt.Errorf("oidc: issuer mismatch: %s", out.Issuer)
+	}
+	s.discovery = out
+	return out, nil
+}
+
+// helper function gets and decodes the json resource.
+func (s *service) get(ctx context.Context, endpoint string, out interface{}) error {
+	req, err := http.NewRequest("GET", endpoint, nil)
+	if err != nil {
+		return err
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("Accept", "application/json")
+	res, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.StatusCode > 299 {
+		return fmt.Errorf("oidc: cannot get %s: %s", endpoint, res.Status)
+	}
+	return json.NewDecoder(res.Body).Decode(out)
+}
+
+// helper function converts the verified id_token claims to
+// identity claims using the configured claim mappings.
+func (s *service) convert(claims map[string]interface{}) (*core.IdentityClaims, error) {
+	out := &core.IdentityClaims{
+		MatchLogin: s.config.MatchLogin,
+	}
+	out.Subject, _ = claims["sub"].(string)
+	out.Login, _ = claims[s.config.LoginClaim].(string)
+	out.Email, _ = claims["email"].(string)
+	out.Avatar, _ = claims["picture"].(string)
+	if out.Subject == "" {
+		return nil, errMissingSubject
+	}
+	if out.Login == "" {
+		return nil, errMissingLogin
+	}
+
+	// some providers encode the email_verified claim
+	// as a string instead of a boolean.
+	switch v := claims["email_verified"].(type) {
+	case bool:
+		out.Verified = v
+	case string:
+		out.Verified = v == "true"
+	}
+
+	if len(s.config.AdminValues) != 0 {
+		admin := false
+		for _, value := range claimValues(claims[s.config.AdminClaim]) {
+			if contains(s.config.AdminValues, value) {
+				admin = true
+				break
+			}
+		}
+		out.Admin = &admin
+	}
+
+	for _, value := range claimValues(claims[s.config.OrgClaim]) {
+		org := s.config.OrgMap[value]
+		if org != "" && !contains(out.Orgs, org) {
+			out.Orgs = append(out.Orgs, org)
+		}
+	}
+	return out, nil
+}
+
+// helper function returns the claim values. The claim may
+// be a single string or a list of strings.
+func claimValues(claim interface{}) []string {
+	switch v := claim.(type) {
+	case string:
+		return []string{v}
+	case []interface{}:
+		var out []string
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				out = append(out, s)
+			}
+		}
+		return out
+	}
+	return nil
+}
+
+// helper function returns true if the list contains the
+// string value.
+func contains(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,287 @@
+// Copyright 2019 Drone.IO Inc. All rights reserved.
+// Use of this source code is governed by the Drone Non-Commercial License
+// that can be found in the LICENSE file.
+
+package oidc
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/drone/drone/core"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+var noContext = context.Background()
+
+// standin is a local OpenID Connect provider used to test
+// the authorization code flow.
+type standin struct {
+	*httptest.Server
+	key    *rsa.PrivateKey
+	claims map[string]interface{}
+	code   string
+}
+
+func newStandin(t *testing.T) *standin {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &standin{key: key, code: "8cbc4d4a"}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]string{
+			"issuer":                 s.URL,
+			"authorization_endpoint": s.URL + "/authorize",
+			"token_endpoint":         s.URL + "/token",
+			"jwks_uri":               s.URL + "/keys",
+		})
+	})
+	mux.HandleFunc("/keys", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"keys": []map[string]string{{
+				"kty": "RSA",
+				"kid": "key1",
+				"use": "sig",
+				"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+				"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+			}},
+		})
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		user, pass, _ := r.BasicAuth()
+		if user != "drone" || pass != "correct-horse-battery-staple" {
+			w.WriteHeader(401)
+			json.NewEncoder(w).Encode(map[string]string{"error": "invalid_client"})
+			return
+		}
+		if r.FormValue("code") != s.code {
+			w.WriteHeader(400)
+			json.NewEncoder(w).Encode(map[string]string{"error": "invalid_grant"})
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]string{
+			"access_token": "d4a7c9f0",
+			"id_token":     s.sign(t, "key1", s.claims),
+		})
+	})
+	s.Server = httptest.NewServer(mux)
+	s.claims = map[string]interface{}{
+		"iss":                s.URL,
+		"aud":                "drone",
+		"sub":                "248289761001",
+		"exp":                time.Now().Add(time.Hour).Unix(),
+		"nonce":              "n-0S6_WzA2Mj",
+		"preferred_username": "octocat",
+		"email":              "octocat@github.com",
+		"email_verified":     true,
+		"groups":             []string{"admins", "contractors"},
+	}
+	return s
+}
+
+func (s *standin) sign(t *testing.T, kid string, claims map[string]interface{}) string {
+	head, _ := json.Marshal(map[string]string{"alg": "RS256", "kid": kid})
+	body, _ := json.Marshal(claims)
+	payload := base64.RawURLEncoding.EncodeToString(head) + "." +
+		base64.RawURLEncoding.EncodeToString(body)
+	digest := sha256.Sum256([]byte(payload))
+	sig, err := rsa.SignPKCS1v15(rand.Reader, s.key, crypto.SHA256, digest[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	return payload + "." + base64.RawURLEncoding.EncodeToString(sig)
+}
+
+func (s *standin) config() Config {
+	return Config{
+		Issuer:       s.URL,
+		ClientID:     "drone",
+		ClientSecret: "correct-horse-battery-staple",
+		RedirectURL:  "https://drone.company.com/login/oidc",
+		Scope:        []string{"openid", "profile", "email"},
+		LoginClaim:   "preferred_username",
+		AdminClaim:   "groups",
+		OrgClaim:     "groups",
+	}
+}
+
+func TestRedirect(t *testing.T) {
+	idp := newStandin(t)
+	defer idp.Close()
+
+	service := New(idp.config())
+	got, err := service.Redirect(noContext, "xyz", "n-0S6_WzA2Mj")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	u, err := url.Parse(got)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if got, want := u.Scheme+"://"+u.Host+u.Path, idp.URL+"/authorize"; got != want {
+		t.Errorf("Want authorization endpoint %s, got %s", want, got)
+	}
+	params := u.Query()
+	for key, want := range map[string]string{
+		"response_type": "code",
+		"client_id":     "drone",
+		"redirect_uri":  "https://drone.company.com/login/oidc",
+		"scope":         "openid profile email",
+		"state":         "xyz",
+		"nonce":         "n-0S6_WzA2Mj",
+	} {
+		if got := params.Get(key); got != want {
+			t.Errorf("Want %s %q, got %q", key, want, got)
+		}
+	}
+}
+
+func TestVerify(t *testing.T) {
+	idp := newStandin(t)
+	defer idp.Close()
+
+	config := idp.config()
+	config.AdminValues = []string{"admins"}
+	config.OrgMap = map[string]string{"contractors": "octo-org"}
+
+	got, err := New(config).Verify(noContext, idp.code, "n-0S6_WzA2Mj")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	admin := true
+	want := &core.IdentityClaims{
+		Subject:  "248289761001",
+		Login:    "octocat",
+		Email:    "octocat@github.com",
+		Verified: true,
+		Admin:    &admin,
+		Orgs:     []string{"octo-org"},
+	}
+	if diff := cmp.Diff(got, want); diff != "" {
+		t.Errorf(diff)
+	}
+}
+
+// this test verifies that identities are not mapped to
+// organizations if the organization map is empty, and that
+// the admin flag is not mapped if no values are set.
+func TestVerify_DefaultMapping(t *testing.T) {
+	idp := newStandin(t)
+	defer idp.Close()
+
+	got, err := New(idp.config()).Verify(noContext, idp.code, "n-0S6_WzA2Mj")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if got.Admin != nil {
+		t.Errorf("Want admin flag not mapped")
+	}
+	if len(got.Orgs) != 0 {
+		t.Errorf("Want no organizations mapped, got %v", got.Orgs)
+	}
+}
+
+func TestVerify_Errors(t *testing.T) {
+	tests := []struct {
+		name   string
+		code   string
+		nonce  string
+		kid    string
+		claims map[string]interface{}
+		err    error
+	}{
+		{name: "nonce", nonce: "invalid", err: errNonce},
+		{name: "issuer", claims: map[string]interface{}{"iss": "https://evil.com"}, err: errTokenIssuer},
+		{name: "audience", claims: map[string]interface{}{"aud": "other"}, err: errTokenAudience},
+		{name: "expired", claims: map[string]interface{}{"exp": time.Now().Add(-time.Hour).Unix()}, err: errTokenExpired},
+		{name: "subject", claims: map[string]interface{}{"sub": ""}, err: errMissingSubject},
+		{name: "login", claims: map[string]interface{}{"preferred_username": nil}, err: errMissingLogin},
+		{name: "key", kid: "key2", err: errKeyNotFound},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			idp := newStandin(t)
+			defer idp.Close()
+			for k, v := range test.claims {
+				idp.claims[k] = v
+			}
+			service := New(idp.config()).(*service)
+			conf, err := service.discover(noContext)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			kid := test.kid
+			if kid == "" {
+				kid = "key1"
+			}
+			token := idp.sign(t, kid, idp.claims)
+			nonce := test.nonce
+			if nonce == "" {
+				nonce = "n-0S6_WzA2Mj"
+			}
+			claims, err := service.verify(noContext, conf, token)
+			if err == nil {
+				if got, _ := claims["nonce"].(string); got != nonce {
+					err = errNonce
+				} else {
+					_, err = service.convert(claims)
+				}
+			}
+			if err != test.err {
+				t.Errorf("Want error %v, got %v", test.err, err)
+			}
+		})
+	}
+}
+
+// this test verifies that a token signed with a different
+// key is rejected.
+func TestVerify_InvalidSignature(t *testing.T) {
+	idp := newStandin(t)
+	defer idp.Close()
+
+	other := newStandin(t)
+	defer other.Close()
+
+	service := New(idp.config()).(*service)
+	conf, err := service.discover(noContext)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	token := other.sign(t, "key1", idp.claims)
+	if _, err := service.verify(noContext, conf, token); err != errTokenSignature {
+		t.Errorf("Want signature error, got %v", err)
+	}
+}
+
+// this test verifies that an invalid authorization code
+// returns the provider error.
+func TestVerify_InvalidCode(t *testing.T) {
+	idp := newStandin(t)
+	defer idp.Close()
+
+	_, err := New(idp.config()).Verify(noContext, "invalid", "n-0S6_WzA2Mj")
+	if err == nil || err.Error() != "oidc: invalid_grant" {
+		t.Errorf("Want invalid_grant error, got %v", err)
+	}
+}
@@ -0,0 +1,191 @@
+// Copyright 2019 Drone IO, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package retention
+
+import (
+	"context"
+	"runtime/debug"
+	"time"
+
+	"github.com/drone/drone/core"
+
+	"github.com/hashicorp/go-multierror"
+	"github.com/sirupsen/logrus"
+)
+
+// number of repositories and builds loaded per query.
+const pageSize = 100
+
+// Retention expires the logs and cards of builds that
+// exceed the log retention policy. The build history
+// is not removed.
+type Retention struct {
+	Repos  core.RepositoryStore
+	Builds core.BuildStore
+	Stages core.StageStore
+	Logs   core.LogStore
+	Cards  core.CardStore
+	Index  core.LogIndex
+	Policy core.RetentionPolicy
+}
+
+// New returns a new Retention.
+func New(
+	repos core.RepositoryStore,
+	builds core.BuildStore,
+	stages core.StageStore,
+	logs core.LogStore,
+	cards core.CardStore,
+	index core.LogIndex,
+	policy core.RetentionPolicy,
+) *Retention {
+	return &Retention{
+		Repos:  repos,
+		Builds: builds,
+		Stages: stages,
+		Logs:   logs,
+		Cards:  cards,
+		Index:  index,
+		Policy: policy,
+	}
+}
+
+// Start starts the log retention worker.
+func (r *Retention) Start(ctx context.Context, dur time.Duration) error {
+	ticker := time.NewTicker(dur)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			r.expire(ctx, time.Now())
+		}
+	}
+}
+
+func (r *Retention) expire(ctx context.Context, now time.Time) error {
+	defer func() {
+		// taking the paranoid approach to recover from
+		// a panic that should absolutely never happen.
+		if r := recover(); r != nil {
+			logrus.Errorf("retention: unexpected panic: %s", r)
+			debug.PrintStack()
+		}
+	}()
+
+	logrus.Traceln("retention: finding expired build logs")
+
+	var result error
+	for offset := 0; ; offset += pageSize {
+		repos, err := r.Repos.ListAll(ctx, pageSize, offset)
+		if err != nil {
+			logrus.WithError(err).
+				Errorln("retention: cannot list repositories")
+			return multierror.Append(result, err)
+		}
+		for _, repo := range repos {
+			err := r.expireRepo(ctx, repo, now)
+			if err != nil {
+				logrus.WithError(err).
+					WithField("repo", repo.Slug).
+					Errorln("retention: cannot expire build logs")
+				result = multierror.Append(result, err)
+			}
+		}
+		if len(repos) < pageSize {
+			return result
+		}
+	}
+}
+
+func (r *Retention) expireRepo(ctx context.Context, repo *core.Repository, now time.Time) error {
+	// the most recent builds for the default branch are
+	// always kept, regardless of the retention policy.
+	keep := map[int64]struct{}{}
+	if r.Policy.KeepDefault > 0 && repo.Branch != "" {
+		ref := "refs/heads/" + repo.Branch
+		builds, err := r.Builds.ListRef(ctx, repo.ID, ref, r.Policy.KeepDefault, 0)
+		if err != nil {
+			return err
+		}
+		for _, build := range builds {
+			keep[build.ID] = struct{}{}
+		}
+	}
+
+	params := r.Policy.Params(repo, now)
+	params.Limit = pageSize
+	for {
+		builds, err := r.Builds.ListExpirable(ctx, repo.ID, params)
+		if err != nil {
+			return err
+		}
+		for _, build := range builds {
+			params.After = build.Number
+			if _, ok := keep[build.ID]; ok {
+				continue
+			}
+			if !r.Policy.IsExpired(repo, build, now) {
+				continue
+			}
+			err := r.expireBuild(ctx, build)
+			if err != nil {
+				return err
+			}
+		}
+		if len(builds) < pageSize {
+			return nil
+		}
+	}
+}
+
+func (r *Retention) expireBuild(ctx context.Context, build *core.Build) error {
+	logger := logrus.
+		WithField("build.id", build.ID).
+		WithField("build.number", build.Number).
+		WithField("build.repo_id", build.RepoID)
+
+	stages, err := r.Stages.ListSteps(ctx, build.ID)
+	if err != nil {
+		return err
+	}
+	for _, stage := range stages {
+		for _, step := range stage.Steps {
+			// the logs or cards may not exist, for example if
+			// the step was skipped, and errors are therefore
+			// logged and ignored.
+			if err := r.Logs.Delete(ctx, step.ID); err != nil {
+				logger.WithError(err).WithField("step.id", step.ID).
+					Debugln("retention: cannot delete logs")
+			}
+			if err := r.Cards.Delete(ctx, step.ID); err != nil {
+				logger.WithError(err).WithField("step.id", step.ID).
+					Debugln("retention: cannot delete card")
+			}
+			if r.Index == nil {
+				continue
+			}
+			if err := r.Index.Delete(ctx, step.ID); err != nil {
+				logger.WithError(err).WithField("step.id", step.ID).
+					Debugln("retention: cannot delete log index")
+			}
+		}
+	}
+
+	logger.Traceln("retention: build logs expired")
+	return r.Builds.Expire(ctx, build.ID)
+}
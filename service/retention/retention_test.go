@@ -0,0 +1,125 @@
+// Copyright 2019 Drone.IO Inc. All rights reserved.
+// Use of this source code is governed by the Drone Non-Commercial License
+// that can be found in the LICENSE file.
+
+package retention
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/drone/drone/core"
+	"github.com/drone/drone/mock"
+
+	"github.com/golang/mock/gomock"
+)
+
+var nocontext = context.Background()
+
+// this test confirms that the logs and cards are removed for
+// builds that exceed the retention policy, and that the most
+// recent builds for the default branch are kept.
+func TestExpire(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	now := time.Unix(1000000, 0)
+
+	mockRepo := &core.Repository{
+		ID:      1,
+		Branch:  "master",
+		Counter: 150,
+	}
+	mockKept := &core.Build{
+		ID:     1,
+		Number: 10,
+		Ref:    "refs/heads/master",
+	}
+	mockExpired := &core.Build{
+		ID:     2,
+		Number: 20,
+		Ref:    "refs/heads/feature",
+	}
+	mockStages := []*core.Stage{
+		{
+			ID: 1,
+			Steps: []*core.Step{
+				{ID: 3},
+				{ID: 4},
+			},
+		},
+	}
+
+	repos := mock.NewMockRepositoryStore(controller)
+	repos.EXPECT().ListAll(gomock.Any(), pageSize, 0).Return([]*core.Repository{mockRepo}, nil)
+
+	builds := mock.NewMockBuildStore(controller)
+	builds.EXPECT().ListRef(gomock.Any(), mockRepo.ID, "refs/heads/master", 1, 0).Return([]*core.Build{mockKept}, nil)
+	builds.EXPECT().ListExpirable(gomock.Any(), mockRepo.ID, core.ExpireParams{Number: 50, Limit: pageSize}).Return([]*core.Build{mockKept, mockExpired}, nil)
+	builds.EXPECT().Expire(gomock.Any(), mockExpired.ID).Return(nil)
+
+	stages := mock.NewMockStageStore(controller)
+	stages.EXPECT().ListSteps(gomock.Any(), mockExpired.ID).Return(mockStages, nil)
+
+	logs := mock.NewMockLogStore(controller)
+	logs.EXPECT().Delete(gomock.Any(), int64(3)).Return(nil)
+	logs.EXPECT().Delete(gomock.Any(), int64(4)).Return(nil)
+
+	cards := mock.NewMockCardStore(controller)
+	cards.EXPECT().Delete(gomock.Any(), int64(3)).Return(nil)
+	cards.EXPECT().Delete(gomock.Any(), int64(4)).Return(nil)
+
+	index := mock.NewMockLogIndex(controller)
+	index.EXPECT().Delete(gomock.Any(), int64(3)).Return(nil)
+	index.EXPECT().Delete(gomock.Any(), int64(4)).Return(nil)
+
+	policy := core.RetentionPolicy{MaxCount: 100, KeepDefault: 1}
+	r := New(repos, builds, stages, logs, cards, index, policy)
+	err := r.expire(nocontext, now)
+	if err != nil {
+		t.Error(err)
+	}
+}
+
+// this test confirms that builds that do not exceed the
+// maximum age for the build event are not expired.
+func TestExpire_Event(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	now := time.Unix(1000000, 0)
+	day := int64(60 * 60 * 24)
+
+	mockRepo := &core.Repository{
+		ID:      1,
+		Counter: 2,
+	}
+	mockBuilds := []*core.Build{
+		{ID: 1, Number: 1, Event: core.EventPush, Created: now.Unix() - day*2},
+		{ID: 2, Number: 2, Event: core.EventPullRequest, Created: now.Unix() - day*2},
+	}
+
+	repos := mock.NewMockRepositoryStore(controller)
+	repos.EXPECT().ListAll(gomock.Any(), pageSize, 0).Return([]*core.Repository{mockRepo}, nil)
+
+	params := core.ExpireParams{Before: now.Unix() - day, Limit: pageSize}
+	builds := mock.NewMockBuildStore(controller)
+	builds.EXPECT().ListExpirable(gomock.Any(), mockRepo.ID, params).Return(mockBuilds, nil)
+	builds.EXPECT().Expire(gomock.Any(), int64(2)).Return(nil)
+
+	stages := mock.NewMockStageStore(controller)
+	stages.EXPECT().ListSteps(gomock.Any(), int64(2)).Return(nil, nil)
+
+	policy := core.RetentionPolicy{
+		MaxAge: time.Hour * 24 * 7,
+		Events: map[string]time.Duration{
+			core.EventPullRequest: time.Hour * 24,
+		},
+	}
+	r := New(repos, builds, stages, nil, nil, nil, policy)
+	err := r.expire(nocontext, now)
+	if err != nil {
+		t.Error(err)
+	}
+}
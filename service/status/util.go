@@ -32,6 +32,8 @@ func createLabel(name, event string) string {
 		return fmt.Sprintf("%s/pr", name)
 	case core.EventTag:
 		return fmt.Sprintf("%s/tag", name)
+	case core.EventRelease:
+		return fmt.Sprintf("%s/release", name)
 	default:
 		return name
 	}
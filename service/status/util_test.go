@@ -29,6 +29,10 @@ func TestCreateLabel(t *testing.T) {
 			event: core.EventTag,
 			label: "continuous-integration/drone/tag",
 		},
+		{
+			event: core.EventRelease,
+			label: "continuous-integration/drone/release",
+		},
 		{
 			event: "unknown",
 			label: "continuous-integration/drone",
@@ -15,6 +15,8 @@
 package session
 
 import (
+	"context"
+	"errors"
 	"net/http"
 	"strings"
 	"time"
@@ -24,18 +26,24 @@ import (
 	"github.com/dchest/authcookie"
 )
 
+// errTokenExpired is returned when the personal access token
+// is expired.
+var errTokenExpired = errors.New("Token is expired")
+
 // New returns a new cookie-based session management.
-func New(users core.UserStore, config Config) core.Session {
+func New(users core.UserStore, tokens core.AccessTokenStore, config Config) core.Session {
 	return &session{
 		secret:  []byte(config.Secret),
 		secure:  config.Secure,
 		timeout: config.Timeout,
 		users:   users,
+		tokens:  tokens,
 	}
 }
 
 type session struct {
 	users   core.UserStore
+	tokens  core.AccessTokenStore
 	secret  []byte
 	secure  bool
 	timeout time.Duration
@@ -91,9 +99,34 @@ func (s *session) fromSession(r *http.Request) (*core.User, error) {
 }
 
 func (s *session) fromToken(r *http.Request) (*core.User, error) {
-	return s.users.FindToken(r.Context(),
-		extractToken(r),
-	)
+	token := extractToken(r)
+	if strings.HasPrefix(token, core.TokenPrefix) {
+		return s.fromAccessToken(r.Context(), token)
+	}
+	return s.users.FindToken(r.Context(), token)
+}
+
+func (s *session) fromAccessToken(ctx context.Context, raw string) (*core.User, error) {
+	token, err := s.tokens.FindHash(ctx, core.HashToken(raw))
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now().Unix()
+	if token.Expired(now) {
+		return nil, errTokenExpired
+	}
+	user, err := s.users.Find(ctx, token.UserID)
+	if err != nil {
+		return nil, err
+	}
+	// the last used timestamp is updated at most once per
+	// minute to limit writes to the database.
+	if now-token.LastUsed >= 60 {
+		token.LastUsed = now
+		s.tokens.Touch(ctx, token.ID, now)
+	}
+	user.AccessToken = token
+	return user, nil
 }
 
 func isAuthorizationToken(r *http.Request) bool {
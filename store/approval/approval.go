@@ -0,0 +1,151 @@
+// Copyright 2019 Drone IO, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package approval
+
+import (
+	"context"
+
+	"github.com/drone/drone/core"
+	"github.com/drone/drone/store/shared/db"
+)
+
+// New returns a new ApprovalStore.
+func New(db *db.DB) core.ApprovalStore {
+	return &approvalStore{db}
+}
+
+type approvalStore struct {
+	db *db.DB
+}
+
+func (s *approvalStore) List(ctx context.Context, id int64) ([]*core.Approval, error) {
+	var out []*core.Approval
+	err := s.db.View(func(queryer db.Queryer, binder db.Binder) error {
+		params := map[string]interface{}{"approval_build_id": id}
+		stmt, args, err := binder.BindNamed(queryBuild, params)
+		if err != nil {
+			return err
+		}
+		rows, err := queryer.Query(stmt, args...)
+		if err != nil {
+			return err
+		}
+		out, err = scanRows(rows)
+		return err
+	})
+	return out, err
+}
+
+func (s *approvalStore) Create(ctx context.Context, approval *core.Approval) error {
+	if s.db.Driver() == db.Postgres {
+		return s.createPostgres(ctx, approval)
+	}
+	return s.create(ctx, approval)
+}
+
+func (s *approvalStore) Purge(ctx context.Context, repo, before int64) error {
+	return s.db.Lock(func(execer db.Execer, binder db.Binder) error {
+		params := map[string]interface{}{
+			"approval_repo_id": repo,
+			"build_number":     before,
+		}
+		stmt, args, err := binder.BindNamed(stmtPurge, params)
+		if err != nil {
+			return err
+		}
+		_, err = execer.Exec(stmt, args...)
+		return err
+	})
+}
+
+func (s *approvalStore) create(ctx context.Context, approval *core.Approval) error {
+	return s.db.Lock(func(execer db.Execer, binder db.Binder) error {
+		params := toParams(approval)
+		stmt, args, err := binder.BindNamed(stmtInsert, params)
+		if err != nil {
+			return err
+		}
+		res, err := execer.Exec(stmt, args...)
+		if err != nil {
+			return err
+		}
+		approval.ID, err = res.LastInsertId()
+		return err
+	})
+}
+
+func (s *approvalStore) createPostgres(ctx context.Context, approval *core.Approval) error {
+	return s.db.Lock(func(execer db.Execer, binder db.Binder) error {
+		params := toParams(approval)
+		stmt, args, err := binder.BindNamed(stmtInsertPg, params)
+		if err != nil {
+			return err
+		}
+		return execer.QueryRow(stmt, args...).Scan(&approval.ID)
+	})
+}
+
+const queryBuild = `
+SELECT
+ approval_id
+,approval_repo_id
+,approval_build_id
+,approval_stage_id
+,approval_user_id
+,approval_login
+,approval_action
+,approval_comment
+,approval_created
+FROM approvals
+WHERE approval_build_id = :approval_build_id
+ORDER BY approval_id ASC
+`
+
+const stmtPurge = `
+DELETE FROM approvals
+WHERE approval_repo_id = :approval_repo_id
+  AND approval_build_id IN (
+    SELECT build_id
+    FROM builds
+    WHERE build_repo_id = :approval_repo_id
+      AND build_number < :build_number
+  )
+`
+
+const stmtInsert = `
+INSERT INTO approvals (
+ approval_repo_id
+,approval_build_id
+,approval_stage_id
+,approval_user_id
+,approval_login
+,approval_action
+,approval_comment
+,approval_created
+) VALUES (
+ :approval_repo_id
+,:approval_build_id
+,:approval_stage_id
+,:approval_user_id
+,:approval_login
+,:approval_action
+,:approval_comment
+,:approval_created
+)
+`
+
+const stmtInsertPg = stmtInsert + `
+RETURNING approval_id
+`
@@ -0,0 +1,147 @@
+// Copyright 2019 Drone.IO Inc. All rights reserved.
+// Use of this source code is governed by the Drone Non-Commercial License
+// that can be found in the LICENSE file.
+
+// +build !oss
+
+package approval
+
+import (
+	"context"
+	"testing"
+
+	"github.com/drone/drone/core"
+	"github.com/drone/drone/store/build"
+	"github.com/drone/drone/store/shared/db"
+	"github.com/drone/drone/store/shared/db/dbtest"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+var noContext = context.TODO()
+
+func TestApproval(t *testing.T) {
+	conn, err := dbtest.Connect()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	defer func() {
+		dbtest.Reset(conn)
+		dbtest.Disconnect(conn)
+	}()
+
+	store := New(conn).(*approvalStore)
+	t.Run("Create", testApprovalCreate(store))
+	t.Run("Purge", testApprovalPurge(conn))
+}
+
+func testApprovalCreate(store *approvalStore) func(t *testing.T) {
+	return func(t *testing.T) {
+		items := []*core.Approval{
+			{
+				RepoID:  1,
+				BuildID: 2,
+				StageID: 3,
+				UserID:  4,
+				Login:   "octocat",
+				Action:  core.ApprovalApprove,
+				Comment: "lgtm",
+				Created: 1522878684,
+			},
+			{
+				RepoID:  1,
+				BuildID: 2,
+				StageID: 3,
+				UserID:  5,
+				Login:   "spaceghost",
+				Action:  core.ApprovalDecline,
+				Created: 1522878685,
+			},
+			{
+				RepoID:  1,
+				BuildID: 6,
+				StageID: 7,
+				UserID:  4,
+				Login:   "octocat",
+				Action:  core.ApprovalApprove,
+				Created: 1522878686,
+			},
+		}
+		for _, item := range items {
+			err := store.Create(noContext, item)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			if item.ID == 0 {
+				t.Errorf("Want approval ID assigned, got %d", item.ID)
+			}
+		}
+
+		t.Run("List", testApprovalList(store, items[:2]))
+	}
+}
+
+func testApprovalPurge(conn *db.DB) func(t *testing.T) {
+	return func(t *testing.T) {
+		dbtest.Reset(conn)
+
+		// seeds the database with two builds.
+		builds := build.New(conn)
+		first := &core.Build{RepoID: 1, Number: 1}
+		second := &core.Build{RepoID: 1, Number: 2}
+		for _, item := range []*core.Build{first, second} {
+			if err := builds.Create(noContext, item, nil); err != nil {
+				t.Error(err)
+				return
+			}
+		}
+
+		store := New(conn).(*approvalStore)
+		for _, item := range []*core.Approval{
+			{RepoID: 1, BuildID: first.ID, Login: "octocat", Action: core.ApprovalApprove},
+			{RepoID: 1, BuildID: second.ID, Login: "octocat", Action: core.ApprovalApprove},
+		} {
+			if err := store.Create(noContext, item); err != nil {
+				t.Error(err)
+				return
+			}
+		}
+
+		err := store.Purge(noContext, 1, 2)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		purged, err := store.List(noContext, first.ID)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if got, want := len(purged), 0; got != want {
+			t.Errorf("Want %d approvals of purged build, got %d", want, got)
+		}
+		kept, err := store.List(noContext, second.ID)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if got, want := len(kept), 1; got != want {
+			t.Errorf("Want %d approvals of newer build, got %d", want, got)
+		}
+	}
+}
+
+func testApprovalList(store *approvalStore, want []*core.Approval) func(t *testing.T) {
+	return func(t *testing.T) {
+		got, err := store.List(noContext, 2)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if diff := cmp.Diff(got, want); diff != "" {
+			t.Errorf(diff)
+		}
+	}
+}
@@ -0,0 +1,71 @@
+// Copyright 2019 Drone IO, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package approval
+
+import (
+	"database/sql"
+
+	"github.com/drone/drone/core"
+	"github.com/drone/drone/store/shared/db"
+)
+
+// helper function converts the Approval structure to a set
+// of named query parameters.
+func toParams(approval *core.Approval) map[string]interface{} {
+	return map[string]interface{}{
+		"approval_id":       approval.ID,
+		"approval_repo_id":  approval.RepoID,
+		"approval_build_id": approval.BuildID,
+		"approval_stage_id": approval.StageID,
+		"approval_user_id":  approval.UserID,
+		"approval_login":    approval.Login,
+		"approval_action":   approval.Action,
+		"approval_comment":  approval.Comment,
+		"approval_created":  approval.Created,
+	}
+}
+
+// helper function scans the sql.Row and copies the column
+// values to the destination object.
+func scanRow(scanner db.Scanner, dest *core.Approval) error {
+	return scanner.Scan(
+		&dest.ID,
+		&dest.RepoID,
+		&dest.BuildID,
+		&dest.StageID,
+		&dest.UserID,
+		&dest.Login,
+		&dest.Action,
+		&dest.Comment,
+		&dest.Created,
+	)
+}
+
+// helper function scans the sql.Row and copies the column
+// values to the destination object.
+func scanRows(rows *sql.Rows) ([]*core.Approval, error) {
+	defer rows.Close()
+
+	approvals := []*core.Approval{}
+	for rows.Next() {
+		approval := new(core.Approval)
+		err := scanRow(rows, approval)
+		if err != nil {
+			return nil, err
+		}
+		approvals = append(approvals, approval)
+	}
+	return approvals, nil
+}
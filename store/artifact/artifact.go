@@ -0,0 +1,322 @@
+// Copyright 2019 Drone IO, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package artifact
+
+import (
+	"context"
+	"io"
+
+	"github.com/dchest/uniuri"
+	"github.com/drone/drone/core"
+	"github.com/drone/drone/store/shared/db"
+)
+
+// New returns a new ArtifactStore. Artifact metadata is
+// persisted to the database and the artifact contents are
+// persisted to the blob store.
+func New(db *db.DB, blobs Blobs) core.ArtifactStore {
+	return &artifactStore{
+		db:    db,
+		blobs: blobs,
+	}
+}
+
+type artifactStore struct {
+	db    *db.DB
+	blobs Blobs
+}
+
+func (s *artifactStore) List(ctx context.Context, id int64) ([]*core.Artifact, error) {
+	var out []*core.Artifact
+	err := s.db.View(func(queryer db.Queryer, binder db.Binder) error {
+		params := map[string]interface{}{"artifact_build_id": id}
+		stmt, args, err := binder.BindNamed(queryBuild, params)
+		if err != nil {
+			return err
+		}
+		rows, err := queryer.Query(stmt, args...)
+		if err != nil {
+			return err
+		}
+		out, err = scanRows(rows)
+		return err
+	})
+	return out, err
+}
+
+func (s *artifactStore) FindName(ctx context.Context, id int64, name string) (*core.Artifact, error) {
+	out := &core.Artifact{BuildID: id, Name: name}
+	err := s.db.View(func(queryer db.Queryer, binder db.Binder) error {
+		params := toParams(out)
+		query, args, err := binder.BindNamed(queryName, params)
+		if err != nil {
+			return err
+		}
+		row := queryer.QueryRow(query, args...)
+		return scanRow(row, out)
+	})
+	return out, err
+}
+
+func (s *artifactStore) Usage(ctx context.Context, id int64) (int64, error) {
+	var out int64
+	err := s.db.View(func(queryer db.Queryer, binder db.Binder) error {
+		params := map[string]interface{}{"artifact_repo_id": id}
+		query, args, err := binder.BindNamed(queryUsage, params)
+		if err != nil {
+			return err
+		}
+		return queryer.QueryRow(query, args...).Scan(&out)
+	})
+	return out, err
+}
+
+func (s *artifactStore) Open(ctx context.Context, artifact *core.Artifact) (io.ReadCloser, error) {
+	return s.blobs.Find(ctx, artifact.ID)
+}
+
+func (s *artifactStore) Create(ctx context.Context, artifact *core.Artifact, r io.Reader) error {
+	// the artifact metadata is created with a pending name,
+	// which is never a valid artifact name, so that an existing
+	// artifact with the same name (for example, uploaded before
+	// the stage was restarted) is kept until the upload succeeds.
+	name := artifact.Name
+	artifact.Name = pendingPrefix + uniuri.NewLen(20)
+
+	var err error
+	if s.db.Driver() == db.Postgres {
+		err = s.createPostgres(ctx, artifact)
+	} else {
+		err = s.create(ctx, artifact)
+	}
+	if err != nil {
+		artifact.Name = name
+		return err
+	}
+
+	counter := &countingReader{r: r}
+	if err := s.blobs.Create(ctx, artifact.ID, counter); err != nil {
+		s.delete(ctx, artifact)
+		artifact.Name = name
+		return err
+	}
+	artifact.Name = name
+	artifact.Size = counter.n
+
+	prev, err := s.FindName(ctx, artifact.BuildID, name)
+	if err != nil {
+		prev = nil
+	}
+	if err := s.replace(ctx, prev, artifact); err != nil {
+		s.delete(ctx, artifact)
+		return err
+	}
+	if prev != nil {
+		s.blobs.Delete(ctx, prev.ID)
+	}
+	return nil
+}
+
+func (s *artifactStore) Purge(ctx context.Context, repo, before int64) error {
+	var artifacts []*core.Artifact
+	err := s.db.View(func(queryer db.Queryer, binder db.Binder) error {
+		params := map[string]interface{}{
+			"artifact_repo_id": repo,
+			"build_number":     before,
+		}
+		stmt, args, err := binder.BindNamed(queryPurge, params)
+		if err != nil {
+			return err
+		}
+		rows, err := queryer.Query(stmt, args...)
+		if err != nil {
+			return err
+		}
+		artifacts, err = scanRows(rows)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	for _, artifact := range artifacts {
+		if err := s.delete(ctx, artifact); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *artifactStore) create(ctx context.Context, artifact *core.Artifact) error {
+	return s.db.Lock(func(execer db.Execer, binder db.Binder) error {
+		params := toParams(artifact)
+		stmt, args, err := binder.BindNamed(stmtInsert, params)
+		if err != nil {
+			return err
+		}
+		res, err := execer.Exec(stmt, args...)
+		if err != nil {
+			return err
+		}
+		artifact.ID, err = res.LastInsertId()
+		return err
+	})
+}
+
+func (s *artifactStore) createPostgres(ctx context.Context, artifact *core.Artifact) error {
+	return s.db.Lock(func(execer db.Execer, binder db.Binder) error {
+		params := toParams(artifact)
+		stmt, args, err := binder.BindNamed(stmtInsertPg, params)
+		if err != nil {
+			return err
+		}
+		return execer.QueryRow(stmt, args...).Scan(&artifact.ID)
+	})
+}
+
+// replace deletes the metadata of the previous artifact, if
+// any, and renames the pending artifact in a single transaction.
+func (s *artifactStore) replace(ctx context.Context, prev, artifact *core.Artifact) error {
+	return s.db.Update(func(execer db.Execer, binder db.Binder) error {
+		if prev != nil {
+			params := toParams(prev)
+			stmt, args, err := binder.BindNamed(stmtDelete, params)
+			if err != nil {
+				return err
+			}
+			if _, err := execer.Exec(stmt, args...); err != nil {
+				return err
+			}
+		}
+		params := toParams(artifact)
+		stmt, args, err := binder.BindNamed(stmtUpdate, params)
+		if err != nil {
+			return err
+		}
+		_, err = execer.Exec(stmt, args...)
+		return err
+	})
+}
+
+// delete deletes the artifact contents and metadata. The
+// contents may not exist if the upload failed, in which case
+// the error is ignored.
+func (s *artifactStore) delete(ctx context.Context, artifact *core.Artifact) error {
+	s.blobs.Delete(ctx, artifact.ID)
+	return s.db.Lock(func(execer db.Execer, binder db.Binder) error {
+		params := toParams(artifact)
+		stmt, args, err := binder.BindNamed(stmtDelete, params)
+		if err != nil {
+			return err
+		}
+		_, err = execer.Exec(stmt, args...)
+		return err
+	})
+}
+
+// countingReader counts the number of bytes read.
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
+
+// pendingPrefix prefixes the name of an artifact while it is
+// uploaded. Artifact names are relative paths, so a pending
+// name never collides with a valid artifact name.
+const pendingPrefix = "/pending/"
+
+const queryBase = `
+SELECT
+ artifact_id
+,artifact_repo_id
+,artifact_build_id
+,artifact_stage_id
+,artifact_step_id
+,artifact_name
+,artifact_size
+,artifact_created
+`
+
+const queryBuild = queryBase + `
+FROM artifacts
+WHERE artifact_build_id = :artifact_build_id
+  AND artifact_name NOT LIKE '/%'
+ORDER BY artifact_name ASC
+`
+
+const queryName = queryBase + `
+FROM artifacts
+WHERE artifact_build_id = :artifact_build_id
+  AND artifact_name = :artifact_name
+`
+
+const queryPurge = queryBase + `
+FROM artifacts
+WHERE artifact_repo_id = :artifact_repo_id
+  AND artifact_build_id IN (
+    SELECT build_id
+    FROM builds
+    WHERE build_repo_id = :artifact_repo_id
+      AND build_number < :build_number
+  )
+`
+
+const queryUsage = `
+SELECT COALESCE(SUM(artifact_size), 0)
+FROM artifacts
+WHERE artifact_repo_id = :artifact_repo_id
+`
+
+const stmtInsert = `
+INSERT INTO artifacts (
+ artifact_repo_id
+,artifact_build_id
+,artifact_stage_id
+,artifact_step_id
+,artifact_name
+,artifact_size
+,artifact_created
+) VALUES (
+ :artifact_repo_id
+,:artifact_build_id
+,:artifact_stage_id
+,:artifact_step_id
+,:artifact_name
+,:artifact_size
+,:artifact_created
+)
+`
+
+const stmtInsertPg = stmtInsert + `
+RETURNING artifact_id
+`
+
+const stmtUpdate = `
+UPDATE artifacts
+SET
+ artifact_name = :artifact_name
+,artifact_size = :artifact_size
+WHERE artifact_id = :artifact_id
+`
+
+const stmtDelete = `
+DELETE FROM artifacts
+WHERE artifact_id = :artifact_id
+`
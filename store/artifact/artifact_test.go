@@ -0,0 +1,247 @@
+// Copyright 2019 Drone.IO Inc. All rights reserved.
+// Use of this source code is governed by the Drone Non-Commercial License
+// that can be found in the LICENSE file.
+
+// +build !oss
+
+package artifact
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"errors"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/drone/drone/core"
+	"github.com/drone/drone/store/build"
+	"github.com/drone/drone/store/shared/db"
+	"github.com/drone/drone/store/shared/db/dbtest"
+)
+
+var noContext = context.TODO()
+
+func TestArtifact(t *testing.T) {
+	conn, err := dbtest.Connect()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	defer func() {
+		dbtest.Reset(conn)
+		dbtest.Disconnect(conn)
+	}()
+
+	dir, err := ioutil.TempDir("", "artifacts")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	defer os.RemoveAll(dir)
+
+	t.Run("Database", testArtifactStore(conn, NewDatabase(conn, 1024)))
+	t.Run("Filesystem", testArtifactStore(conn, NewFilesystem(dir)))
+	t.Run("MaxSize", testArtifactMaxSize(conn))
+}
+
+func testArtifactStore(conn *db.DB, blobs Blobs) func(t *testing.T) {
+	return func(t *testing.T) {
+		dbtest.Reset(conn)
+
+		// seeds the database with two builds.
+		builds := build.New(conn)
+		first := &core.Build{RepoID: 1, Number: 1}
+		second := &core.Build{RepoID: 1, Number: 2}
+		for _, item := range []*core.Build{first, second} {
+			if err := builds.Create(noContext, item, nil); err != nil {
+				t.Error(err)
+				return
+			}
+		}
+
+		store := New(conn, blobs).(*artifactStore)
+		items := []*core.Artifact{
+			{RepoID: 1, BuildID: first.ID, StepID: 1, Name: "dist/app.tar.gz"},
+			{RepoID: 1, BuildID: second.ID, StepID: 2, Name: "coverage.out"},
+		}
+		for _, item := range items {
+			err := store.Create(noContext, item, bytes.NewBufferString("hello world"))
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			if item.ID == 0 {
+				t.Errorf("Want artifact ID assigned, got %d", item.ID)
+			}
+			if got, want := item.Size, int64(11); got != want {
+				t.Errorf("Want artifact size %d, got %d", want, got)
+			}
+		}
+
+		t.Run("Open", testArtifactOpen(store, items[0]))
+		t.Run("Replace", testArtifactReplace(store, items[1]))
+		t.Run("ReplaceFailed", testArtifactReplaceFailed(store, items[1]))
+		t.Run("Usage", testArtifactUsage(store))
+		t.Run("Purge", testArtifactPurge(store, items[0], items[1]))
+	}
+}
+
+func testArtifactOpen(store *artifactStore, artifact *core.Artifact) func(t *testing.T) {
+	return func(t *testing.T) {
+		item, err := store.FindName(noContext, artifact.BuildID, artifact.Name)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		rc, err := store.Open(noContext, item)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		defer rc.Close()
+		data, _ := ioutil.ReadAll(rc)
+		if got, want := string(data), "hello world"; got != want {
+			t.Errorf("Want artifact contents %q, got %q", want, got)
+		}
+	}
+}
+
+func testArtifactReplace(store *artifactStore, artifact *core.Artifact) func(t *testing.T) {
+	return func(t *testing.T) {
+		item := &core.Artifact{
+			RepoID:  artifact.RepoID,
+			BuildID: artifact.BuildID,
+			Name:    artifact.Name,
+		}
+		err := store.Create(noContext, item, bytes.NewBufferString("hello"))
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		list, err := store.List(noContext, artifact.BuildID)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if got, want := len(list), 1; got != want {
+			t.Errorf("Want %d artifacts, got %d", want, got)
+			return
+		}
+		if got, want := list[0].Size, int64(5); got != want {
+			t.Errorf("Want replaced artifact size %d, got %d", want, got)
+		}
+		if _, err := store.blobs.Find(noContext, artifact.ID); err == nil {
+			t.Errorf("Want replaced artifact contents deleted")
+		}
+	}
+}
+
+func testArtifactReplaceFailed(store *artifactStore, artifact *core.Artifact) func(t *testing.T) {
+	return func(t *testing.T) {
+		item := &core.Artifact{
+			RepoID:  artifact.RepoID,
+			BuildID: artifact.BuildID,
+			Name:    artifact.Name,
+		}
+		err := store.Create(noContext, item, &errReader{})
+		if err != errUpload {
+			t.Errorf("Want upload error, got %v", err)
+		}
+		list, err := store.List(noContext, artifact.BuildID)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if got, want := len(list), 1; got != want {
+			t.Errorf("Want %d artifacts, got %d", want, got)
+			return
+		}
+		if got, want := list[0].Size, int64(5); got != want {
+			t.Errorf("Want previous artifact kept with size %d, got %d", want, got)
+		}
+		rc, err := store.Open(noContext, list[0])
+		if err != nil {
+			t.Errorf("Want previous artifact contents kept, got %v", err)
+			return
+		}
+		defer rc.Close()
+		data, _ := ioutil.ReadAll(rc)
+		if got, want := string(data), "hello"; got != want {
+			t.Errorf("Want artifact contents %q, got %q", want, got)
+		}
+	}
+}
+
+func testArtifactMaxSize(conn *db.DB) func(t *testing.T) {
+	return func(t *testing.T) {
+		dbtest.Reset(conn)
+
+		store := New(conn, NewDatabase(conn, 5))
+		item := &core.Artifact{RepoID: 1, BuildID: 1, StepID: 1, Name: "dist/app.tar.gz"}
+		err := store.Create(noContext, item, bytes.NewBufferString("hello world"))
+		if got, want := err, core.ErrArtifactTooLarge; got != want {
+			t.Errorf("Want ErrArtifactTooLarge, got %v", got)
+		}
+		usage, err := store.Usage(noContext, 1)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if usage != 0 {
+			t.Errorf("Want rejected artifact removed, got usage %d", usage)
+		}
+
+		err = store.Create(noContext, item, bytes.NewBufferString("hello"))
+		if err != nil {
+			t.Errorf("Want artifact within the size limit stored, got %v", err)
+		}
+	}
+}
+
+func testArtifactUsage(store *artifactStore) func(t *testing.T) {
+	return func(t *testing.T) {
+		usage, err := store.Usage(noContext, 1)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if got, want := usage, int64(16); got != want {
+			t.Errorf("Want artifact usage %d, got %d", want, got)
+		}
+	}
+}
+
+func testArtifactPurge(store *artifactStore, purged, kept *core.Artifact) func(t *testing.T) {
+	return func(t *testing.T) {
+		err := store.Purge(noContext, 1, 2)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		_, err = store.FindName(noContext, purged.BuildID, purged.Name)
+		if got, want := err, sql.ErrNoRows; got != want {
+			t.Errorf("Want sql.ErrNoRows for purged artifact, got %v", got)
+		}
+		_, err = store.FindName(noContext, kept.BuildID, kept.Name)
+		if err != nil {
+			t.Errorf("Want artifact of newer build kept, got %v", err)
+		}
+	}
+}
+
+var errUpload = errors.New("upload failed")
+
+// errReader returns an error after the first read.
+type errReader struct {
+	read bool
+}
+
+func (r *errReader) Read(p []byte) (int, error) {
+	if r.read {
+		return 0, errUpload
+	}
+	r.read = true
+	return copy(p, "partial"), nil
+}
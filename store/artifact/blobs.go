@@ -0,0 +1,121 @@
+// Copyright 2019 Drone IO, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package artifact
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"io/ioutil"
+
+	"github.com/drone/drone/core"
+	"github.com/drone/drone/store/shared/db"
+)
+
+// Blobs persists artifact contents to storage.
+type Blobs interface {
+	// Find returns the artifact contents from the datastore.
+	Find(ctx context.Context, id int64) (io.ReadCloser, error)
+
+	// Create copies the artifact contents from Reader r to
+	// the datastore.
+	Create(ctx context.Context, id int64, r io.Reader) error
+
+	// Delete purges the artifact contents from the datastore.
+	Delete(ctx context.Context, id int64) error
+}
+
+// NewDatabase returns a new Blobs store that persists the
+// artifact contents to the database. The artifact contents
+// are buffered in memory, so uploads larger than max bytes
+// are rejected.
+func NewDatabase(db *db.DB, max int64) Blobs {
+	return &dbBlobs{db: db, max: max}
+}
+
+type dbBlobs struct {
+	db  *db.DB
+	max int64
+}
+
+func (s *dbBlobs) Find(ctx context.Context, id int64) (io.ReadCloser, error) {
+	var data []byte
+	err := s.db.View(func(queryer db.Queryer, binder db.Binder) error {
+		params := map[string]interface{}{"artifact_id": id}
+		query, args, err := binder.BindNamed(queryData, params)
+		if err != nil {
+			return err
+		}
+		return queryer.QueryRow(query, args...).Scan(&data)
+	})
+	return ioutil.NopCloser(
+		bytes.NewBuffer(data),
+	), err
+}
+
+func (s *dbBlobs) Create(ctx context.Context, id int64, r io.Reader) error {
+	data, err := ioutil.ReadAll(io.LimitReader(r, s.max+1))
+	if err != nil {
+		return err
+	}
+	if int64(len(data)) > s.max {
+		return core.ErrArtifactTooLarge
+	}
+	return s.db.Lock(func(execer db.Execer, binder db.Binder) error {
+		params := map[string]interface{}{
+			"artifact_id":   id,
+			"artifact_data": data,
+		}
+		stmt, args, err := binder.BindNamed(stmtInsertData, params)
+		if err != nil {
+			return err
+		}
+		_, err = execer.Exec(stmt, args...)
+		return err
+	})
+}
+
+func (s *dbBlobs) Delete(ctx context.Context, id int64) error {
+	return s.db.Lock(func(execer db.Execer, binder db.Binder) error {
+		params := map[string]interface{}{"artifact_id": id}
+		stmt, args, err := binder.BindNamed(stmtDeleteData, params)
+		if err != nil {
+			return err
+		}
+		_, err = execer.Exec(stmt, args...)
+		return err
+	})
+}
+
+const queryData = `
+SELECT artifact_data
+FROM artifact_data
+WHERE artifact_id = :artifact_id
+`
+
+const stmtInsertData = `
+INSERT INTO artifact_data (
+ artifact_id
+,artifact_data
+) VALUES (
+ :artifact_id
+,:artifact_data
+)
+`
+
+const stmtDeleteData = `
+DELETE FROM artifact_data
+WHERE artifact_id = :artifact_id
+`
@@ -0,0 +1,61 @@
+// Copyright 2019 Drone IO, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package artifact
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// NewFilesystem returns a new Blobs store that persists the
+// artifact contents to the local filesystem.
+func NewFilesystem(root string) Blobs {
+	return &fsBlobs{root: root}
+}
+
+type fsBlobs struct {
+	root string
+}
+
+func (s *fsBlobs) Find(ctx context.Context, id int64) (io.ReadCloser, error) {
+	return os.Open(s.path(id))
+}
+
+func (s *fsBlobs) Create(ctx context.Context, id int64, r io.Reader) error {
+	err := os.MkdirAll(s.root, 0700)
+	if err != nil {
+		return err
+	}
+	f, err := os.OpenFile(s.path(id), os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(f, r)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+func (s *fsBlobs) Delete(ctx context.Context, id int64) error {
+	return os.Remove(s.path(id))
+}
+
+func (s *fsBlobs) path(id int64) string {
+	return filepath.Join(s.root, fmt.Sprint(id))
+}
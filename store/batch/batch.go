@@ -187,6 +187,7 @@ const stmtInsertBase = `
 ,repo_cancel_pulls
 ,repo_cancel_push
 ,repo_cancel_running
+,repo_requeue_lost
 ,repo_synced
 ,repo_created
 ,repo_updated
@@ -218,6 +219,7 @@ const stmtInsertBase = `
 ,:repo_cancel_pulls
 ,:repo_cancel_push
 ,:repo_cancel_running
+,:repo_requeue_lost
 ,:repo_synced
 ,:repo_created
 ,:repo_updated
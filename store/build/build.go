@@ -18,6 +18,7 @@ import (
 	"context"
 	"fmt"
 	"regexp"
+	"strings"
 	"time"
 
 	"github.com/drone/drone/core"
@@ -129,6 +130,86 @@ func (s *buildStore) ListRef(ctx context.Context, repo int64, ref string, limit,
 	return out, err
 }
 
+// Search returns a list of builds from the datastore by
+// repository id, filtered by the query parameters.
+func (s *buildStore) Search(ctx context.Context, repo int64, params core.BuildParams) ([]*core.Build, error) {
+	var out []*core.Build
+	err := s.db.View(func(queryer db.Queryer, binder db.Binder) error {
+		query, named := searchQuery(repo, params)
+		stmt, args, err := binder.BindNamed(query, named)
+		if err != nil {
+			return err
+		}
+		rows, err := queryer.Query(stmt, args...)
+		if err != nil {
+			return err
+		}
+		out, err = scanRows(rows)
+		return err
+	})
+	return out, err
+}
+
+// helper function returns the build search query and the
+// named query parameters. Only non-empty parameters are
+// included in the query.
+func searchQuery(repo int64, params core.BuildParams) (string, map[string]interface{}) {
+	args := map[string]interface{}{
+		"build_repo_id": repo,
+		"limit":         params.Limit,
+		"offset":        params.Offset,
+	}
+
+	var b strings.Builder
+	b.WriteString(queryBase)
+	b.WriteString("FROM builds\nWHERE build_repo_id = :build_repo_id\n")
+
+	if len(params.Status) != 0 {
+		var keys []string
+		for i, status := range params.Status {
+			key := fmt.Sprintf("build_status_%d", i)
+			args[key] = status
+			keys = append(keys, ":"+key)
+		}
+		fmt.Fprintf(&b, "  AND build_status IN (%s)\n", strings.Join(keys, ","))
+	}
+
+	filters := []struct {
+		column string
+		value  string
+	}{
+		{"build_event", params.Event},
+		{"build_author", params.Author},
+		{"build_sender", params.Sender},
+		{"build_ref", params.Ref},
+		{"build_target", params.Target},
+		{"build_deploy", params.Deploy},
+		{"build_cron", params.Cron},
+	}
+	for _, filter := range filters {
+		if filter.value != "" {
+			args[filter.column] = filter.value
+			fmt.Fprintf(&b, "  AND %s = :%s\n", filter.column, filter.column)
+		}
+	}
+
+	if params.Commit != "" {
+		args["build_after"] = params.Commit + "%"
+		b.WriteString("  AND build_after LIKE :build_after\n")
+	}
+	if params.Since != 0 {
+		args["since"] = params.Since
+		b.WriteString("  AND build_created >= :since\n")
+	}
+	if params.Until != 0 {
+		args["until"] = params.Until
+		b.WriteString("  AND build_created <= :until\n")
+	}
+
+	b.WriteString("ORDER BY build_id DESC\nLIMIT :limit OFFSET :offset\n")
+	return b.String(), args
+}
+
 // LatestBranches returns a list of the latest build by branch.
 func (s *buildStore) LatestBranches(ctx context.Context, repo int64) ([]*core.Build, error) {
 	return s.latest(ctx, repo, "branch")
@@ -427,6 +508,50 @@ func (s *buildStore) Purge(ctx context.Context, repo, number int64) error {
 	})
 }
 
+// ListExpirable returns a list of completed builds from the
+// datastore, by repository id, with logs that have not yet been
+// expired, ordered by build number.
+func (s *buildStore) ListExpirable(ctx context.Context, repo int64, opts core.ExpireParams) ([]*core.Build, error) {
+	var out []*core.Build
+	err := s.db.View(func(queryer db.Queryer, binder db.Binder) error {
+		params := map[string]interface{}{
+			"build_repo_id": repo,
+			"build_expired": false,
+			"after":         opts.After,
+			"number":        opts.Number,
+			"before":        opts.Before,
+			"limit":         opts.Limit,
+		}
+		query, args, err := binder.BindNamed(queryExpirable, params)
+		if err != nil {
+			return err
+		}
+		rows, err := queryer.Query(query, args...)
+		if err != nil {
+			return err
+		}
+		out, err = scanRows(rows)
+		return err
+	})
+	return out, err
+}
+
+// Expire marks the build logs as expired.
+func (s *buildStore) Expire(ctx context.Context, id int64) error {
+	return s.db.Lock(func(execer db.Execer, binder db.Binder) error {
+		params := map[string]interface{}{
+			"build_id":      id,
+			"build_expired": true,
+		}
+		stmt, args, err := binder.BindNamed(stmtExpire, params)
+		if err != nil {
+			return err
+		}
+		_, err = execer.Exec(stmt, args...)
+		return err
+	})
+}
+
 // Count returns a count of builds.
 func (s *buildStore) Count(ctx context.Context) (i int64, err error) {
 	err = s.db.View(func(queryer db.Queryer, binder db.Binder) error {
@@ -471,6 +596,7 @@ SELECT
 ,build_deploy
 ,build_deploy_id
 ,build_debug
+,build_superseded
 ,build_started
 ,build_finished
 ,build_created
@@ -571,6 +697,7 @@ UPDATE builds SET
 ,build_params = :build_params
 ,build_cron = :build_cron
 ,build_deploy = :build_deploy
+,build_superseded = :build_superseded
 ,build_started = :build_started
 ,build_finished = :build_finished
 ,build_updated = :build_updated
@@ -609,6 +736,7 @@ INSERT INTO builds (
 ,build_deploy
 ,build_deploy_id
 ,build_debug
+,build_superseded
 ,build_started
 ,build_finished
 ,build_created
@@ -643,6 +771,7 @@ INSERT INTO builds (
 ,:build_deploy
 ,:build_deploy_id
 ,:build_debug
+,:build_superseded
 ,:build_started
 ,:build_finished
 ,:build_created
@@ -683,6 +812,9 @@ INSERT INTO stages (
 ,stage_on_failure
 ,stage_depends_on
 ,stage_labels
+,stage_tolerates
+,stage_priority
+,stage_timeout
 ) VALUES (
  :stage_repo_id
 ,:stage_build_id
@@ -710,6 +842,9 @@ INSERT INTO stages (
 ,:stage_on_failure
 ,:stage_depends_on
 ,:stage_labels
+,:stage_tolerates
+,:stage_priority
+,:stage_timeout
 )
 `
 
@@ -722,6 +857,23 @@ DELETE FROM builds
 WHERE build_id = :build_id
 `
 
+const queryExpirable = queryBase + `
+FROM builds
+WHERE build_repo_id = :build_repo_id
+  AND build_expired = :build_expired
+  AND build_number > :after
+  AND build_status NOT IN ('waiting_on_dependencies', 'pending', 'running', 'blocked')
+  AND (build_number <= :number OR build_created < :before)
+ORDER BY build_number ASC
+LIMIT :limit
+`
+
+const stmtExpire = `
+UPDATE builds
+SET build_expired = :build_expired
+WHERE build_id = :build_id
+`
+
 const stmtPurge = `
 DELETE FROM builds
 WHERE build_repo_id = :build_repo_id
@@ -65,6 +65,8 @@ func testBuildCreate(store *buildStore) func(t *testing.T) {
 		t.Run("FindRef", testBuildFindRef(store, build))
 		t.Run("List", testBuildList(store, build))
 		t.Run("ListRef", testBuildListRef(store, build))
+		t.Run("Search", testBuildSearch(store, build))
+		t.Run("Expire", testBuildExpire(store, build))
 		t.Run("Update", testBuildUpdate(store, build))
 		t.Run("Locking", testBuildLocking(store, build))
 		t.Run("Delete", testBuildDelete(store, build))
@@ -134,6 +136,76 @@ func testBuildListRef(store *buildStore, build *core.Build) func(t *testing.T) {
 	}
 }
 
+func testBuildSearch(store *buildStore, build *core.Build) func(t *testing.T) {
+	return func(t *testing.T) {
+		tests := []struct {
+			params core.BuildParams
+			count  int
+		}{
+			{core.BuildParams{}, 1},
+			{core.BuildParams{Event: core.EventPush, Target: "master"}, 1},
+			{core.BuildParams{Ref: "refs/heads/master"}, 1},
+			{core.BuildParams{Event: core.EventPullRequest}, 0},
+			{core.BuildParams{Status: []string{core.StatusFailing, core.StatusError}}, 0},
+			{core.BuildParams{Author: "octocat"}, 0},
+			{core.BuildParams{Commit: "7fd1a60"}, 0},
+			{core.BuildParams{Since: build.Created + 1}, 0},
+		}
+		for i, test := range tests {
+			test.params.Limit = 10
+			list, err := store.Search(noContext, build.RepoID, test.params)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			if got, want := len(list), test.count; got != want {
+				t.Errorf("Want search count %d, got %d at index %d", want, got, i)
+			}
+		}
+	}
+}
+
+func testBuildExpire(store *buildStore, build *core.Build) func(t *testing.T) {
+	return func(t *testing.T) {
+		tests := []struct {
+			params core.ExpireParams
+			count  int
+		}{
+			{core.ExpireParams{}, 0},
+			{core.ExpireParams{Number: 99}, 1},
+			{core.ExpireParams{Number: 98}, 0},
+			{core.ExpireParams{Before: build.Created + 1}, 1},
+			{core.ExpireParams{Before: build.Created}, 0},
+			{core.ExpireParams{Number: 99, After: 99}, 0},
+		}
+		for i, test := range tests {
+			test.params.Limit = 10
+			list, err := store.ListExpirable(noContext, build.RepoID, test.params)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			if got, want := len(list), test.count; got != want {
+				t.Errorf("Want expirable count %d, got %d at index %d", want, got, i)
+			}
+		}
+
+		err := store.Expire(noContext, build.ID)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		list, err := store.ListExpirable(noContext, build.RepoID, core.ExpireParams{Number: 99, Limit: 10})
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if len(list) != 0 {
+			t.Errorf("Want expired build excluded from results")
+		}
+	}
+}
+
 func testBuildUpdate(store *buildStore, build *core.Build) func(t *testing.T) {
 	return func(t *testing.T) {
 		before := &core.Build{
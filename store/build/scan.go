@@ -57,6 +57,7 @@ func toParams(build *core.Build) map[string]interface{} {
 		"build_deploy":        build.Deploy,
 		"build_deploy_id":     build.DeployID,
 		"build_debug":         build.Debug,
+		"build_superseded":    build.Superseded,
 		"build_started":       build.Started,
 		"build_finished":      build.Finished,
 		"build_created":       build.Created,
@@ -96,6 +97,9 @@ func toStageParams(stage *core.Stage) map[string]interface{} {
 		"stage_on_failure": stage.OnFailure,
 		"stage_depends_on": encodeSlice(stage.DependsOn),
 		"stage_labels":     encodeParams(stage.Labels),
+		"stage_tolerates":  encodeParams(stage.Tolerates),
+		"stage_priority":   stage.Priority,
+		"stage_timeout":    stage.Timeout,
 	}
 }
 
@@ -143,6 +147,7 @@ func scanRow(scanner db.Scanner, dest *core.Build) error {
 		&dest.Deploy,
 		&dest.DeployID,
 		&dest.Debug,
+		&dest.Superseded,
 		&dest.Started,
 		&dest.Finished,
 		&dest.Created,
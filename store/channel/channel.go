@@ -0,0 +1,227 @@
+// Copyright 2019 Drone.IO Inc. All rights reserved.
+// Use of this source code is governed by the Drone Non-Commercial License
+// that can be found in the LICENSE file.
+
+// +build !oss
+
+package channel
+
+import (
+	"context"
+
+	"github.com/drone/drone/core"
+	"github.com/drone/drone/store/shared/db"
+	"github.com/drone/drone/store/shared/encrypt"
+)
+
+// New returns a new notification Channel database store.
+func New(db *db.DB, enc encrypt.Encrypter) core.ChannelStore {
+	return &channelStore{
+		db:  db,
+		enc: enc,
+	}
+}
+
+type channelStore struct {
+	db  *db.DB
+	enc encrypt.Encrypter
+}
+
+func (s *channelStore) List(ctx context.Context, id int64) ([]*core.Channel, error) {
+	var out []*core.Channel
+	err := s.db.View(func(queryer db.Queryer, binder db.Binder) error {
+		params := map[string]interface{}{"channel_repo_id": id}
+		stmt, args, err := binder.BindNamed(queryRepo, params)
+		if err != nil {
+			return err
+		}
+		rows, err := queryer.Query(stmt, args...)
+		if err != nil {
+			return err
+		}
+		out, err = scanRows(s.enc, rows)
+		return err
+	})
+	return out, err
+}
+
+func (s *channelStore) ListNamespace(ctx context.Context, namespace string) ([]*core.Channel, error) {
+	var out []*core.Channel
+	err := s.db.View(func(queryer db.Queryer, binder db.Binder) error {
+		params := map[string]interface{}{"channel_namespace": namespace}
+		stmt, args, err := binder.BindNamed(queryNamespace, params)
+		if err != nil {
+			return err
+		}
+		rows, err := queryer.Query(stmt, args...)
+		if err != nil {
+			return err
+		}
+		out, err = scanRows(s.enc, rows)
+		return err
+	})
+	return out, err
+}
+
+func (s *channelStore) Find(ctx context.Context, id int64) (*core.Channel, error) {
+	out := &core.Channel{ID: id}
+	err := s.db.View(func(queryer db.Queryer, binder db.Binder) error {
+		params, err := toParams(s.enc, out)
+		if err != nil {
+			return err
+		}
+		query, args, err := binder.BindNamed(queryKey, params)
+		if err != nil {
+			return err
+		}
+		row := queryer.QueryRow(query, args...)
+		return scanRow(s.enc, row, out)
+	})
+	return out, err
+}
+
+func (s *channelStore) Create(ctx context.Context, channel *core.Channel) error {
+	if s.db.Driver() == db.Postgres {
+		return s.createPostgres(ctx, channel)
+	}
+	return s.create(ctx, channel)
+}
+
+func (s *channelStore) create(ctx context.Context, channel *core.Channel) error {
+	return s.db.Lock(func(execer db.Execer, binder db.Binder) error {
+		params, err := toParams(s.enc, channel)
+		if err != nil {
+			return err
+		}
+		stmt, args, err := binder.BindNamed(stmtInsert, params)
+		if err != nil {
+			return err
+		}
+		res, err := execer.Exec(stmt, args...)
+		if err != nil {
+			return err
+		}
+		channel.ID, err = res.LastInsertId()
+		return err
+	})
+}
+
+func (s *channelStore) createPostgres(ctx context.Context, channel *core.Channel) error {
+	return s.db.Lock(func(execer db.Execer, binder db.Binder) error {
+		params, err := toParams(s.enc, channel)
+		if err != nil {
+			return err
+		}
+		stmt, args, err := binder.BindNamed(stmtInsertPg, params)
+		if err != nil {
+			return err
+		}
+		return execer.QueryRow(stmt, args...).Scan(&channel.ID)
+	})
+}
+
+func (s *channelStore) Update(ctx context.Context, channel *core.Channel) error {
+	return s.db.Lock(func(execer db.Execer, binder db.Binder) error {
+		params, err := toParams(s.enc, channel)
+		if err != nil {
+			return err
+		}
+		stmt, args, err := binder.BindNamed(stmtUpdate, params)
+		if err != nil {
+			return err
+		}
+		_, err = execer.Exec(stmt, args...)
+		return err
+	})
+}
+
+func (s *channelStore) Delete(ctx context.Context, channel *core.Channel) error {
+	return s.db.Lock(func(execer db.Execer, binder db.Binder) error {
+		params, err := toParams(s.enc, channel)
+		if err != nil {
+			return err
+		}
+		stmt, args, err := binder.BindNamed(stmtDelete, params)
+		if err != nil {
+			return err
+		}
+		_, err = execer.Exec(stmt, args...)
+		return err
+	})
+}
+
+const queryBase = `
+SELECT
+ channel_id
+,channel_repo_id
+,channel_namespace
+,channel_name
+,channel_kind
+,channel_endpoint
+,channel_template
+,channel_events
+,channel_created
+,channel_updated
+`
+
+const queryKey = queryBase + `
+FROM channels
+WHERE channel_id = :channel_id
+LIMIT 1
+`
+
+const queryRepo = queryBase + `
+FROM channels
+WHERE channel_repo_id = :channel_repo_id
+ORDER BY channel_name
+`
+
+const queryNamespace = queryBase + `
+FROM channels
+WHERE channel_namespace = :channel_namespace
+  AND channel_repo_id = 0
+ORDER BY channel_name
+`
+
+const stmtUpdate = `
+UPDATE channels SET
+ channel_kind = :channel_kind
+,channel_endpoint = :channel_endpoint
+,channel_template = :channel_template
+,channel_events = :channel_events
+,channel_updated = :channel_updated
+WHERE channel_id = :channel_id
+`
+
+const stmtDelete = `
+DELETE FROM channels
+WHERE channel_id = :channel_id
+`
+
+const stmtInsert = `
+INSERT INTO channels (
+ channel_repo_id
+,channel_namespace
+,channel_name
+,channel_kind
+,channel_endpoint
+,channel_template
+,channel_events
+,channel_created
+,channel_updated
+) VALUES (
+ :channel_repo_id
+,:channel_namespace
+,:channel_name
+,:channel_kind
+,:channel_endpoint
+,:channel_template
+,:channel_events
+,:channel_created
+,:channel_updated
+)
+`
+
+const stmtInsertPg = stmtInsert + `
+RETURNING channel_id
+`
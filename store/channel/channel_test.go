@@ -0,0 +1,172 @@
+// Copyright 2019 Drone.IO Inc. All rights reserved.
+// Use of this source code is governed by the Drone Non-Commercial License
+// that can be found in the LICENSE file.
+
+// +build !oss
+
+package channel
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/drone/drone/core"
+	"github.com/drone/drone/store/shared/db/dbtest"
+	"github.com/drone/drone/store/shared/encrypt"
+)
+
+var noContext = context.TODO()
+
+func TestChannel(t *testing.T) {
+	conn, err := dbtest.Connect()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	defer func() {
+		dbtest.Reset(conn)
+		dbtest.Disconnect(conn)
+	}()
+
+	store := New(conn, nil).(*channelStore)
+	store.enc, _ = encrypt.New("fb4b4d6267c8a5ce8231f8b186dbca92")
+	t.Run("Create", testChannelCreate(store))
+}
+
+func testChannelCreate(store *channelStore) func(t *testing.T) {
+	return func(t *testing.T) {
+		item := &core.Channel{
+			RepoID:    1,
+			Namespace: "octocat",
+			Name:      "slack",
+			Kind:      core.ChannelSlack,
+			Endpoint:  "https://hooks.slack.com/services/T0/B0/X",
+			Events:    []string{"failure"},
+		}
+		err := store.Create(noContext, item)
+		if err != nil {
+			t.Error(err)
+		}
+		if item.ID == 0 {
+			t.Errorf("Want channel ID assigned, got %d", item.ID)
+		}
+
+		namespaced := &core.Channel{
+			Namespace: "octocat",
+			Name:      "slack",
+			Kind:      core.ChannelSlack,
+			Endpoint:  "https://hooks.slack.com/services/T0/B0/X",
+			Events:    []string{"failure"},
+		}
+		err = store.Create(noContext, namespaced)
+		if err != nil {
+			t.Error(err)
+		}
+
+		t.Run("Find", testChannelFind(store, item))
+		t.Run("List", testChannelList(store, item))
+		t.Run("ListNamespace", testChannelListNamespace(store, namespaced))
+		t.Run("Update", testChannelUpdate(store, item))
+		t.Run("Delete", testChannelDelete(store, item))
+	}
+}
+
+func testChannelFind(store *channelStore, channel *core.Channel) func(t *testing.T) {
+	return func(t *testing.T) {
+		item, err := store.Find(noContext, channel.ID)
+		if err != nil {
+			t.Error(err)
+		} else {
+			t.Run("Fields", testChannel(item))
+		}
+	}
+}
+
+func testChannelList(store *channelStore, channel *core.Channel) func(t *testing.T) {
+	return func(t *testing.T) {
+		list, err := store.List(noContext, channel.RepoID)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if got, want := len(list), 1; got != want {
+			t.Errorf("Want count %d, got %d", want, got)
+		} else {
+			t.Run("Fields", testChannel(list[0]))
+		}
+	}
+}
+
+func testChannelListNamespace(store *channelStore, channel *core.Channel) func(t *testing.T) {
+	return func(t *testing.T) {
+		list, err := store.ListNamespace(noContext, channel.Namespace)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if got, want := len(list), 1; got != want {
+			t.Errorf("Want count %d, got %d", want, got)
+			return
+		}
+		if got, want := list[0].ID, channel.ID; got != want {
+			t.Errorf("Want namespace channel %d, got %d", want, got)
+		}
+	}
+}
+
+func testChannelUpdate(store *channelStore, channel *core.Channel) func(t *testing.T) {
+	return func(t *testing.T) {
+		before, err := store.Find(noContext, channel.ID)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		before.Template = "{{ .Build.Status }}"
+		err = store.Update(noContext, before)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		after, err := store.Find(noContext, before.ID)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if got, want := after.Template, before.Template; got != want {
+			t.Errorf("Want template %q, got %q", want, got)
+		}
+	}
+}
+
+func testChannelDelete(store *channelStore, channel *core.Channel) func(t *testing.T) {
+	return func(t *testing.T) {
+		err := store.Delete(noContext, channel)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		_, err = store.Find(noContext, channel.ID)
+		if got, want := sql.ErrNoRows, err; got != want {
+			t.Errorf("Want sql.ErrNoRows, got %v", got)
+			return
+		}
+	}
+}
+
+func testChannel(item *core.Channel) func(t *testing.T) {
+	return func(t *testing.T) {
+		if got, want := item.Name, "slack"; got != want {
+			t.Errorf("Want channel name %q, got %q", want, got)
+		}
+		if got, want := item.Kind, core.ChannelSlack; got != want {
+			t.Errorf("Want channel kind %q, got %q", want, got)
+		}
+		if got, want := item.Endpoint, "https://hooks.slack.com/services/T0/B0/X"; got != want {
+			t.Errorf("Want channel endpoint %q, got %q", want, got)
+		}
+		if got, want := len(item.Events), 1; got != want {
+			t.Errorf("Want event count %d, got %d", want, got)
+		}
+	}
+}
@@ -0,0 +1,90 @@
+// Copyright 2019 Drone.IO Inc. All rights reserved.
+// Use of this source code is governed by the Drone Non-Commercial License
+// that can be found in the LICENSE file.
+
+// +build !oss
+
+package channel
+
+import (
+	"database/sql"
+	"encoding/json"
+
+	"github.com/drone/drone/core"
+	"github.com/drone/drone/store/shared/db"
+	"github.com/drone/drone/store/shared/encrypt"
+
+	"github.com/jmoiron/sqlx/types"
+)
+
+// helper function converts the Channel structure to a set
+// of named query parameters.
+func toParams(encrypt encrypt.Encrypter, channel *core.Channel) (map[string]interface{}, error) {
+	ciphertext, err := encrypt.Encrypt(channel.Endpoint)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{
+		"channel_id":        channel.ID,
+		"channel_repo_id":   channel.RepoID,
+		"channel_namespace": channel.Namespace,
+		"channel_name":      channel.Name,
+		"channel_kind":      channel.Kind,
+		"channel_endpoint":  ciphertext,
+		"channel_template":  channel.Template,
+		"channel_events":    encodeSlice(channel.Events),
+		"channel_created":   channel.Created,
+		"channel_updated":   channel.Updated,
+	}, nil
+}
+
+func encodeSlice(v []string) types.JSONText {
+	raw, _ := json.Marshal(v)
+	return types.JSONText(raw)
+}
+
+// helper function scans the sql.Row and copies the column
+// values to the destination object.
+func scanRow(encrypt encrypt.Encrypter, scanner db.Scanner, dst *core.Channel) error {
+	var ciphertext []byte
+	events := types.JSONText{}
+	err := scanner.Scan(
+		&dst.ID,
+		&dst.RepoID,
+		&dst.Namespace,
+		&dst.Name,
+		&dst.Kind,
+		&ciphertext,
+		&dst.Template,
+		&events,
+		&dst.Created,
+		&dst.Updated,
+	)
+	if err != nil {
+		return err
+	}
+	json.Unmarshal(events, &dst.Events)
+	plaintext, err := encrypt.Decrypt(ciphertext)
+	if err != nil {
+		return err
+	}
+	dst.Endpoint = plaintext
+	return nil
+}
+
+// helper function scans the sql.Row and copies the column
+// values to the destination object.
+func scanRows(encrypt encrypt.Encrypter, rows *sql.Rows) ([]*core.Channel, error) {
+	defer rows.Close()
+
+	channels := []*core.Channel{}
+	for rows.Next() {
+		channel := new(core.Channel)
+		err := scanRow(encrypt, rows, channel)
+		if err != nil {
+			return nil, err
+		}
+		channels = append(channels, channel)
+	}
+	return channels, nil
+}
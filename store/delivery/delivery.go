@@ -0,0 +1,350 @@
+// Copyright 2019 Drone.IO Inc. All rights reserved.
+// Use of this source code is governed by the Drone Non-Commercial License
+// that can be found in the LICENSE file.
+
+// +build !oss
+
+package delivery
+
+import (
+	"context"
+
+	"github.com/drone/drone/core"
+	"github.com/drone/drone/store/shared/db"
+)
+
+// New returns a new DeliveryStore.
+func New(db *db.DB) core.DeliveryStore {
+	return &deliveryStore{db}
+}
+
+type deliveryStore struct {
+	db *db.DB
+}
+
+func (s *deliveryStore) List(ctx context.Context, limit, offset int) ([]*core.Delivery, error) {
+	var out []*core.Delivery
+	err := s.db.View(func(queryer db.Queryer, binder db.Binder) error {
+		params := map[string]interface{}{
+			"limit":  limit,
+			"offset": offset,
+		}
+		stmt, args, err := binder.BindNamed(queryAll, params)
+		if err != nil {
+			return err
+		}
+		rows, err := queryer.Query(stmt, args...)
+		if err != nil {
+			return err
+		}
+		out, err = scanRows(rows)
+		return err
+	})
+	return out, err
+}
+
+func (s *deliveryStore) ListPending(ctx context.Context, now int64) ([]*core.Delivery, error) {
+	var out []*core.Delivery
+	err := s.db.View(func(queryer db.Queryer, binder db.Binder) error {
+		params := map[string]interface{}{
+			"delivery_status": core.DeliveryPending,
+			"delivery_next":   now,
+		}
+		stmt, args, err := binder.BindNamed(queryPending, params)
+		if err != nil {
+			return err
+		}
+		rows, err := queryer.Query(stmt, args...)
+		if err != nil {
+			return err
+		}
+		out, err = scanRows(rows)
+		return err
+	})
+	return out, err
+}
+
+func (s *deliveryStore) Find(ctx context.Context, id int64) (*core.Delivery, error) {
+	out := &core.Delivery{ID: id}
+	err := s.db.View(func(queryer db.Queryer, binder db.Binder) error {
+		params := toParams(out)
+		query, args, err := binder.BindNamed(queryKey, params)
+		if err != nil {
+			return err
+		}
+		row := queryer.QueryRow(query, args...)
+		return scanRow(row, out)
+	})
+	return out, err
+}
+
+func (s *deliveryStore) Create(ctx context.Context, delivery *core.Delivery) error {
+	if s.db.Driver() == db.Postgres {
+		return s.createPostgres(ctx, delivery)
+	}
+	return s.create(ctx, delivery)
+}
+
+func (s *deliveryStore) create(ctx context.Context, delivery *core.Delivery) error {
+	return s.db.Lock(func(execer db.Execer, binder db.Binder) error {
+		params := toParams(delivery)
+		stmt, args, err := binder.BindNamed(stmtInsert, params)
+		if err != nil {
+			return err
+		}
+		res, err := execer.Exec(stmt, args...)
+		if err != nil {
+			return err
+		}
+		delivery.ID, err = res.LastInsertId()
+		return err
+	})
+}
+
+func (s *deliveryStore) createPostgres(ctx context.Context, delivery *core.Delivery) error {
+	return s.db.Lock(func(execer db.Execer, binder db.Binder) error {
+		params := toParams(delivery)
+		stmt, args, err := binder.BindNamed(stmtInsertPg, params)
+		if err != nil {
+			return err
+		}
+		return execer.QueryRow(stmt, args...).Scan(&delivery.ID)
+	})
+}
+
+func (s *deliveryStore) Update(ctx context.Context, delivery *core.Delivery) error {
+	versionNew := delivery.Version + 1
+	versionOld := delivery.Version
+
+	err := s.db.Lock(func(execer db.Execer, binder db.Binder) error {
+		params := toParams(delivery)
+		params["delivery_version_old"] = versionOld
+		params["delivery_version_new"] = versionNew
+		stmt, args, err := binder.BindNamed(stmtUpdate, params)
+		if err != nil {
+			return err
+		}
+		res, err := execer.Exec(stmt, args...)
+		if err != nil {
+			return err
+		}
+		effected, err := res.RowsAffected()
+		if err != nil {
+			return err
+		}
+		if effected == 0 {
+			return db.ErrOptimisticLock
+		}
+		return nil
+	})
+	if err == nil {
+		delivery.Version = versionNew
+	}
+	return err
+}
+
+func (s *deliveryStore) Purge(ctx context.Context, before int64) error {
+	return s.db.Lock(func(execer db.Execer, binder db.Binder) error {
+		params := map[string]interface{}{
+			"delivery_status":  core.DeliveryPending,
+			"delivery_created": before,
+		}
+		stmt, args, err := binder.BindNamed(stmtPurgeAttempts, params)
+		if err != nil {
+			return err
+		}
+		if _, err := execer.Exec(stmt, args...); err != nil {
+			return err
+		}
+		stmt, args, err = binder.BindNamed(stmtPurge, params)
+		if err != nil {
+			return err
+		}
+		_, err = execer.Exec(stmt, args...)
+		return err
+	})
+}
+
+func (s *deliveryStore) ListAttempts(ctx context.Context, id int64) ([]*core.DeliveryAttempt, error) {
+	var out []*core.DeliveryAttempt
+	err := s.db.View(func(queryer db.Queryer, binder db.Binder) error {
+		params := map[string]interface{}{
+			"attempt_delivery_id": id,
+		}
+		stmt, args, err := binder.BindNamed(queryAttempts, params)
+		if err != nil {
+			return err
+		}
+		rows, err := queryer.Query(stmt, args...)
+		if err != nil {
+			return err
+		}
+		out, err = scanAttemptRows(rows)
+		return err
+	})
+	return out, err
+}
+
+func (s *deliveryStore) CreateAttempt(ctx context.Context, attempt *core.DeliveryAttempt) error {
+	if s.db.Driver() == db.Postgres {
+		return s.createAttemptPostgres(ctx, attempt)
+	}
+	return s.createAttempt(ctx, attempt)
+}
+
+func (s *deliveryStore) createAttempt(ctx context.Context, attempt *core.DeliveryAttempt) error {
+	return s.db.Lock(func(execer db.Execer, binder db.Binder) error {
+		params := toAttemptParams(attempt)
+		stmt, args, err := binder.BindNamed(stmtInsertAttempt, params)
+		if err != nil {
+			return err
+		}
+		res, err := execer.Exec(stmt, args...)
+		if err != nil {
+			return err
+		}
+		attempt.ID, err = res.LastInsertId()
+		return err
+	})
+}
+
+func (s *deliveryStore) createAttemptPostgres(ctx context.Context, attempt *core.DeliveryAttempt) error {
+	return s.db.Lock(func(execer db.Execer, binder db.Binder) error {
+		params := toAttemptParams(attempt)
+		stmt, args, err := binder.BindNamed(stmtInsertAttemptPg, params)
+		if err != nil {
+			return err
+		}
+		return execer.QueryRow(stmt, args...).Scan(&attempt.ID)
+	})
+}
+
+const queryBase = `
+SELECT
+ delivery_id
+,delivery_event
+,delivery_action
+,delivery_subscription_id
+,delivery_endpoint
+,delivery_payload
+,delivery_status
+,delivery_attempts
+,delivery_next
+,delivery_created
+,delivery_updated
+,delivery_version
+`
+
+const queryKey = queryBase + `
+FROM deliveries
+WHERE delivery_id = :delivery_id
+LIMIT 1
+`
+
+const queryAll = queryBase + `
+FROM deliveries
+ORDER BY delivery_id DESC
+LIMIT :limit OFFSET :offset
+`
+
+const queryPending = queryBase + `
+FROM deliveries
+WHERE delivery_status = :delivery_status
+  AND delivery_next <= :delivery_next
+ORDER BY delivery_id ASC
+`
+
+const stmtUpdate = `
+UPDATE deliveries SET
+ delivery_status = :delivery_status
+,delivery_attempts = :delivery_attempts
+,delivery_next = :delivery_next
+,delivery_updated = :delivery_updated
+,delivery_version = :delivery_version_new
+WHERE delivery_id = :delivery_id
+  AND delivery_version = :delivery_version_old
+`
+
+const stmtInsert = `
+INSERT INTO deliveries (
+ delivery_event
+,delivery_action
+,delivery_subscription_id
+,delivery_endpoint
+,delivery_payload
+,delivery_status
+,delivery_attempts
+,delivery_next
+,delivery_created
+,delivery_updated
+,delivery_version
+) VALUES (
+ :delivery_event
+,:delivery_action
+,:delivery_subscription_id
+,:delivery_endpoint
+,:delivery_payload
+,:delivery_status
+,:delivery_attempts
+,:delivery_next
+,:delivery_created
+,:delivery_updated
+,:delivery_version
+)
+`
+
+const stmtInsertPg = stmtInsert + `
+RETURNING delivery_id
+`
+
+const stmtPurgeAttempts = `
+DELETE FROM delivery_attempts
+WHERE attempt_delivery_id IN (
+  SELECT delivery_id
+  FROM deliveries
+  WHERE delivery_status != :delivery_status
+    AND delivery_created < :delivery_created
+)
+`
+
+const stmtPurge = `
+DELETE FROM deliveries
+WHERE delivery_status != :delivery_status
+  AND delivery_created < :delivery_created
+`
+
+const queryAttempts = `
+SELECT
+ attempt_id
+,attempt_delivery_id
+,attempt_code
+,attempt_latency
+,attempt_response
+,attempt_error
+,attempt_created
+FROM delivery_attempts
+WHERE attempt_delivery_id = :attempt_delivery_id
+ORDER BY attempt_id ASC
+`
+
+const stmtInsertAttempt = `
+INSERT INTO delivery_attempts (
+ attempt_delivery_id
+,attempt_code
+,attempt_latency
+,attempt_response
+,attempt_error
+,attempt_created
+) VALUES (
+ :attempt_delivery_id
+,:attempt_code
+,:attempt_latency
+,:attempt_response
+,:attempt_error
+,:attempt_created
+)
+`
+
+const stmtInsertAttemptPg = stmtInsertAttempt + `
+RETURNING attempt_id
+`
@@ -0,0 +1,223 @@
+// Copyright 2019 Drone.IO Inc. All rights reserved.
+// Use of this source code is governed by the Drone Non-Commercial License
+// that can be found in the LICENSE file.
+
+// +build !oss
+
+package delivery
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/drone/drone/core"
+	"github.com/drone/drone/store/shared/db"
+	"github.com/drone/drone/store/shared/db/dbtest"
+)
+
+var noContext = context.TODO()
+
+func TestDelivery(t *testing.T) {
+	conn, err := dbtest.Connect()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	defer func() {
+		dbtest.Reset(conn)
+		dbtest.Disconnect(conn)
+	}()
+
+	store := New(conn).(*deliveryStore)
+	t.Run("Create", testDeliveryCreate(store))
+}
+
+func testDeliveryCreate(store *deliveryStore) func(t *testing.T) {
+	return func(t *testing.T) {
+		item := &core.Delivery{
+			Event:          core.WebhookEventBuild,
+			Action:         core.WebhookActionCreated,
+			SubscriptionID: 1,
+			Endpoint:       "https://company.com/hook",
+			Payload:        `{"event":"build"}`,
+			Status:         core.DeliveryPending,
+			Next:           1522878684,
+			Created:        1522878684,
+			Updated:        1522878684,
+		}
+		err := store.Create(noContext, item)
+		if err != nil {
+			t.Error(err)
+		}
+		if item.ID == 0 {
+			t.Errorf("Want delivery ID assigned, got %d", item.ID)
+		}
+
+		t.Run("Find", testDeliveryFind(store, item))
+		t.Run("List", testDeliveryList(store))
+		t.Run("ListPending", testDeliveryListPending(store))
+		t.Run("Attempts", testDeliveryAttempts(store, item))
+		t.Run("Update", testDeliveryUpdate(store, item))
+		t.Run("Purge", testDeliveryPurge(store, item))
+	}
+}
+
+func testDeliveryFind(store *deliveryStore, delivery *core.Delivery) func(t *testing.T) {
+	return func(t *testing.T) {
+		item, err := store.Find(noContext, delivery.ID)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		t.Run("Fields", testDelivery(item))
+	}
+}
+
+func testDeliveryList(store *deliveryStore) func(t *testing.T) {
+	return func(t *testing.T) {
+		list, err := store.List(noContext, 10, 0)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if got, want := len(list), 1; got != want {
+			t.Errorf("Want count %d, got %d", want, got)
+			return
+		}
+		t.Run("Fields", testDelivery(list[0]))
+	}
+}
+
+func testDeliveryListPending(store *deliveryStore) func(t *testing.T) {
+	return func(t *testing.T) {
+		list, err := store.ListPending(noContext, 1522878683)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if got, want := len(list), 0; got != want {
+			t.Errorf("Want no deliveries due, got %d", got)
+		}
+		list, err = store.ListPending(noContext, 1522878684)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if got, want := len(list), 1; got != want {
+			t.Errorf("Want count %d, got %d", want, got)
+		}
+	}
+}
+
+func testDeliveryAttempts(store *deliveryStore, delivery *core.Delivery) func(t *testing.T) {
+	return func(t *testing.T) {
+		attempt := &core.DeliveryAttempt{
+			DeliveryID: delivery.ID,
+			Code:       500,
+			Latency:    120,
+			Response:   "internal server error",
+			Created:    1522878684,
+		}
+		err := store.CreateAttempt(noContext, attempt)
+		if err != nil {
+			t.Error(err)
+		}
+		if attempt.ID == 0 {
+			t.Errorf("Want attempt ID assigned, got %d", attempt.ID)
+		}
+		list, err := store.ListAttempts(noContext, delivery.ID)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if got, want := len(list), 1; got != want {
+			t.Errorf("Want count %d, got %d", want, got)
+			return
+		}
+		if got, want := list[0].Code, 500; got != want {
+			t.Errorf("Want Code %d, got %d", want, got)
+		}
+		if got, want := list[0].Response, "internal server error"; got != want {
+			t.Errorf("Want Response %q, got %q", want, got)
+		}
+	}
+}
+
+func testDeliveryUpdate(store *deliveryStore, delivery *core.Delivery) func(t *testing.T) {
+	return func(t *testing.T) {
+		before := *delivery
+		before.Status = core.DeliverySuccess
+		before.Attempts = 1
+		err := store.Update(noContext, &before)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		after, err := store.Find(noContext, delivery.ID)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if got, want := after.Status, core.DeliverySuccess; got != want {
+			t.Errorf("Want Status %q, got %q", want, got)
+		}
+		if got, want := after.Attempts, 1; got != want {
+			t.Errorf("Want Attempts %d, got %d", want, got)
+		}
+		if got, want := after.Version, delivery.Version+1; got != want {
+			t.Errorf("Want Version %d, got %d", want, got)
+		}
+
+		// updating a stale copy of the delivery must fail
+		// with an optimistic lock error.
+		err = store.Update(noContext, delivery)
+		if err != db.ErrOptimisticLock {
+			t.Errorf("Want optimistic lock error, got %v", err)
+		}
+	}
+}
+
+func testDeliveryPurge(store *deliveryStore, delivery *core.Delivery) func(t *testing.T) {
+	return func(t *testing.T) {
+		err := store.Purge(noContext, delivery.Created+1)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		_, err = store.Find(noContext, delivery.ID)
+		if err != sql.ErrNoRows {
+			t.Errorf("Want sql.ErrNoRows, got %v", err)
+		}
+		list, _ := store.ListAttempts(noContext, delivery.ID)
+		if len(list) != 0 {
+			t.Errorf("Want attempts purged, got %d", len(list))
+		}
+	}
+}
+
+func testDelivery(item *core.Delivery) func(t *testing.T) {
+	return func(t *testing.T) {
+		if got, want := item.Event, core.WebhookEventBuild; got != want {
+			t.Errorf("Want Event %q, got %q", want, got)
+		}
+		if got, want := item.Action, core.WebhookActionCreated; got != want {
+			t.Errorf("Want Action %q, got %q", want, got)
+		}
+		if got, want := item.SubscriptionID, int64(1); got != want {
+			t.Errorf("Want SubscriptionID %d, got %d", want, got)
+		}
+		if got, want := item.Endpoint, "https://company.com/hook"; got != want {
+			t.Errorf("Want Endpoint %q, got %q", want, got)
+		}
+		if got, want := item.Payload, `{"event":"build"}`; got != want {
+			t.Errorf("Want Payload %q, got %q", want, got)
+		}
+		if got, want := item.Status, core.DeliveryPending; got != want {
+			t.Errorf("Want Status %q, got %q", want, got)
+		}
+		if got, want := item.Next, int64(1522878684); got != want {
+			t.Errorf("Want Next %d, got %d", want, got)
+		}
+	}
+}
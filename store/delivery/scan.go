@@ -0,0 +1,114 @@
+// Copyright 2019 Drone.IO Inc. All rights reserved.
+// Use of this source code is governed by the Drone Non-Commercial License
+// that can be found in the LICENSE file.
+
+// +build !oss
+
+package delivery
+
+import (
+	"database/sql"
+
+	"github.com/drone/drone/core"
+	"github.com/drone/drone/store/shared/db"
+)
+
+// helper function converts the Delivery structure to a set
+// of named query parameters.
+func toParams(delivery *core.Delivery) map[string]interface{} {
+	return map[string]interface{}{
+		"delivery_id":              delivery.ID,
+		"delivery_event":           delivery.Event,
+		"delivery_action":          delivery.Action,
+		"delivery_subscription_id": delivery.SubscriptionID,
+		"delivery_endpoint":        delivery.Endpoint,
+		"delivery_payload":         delivery.Payload,
+		"delivery_status":          delivery.Status,
+		"delivery_attempts":        delivery.Attempts,
+		"delivery_next":            delivery.Next,
+		"delivery_created":         delivery.Created,
+		"delivery_updated":         delivery.Updated,
+		"delivery_version":         delivery.Version,
+	}
+}
+
+// helper function converts the DeliveryAttempt structure to
+// a set of named query parameters.
+func toAttemptParams(attempt *core.DeliveryAttempt) map[string]interface{} {
+	return map[string]interface{}{
+		"attempt_id":          attempt.ID,
+		"attempt_delivery_id": attempt.DeliveryID,
+		"attempt_code":        attempt.Code,
+		"attempt_latency":     attempt.Latency,
+		"attempt_response":    attempt.Response,
+		"attempt_error":       attempt.Error,
+		"attempt_created":     attempt.Created,
+	}
+}
+
+// helper function scans the sql.Row and copies the column
+// values to the destination object.
+func scanRow(scanner db.Scanner, dst *core.Delivery) error {
+	return scanner.Scan(
+		&dst.ID,
+		&dst.Event,
+		&dst.Action,
+		&dst.SubscriptionID,
+		&dst.Endpoint,
+		&dst.Payload,
+		&dst.Status,
+		&dst.Attempts,
+		&dst.Next,
+		&dst.Created,
+		&dst.Updated,
+		&dst.Version,
+	)
+}
+
+// helper function scans the sql.Row and copies the column
+// values to the destination object.
+func scanRows(rows *sql.Rows) ([]*core.Delivery, error) {
+	defer rows.Close()
+
+	deliveries := []*core.Delivery{}
+	for rows.Next() {
+		delivery := new(core.Delivery)
+		err := scanRow(rows, delivery)
+		if err != nil {
+			return nil, err
+		}
+		deliveries = append(deliveries, delivery)
+	}
+	return deliveries, nil
+}
+
+// helper function scans the sql.Row and copies the column
+// values to the destination object.
+func scanAttemptRow(scanner db.Scanner, dst *core.DeliveryAttempt) error {
+	return scanner.Scan(
+		&dst.ID,
+		&dst.DeliveryID,
+		&dst.Code,
+		&dst.Latency,
+		&dst.Response,
+		&dst.Error,
+		&dst.Created,
+	)
+}
+
+// helper function scans the sql.Row and copies the column
+// values to the destination object.
+func scanAttemptRows(rows *sql.Rows) ([]*core.DeliveryAttempt, error) {
+	defer rows.Close()
+
+	attempts := []*core.DeliveryAttempt{}
+	for rows.Next() {
+		attempt := new(core.DeliveryAttempt)
+		err := scanAttemptRow(rows, attempt)
+		if err != nil {
+			return nil, err
+		}
+		attempts = append(attempts, attempt)
+	}
+	return attempts, nil
+}
@@ -0,0 +1,260 @@
+// Copyright 2019 Drone IO, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package environment
+
+import (
+	"context"
+	"time"
+
+	"github.com/drone/drone/core"
+	"github.com/drone/drone/store/shared/db"
+	"github.com/drone/drone/store/shared/encrypt"
+)
+
+// New returns a new EnvironmentStore.
+func New(db *db.DB, enc encrypt.Encrypter) core.EnvironmentStore {
+	return &environmentStore{
+		db:  db,
+		enc: enc,
+	}
+}
+
+type environmentStore struct {
+	db  *db.DB
+	enc encrypt.Encrypter
+}
+
+func (s *environmentStore) List(ctx context.Context, id int64) ([]*core.Environment, error) {
+	var out []*core.Environment
+	err := s.db.View(func(queryer db.Queryer, binder db.Binder) error {
+		params := map[string]interface{}{"environment_repo_id": id}
+		stmt, args, err := binder.BindNamed(queryRepo, params)
+		if err != nil {
+			return err
+		}
+		rows, err := queryer.Query(stmt, args...)
+		if err != nil {
+			return err
+		}
+		out, err = scanRows(s.enc, rows)
+		return err
+	})
+	return out, err
+}
+
+func (s *environmentStore) FindName(ctx context.Context, id int64, name string) (*core.Environment, error) {
+	out := &core.Environment{Name: name, RepoID: id}
+	err := s.db.View(func(queryer db.Queryer, binder db.Binder) error {
+		params := map[string]interface{}{
+			"environment_repo_id": id,
+			"environment_name":    name,
+		}
+		query, args, err := binder.BindNamed(queryName, params)
+		if err != nil {
+			return err
+		}
+		row := queryer.QueryRow(query, args...)
+		return scanRow(s.enc, row, out)
+	})
+	return out, err
+}
+
+func (s *environmentStore) Create(ctx context.Context, environment *core.Environment) error {
+	if s.db.Driver() == db.Postgres {
+		return s.createPostgres(ctx, environment)
+	}
+	return s.create(ctx, environment)
+}
+
+func (s *environmentStore) create(ctx context.Context, environment *core.Environment) error {
+	return s.db.Lock(func(execer db.Execer, binder db.Binder) error {
+		params, err := toParams(s.enc, environment)
+		if err != nil {
+			return err
+		}
+		stmt, args, err := binder.BindNamed(stmtInsert, params)
+		if err != nil {
+			return err
+		}
+		res, err := execer.Exec(stmt, args...)
+		if err != nil {
+			return err
+		}
+		environment.ID, err = res.LastInsertId()
+		return err
+	})
+}
+
+func (s *environmentStore) createPostgres(ctx context.Context, environment *core.Environment) error {
+	return s.db.Lock(func(execer db.Execer, binder db.Binder) error {
+		params, err := toParams(s.enc, environment)
+		if err != nil {
+			return err
+		}
+		stmt, args, err := binder.BindNamed(stmtInsertPg, params)
+		if err != nil {
+			return err
+		}
+		return execer.QueryRow(stmt, args...).Scan(&environment.ID)
+	})
+}
+
+func (s *environmentStore) Update(ctx context.Context, environment *core.Environment) error {
+	return s.db.Lock(func(execer db.Execer, binder db.Binder) error {
+		params, err := toParams(s.enc, environment)
+		if err != nil {
+			return err
+		}
+		stmt, args, err := binder.BindNamed(stmtUpdate, params)
+		if err != nil {
+			return err
+		}
+		_, err = execer.Exec(stmt, args...)
+		return err
+	})
+}
+
+func (s *environmentStore) Delete(ctx context.Context, environment *core.Environment) error {
+	return s.db.Lock(func(execer db.Execer, binder db.Binder) error {
+		params := map[string]interface{}{"environment_id": environment.ID}
+		stmt, args, err := binder.BindNamed(stmtDelete, params)
+		if err != nil {
+			return err
+		}
+		_, err = execer.Exec(stmt, args...)
+		return err
+	})
+}
+
+func (s *environmentStore) Lock(ctx context.Context, environment *core.Environment, expiry int64) error {
+	return s.db.Lock(func(execer db.Execer, binder db.Binder) error {
+		params := map[string]interface{}{
+			"environment_id":     environment.ID,
+			"environment_locked": expiry,
+			"now":                time.Now().Unix(),
+		}
+		stmt, args, err := binder.BindNamed(stmtLock, params)
+		if err != nil {
+			return err
+		}
+		res, err := execer.Exec(stmt, args...)
+		if err != nil {
+			return err
+		}
+		effected, err := res.RowsAffected()
+		if err != nil {
+			return err
+		}
+		if effected == 0 {
+			return db.ErrOptimisticLock
+		}
+		return nil
+	})
+}
+
+func (s *environmentStore) Unlock(ctx context.Context, environment *core.Environment) error {
+	return s.db.Lock(func(execer db.Execer, binder db.Binder) error {
+		params := map[string]interface{}{"environment_id": environment.ID}
+		stmt, args, err := binder.BindNamed(stmtUnlock, params)
+		if err != nil {
+			return err
+		}
+		_, err = execer.Exec(stmt, args...)
+		return err
+	})
+}
+
+const queryBase = `
+SELECT
+ environment_id
+,environment_repo_id
+,environment_name
+,environment_description
+,environment_branches
+,environment_users
+,environment_secrets
+,environment_created
+,environment_updated
+`
+
+const queryRepo = queryBase + `
+FROM environments
+WHERE environment_repo_id = :environment_repo_id
+ORDER BY environment_name
+`
+
+const queryName = queryBase + `
+FROM environments
+WHERE environment_repo_id = :environment_repo_id
+  AND environment_name = :environment_name
+`
+
+const stmtInsert = `
+INSERT INTO environments (
+ environment_repo_id
+,environment_name
+,environment_description
+,environment_branches
+,environment_users
+,environment_secrets
+,environment_created
+,environment_updated
+) VALUES (
+ :environment_repo_id
+,:environment_name
+,:environment_description
+,:environment_branches
+,:environment_users
+,:environment_secrets
+,:environment_created
+,:environment_updated
+)
+`
+
+const stmtInsertPg = stmtInsert + `
+RETURNING environment_id
+`
+
+const stmtUpdate = `
+UPDATE environments SET
+ environment_description = :environment_description
+,environment_branches    = :environment_branches
+,environment_users       = :environment_users
+,environment_secrets     = :environment_secrets
+,environment_updated     = :environment_updated
+WHERE environment_id = :environment_id
+`
+
+const stmtDelete = `
+DELETE FROM environments
+WHERE environment_id = :environment_id
+`
+
+// the deployment lock is acquired by a single conditional
+// update, which is atomic in every supported database. An
+// expired lock, for example held by a server that stopped
+// while creating the deployment, is acquired again.
+const stmtLock = `
+UPDATE environments SET
+ environment_locked = :environment_locked
+WHERE environment_id = :environment_id
+  AND environment_locked < :now
+`
+
+const stmtUnlock = `
+UPDATE environments SET
+ environment_locked = 0
+WHERE environment_id = :environment_id
+`
@@ -0,0 +1,173 @@
+// Copyright 2019 Drone.IO Inc. All rights reserved.
+// Use of this source code is governed by the Drone Non-Commercial License
+// that can be found in the LICENSE file.
+
+// +build !oss
+
+package environment
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/drone/drone/core"
+	"github.com/drone/drone/store/repos"
+	"github.com/drone/drone/store/shared/db"
+	"github.com/drone/drone/store/shared/db/dbtest"
+	"github.com/drone/drone/store/shared/encrypt"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+var noContext = context.TODO()
+
+func TestEnvironment(t *testing.T) {
+	conn, err := dbtest.Connect()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	defer func() {
+		dbtest.Reset(conn)
+		dbtest.Disconnect(conn)
+	}()
+
+	// seeds the database with a dummy repository.
+	repo := &core.Repository{UID: "1", Slug: "octocat/hello-world"}
+	repos := repos.New(conn)
+	if err := repos.Create(noContext, repo); err != nil {
+		t.Error(err)
+	}
+
+	enc, _ := encrypt.New("fb4b4d6267c8a5ce8231f8b186dbca92")
+	store := New(conn, enc).(*environmentStore)
+	t.Run("Create", testEnvironmentCreate(store, repo))
+}
+
+func testEnvironmentCreate(store *environmentStore, repo *core.Repository) func(t *testing.T) {
+	return func(t *testing.T) {
+		item := &core.Environment{
+			RepoID:      repo.ID,
+			Name:        "production",
+			Description: "production cluster",
+			Branches:    []string{"main", "release/*"},
+			Users:       []string{"octocat"},
+			Secrets: []*core.Secret{
+				{Name: "kubeconfig", Data: "correct-horse-battery-staple"},
+			},
+			Created: 1,
+			Updated: 2,
+		}
+		err := store.Create(noContext, item)
+		if err != nil {
+			t.Error(err)
+		}
+		if item.ID == 0 {
+			t.Errorf("Want environment ID assigned, got %d", item.ID)
+		}
+
+		t.Run("FindName", testEnvironmentFindName(store, item))
+		t.Run("List", testEnvironmentList(store, item))
+		t.Run("Update", testEnvironmentUpdate(store, item))
+		t.Run("Lock", testEnvironmentLock(store, item))
+		t.Run("Delete", testEnvironmentDelete(store, item))
+	}
+}
+
+func testEnvironmentLock(store *environmentStore, environment *core.Environment) func(t *testing.T) {
+	return func(t *testing.T) {
+		expiry := time.Now().Add(time.Minute).Unix()
+		if err := store.Lock(noContext, environment, expiry); err != nil {
+			t.Error(err)
+			return
+		}
+		if err := store.Lock(noContext, environment, expiry); err != db.ErrOptimisticLock {
+			t.Errorf("Want optimistic lock error for a held lock, got %v", err)
+		}
+		if err := store.Unlock(noContext, environment); err != nil {
+			t.Error(err)
+			return
+		}
+		if err := store.Lock(noContext, environment, expiry); err != nil {
+			t.Errorf("Want lock acquired after release, got %v", err)
+		}
+
+		// an expired lock is acquired again.
+		store.Unlock(noContext, environment)
+		store.Lock(noContext, environment, time.Now().Add(-time.Minute).Unix())
+		if err := store.Lock(noContext, environment, expiry); err != nil {
+			t.Errorf("Want expired lock acquired, got %v", err)
+		}
+	}
+}
+
+func testEnvironmentFindName(store *environmentStore, environment *core.Environment) func(t *testing.T) {
+	return func(t *testing.T) {
+		item, err := store.FindName(noContext, environment.RepoID, "production")
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if diff := cmp.Diff(item, environment); diff != "" {
+			t.Errorf(diff)
+		}
+	}
+}
+
+func testEnvironmentList(store *environmentStore, environment *core.Environment) func(t *testing.T) {
+	return func(t *testing.T) {
+		list, err := store.List(noContext, environment.RepoID)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if got, want := len(list), 1; got != want {
+			t.Errorf("Want count %d, got %d", want, got)
+			return
+		}
+		if diff := cmp.Diff(list[0], environment); diff != "" {
+			t.Errorf(diff)
+		}
+	}
+}
+
+func testEnvironmentUpdate(store *environmentStore, environment *core.Environment) func(t *testing.T) {
+	return func(t *testing.T) {
+		before := &core.Environment{
+			ID:      environment.ID,
+			RepoID:  environment.RepoID,
+			Name:    environment.Name,
+			Created: environment.Created,
+			Updated: 3,
+		}
+		err := store.Update(noContext, before)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		after, err := store.FindName(noContext, before.RepoID, before.Name)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if diff := cmp.Diff(after, before); diff != "" {
+			t.Errorf(diff)
+		}
+	}
+}
+
+func testEnvironmentDelete(store *environmentStore, environment *core.Environment) func(t *testing.T) {
+	return func(t *testing.T) {
+		err := store.Delete(noContext, environment)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		_, err = store.FindName(noContext, environment.RepoID, environment.Name)
+		if got, want := sql.ErrNoRows, err; got != want {
+			t.Errorf("Want sql.ErrNoRows, got %v", got)
+		}
+	}
+}
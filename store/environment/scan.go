@@ -0,0 +1,134 @@
+// Copyright 2019 Drone IO, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package environment
+
+import (
+	"database/sql"
+	"encoding/json"
+
+	"github.com/drone/drone/core"
+	"github.com/drone/drone/store/shared/db"
+	"github.com/drone/drone/store/shared/encrypt"
+)
+
+// helper function converts the Environment structure to a
+// set of named query parameters. The environment secrets
+// are encrypted before they are persisted.
+func toParams(enc encrypt.Encrypter, environment *core.Environment) (map[string]interface{}, error) {
+	secrets, err := encodeSecrets(enc, environment.Secrets)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{
+		"environment_id":          environment.ID,
+		"environment_repo_id":     environment.RepoID,
+		"environment_name":        environment.Name,
+		"environment_description": environment.Description,
+		"environment_branches":    encodeList(environment.Branches),
+		"environment_users":       encodeList(environment.Users),
+		"environment_secrets":     secrets,
+		"environment_created":     environment.Created,
+		"environment_updated":     environment.Updated,
+	}, nil
+}
+
+// helper function scans the sql.Row and copies the column
+// values to the destination object.
+func scanRow(enc encrypt.Encrypter, scanner db.Scanner, dest *core.Environment) error {
+	var branches, users sql.NullString
+	var ciphertext []byte
+	err := scanner.Scan(
+		&dest.ID,
+		&dest.RepoID,
+		&dest.Name,
+		&dest.Description,
+		&branches,
+		&users,
+		&ciphertext,
+		&dest.Created,
+		&dest.Updated,
+	)
+	if err != nil {
+		return err
+	}
+	dest.Branches = decodeList(branches)
+	dest.Users = decodeList(users)
+	dest.Secrets, err = decodeSecrets(enc, ciphertext)
+	return err
+}
+
+// helper function scans the sql.Row and copies the column
+// values to the destination object.
+func scanRows(enc encrypt.Encrypter, rows *sql.Rows) ([]*core.Environment, error) {
+	defer rows.Close()
+
+	environments := []*core.Environment{}
+	for rows.Next() {
+		environment := new(core.Environment)
+		err := scanRow(enc, rows, environment)
+		if err != nil {
+			return nil, err
+		}
+		environments = append(environments, environment)
+	}
+	return environments, nil
+}
+
+// helper function encodes the string list to json.
+func encodeList(v []string) sql.NullString {
+	if len(v) == 0 {
+		return sql.NullString{}
+	}
+	raw, _ := json.Marshal(v)
+	return sql.NullString{String: string(raw), Valid: true}
+}
+
+// helper function decodes the string list from json.
+func decodeList(v sql.NullString) []string {
+	if v.Valid == false || v.String == "" {
+		return nil
+	}
+	var list []string
+	json.Unmarshal([]byte(v.String), &list)
+	return list
+}
+
+// helper function encodes the secrets to json and encrypts
+// the result.
+func encodeSecrets(enc encrypt.Encrypter, v []*core.Secret) ([]byte, error) {
+	if len(v) == 0 {
+		return nil, nil
+	}
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	return enc.Encrypt(string(raw))
+}
+
+// helper function decrypts the secrets and decodes the
+// result from json.
+func decodeSecrets(enc encrypt.Encrypter, ciphertext []byte) ([]*core.Secret, error) {
+	if len(ciphertext) == 0 {
+		return nil, nil
+	}
+	plaintext, err := enc.Decrypt(ciphertext)
+	if err != nil {
+		return nil, err
+	}
+	var secrets []*core.Secret
+	err = json.Unmarshal([]byte(plaintext), &secrets)
+	return secrets, err
+}
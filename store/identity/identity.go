@@ -0,0 +1,158 @@
+// Copyright 2019 Drone IO, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package identity
+
+import (
+	"context"
+
+	"github.com/drone/drone/core"
+	"github.com/drone/drone/store/shared/db"
+)
+
+// New returns a new IdentityStore.
+func New(db *db.DB) core.IdentityStore {
+	return &identityStore{db}
+}
+
+type identityStore struct {
+	db *db.DB
+}
+
+func (s *identityStore) FindSubject(ctx context.Context, subject string) (*core.Identity, error) {
+	out := &core.Identity{Subject: subject}
+	err := s.db.View(func(queryer db.Queryer, binder db.Binder) error {
+		params := toParams(out)
+		query, args, err := binder.BindNamed(querySubject, params)
+		if err != nil {
+			return err
+		}
+		row := queryer.QueryRow(query, args...)
+		return scanRow(row, out)
+	})
+	return out, err
+}
+
+func (s *identityStore) FindUser(ctx context.Context, user int64) (*core.Identity, error) {
+	out := &core.Identity{UserID: user}
+	err := s.db.View(func(queryer db.Queryer, binder db.Binder) error {
+		params := toParams(out)
+		query, args, err := binder.BindNamed(queryUser, params)
+		if err != nil {
+			return err
+		}
+		row := queryer.QueryRow(query, args...)
+		return scanRow(row, out)
+	})
+	return out, err
+}
+
+func (s *identityStore) Create(ctx context.Context, identity *core.Identity) error {
+	if s.db.Driver() == db.Postgres {
+		return s.createPostgres(ctx, identity)
+	}
+	return s.create(ctx, identity)
+}
+
+func (s *identityStore) create(ctx context.Context, identity *core.Identity) error {
+	return s.db.Lock(func(execer db.Execer, binder db.Binder) error {
+		params := toParams(identity)
+		stmt, args, err := binder.BindNamed(stmtInsert, params)
+		if err != nil {
+			return err
+		}
+		res, err := execer.Exec(stmt, args...)
+		if err != nil {
+			return err
+		}
+		identity.ID, err = res.LastInsertId()
+		return err
+	})
+}
+
+func (s *identityStore) createPostgres(ctx context.Context, identity *core.Identity) error {
+	return s.db.Lock(func(execer db.Execer, binder db.Binder) error {
+		params := toParams(identity)
+		stmt, args, err := binder.BindNamed(stmtInsertPg, params)
+		if err != nil {
+			return err
+		}
+		return execer.QueryRow(stmt, args...).Scan(&identity.ID)
+	})
+}
+
+func (s *identityStore) Update(ctx context.Context, identity *core.Identity) error {
+	return s.db.Lock(func(execer db.Execer, binder db.Binder) error {
+		params := toParams(identity)
+		stmt, args, err := binder.BindNamed(stmtUpdate, params)
+		if err != nil {
+			return err
+		}
+		_, err = execer.Exec(stmt, args...)
+		return err
+	})
+}
+
+const queryBase = `
+SELECT
+ identity_id
+,identity_user_id
+,identity_subject
+,identity_email
+,identity_orgs
+,identity_created
+,identity_updated
+`
+
+const querySubject = queryBase + `
+FROM identities
+WHERE identity_subject = :identity_subject
+LIMIT 1
+`
+
+const queryUser = queryBase + `
+FROM identities
+WHERE identity_user_id = :identity_user_id
+LIMIT 1
+`
+
+const stmtUpdate = `
+UPDATE identities SET
+ identity_email = :identity_email
+,identity_orgs = :identity_orgs
+,identity_updated = :identity_updated
+WHERE identity_id = :identity_id
+`
+
+const stmtInsert = `
+INSERT INTO identities (
+ identity_user_id
+,identity_subject
+,identity_email
+,identity_orgs
+,identity_created
+,identity_updated
+) VALUES (
+ :identity_user_id
+,:identity_subject
+,:identity_email
+,:identity_orgs
+,:identity_created
+,:identity_updated
+)
+`
+
+const stmtInsertPg = stmtInsert + `
+RETURNING identity_id
+`
@@ -0,0 +1,130 @@
+// Copyright 2019 Drone.IO Inc. All rights reserved.
+// Use of this source code is governed by the Drone Non-Commercial License
+// that can be found in the LICENSE file.
+
+// +build !oss
+
+package identity
+
+import (
+	"context"
+	"testing"
+
+	"github.com/drone/drone/core"
+	"github.com/drone/drone/store/shared/db/dbtest"
+	"github.com/drone/drone/store/shared/encrypt"
+	"github.com/drone/drone/store/user"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+var noContext = context.TODO()
+
+func TestIdentity(t *testing.T) {
+	conn, err := dbtest.Connect()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	defer func() {
+		dbtest.Reset(conn)
+		dbtest.Disconnect(conn)
+	}()
+
+	// seeds the database with a dummy user.
+	enc, _ := encrypt.New("")
+	users := user.New(conn, enc)
+	owner := &core.User{Login: "octocat", Hash: "MjAxOC0wOC0xMVQxNTo1ODowN1o"}
+	if err := users.Create(noContext, owner); err != nil {
+		t.Error(err)
+		return
+	}
+
+	store := New(conn).(*identityStore)
+	t.Run("Create", testIdentityCreate(store, owner))
+}
+
+func testIdentityCreate(store *identityStore, owner *core.User) func(t *testing.T) {
+	return func(t *testing.T) {
+		item := &core.Identity{
+			UserID:  owner.ID,
+			Subject: "248289761001",
+			Email:   "octocat@github.com",
+			Orgs:    []string{"octocat"},
+			Created: 1522878684,
+			Updated: 1522878684,
+		}
+		err := store.Create(noContext, item)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if item.ID == 0 {
+			t.Errorf("Want identity ID assigned, got %d", item.ID)
+		}
+
+		t.Run("Duplicate", testIdentityDuplicate(store, item))
+		t.Run("FindSubject", testIdentityFindSubject(store, item))
+		t.Run("FindUser", testIdentityFindUser(store, item))
+		t.Run("Update", testIdentityUpdate(store, item))
+	}
+}
+
+func testIdentityDuplicate(store *identityStore, identity *core.Identity) func(t *testing.T) {
+	return func(t *testing.T) {
+		err := store.Create(noContext, &core.Identity{
+			UserID:  identity.UserID,
+			Subject: "248289761002",
+		})
+		if err == nil {
+			t.Errorf("Want unique constraint error for duplicate user")
+		}
+	}
+}
+
+func testIdentityFindSubject(store *identityStore, identity *core.Identity) func(t *testing.T) {
+	return func(t *testing.T) {
+		result, err := store.FindSubject(noContext, identity.Subject)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if diff := cmp.Diff(result, identity); diff != "" {
+			t.Errorf(diff)
+		}
+	}
+}
+
+func testIdentityFindUser(store *identityStore, identity *core.Identity) func(t *testing.T) {
+	return func(t *testing.T) {
+		result, err := store.FindUser(noContext, identity.UserID)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if diff := cmp.Diff(result, identity); diff != "" {
+			t.Errorf(diff)
+		}
+	}
+}
+
+func testIdentityUpdate(store *identityStore, identity *core.Identity) func(t *testing.T) {
+	return func(t *testing.T) {
+		identity.Email = "octocat@example.com"
+		identity.Orgs = []string{"octocat", "octo-org"}
+		identity.Updated = 1522878690
+		err := store.Update(noContext, identity)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		result, err := store.FindSubject(noContext, identity.Subject)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if diff := cmp.Diff(result, identity); diff != "" {
+			t.Errorf(diff)
+		}
+	}
+}
@@ -0,0 +1,312 @@
+// Copyright 2019 Drone IO, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package journal
+
+import (
+	"context"
+
+	"github.com/drone/drone/core"
+	"github.com/drone/drone/store/shared/db"
+)
+
+// New returns a new JournalStore.
+func New(db *db.DB) core.JournalStore {
+	return &journalStore{db}
+}
+
+type journalStore struct {
+	db *db.DB
+}
+
+func (s *journalStore) List(ctx context.Context, limit, offset int) ([]*core.JournalEntry, error) {
+	var out []*core.JournalEntry
+	err := s.db.View(func(queryer db.Queryer, binder db.Binder) error {
+		params := map[string]interface{}{
+			"limit":  limit,
+			"offset": offset,
+		}
+		stmt, args, err := binder.BindNamed(queryAll, params)
+		if err != nil {
+			return err
+		}
+		rows, err := queryer.Query(stmt, args...)
+		if err != nil {
+			return err
+		}
+		out, err = scanRows(rows)
+		return err
+	})
+	return out, err
+}
+
+func (s *journalStore) ListRepo(ctx context.Context, repo int64, limit, offset int) ([]*core.JournalEntry, error) {
+	var out []*core.JournalEntry
+	err := s.db.View(func(queryer db.Queryer, binder db.Binder) error {
+		params := map[string]interface{}{
+			"journal_repo_id": repo,
+			"limit":           limit,
+			"offset":          offset,
+		}
+		stmt, args, err := binder.BindNamed(queryRepo, params)
+		if err != nil {
+			return err
+		}
+		rows, err := queryer.Query(stmt, args...)
+		if err != nil {
+			return err
+		}
+		out, err = scanRows(rows)
+		return err
+	})
+	return out, err
+}
+
+func (s *journalStore) ListPending(ctx context.Context, now int64) ([]*core.JournalEntry, error) {
+	var out []*core.JournalEntry
+	err := s.db.View(func(queryer db.Queryer, binder db.Binder) error {
+		params := map[string]interface{}{
+			"journal_status": core.JournalPending,
+			"journal_next":   now,
+		}
+		stmt, args, err := binder.BindNamed(queryPending, params)
+		if err != nil {
+			return err
+		}
+		rows, err := queryer.Query(stmt, args...)
+		if err != nil {
+			return err
+		}
+		out, err = scanRows(rows)
+		return err
+	})
+	return out, err
+}
+
+func (s *journalStore) Find(ctx context.Context, id int64) (*core.JournalEntry, error) {
+	out := &core.JournalEntry{ID: id}
+	err := s.db.View(func(queryer db.Queryer, binder db.Binder) error {
+		params := toParams(out)
+		query, args, err := binder.BindNamed(queryKey, params)
+		if err != nil {
+			return err
+		}
+		row := queryer.QueryRow(query, args...)
+		return scanRow(row, out)
+	})
+	return out, err
+}
+
+func (s *journalStore) FindDelivery(ctx context.Context, delivery string) (*core.JournalEntry, error) {
+	out := &core.JournalEntry{Delivery: delivery}
+	err := s.db.View(func(queryer db.Queryer, binder db.Binder) error {
+		params := toParams(out)
+		query, args, err := binder.BindNamed(queryDelivery, params)
+		if err != nil {
+			return err
+		}
+		row := queryer.QueryRow(query, args...)
+		return scanRow(row, out)
+	})
+	return out, err
+}
+
+func (s *journalStore) Create(ctx context.Context, entry *core.JournalEntry) error {
+	if s.db.Driver() == db.Postgres {
+		return s.createPostgres(ctx, entry)
+	}
+	return s.create(ctx, entry)
+}
+
+func (s *journalStore) create(ctx context.Context, entry *core.JournalEntry) error {
+	return s.db.Lock(func(execer db.Execer, binder db.Binder) error {
+		params := toParams(entry)
+		stmt, args, err := binder.BindNamed(stmtInsert, params)
+		if err != nil {
+			return err
+		}
+		res, err := execer.Exec(stmt, args...)
+		if err != nil {
+			return err
+		}
+		entry.ID, err = res.LastInsertId()
+		return err
+	})
+}
+
+func (s *journalStore) createPostgres(ctx context.Context, entry *core.JournalEntry) error {
+	return s.db.Lock(func(execer db.Execer, binder db.Binder) error {
+		params := toParams(entry)
+		stmt, args, err := binder.BindNamed(stmtInsertPg, params)
+		if err != nil {
+			return err
+		}
+		return execer.QueryRow(stmt, args...).Scan(&entry.ID)
+	})
+}
+
+func (s *journalStore) Update(ctx context.Context, entry *core.JournalEntry) error {
+	versionNew := entry.Version + 1
+	versionOld := entry.Version
+
+	err := s.db.Lock(func(execer db.Execer, binder db.Binder) error {
+		params := toParams(entry)
+		params["journal_version_old"] = versionOld
+		params["journal_version_new"] = versionNew
+		stmt, args, err := binder.BindNamed(stmtUpdate, params)
+		if err != nil {
+			return err
+		}
+		res, err := execer.Exec(stmt, args...)
+		if err != nil {
+			return err
+		}
+		effected, err := res.RowsAffected()
+		if err != nil {
+			return err
+		}
+		if effected == 0 {
+			return db.ErrOptimisticLock
+		}
+		return nil
+	})
+	if err == nil {
+		entry.Version = versionNew
+	}
+	return err
+}
+
+func (s *journalStore) Purge(ctx context.Context, before int64) error {
+	return s.db.Lock(func(execer db.Execer, binder db.Binder) error {
+		params := map[string]interface{}{
+			"journal_status":  core.JournalPending,
+			"journal_created": before,
+		}
+		stmt, args, err := binder.BindNamed(stmtPurge, params)
+		if err != nil {
+			return err
+		}
+		_, err = execer.Exec(stmt, args...)
+		return err
+	})
+}
+
+const queryBase = `
+SELECT
+ journal_id
+,journal_repo_id
+,journal_delivery
+,journal_event
+,journal_action
+,journal_hook
+,journal_status
+,journal_error
+,journal_build
+,journal_attempts
+,journal_next
+,journal_created
+,journal_updated
+,journal_version
+`
+
+const queryKey = queryBase + `
+FROM journal
+WHERE journal_id = :journal_id
+LIMIT 1
+`
+
+const queryDelivery = queryBase + `
+FROM journal
+WHERE journal_delivery = :journal_delivery
+LIMIT 1
+`
+
+const queryAll = queryBase + `
+FROM journal
+ORDER BY journal_id DESC
+LIMIT :limit OFFSET :offset
+`
+
+const queryRepo = queryBase + `
+FROM journal
+WHERE journal_repo_id = :journal_repo_id
+ORDER BY journal_id DESC
+LIMIT :limit OFFSET :offset
+`
+
+const queryPending = queryBase + `
+FROM journal
+WHERE journal_status = :journal_status
+  AND journal_next <= :journal_next
+ORDER BY journal_id ASC
+`
+
+const stmtUpdate = `
+UPDATE journal SET
+ journal_repo_id = :journal_repo_id
+,journal_event = :journal_event
+,journal_action = :journal_action
+,journal_hook = :journal_hook
+,journal_status = :journal_status
+,journal_error = :journal_error
+,journal_build = :journal_build
+,journal_attempts = :journal_attempts
+,journal_next = :journal_next
+,journal_updated = :journal_updated
+,journal_version = :journal_version_new
+WHERE journal_id = :journal_id
+  AND journal_version = :journal_version_old
+`
+
+const stmtInsert = `
+INSERT INTO journal (
+ journal_repo_id
+,journal_delivery
+,journal_event
+,journal_action
+,journal_hook
+,journal_status
+,journal_error
+,journal_build
+,journal_attempts
+,journal_next
+,journal_created
+,journal_updated
+,journal_version
+) VALUES (
+ :journal_repo_id
+,:journal_delivery
+,:journal_event
+,:journal_action
+,:journal_hook
+,:journal_status
+,:journal_error
+,:journal_build
+,:journal_attempts
+,:journal_next
+,:journal_created
+,:journal_updated
+,:journal_version
+)
+`
+
+const stmtInsertPg = stmtInsert + `
+RETURNING journal_id
+`
+
+const stmtPurge = `
+DELETE FROM journal
+WHERE journal_status != :journal_status
+  AND journal_created < :journal_created
+`
@@ -0,0 +1,222 @@
+// Copyright 2019 Drone.IO Inc. All rights reserved.
+// Use of this source code is governed by the Drone Non-Commercial License
+// that can be found in the LICENSE file.
+
+// +build !oss
+
+package journal
+
+import (
+	"context"
+	"testing"
+
+	"github.com/drone/drone/core"
+	"github.com/drone/drone/store/shared/db"
+	"github.com/drone/drone/store/shared/db/dbtest"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+var noContext = context.TODO()
+
+func TestJournal(t *testing.T) {
+	conn, err := dbtest.Connect()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	defer func() {
+		dbtest.Reset(conn)
+		dbtest.Disconnect(conn)
+	}()
+
+	store := New(conn).(*journalStore)
+	t.Run("Create", testJournalCreate(store))
+}
+
+func testJournalCreate(store *journalStore) func(t *testing.T) {
+	return func(t *testing.T) {
+		items := []*core.JournalEntry{
+			{
+				RepoID:   1,
+				Delivery: "72d3162e-cc78-11e3-81ab-4c9367dc0958",
+				Event:    core.EventPush,
+				Hook:     &core.Hook{Event: core.EventPush, After: "7fd1a60b01f91b314f59955a4e4d4e80d8edf11d"},
+				Status:   core.JournalSuccess,
+				Build:    42,
+				Attempts: 1,
+				Created:  1522878684,
+				Updated:  1522878684,
+			},
+			{
+				Delivery: "8d7b8a4e-cc78-11e3-81ab-4c9367dc0958",
+				Status:   core.JournalFailed,
+				Error:    "cannot parse webhook",
+				Created:  1522878685,
+				Updated:  1522878685,
+			},
+			{
+				RepoID:   1,
+				Delivery: "9a4c1f0e-cc78-11e3-81ab-4c9367dc0958",
+				Event:    core.EventTag,
+				Hook:     &core.Hook{Event: core.EventTag, Ref: "refs/tags/v1.0.0"},
+				Status:   core.JournalPending,
+				Next:     1522878686,
+				Created:  1522878686,
+				Updated:  1522878686,
+			},
+		}
+		for _, item := range items {
+			err := store.Create(noContext, item)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			if item.ID == 0 {
+				t.Errorf("Want journal ID assigned, got %d", item.ID)
+			}
+		}
+
+		t.Run("Duplicate", testJournalDuplicate(store, items[0]))
+		t.Run("Find", testJournalFind(store, items[0]))
+		t.Run("FindDelivery", testJournalFindDelivery(store, items[1]))
+		t.Run("List", testJournalList(store, items))
+		t.Run("ListRepo", testJournalListRepo(store, items))
+		t.Run("ListPending", testJournalListPending(store, items[2]))
+		t.Run("Update", testJournalUpdate(store, items[2]))
+		t.Run("Purge", testJournalPurge(store, items))
+	}
+}
+
+func testJournalDuplicate(store *journalStore, entry *core.JournalEntry) func(t *testing.T) {
+	return func(t *testing.T) {
+		err := store.Create(noContext, &core.JournalEntry{Delivery: entry.Delivery})
+		if err == nil {
+			t.Errorf("Want unique constraint error for duplicate delivery")
+		}
+	}
+}
+
+func testJournalFind(store *journalStore, entry *core.JournalEntry) func(t *testing.T) {
+	return func(t *testing.T) {
+		result, err := store.Find(noContext, entry.ID)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if diff := cmp.Diff(result, entry); diff != "" {
+			t.Errorf(diff)
+		}
+	}
+}
+
+func testJournalFindDelivery(store *journalStore, entry *core.JournalEntry) func(t *testing.T) {
+	return func(t *testing.T) {
+		result, err := store.FindDelivery(noContext, entry.Delivery)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if diff := cmp.Diff(result, entry); diff != "" {
+			t.Errorf(diff)
+		}
+	}
+}
+
+func testJournalList(store *journalStore, entries []*core.JournalEntry) func(t *testing.T) {
+	return func(t *testing.T) {
+		list, err := store.List(noContext, 2, 0)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		want := []*core.JournalEntry{entries[2], entries[1]}
+		if diff := cmp.Diff(list, want); diff != "" {
+			t.Errorf(diff)
+		}
+	}
+}
+
+func testJournalListRepo(store *journalStore, entries []*core.JournalEntry) func(t *testing.T) {
+	return func(t *testing.T) {
+		list, err := store.ListRepo(noContext, 1, 25, 0)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		want := []*core.JournalEntry{entries[2], entries[0]}
+		if diff := cmp.Diff(list, want); diff != "" {
+			t.Errorf(diff)
+		}
+	}
+}
+
+func testJournalListPending(store *journalStore, entry *core.JournalEntry) func(t *testing.T) {
+	return func(t *testing.T) {
+		list, err := store.ListPending(noContext, entry.Next-1)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if len(list) != 0 {
+			t.Errorf("Want no pending entries before the next timestamp")
+		}
+		list, err = store.ListPending(noContext, entry.Next)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if diff := cmp.Diff(list, []*core.JournalEntry{entry}); diff != "" {
+			t.Errorf(diff)
+		}
+	}
+}
+
+func testJournalUpdate(store *journalStore, entry *core.JournalEntry) func(t *testing.T) {
+	return func(t *testing.T) {
+		stale := *entry
+		entry.Status = core.JournalFailed
+		entry.Error = "trigger failed"
+		entry.Attempts = 1
+		entry.Action = core.ActionCreate
+		entry.Hook = &core.Hook{Event: core.EventTag, Action: core.ActionCreate, Ref: "refs/tags/v1.0.1"}
+		err := store.Update(noContext, entry)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if got, want := entry.Version, stale.Version+1; got != want {
+			t.Errorf("Want incremented version %d, got %d", want, got)
+		}
+		result, err := store.Find(noContext, entry.ID)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if diff := cmp.Diff(result, entry); diff != "" {
+			t.Errorf(diff)
+		}
+		err = store.Update(noContext, &stale)
+		if err != db.ErrOptimisticLock {
+			t.Errorf("Want optimistic lock error, got %v", err)
+		}
+	}
+}
+
+func testJournalPurge(store *journalStore, entries []*core.JournalEntry) func(t *testing.T) {
+	return func(t *testing.T) {
+		err := store.Purge(noContext, entries[2].Created)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		list, err := store.List(noContext, 25, 0)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if diff := cmp.Diff(list, entries[2:]); diff != "" {
+			t.Errorf(diff)
+		}
+	}
+}
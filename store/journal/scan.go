@@ -0,0 +1,101 @@
+// Copyright 2019 Drone IO, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package journal
+
+import (
+	"database/sql"
+	"encoding/json"
+
+	"github.com/drone/drone/core"
+	"github.com/drone/drone/store/shared/db"
+)
+
+// helper function converts the JournalEntry structure to a
+// set of named query parameters.
+func toParams(entry *core.JournalEntry) map[string]interface{} {
+	return map[string]interface{}{
+		"journal_id":       entry.ID,
+		"journal_repo_id":  entry.RepoID,
+		"journal_delivery": entry.Delivery,
+		"journal_event":    entry.Event,
+		"journal_action":   entry.Action,
+		"journal_hook":     encodeHook(entry.Hook),
+		"journal_status":   entry.Status,
+		"journal_error":    entry.Error,
+		"journal_build":    entry.Build,
+		"journal_attempts": entry.Attempts,
+		"journal_next":     entry.Next,
+		"journal_created":  entry.Created,
+		"journal_updated":  entry.Updated,
+		"journal_version":  entry.Version,
+	}
+}
+
+// helper function encodes the hook to a json string. A nil
+// hook is stored as a null value.
+func encodeHook(hook *core.Hook) sql.NullString {
+	if hook == nil {
+		return sql.NullString{}
+	}
+	raw, _ := json.Marshal(hook)
+	return sql.NullString{String: string(raw), Valid: true}
+}
+
+// helper function scans the sql.Row and copies the column
+// values to the destination object.
+func scanRow(scanner db.Scanner, dst *core.JournalEntry) error {
+	hook := sql.NullString{}
+	err := scanner.Scan(
+		&dst.ID,
+		&dst.RepoID,
+		&dst.Delivery,
+		&dst.Event,
+		&dst.Action,
+		&hook,
+		&dst.Status,
+		&dst.Error,
+		&dst.Build,
+		&dst.Attempts,
+		&dst.Next,
+		&dst.Created,
+		&dst.Updated,
+		&dst.Version,
+	)
+	if err != nil {
+		return err
+	}
+	if hook.Valid {
+		dst.Hook = new(core.Hook)
+		json.Unmarshal([]byte(hook.String), dst.Hook)
+	}
+	return nil
+}
+
+// helper function scans the sql.Row and copies the column
+// values to the destination object.
+func scanRows(rows *sql.Rows) ([]*core.JournalEntry, error) {
+	defer rows.Close()
+
+	entries := []*core.JournalEntry{}
+	for rows.Next() {
+		entry := new(core.JournalEntry)
+		err := scanRow(rows, entry)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
@@ -0,0 +1,166 @@
+// Copyright 2019 Drone IO, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logs
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"io"
+
+	"github.com/drone/drone/core"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// Compression algorithms supported by the log store.
+const (
+	CompressionGzip = "gzip"
+	CompressionZstd = "zstd"
+)
+
+// magic numbers used to detect compressed logs.
+var (
+	magicGzip = []byte{0x1f, 0x8b}
+	magicZstd = []byte{0x28, 0xb5, 0x2f, 0xfd}
+)
+
+// NewCompressed returns a new LogStore that compresses the logs
+// using the named algorithm before they are written to the
+// underlying store. Compressed logs are transparently decompressed
+// when read, and logs written before compression was enabled
+// remain readable. If the algorithm is empty the logs are not
+// compressed.
+func NewCompressed(store core.LogStore, algorithm string) core.LogStore {
+	return &compressed{
+		store:     store,
+		algorithm: algorithm,
+	}
+}
+
+type compressed struct {
+	store     core.LogStore
+	algorithm string
+}
+
+func (s *compressed) Find(ctx context.Context, step int64) (io.ReadCloser, error) {
+	rc, err := s.store.Find(ctx, step)
+	if err != nil {
+		return rc, err
+	}
+	return decompress(rc)
+}
+
+func (s *compressed) Create(ctx context.Context, step int64, r io.Reader) error {
+	r, err := s.compress(r)
+	if err != nil {
+		return err
+	}
+	return s.store.Create(ctx, step, r)
+}
+
+func (s *compressed) Update(ctx context.Context, step int64, r io.Reader) error {
+	r, err := s.compress(r)
+	if err != nil {
+		return err
+	}
+	return s.store.Update(ctx, step, r)
+}
+
+func (s *compressed) Delete(ctx context.Context, step int64) error {
+	return s.store.Delete(ctx, step)
+}
+
+// helper function compresses the logs using the configured
+// compression algorithm.
+func (s *compressed) compress(r io.Reader) (io.Reader, error) {
+	var w io.WriteCloser
+	buf := new(bytes.Buffer)
+	switch s.algorithm {
+	case CompressionGzip:
+		w = gzip.NewWriter(buf)
+	case CompressionZstd:
+		enc, err := zstd.NewWriter(buf)
+		if err != nil {
+			return nil, err
+		}
+		w = enc
+	default:
+		return r, nil
+	}
+	if _, err := io.Copy(w, r); err != nil {
+		w.Close()
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return buf, nil
+}
+
+// helper function returns a reader that decompresses the logs,
+// detecting the compression algorithm from the magic number.
+// Uncompressed logs are returned as-is.
+func decompress(rc io.ReadCloser) (io.ReadCloser, error) {
+	br := bufio.NewReader(rc)
+	head, _ := br.Peek(len(magicZstd))
+	switch {
+	case bytes.HasPrefix(head, magicGzip):
+		zr, err := gzip.NewReader(br)
+		if err != nil {
+			rc.Close()
+			return nil, err
+		}
+		return &readCloser{Reader: zr, closers: []io.Closer{zr, rc}}, nil
+	case bytes.HasPrefix(head, magicZstd):
+		zr, err := zstd.NewReader(br)
+		if err != nil {
+			rc.Close()
+			return nil, err
+		}
+		return &readCloser{Reader: zr, closers: []io.Closer{zstdCloser{zr}, rc}}, nil
+	default:
+		return &readCloser{Reader: br, closers: []io.Closer{rc}}, nil
+	}
+}
+
+// readCloser reads from the decompressed stream and closes
+// the decompressor and the underlying stream.
+type readCloser struct {
+	io.Reader
+	closers []io.Closer
+}
+
+func (r *readCloser) Close() error {
+	var err error
+	for _, c := range r.closers {
+		if cerr := c.Close(); cerr != nil && err == nil {
+			err = cerr
+		}
+	}
+	return err
+}
+
+// zstdCloser adapts the zstd decoder, which does not return
+// an error when closed, to the io.Closer interface.
+type zstdCloser struct {
+	*zstd.Decoder
+}
+
+func (c zstdCloser) Close() error {
+	c.Decoder.Close()
+	return nil
+}
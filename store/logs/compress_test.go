@@ -0,0 +1,78 @@
+// Copyright 2019 Drone.IO Inc. All rights reserved.
+// Use of this source code is governed by the Drone Non-Commercial License
+// that can be found in the LICENSE file.
+
+package logs
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"io/ioutil"
+	"testing"
+)
+
+// memStore is an in-memory log store used to inspect the
+// data written by the compressed log store.
+type memStore map[int64][]byte
+
+func (m memStore) Find(ctx context.Context, step int64) (io.ReadCloser, error) {
+	return ioutil.NopCloser(bytes.NewReader(m[step])), nil
+}
+
+func (m memStore) Create(ctx context.Context, step int64, r io.Reader) error {
+	data, err := ioutil.ReadAll(r)
+	m[step] = data
+	return err
+}
+
+func (m memStore) Update(ctx context.Context, step int64, r io.Reader) error {
+	return m.Create(ctx, step, r)
+}
+
+func (m memStore) Delete(ctx context.Context, step int64) error {
+	delete(m, step)
+	return nil
+}
+
+func TestCompressed(t *testing.T) {
+	data := []byte(`[{"pos":0,"out":"hello world\n","time":0}]`)
+	for _, algorithm := range []string{"", CompressionGzip, CompressionZstd} {
+		store := memStore{}
+		compressed := NewCompressed(store, algorithm)
+		err := compressed.Create(noContext, 1, bytes.NewBuffer(data))
+		if err != nil {
+			t.Error(err)
+			continue
+		}
+		if got := store[1]; algorithm != "" && bytes.Equal(got, data) {
+			t.Errorf("Want logs compressed with %s", algorithm)
+		}
+
+		rc, err := compressed.Find(noContext, 1)
+		if err != nil {
+			t.Error(err)
+			continue
+		}
+		got, _ := ioutil.ReadAll(rc)
+		rc.Close()
+		if !bytes.Equal(got, data) {
+			t.Errorf("Want decompressed logs %s, got %s", data, got)
+		}
+
+		// logs written with any algorithm, or written before
+		// compression was enabled, remain readable.
+		for _, other := range []string{"", CompressionGzip, CompressionZstd} {
+			rc, err := NewCompressed(store, other).Find(noContext, 1)
+			if err != nil {
+				t.Error(err)
+				continue
+			}
+			got, _ := ioutil.ReadAll(rc)
+			rc.Close()
+			if !bytes.Equal(got, data) {
+				t.Errorf("Want logs written with %q readable with %q", algorithm, other)
+			}
+		}
+	}
+}
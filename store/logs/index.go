@@ -0,0 +1,322 @@
+// Copyright 2019 Drone IO, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logs
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/drone/drone/core"
+	"github.com/drone/drone/store/shared/db"
+)
+
+// default number of log lines returned by a search.
+const defaultSearchLimit = 100
+
+// default search window. Only the logs of builds created
+// within the window are searched.
+const defaultSearchWindow = time.Hour * 24 * 7
+
+// NewIndex returns a new LogIndex that searches the logs
+// persisted by the database LogStore. The logs are written
+// to the database when uploaded, so indexing is a no-op.
+// The search is limited to builds created within the window
+// so that the logs table is never scanned in full.
+func NewIndex(db *db.DB, window time.Duration) core.LogIndex {
+	return &logIndex{db: db, query: querySearch, window: window}
+}
+
+// NewLocalIndex returns a new LogIndex that keeps a searchable
+// copy of the logs in the database. It should be used when the
+// logs are persisted to external storage, such as S3 or Azure
+// Blob Storage, or are compressed in the database. The search
+// is limited to builds created within the window.
+func NewLocalIndex(db *db.DB, window time.Duration) core.LogIndex {
+	return &logIndex{db: db, query: querySearchLocal, window: window, local: true}
+}
+
+type logIndex struct {
+	db     *db.DB
+	query  string
+	window time.Duration
+	local  bool
+}
+
+func (s *logIndex) Index(ctx context.Context, step int64, data []byte) error {
+	if !s.local {
+		return nil
+	}
+	return s.db.Lock(func(execer db.Execer, binder db.Binder) error {
+		params := &logs{
+			ID:   step,
+			Data: data,
+		}
+		stmt, args, err := binder.BindNamed(stmtIndexDelete, params)
+		if err != nil {
+			return err
+		}
+		if _, err = execer.Exec(stmt, args...); err != nil {
+			return err
+		}
+		stmt, args, err = binder.BindNamed(stmtIndexInsert, params)
+		if err != nil {
+			return err
+		}
+		_, err = execer.Exec(stmt, args...)
+		return err
+	})
+}
+
+func (s *logIndex) Search(ctx context.Context, query *core.LogQuery) ([]*core.LogHit, error) {
+	if query.Text == "" || len(query.Repos) == 0 {
+		return nil, nil
+	}
+	limit := query.Limit
+	if limit <= 0 {
+		limit = defaultSearchLimit
+	}
+	window := s.window
+	if window <= 0 {
+		window = defaultSearchWindow
+	}
+	since := time.Now().Add(-window).Unix()
+	var out []*core.LogHit
+	err := s.db.View(func(queryer db.Queryer, binder db.Binder) error {
+		// the query is not limited, since the pattern matches
+		// false positives that do not produce hits. The rows
+		// are instead read until the hit limit is reached.
+		stmt, params := searchQuery(s.query, query.Text, query.Repos, since)
+		stmt, args, err := binder.BindNamed(stmt, params)
+		if err != nil {
+			return err
+		}
+		rows, err := queryer.Query(stmt, args...)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+		for rows.Next() && len(out) < limit {
+			hit := new(core.LogHit)
+			var data []byte
+			err := rows.Scan(
+				&hit.RepoID,
+				&hit.Slug,
+				&hit.BuildID,
+				&hit.Build,
+				&hit.Stage,
+				&hit.StageName,
+				&hit.StepID,
+				&hit.Step,
+				&hit.StepName,
+				&data,
+			)
+			if err != nil {
+				return err
+			}
+			out = append(out, match(hit, data, query.Text, limit-len(out))...)
+		}
+		return rows.Err()
+	})
+	return out, err
+}
+
+func (s *logIndex) Delete(ctx context.Context, step int64) error {
+	if !s.local {
+		return nil
+	}
+	return s.db.Lock(func(execer db.Execer, binder db.Binder) error {
+		params := &logs{
+			ID: step,
+		}
+		stmt, args, err := binder.BindNamed(stmtIndexDelete, params)
+		if err != nil {
+			return err
+		}
+		_, err = execer.Exec(stmt, args...)
+		return err
+	})
+}
+
+func (s *logIndex) Purge(ctx context.Context, repo, before int64) error {
+	if !s.local {
+		return nil
+	}
+	return s.db.Lock(func(execer db.Execer, binder db.Binder) error {
+		params := map[string]interface{}{
+			"build_repo_id": repo,
+			"build_number":  before,
+		}
+		stmt, args, err := binder.BindNamed(stmtIndexPurge, params)
+		if err != nil {
+			return err
+		}
+		_, err = execer.Exec(stmt, args...)
+		return err
+	})
+}
+
+// helper function decodes the step logs and returns a hit for
+// each line containing the text, up to the limit.
+func match(step *core.LogHit, data []byte, text string, limit int) []*core.LogHit {
+	var lines []*core.Line
+	if err := json.Unmarshal(data, &lines); err != nil {
+		return nil
+	}
+	var hits []*core.LogHit
+	for _, line := range lines {
+		if len(hits) == limit {
+			break
+		}
+		if !strings.Contains(line.Message, text) {
+			continue
+		}
+		hit := *step
+		hit.Line = line.Number
+		hit.Message = line.Message
+		hits = append(hits, &hit)
+	}
+	return hits
+}
+
+// helper function returns the search query and parameters
+// for the text, limited to the listed repositories and the
+// builds created since the unix timestamp.
+func searchQuery(base, text string, repos []int64, since int64) (string, map[string]interface{}) {
+	args := map[string]interface{}{
+		"log_pattern": []byte(pattern(text)),
+		"since":       since,
+	}
+	var keys []string
+	for i, repo := range repos {
+		key := fmt.Sprintf("repo_id_%d", i)
+		args[key] = repo
+		keys = append(keys, ":"+key)
+	}
+	return fmt.Sprintf(base, strings.Join(keys, ",")), args
+}
+
+// helper function returns a LIKE pattern that matches the
+// JSON-encoded logs containing the text. Characters that may
+// be escaped when the logs are encoded are replaced with a
+// wildcard; false positives are discarded when the matching
+// logs are decoded. The LIKE wildcards, and the escape
+// character itself, are escaped with the escape character.
+func pattern(text string) string {
+	var b strings.Builder
+	b.WriteByte('%')
+	wildcard := true
+	for _, r := range text {
+		switch {
+		case r < 0x20, r > 0x7e, r == '"', r == '\\', r == '<', r == '>', r == '&':
+			if !wildcard {
+				b.WriteByte('%')
+				wildcard = true
+			}
+		case r == '%', r == '_', r == patternEscape:
+			b.WriteByte(patternEscape)
+			b.WriteRune(r)
+			wildcard = false
+		default:
+			b.WriteRune(r)
+			wildcard = false
+		}
+	}
+	if !wildcard {
+		b.WriteByte('%')
+	}
+	return b.String()
+}
+
+// patternEscape is the escape character of the LIKE pattern.
+// A character that is not escaped when the logs are encoded
+// is used, since the escape sequences of string literals vary
+// by database driver.
+const patternEscape = '!'
+
+const querySearch = `
+SELECT
+ repo_id
+,repo_slug
+,build_id
+,build_number
+,stage_number
+,stage_name
+,step_id
+,step_number
+,step_name
+,log_data
+FROM logs
+INNER JOIN steps ON steps.step_id = logs.log_id
+INNER JOIN stages ON stages.stage_id = steps.step_stage_id
+INNER JOIN builds ON builds.build_id = stages.stage_build_id
+INNER JOIN repos ON repos.repo_id = builds.build_repo_id
+WHERE build_repo_id IN (%s)
+  AND build_created >= :since
+  AND log_data LIKE :log_pattern ESCAPE '!'
+ORDER BY build_id DESC, stage_number ASC, step_number ASC
+`
+
+const querySearchLocal = `
+SELECT
+ repo_id
+,repo_slug
+,build_id
+,build_number
+,stage_number
+,stage_name
+,step_id
+,step_number
+,step_name
+,log_index_data
+FROM log_index
+INNER JOIN steps ON steps.step_id = log_index.log_index_id
+INNER JOIN stages ON stages.stage_id = steps.step_stage_id
+INNER JOIN builds ON builds.build_id = stages.stage_build_id
+INNER JOIN repos ON repos.repo_id = builds.build_repo_id
+WHERE build_repo_id IN (%s)
+  AND build_created >= :since
+  AND log_index_data LIKE :log_pattern ESCAPE '!'
+ORDER BY build_id DESC, stage_number ASC, step_number ASC
+`
+
+const stmtIndexInsert = `
+INSERT INTO log_index (
+ log_index_id
+,log_index_data
+) VALUES (
+ :log_id
+,:log_data
+)
+`
+
+const stmtIndexDelete = `
+DELETE FROM log_index
+WHERE log_index_id = :log_id
+`
+
+const stmtIndexPurge = `
+DELETE FROM log_index
+WHERE log_index_id IN (
+  SELECT step_id
+  FROM steps
+  INNER JOIN stages ON stages.stage_id = steps.step_stage_id
+  INNER JOIN builds ON builds.build_id = stages.stage_build_id
+  WHERE build_repo_id = :build_repo_id
+    AND build_number < :build_number
+)
+`
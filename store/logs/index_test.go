@@ -0,0 +1,248 @@
+// Copyright 2019 Drone.IO Inc. All rights reserved.
+// Use of this source code is governed by the Drone Non-Commercial License
+// that can be found in the LICENSE file.
+
+package logs
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/drone/drone/core"
+	"github.com/drone/drone/store/build"
+	"github.com/drone/drone/store/repos"
+	"github.com/drone/drone/store/shared/db"
+	"github.com/drone/drone/store/shared/db/dbtest"
+	"github.com/drone/drone/store/step"
+)
+
+func TestIndex(t *testing.T) {
+	conn, err := dbtest.Connect()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	defer func() {
+		dbtest.Reset(conn)
+		dbtest.Disconnect(conn)
+	}()
+
+	// seed with a dummy repository
+	arepo := &core.Repository{UID: "1", Slug: "octocat/hello-world"}
+	repos := repos.New(conn)
+	repos.Create(noContext, arepo)
+
+	// seed with a dummy stage
+	stage := &core.Stage{Number: 1, Name: "default"}
+	stages := []*core.Stage{stage}
+
+	// seed with a dummy build
+	abuild := &core.Build{Number: 1, RepoID: arepo.ID, Created: time.Now().Unix()}
+	builds := build.New(conn)
+	builds.Create(noContext, abuild, stages)
+
+	// seed with a dummy step
+	astep := &core.Step{Number: 1, Name: "test", StageID: stage.ID}
+	steps := step.New(conn)
+	steps.Create(noContext, astep)
+
+	data := []byte(`[{"pos":0,"out":"go test ./...\n"},{"pos":1,"out":"--- FAIL: TestLogs <nil>\n"},{"pos":2,"out":"FAIL\n"}]`)
+
+	t.Run("Database", func(t *testing.T) {
+		store := New(conn)
+		store.Create(noContext, astep.ID, bytes.NewBuffer(data))
+		defer store.Delete(noContext, astep.ID)
+
+		index := NewIndex(conn, time.Hour)
+		testIndexSearch(index, arepo, astep)(t)
+	})
+
+	t.Run("Local", func(t *testing.T) {
+		index := NewLocalIndex(conn, time.Hour)
+		if err := index.Index(noContext, astep.ID, data); err != nil {
+			t.Error(err)
+			return
+		}
+		// indexing the logs twice should replace the
+		// existing entry.
+		if err := index.Index(noContext, astep.ID, data); err != nil {
+			t.Error(err)
+			return
+		}
+		testIndexSearch(index, arepo, astep)(t)
+
+		if err := index.Delete(noContext, astep.ID); err != nil {
+			t.Error(err)
+			return
+		}
+		hits, err := index.Search(noContext, &core.LogQuery{Text: "FAIL", Repos: []int64{arepo.ID}})
+		if err != nil {
+			t.Error(err)
+		} else if len(hits) != 0 {
+			t.Errorf("Want no hits after delete, got %d", len(hits))
+		}
+
+		// the logs of builds with a lower build number are
+		// removed from the index when the builds are purged.
+		if err := index.Index(noContext, astep.ID, data); err != nil {
+			t.Error(err)
+			return
+		}
+		if err := index.Purge(noContext, arepo.ID, abuild.Number); err != nil {
+			t.Error(err)
+			return
+		}
+		hits, _ = index.Search(noContext, &core.LogQuery{Text: "FAIL", Repos: []int64{arepo.ID}})
+		if got, want := len(hits), 2; got != want {
+			t.Errorf("Want %d hits for builds that are not purged, got %d", want, got)
+		}
+		if err := index.Purge(noContext, arepo.ID, abuild.Number+1); err != nil {
+			t.Error(err)
+			return
+		}
+		hits, _ = index.Search(noContext, &core.LogQuery{Text: "FAIL", Repos: []int64{arepo.ID}})
+		if got, want := len(hits), 0; got != want {
+			t.Errorf("Want %d hits after purge, got %d", want, got)
+		}
+	})
+
+	// the LIKE wildcards in the text are matched literally,
+	// and the hit limit is applied after false positives are
+	// discarded.
+	t.Run("Pattern", func(t *testing.T) {
+		bstep := &core.Step{Number: 2, Name: "lint", StageID: stage.ID}
+		steps.Create(noContext, bstep)
+
+		store := New(conn)
+		store.Create(noContext, astep.ID, bytes.NewBufferString(`[{"pos":0,"out":"coverage 1000 a b\n"}]`))
+		store.Create(noContext, bstep.ID, bytes.NewBufferString(`[{"pos":0,"out":"coverage 100% a\"b\n"}]`))
+		defer store.Delete(noContext, astep.ID)
+		defer store.Delete(noContext, bstep.ID)
+
+		index := NewIndex(conn, time.Hour)
+		hits, err := index.Search(noContext, &core.LogQuery{Text: "100%", Repos: []int64{arepo.ID}})
+		if err != nil {
+			t.Error(err)
+		} else if len(hits) != 1 || hits[0].StepID != bstep.ID {
+			t.Errorf("Want a single hit for the escaped wildcard, got %d", len(hits))
+		}
+		hits, err = index.Search(noContext, &core.LogQuery{Text: `a"b`, Repos: []int64{arepo.ID}, Limit: 1})
+		if err != nil {
+			t.Error(err)
+		} else if len(hits) != 1 || hits[0].StepID != bstep.ID {
+			t.Errorf("Want a hit after the false positive, got %d", len(hits))
+		}
+	})
+
+	// the search is limited to builds created within the
+	// search window.
+	t.Run("Window", func(t *testing.T) {
+		store := New(conn)
+		store.Create(noContext, astep.ID, bytes.NewBuffer(data))
+		defer store.Delete(noContext, astep.ID)
+
+		err := conn.Lock(func(execer db.Execer, binder db.Binder) error {
+			stmt, args, err := binder.BindNamed(
+				"UPDATE builds SET build_created = :build_created WHERE build_id = :build_id",
+				map[string]interface{}{
+					"build_created": time.Now().Add(-2 * time.Hour).Unix(),
+					"build_id":      abuild.ID,
+				},
+			)
+			if err != nil {
+				return err
+			}
+			_, err = execer.Exec(stmt, args...)
+			return err
+		})
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		hits, err := NewIndex(conn, time.Hour).Search(noContext, &core.LogQuery{Text: "FAIL", Repos: []int64{arepo.ID}})
+		if err != nil {
+			t.Error(err)
+		} else if len(hits) != 0 {
+			t.Errorf("Want no hits outside the search window, got %d", len(hits))
+		}
+	})
+}
+
+func testIndexSearch(index core.LogIndex, repo *core.Repository, step *core.Step) func(t *testing.T) {
+	return func(t *testing.T) {
+		hits, err := index.Search(noContext, &core.LogQuery{Text: "FAIL", Repos: []int64{repo.ID}})
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if got, want := len(hits), 2; got != want {
+			t.Errorf("Want %d hits, got %d", want, got)
+			return
+		}
+		hit := hits[0]
+		if got, want := hit.Slug, repo.Slug; got != want {
+			t.Errorf("Want repository slug %q, got %q", want, got)
+		}
+		if got, want := hit.Build, int64(1); got != want {
+			t.Errorf("Want build number %d, got %d", want, got)
+		}
+		if got, want := hit.StageName, "default"; got != want {
+			t.Errorf("Want stage name %q, got %q", want, got)
+		}
+		if got, want := hit.StepID, step.ID; got != want {
+			t.Errorf("Want step id %d, got %d", want, got)
+		}
+		if got, want := hit.StepName, "test"; got != want {
+			t.Errorf("Want step name %q, got %q", want, got)
+		}
+		if got, want := hit.Line, 1; got != want {
+			t.Errorf("Want line %d, got %d", want, got)
+		}
+
+		// escaped characters must match the decoded text.
+		hits, _ = index.Search(noContext, &core.LogQuery{Text: "TestLogs <nil>", Repos: []int64{repo.ID}})
+		if got, want := len(hits), 1; got != want {
+			t.Errorf("Want %d hits, got %d", want, got)
+		}
+
+		// the search is case-sensitive.
+		hits, _ = index.Search(noContext, &core.LogQuery{Text: "fail", Repos: []int64{repo.ID}})
+		if got, want := len(hits), 0; got != want {
+			t.Errorf("Want %d hits, got %d", want, got)
+		}
+
+		// the search is limited to the listed repositories.
+		hits, _ = index.Search(noContext, &core.LogQuery{Text: "FAIL", Repos: []int64{repo.ID + 1}})
+		if got, want := len(hits), 0; got != want {
+			t.Errorf("Want %d hits, got %d", want, got)
+		}
+
+		// the number of hits is limited.
+		hits, _ = index.Search(noContext, &core.LogQuery{Text: "FAIL", Repos: []int64{repo.ID}, Limit: 1})
+		if got, want := len(hits), 1; got != want {
+			t.Errorf("Want %d hits, got %d", want, got)
+		}
+	}
+}
+
+func TestPattern(t *testing.T) {
+	tests := []struct {
+		text, want string
+	}{
+		{"FAIL", "%FAIL%"},
+		{"go test ./...", "%go test ./...%"},
+		{`"quoted"`, "%quoted%"},
+		{"a <b> c", "%a %b% c%"},
+		{"été", "%t%"},
+		{"100%", "%100!%%"},
+		{"a_b", "%a!_b%"},
+		{"wow!", "%wow!!%"},
+		{`50%"`, "%50!%%"},
+	}
+	for _, test := range tests {
+		if got := pattern(test.text); got != test.want {
+			t.Errorf("Want pattern %q for %q, got %q", test.want, test.text, got)
+		}
+	}
+}
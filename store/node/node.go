@@ -0,0 +1,224 @@
+// Copyright 2019 Drone.IO Inc. All rights reserved.
+// Use of this source code is governed by the Drone Non-Commercial License
+// that can be found in the LICENSE file.
+
+// +build !oss
+
+package node
+
+import (
+	"context"
+
+	"github.com/drone/drone/core"
+	"github.com/drone/drone/store/shared/db"
+)
+
+// New returns a new NodeStore.
+func New(db *db.DB) core.NodeStore {
+	return &nodeStore{db}
+}
+
+type nodeStore struct {
+	db *db.DB
+}
+
+func (s *nodeStore) List(ctx context.Context) ([]*core.Node, error) {
+	var out []*core.Node
+	err := s.db.View(func(queryer db.Queryer, binder db.Binder) error {
+		rows, err := queryer.Query(queryAll)
+		if err != nil {
+			return err
+		}
+		out, err = scanRows(rows)
+		return err
+	})
+	return out, err
+}
+
+func (s *nodeStore) Find(ctx context.Context, id int64) (*core.Node, error) {
+	out := &core.Node{ID: id}
+	err := s.db.View(func(queryer db.Queryer, binder db.Binder) error {
+		params := toParams(out)
+		query, args, err := binder.BindNamed(queryKey, params)
+		if err != nil {
+			return err
+		}
+		row := queryer.QueryRow(query, args...)
+		return scanRow(row, out)
+	})
+	return out, err
+}
+
+func (s *nodeStore) FindName(ctx context.Context, name string) (*core.Node, error) {
+	out := &core.Node{Name: name}
+	err := s.db.View(func(queryer db.Queryer, binder db.Binder) error {
+		params := toParams(out)
+		query, args, err := binder.BindNamed(queryName, params)
+		if err != nil {
+			return err
+		}
+		row := queryer.QueryRow(query, args...)
+		return scanRow(row, out)
+	})
+	return out, err
+}
+
+func (s *nodeStore) Create(ctx context.Context, node *core.Node) error {
+	if s.db.Driver() == db.Postgres {
+		return s.createPostgres(ctx, node)
+	}
+	return s.create(ctx, node)
+}
+
+func (s *nodeStore) create(ctx context.Context, node *core.Node) error {
+	return s.db.Lock(func(execer db.Execer, binder db.Binder) error {
+		params := toParams(node)
+		stmt, args, err := binder.BindNamed(stmtInsert, params)
+		if err != nil {
+			return err
+		}
+		res, err := execer.Exec(stmt, args...)
+		if err != nil {
+			return err
+		}
+		node.ID, err = res.LastInsertId()
+		return err
+	})
+}
+
+func (s *nodeStore) createPostgres(ctx context.Context, node *core.Node) error {
+	return s.db.Lock(func(execer db.Execer, binder db.Binder) error {
+		params := toParams(node)
+		stmt, args, err := binder.BindNamed(stmtInsertPg, params)
+		if err != nil {
+			return err
+		}
+		return execer.QueryRow(stmt, args...).Scan(&node.ID)
+	})
+}
+
+func (s *nodeStore) Update(ctx context.Context, node *core.Node) error {
+	return s.db.Lock(func(execer db.Execer, binder db.Binder) error {
+		params := toParams(node)
+		stmt, args, err := binder.BindNamed(stmtUpdate, params)
+		if err != nil {
+			return err
+		}
+		_, err = execer.Exec(stmt, args...)
+		return err
+	})
+}
+
+func (s *nodeStore) Delete(ctx context.Context, node *core.Node) error {
+	return s.db.Lock(func(execer db.Execer, binder db.Binder) error {
+		params := toParams(node)
+		stmt, args, err := binder.BindNamed(stmtDelete, params)
+		if err != nil {
+			return err
+		}
+		_, err = execer.Exec(stmt, args...)
+		return err
+	})
+}
+
+const queryBase = `
+SELECT
+ node_id
+,node_name
+,node_state
+,node_kind
+,node_type
+,node_os
+,node_arch
+,node_variant
+,node_kernel
+,node_capacity
+,node_labels
+,node_taints
+,node_paused
+,node_pulled
+,node_created
+,node_updated
+`
+
+const queryKey = queryBase + `
+FROM nodes
+WHERE node_id = :node_id
+LIMIT 1
+`
+
+const queryName = queryBase + `
+FROM nodes
+WHERE node_name = :node_name
+LIMIT 1
+`
+
+const queryAll = queryBase + `
+FROM nodes
+ORDER BY node_name
+`
+
+const stmtUpdate = `
+UPDATE nodes SET
+ node_name = :node_name
+,node_state = :node_state
+,node_kind = :node_kind
+,node_type = :node_type
+,node_os = :node_os
+,node_arch = :node_arch
+,node_variant = :node_variant
+,node_kernel = :node_kernel
+,node_capacity = :node_capacity
+,node_labels = :node_labels
+,node_taints = :node_taints
+,node_paused = :node_paused
+,node_pulled = :node_pulled
+,node_created = :node_created
+,node_updated = :node_updated
+WHERE node_id = :node_id
+`
+
+const stmtDelete = `
+DELETE FROM nodes
+WHERE node_id = :node_id
+`
+
+const stmtInsert = `
+INSERT INTO nodes (
+ node_name
+,node_state
+,node_kind
+,node_type
+,node_os
+,node_arch
+,node_variant
+,node_kernel
+,node_capacity
+,node_labels
+,node_taints
+,node_paused
+,node_pulled
+,node_created
+,node_updated
+) VALUES (
+ :node_name
+,:node_state
+,:node_kind
+,:node_type
+,:node_os
+,:node_arch
+,:node_variant
+,:node_kernel
+,:node_capacity
+,:node_labels
+,:node_taints
+,:node_paused
+,:node_pulled
+,:node_created
+,:node_updated
+)
+`
+
+const stmtInsertPg = stmtInsert + `
+RETURNING node_id
+`
@@ -0,0 +1,168 @@
+// Copyright 2019 Drone.IO Inc. All rights reserved.
+// Use of this source code is governed by the Drone Non-Commercial License
+// that can be found in the LICENSE file.
+
+// +build !oss
+
+package node
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/drone/drone/core"
+	"github.com/drone/drone/store/shared/db/dbtest"
+)
+
+var noContext = context.TODO()
+
+func TestNode(t *testing.T) {
+	conn, err := dbtest.Connect()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	defer func() {
+		dbtest.Reset(conn)
+		dbtest.Disconnect(conn)
+	}()
+
+	store := New(conn).(*nodeStore)
+	t.Run("Create", testNodeCreate(store))
+}
+
+func testNodeCreate(store *nodeStore) func(t *testing.T) {
+	return func(t *testing.T) {
+		item := &core.Node{
+			Name:     "runner-1",
+			State:    core.NodeStateActive,
+			Kind:     "pipeline",
+			Type:     "docker",
+			OS:       "linux",
+			Arch:     "amd64",
+			Capacity: 2,
+			Labels:   map[string]string{"region": "us-east"},
+			Taints:   map[string]string{"gpu": "true"},
+			Seen:     1522878684,
+		}
+		err := store.Create(noContext, item)
+		if err != nil {
+			t.Error(err)
+		}
+		if item.ID == 0 {
+			t.Errorf("Want node ID assigned, got %d", item.ID)
+		}
+
+		t.Run("Find", testNodeFind(store, item))
+		t.Run("FindName", testNodeFindName(store, item))
+		t.Run("List", testNodeList(store))
+		t.Run("Update", testNodeUpdate(store, item))
+		t.Run("Delete", testNodeDelete(store, item))
+	}
+}
+
+func testNodeFind(store *nodeStore, node *core.Node) func(t *testing.T) {
+	return func(t *testing.T) {
+		item, err := store.Find(noContext, node.ID)
+		if err != nil {
+			t.Error(err)
+		} else {
+			t.Run("Fields", testNode(item))
+		}
+	}
+}
+
+func testNodeFindName(store *nodeStore, node *core.Node) func(t *testing.T) {
+	return func(t *testing.T) {
+		item, err := store.FindName(noContext, node.Name)
+		if err != nil {
+			t.Error(err)
+		} else {
+			t.Run("Fields", testNode(item))
+		}
+	}
+}
+
+func testNodeList(store *nodeStore) func(t *testing.T) {
+	return func(t *testing.T) {
+		list, err := store.List(noContext)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if got, want := len(list), 1; got != want {
+			t.Errorf("Want count %d, got %d", want, got)
+		} else {
+			t.Run("Fields", testNode(list[0]))
+		}
+	}
+}
+
+func testNodeUpdate(store *nodeStore, node *core.Node) func(t *testing.T) {
+	return func(t *testing.T) {
+		before, err := store.Find(noContext, node.ID)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		before.Cordoned = true
+		before.State = core.NodeStateDraining
+		err = store.Update(noContext, before)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		after, err := store.Find(noContext, node.ID)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if got, want := after.Cordoned, true; got != want {
+			t.Errorf("Want updated Cordoned %v, got %v", want, got)
+		}
+		if got, want := after.State, core.NodeStateDraining; got != want {
+			t.Errorf("Want updated State %q, got %q", want, got)
+		}
+	}
+}
+
+func testNodeDelete(store *nodeStore, node *core.Node) func(t *testing.T) {
+	return func(t *testing.T) {
+		err := store.Delete(noContext, node)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		_, err = store.Find(noContext, node.ID)
+		if got, want := sql.ErrNoRows, err; got != want {
+			t.Errorf("Want sql.ErrNoRows, got %v", got)
+		}
+	}
+}
+
+func testNode(item *core.Node) func(t *testing.T) {
+	return func(t *testing.T) {
+		if got, want := item.Name, "runner-1"; got != want {
+			t.Errorf("Want Name %q, got %q", want, got)
+		}
+		if got, want := item.OS, "linux"; got != want {
+			t.Errorf("Want OS %q, got %q", want, got)
+		}
+		if got, want := item.Arch, "amd64"; got != want {
+			t.Errorf("Want Arch %q, got %q", want, got)
+		}
+		if got, want := item.Capacity, 2; got != want {
+			t.Errorf("Want Capacity %d, got %d", want, got)
+		}
+		if got, want := item.Labels["region"], "us-east"; got != want {
+			t.Errorf("Want Labels region %q, got %q", want, got)
+		}
+		if got, want := item.Taints["gpu"], "true"; got != want {
+			t.Errorf("Want Taints gpu %q, got %q", want, got)
+		}
+		if got, want := item.Seen, int64(1522878684); got != want {
+			t.Errorf("Want Seen %d, got %d", want, got)
+		}
+	}
+}
@@ -0,0 +1,90 @@
+// Copyright 2019 Drone.IO Inc. All rights reserved.
+// Use of this source code is governed by the Drone Non-Commercial License
+// that can be found in the LICENSE file.
+
+// +build !oss
+
+package node
+
+import (
+	"database/sql"
+	"encoding/json"
+
+	"github.com/drone/drone/core"
+	"github.com/drone/drone/store/shared/db"
+
+	"github.com/jmoiron/sqlx/types"
+)
+
+// helper function converts the Node structure to a set
+// of named query parameters.
+func toParams(node *core.Node) map[string]interface{} {
+	return map[string]interface{}{
+		"node_id":       node.ID,
+		"node_name":     node.Name,
+		"node_state":    node.State,
+		"node_kind":     node.Kind,
+		"node_type":     node.Type,
+		"node_os":       node.OS,
+		"node_arch":     node.Arch,
+		"node_variant":  node.Variant,
+		"node_kernel":   node.Kernel,
+		"node_capacity": node.Capacity,
+		"node_labels":   encodeLabels(node.Labels),
+		"node_taints":   encodeLabels(node.Taints),
+		"node_paused":   node.Cordoned,
+		"node_pulled":   node.Seen,
+		"node_created":  node.Created,
+		"node_updated":  node.Updated,
+	}
+}
+
+func encodeLabels(v map[string]string) types.JSONText {
+	raw, _ := json.Marshal(v)
+	return types.JSONText(raw)
+}
+
+// helper function scans the sql.Row and copies the column
+// values to the destination object.
+func scanRow(scanner db.Scanner, dst *core.Node) error {
+	labels := types.JSONText{}
+	taints := types.JSONText{}
+	err := scanner.Scan(
+		&dst.ID,
+		&dst.Name,
+		&dst.State,
+		&dst.Kind,
+		&dst.Type,
+		&dst.OS,
+		&dst.Arch,
+		&dst.Variant,
+		&dst.Kernel,
+		&dst.Capacity,
+		&labels,
+		&taints,
+		&dst.Cordoned,
+		&dst.Seen,
+		&dst.Created,
+		&dst.Updated,
+	)
+	json.Unmarshal(labels, &dst.Labels)
+	json.Unmarshal(taints, &dst.Taints)
+	return err
+}
+
+// helper function scans the sql.Row and copies the column
+// values to the destination object.
+func scanRows(rows *sql.Rows) ([]*core.Node, error) {
+	defer rows.Close()
+
+	nodes := []*core.Node{}
+	for rows.Next() {
+		node := new(core.Node)
+		err := scanRow(rows, node)
+		if err != nil {
+			return nil, err
+		}
+		nodes = append(nodes, node)
+	}
+	return nodes, nil
+}
@@ -304,6 +304,10 @@ SELECT
 ,repo_cancel_pulls
 ,repo_cancel_push
 ,repo_cancel_running
+,repo_cancel_policy
+,repo_approval_rules
+,repo_artifact_quota
+,repo_requeue_lost
 ,repo_synced
 ,repo_created
 ,repo_updated
@@ -342,6 +346,7 @@ const queryColsBuilds = queryCols + `
 ,build_deploy
 ,build_deploy_id
 ,build_debug
+,build_superseded
 ,build_started
 ,build_finished
 ,build_created
@@ -401,6 +406,10 @@ INSERT INTO repos (
 ,repo_cancel_pulls
 ,repo_cancel_push
 ,repo_cancel_running
+,repo_cancel_policy
+,repo_approval_rules
+,repo_artifact_quota
+,repo_requeue_lost
 ,repo_synced
 ,repo_created
 ,repo_updated
@@ -432,6 +441,10 @@ INSERT INTO repos (
 ,:repo_cancel_pulls
 ,:repo_cancel_push
 ,:repo_cancel_running
+,:repo_cancel_policy
+,:repo_approval_rules
+,:repo_artifact_quota
+,:repo_requeue_lost
 ,:repo_synced
 ,:repo_created
 ,:repo_updated
@@ -480,6 +493,10 @@ UPDATE repos SET
 ,repo_cancel_pulls = :repo_cancel_pulls
 ,repo_cancel_push = :repo_cancel_push
 ,repo_cancel_running = :repo_cancel_running
+,repo_cancel_policy = :repo_cancel_policy
+,repo_approval_rules = :repo_approval_rules
+,repo_artifact_quota = :repo_artifact_quota
+,repo_requeue_lost = :repo_requeue_lost
 ,repo_timeout = :repo_timeout
 ,repo_throttle = :repo_throttle
 ,repo_counter = :repo_counter
@@ -225,6 +225,14 @@ func testRepoUpdate(repos *repoStore) func(t *testing.T) {
 
 		version := before.Version
 		before.Private = true
+		before.CancelPolicy = &core.CancelPolicy{
+			Branches: []string{"feature/*"},
+			Exclude:  []string{"refs/tags/*"},
+		}
+		before.ApprovalRules = []*core.ApprovalRule{
+			{Target: "production", Required: 2, Orgs: []string{"octocat"}},
+		}
+		before.ArtifactQuota = 1048576
 		err = repos.Update(noContext, before)
 		if err != nil {
 			t.Error(err)
@@ -241,6 +249,17 @@ func testRepoUpdate(repos *repoStore) func(t *testing.T) {
 		if got, want := before.Private, after.Private; got != want {
 			t.Errorf("Want updated Repo private %v, got %v", want, got)
 		}
+		if diff := cmp.Diff(before.CancelPolicy, after.CancelPolicy); diff != "" {
+			t.Errorf("Want updated Repo auto-cancel policy")
+			t.Log(diff)
+		}
+		if diff := cmp.Diff(before.ApprovalRules, after.ApprovalRules); diff != "" {
+			t.Errorf("Want updated Repo approval rules")
+			t.Log(diff)
+		}
+		if got, want := after.ArtifactQuota, before.ArtifactQuota; got != want {
+			t.Errorf("Want updated Repo artifact quota %d, got %d", want, got)
+		}
 	}
 }
 
@@ -16,6 +16,7 @@ package repos
 
 import (
 	"database/sql"
+	"encoding/json"
 
 	"github.com/drone/drone/core"
 	"github.com/drone/drone/store/shared/db"
@@ -47,6 +48,10 @@ func ToParams(v *core.Repository) map[string]interface{} {
 		"repo_cancel_pulls":   v.CancelPulls,
 		"repo_cancel_push":    v.CancelPush,
 		"repo_cancel_running": v.CancelRunning,
+		"repo_cancel_policy":  encodeCancelPolicy(v.CancelPolicy),
+		"repo_approval_rules": encodeApprovalRules(v.ApprovalRules),
+		"repo_artifact_quota": v.ArtifactQuota,
+		"repo_requeue_lost":   v.RequeueLost,
 		"repo_timeout":        v.Timeout,
 		"repo_throttle":       v.Throttle,
 		"repo_counter":        v.Counter,
@@ -62,7 +67,9 @@ func ToParams(v *core.Repository) map[string]interface{} {
 // helper function scans the sql.Row and copies the column
 // values to the destination object.
 func scanRow(scanner db.Scanner, dest *core.Repository) error {
-	return scanner.Scan(
+	policy := sql.NullString{}
+	rules := sql.NullString{}
+	err := scanner.Scan(
 		&dest.ID,
 		&dest.UID,
 		&dest.UserID,
@@ -88,6 +95,10 @@ func scanRow(scanner db.Scanner, dest *core.Repository) error {
 		&dest.CancelPulls,
 		&dest.CancelPush,
 		&dest.CancelRunning,
+		&policy,
+		&rules,
+		&dest.ArtifactQuota,
+		&dest.RequeueLost,
 		&dest.Synced,
 		&dest.Created,
 		&dest.Updated,
@@ -95,6 +106,9 @@ func scanRow(scanner db.Scanner, dest *core.Repository) error {
 		&dest.Signer,
 		&dest.Secret,
 	)
+	dest.CancelPolicy = decodeCancelPolicy(policy)
+	dest.ApprovalRules = decodeApprovalRules(rules)
+	return err
 }
 
 // helper function scans the sql.Row and copies the column
@@ -118,6 +132,8 @@ func scanRows(rows *sql.Rows) ([]*core.Repository, error) {
 // values to the destination object.
 func scanRowBuild(scanner db.Scanner, dest *core.Repository) error {
 	build := new(nullBuild)
+	policy := sql.NullString{}
+	rules := sql.NullString{}
 	err := scanner.Scan(
 		&dest.ID,
 		&dest.UID,
@@ -144,6 +160,10 @@ func scanRowBuild(scanner db.Scanner, dest *core.Repository) error {
 		&dest.CancelPulls,
 		&dest.CancelPush,
 		&dest.CancelRunning,
+		&policy,
+		&rules,
+		&dest.ArtifactQuota,
+		&dest.RequeueLost,
 		&dest.Synced,
 		&dest.Created,
 		&dest.Updated,
@@ -180,12 +200,15 @@ func scanRowBuild(scanner db.Scanner, dest *core.Repository) error {
 		&build.Deploy,
 		&build.DeployID,
 		&build.Debug,
+		&build.Superseded,
 		&build.Started,
 		&build.Finished,
 		&build.Created,
 		&build.Updated,
 		&build.Version,
 	)
+	dest.CancelPolicy = decodeCancelPolicy(policy)
+	dest.ApprovalRules = decodeApprovalRules(rules)
 	if build.ID.Int64 != 0 {
 		dest.Build = build.value()
 	}
@@ -257,3 +280,47 @@ func repoBuildStageRowsBuild(rows *sql.Rows) ([]*core.RepoBuildStage, error) {
 	}
 	return slices, nil
 }
+
+// helper function encodes the auto-cancel policy to json.
+// A nil policy is stored as a null value.
+func encodeCancelPolicy(v *core.CancelPolicy) sql.NullString {
+	if v == nil {
+		return sql.NullString{}
+	}
+	raw, _ := json.Marshal(v)
+	return sql.NullString{String: string(raw), Valid: true}
+}
+
+// helper function decodes the auto-cancel policy from json.
+func decodeCancelPolicy(v sql.NullString) *core.CancelPolicy {
+	if v.Valid == false || v.String == "" {
+		return nil
+	}
+	policy := new(core.CancelPolicy)
+	if err := json.Unmarshal([]byte(v.String), policy); err != nil {
+		return nil
+	}
+	return policy
+}
+
+// helper function encodes the approval rules to json. Empty
+// rules are stored as a null value.
+func encodeApprovalRules(v []*core.ApprovalRule) sql.NullString {
+	if len(v) == 0 {
+		return sql.NullString{}
+	}
+	raw, _ := json.Marshal(v)
+	return sql.NullString{String: string(raw), Valid: true}
+}
+
+// helper function decodes the approval rules from json.
+func decodeApprovalRules(v sql.NullString) []*core.ApprovalRule {
+	if v.Valid == false || v.String == "" {
+		return nil
+	}
+	var rules []*core.ApprovalRule
+	if err := json.Unmarshal([]byte(v.String), &rules); err != nil {
+		return nil
+	}
+	return rules
+}
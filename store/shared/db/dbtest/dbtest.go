@@ -48,17 +48,31 @@ func Connect() (*db.DB, error) {
 func Reset(d *db.DB) {
 	d.Lock(func(tx db.Execer, _ db.Binder) error {
 		tx.Exec("DELETE FROM cron")
+		tx.Exec("DELETE FROM approvals")
+		tx.Exec("DELETE FROM environments")
+		tx.Exec("DELETE FROM artifacts")
+		tx.Exec("DELETE FROM artifact_data")
+		tx.Exec("DELETE FROM tests")
 		tx.Exec("DELETE FROM cards")
+		tx.Exec("DELETE FROM log_index")
 		tx.Exec("DELETE FROM logs")
 		tx.Exec("DELETE FROM steps")
 		tx.Exec("DELETE FROM stages")
 		tx.Exec("DELETE FROM latest")
 		tx.Exec("DELETE FROM builds")
 		tx.Exec("DELETE FROM perms")
+		tx.Exec("DELETE FROM tokens")
+		tx.Exec("DELETE FROM identities")
 		tx.Exec("DELETE FROM repos")
 		tx.Exec("DELETE FROM users")
 		tx.Exec("DELETE FROM templates")
 		tx.Exec("DELETE FROM orgsecrets")
+		tx.Exec("DELETE FROM nodes")
+		tx.Exec("DELETE FROM delivery_attempts")
+		tx.Exec("DELETE FROM deliveries")
+		tx.Exec("DELETE FROM journal")
+		tx.Exec("DELETE FROM subscriptions")
+		tx.Exec("DELETE FROM channels")
 		return nil
 	})
 }
@@ -0,0 +1,7 @@
+-- name: alter-table-stages-add-column-tolerates
+
+ALTER TABLE stages ADD COLUMN stage_tolerates TEXT;
+
+-- name: alter-table-nodes-add-column-taints
+
+ALTER TABLE nodes ADD COLUMN node_taints VARCHAR(2000);
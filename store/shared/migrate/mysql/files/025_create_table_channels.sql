@@ -0,0 +1,19 @@
+-- name: create-table-channels
+
+CREATE TABLE IF NOT EXISTS channels (
+ channel_id         INTEGER PRIMARY KEY AUTO_INCREMENT
+,channel_repo_id    INTEGER
+,channel_namespace  VARCHAR(250)
+,channel_name       VARCHAR(250)
+,channel_kind       VARCHAR(250)
+,channel_endpoint   BLOB
+,channel_template   MEDIUMTEXT
+,channel_events     VARCHAR(2000)
+,channel_created    INTEGER
+,channel_updated    INTEGER
+,UNIQUE(channel_namespace, channel_repo_id, channel_name)
+);
+
+-- name: create-index-channels-repo
+
+CREATE INDEX ix_channels_repo ON channels (channel_repo_id);
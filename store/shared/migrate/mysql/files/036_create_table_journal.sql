@@ -0,0 +1,27 @@
+-- name: create-table-journal
+
+CREATE TABLE IF NOT EXISTS journal (
+ journal_id        INTEGER PRIMARY KEY AUTO_INCREMENT
+,journal_repo_id   INTEGER
+,journal_delivery  VARCHAR(250)
+,journal_event     VARCHAR(50)
+,journal_action    VARCHAR(50)
+,journal_hook      MEDIUMTEXT
+,journal_status    VARCHAR(50)
+,journal_error     VARCHAR(500)
+,journal_build     INTEGER
+,journal_attempts  INTEGER
+,journal_next      INTEGER
+,journal_created   INTEGER
+,journal_updated   INTEGER
+,journal_version   INTEGER
+,UNIQUE(journal_delivery)
+);
+
+-- name: create-index-journal-status
+
+CREATE INDEX ix_journal_status ON journal (journal_status, journal_next);
+
+-- name: create-index-journal-repo
+
+CREATE INDEX ix_journal_repo ON journal (journal_repo_id);
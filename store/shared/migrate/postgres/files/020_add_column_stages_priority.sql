@@ -0,0 +1,3 @@
+-- name: alter-table-stages-add-column-priority
+
+ALTER TABLE stages ADD COLUMN stage_priority INTEGER NOT NULL DEFAULT 0;
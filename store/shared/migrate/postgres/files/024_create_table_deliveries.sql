@@ -0,0 +1,35 @@
+-- name: create-table-deliveries
+
+CREATE TABLE IF NOT EXISTS deliveries (
+ delivery_id        SERIAL PRIMARY KEY
+,delivery_event     VARCHAR(50)
+,delivery_action    VARCHAR(50)
+,delivery_endpoint  VARCHAR(2000)
+,delivery_payload   TEXT
+,delivery_status    VARCHAR(50)
+,delivery_attempts  INTEGER
+,delivery_next      INTEGER
+,delivery_created   INTEGER
+,delivery_updated   INTEGER
+,delivery_version   INTEGER
+);
+
+-- name: create-index-deliveries-status
+
+CREATE INDEX IF NOT EXISTS ix_deliveries_status ON deliveries (delivery_status, delivery_next);
+
+-- name: create-table-delivery-attempts
+
+CREATE TABLE IF NOT EXISTS delivery_attempts (
+ attempt_id           SERIAL PRIMARY KEY
+,attempt_delivery_id  INTEGER
+,attempt_code         INTEGER
+,attempt_latency      INTEGER
+,attempt_response     VARCHAR(2000)
+,attempt_error        VARCHAR(500)
+,attempt_created      INTEGER
+);
+
+-- name: create-index-delivery-attempts-delivery
+
+CREATE INDEX IF NOT EXISTS ix_delivery_attempts_delivery ON delivery_attempts (attempt_delivery_id);
@@ -0,0 +1,20 @@
+-- name: create-table-subscriptions
+
+CREATE TABLE IF NOT EXISTS subscriptions (
+ subscription_id        SERIAL PRIMARY KEY
+,subscription_repo_id   INTEGER
+,subscription_endpoint  VARCHAR(2000)
+,subscription_secret    BYTEA
+,subscription_events    VARCHAR(2000)
+,subscription_created   INTEGER
+,subscription_updated   INTEGER
+,FOREIGN KEY(subscription_repo_id) REFERENCES repos(repo_id) ON DELETE CASCADE
+);
+
+-- name: create-index-subscriptions-repo
+
+CREATE INDEX IF NOT EXISTS ix_subscriptions_repo ON subscriptions (subscription_repo_id);
+
+-- name: alter-table-deliveries-add-column-subscription-id
+
+ALTER TABLE deliveries ADD COLUMN delivery_subscription_id INTEGER NOT NULL DEFAULT 0;
@@ -0,0 +1,19 @@
+-- name: create-table-channels
+
+CREATE TABLE IF NOT EXISTS channels (
+ channel_id         SERIAL PRIMARY KEY
+,channel_repo_id    INTEGER
+,channel_namespace  VARCHAR(250)
+,channel_name       VARCHAR(250)
+,channel_kind       VARCHAR(250)
+,channel_endpoint   BYTEA
+,channel_template   TEXT
+,channel_events     VARCHAR(2000)
+,channel_created    INTEGER
+,channel_updated    INTEGER
+,UNIQUE(channel_namespace, channel_repo_id, channel_name)
+);
+
+-- name: create-index-channels-repo
+
+CREATE INDEX IF NOT EXISTS ix_channels_repo ON channels (channel_repo_id);
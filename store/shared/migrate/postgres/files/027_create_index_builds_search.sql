@@ -0,0 +1,23 @@
+-- name: create-index-builds-repo-status
+
+CREATE INDEX IF NOT EXISTS ix_build_repo_status ON builds (build_repo_id, build_status);
+
+-- name: create-index-builds-repo-event
+
+CREATE INDEX IF NOT EXISTS ix_build_repo_event ON builds (build_repo_id, build_event);
+
+-- name: create-index-builds-repo-target
+
+CREATE INDEX IF NOT EXISTS ix_build_repo_target ON builds (build_repo_id, build_target);
+
+-- name: create-index-builds-repo-deploy
+
+CREATE INDEX IF NOT EXISTS ix_build_repo_deploy ON builds (build_repo_id, build_deploy);
+
+-- name: create-index-builds-repo-created
+
+CREATE INDEX IF NOT EXISTS ix_build_repo_created ON builds (build_repo_id, build_created);
+
+-- name: create-index-builds-repo-after
+
+CREATE INDEX IF NOT EXISTS ix_build_repo_after ON builds (build_repo_id, build_after);
@@ -0,0 +1,6 @@
+-- name: create-table-log-index
+
+CREATE TABLE IF NOT EXISTS log_index (
+ log_index_id    INTEGER PRIMARY KEY
+,log_index_data  BYTEA
+);
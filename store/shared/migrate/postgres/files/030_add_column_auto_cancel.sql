@@ -0,0 +1,7 @@
+-- name: alter-table-repos-add-column-cancel-policy
+
+ALTER TABLE repos ADD COLUMN repo_cancel_policy TEXT;
+
+-- name: alter-table-builds-add-column-superseded
+
+ALTER TABLE builds ADD COLUMN build_superseded INTEGER NOT NULL DEFAULT 0;
@@ -0,0 +1,3 @@
+-- name: alter-table-steps-add-column-step-metrics
+
+ALTER TABLE steps ADD COLUMN step_metrics TEXT NOT NULL DEFAULT '';
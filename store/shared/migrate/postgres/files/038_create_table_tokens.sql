@@ -0,0 +1,16 @@
+-- name: create-table-tokens
+
+CREATE TABLE IF NOT EXISTS tokens (
+ token_id        SERIAL PRIMARY KEY
+,token_user_id   INTEGER
+,token_repo_id   INTEGER
+,token_name      VARCHAR(100)
+,token_scopes    VARCHAR(250)
+,token_hash      VARCHAR(64)
+,token_expires   INTEGER
+,token_last_used INTEGER
+,token_created   INTEGER
+,UNIQUE(token_hash)
+,UNIQUE(token_user_id, token_name)
+,FOREIGN KEY(token_user_id) REFERENCES users(user_id) ON DELETE CASCADE
+);
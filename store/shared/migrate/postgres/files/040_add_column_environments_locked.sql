@@ -0,0 +1,3 @@
+-- name: alter-table-environments-add-column-environment-locked
+
+ALTER TABLE environments ADD COLUMN environment_locked INTEGER NOT NULL DEFAULT 0;
@@ -200,6 +200,190 @@ var migrations = []struct {
 		name: "create-new-table-cards",
 		stmt: createNewTableCards,
 	},
+	{
+		name: "alter-table-stages-add-column-priority",
+		stmt: alterTableStagesAddColumnPriority,
+	},
+	{
+		name: "alter-table-stages-add-column-heartbeat",
+		stmt: alterTableStagesAddColumnHeartbeat,
+	},
+	{
+		name: "alter-table-repos-add-column-requeue-lost",
+		stmt: alterTableReposAddColumnRequeueLost,
+	},
+	{
+		name: "alter-table-nodes-add-column-kind",
+		stmt: alterTableNodesAddColumnKind,
+	},
+	{
+		name: "alter-table-nodes-add-column-type",
+		stmt: alterTableNodesAddColumnType,
+	},
+	{
+		name: "alter-table-stages-add-column-tolerates",
+		stmt: alterTableStagesAddColumnTolerates,
+	},
+	{
+		name: "alter-table-nodes-add-column-taints",
+		stmt: alterTableNodesAddColumnTaints,
+	},
+	{
+		name: "create-table-deliveries",
+		stmt: createTableDeliveries,
+	},
+	{
+		name: "create-index-deliveries-status",
+		stmt: createIndexDeliveriesStatus,
+	},
+	{
+		name: "create-table-delivery-attempts",
+		stmt: createTableDeliveryAttempts,
+	},
+	{
+		name: "create-index-delivery-attempts-delivery",
+		stmt: createIndexDeliveryAttemptsDelivery,
+	},
+	{
+		name: "create-table-subscriptions",
+		stmt: createTableSubscriptions,
+	},
+	{
+		name: "create-index-subscriptions-repo",
+		stmt: createIndexSubscriptionsRepo,
+	},
+	{
+		name: "alter-table-deliveries-add-column-subscription-id",
+		stmt: alterTableDeliveriesAddColumnSubscriptionId,
+	},
+	{
+		name: "create-table-channels",
+		stmt: createTableChannels,
+	},
+	{
+		name: "create-index-channels-repo",
+		stmt: createIndexChannelsRepo,
+	},
+	{
+		name: "create-index-builds-repo-status",
+		stmt: createIndexBuildsRepoStatus,
+	},
+	{
+		name: "create-index-builds-repo-event",
+		stmt: createIndexBuildsRepoEvent,
+	},
+	{
+		name: "create-index-builds-repo-target",
+		stmt: createIndexBuildsRepoTarget,
+	},
+	{
+		name: "create-index-builds-repo-deploy",
+		stmt: createIndexBuildsRepoDeploy,
+	},
+	{
+		name: "create-index-builds-repo-created",
+		stmt: createIndexBuildsRepoCreated,
+	},
+	{
+		name: "create-index-builds-repo-after",
+		stmt: createIndexBuildsRepoAfter,
+	},
+	{
+		name: "create-table-log-index",
+		stmt: createTableLogIndex,
+	},
+	{
+		name: "alter-table-builds-add-column-expired",
+		stmt: alterTableBuildsAddColumnExpired,
+	},
+	{
+		name: "alter-table-repos-add-column-cancel-policy",
+		stmt: alterTableReposAddColumnCancelPolicy,
+	},
+	{
+		name: "alter-table-builds-add-column-superseded",
+		stmt: alterTableBuildsAddColumnSuperseded,
+	},
+	{
+		name: "alter-table-stages-add-column-timeout",
+		stmt: alterTableStagesAddColumnTimeout,
+	},
+	{
+		name: "alter-table-repos-add-column-approval-rules",
+		stmt: alterTableReposAddColumnApprovalRules,
+	},
+	{
+		name: "create-table-approvals",
+		stmt: createTableApprovals,
+	},
+	{
+		name: "create-index-approvals-build",
+		stmt: createIndexApprovalsBuild,
+	},
+	{
+		name: "create-table-environments",
+		stmt: createTableEnvironments,
+	},
+	{
+		name: "create-index-environments-repo",
+		stmt: createIndexEnvironmentsRepo,
+	},
+	{
+		name: "alter-table-repos-add-column-artifact-quota",
+		stmt: alterTableReposAddColumnArtifactQuota,
+	},
+	{
+		name: "create-table-artifacts",
+		stmt: createTableArtifacts,
+	},
+	{
+		name: "create-index-artifacts-repo",
+		stmt: createIndexArtifactsRepo,
+	},
+	{
+		name: "create-table-artifact-data",
+		stmt: createTableArtifactData,
+	},
+	{
+		name: "create-table-tests",
+		stmt: createTableTests,
+	},
+	{
+		name: "create-index-tests-build",
+		stmt: createIndexTestsBuild,
+	},
+	{
+		name: "create-index-tests-repo",
+		stmt: createIndexTestsRepo,
+	},
+	{
+		name: "alter-table-steps-add-column-step-metrics",
+		stmt: alterTableStepsAddColumnStepMetrics,
+	},
+	{
+		name: "create-table-journal",
+		stmt: createTableJournal,
+	},
+	{
+		name: "create-index-journal-status",
+		stmt: createIndexJournalStatus,
+	},
+	{
+		name: "create-index-journal-repo",
+		stmt: createIndexJournalRepo,
+	},
+	{
+		name: "create-table-tokens",
+		stmt: createTableTokens,
+	},
+	{
+		name: "create-table-identities",
+		stmt: createTableIdentities,
+	},
+	{
+		name: "alter-table-environments-add-column-environment-locked",
+		stmt: alterTableEnvironmentsAddColumnEnvironmentLocked,
+	},
 }
 
 // Migrate performs the database migration. If the migration fails
@@ -771,3 +955,405 @@ CREATE TABLE IF NOT EXISTS cards
     FOREIGN KEY (card_id) REFERENCES steps (step_id) ON DELETE CASCADE
 );
 `
+
+//
+// 019_add_column_stages_priority.sql
+//
+
+var alterTableStagesAddColumnPriority = `
+ALTER TABLE stages ADD COLUMN stage_priority INTEGER NOT NULL DEFAULT 0;
+`
+
+//
+// 020_add_columns_stage_lease.sql
+//
+
+var alterTableStagesAddColumnHeartbeat = `
+ALTER TABLE stages ADD COLUMN stage_heartbeat INTEGER NOT NULL DEFAULT 0;
+`
+
+var alterTableReposAddColumnRequeueLost = `
+ALTER TABLE repos ADD COLUMN repo_requeue_lost BOOLEAN NOT NULL DEFAULT 0;
+`
+
+//
+// 021_add_columns_nodes_kind.sql
+//
+
+var alterTableNodesAddColumnKind = `
+ALTER TABLE nodes ADD COLUMN node_kind TEXT NOT NULL DEFAULT '';
+`
+
+var alterTableNodesAddColumnType = `
+ALTER TABLE nodes ADD COLUMN node_type TEXT NOT NULL DEFAULT '';
+`
+
+//
+// 022_add_columns_taints.sql
+//
+
+var alterTableStagesAddColumnTolerates = `
+ALTER TABLE stages ADD COLUMN stage_tolerates TEXT;
+`
+
+var alterTableNodesAddColumnTaints = `
+ALTER TABLE nodes ADD COLUMN node_taints TEXT;
+`
+
+//
+// 023_create_table_deliveries.sql
+//
+
+var createTableDeliveries = `
+CREATE TABLE IF NOT EXISTS deliveries (
+ delivery_id        INTEGER PRIMARY KEY AUTOINCREMENT
+,delivery_event     TEXT
+,delivery_action    TEXT
+,delivery_endpoint  TEXT
+,delivery_payload   TEXT
+,delivery_status    TEXT
+,delivery_attempts  INTEGER
+,delivery_next      INTEGER
+,delivery_created   INTEGER
+,delivery_updated   INTEGER
+,delivery_version   INTEGER
+);
+`
+
+var createIndexDeliveriesStatus = `
+CREATE INDEX IF NOT EXISTS ix_deliveries_status ON deliveries (delivery_status, delivery_next);
+`
+
+var createTableDeliveryAttempts = `
+CREATE TABLE IF NOT EXISTS delivery_attempts (
+ attempt_id           INTEGER PRIMARY KEY AUTOINCREMENT
+,attempt_delivery_id  INTEGER
+,attempt_code         INTEGER
+,attempt_latency      INTEGER
+,attempt_response     TEXT
+,attempt_error        TEXT
+,attempt_created      INTEGER
+);
+`
+
+var createIndexDeliveryAttemptsDelivery = `
+CREATE INDEX IF NOT EXISTS ix_delivery_attempts_delivery ON delivery_attempts (attempt_delivery_id);
+`
+
+//
+// 024_create_table_subscriptions.sql
+//
+
+var createTableSubscriptions = `
+CREATE TABLE IF NOT EXISTS subscriptions (
+ subscription_id        INTEGER PRIMARY KEY AUTOINCREMENT
+,subscription_repo_id   INTEGER
+,subscription_endpoint  TEXT
+,subscription_secret    BLOB
+,subscription_events    TEXT
+,subscription_created   INTEGER
+,subscription_updated   INTEGER
+,FOREIGN KEY(subscription_repo_id) REFERENCES repos(repo_id) ON DELETE CASCADE
+);
+`
+
+var createIndexSubscriptionsRepo = `
+CREATE INDEX IF NOT EXISTS ix_subscriptions_repo ON subscriptions (subscription_repo_id);
+`
+
+var alterTableDeliveriesAddColumnSubscriptionId = `
+ALTER TABLE deliveries ADD COLUMN delivery_subscription_id INTEGER NOT NULL DEFAULT 0;
+`
+
+//
+// 025_create_table_channels.sql
+//
+
+var createTableChannels = `
+CREATE TABLE IF NOT EXISTS channels (
+ channel_id         INTEGER PRIMARY KEY AUTOINCREMENT
+,channel_repo_id    INTEGER
+,channel_namespace  TEXT
+,channel_name       TEXT
+,channel_kind       TEXT
+,channel_endpoint   BLOB
+,channel_template   TEXT
+,channel_events     TEXT
+,channel_created    INTEGER
+,channel_updated    INTEGER
+,UNIQUE(channel_namespace, channel_repo_id, channel_name)
+);
+`
+
+var createIndexChannelsRepo = `
+CREATE INDEX IF NOT EXISTS ix_channels_repo ON channels (channel_repo_id);
+`
+
+//
+// 026_create_index_builds_search.sql
+//
+
+var createIndexBuildsRepoStatus = `
+CREATE INDEX IF NOT EXISTS ix_build_repo_status ON builds (build_repo_id, build_status);
+`
+
+var createIndexBuildsRepoEvent = `
+CREATE INDEX IF NOT EXISTS ix_build_repo_event ON builds (build_repo_id, build_event);
+`
+
+var createIndexBuildsRepoTarget = `
+CREATE INDEX IF NOT EXISTS ix_build_repo_target ON builds (build_repo_id, build_target);
+`
+
+var createIndexBuildsRepoDeploy = `
+CREATE INDEX IF NOT EXISTS ix_build_repo_deploy ON builds (build_repo_id, build_deploy);
+`
+
+var createIndexBuildsRepoCreated = `
+CREATE INDEX IF NOT EXISTS ix_build_repo_created ON builds (build_repo_id, build_created);
+`
+
+var createIndexBuildsRepoAfter = `
+CREATE INDEX IF NOT EXISTS ix_build_repo_after ON builds (build_repo_id, build_after);
+`
+
+//
+// 027_create_table_log_index.sql
+//
+
+var createTableLogIndex = `
+CREATE TABLE IF NOT EXISTS log_index (
+ log_index_id    INTEGER PRIMARY KEY
+,log_index_data  BLOB
+,FOREIGN KEY(log_index_id) REFERENCES steps(step_id) ON DELETE CASCADE
+);
+`
+
+//
+// 028_add_column_builds_expired.sql
+//
+
+var alterTableBuildsAddColumnExpired = `
+ALTER TABLE builds ADD COLUMN build_expired BOOLEAN NOT NULL DEFAULT 0;
+`
+
+//
+// 029_add_column_auto_cancel.sql
+//
+
+var alterTableReposAddColumnCancelPolicy = `
+ALTER TABLE repos ADD COLUMN repo_cancel_policy TEXT;
+`
+
+var alterTableBuildsAddColumnSuperseded = `
+ALTER TABLE builds ADD COLUMN build_superseded INTEGER NOT NULL DEFAULT 0;
+`
+
+//
+// 030_add_column_stage_timeout.sql
+//
+
+var alterTableStagesAddColumnTimeout = `
+ALTER TABLE stages ADD COLUMN stage_timeout INTEGER NOT NULL DEFAULT 0;
+`
+
+//
+// 031_create_table_approvals.sql
+//
+
+var alterTableReposAddColumnApprovalRules = `
+ALTER TABLE repos ADD COLUMN repo_approval_rules TEXT;
+`
+
+var createTableApprovals = `
+CREATE TABLE IF NOT EXISTS approvals (
+ approval_id        INTEGER PRIMARY KEY AUTOINCREMENT
+,approval_repo_id   INTEGER
+,approval_build_id  INTEGER
+,approval_stage_id  INTEGER
+,approval_user_id   INTEGER
+,approval_login     TEXT
+,approval_action    TEXT
+,approval_comment   TEXT
+,approval_created   INTEGER
+);
+`
+
+var createIndexApprovalsBuild = `
+CREATE INDEX IF NOT EXISTS ix_approvals_build ON approvals (approval_build_id);
+`
+
+//
+// 032_create_table_environments.sql
+//
+
+var createTableEnvironments = `
+CREATE TABLE IF NOT EXISTS environments (
+ environment_id          INTEGER PRIMARY KEY AUTOINCREMENT
+,environment_repo_id     INTEGER
+,environment_name        TEXT
+,environment_description TEXT
+,environment_branches    TEXT
+,environment_users       TEXT
+,environment_secrets     BLOB
+,environment_created     INTEGER
+,environment_updated     INTEGER
+,UNIQUE(environment_repo_id, environment_name)
+,FOREIGN KEY(environment_repo_id) REFERENCES repos(repo_id) ON DELETE CASCADE
+);
+`
+
+var createIndexEnvironmentsRepo = `
+CREATE INDEX IF NOT EXISTS ix_environments_repo ON environments (environment_repo_id);
+`
+
+//
+// 033_create_table_artifacts.sql
+//
+
+var alterTableReposAddColumnArtifactQuota = `
+ALTER TABLE repos ADD COLUMN repo_artifact_quota INTEGER NOT NULL DEFAULT 0;
+`
+
+var createTableArtifacts = `
+CREATE TABLE IF NOT EXISTS artifacts (
+ artifact_id       INTEGER PRIMARY KEY AUTOINCREMENT
+,artifact_repo_id  INTEGER
+,artifact_build_id INTEGER
+,artifact_stage_id INTEGER
+,artifact_step_id  INTEGER
+,artifact_name     TEXT
+,artifact_size     INTEGER
+,artifact_created  INTEGER
+,UNIQUE(artifact_build_id, artifact_name)
+);
+`
+
+var createIndexArtifactsRepo = `
+CREATE INDEX IF NOT EXISTS ix_artifacts_repo ON artifacts (artifact_repo_id);
+`
+
+var createTableArtifactData = `
+CREATE TABLE IF NOT EXISTS artifact_data (
+ artifact_id   INTEGER PRIMARY KEY
+,artifact_data BLOB
+);
+`
+
+//
+// 034_create_table_tests.sql
+//
+
+var createTableTests = `
+CREATE TABLE IF NOT EXISTS tests (
+ test_id       INTEGER PRIMARY KEY AUTOINCREMENT
+,test_repo_id  INTEGER
+,test_build_id INTEGER
+,test_stage_id INTEGER
+,test_step_id  INTEGER
+,test_suite    TEXT
+,test_class    TEXT
+,test_name     TEXT
+,test_status   TEXT
+,test_duration INTEGER
+,test_message  TEXT
+,test_created  INTEGER
+,FOREIGN KEY(test_step_id) REFERENCES steps(step_id) ON DELETE CASCADE
+);
+`
+
+var createIndexTestsBuild = `
+CREATE INDEX IF NOT EXISTS ix_tests_build ON tests (test_build_id);
+`
+
+var createIndexTestsRepo = `
+CREATE INDEX IF NOT EXISTS ix_tests_repo ON tests (test_repo_id);
+`
+
+//
+// 035_add_column_steps_metrics.sql
+//
+
+var alterTableStepsAddColumnStepMetrics = `
+ALTER TABLE steps ADD COLUMN step_metrics TEXT NOT NULL DEFAULT '';
+`
+
+//
+// 036_create_table_journal.sql
+//
+
+var createTableJournal = `
+CREATE TABLE IF NOT EXISTS journal (
+ journal_id        INTEGER PRIMARY KEY AUTOINCREMENT
+,journal_repo_id   INTEGER
+,journal_delivery  TEXT
+,journal_event     TEXT
+,journal_action    TEXT
+,journal_hook      TEXT
+,journal_status    TEXT
+,journal_error     TEXT
+,journal_build     INTEGER
+,journal_attempts  INTEGER
+,journal_next      INTEGER
+,journal_created   INTEGER
+,journal_updated   INTEGER
+,journal_version   INTEGER
+,UNIQUE(journal_delivery)
+);
+`
+
+var createIndexJournalStatus = `
+CREATE INDEX IF NOT EXISTS ix_journal_status ON journal (journal_status, journal_next);
+`
+
+var createIndexJournalRepo = `
+CREATE INDEX IF NOT EXISTS ix_journal_repo ON journal (journal_repo_id);
+`
+
+//
+// 037_create_table_tokens.sql
+//
+
+var createTableTokens = `
+CREATE TABLE IF NOT EXISTS tokens (
+ token_id        INTEGER PRIMARY KEY AUTOINCREMENT
+,token_user_id   INTEGER
+,token_repo_id   INTEGER
+,token_name      TEXT
+,token_scopes    TEXT
+,token_hash      TEXT
+,token_expires   INTEGER
+,token_last_used INTEGER
+,token_created   INTEGER
+,UNIQUE(token_hash)
+,UNIQUE(token_user_id, token_name)
+,FOREIGN KEY(token_user_id) REFERENCES users(user_id) ON DELETE CASCADE
+);
+`
+
+//
+// 038_create_table_identities.sql
+//
+
+var createTableIdentities = `
+CREATE TABLE IF NOT EXISTS identities (
+ identity_id      INTEGER PRIMARY KEY AUTOINCREMENT
+,identity_user_id INTEGER
+,identity_subject TEXT
+,identity_email   TEXT
+,identity_orgs    TEXT
+,identity_created INTEGER
+,identity_updated INTEGER
+,UNIQUE(identity_subject)
+,UNIQUE(identity_user_id)
+,FOREIGN KEY(identity_user_id) REFERENCES users(user_id) ON DELETE CASCADE
+);
+`
+
+//
+// 039_add_column_environments_locked.sql
+//
+
+var alterTableEnvironmentsAddColumnEnvironmentLocked = `
+ALTER TABLE environments ADD COLUMN environment_locked INTEGER NOT NULL DEFAULT 0;
+`
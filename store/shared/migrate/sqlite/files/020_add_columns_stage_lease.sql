@@ -0,0 +1,7 @@
+-- name: alter-table-stages-add-column-heartbeat
+
+ALTER TABLE stages ADD COLUMN stage_heartbeat INTEGER NOT NULL DEFAULT 0;
+
+-- name: alter-table-repos-add-column-requeue-lost
+
+ALTER TABLE repos ADD COLUMN repo_requeue_lost BOOLEAN NOT NULL DEFAULT 0;
@@ -0,0 +1,7 @@
+-- name: alter-table-nodes-add-column-kind
+
+ALTER TABLE nodes ADD COLUMN node_kind TEXT NOT NULL DEFAULT '';
+
+-- name: alter-table-nodes-add-column-type
+
+ALTER TABLE nodes ADD COLUMN node_type TEXT NOT NULL DEFAULT '';
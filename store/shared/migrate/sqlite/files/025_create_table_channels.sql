@@ -0,0 +1,19 @@
+-- name: create-table-channels
+
+CREATE TABLE IF NOT EXISTS channels (
+ channel_id         INTEGER PRIMARY KEY AUTOINCREMENT
+,channel_repo_id    INTEGER
+,channel_namespace  TEXT
+,channel_name       TEXT
+,channel_kind       TEXT
+,channel_endpoint   BLOB
+,channel_template   TEXT
+,channel_events     TEXT
+,channel_created    INTEGER
+,channel_updated    INTEGER
+,UNIQUE(channel_namespace, channel_repo_id, channel_name)
+);
+
+-- name: create-index-channels-repo
+
+CREATE INDEX IF NOT EXISTS ix_channels_repo ON channels (channel_repo_id);
@@ -0,0 +1,7 @@
+-- name: create-table-log-index
+
+CREATE TABLE IF NOT EXISTS log_index (
+ log_index_id    INTEGER PRIMARY KEY
+,log_index_data  BLOB
+,FOREIGN KEY(log_index_id) REFERENCES steps(step_id) ON DELETE CASCADE
+);
@@ -0,0 +1,3 @@
+-- name: alter-table-builds-add-column-expired
+
+ALTER TABLE builds ADD COLUMN build_expired BOOLEAN NOT NULL DEFAULT 0;
@@ -0,0 +1,21 @@
+-- name: alter-table-repos-add-column-approval-rules
+
+ALTER TABLE repos ADD COLUMN repo_approval_rules TEXT;
+
+-- name: create-table-approvals
+
+CREATE TABLE IF NOT EXISTS approvals (
+ approval_id        INTEGER PRIMARY KEY AUTOINCREMENT
+,approval_repo_id   INTEGER
+,approval_build_id  INTEGER
+,approval_stage_id  INTEGER
+,approval_user_id   INTEGER
+,approval_login     TEXT
+,approval_action    TEXT
+,approval_comment   TEXT
+,approval_created   INTEGER
+);
+
+-- name: create-index-approvals-build
+
+CREATE INDEX IF NOT EXISTS ix_approvals_build ON approvals (approval_build_id);
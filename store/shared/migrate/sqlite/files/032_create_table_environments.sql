@@ -0,0 +1,19 @@
+-- name: create-table-environments
+
+CREATE TABLE IF NOT EXISTS environments (
+ environment_id          INTEGER PRIMARY KEY AUTOINCREMENT
+,environment_repo_id     INTEGER
+,environment_name        TEXT
+,environment_description TEXT
+,environment_branches    TEXT
+,environment_users       TEXT
+,environment_secrets     BLOB
+,environment_created     INTEGER
+,environment_updated     INTEGER
+,UNIQUE(environment_repo_id, environment_name)
+,FOREIGN KEY(environment_repo_id) REFERENCES repos(repo_id) ON DELETE CASCADE
+);
+
+-- name: create-index-environments-repo
+
+CREATE INDEX IF NOT EXISTS ix_environments_repo ON environments (environment_repo_id);
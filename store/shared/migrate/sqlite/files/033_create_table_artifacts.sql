@@ -0,0 +1,28 @@
+-- name: alter-table-repos-add-column-artifact-quota
+
+ALTER TABLE repos ADD COLUMN repo_artifact_quota INTEGER NOT NULL DEFAULT 0;
+
+-- name: create-table-artifacts
+
+CREATE TABLE IF NOT EXISTS artifacts (
+ artifact_id       INTEGER PRIMARY KEY AUTOINCREMENT
+,artifact_repo_id  INTEGER
+,artifact_build_id INTEGER
+,artifact_stage_id INTEGER
+,artifact_step_id  INTEGER
+,artifact_name     TEXT
+,artifact_size     INTEGER
+,artifact_created  INTEGER
+,UNIQUE(artifact_build_id, artifact_name)
+);
+
+-- name: create-index-artifacts-repo
+
+CREATE INDEX IF NOT EXISTS ix_artifacts_repo ON artifacts (artifact_repo_id);
+
+-- name: create-table-artifact-data
+
+CREATE TABLE IF NOT EXISTS artifact_data (
+ artifact_id   INTEGER PRIMARY KEY
+,artifact_data BLOB
+);
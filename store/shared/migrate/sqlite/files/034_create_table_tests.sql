@@ -0,0 +1,25 @@
+-- name: create-table-tests
+
+CREATE TABLE IF NOT EXISTS tests (
+ test_id       INTEGER PRIMARY KEY AUTOINCREMENT
+,test_repo_id  INTEGER
+,test_build_id INTEGER
+,test_stage_id INTEGER
+,test_step_id  INTEGER
+,test_suite    TEXT
+,test_class    TEXT
+,test_name     TEXT
+,test_status   TEXT
+,test_duration INTEGER
+,test_message  TEXT
+,test_created  INTEGER
+,FOREIGN KEY(test_step_id) REFERENCES steps(step_id) ON DELETE CASCADE
+);
+
+-- name: create-index-tests-build
+
+CREATE INDEX IF NOT EXISTS ix_tests_build ON tests (test_build_id);
+
+-- name: create-index-tests-repo
+
+CREATE INDEX IF NOT EXISTS ix_tests_repo ON tests (test_repo_id);
@@ -0,0 +1,27 @@
+-- name: create-table-journal
+
+CREATE TABLE IF NOT EXISTS journal (
+ journal_id        INTEGER PRIMARY KEY AUTOINCREMENT
+,journal_repo_id   INTEGER
+,journal_delivery  TEXT
+,journal_event     TEXT
+,journal_action    TEXT
+,journal_hook      TEXT
+,journal_status    TEXT
+,journal_error     TEXT
+,journal_build     INTEGER
+,journal_attempts  INTEGER
+,journal_next      INTEGER
+,journal_created   INTEGER
+,journal_updated   INTEGER
+,journal_version   INTEGER
+,UNIQUE(journal_delivery)
+);
+
+-- name: create-index-journal-status
+
+CREATE INDEX IF NOT EXISTS ix_journal_status ON journal (journal_status, journal_next);
+
+-- name: create-index-journal-repo
+
+CREATE INDEX IF NOT EXISTS ix_journal_repo ON journal (journal_repo_id);
@@ -0,0 +1,16 @@
+-- name: create-table-tokens
+
+CREATE TABLE IF NOT EXISTS tokens (
+ token_id        INTEGER PRIMARY KEY AUTOINCREMENT
+,token_user_id   INTEGER
+,token_repo_id   INTEGER
+,token_name      TEXT
+,token_scopes    TEXT
+,token_hash      TEXT
+,token_expires   INTEGER
+,token_last_used INTEGER
+,token_created   INTEGER
+,UNIQUE(token_hash)
+,UNIQUE(token_user_id, token_name)
+,FOREIGN KEY(token_user_id) REFERENCES users(user_id) ON DELETE CASCADE
+);
@@ -0,0 +1,14 @@
+-- name: create-table-identities
+
+CREATE TABLE IF NOT EXISTS identities (
+ identity_id      INTEGER PRIMARY KEY AUTOINCREMENT
+,identity_user_id INTEGER
+,identity_subject TEXT
+,identity_email   TEXT
+,identity_orgs    TEXT
+,identity_created INTEGER
+,identity_updated INTEGER
+,UNIQUE(identity_subject)
+,UNIQUE(identity_user_id)
+,FOREIGN KEY(identity_user_id) REFERENCES users(user_id) ON DELETE CASCADE
+);
@@ -55,6 +55,10 @@ func toParams(stage *core.Stage) map[string]interface{} {
 		"stage_on_failure": stage.OnFailure,
 		"stage_depends_on": encodeSlice(stage.DependsOn),
 		"stage_labels":     encodeParams(stage.Labels),
+		"stage_tolerates":  encodeParams(stage.Tolerates),
+		"stage_priority":   stage.Priority,
+		"stage_heartbeat":  stage.Heartbeat,
+		"stage_timeout":    stage.Timeout,
 	}
 }
 
@@ -73,6 +77,7 @@ func encodeParams(v map[string]string) types.JSONText {
 func scanRow(scanner db.Scanner, dest *core.Stage) error {
 	depJSON := types.JSONText{}
 	labJSON := types.JSONText{}
+	tolJSON := types.JSONText{}
 	err := scanner.Scan(
 		&dest.ID,
 		&dest.RepoID,
@@ -101,9 +106,14 @@ func scanRow(scanner db.Scanner, dest *core.Stage) error {
 		&dest.OnFailure,
 		&depJSON,
 		&labJSON,
+		&tolJSON,
+		&dest.Priority,
+		&dest.Heartbeat,
+		&dest.Timeout,
 	)
 	json.Unmarshal(depJSON, &dest.DependsOn)
 	json.Unmarshal(labJSON, &dest.Labels)
+	json.Unmarshal(tolJSON, &dest.Tolerates)
 	return err
 }
 
@@ -112,6 +122,7 @@ func scanRow(scanner db.Scanner, dest *core.Stage) error {
 func scanRowStep(scanner db.Scanner, stage *core.Stage, step *nullStep) error {
 	depJSON := types.JSONText{}
 	labJSON := types.JSONText{}
+	tolJSON := types.JSONText{}
 	stepDepJSON := types.JSONText{}
 	err := scanner.Scan(
 		&stage.ID,
@@ -141,6 +152,10 @@ func scanRowStep(scanner db.Scanner, stage *core.Stage, step *nullStep) error {
 		&stage.OnFailure,
 		&depJSON,
 		&labJSON,
+		&tolJSON,
+		&stage.Priority,
+		&stage.Heartbeat,
+		&stage.Timeout,
 		&step.ID,
 		&step.StageID,
 		&step.Number,
@@ -156,9 +171,11 @@ func scanRowStep(scanner db.Scanner, stage *core.Stage, step *nullStep) error {
 		&step.Image,
 		&step.Detached,
 		&step.Schema,
+		&step.Metrics,
 	)
 	json.Unmarshal(depJSON, &stage.DependsOn)
 	json.Unmarshal(labJSON, &stage.Labels)
+	json.Unmarshal(tolJSON, &stage.Tolerates)
 	json.Unmarshal(stepDepJSON, &step.DependsOn)
 	return err
 }
@@ -215,6 +215,18 @@ func (s *stageStore) Update(ctx context.Context, stage *core.Stage) error {
 	return err
 }
 
+func (s *stageStore) Heartbeat(ctx context.Context, stage *core.Stage) error {
+	return s.db.Lock(func(execer db.Execer, binder db.Binder) error {
+		params := toParams(stage)
+		stmt, args, err := binder.BindNamed(stmtHeartbeat, params)
+		if err != nil {
+			return err
+		}
+		_, err = execer.Exec(stmt, args...)
+		return err
+	})
+}
+
 const queryBase = `
 SELECT
  stage_id
@@ -244,6 +256,10 @@ SELECT
 ,stage_on_failure
 ,stage_depends_on
 ,stage_labels
+,stage_tolerates
+,stage_priority
+,stage_heartbeat
+,stage_timeout
 FROM stages
 `
 
@@ -312,6 +328,10 @@ SELECT
 ,stage_on_failure
 ,stage_depends_on
 ,stage_labels
+,stage_tolerates
+,stage_priority
+,stage_heartbeat
+,stage_timeout
 ,step_id
 ,step_stage_id
 ,step_number
@@ -327,6 +347,7 @@ SELECT
 ,step_image
 ,step_detached
 ,step_schema
+,step_metrics
 FROM stages
   LEFT JOIN steps
 	ON stages.stage_id=steps.step_stage_id
@@ -357,10 +378,17 @@ SET
 ,stage_on_failure = :stage_on_failure
 ,stage_depends_on = :stage_depends_on
 ,stage_labels = :stage_labels
+,stage_tolerates = :stage_tolerates
 WHERE stage_id = :stage_id
   AND stage_version = :stage_version_old
 `
 
+const stmtHeartbeat = `
+UPDATE stages
+SET stage_heartbeat = :stage_heartbeat
+WHERE stage_id = :stage_id
+`
+
 const stmtInsert = `
 INSERT INTO stages (
  stage_repo_id
@@ -389,6 +417,9 @@ INSERT INTO stages (
 ,stage_on_failure
 ,stage_depends_on
 ,stage_labels
+,stage_tolerates
+,stage_priority
+,stage_timeout
 ) VALUES (
  :stage_repo_id
 ,:stage_build_id
@@ -416,6 +447,9 @@ INSERT INTO stages (
 ,:stage_on_failure
 ,:stage_depends_on
 ,:stage_labels
+,:stage_tolerates
+,:stage_priority
+,:stage_timeout
 )
 `
 
@@ -56,6 +56,7 @@ func testStageCreate(store *stageStore, build *core.Build) func(t *testing.T) {
 			ExitCode: 0,
 			Started:  1522878684,
 			Stopped:  0,
+			Timeout:  90,
 		}
 		err := store.Create(noContext, item)
 		if err != nil {
@@ -74,6 +75,7 @@ func testStageCreate(store *stageStore, build *core.Build) func(t *testing.T) {
 		t.Run("ListSteps", testStageListSteps(store, item))
 		t.Run("Update", testStageUpdate(store, item))
 		t.Run("Locking", testStageLocking(store, item))
+		t.Run("Heartbeat", testStageHeartbeat(store, item))
 	}
 }
 
@@ -194,6 +196,33 @@ func testStageLocking(store *stageStore, stage *core.Stage) func(t *testing.T) {
 	}
 }
 
+func testStageHeartbeat(store *stageStore, stage *core.Stage) func(t *testing.T) {
+	return func(t *testing.T) {
+		before, err := store.Find(noContext, stage.ID)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		before.Heartbeat = 1522878700
+		err = store.Heartbeat(noContext, before)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		after, err := store.Find(noContext, stage.ID)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if got, want := after.Heartbeat, before.Heartbeat; got != want {
+			t.Errorf("Want updated Heartbeat %d, got %d", want, got)
+		}
+		if got, want := after.Version, before.Version; got != want {
+			t.Errorf("Want unchanged version %d, got %d", want, got)
+		}
+	}
+}
+
 func testStageListStatus(store *stageStore, build *core.Build) func(t *testing.T) {
 	return func(t *testing.T) {
 		store.db.Update(func(execer db.Execer, binder db.Binder) error {
@@ -247,5 +276,8 @@ func testStage(item *core.Stage) func(t *testing.T) {
 		if got, want := item.RepoID, int64(42); got != want {
 			t.Errorf("Want RepoID %d, got %d", want, got)
 		}
+		if got, want := item.Timeout, int64(90); got != want {
+			t.Errorf("Want Timeout %d, got %d", want, got)
+		}
 	}
 }
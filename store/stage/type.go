@@ -38,12 +38,16 @@ type nullStep struct {
 	Image     sql.NullString
 	Detached  sql.NullBool
 	Schema    sql.NullString
+	Metrics   types.JSONText
 }
 
 func (s *nullStep) value() *core.Step {
 	var dependsOn []string
 	json.Unmarshal(s.DependsOn, &dependsOn)
 
+	var metrics *core.StepMetrics
+	json.Unmarshal(s.Metrics, &metrics)
+
 	step := &core.Step{
 		ID:        s.ID.Int64,
 		StageID:   s.StageID.Int64,
@@ -60,6 +64,7 @@ func (s *nullStep) value() *core.Step {
 		Image:     s.Image.String,
 		Detached:  s.Detached.Bool,
 		Schema:    s.Schema.String,
+		Metrics:   metrics,
 	}
 
 	return step
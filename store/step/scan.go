@@ -43,6 +43,7 @@ func toParams(from *core.Step) map[string]interface{} {
 		"step_image":      from.Image,
 		"step_detached":   from.Detached,
 		"step_schema":     from.Schema,
+		"step_metrics":    encodeMetrics(from.Metrics),
 	}
 }
 
@@ -51,10 +52,16 @@ func encodeSlice(v []string) types.JSONText {
 	return types.JSONText(raw)
 }
 
+func encodeMetrics(v *core.StepMetrics) types.JSONText {
+	raw, _ := json.Marshal(v)
+	return types.JSONText(raw)
+}
+
 // helper function scans the sql.Row and copies the column
 // values to the destination object.
 func scanRow(scanner db.Scanner, dest *core.Step) error {
 	depJSON := types.JSONText{}
+	metJSON := types.JSONText{}
 	err := scanner.Scan(
 		&dest.ID,
 		&dest.StageID,
@@ -71,8 +78,10 @@ func scanRow(scanner db.Scanner, dest *core.Step) error {
 		&dest.Image,
 		&dest.Detached,
 		&dest.Schema,
+		&metJSON,
 	)
 	json.Unmarshal(depJSON, &dest.DependsOn)
+	json.Unmarshal(metJSON, &dest.Metrics)
 	return err
 }
 
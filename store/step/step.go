@@ -120,6 +120,12 @@ func (s *stepStore) Update(ctx context.Context, step *core.Step) error {
 		params := toParams(step)
 		params["step_version_old"] = versionOld
 		params["step_version_new"] = versionNew
+		// the runner does not report metrics for every update,
+		// and existing metrics are not overwritten when the step
+		// has no metrics.
+		if step.Metrics == nil {
+			params["step_metrics"] = nil
+		}
 		stmt, args, err := binder.BindNamed(stmtUpdate, params)
 		if err != nil {
 			return err
@@ -143,6 +149,20 @@ func (s *stepStore) Update(ctx context.Context, step *core.Step) error {
 	return err
 }
 
+func (s *stepStore) DeleteStage(ctx context.Context, id int64) error {
+	return s.db.Lock(func(execer db.Execer, binder db.Binder) error {
+		params := map[string]interface{}{
+			"step_stage_id": id,
+		}
+		stmt, args, err := binder.BindNamed(stmtDeleteStage, params)
+		if err != nil {
+			return err
+		}
+		_, err = execer.Exec(stmt, args...)
+		return err
+	})
+}
+
 const queryBase = `
 SELECT
  step_id
@@ -160,6 +180,7 @@ SELECT
 ,step_image
 ,step_detached
 ,step_schema
+,step_metrics
 `
 
 const queryKey = queryBase + `
@@ -193,10 +214,16 @@ SET
 ,step_image = :step_image
 ,step_detached = :step_detached
 ,step_schema = :step_schema
+,step_metrics = COALESCE(:step_metrics, step_metrics)
 WHERE step_id = :step_id
   AND step_version = :step_version_old
 `
 
+const stmtDeleteStage = `
+DELETE FROM steps
+WHERE step_stage_id = :step_stage_id
+`
+
 const stmtInsert = `
 INSERT INTO steps (
  step_stage_id
@@ -213,6 +240,7 @@ INSERT INTO steps (
 ,step_image
 ,step_detached
 ,step_schema
+,step_metrics
 ) VALUES (
  :step_stage_id
 ,:step_number
@@ -228,6 +256,7 @@ INSERT INTO steps (
 ,:step_image
 ,:step_detached
 ,:step_schema
+,:step_metrics
 )
 `
 
@@ -15,6 +15,8 @@ import (
 	"github.com/drone/drone/store/repos"
 	"github.com/drone/drone/store/shared/db"
 	"github.com/drone/drone/store/shared/db/dbtest"
+
+	"github.com/google/go-cmp/cmp"
 )
 
 var noContext = context.TODO()
@@ -78,6 +80,7 @@ func testStepCreate(store *stepStore, stage *core.Stage) func(t *testing.T) {
 		t.Run("List", testStepList(store, stage))
 		t.Run("Update", testStepUpdate(store, item))
 		t.Run("Locking", testStepLocking(store, item))
+		t.Run("DeleteStage", testStepDeleteStage(store, stage))
 	}
 }
 
@@ -118,6 +121,24 @@ func testStepList(store *stepStore, stage *core.Stage) func(t *testing.T) {
 	}
 }
 
+func testStepDeleteStage(store *stepStore, stage *core.Stage) func(t *testing.T) {
+	return func(t *testing.T) {
+		err := store.DeleteStage(noContext, stage.ID)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		list, err := store.List(noContext, stage.ID)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if got, want := len(list), 0; got != want {
+			t.Errorf("Want count %d, got %d", want, got)
+		}
+	}
+}
+
 func testStepUpdate(store *stepStore, step *core.Step) func(t *testing.T) {
 	return func(t *testing.T) {
 		before := &core.Step{
@@ -130,6 +151,11 @@ func testStepUpdate(store *stepStore, step *core.Step) func(t *testing.T) {
 			Stopped:  1522878690,
 			Status:   core.StatusFailing,
 			Version:  step.Version,
+			Metrics: &core.StepMetrics{
+				MemoryPeak:  1048576,
+				CPUSeconds:  1.5,
+				PullSeconds: 3,
+			},
 		}
 		err := store.Update(noContext, before)
 		if err != nil {
@@ -156,6 +182,25 @@ func testStepUpdate(store *stepStore, step *core.Step) func(t *testing.T) {
 		if got, want := after.Stopped, before.Stopped; got != want {
 			t.Errorf("Want updated Stopped %v, got %v", want, got)
 		}
+		if diff := cmp.Diff(after.Metrics, before.Metrics); diff != "" {
+			t.Errorf(diff)
+		}
+
+		// updating the step without metrics, for example when
+		// the stage is torn down, keeps the existing metrics.
+		after.Metrics = nil
+		if err := store.Update(noContext, after); err != nil {
+			t.Error(err)
+			return
+		}
+		after, err = store.Find(noContext, before.ID)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if diff := cmp.Diff(after.Metrics, before.Metrics); diff != "" {
+			t.Errorf(diff)
+		}
 	}
 }
 
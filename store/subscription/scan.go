@@ -0,0 +1,84 @@
+// Copyright 2019 Drone.IO Inc. All rights reserved.
+// Use of this source code is governed by the Drone Non-Commercial License
+// that can be found in the LICENSE file.
+
+// +build !oss
+
+package subscription
+
+import (
+	"database/sql"
+	"encoding/json"
+
+	"github.com/drone/drone/core"
+	"github.com/drone/drone/store/shared/db"
+	"github.com/drone/drone/store/shared/encrypt"
+
+	"github.com/jmoiron/sqlx/types"
+)
+
+// helper function converts the Subscription structure to a set
+// of named query parameters.
+func toParams(encrypt encrypt.Encrypter, subscription *core.Subscription) (map[string]interface{}, error) {
+	ciphertext, err := encrypt.Encrypt(subscription.Secret)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{
+		"subscription_id":       subscription.ID,
+		"subscription_repo_id":  subscription.RepoID,
+		"subscription_endpoint": subscription.Endpoint,
+		"subscription_secret":   ciphertext,
+		"subscription_events":   encodeSlice(subscription.Events),
+		"subscription_created":  subscription.Created,
+		"subscription_updated":  subscription.Updated,
+	}, nil
+}
+
+func encodeSlice(v []string) types.JSONText {
+	raw, _ := json.Marshal(v)
+	return types.JSONText(raw)
+}
+
+// helper function scans the sql.Row and copies the column
+// values to the destination object.
+func scanRow(encrypt encrypt.Encrypter, scanner db.Scanner, dst *core.Subscription) error {
+	var ciphertext []byte
+	events := types.JSONText{}
+	err := scanner.Scan(
+		&dst.ID,
+		&dst.RepoID,
+		&dst.Endpoint,
+		&ciphertext,
+		&events,
+		&dst.Created,
+		&dst.Updated,
+	)
+	if err != nil {
+		return err
+	}
+	json.Unmarshal(events, &dst.Events)
+	plaintext, err := encrypt.Decrypt(ciphertext)
+	if err != nil {
+		return err
+	}
+	dst.Secret = plaintext
+	return nil
+}
+
+// helper function scans the sql.Row and copies the column
+// values to the destination object.
+func scanRows(encrypt encrypt.Encrypter, rows *sql.Rows) ([]*core.Subscription, error) {
+	defer rows.Close()
+
+	subscriptions := []*core.Subscription{}
+	for rows.Next() {
+		subscription := new(core.Subscription)
+		err := scanRow(encrypt, rows, subscription)
+		if err != nil {
+			return nil, err
+		}
+		subscriptions = append(subscriptions, subscription)
+	}
+	return subscriptions, nil
+}
@@ -0,0 +1,192 @@
+// Copyright 2019 Drone.IO Inc. All rights reserved.
+// Use of this source code is governed by the Drone Non-Commercial License
+// that can be found in the LICENSE file.
+
+// +build !oss
+
+package subscription
+
+import (
+	"context"
+
+	"github.com/drone/drone/core"
+	"github.com/drone/drone/store/shared/db"
+	"github.com/drone/drone/store/shared/encrypt"
+)
+
+// New returns a new webhook Subscription database store.
+func New(db *db.DB, enc encrypt.Encrypter) core.SubscriptionStore {
+	return &subscriptionStore{
+		db:  db,
+		enc: enc,
+	}
+}
+
+type subscriptionStore struct {
+	db  *db.DB
+	enc encrypt.Encrypter
+}
+
+func (s *subscriptionStore) List(ctx context.Context, id int64) ([]*core.Subscription, error) {
+	var out []*core.Subscription
+	err := s.db.View(func(queryer db.Queryer, binder db.Binder) error {
+		params := map[string]interface{}{"subscription_repo_id": id}
+		stmt, args, err := binder.BindNamed(queryRepo, params)
+		if err != nil {
+			return err
+		}
+		rows, err := queryer.Query(stmt, args...)
+		if err != nil {
+			return err
+		}
+		out, err = scanRows(s.enc, rows)
+		return err
+	})
+	return out, err
+}
+
+func (s *subscriptionStore) Find(ctx context.Context, id int64) (*core.Subscription, error) {
+	out := &core.Subscription{ID: id}
+	err := s.db.View(func(queryer db.Queryer, binder db.Binder) error {
+		params, err := toParams(s.enc, out)
+		if err != nil {
+			return err
+		}
+		query, args, err := binder.BindNamed(queryKey, params)
+		if err != nil {
+			return err
+		}
+		row := queryer.QueryRow(query, args...)
+		return scanRow(s.enc, row, out)
+	})
+	return out, err
+}
+
+func (s *subscriptionStore) Create(ctx context.Context, subscription *core.Subscription) error {
+	if s.db.Driver() == db.Postgres {
+		return s.createPostgres(ctx, subscription)
+	}
+	return s.create(ctx, subscription)
+}
+
+func (s *subscriptionStore) create(ctx context.Context, subscription *core.Subscription) error {
+	return s.db.Lock(func(execer db.Execer, binder db.Binder) error {
+		params, err := toParams(s.enc, subscription)
+		if err != nil {
+			return err
+		}
+		stmt, args, err := binder.BindNamed(stmtInsert, params)
+		if err != nil {
+			return err
+		}
+		res, err := execer.Exec(stmt, args...)
+		if err != nil {
+			return err
+		}
+		subscription.ID, err = res.LastInsertId()
+		return err
+	})
+}
+
+func (s *subscriptionStore) createPostgres(ctx context.Context, subscription *core.Subscription) error {
+	return s.db.Lock(func(execer db.Execer, binder db.Binder) error {
+		params, err := toParams(s.enc, subscription)
+		if err != nil {
+			return err
+		}
+		stmt, args, err := binder.BindNamed(stmtInsertPg, params)
+		if err != nil {
+			return err
+		}
+		return execer.QueryRow(stmt, args...).Scan(&subscription.ID)
+	})
+}
+
+func (s *subscriptionStore) Update(ctx context.Context, subscription *core.Subscription) error {
+	return s.db.Lock(func(execer db.Execer, binder db.Binder) error {
+		params, err := toParams(s.enc, subscription)
+		if err != nil {
+			return err
+		}
+		stmt, args, err := binder.BindNamed(stmtUpdate, params)
+		if err != nil {
+			return err
+		}
+		_, err = execer.Exec(stmt, args...)
+		return err
+	})
+}
+
+func (s *subscriptionStore) Delete(ctx context.Context, subscription *core.Subscription) error {
+	return s.db.Lock(func(execer db.Execer, binder db.Binder) error {
+		params, err := toParams(s.enc, subscription)
+		if err != nil {
+			return err
+		}
+		stmt, args, err := binder.BindNamed(stmtDelete, params)
+		if err != nil {
+			return err
+		}
+		_, err = execer.Exec(stmt, args...)
+		return err
+	})
+}
+
+const queryBase = `
+SELECT
+ subscription_id
+,subscription_repo_id
+,subscription_endpoint
+,subscription_secret
+,subscription_events
+,subscription_created
+,subscription_updated
+`
+
+const queryKey = queryBase + `
+FROM subscriptions
+WHERE subscription_id = :subscription_id
+LIMIT 1
+`
+
+const queryRepo = queryBase + `
+FROM subscriptions
+WHERE subscription_repo_id = :subscription_repo_id
+ORDER BY subscription_id
+`
+
+const stmtUpdate = `
+UPDATE subscriptions SET
+ subscription_endpoint = :subscription_endpoint
+,subscription_secret = :subscription_secret
+,subscription_events = :subscription_events
+,subscription_updated = :subscription_updated
+WHERE subscription_id = :subscription_id
+`
+
+const stmtDelete = `
+DELETE FROM subscriptions
+WHERE subscription_id = :subscription_id
+`
+
+const stmtInsert = `
+INSERT INTO subscriptions (
+ subscription_repo_id
+,subscription_endpoint
+,subscription_secret
+,subscription_events
+,subscription_created
+,subscription_updated
+) VALUES (
+ :subscription_repo_id
+,:subscription_endpoint
+,:subscription_secret
+,:subscription_events
+,:subscription_created
+,:subscription_updated
+)
+`
+
+const stmtInsertPg = stmtInsert + `
+RETURNING subscription_id
+`
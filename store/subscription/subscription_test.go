@@ -0,0 +1,173 @@
+// Copyright 2019 Drone.IO Inc. All rights reserved.
+// Use of this source code is governed by the Drone Non-Commercial License
+// that can be found in the LICENSE file.
+
+// +build !oss
+
+package subscription
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/drone/drone/core"
+	"github.com/drone/drone/store/repos"
+	"github.com/drone/drone/store/shared/db/dbtest"
+	"github.com/drone/drone/store/shared/encrypt"
+)
+
+var noContext = context.TODO()
+
+func TestSubscription(t *testing.T) {
+	conn, err := dbtest.Connect()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	defer func() {
+		dbtest.Reset(conn)
+		dbtest.Disconnect(conn)
+	}()
+
+	// seeds the database with a dummy repository.
+	repo := &core.Repository{UID: "1", Slug: "octocat/hello-world"}
+	repos := repos.New(conn)
+	if err := repos.Create(noContext, repo); err != nil {
+		t.Error(err)
+	}
+
+	store := New(conn, nil).(*subscriptionStore)
+	store.enc, _ = encrypt.New("fb4b4d6267c8a5ce8231f8b186dbca92")
+	t.Run("Create", testSubscriptionCreate(store, repos, repo))
+}
+
+func testSubscriptionCreate(store *subscriptionStore, repos core.RepositoryStore, repo *core.Repository) func(t *testing.T) {
+	return func(t *testing.T) {
+		item := &core.Subscription{
+			RepoID:   repo.ID,
+			Endpoint: "https://example.com/hook",
+			Secret:   "correct-horse-battery-staple",
+			Events:   []string{"build:*"},
+		}
+		err := store.Create(noContext, item)
+		if err != nil {
+			t.Error(err)
+		}
+		if item.ID == 0 {
+			t.Errorf("Want subscription ID assigned, got %d", item.ID)
+		}
+
+		t.Run("Find", testSubscriptionFind(store, item))
+		t.Run("List", testSubscriptionList(store, repo))
+		t.Run("Update", testSubscriptionUpdate(store, item))
+		t.Run("Delete", testSubscriptionDelete(store, item))
+		t.Run("Fkey", testSubscriptionForeignKey(store, repos, repo))
+	}
+}
+
+func testSubscriptionFind(store *subscriptionStore, subscription *core.Subscription) func(t *testing.T) {
+	return func(t *testing.T) {
+		item, err := store.Find(noContext, subscription.ID)
+		if err != nil {
+			t.Error(err)
+		} else {
+			t.Run("Fields", testSubscription(item))
+		}
+	}
+}
+
+func testSubscriptionList(store *subscriptionStore, repo *core.Repository) func(t *testing.T) {
+	return func(t *testing.T) {
+		list, err := store.List(noContext, repo.ID)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if got, want := len(list), 1; got != want {
+			t.Errorf("Want count %d, got %d", want, got)
+		} else {
+			t.Run("Fields", testSubscription(list[0]))
+		}
+	}
+}
+
+func testSubscriptionUpdate(store *subscriptionStore, subscription *core.Subscription) func(t *testing.T) {
+	return func(t *testing.T) {
+		before, err := store.Find(noContext, subscription.ID)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		before.Events = []string{"build:*", "repo:*"}
+		err = store.Update(noContext, before)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		after, err := store.Find(noContext, before.ID)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if got, want := len(after.Events), 2; got != want {
+			t.Errorf("Want event count %d, got %d", want, got)
+		}
+	}
+}
+
+func testSubscriptionDelete(store *subscriptionStore, subscription *core.Subscription) func(t *testing.T) {
+	return func(t *testing.T) {
+		err := store.Delete(noContext, subscription)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		_, err = store.Find(noContext, subscription.ID)
+		if got, want := sql.ErrNoRows, err; got != want {
+			t.Errorf("Want sql.ErrNoRows, got %v", got)
+			return
+		}
+	}
+}
+
+func testSubscriptionForeignKey(store *subscriptionStore, repos core.RepositoryStore, repo *core.Repository) func(t *testing.T) {
+	return func(t *testing.T) {
+		item := &core.Subscription{
+			RepoID:   repo.ID,
+			Endpoint: "https://example.com/hook",
+			Secret:   "correct-horse-battery-staple",
+			Events:   []string{"build:*"},
+		}
+		store.Create(noContext, item)
+		before, _ := store.List(noContext, repo.ID)
+		if len(before) == 0 {
+			t.Errorf("Want non-empty subscription list")
+			return
+		}
+
+		err := repos.Delete(noContext, repo)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		after, _ := store.List(noContext, repo.ID)
+		if len(after) != 0 {
+			t.Errorf("Want empty subscription list")
+		}
+	}
+}
+
+func testSubscription(item *core.Subscription) func(t *testing.T) {
+	return func(t *testing.T) {
+		if got, want := item.Endpoint, "https://example.com/hook"; got != want {
+			t.Errorf("Want subscription endpoint %q, got %q", want, got)
+		}
+		if got, want := item.Secret, "correct-horse-battery-staple"; got != want {
+			t.Errorf("Want subscription secret %q, got %q", want, got)
+		}
+		if got, want := len(item.Events), 1; got != want {
+			t.Errorf("Want event count %d, got %d", want, got)
+		}
+	}
+}
@@ -0,0 +1,77 @@
+// Copyright 2019 Drone IO, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package testresult
+
+import (
+	"database/sql"
+
+	"github.com/drone/drone/core"
+	"github.com/drone/drone/store/shared/db"
+)
+
+// helper function converts the TestResult structure to a set
+// of named query parameters.
+func toParams(test *core.TestResult) map[string]interface{} {
+	return map[string]interface{}{
+		"test_id":       test.ID,
+		"test_repo_id":  test.RepoID,
+		"test_build_id": test.BuildID,
+		"test_stage_id": test.StageID,
+		"test_step_id":  test.StepID,
+		"test_suite":    test.Suite,
+		"test_class":    test.Class,
+		"test_name":     test.Name,
+		"test_status":   test.Status,
+		"test_duration": test.Duration,
+		"test_message":  test.Message,
+		"test_created":  test.Created,
+	}
+}
+
+// helper function scans the sql.Row and copies the column
+// values to the destination object.
+func scanRow(scanner db.Scanner, dest *core.TestResult) error {
+	return scanner.Scan(
+		&dest.ID,
+		&dest.RepoID,
+		&dest.BuildID,
+		&dest.StageID,
+		&dest.StepID,
+		&dest.Suite,
+		&dest.Class,
+		&dest.Name,
+		&dest.Status,
+		&dest.Duration,
+		&dest.Message,
+		&dest.Created,
+	)
+}
+
+// helper function scans the sql.Row and copies the column
+// values to the destination object.
+func scanRows(rows *sql.Rows) ([]*core.TestResult, error) {
+	defer rows.Close()
+
+	tests := []*core.TestResult{}
+	for rows.Next() {
+		test := new(core.TestResult)
+		err := scanRow(rows, test)
+		if err != nil {
+			return nil, err
+		}
+		tests = append(tests, test)
+	}
+	return tests, nil
+}
@@ -0,0 +1,229 @@
+// Copyright 2019 Drone IO, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package testresult
+
+import (
+	"context"
+
+	"github.com/drone/drone/core"
+	"github.com/drone/drone/store/shared/db"
+)
+
+// New returns a new TestStore.
+func New(db *db.DB) core.TestStore {
+	return &testStore{db}
+}
+
+type testStore struct {
+	db *db.DB
+}
+
+func (s *testStore) List(ctx context.Context, id int64) ([]*core.TestResult, error) {
+	var out []*core.TestResult
+	err := s.db.View(func(queryer db.Queryer, binder db.Binder) error {
+		params := map[string]interface{}{"test_build_id": id}
+		stmt, args, err := binder.BindNamed(queryBuild, params)
+		if err != nil {
+			return err
+		}
+		rows, err := queryer.Query(stmt, args...)
+		if err != nil {
+			return err
+		}
+		out, err = scanRows(rows)
+		return err
+	})
+	return out, err
+}
+
+func (s *testStore) Summary(ctx context.Context, id int64) (*core.TestSummary, error) {
+	out := new(core.TestSummary)
+	err := s.db.View(func(queryer db.Queryer, binder db.Binder) error {
+		params := map[string]interface{}{"test_build_id": id}
+		stmt, args, err := binder.BindNamed(querySummary, params)
+		if err != nil {
+			return err
+		}
+		rows, err := queryer.Query(stmt, args...)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+		for rows.Next() {
+			var status string
+			var count int
+			if err := rows.Scan(&status, &count); err != nil {
+				return err
+			}
+			out.Add(status, count)
+		}
+		return rows.Err()
+	})
+	return out, err
+}
+
+func (s *testStore) History(ctx context.Context, repo int64, branch string, n int) ([]*core.TestResult, error) {
+	var out []*core.TestResult
+	err := s.db.View(func(queryer db.Queryer, binder db.Binder) error {
+		params := map[string]interface{}{
+			"build_repo_id": repo,
+			"build_branch":  branch,
+			"limit":         n,
+		}
+		stmt, args, err := binder.BindNamed(queryHistory, params)
+		if err != nil {
+			return err
+		}
+		rows, err := queryer.Query(stmt, args...)
+		if err != nil {
+			return err
+		}
+		out, err = scanRows(rows)
+		return err
+	})
+	return out, err
+}
+
+func (s *testStore) Create(ctx context.Context, results []*core.TestResult) error {
+	return s.db.Lock(func(execer db.Execer, binder db.Binder) error {
+		for _, result := range results {
+			err := s.insert(execer, binder, result)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (s *testStore) DeleteStage(ctx context.Context, build, stage int64) error {
+	return s.db.Lock(func(execer db.Execer, binder db.Binder) error {
+		params := map[string]interface{}{
+			"test_build_id": build,
+			"test_stage_id": stage,
+		}
+		stmt, args, err := binder.BindNamed(stmtDeleteStage, params)
+		if err != nil {
+			return err
+		}
+		_, err = execer.Exec(stmt, args...)
+		return err
+	})
+}
+
+func (s *testStore) insert(execer db.Execer, binder db.Binder, result *core.TestResult) error {
+	params := toParams(result)
+	if s.db.Driver() == db.Postgres {
+		stmt, args, err := binder.BindNamed(stmtInsertPg, params)
+		if err != nil {
+			return err
+		}
+		return execer.QueryRow(stmt, args...).Scan(&result.ID)
+	}
+	stmt, args, err := binder.BindNamed(stmtInsert, params)
+	if err != nil {
+		return err
+	}
+	res, err := execer.Exec(stmt, args...)
+	if err != nil {
+		return err
+	}
+	result.ID, err = res.LastInsertId()
+	return err
+}
+
+const queryBase = `
+SELECT
+ test_id
+,test_repo_id
+,test_build_id
+,test_stage_id
+,test_step_id
+,test_suite
+,test_class
+,test_name
+,test_status
+,test_duration
+,test_message
+,test_created
+`
+
+const queryBuild = queryBase + `
+FROM tests
+WHERE test_build_id = :test_build_id
+ORDER BY test_id ASC
+`
+
+const querySummary = `
+SELECT test_status, COUNT(*)
+FROM tests
+WHERE test_build_id = :test_build_id
+GROUP BY test_status
+`
+
+// the history is limited to the most recent builds of the
+// branch. Pull requests from other branches are excluded
+// because the build source and target must both match.
+const queryHistory = queryBase + `
+FROM tests
+INNER JOIN (
+  SELECT build_id
+  FROM builds
+  WHERE build_repo_id = :build_repo_id
+    AND build_source = :build_branch
+    AND build_target = :build_branch
+  ORDER BY build_id DESC
+  LIMIT :limit
+) recent ON test_build_id = recent.build_id
+ORDER BY test_build_id ASC, test_id ASC
+`
+
+const stmtInsert = `
+INSERT INTO tests (
+ test_repo_id
+,test_build_id
+,test_stage_id
+,test_step_id
+,test_suite
+,test_class
+,test_name
+,test_status
+,test_duration
+,test_message
+,test_created
+) VALUES (
+ :test_repo_id
+,:test_build_id
+,:test_stage_id
+,:test_step_id
+,:test_suite
+,:test_class
+,:test_name
+,:test_status
+,:test_duration
+,:test_message
+,:test_created
+)
+`
+
+const stmtInsertPg = stmtInsert + `
+RETURNING test_id
+`
+
+const stmtDeleteStage = `
+DELETE FROM tests
+WHERE test_build_id = :test_build_id
+  AND test_stage_id = :test_stage_id
+`
@@ -0,0 +1,154 @@
+// Copyright 2019 Drone.IO Inc. All rights reserved.
+// Use of this source code is governed by the Drone Non-Commercial License
+// that can be found in the LICENSE file.
+
+// +build !oss
+
+package testresult
+
+import (
+	"context"
+	"testing"
+
+	"github.com/drone/drone/core"
+	"github.com/drone/drone/store/build"
+	"github.com/drone/drone/store/shared/db/dbtest"
+	"github.com/drone/drone/store/step"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+var noContext = context.TODO()
+
+func TestTestResult(t *testing.T) {
+	conn, err := dbtest.Connect()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	defer func() {
+		dbtest.Reset(conn)
+		dbtest.Disconnect(conn)
+	}()
+
+	// seeds the database with three builds of the master
+	// branch and one pull request targeting the master
+	// branch, each with a single stage and step.
+	builds := build.New(conn)
+	steps := step.New(conn)
+	store := New(conn).(*testStore)
+	statuses := []string{core.TestPassed, core.TestFailed, core.TestPassed, core.TestFailed}
+	sources := []string{"master", "master", "master", "feature"}
+
+	var items []*core.TestResult
+	for i, source := range sources {
+		item := &core.Build{
+			RepoID: 1,
+			Number: int64(i + 1),
+			Source: source,
+			Target: "master",
+		}
+		stage := &core.Stage{RepoID: 1, Number: 1}
+		err := builds.Create(noContext, item, []*core.Stage{stage})
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		step := &core.Step{StageID: stage.ID, Number: 1}
+		err = steps.Create(noContext, step)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		results := []*core.TestResult{
+			{Suite: "api", Name: "TestFlaky", Status: statuses[i], Duration: 15},
+			{Suite: "api", Name: "TestSkipped", Status: core.TestSkipped},
+		}
+		for _, result := range results {
+			result.RepoID = 1
+			result.BuildID = item.ID
+			result.StageID = stage.ID
+			result.StepID = step.ID
+		}
+		err = store.Create(noContext, results)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		for _, result := range results {
+			if result.ID == 0 {
+				t.Errorf("Want test result ID assigned, got %d", result.ID)
+			}
+		}
+		items = append(items, results...)
+	}
+
+	t.Run("List", testTestResultList(store, items))
+	t.Run("Summary", testTestResultSummary(store, items))
+	t.Run("History", testTestResultHistory(store, items))
+	t.Run("DeleteStage", testTestResultDeleteStage(store, items))
+}
+
+func testTestResultList(store *testStore, items []*core.TestResult) func(t *testing.T) {
+	return func(t *testing.T) {
+		list, err := store.List(noContext, items[2].BuildID)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if diff := cmp.Diff(list, items[2:4]); diff != "" {
+			t.Errorf(diff)
+		}
+	}
+}
+
+func testTestResultSummary(store *testStore, items []*core.TestResult) func(t *testing.T) {
+	return func(t *testing.T) {
+		summary, err := store.Summary(noContext, items[2].BuildID)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		want := &core.TestSummary{Total: 2, Failed: 1, Skipped: 1}
+		if diff := cmp.Diff(summary, want); diff != "" {
+			t.Errorf(diff)
+		}
+	}
+}
+
+func testTestResultHistory(store *testStore, items []*core.TestResult) func(t *testing.T) {
+	return func(t *testing.T) {
+		list, err := store.History(noContext, 1, "master", 2)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		// the pull request is excluded and the history is
+		// limited to the two most recent builds.
+		if diff := cmp.Diff(list, items[2:6]); diff != "" {
+			t.Errorf(diff)
+		}
+	}
+}
+
+func testTestResultDeleteStage(store *testStore, items []*core.TestResult) func(t *testing.T) {
+	return func(t *testing.T) {
+		err := store.DeleteStage(noContext, items[0].BuildID, items[0].StageID)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		list, err := store.List(noContext, items[0].BuildID)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if len(list) != 0 {
+			t.Errorf("Want test results of the stage deleted, got %d", len(list))
+		}
+		list, _ = store.List(noContext, items[2].BuildID)
+		if len(list) != 2 {
+			t.Errorf("Want test results of other builds retained, got %d", len(list))
+		}
+	}
+}
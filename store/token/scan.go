@@ -0,0 +1,85 @@
+// Copyright 2019 Drone IO, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package token
+
+import (
+	"database/sql"
+	"encoding/json"
+
+	"github.com/drone/drone/core"
+	"github.com/drone/drone/store/shared/db"
+
+	"github.com/jmoiron/sqlx/types"
+)
+
+// helper function converts the AccessToken structure to a
+// set of named query parameters.
+func toParams(token *core.AccessToken) map[string]interface{} {
+	return map[string]interface{}{
+		"token_id":        token.ID,
+		"token_user_id":   token.UserID,
+		"token_repo_id":   token.RepoID,
+		"token_name":      token.Name,
+		"token_scopes":    encodeSlice(token.Scopes),
+		"token_hash":      token.Hash,
+		"token_expires":   token.Expires,
+		"token_last_used": token.LastUsed,
+		"token_created":   token.Created,
+	}
+}
+
+func encodeSlice(v []string) types.JSONText {
+	raw, _ := json.Marshal(v)
+	return types.JSONText(raw)
+}
+
+// helper function scans the sql.Row and copies the column
+// values to the destination object.
+func scanRow(scanner db.Scanner, dst *core.AccessToken) error {
+	scopes := types.JSONText{}
+	err := scanner.Scan(
+		&dst.ID,
+		&dst.UserID,
+		&dst.RepoID,
+		&dst.Name,
+		&scopes,
+		&dst.Hash,
+		&dst.Expires,
+		&dst.LastUsed,
+		&dst.Created,
+	)
+	if err != nil {
+		return err
+	}
+	json.Unmarshal(scopes, &dst.Scopes)
+	return nil
+}
+
+// helper function scans the sql.Row and copies the column
+// values to the destination object.
+func scanRows(rows *sql.Rows) ([]*core.AccessToken, error) {
+	defer rows.Close()
+
+	tokens := []*core.AccessToken{}
+	for rows.Next() {
+		token := new(core.AccessToken)
+		err := scanRow(rows, token)
+		if err != nil {
+			return nil, err
+		}
+		tokens = append(tokens, token)
+	}
+	return tokens, nil
+}
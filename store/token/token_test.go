@@ -0,0 +1,171 @@
+// Copyright 2019 Drone.IO Inc. All rights reserved.
+// Use of this source code is governed by the Drone Non-Commercial License
+// that can be found in the LICENSE file.
+
+// +build !oss
+
+package token
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/drone/drone/core"
+	"github.com/drone/drone/store/shared/db/dbtest"
+	"github.com/drone/drone/store/shared/encrypt"
+	"github.com/drone/drone/store/user"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+var noContext = context.TODO()
+
+func TestToken(t *testing.T) {
+	conn, err := dbtest.Connect()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	defer func() {
+		dbtest.Reset(conn)
+		dbtest.Disconnect(conn)
+	}()
+
+	// seeds the database with a dummy user.
+	enc, _ := encrypt.New("")
+	users := user.New(conn, enc)
+	owner := &core.User{Login: "octocat", Hash: "MjAxOC0wOC0xMVQxNTo1ODowN1o"}
+	if err := users.Create(noContext, owner); err != nil {
+		t.Error(err)
+		return
+	}
+
+	store := New(conn).(*tokenStore)
+	t.Run("Create", testTokenCreate(store, owner))
+}
+
+func testTokenCreate(store *tokenStore, owner *core.User) func(t *testing.T) {
+	return func(t *testing.T) {
+		items := []*core.AccessToken{
+			{
+				UserID:  owner.ID,
+				Name:    "deploy",
+				Scopes:  []string{core.ScopeBuildsWrite},
+				Hash:    core.HashToken("drone_pat_a"),
+				RepoID:  1,
+				Expires: 1522878684,
+				Created: 1522878684,
+			},
+			{
+				UserID:  owner.ID,
+				Name:    "cli",
+				Scopes:  []string{core.ScopeRead},
+				Hash:    core.HashToken("drone_pat_b"),
+				Created: 1522878685,
+			},
+		}
+		for _, item := range items {
+			err := store.Create(noContext, item)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			if item.ID == 0 {
+				t.Errorf("Want token ID assigned, got %d", item.ID)
+			}
+		}
+
+		t.Run("Duplicate", testTokenDuplicate(store, items[0]))
+		t.Run("Find", testTokenFind(store, items[0]))
+		t.Run("FindHash", testTokenFindHash(store, items[1]))
+		t.Run("List", testTokenList(store, owner, items))
+		t.Run("Touch", testTokenTouch(store, items[1]))
+		t.Run("Delete", testTokenDelete(store, items[0]))
+	}
+}
+
+func testTokenDuplicate(store *tokenStore, token *core.AccessToken) func(t *testing.T) {
+	return func(t *testing.T) {
+		err := store.Create(noContext, &core.AccessToken{
+			UserID: token.UserID,
+			Name:   "duplicate",
+			Hash:   token.Hash,
+		})
+		if err == nil {
+			t.Errorf("Want unique constraint error for duplicate hash")
+		}
+	}
+}
+
+func testTokenFind(store *tokenStore, token *core.AccessToken) func(t *testing.T) {
+	return func(t *testing.T) {
+		result, err := store.Find(noContext, token.ID)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if diff := cmp.Diff(result, token); diff != "" {
+			t.Errorf(diff)
+		}
+	}
+}
+
+func testTokenFindHash(store *tokenStore, token *core.AccessToken) func(t *testing.T) {
+	return func(t *testing.T) {
+		result, err := store.FindHash(noContext, token.Hash)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if diff := cmp.Diff(result, token); diff != "" {
+			t.Errorf(diff)
+		}
+	}
+}
+
+func testTokenList(store *tokenStore, owner *core.User, tokens []*core.AccessToken) func(t *testing.T) {
+	return func(t *testing.T) {
+		list, err := store.List(noContext, owner.ID)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		want := []*core.AccessToken{tokens[1], tokens[0]}
+		if diff := cmp.Diff(list, want); diff != "" {
+			t.Errorf(diff)
+		}
+	}
+}
+
+func testTokenTouch(store *tokenStore, token *core.AccessToken) func(t *testing.T) {
+	return func(t *testing.T) {
+		err := store.Touch(noContext, token.ID, 1522878690)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		result, err := store.Find(noContext, token.ID)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if got, want := result.LastUsed, int64(1522878690); got != want {
+			t.Errorf("Want last used %d, got %d", want, got)
+		}
+	}
+}
+
+func testTokenDelete(store *tokenStore, token *core.AccessToken) func(t *testing.T) {
+	return func(t *testing.T) {
+		err := store.Delete(noContext, token)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		_, err = store.Find(noContext, token.ID)
+		if got, want := sql.ErrNoRows, err; got != want {
+			t.Errorf("Want sql.ErrNoRows, got %v", got)
+		}
+	}
+}
@@ -63,6 +63,21 @@ func (s *userStore) FindLogin(ctx context.Context, login string) (*core.User, er
 	return out, err
 }
 
+// FindEmail returns a user from the datastore by email.
+func (s *userStore) FindEmail(ctx context.Context, email string) (*core.User, error) {
+	out := &core.User{Email: email}
+	err := s.db.View(func(queryer db.Queryer, binder db.Binder) error {
+		params := map[string]interface{}{"user_email": email}
+		query, args, err := binder.BindNamed(queryEmail, params)
+		if err != nil {
+			return err
+		}
+		row := queryer.QueryRow(query, args...)
+		return scanRow(s.enc, row, out)
+	})
+	return out, err
+}
+
 // FindToken returns a user from the datastore by token.
 func (s *userStore) FindToken(ctx context.Context, token string) (*core.User, error) {
 	out := &core.User{Hash: token}
@@ -251,6 +266,13 @@ FROM users
 WHERE user_login = :user_login
 `
 
+const queryEmail = queryBase + `
+FROM users
+WHERE LOWER(user_email) = LOWER(:user_email)
+ORDER BY user_id
+LIMIT 1
+`
+
 const queryToken = queryBase + `
 FROM users
 WHERE user_hash = :user_hash
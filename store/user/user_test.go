@@ -54,6 +54,7 @@ func testUserCreate(store *userStore) func(t *testing.T) {
 		t.Run("Count", testUserCount(store))
 		t.Run("Find", testUserFind(store, user))
 		t.Run("FindLogin", testUserFindLogin(store))
+		t.Run("FindEmail", testUserFindEmail(store))
 		t.Run("FindToken", testUserFindToken(store))
 		t.Run("List", testUserList(store))
 		t.Run("Update", testUserUpdate(store, user))
@@ -103,6 +104,17 @@ func testUserFindLogin(users *userStore) func(t *testing.T) {
 	}
 }
 
+func testUserFindEmail(users *userStore) func(t *testing.T) {
+	return func(t *testing.T) {
+		user, err := users.FindEmail(noContext, "OctoCat@github.com")
+		if err != nil {
+			t.Error(err)
+		} else {
+			t.Run("Fields", testUser(user))
+		}
+	}
+}
+
 func testUserFindToken(users *userStore) func(t *testing.T) {
 	return func(t *testing.T) {
 		user, err := users.FindToken(noContext, "MjAxOC0wOC0xMVQxNTo1ODowN1o")
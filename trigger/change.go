@@ -14,59 +14,91 @@
 
 package trigger
 
-// import (
-// 	"context"
-// 	"regexp"
-// 	"strconv"
+import (
+	"context"
+	"regexp"
+	"strconv"
 
-// 	"github.com/drone/drone/core"
-// 	"github.com/drone/go-scm/scm"
-// )
+	"github.com/drone/drone-yaml/yaml"
+	"github.com/drone/drone/core"
 
-// func listChanges(client *scm.Client, repo *core.Repository, build *core.Build) ([]string, error) {
-// 	switch build.Event {
-// 	case core.EventPullRequest:
-// 		return listChangesPullRequest(client, repo, build)
-// 	case core.EventPush:
-// 		return listChangesPush(client, repo, build)
-// 	default:
-// 		return nil, nil
-// 	}
-// }
+	"github.com/sirupsen/logrus"
+)
 
-// func listChangesPullRequest(client *scm.Client, repo *core.Repository, build *core.Build) ([]string, error) {
-// 	var paths []string
-// 	pr, err := parsePullRequest(build.Ref)
-// 	if err != nil {
-// 		return nil, err
-// 	}
-// 	change, _, err := client.PullRequests.ListChanges(context.Background(), repo.Slug, pr, scm.ListOptions{})
-// 	if err == nil {
-// 		for _, file := range change {
-// 			paths = append(paths, file.Path)
-// 		}
-// 	}
-// 	return paths, err
-// }
+// emptyCommit is the sha used by some providers to indicate
+// the before commit is unknown, for example when a new branch
+// is pushed.
+const emptyCommit = "0000000000000000000000000000000000000000"
 
-// func listChangesPush(client *scm.Client, repo *core.Repository, build *core.Build) ([]string, error) {
-// 	var paths []string
-// 	// TODO (bradrydzewski) some tag hooks provide the tag but do
-// 	// not provide the sha, in which case we should use the ref
-// 	// instead of the sha.
-// 	change, _, err := client.Git.ListChanges(context.Background(), repo.Slug, build.After, scm.ListOptions{})
-// 	if err == nil {
-// 		for _, file := range change {
-// 			paths = append(paths, file.Path)
-// 		}
-// 	}
-// 	return paths, err
-// }
+// listChanges returns the files changed by the hook. The push
+// changes are computed from the before and after commits, and
+// the pull request changes from the pull request diff. If the
+// before commit is unknown, for example when a new branch is
+// pushed, or the range cannot be compared, for example after
+// a force push, an empty list is returned so that no path
+// conditions are applied.
+//
+// Comparing the range fails open: the pipelines run as if they
+// did not define path conditions, since skipping a build whose
+// changes are unknown could skip required checks.
+func (t *triggerer) listChanges(ctx context.Context, user *core.User, repo *core.Repository, base *core.Hook) ([]string, error) {
+	var changes []*core.Change
+	var err error
+	switch base.Event {
+	case core.EventPullRequest:
+		number, perr := parsePullRequest(base.Ref)
+		if perr != nil {
+			return nil, perr
+		}
+		changes, err = t.commits.ListPullChanges(ctx, user, repo.Slug, number)
+	case core.EventPush:
+		if base.After == "" || base.Before == "" || base.Before == emptyCommit {
+			return nil, nil
+		}
+		changes, err = t.commits.CompareChanges(ctx, user, repo.Slug, base.Before, base.After)
+		if err != nil {
+			logrus.WithError(err).
+				WithField("repo", repo.Slug).
+				WithField("before", base.Before).
+				WithField("after", base.After).
+				Warnln("trigger: cannot compare commits, path conditions ignored")
+			return nil, nil
+		}
+	default:
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var paths []string
+	for _, change := range changes {
+		paths = append(paths, change.Path)
+	}
+	return paths, nil
+}
+
+// helper function returns true if any pipeline in the
+// manifest defines path conditions.
+func hasPaths(manifest *yaml.Manifest) bool {
+	for _, document := range manifest.Resources {
+		pipeline, ok := document.(*yaml.Pipeline)
+		if !ok {
+			continue
+		}
+		paths := pipeline.Trigger.Paths
+		if len(paths.Include) != 0 || len(paths.Exclude) != 0 {
+			return true
+		}
+	}
+	return false
+}
 
-// func parsePullRequest(ref string) (int, error) {
-// 	return strconv.Atoi(
-// 		pre.FindString(ref),
-// 	)
-// }
+// helper function returns the pull request number from
+// the git reference (e.g. refs/pull/42/head).
+func parsePullRequest(ref string) (int, error) {
+	return strconv.Atoi(
+		pre.FindString(ref),
+	)
+}
 
-// var pre = regexp.MustCompile("\\d+")
+var pre = regexp.MustCompile("\\d+")
@@ -6,131 +6,218 @@
 
 package trigger
 
-// import (
-// 	"testing"
-
-// 	"github.com/drone/drone/core"
-// 	"github.com/drone/drone/mock"
-// 	"github.com/drone/go-scm/scm"
-
-// 	"github.com/golang/mock/gomock"
-// 	"github.com/google/go-cmp/cmp"
-// )
-
-// func Test_listChanges_None(t *testing.T) {
-// 	controller := gomock.NewController(t)
-// 	defer controller.Finish()
-
-// 	mockRepo := &core.Repository{
-// 		Slug: "octocat/hello-world",
-// 	}
-// 	mockBuild := &core.Build{
-// 		Event: core.EventTag,
-// 		Ref:   "refs/tags/v1.0.0",
-// 	}
-// 	paths, err := listChanges(nil, mockRepo, mockBuild)
-// 	if err != nil {
-// 		t.Error(err)
-// 	}
-// 	if len(paths) != 0 {
-// 		t.Errorf("Expect empty changeset for Tag events")
-// 	}
-// }
-
-// func Test_listChanges_Push(t *testing.T) {
-// 	controller := gomock.NewController(t)
-// 	defer controller.Finish()
-
-// 	mockRepo := &core.Repository{
-// 		Slug: "octocat/hello-world",
-// 	}
-// 	mockBuild := &core.Build{
-// 		Event: core.EventPush,
-// 		After: "7fd1a60b01f91b314f59955a4e4d4e80d8edf11d",
-// 	}
-// 	mockChanges := []*scm.Change{
-// 		{Path: "README.md"},
-// 	}
-
-// 	mockGit := mock.NewMockGitService(controller)
-// 	mockGit.EXPECT().ListChanges(gomock.Any(), mockRepo.Slug, mockBuild.After, gomock.Any()).Return(mockChanges, nil, nil)
-
-// 	mockClient := new(scm.Client)
-// 	mockClient.Git = mockGit
-
-// 	got, err := listChanges(mockClient, mockRepo, mockBuild)
-// 	if err != nil {
-// 		t.Error(err)
-// 	}
-// 	want := []string{"README.md"}
-// 	if diff := cmp.Diff(got, want); diff != "" {
-// 		t.Errorf(diff)
-// 	}
-// }
-
-// func Test_listChanges_PullRequest(t *testing.T) {
-// 	controller := gomock.NewController(t)
-// 	defer controller.Finish()
-
-// 	mockRepo := &core.Repository{
-// 		Slug: "octocat/hello-world",
-// 	}
-// 	mockBuild := &core.Build{
-// 		Event: core.EventPullRequest,
-// 		Ref:   "refs/pulls/12/head",
-// 	}
-// 	mockChanges := []*scm.Change{
-// 		{Path: "README.md"},
-// 	}
-
-// 	mockPR := mock.NewMockPullRequestService(controller)
-// 	mockPR.EXPECT().ListChanges(gomock.Any(), mockRepo.Slug, 12, gomock.Any()).Return(mockChanges, nil, nil)
-
-// 	mockClient := new(scm.Client)
-// 	mockClient.PullRequests = mockPR
-
-// 	got, err := listChanges(mockClient, mockRepo, mockBuild)
-// 	if err != nil {
-// 		t.Error(err)
-// 	}
-// 	want := []string{"README.md"}
-// 	if diff := cmp.Diff(got, want); diff != "" {
-// 		t.Errorf(diff)
-// 	}
-// }
-
-// func Test_listChanges_PullRequest_ParseError(t *testing.T) {
-// 	controller := gomock.NewController(t)
-// 	defer controller.Finish()
-
-// 	mockRepo := &core.Repository{
-// 		Slug: "octocat/hello-world",
-// 	}
-// 	mockBuild := &core.Build{
-// 		Event: core.EventPullRequest,
-// 		Ref:   "refs/pulls/foo/head",
-// 	}
-// 	_, err := listChanges(nil, mockRepo, mockBuild)
-// 	if err == nil {
-// 		t.Errorf("Expect error parsing invalid pull request number")
-// 	}
-// }
-
-// func Test_parsePullRequest(t *testing.T) {
-// 	var tests = []struct {
-// 		ref string
-// 		num int
-// 	}{
-// 		{"refs/pulls/1/merge", 1},
-// 		{"refs/pulls/12/merge", 12},
-// 	}
-// 	for _, test := range tests {
-// 		pr, err := parsePullRequest(test.ref)
-// 		if err != nil {
-// 			t.Error(err)
-// 		}
-// 		if got, want := pr, test.num; got != want {
-// 			t.Errorf("Want pull request number %d, got %d", want, got)
-// 		}
-// 	}
-// }
+import (
+	"testing"
+
+	"github.com/drone/drone/core"
+	"github.com/drone/drone/mock"
+	"github.com/drone/go-scm/scm"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/go-cmp/cmp"
+	"github.com/sirupsen/logrus"
+	"github.com/sirupsen/logrus/hooks/test"
+)
+
+func Test_listChanges_None(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	mockRepo := &core.Repository{
+		Slug: "octocat/hello-world",
+	}
+	mockHook := &core.Hook{
+		Event: core.EventTag,
+		Ref:   "refs/tags/v1.0.0",
+	}
+	triggerer := &triggerer{}
+	paths, err := triggerer.listChanges(noContext, dummyUser, mockRepo, mockHook)
+	if err != nil {
+		t.Error(err)
+	}
+	if len(paths) != 0 {
+		t.Errorf("Expect empty changeset for Tag events")
+	}
+}
+
+func Test_listChanges_Push(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	mockRepo := &core.Repository{
+		Slug: "octocat/hello-world",
+	}
+	mockHook := &core.Hook{
+		Event:  core.EventPush,
+		Before: "553c2077f0edc3d5dc5d17262f6aa498e69d6f8e",
+		After:  "7fd1a60b01f91b314f59955a4e4d4e80d8edf11d",
+		Ref:    "refs/heads/master",
+	}
+	mockChanges := []*core.Change{
+		{Path: "README.md"},
+		{Path: "main.go"},
+	}
+
+	mockCommits := mock.NewMockCommitService(controller)
+	mockCommits.EXPECT().CompareChanges(gomock.Any(), dummyUser, mockRepo.Slug, mockHook.Before, mockHook.After).Return(mockChanges, nil)
+
+	triggerer := &triggerer{commits: mockCommits}
+	got, err := triggerer.listChanges(noContext, dummyUser, mockRepo, mockHook)
+	if err != nil {
+		t.Error(err)
+	}
+	want := []string{"README.md", "main.go"}
+	if diff := cmp.Diff(got, want); diff != "" {
+		t.Errorf(diff)
+	}
+}
+
+// this test verifies no changes are returned when the before
+// commit is unknown, for example when a new branch is pushed,
+// so that path conditions are not applied.
+func Test_listChanges_PushNewBranch(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	mockRepo := &core.Repository{
+		Slug: "octocat/hello-world",
+	}
+	for _, before := range []string{"", emptyCommit} {
+		mockHook := &core.Hook{
+			Event:  core.EventPush,
+			Before: before,
+			After:  "7fd1a60b01f91b314f59955a4e4d4e80d8edf11d",
+			Ref:    "refs/heads/feature",
+		}
+
+		mockCommits := mock.NewMockCommitService(controller)
+
+		triggerer := &triggerer{commits: mockCommits}
+		got, err := triggerer.listChanges(noContext, dummyUser, mockRepo, mockHook)
+		if err != nil {
+			t.Error(err)
+		}
+		if len(got) != 0 {
+			t.Errorf("Expect empty changeset for before commit %q, got %v", before, got)
+		}
+	}
+}
+
+// this test verifies no changes are returned when the commit
+// range cannot be compared, for example after a force push.
+func Test_listChanges_PushForce(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	mockRepo := &core.Repository{
+		Slug: "octocat/hello-world",
+	}
+	mockHook := &core.Hook{
+		Event:  core.EventPush,
+		Before: "553c2077f0edc3d5dc5d17262f6aa498e69d6f8e",
+		After:  "7fd1a60b01f91b314f59955a4e4d4e80d8edf11d",
+		Ref:    "refs/heads/master",
+	}
+
+	mockCommits := mock.NewMockCommitService(controller)
+	mockCommits.EXPECT().CompareChanges(gomock.Any(), dummyUser, mockRepo.Slug, mockHook.Before, mockHook.After).Return(nil, scm.ErrNotFound)
+
+	hook := test.NewGlobal()
+	defer hook.Reset()
+
+	triggerer := &triggerer{commits: mockCommits}
+	got, err := triggerer.listChanges(noContext, dummyUser, mockRepo, mockHook)
+	if err != nil {
+		t.Error(err)
+	}
+	if len(got) != 0 {
+		t.Errorf("Expect empty changeset, got %v", got)
+	}
+
+	// the comparison error is logged, since the path
+	// conditions of the pipelines are ignored.
+	entry := hook.LastEntry()
+	if entry == nil {
+		t.Errorf("Expect comparison error logged")
+		return
+	}
+	if got, want := entry.Level, logrus.WarnLevel; got != want {
+		t.Errorf("Want log level %s, got %s", want, got)
+	}
+	if got, want := entry.Data["repo"], mockRepo.Slug; got != want {
+		t.Errorf("Want repository %q logged, got %v", want, got)
+	}
+	if got, want := entry.Data["before"], mockHook.Before; got != want {
+		t.Errorf("Want before commit %q logged, got %v", want, got)
+	}
+	if got, want := entry.Data["after"], mockHook.After; got != want {
+		t.Errorf("Want after commit %q logged, got %v", want, got)
+	}
+}
+
+func Test_listChanges_PullRequest(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	mockRepo := &core.Repository{
+		Slug: "octocat/hello-world",
+	}
+	mockHook := &core.Hook{
+		Event: core.EventPullRequest,
+		Ref:   "refs/pull/12/head",
+	}
+	mockChanges := []*core.Change{
+		{Path: "README.md"},
+	}
+
+	mockCommits := mock.NewMockCommitService(controller)
+	mockCommits.EXPECT().ListPullChanges(gomock.Any(), dummyUser, mockRepo.Slug, 12).Return(mockChanges, nil)
+
+	triggerer := &triggerer{commits: mockCommits}
+	got, err := triggerer.listChanges(noContext, dummyUser, mockRepo, mockHook)
+	if err != nil {
+		t.Error(err)
+	}
+	want := []string{"README.md"}
+	if diff := cmp.Diff(got, want); diff != "" {
+		t.Errorf(diff)
+	}
+}
+
+func Test_listChanges_PullRequest_ParseError(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	mockRepo := &core.Repository{
+		Slug: "octocat/hello-world",
+	}
+	mockHook := &core.Hook{
+		Event: core.EventPullRequest,
+		Ref:   "refs/pull/foo/head",
+	}
+	triggerer := &triggerer{}
+	_, err := triggerer.listChanges(noContext, dummyUser, mockRepo, mockHook)
+	if err == nil {
+		t.Errorf("Expect error parsing invalid pull request number")
+	}
+}
+
+func Test_parsePullRequest(t *testing.T) {
+	var tests = []struct {
+		ref string
+		num int
+	}{
+		{"refs/pull/1/merge", 1},
+		{"refs/pull/12/head", 12},
+		{"refs/merge-requests/42/head", 42},
+	}
+	for _, test := range tests {
+		pr, err := parsePullRequest(test.ref)
+		if err != nil {
+			t.Error(err)
+		}
+		if got, want := pr, test.num; got != want {
+			t.Errorf("Want pull request number %d, got %d", want, got)
+		}
+	}
+}
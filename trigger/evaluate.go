@@ -0,0 +1,189 @@
+// Copyright 2019 Drone IO, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package trigger
+
+import (
+	"context"
+
+	"github.com/drone/drone-yaml/yaml"
+	"github.com/drone/drone-yaml/yaml/converter"
+	"github.com/drone/drone-yaml/yaml/linter"
+	"github.com/drone/drone-yaml/yaml/signer"
+
+	"github.com/drone/drone/core"
+	"github.com/drone/drone/trigger/dag"
+)
+
+// Evaluate evaluates the pipeline configuration for the hook
+// using the same steps as Trigger, without creating a build.
+// Configuration errors are included in the evaluation result
+// and are not returned as an error.
+func (t *triggerer) Evaluate(ctx context.Context, repo *core.Repository, base *core.Hook, config *core.Config) (*core.Evaluation, error) {
+	out := &core.Evaluation{
+		Pipelines: []*core.PipelineEvaluation{},
+	}
+
+	if reason := skipHook(repo, base); reason != "" {
+		out.Skipped = reason
+		return out, nil
+	}
+
+	user, err := t.users.Find(ctx, repo.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if user.Active == false {
+		out.Skipped = "repository owner is inactive"
+		return out, nil
+	}
+
+	tmpBuild := newTempBuild(repo, base)
+
+	// if the configuration is provided inline it is used
+	// in place of the configuration file in the repository.
+	var raw *core.Config
+	if config != nil {
+		raw = &core.Config{
+			Data: config.Data,
+			Kind: config.Kind,
+		}
+	} else {
+		raw, err = t.config.Find(ctx, &core.ConfigArgs{
+			User:  user,
+			Repo:  repo,
+			Build: tmpBuild,
+		})
+		if err != nil {
+			return evaluateError(out, err)
+		}
+	}
+	out.Config = raw.Data
+
+	raw, err = t.convert.Convert(ctx, &core.ConvertArgs{
+		User:   user,
+		Repo:   repo,
+		Build:  tmpBuild,
+		Config: raw,
+	})
+	if err != nil {
+		return evaluateError(out, err)
+	}
+	out.Config = raw.Data
+
+	raw.Data, err = converter.ConvertString(raw.Data, converter.Metadata{
+		Filename: repo.Config,
+		URL:      repo.Link,
+		Ref:      base.Ref,
+	})
+	if err != nil {
+		return evaluateError(out, err)
+	}
+	out.Config = raw.Data
+
+	manifest, err := yaml.ParseString(raw.Data)
+	if err != nil {
+		return evaluateError(out, err)
+	}
+
+	verr := t.validate.Validate(ctx, &core.ValidateArgs{
+		User:   user,
+		Repo:   repo,
+		Build:  tmpBuild,
+		Config: raw,
+	})
+	switch verr {
+	case nil, core.ErrValidatorBlock:
+	case core.ErrValidatorSkip:
+		out.Skipped = "validation service skipped pipeline"
+		return out, nil
+	default:
+		return evaluateError(out, verr)
+	}
+
+	err = linter.Manifest(manifest, repo.Trusted)
+	if err != nil {
+		return evaluateError(out, err)
+	}
+
+	out.Verified = true
+	if repo.Protected && base.Trigger == core.TriggerHook {
+		key := signer.KeyString(repo.Secret)
+		val := []byte(raw.Data)
+		out.Verified, _ = signer.Verify(val, key)
+	}
+	if verr == core.ErrValidatorBlock {
+		out.Verified = false
+	}
+
+	var paths []string
+	if hasPaths(manifest) {
+		paths, err = t.listChanges(ctx, user, repo, base)
+		if err != nil {
+			out.Errors = append(out.Errors, err.Error())
+		}
+	}
+
+	var matched []*core.PipelineEvaluation
+	var nodes []string
+	var dag = dag.New()
+	for _, document := range manifest.Resources {
+		pipeline, ok := document.(*yaml.Pipeline)
+		if !ok {
+			continue
+		}
+		name := pipeline.Name
+		if name == "" {
+			name = "default"
+		}
+		node := dag.Add(pipeline.Name, pipeline.DependsOn...)
+		node.Skip = true
+
+		result := &core.PipelineEvaluation{Name: name}
+		if reason := skipPipeline(pipeline, repo, base, paths); reason != "" {
+			result.Reason = reason
+		} else {
+			result.Matched = true
+			node.Skip = false
+			matched = append(matched, result)
+			nodes = append(nodes, pipeline.Name)
+		}
+		out.Pipelines = append(out.Pipelines, result)
+	}
+
+	if dag.DetectCycles() {
+		out.Errors = append(out.Errors, "Error: Dependency cycle detected in Pipeline")
+		return out, nil
+	}
+
+	if len(matched) == 0 {
+		out.Skipped = "no matching pipelines"
+		return out, nil
+	}
+
+	// the dependencies are re-worked to account for skipped
+	// pipelines, matching the dependencies of the build stages.
+	// The graph nodes are keyed by the pipeline name, before an
+	// unnamed pipeline is renamed to default.
+	for i, result := range matched {
+		result.DependsOn = dag.Dependencies(nodes[i])
+	}
+	return out, nil
+}
+
+// helper function appends the error to the evaluation result.
+func evaluateError(out *core.Evaluation, err error) (*core.Evaluation, error) {
+	out.Errors = append(out.Errors, err.Error())
+	return out, nil
+}
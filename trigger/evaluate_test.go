@@ -0,0 +1,214 @@
+// Copyright 2019 Drone.IO Inc. All rights reserved.
+// Use of this source code is governed by the Drone Non-Commercial License
+// that can be found in the LICENSE file.
+
+// +build !oss
+
+package trigger
+
+import (
+	"testing"
+
+	"github.com/drone/drone/core"
+	"github.com/drone/drone/mock"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestEvaluate(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	mockUsers := mock.NewMockUserStore(controller)
+	mockUsers.EXPECT().Find(gomock.Any(), dummyRepo.UserID).Return(dummyUser, nil)
+
+	mockConvertService := mock.NewMockConvertService(controller)
+	mockConvertService.EXPECT().Convert(gomock.Any(), gomock.Any()).Return(dummyYamlEvaluate, nil)
+
+	mockValidateService := mock.NewMockValidateService(controller)
+	mockValidateService.EXPECT().Validate(gomock.Any(), gomock.Any()).Return(nil)
+
+	triggerer := New(
+		nil,
+		nil,
+		mockConvertService,
+		nil,
+		nil,
+		nil,
+		nil,
+		nil,
+		mockUsers,
+		mockValidateService,
+		nil,
+		nil,
+	)
+
+	got, err := triggerer.Evaluate(noContext, dummyRepo, dummyHook, dummyYamlEvaluate)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	want := &core.Evaluation{
+		Config:   dummyYamlEvaluate.Data,
+		Verified: true,
+		Pipelines: []*core.PipelineEvaluation{
+			{Name: "build", Matched: true},
+			{Name: "deploy", Reason: "does not match event"},
+			{Name: "notify", Matched: true, DependsOn: []string{"build"}},
+		},
+	}
+	if diff := cmp.Diff(got, want); diff != "" {
+		t.Errorf(diff)
+	}
+}
+
+// this test verifies the dependencies of an unnamed pipeline,
+// which is evaluated as the default pipeline, are resolved.
+func TestEvaluate_Unnamed(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	mockUsers := mock.NewMockUserStore(controller)
+	mockUsers.EXPECT().Find(gomock.Any(), dummyRepo.UserID).Return(dummyUser, nil)
+
+	mockConvertService := mock.NewMockConvertService(controller)
+	mockConvertService.EXPECT().Convert(gomock.Any(), gomock.Any()).Return(dummyYamlEvaluateUnnamed, nil)
+
+	mockValidateService := mock.NewMockValidateService(controller)
+	mockValidateService.EXPECT().Validate(gomock.Any(), gomock.Any()).Return(nil)
+
+	triggerer := New(
+		nil,
+		nil,
+		mockConvertService,
+		nil,
+		nil,
+		nil,
+		nil,
+		nil,
+		mockUsers,
+		mockValidateService,
+		nil,
+		nil,
+	)
+
+	got, err := triggerer.Evaluate(noContext, dummyRepo, dummyHook, dummyYamlEvaluateUnnamed)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	want := &core.Evaluation{
+		Config:   dummyYamlEvaluateUnnamed.Data,
+		Verified: true,
+		Pipelines: []*core.PipelineEvaluation{
+			{Name: "build", Matched: true},
+			{Name: "default", Matched: true, DependsOn: []string{"build"}},
+		},
+	}
+	if diff := cmp.Diff(got, want); diff != "" {
+		t.Errorf(diff)
+	}
+}
+
+func TestEvaluate_SkipCI(t *testing.T) {
+	triggerer := New(
+		nil,
+		nil,
+		nil,
+		nil,
+		nil,
+		nil,
+		nil,
+		nil,
+		nil,
+		nil,
+		nil,
+		nil,
+	)
+
+	dummyHookSkip := *dummyHook
+	dummyHookSkip.Message = "foo [CI SKIP] bar"
+
+	got, err := triggerer.Evaluate(noContext, dummyRepo, &dummyHookSkip, nil)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if got, want := got.Skipped, "found skip directive"; got != want {
+		t.Errorf("Want skip reason %q, got %q", want, got)
+	}
+}
+
+func TestEvaluate_ErrorYaml(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	mockUsers := mock.NewMockUserStore(controller)
+	mockUsers.EXPECT().Find(gomock.Any(), dummyRepo.UserID).Return(dummyUser, nil)
+
+	mockConfigService := mock.NewMockConfigService(controller)
+	mockConfigService.EXPECT().Find(gomock.Any(), gomock.Any()).Return(dummyYamlInvalid, nil)
+
+	mockConvertService := mock.NewMockConvertService(controller)
+	mockConvertService.EXPECT().Convert(gomock.Any(), gomock.Any()).Return(dummyYamlInvalid, nil)
+
+	triggerer := New(
+		nil,
+		mockConfigService,
+		mockConvertService,
+		nil,
+		nil,
+		nil,
+		nil,
+		nil,
+		mockUsers,
+		nil,
+		nil,
+		nil,
+	)
+
+	got, err := triggerer.Evaluate(noContext, dummyRepo, dummyHook, nil)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if len(got.Errors) == 0 {
+		t.Errorf("Expect yaml parsing error included in evaluation")
+	}
+	if len(got.Pipelines) != 0 {
+		t.Errorf("Expect no pipelines evaluated when yaml is invalid")
+	}
+}
+
+var dummyYamlEvaluate = &core.Config{
+	Data: `
+kind: pipeline
+name: build
+---
+kind: pipeline
+name: deploy
+trigger:
+  event:
+  - promote
+---
+kind: pipeline
+name: notify
+depends_on:
+- build
+- deploy
+`,
+}
+
+var dummyYamlEvaluateUnnamed = &core.Config{
+	Data: `
+kind: pipeline
+name: build
+---
+kind: pipeline
+depends_on:
+- build
+`,
+}
@@ -0,0 +1,214 @@
+// Copyright 2019 Drone IO, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package journal
+
+import (
+	"context"
+	"runtime/debug"
+	"time"
+
+	"github.com/drone/drone/core"
+	"github.com/drone/drone/logger"
+	"github.com/drone/drone/store/shared/db"
+	"github.com/drone/go-scm/scm"
+
+	"github.com/sirupsen/logrus"
+)
+
+// triggerTimeout is the maximum duration allowed to process
+// a journal entry.
+const triggerTimeout = time.Minute * 5
+
+// claimTimeout is the duration a journal entry is reserved by
+// the server processing the entry. It must exceed the trigger
+// timeout to prevent duplicate builds.
+const claimTimeout = time.Minute * 10
+
+// purgeInterval is the interval at which expired journal
+// entries are removed from the datastore.
+const purgeInterval = time.Hour
+
+// New returns a new journal Worker.
+func New(
+	builds core.BuildStore,
+	commands core.CommandService,
+	journal core.JournalStore,
+	repos core.RepositoryStore,
+	triggerer core.Triggerer,
+	retention time.Duration,
+) *Worker {
+	return &Worker{
+		builds:    builds,
+		commands:  commands,
+		journal:   journal,
+		repos:     repos,
+		triggerer: triggerer,
+		retention: retention,
+	}
+}
+
+// Worker processes the pending entries of the incoming
+// webhook journal.
+type Worker struct {
+	builds    core.BuildStore
+	commands  core.CommandService
+	journal   core.JournalStore
+	repos     core.RepositoryStore
+	triggerer core.Triggerer
+	retention time.Duration
+	purged    time.Time
+}
+
+// Start starts the journal worker.
+func (w *Worker) Start(ctx context.Context, dur time.Duration) error {
+	ticker := time.NewTicker(dur)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			w.run(ctx)
+		}
+	}
+}
+
+func (w *Worker) run(ctx context.Context) error {
+	defer func() {
+		// taking the paranoid approach to recover from
+		// a panic that should absolutely never happen.
+		if r := recover(); r != nil {
+			logrus.Errorf("journal: unexpected panic: %s", r)
+			debug.PrintStack()
+		}
+	}()
+
+	now := time.Now()
+	if w.retention != 0 && now.Sub(w.purged) > purgeInterval {
+		w.purged = now
+		err := w.journal.Purge(ctx, now.Add(-w.retention).Unix())
+		if err != nil {
+			logrus.WithError(err).
+				Errorln("journal: cannot purge journal entries")
+		}
+	}
+
+	entries, err := w.journal.ListPending(ctx, now.Unix())
+	if err != nil {
+		logrus.WithError(err).
+			Errorln("journal: cannot list pending journal entries")
+		return err
+	}
+
+	// entries are processed in the order they were received
+	// so that, for example, a branch deletion is processed
+	// after pushes to the branch.
+	for _, entry := range entries {
+		w.process(ctx, entry)
+	}
+	return nil
+}
+
+// process processes the journal entry and records the
+// outcome.
+func (w *Worker) process(ctx context.Context, entry *core.JournalEntry) error {
+	log := logrus.
+		WithField("journal.id", entry.ID).
+		WithField("journal.delivery", entry.Delivery).
+		WithField("journal.event", entry.Event)
+
+	// the entry is claimed before it is processed to prevent
+	// other servers from processing the same entry.
+	entry.Next = time.Now().Add(claimTimeout).Unix()
+	err := w.journal.Update(ctx, entry)
+	if err == db.ErrOptimisticLock {
+		log.Debugln("journal: entry claimed by another server")
+		return nil
+	}
+	if err != nil {
+		log.WithError(err).Errorln("journal: cannot claim entry")
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, triggerTimeout)
+	ctx = logger.WithContext(ctx, log)
+	defer cancel()
+
+	entry.Attempts++
+	entry.Error = ""
+	entry.Status, err = w.trigger(ctx, entry)
+	if err != nil {
+		log.WithError(err).Warnln("journal: cannot trigger build")
+		entry.Error = err.Error()
+		if len(entry.Error) > 500 {
+			entry.Error = entry.Error[:500]
+		}
+	}
+	entry.Updated = time.Now().Unix()
+	err = w.journal.Update(ctx, entry)
+	if err != nil {
+		log.WithError(err).Errorln("journal: cannot update entry")
+	}
+	return err
+}
+
+// trigger processes the hook and returns the journal status.
+func (w *Worker) trigger(ctx context.Context, entry *core.JournalEntry) (string, error) {
+	hook := entry.Hook
+	if hook == nil {
+		return core.JournalIgnored, nil
+	}
+	repo, err := w.repos.Find(ctx, entry.RepoID)
+	if err != nil {
+		return core.JournalFailed, err
+	}
+	if !repo.Active {
+		return core.JournalIgnored, nil
+	}
+
+	if hook.Event == core.EventPush && hook.Action == core.ActionDelete {
+		err := w.builds.DeleteBranch(ctx, repo.ID, hook.Target)
+		if err != nil {
+			return core.JournalFailed, err
+		}
+		return core.JournalSuccess, nil
+	}
+	if hook.Event == core.EventPullRequest && hook.Action == core.ActionClose {
+		err := w.builds.DeletePull(ctx, repo.ID, scm.ExtractPullRequest(hook.Ref))
+		if err != nil {
+			return core.JournalFailed, err
+		}
+		return core.JournalSuccess, nil
+	}
+
+	if hook.Event == core.EventComment {
+		err := w.commands.Exec(ctx, repo, hook)
+		if err != nil {
+			return core.JournalFailed, err
+		}
+		return core.JournalSuccess, nil
+	}
+
+	build, err := w.triggerer.Trigger(ctx, repo, hook)
+	if err != nil {
+		return core.JournalFailed, err
+	}
+	if build == nil {
+		return core.JournalIgnored, nil
+	}
+	entry.Build = build.Number
+	return core.JournalSuccess, nil
+}
@@ -0,0 +1,191 @@
+// Copyright 2019 Drone.IO Inc. All rights reserved.
+// Use of this source code is governed by the Drone Non-Commercial License
+// that can be found in the LICENSE file.
+
+// +build !oss
+
+package journal
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"testing"
+	"time"
+
+	"github.com/drone/drone/core"
+	"github.com/drone/drone/mock"
+	"github.com/drone/drone/store/shared/db"
+
+	"github.com/golang/mock/gomock"
+	"github.com/sirupsen/logrus"
+)
+
+var noContext = context.Background()
+
+func init() {
+	logrus.SetOutput(ioutil.Discard)
+}
+
+func TestWorker(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	repo := &core.Repository{ID: 1, Active: true}
+	hook := &core.Hook{Event: core.EventPush, Target: "master"}
+	entry := &core.JournalEntry{ID: 2, RepoID: 1, Hook: hook, Status: core.JournalPending}
+
+	journal := mock.NewMockJournalStore(controller)
+	journal.EXPECT().ListPending(gomock.Any(), gomock.Any()).Return([]*core.JournalEntry{entry}, nil)
+	journal.EXPECT().Update(gomock.Any(), entry).Return(nil).Times(2)
+
+	repos := mock.NewMockRepositoryStore(controller)
+	repos.EXPECT().Find(gomock.Any(), repo.ID).Return(repo, nil)
+
+	triggerer := mock.NewMockTriggerer(controller)
+	triggerer.EXPECT().Trigger(gomock.Any(), repo, hook).Return(&core.Build{Number: 42}, nil)
+
+	w := New(nil, nil, journal, repos, triggerer, 0)
+	err := w.run(noContext)
+	if err != nil {
+		t.Error(err)
+	}
+	if got, want := entry.Status, core.JournalSuccess; got != want {
+		t.Errorf("Want status %s, got %s", want, got)
+	}
+	if got, want := entry.Build, int64(42); got != want {
+		t.Errorf("Want build number %d, got %d", want, got)
+	}
+	if got, want := entry.Attempts, 1; got != want {
+		t.Errorf("Want %d attempts, got %d", want, got)
+	}
+}
+
+func TestWorker_TriggerError(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	repo := &core.Repository{ID: 1, Active: true}
+	hook := &core.Hook{Event: core.EventPush, Target: "master"}
+	entry := &core.JournalEntry{ID: 2, RepoID: 1, Hook: hook, Status: core.JournalPending}
+
+	journal := mock.NewMockJournalStore(controller)
+	journal.EXPECT().Update(gomock.Any(), entry).Return(nil).Times(2)
+
+	repos := mock.NewMockRepositoryStore(controller)
+	repos.EXPECT().Find(gomock.Any(), repo.ID).Return(repo, nil)
+
+	triggerer := mock.NewMockTriggerer(controller)
+	triggerer.EXPECT().Trigger(gomock.Any(), repo, hook).Return(nil, errors.New("cannot find yaml"))
+
+	w := New(nil, nil, journal, repos, triggerer, 0)
+	w.process(noContext, entry)
+	if got, want := entry.Status, core.JournalFailed; got != want {
+		t.Errorf("Want status %s, got %s", want, got)
+	}
+	if got, want := entry.Error, "cannot find yaml"; got != want {
+		t.Errorf("Want error %q, got %q", want, got)
+	}
+}
+
+func TestWorker_InactiveRepo(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	repo := &core.Repository{ID: 1, Active: false}
+	entry := &core.JournalEntry{ID: 2, RepoID: 1, Hook: &core.Hook{}, Status: core.JournalPending}
+
+	journal := mock.NewMockJournalStore(controller)
+	journal.EXPECT().Update(gomock.Any(), entry).Return(nil).Times(2)
+
+	repos := mock.NewMockRepositoryStore(controller)
+	repos.EXPECT().Find(gomock.Any(), repo.ID).Return(repo, nil)
+
+	w := New(nil, nil, journal, repos, nil, 0)
+	w.process(noContext, entry)
+	if got, want := entry.Status, core.JournalIgnored; got != want {
+		t.Errorf("Want status %s, got %s", want, got)
+	}
+}
+
+func TestWorker_DeleteBranch(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	repo := &core.Repository{ID: 1, Active: true}
+	hook := &core.Hook{Event: core.EventPush, Action: core.ActionDelete, Target: "feature"}
+	entry := &core.JournalEntry{ID: 2, RepoID: 1, Hook: hook, Status: core.JournalPending}
+
+	journal := mock.NewMockJournalStore(controller)
+	journal.EXPECT().Update(gomock.Any(), entry).Return(nil).Times(2)
+
+	repos := mock.NewMockRepositoryStore(controller)
+	repos.EXPECT().Find(gomock.Any(), repo.ID).Return(repo, nil)
+
+	builds := mock.NewMockBuildStore(controller)
+	builds.EXPECT().DeleteBranch(gomock.Any(), repo.ID, "feature").Return(nil)
+
+	w := New(builds, nil, journal, repos, nil, 0)
+	w.process(noContext, entry)
+	if got, want := entry.Status, core.JournalSuccess; got != want {
+		t.Errorf("Want status %s, got %s", want, got)
+	}
+}
+
+// this test verifies that an entry claimed by another server
+// is not processed.
+func TestWorker_Claimed(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	entry := &core.JournalEntry{ID: 2, RepoID: 1, Status: core.JournalPending}
+
+	journal := mock.NewMockJournalStore(controller)
+	journal.EXPECT().Update(gomock.Any(), entry).Return(db.ErrOptimisticLock)
+
+	w := New(nil, nil, journal, nil, nil, 0)
+	err := w.process(noContext, entry)
+	if err != nil {
+		t.Error(err)
+	}
+	if got, want := entry.Attempts, 0; got != want {
+		t.Errorf("Want %d attempts, got %d", want, got)
+	}
+}
+
+func TestWorker_Purge(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	journal := mock.NewMockJournalStore(controller)
+	journal.EXPECT().Purge(gomock.Any(), gomock.Any()).Return(nil)
+	journal.EXPECT().ListPending(gomock.Any(), gomock.Any()).Return(nil, nil).Times(2)
+
+	w := New(nil, nil, journal, nil, nil, time.Hour)
+	w.run(noContext)
+	w.run(noContext) // purge is skipped until the interval elapses
+}
+
+func TestWorker_Comment(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	repo := &core.Repository{ID: 1, Active: true}
+	hook := &core.Hook{Event: core.EventComment, Ref: "refs/pull/42/head", Message: "/drone retry"}
+	entry := &core.JournalEntry{ID: 2, RepoID: 1, Hook: hook, Status: core.JournalPending}
+
+	journal := mock.NewMockJournalStore(controller)
+	journal.EXPECT().Update(gomock.Any(), entry).Return(nil).Times(2)
+
+	repos := mock.NewMockRepositoryStore(controller)
+	repos.EXPECT().Find(gomock.Any(), repo.ID).Return(repo, nil)
+
+	commands := mock.NewMockCommandService(controller)
+	commands.EXPECT().Exec(gomock.Any(), repo, hook).Return(nil)
+
+	w := New(nil, commands, journal, repos, nil, 0)
+	w.process(noContext, entry)
+	if got, want := entry.Status, core.JournalSuccess; got != want {
+		t.Errorf("Want status %s, got %s", want, got)
+	}
+}
@@ -19,6 +19,8 @@ import (
 
 	"github.com/drone/drone-yaml/yaml"
 	"github.com/drone/drone/core"
+
+	"github.com/bmatcuk/doublestar"
 )
 
 func skipBranch(document *yaml.Pipeline, branch string) bool {
@@ -53,10 +55,52 @@ func skipCron(document *yaml.Pipeline, cron string) bool {
 	return !document.Trigger.Cron.Match(cron)
 }
 
+// skipHook returns the reason the hook should be skipped,
+// or an empty string if the hook should be processed.
+func skipHook(repo *core.Repository, hook *core.Hook) string {
+	switch {
+	case skipMessage(hook):
+		return "found skip directive"
+	case hook.Event == core.EventPullRequest && repo.IgnorePulls:
+		return "project ignores pull requests"
+	case hook.Event == core.EventPullRequest && repo.IgnoreForks && !strings.EqualFold(hook.Fork, repo.Slug):
+		return "project ignores forks"
+	default:
+		return ""
+	}
+}
+
+// skipPipeline returns the reason the pipeline should be
+// skipped, or an empty string if the pipeline matches the hook.
+func skipPipeline(document *yaml.Pipeline, repo *core.Repository, hook *core.Hook, paths []string) string {
+	switch {
+	case skipBranch(document, hook.Target):
+		return "does not match branch"
+	case skipEvent(document, hook.Event):
+		return "does not match event"
+	case skipAction(document, hook.Action):
+		return "does not match action"
+	case skipRef(document, hook.Ref):
+		return "does not match ref"
+	case skipRepo(document, repo.Slug):
+		return "does not match repo"
+	case skipTarget(document, hook.Deployment):
+		return "does not match deploy target"
+	case skipCron(document, hook.Cron):
+		return "does not match cron job"
+	case skipPaths(document, paths):
+		return "does not match changed files"
+	default:
+		return ""
+	}
+}
+
 func skipMessage(hook *core.Hook) bool {
 	switch {
 	case hook.Event == core.EventTag:
 		return false
+	case hook.Event == core.EventRelease:
+		return false
 	case hook.Event == core.EventCron:
 		return false
 	case hook.Event == core.EventCustom:
@@ -86,19 +130,56 @@ func skipMessageEval(str string) bool {
 	}
 }
 
-// func skipPaths(document *config.Config, paths []string) bool {
-// 	switch {
-// 	// changed files are only returned for push and pull request
-// 	// events. If the list of changed files is empty the system will
-// 	// force-run all pipelines and pipeline steps
-// 	case len(paths) == 0:
-// 		return false
-// 	// github returns a maximum of 300 changed files from the
-// 	// api response. If there are 300+ changed files the system
-// 	// will force-run all pipelines and pipeline steps.
-// 	case len(paths) >= 300:
-// 		return false
-// 	default:
-// 		return !document.Trigger.Paths.MatchAny(paths)
-// 	}
-// }
+// maximum number of changed files returned by some providers.
+// If the number of changed files reaches the limit the list may
+// be truncated.
+const maxChanges = 300
+
+func skipPaths(document *yaml.Pipeline, paths []string) bool {
+	cond := document.Trigger.Paths
+	switch {
+	// the pipeline does not define path conditions.
+	case len(cond.Include) == 0 && len(cond.Exclude) == 0:
+		return false
+	// changed files are only returned for push and pull request
+	// events. If the list of changed files is empty the system will
+	// force-run all pipelines and pipeline steps
+	case len(paths) == 0:
+		return false
+	// github returns a maximum of 300 changed files from the
+	// api response. If there are 300+ changed files the system
+	// will force-run all pipelines and pipeline steps.
+	case len(paths) >= maxChanges:
+		return false
+	default:
+		return !matchPaths(cond.Include, cond.Exclude, paths)
+	}
+}
+
+// helper function returns true if any of the paths match
+// the include patterns, and do not match the exclude
+// patterns. An empty include list matches all paths.
+func matchPaths(include, exclude, paths []string) bool {
+	for _, path := range paths {
+		if len(include) != 0 && !matchAny(include, path) {
+			continue
+		}
+		if matchAny(exclude, path) {
+			continue
+		}
+		return true
+	}
+	return false
+}
+
+// helper function returns true if the path matches any of
+// the glob patterns. Patterns support ** to match any
+// number of directories.
+func matchAny(patterns []string, path string) bool {
+	for _, pattern := range patterns {
+		if ok, _ := doublestar.Match(pattern, path); ok {
+			return true
+		}
+	}
+	return false
+}
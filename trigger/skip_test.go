@@ -69,6 +69,16 @@ func Test_skipEvent(t *testing.T) {
 			event:  "pull_request",
 			want:   true,
 		},
+		{
+			config: "kind: pipeline\ntrigger: { event: [ release ] }",
+			event:  "release",
+			want:   false,
+		},
+		{
+			config: "kind: pipeline\ntrigger: { event: [ tag ] }",
+			event:  "release",
+			want:   true,
+		},
 	}
 	for i, test := range tests {
 		manifest, err := yaml.ParseString(test.config)
@@ -83,56 +93,125 @@ func Test_skipEvent(t *testing.T) {
 	}
 }
 
-// func Test_skipPath(t *testing.T) {
-// 	tests := []struct {
-// 		config string
-// 		paths  []string
-// 		want   bool
-// 	}{
-// 		{
-// 			config: "trigger: { }",
-// 			paths:  []string{},
-// 			want:   false,
-// 		},
-// 		{
-// 			config: "trigger: { }",
-// 			paths:  []string{"README.md"},
-// 			want:   false,
-// 		},
-// 		{
-// 			config: "trigger: { paths: foo/* }",
-// 			paths:  []string{"foo/README"},
-// 			want:   false,
-// 		},
-// 		{
-// 			config: "trigger: { paths: foo/* }",
-// 			paths:  []string{"bar/README"},
-// 			want:   true,
-// 		},
-// 		// if empty changeset, never skip the pipeline
-// 		{
-// 			config: "trigger: { paths: foo/* }",
-// 			paths:  []string{},
-// 			want:   false,
-// 		},
-// 		// if max changeset, never skip the pipeline
-// 		{
-// 			config: "trigger: { paths: foo/* }",
-// 			paths:  make([]string, 400),
-// 			want:   false,
-// 		},
-// 	}
-// 	for i, test := range tests {
-// 		document, err := config.ParseString(test.config)
-// 		if err != nil {
-// 			t.Error(err)
-// 		}
-// 		got, want := skipPaths(document, test.paths), test.want
-// 		if got != want {
-// 			t.Errorf("Want test %d to return %v", i, want)
-// 		}
-// 	}
-// }
+func Test_skipAction(t *testing.T) {
+	tests := []struct {
+		config string
+		action string
+		want   bool
+	}{
+		{
+			config: "kind: pipeline\ntrigger: { }",
+			action: "published",
+			want:   false,
+		},
+		{
+			config: "kind: pipeline\ntrigger: { action: [ published ] }",
+			action: "published",
+			want:   false,
+		},
+		{
+			config: "kind: pipeline\ntrigger: { action: [ published ] }",
+			action: "prereleased",
+			want:   true,
+		},
+		{
+			config: "kind: pipeline\ntrigger: { action: { exclude: [ edited ] } }",
+			action: "edited",
+			want:   true,
+		},
+	}
+	for i, test := range tests {
+		manifest, err := yaml.ParseString(test.config)
+		if err != nil {
+			t.Error(err)
+		}
+		pipeline := manifest.Resources[0].(*yaml.Pipeline)
+		got, want := skipAction(pipeline, test.action), test.want
+		if got != want {
+			t.Errorf("Want test %d to return %v", i, want)
+		}
+	}
+}
+
+func Test_skipPaths(t *testing.T) {
+	tests := []struct {
+		config string
+		paths  []string
+		want   bool
+	}{
+		{
+			config: "kind: pipeline\ntrigger: { }",
+			paths:  []string{},
+			want:   false,
+		},
+		{
+			config: "kind: pipeline\ntrigger: { }",
+			paths:  []string{"README.md"},
+			want:   false,
+		},
+		{
+			config: "kind: pipeline\ntrigger: { paths: foo/* }",
+			paths:  []string{"foo/README"},
+			want:   false,
+		},
+		{
+			config: "kind: pipeline\ntrigger: { paths: foo/* }",
+			paths:  []string{"bar/README"},
+			want:   true,
+		},
+		// match any number of directories
+		{
+			config: "kind: pipeline\ntrigger: { paths: [ services/api/** ] }",
+			paths:  []string{"README.md", "services/api/cmd/main.go"},
+			want:   false,
+		},
+		{
+			config: "kind: pipeline\ntrigger: { paths: [ services/api/** ] }",
+			paths:  []string{"services/web/index.html"},
+			want:   true,
+		},
+		// skip if all changed files are excluded
+		{
+			config: "kind: pipeline\ntrigger: { paths: { exclude: [ \"**/*.md\" ] } }",
+			paths:  []string{"README.md", "docs/index.md"},
+			want:   true,
+		},
+		{
+			config: "kind: pipeline\ntrigger: { paths: { exclude: [ \"**/*.md\" ] } }",
+			paths:  []string{"README.md", "main.go"},
+			want:   false,
+		},
+		// skip if the included files are excluded
+		{
+			config: "kind: pipeline\ntrigger: { paths: { include: [ \"web/**\" ], exclude: [ \"**/*.md\" ] } }",
+			paths:  []string{"web/README.md", "main.go"},
+			want:   true,
+		},
+		// if empty changeset, never skip the pipeline
+		{
+			config: "kind: pipeline\ntrigger: { paths: foo/* }",
+			paths:  []string{},
+			want:   false,
+		},
+		// if max changeset, never skip the pipeline
+		{
+			config: "kind: pipeline\ntrigger: { paths: foo/* }",
+			paths:  make([]string, 400),
+			want:   false,
+		},
+	}
+	for i, test := range tests {
+		manifest, err := yaml.ParseString(test.config)
+		if err != nil {
+			t.Error(err)
+		}
+		pipeline := manifest.Resources[0].(*yaml.Pipeline)
+		got, want := skipPaths(pipeline, test.paths), test.want
+		if got != want {
+			t.Errorf("Want test %d to return %v", i, want)
+		}
+	}
+}
 
 func Test_skipMessage(t *testing.T) {
 	tests := []struct {
@@ -220,6 +299,11 @@ func Test_skipMessage(t *testing.T) {
 			title: "update readme [CI SKIP]",
 			want:  false,
 		},
+		{
+			event: "release",
+			title: "update readme [CI SKIP]",
+			want:  false,
+		},
 	}
 	for _, test := range tests {
 		hook := &core.Hook{
@@ -0,0 +1,74 @@
+// Copyright 2019 Drone IO, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package trigger
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v2"
+)
+
+// parseTimeouts returns the timeout of each pipeline in the
+// yaml configuration in minutes, indexed by pipeline name. The
+// timeout is expressed in minutes or as a duration string.
+// Timeouts are not part of the yaml specification and are
+// therefore parsed separately from the manifest.
+//
+//	kind: pipeline
+//	name: test
+//	timeout: 1h30m
+func parseTimeouts(data string) map[string]int64 {
+	out := map[string]int64{}
+	dec := yaml.NewDecoder(strings.NewReader(data))
+	for {
+		doc := struct {
+			Kind    string `yaml:"kind"`
+			Name    string `yaml:"name"`
+			Timeout string `yaml:"timeout"`
+		}{}
+		// the manifest is already parsed and validated, so
+		// decoding stops at the end of the input or at the
+		// first document that cannot be decoded.
+		if err := dec.Decode(&doc); err != nil {
+			break
+		}
+		if doc.Kind != "pipeline" {
+			continue
+		}
+		if timeout := parseTimeout(doc.Timeout); timeout > 0 {
+			out[doc.Name] = timeout
+		}
+	}
+	return out
+}
+
+// helper function parses the timeout and returns the value in
+// minutes, rounded up to the nearest minute. Invalid timeouts
+// are ignored and return zero.
+func parseTimeout(s string) int64 {
+	if s == "" {
+		return 0
+	}
+	if minutes, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return minutes
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil || d <= 0 {
+		return 0
+	}
+	return int64((d + time.Minute - 1) / time.Minute)
+}
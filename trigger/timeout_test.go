@@ -0,0 +1,44 @@
+// Copyright 2019 Drone.IO Inc. All rights reserved.
+// Use of this source code is governed by the Drone Non-Commercial License
+// that can be found in the LICENSE file.
+
+// +build !oss
+
+package trigger
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func Test_parseTimeouts(t *testing.T) {
+	data := `
+kind: pipeline
+name: build
+---
+kind: pipeline
+name: test
+timeout: 90
+---
+kind: pipeline
+name: deploy
+timeout: 1h30s
+---
+kind: pipeline
+name: invalid
+timeout: forever
+---
+kind: secret
+name: token
+timeout: 10
+`
+	want := map[string]int64{
+		"test":   90,
+		"deploy": 61,
+	}
+	got := parseTimeouts(data)
+	if diff := cmp.Diff(got, want); diff != "" {
+		t.Errorf(diff)
+	}
+}
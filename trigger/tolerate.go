@@ -0,0 +1,52 @@
+// Copyright 2019 Drone IO, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package trigger
+
+import (
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+// parseTolerations returns the runner taints tolerated by each
+// pipeline in the yaml configuration, indexed by pipeline name.
+// Tolerations are not part of the yaml specification and are
+// therefore parsed separately from the manifest.
+//
+//	kind: pipeline
+//	name: train
+//	tolerations:
+//	  gpu: exists
+func parseTolerations(data string) map[string]map[string]string {
+	out := map[string]map[string]string{}
+	dec := yaml.NewDecoder(strings.NewReader(data))
+	for {
+		doc := struct {
+			Kind        string            `yaml:"kind"`
+			Name        string            `yaml:"name"`
+			Tolerations map[string]string `yaml:"tolerations"`
+		}{}
+		// the manifest is already parsed and validated, so
+		// decoding stops at the end of the input or at the
+		// first document that cannot be decoded.
+		if err := dec.Decode(&doc); err != nil {
+			break
+		}
+		if doc.Kind == "pipeline" && len(doc.Tolerations) != 0 {
+			out[doc.Name] = doc.Tolerations
+		}
+	}
+	return out
+}
@@ -0,0 +1,37 @@
+// Copyright 2019 Drone.IO Inc. All rights reserved.
+// Use of this source code is governed by the Drone Non-Commercial License
+// that can be found in the LICENSE file.
+
+// +build !oss
+
+package trigger
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func Test_parseTolerations(t *testing.T) {
+	data := `
+kind: pipeline
+name: build
+---
+kind: pipeline
+name: train
+node:
+  region: eu
+tolerations:
+  gpu: exists
+---
+kind: secret
+name: token
+`
+	want := map[string]map[string]string{
+		"train": {"gpu": "exists"},
+	}
+	got := parseTolerations(data)
+	if diff := cmp.Diff(got, want); diff != "" {
+		t.Errorf(diff)
+	}
+}
@@ -17,7 +17,6 @@ package trigger
 import (
 	"context"
 	"runtime/debug"
-	"strings"
 	"time"
 
 	"github.com/drone/drone-yaml/yaml"
@@ -27,6 +26,7 @@ import (
 
 	"github.com/drone/drone/core"
 	"github.com/drone/drone/trigger/dag"
+	"github.com/drone/go-scm/scm"
 
 	"github.com/sirupsen/logrus"
 )
@@ -43,6 +43,7 @@ type triggerer struct {
 	users    core.UserStore
 	validate core.ValidateService
 	hooks    core.WebhookSender
+	policy   *core.QueuePolicy
 }
 
 // New returns a new build triggerer.
@@ -58,6 +59,7 @@ func New(
 	users core.UserStore,
 	validate core.ValidateService,
 	hooks core.WebhookSender,
+	policy *core.QueuePolicy,
 ) core.Triggerer {
 	return &triggerer{
 		canceler: canceler,
@@ -71,6 +73,7 @@ func New(
 		users:    users,
 		validate: validate,
 		hooks:    hooks,
+		policy:   policy,
 	}
 }
 
@@ -94,20 +97,10 @@ func (t *triggerer) Trigger(ctx context.Context, repo *core.Repository, base *co
 		}
 	}()
 
-	if skipMessage(base) {
-		logger.Infoln("trigger: skipping hook. found skip directive")
+	if reason := skipHook(repo, base); reason != "" {
+		logger.Infoln("trigger: skipping hook. " + reason)
 		return nil, nil
 	}
-	if base.Event == core.EventPullRequest {
-		if repo.IgnorePulls {
-			logger.Infoln("trigger: skipping hook. project ignores pull requests")
-			return nil, nil
-		}
-		if repo.IgnoreForks && !strings.EqualFold(base.Fork, repo.Slug) {
-			logger.Infoln("trigger: skipping hook. project ignores forks")
-			return nil, nil
-		}
-	}
 
 	user, err := t.users.Find(ctx, repo.UserID)
 	if err != nil {
@@ -121,6 +114,18 @@ func (t *triggerer) Trigger(ctx context.Context, repo *core.Repository, base *co
 		return nil, nil
 	}
 
+	// release hooks do not include the commit sha, which is
+	// resolved from the release tag.
+	if base.After == "" && base.Event == core.EventRelease {
+		commit, err := t.commits.FindRef(ctx, user, repo.Slug, scm.TrimRef(base.Ref))
+		if err != nil {
+			logger = logger.WithError(err)
+			logger.Warnln("trigger: cannot find release commit")
+			return nil, err
+		}
+		base.After = commit.Sha
+	}
+
 	// if the commit message is not included we should
 	// make an optional API call to the version control
 	// system to augment the available information.
@@ -161,36 +166,7 @@ func (t *triggerer) Trigger(ctx context.Context, repo *core.Repository, base *co
 	// 		obj = base.Ref
 	// 	}
 	// }
-	tmpBuild := &core.Build{
-		RepoID:  repo.ID,
-		Trigger: base.Trigger,
-		Parent:  base.Parent,
-		Status:  core.StatusPending,
-		Event:   base.Event,
-		Action:  base.Action,
-		Link:    base.Link,
-		// Timestamp:    base.Timestamp,
-		Title:        base.Title,
-		Message:      base.Message,
-		Before:       base.Before,
-		After:        base.After,
-		Ref:          base.Ref,
-		Fork:         base.Fork,
-		Source:       base.Source,
-		Target:       base.Target,
-		Author:       base.Author,
-		AuthorName:   base.AuthorName,
-		AuthorEmail:  base.AuthorEmail,
-		AuthorAvatar: base.AuthorAvatar,
-		Params:       base.Params,
-		Cron:         base.Cron,
-		Deploy:       base.Deployment,
-		DeployID:     base.DeploymentID,
-		Debug:        base.Debug,
-		Sender:       base.Sender,
-		Created:      time.Now().Unix(),
-		Updated:      time.Now().Unix(),
-	}
+	tmpBuild := newTempBuild(repo, base)
 	req := &core.ConfigArgs{
 		User:  user,
 		Repo:  repo,
@@ -275,12 +251,17 @@ func (t *triggerer) Trigger(ctx context.Context, repo *core.Repository, base *co
 		verified = false
 	}
 
-	// var paths []string
-	// paths, err := listChanges(t.client, repo, base)
-	// if err != nil {
-	// 	logger.Warn().Err(err).
-	// 		Msg("cannot fetch changeset")
-	// }
+	// the changed files are only fetched when one or more
+	// pipelines define path conditions, to avoid unnecessary
+	// calls to the source control management service.
+	var paths []string
+	if hasPaths(manifest) {
+		paths, err = t.listChanges(ctx, user, repo, base)
+		if err != nil {
+			logger.WithError(err).
+				Warnln("trigger: cannot fetch changeset")
+		}
+	}
 
 	var matched []*yaml.Pipeline
 	var dag = dag.New()
@@ -300,27 +281,9 @@ func (t *triggerer) Trigger(ctx context.Context, repo *core.Repository, base *co
 		node := dag.Add(pipeline.Name, pipeline.DependsOn...)
 		node.Skip = true
 
-		if skipBranch(pipeline, base.Target) {
-			logger = logger.WithField("pipeline", pipeline.Name)
-			logger.Infoln("trigger: skipping pipeline, does not match branch")
-		} else if skipEvent(pipeline, base.Event) {
-			logger = logger.WithField("pipeline", pipeline.Name)
-			logger.Infoln("trigger: skipping pipeline, does not match event")
-		} else if skipAction(pipeline, base.Action) {
-			logger = logger.WithField("pipeline", pipeline.Name).WithField("action", base.Action)
-			logger.Infoln("trigger: skipping pipeline, does not match action")
-		} else if skipRef(pipeline, base.Ref) {
-			logger = logger.WithField("pipeline", pipeline.Name)
-			logger.Infoln("trigger: skipping pipeline, does not match ref")
-		} else if skipRepo(pipeline, repo.Slug) {
-			logger = logger.WithField("pipeline", pipeline.Name)
-			logger.Infoln("trigger: skipping pipeline, does not match repo")
-		} else if skipTarget(pipeline, base.Deployment) {
-			logger = logger.WithField("pipeline", pipeline.Name)
-			logger.Infoln("trigger: skipping pipeline, does not match deploy target")
-		} else if skipCron(pipeline, base.Cron) {
-			logger = logger.WithField("pipeline", pipeline.Name)
-			logger.Infoln("trigger: skipping pipeline, does not match cron job")
+		if reason := skipPipeline(pipeline, repo, base, paths); reason != "" {
+			logger.WithField("pipeline", pipeline.Name).
+				Infoln("trigger: skipping pipeline, " + reason)
 		} else {
 			matched = append(matched, pipeline)
 			node.Skip = false
@@ -375,6 +338,10 @@ func (t *triggerer) Trigger(ctx context.Context, repo *core.Repository, base *co
 		Updated:      time.Now().Unix(),
 	}
 
+	priority := t.policy.Priority(repo, build)
+	tolerations := parseTolerations(raw.Data)
+	timeouts := parseTimeouts(raw.Data)
+
 	stages := make([]*core.Stage, len(matched))
 	for i, match := range matched {
 		onSuccess := match.Trigger.Status.Match(core.StatusPassing)
@@ -395,11 +362,14 @@ func (t *triggerer) Trigger(ctx context.Context, repo *core.Repository, base *co
 			Kernel:    match.Platform.Version,
 			Limit:     match.Concurrency.Limit,
 			LimitRepo: int(repo.Throttle),
+			Priority:  priority,
 			Status:    core.StatusWaiting,
 			DependsOn: match.DependsOn,
 			OnSuccess: onSuccess,
 			OnFailure: onFailure,
 			Labels:    match.Node,
+			Tolerates: tolerations[match.Name],
+			Timeout:   timeouts[match.Name],
 			Created:   time.Now().Unix(),
 			Updated:   time.Now().Unix(),
 		}
@@ -480,7 +450,8 @@ func (t *triggerer) Trigger(ctx context.Context, repo *core.Repository, base *co
 		logger.Warnln("trigger: cannot send webhook")
 	}
 
-	if repo.CancelPush && build.Event == core.EventPush ||
+	if repo.CancelPolicy != nil ||
+		repo.CancelPush && build.Event == core.EventPush ||
 		repo.CancelPulls && build.Event == core.EventPullRequest {
 		go t.canceler.CancelPending(ctx, repo, build)
 	}
@@ -506,6 +477,42 @@ func (t *triggerer) Trigger(ctx context.Context, repo *core.Repository, base *co
 	return build, nil
 }
 
+// helper function returns a temporary build used to fetch,
+// convert and validate the configuration before the build
+// is created.
+func newTempBuild(repo *core.Repository, base *core.Hook) *core.Build {
+	return &core.Build{
+		RepoID:  repo.ID,
+		Trigger: base.Trigger,
+		Parent:  base.Parent,
+		Status:  core.StatusPending,
+		Event:   base.Event,
+		Action:  base.Action,
+		Link:    base.Link,
+		// Timestamp:    base.Timestamp,
+		Title:        base.Title,
+		Message:      base.Message,
+		Before:       base.Before,
+		After:        base.After,
+		Ref:          base.Ref,
+		Fork:         base.Fork,
+		Source:       base.Source,
+		Target:       base.Target,
+		Author:       base.Author,
+		AuthorName:   base.AuthorName,
+		AuthorEmail:  base.AuthorEmail,
+		AuthorAvatar: base.AuthorAvatar,
+		Params:       base.Params,
+		Cron:         base.Cron,
+		Deploy:       base.Deployment,
+		DeployID:     base.DeploymentID,
+		Debug:        base.Debug,
+		Sender:       base.Sender,
+		Created:      time.Now().Unix(),
+		Updated:      time.Now().Unix(),
+	}
+}
+
 func trunc(s string, i int) string {
 	runes := []rune(s)
 	if len(runes) > i {
@@ -90,6 +90,7 @@ func TestTrigger(t *testing.T) {
 		mockUsers,
 		mockValidateService,
 		mockWebhooks,
+		nil,
 	)
 
 	build, err := triggerer.Trigger(noContext, dummyRepo, dummyHook)
@@ -117,6 +118,7 @@ func TestTrigger_SkipCI(t *testing.T) {
 		nil,
 		nil,
 		nil,
+		nil,
 	)
 	dummyHookSkip := *dummyHook
 	dummyHookSkip.Message = "foo [CI SKIP] bar"
@@ -146,6 +148,7 @@ func TestTrigger_NoOwner(t *testing.T) {
 		mockUsers,
 		nil,
 		nil,
+		nil,
 	)
 
 	_, err := triggerer.Trigger(noContext, dummyRepo, dummyHook)
@@ -154,6 +157,45 @@ func TestTrigger_NoOwner(t *testing.T) {
 	}
 }
 
+// this test verifies that the commit sha of a release hook is
+// resolved from the release tag, and the function exits with
+// an error if the commit cannot be found.
+func TestTrigger_ReleaseCommit(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	mockUsers := mock.NewMockUserStore(controller)
+	mockUsers.EXPECT().Find(noContext, dummyRepo.UserID).Return(dummyUser, nil)
+
+	mockCommits := mock.NewMockCommitService(controller)
+	mockCommits.EXPECT().FindRef(gomock.Any(), dummyUser, dummyRepo.Slug, "v1.0.0").Return(nil, sql.ErrNoRows)
+
+	triggerer := New(
+		nil,
+		nil,
+		nil,
+		mockCommits,
+		nil,
+		nil,
+		nil,
+		nil,
+		mockUsers,
+		nil,
+		nil,
+		nil,
+	)
+
+	hook := &core.Hook{
+		Event:  core.EventRelease,
+		Action: core.ActionPublished,
+		Ref:    "refs/tags/v1.0.0",
+	}
+	_, err := triggerer.Trigger(noContext, dummyRepo, hook)
+	if err != sql.ErrNoRows {
+		t.Errorf("Expect error when release commit not found")
+	}
+}
+
 // this test verifies that if the system cannot fetch the yaml
 // configuration file, the function must exit with an error.
 func TestTrigger_MissingYaml(t *testing.T) {
@@ -178,6 +220,7 @@ func TestTrigger_MissingYaml(t *testing.T) {
 		mockUsers,
 		nil,
 		nil,
+		nil,
 	)
 
 	_, err := triggerer.Trigger(noContext, dummyRepo, dummyHook)
@@ -219,6 +262,7 @@ func TestTrigger_ErrorYaml(t *testing.T) {
 		mockUsers,
 		nil,
 		nil,
+		nil,
 	)
 
 	build, err := triggerer.Trigger(noContext, dummyRepo, dummyHook)
@@ -267,6 +311,7 @@ func TestTrigger_SkipBranch(t *testing.T) {
 		mockUsers,
 		mockValidateService,
 		nil,
+		nil,
 	)
 
 	_, err := triggerer.Trigger(noContext, dummyRepo, dummyHook)
@@ -275,6 +320,51 @@ func TestTrigger_SkipBranch(t *testing.T) {
 	}
 }
 
+// this test verifies that no build should be scheduled if the
+// changed files do not match the paths defined in the yaml.
+func TestTrigger_SkipPaths(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	mockUsers := mock.NewMockUserStore(controller)
+	mockUsers.EXPECT().Find(noContext, dummyRepo.UserID).Return(dummyUser, nil)
+
+	mockConfigService := mock.NewMockConfigService(controller)
+	mockConfigService.EXPECT().Find(gomock.Any(), gomock.Any()).Return(dummyYamlSkipPaths, nil)
+
+	mockConvertService := mock.NewMockConvertService(controller)
+	mockConvertService.EXPECT().Convert(gomock.Any(), gomock.Any()).Return(dummyYamlSkipPaths, nil)
+
+	mockValidateService := mock.NewMockValidateService(controller)
+	mockValidateService.EXPECT().Validate(gomock.Any(), gomock.Any()).Return(nil)
+
+	mockCommits := mock.NewMockCommitService(controller)
+	mockCommits.EXPECT().CompareChanges(gomock.Any(), dummyUser, dummyRepo.Slug, dummyHook.Before, dummyHook.After).Return([]*core.Change{{Path: "docs/README.md"}}, nil)
+
+	triggerer := New(
+		nil,
+		mockConfigService,
+		mockConvertService,
+		mockCommits,
+		nil,
+		nil,
+		nil,
+		nil,
+		mockUsers,
+		mockValidateService,
+		nil,
+		nil,
+	)
+
+	build, err := triggerer.Trigger(noContext, dummyRepo, dummyHook)
+	if err != nil {
+		t.Errorf("Expect build silently skipped if paths do not match")
+	}
+	if build != nil {
+		t.Errorf("Expect build skipped if paths do not match")
+	}
+}
+
 // this test verifies that no build should be scheduled if the
 // hook event does not match the events defined in the yaml.
 func TestTrigger_SkipEvent(t *testing.T) {
@@ -305,6 +395,7 @@ func TestTrigger_SkipEvent(t *testing.T) {
 		mockUsers,
 		mockValidateService,
 		nil,
+		nil,
 	)
 
 	_, err := triggerer.Trigger(noContext, dummyRepo, dummyHook)
@@ -343,6 +434,7 @@ func TestTrigger_SkipAction(t *testing.T) {
 		mockUsers,
 		mockValidateService,
 		nil,
+		nil,
 	)
 
 	_, err := triggerer.Trigger(noContext, dummyRepo, dummyHook)
@@ -385,6 +477,7 @@ func TestTrigger_ErrorIncrement(t *testing.T) {
 		mockUsers,
 		mockValidateService,
 		nil,
+		nil,
 	)
 
 	_, err := triggerer.Trigger(noContext, dummyRepo, dummyHook)
@@ -599,6 +692,15 @@ trigger:
     - opened`,
 	}
 
+	dummyYamlSkipPaths = &core.Config{
+		Data: `
+kind: pipeline
+trigger:
+  paths:
+    include:
+    - src/**`,
+	}
+
 	ignoreBuildFields = cmpopts.IgnoreFields(core.Build{},
 		"Created", "Updated")
 